@@ -0,0 +1,171 @@
+package authz
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Action — действие, право на которое проверяет Authorizer. Это же ключ права в наборе
+// permissions роли.
+type Action string
+
+// Действия в сообществе. Права ролей платформы распространяются на все сообщества.
+const (
+	// ManageCommunity — настройки сообщества, элементы профиля и правила
+	ManageCommunity Action = "community.manage"
+	// ManageRoles — роли сообщества и их участники
+	ManageRoles Action = "community.roles.manage"
+	BanUser     Action = "community.user.ban"
+	MuteUser    Action = "community.user.mute"
+	DeletePost  Action = "community.post.delete"
+	// EditPost — правка чужих постов (заголовок, текст, обложка)
+	EditPost Action = "community.post.edit"
+	// UnpublishPost — снятие поста с публикации
+	UnpublishPost Action = "community.post.unpublish"
+	DeleteComment Action = "community.comment.delete"
+	// ManageReports — очередь жалоб сообщества
+	ManageReports Action = "community.reports.manage"
+)
+
+// Действия на платформе
+const (
+	// ManageHost — настройки платформы, навигация, правила и роли платформы
+	ManageHost        Action = "host.manage"
+	HostBanUser       Action = "host.user.ban"
+	HostMuteUser      Action = "host.user.mute"
+	HostBanCommunity  Action = "host.community.ban"
+	HostMuteCommunity Action = "host.community.mute"
+	// HostManageReports — очередь жалоб платформы (нарушения правил платформы, пользователи, сообщества)
+	HostManageReports Action = "host.reports.manage"
+)
+
+// Scope — где действует право
+type Scope string
+
+const (
+	ScopeHost      Scope = "host"
+	ScopeCommunity Scope = "community"
+)
+
+// Definition — описание права в реестре
+type Definition struct {
+	Key         Action
+	Description string
+	Scope       Scope
+	// Dangerous — право дает власть над другими пользователями или всей платформой;
+	// интерфейс выдает его с подтверждением
+	Dangerous bool
+}
+
+// RegistryVersion — версия ключей реестра. Роли хранят ее вместе с permissions и
+// denied_actions (permissions_version). Добавление права версию не меняет; при
+// переименовании или удалении ключа версия увеличивается, а замена записывается в renames —
+// миграция при старте переводит наборы старых версий.
+const RegistryVersion = 1
+
+// renames — замены ключей по версиям: renames[v] переводит ключ версии v-1 в ключи версии v,
+// пустой список удаляет ключ
+var renames = map[int]map[Action][]Action{}
+
+// registry — все права в порядке отображения. Новое право добавляется сюда: роли хранят
+// ключи, схема базы и GraphQL-входы не меняются.
+var registry = []Definition{
+	{ManageCommunity, "Изменять настройки, профиль и правила сообщества", ScopeCommunity, true},
+	{ManageRoles, "Создавать роли сообщества и назначать их участникам", ScopeCommunity, true},
+	{BanUser, "Банить пользователей в сообществе", ScopeCommunity, true},
+	{MuteUser, "Мутить пользователей в сообществе", ScopeCommunity, false},
+	{EditPost, "Редактировать чужие посты в сообществе", ScopeCommunity, false},
+	{DeletePost, "Удалять посты в сообществе", ScopeCommunity, false},
+	{UnpublishPost, "Снимать посты с публикации", ScopeCommunity, false},
+	{DeleteComment, "Удалять комментарии в сообществе", ScopeCommunity, false},
+	{ManageReports, "Разбирать жалобы в сообществе", ScopeCommunity, false},
+
+	{ManageHost, "Изменять настройки, навигацию, правила и роли платформы", ScopeHost, true},
+	{HostBanUser, "Банить пользователей на платформе", ScopeHost, true},
+	{HostMuteUser, "Мутить пользователей на платформе", ScopeHost, false},
+	{HostBanCommunity, "Банить сообщества на платформе", ScopeHost, true},
+	{HostMuteCommunity, "Мутить сообщества на платформе", ScopeHost, false},
+	{HostManageReports, "Разбирать жалобы на платформе", ScopeHost, false},
+}
+
+// Definitions — реестр прав; пустой scope — все права
+func Definitions(scope Scope) []Definition {
+	out := make([]Definition, 0, len(registry))
+	for _, d := range registry {
+		if scope == "" || d.Scope == scope {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// Lookup находит право в реестре
+func Lookup(key Action) (Definition, bool) {
+	i := slices.IndexFunc(registry, func(d Definition) bool { return d.Key == key })
+	if i < 0 {
+		return Definition{}, false
+	}
+	return registry[i], true
+}
+
+// Keys — ключи прав реестра для набора permissions
+func Keys(defs []Definition) []string {
+	out := make([]string, len(defs))
+	for i, d := range defs {
+		out[i] = string(d.Key)
+	}
+	return out
+}
+
+// Upgrade переводит ключи, записанные при версии реестра version, в ключи текущей версии.
+// Версия 0 — набор, записанный до появления версий: его ключи совпадают с версией 1.
+func Upgrade(version int, keys []string) []string {
+	out := slices.Clone(keys)
+	for v := max(version, 1) + 1; v <= RegistryVersion; v++ {
+		next := make([]string, 0, len(out))
+		for _, key := range out {
+			replaced, ok := renames[v][Action(key)]
+			if !ok {
+				replaced = []Action{Action(key)}
+			}
+			for _, a := range replaced {
+				if !slices.Contains(next, string(a)) {
+					next = append(next, string(a))
+				}
+			}
+		}
+		out = next
+	}
+	return out
+}
+
+// ParseActions проверяет список действий для явных запретов роли
+func ParseActions(names []string) ([]string, error) {
+	return parse(names, "")
+}
+
+// ParsePermissions проверяет набор прав роли. Роли сообщества получают только права
+// сообщества; роли платформы — любые, права сообщества действуют во всех сообществах.
+func ParsePermissions(scope Scope, names []string) ([]string, error) {
+	if scope == ScopeHost {
+		scope = ""
+	}
+	return parse(names, scope)
+}
+
+func parse(names []string, scope Scope) ([]string, error) {
+	out := make([]string, 0, len(names))
+	for _, name := range names {
+		d, ok := Lookup(Action(name))
+		if !ok {
+			return nil, fmt.Errorf("unknown action %q", name)
+		}
+		if scope != "" && d.Scope != scope {
+			return nil, fmt.Errorf("action %q is not allowed in %s scope", name, scope)
+		}
+		if !slices.Contains(out, name) {
+			out = append(out, name)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,296 @@
+// Package authz — единая проверка прав на платформе и в сообществах.
+//
+// Решение принимается так:
+//   - владелец платформы может всё, запреты на него не действуют;
+//   - явный запрет (denied_actions) любой роли пользователя — платформы или сообщества —
+//     перекрывает любые разрешения, в том числе владение сообществом;
+//   - владелец сообщества может любое действие в своём сообществе;
+//   - иначе право дает ключ в наборе permissions одной из ролей: права сообщества
+//     в ролях платформы действуют во всех сообществах;
+//   - санкции к пользователю (Resource.Against) и управление ролью (Resource.WithRole)
+//     запрещены, если цель старше актора в иерархии; к себе санкции не применяются.
+//
+// Иерархия сообщества (от старших): владелец платформы, сотрудники платформы (роли платформы
+// с правами модерации) по позиции, владелец сообщества, роли сообщества по позиции.
+// На уровне платформы учитываются только владелец платформы и роли платформы.
+package authz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"stormlink/server/ent"
+	"stormlink/server/model"
+)
+
+// ErrForbidden — действие запрещено
+var ErrForbidden = errors.New("forbidden")
+
+// Resource — над чем совершается действие
+type Resource struct {
+	// CommunityID — сообщество; 0 — платформа
+	CommunityID int
+	// TargetUserID — пользователь, к которому применяется действие; 0 — нет
+	TargetUserID int
+
+	rolePosition *int32
+}
+
+// Host — ресурс уровня платформы
+func Host() Resource { return Resource{} }
+
+// Community — ресурс сообщества
+func Community(id int) Resource { return Resource{CommunityID: id} }
+
+// Against добавляет цель действия: она не должна быть старше актора
+func (r Resource) Against(userID int) Resource {
+	r.TargetUserID = userID
+	return r
+}
+
+// WithRole добавляет роль, которой управляют: она не должна быть старше актора
+func (r Resource) WithRole(position int32) Resource {
+	r.rolePosition = &position
+	return r
+}
+
+// Authorizer проверяет права пользователей
+type Authorizer struct {
+	store Store
+}
+
+func New(store Store) *Authorizer {
+	return &Authorizer{store: store}
+}
+
+// Can сообщает, может ли actor совершить action над res
+func (a *Authorizer) Can(ctx context.Context, actor int, action Action, res Resource) (bool, error) {
+	d, ok := Lookup(action)
+	if !ok {
+		return false, fmt.Errorf("authz: unknown action %q", action)
+	}
+	if actor == 0 {
+		return false, nil
+	}
+	host := d.Scope == ScopeHost
+	scope := res.CommunityID
+	if host {
+		scope = 0
+	} else if scope == 0 {
+		return false, fmt.Errorf("authz: %s requires a community", action)
+	}
+
+	s, err := a.load(ctx, actor, scope)
+	if err != nil {
+		return false, err
+	}
+	if !s.allows(action, host) {
+		return false, nil
+	}
+	if s.hostOwner {
+		return res.TargetUserID != actor, nil
+	}
+	if res.rolePosition != nil && roleRank(host, int(*res.rolePosition)).above(s.rank(host)) {
+		return false, nil
+	}
+	if res.TargetUserID != 0 {
+		if res.TargetUserID == actor {
+			return false, nil
+		}
+		target, err := a.load(ctx, res.TargetUserID, scope)
+		if err != nil {
+			return false, err
+		}
+		if target.rank(host).above(s.rank(host)) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Require — Can, возвращающий ErrForbidden при отказе
+func (a *Authorizer) Require(ctx context.Context, actor int, action Action, res Resource) error {
+	ok, err := a.Can(ctx, actor, action, res)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrForbidden, action)
+	}
+	return nil
+}
+
+// Permissions — права пользователя в сообществе одним набором (для viewerPermissions)
+func (a *Authorizer) Permissions(ctx context.Context, actor, communityID int) (*model.CommunityPermissions, error) {
+	if actor == 0 {
+		return &model.CommunityPermissions{}, nil
+	}
+	s, err := a.load(ctx, actor, communityID)
+	if err != nil {
+		return nil, err
+	}
+	granted := []string{}
+	for _, d := range Definitions(ScopeCommunity) {
+		if s.allows(d.Key, false) {
+			granted = append(granted, string(d.Key))
+		}
+	}
+	return &model.CommunityPermissions{
+		Permissions:                        granted,
+		CommunityRolesManagement:           slices.Contains(granted, string(ManageRoles)),
+		CommunityUserBan:                   slices.Contains(granted, string(BanUser)),
+		CommunityUserMute:                  slices.Contains(granted, string(MuteUser)),
+		CommunityDeletePost:                slices.Contains(granted, string(DeletePost)),
+		CommunityDeleteComments:            slices.Contains(granted, string(DeleteComment)),
+		CommunityRemovePostFromPublication: slices.Contains(granted, string(UnpublishPost)),
+		CommunityOwner:                     s.communityOwner,
+		HostOwner:                          s.hostOwner,
+	}, nil
+}
+
+// subject — всё, что известно о пользователе в области действия (платформа или сообщество)
+type subject struct {
+	hostOwner      bool
+	communityOwner bool
+	hostRoles      []*ent.HostRole
+	roles          []*ent.Role
+}
+
+func (a *Authorizer) load(ctx context.Context, userID, communityID int) (*subject, error) {
+	s := &subject{}
+	hostOwner, err := a.store.HostOwnerID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("authz: host owner: %w", err)
+	}
+	s.hostOwner = hostOwner != 0 && hostOwner == userID
+	if s.hostRoles, err = a.store.HostRoles(ctx, userID); err != nil {
+		return nil, fmt.Errorf("authz: host roles: %w", err)
+	}
+	if communityID == 0 {
+		return s, nil
+	}
+	owner, err := a.store.CommunityOwnerID(ctx, communityID)
+	if err != nil {
+		return nil, fmt.Errorf("authz: community owner: %w", err)
+	}
+	s.communityOwner = owner == userID
+	if s.roles, err = a.store.CommunityRoles(ctx, userID, communityID); err != nil {
+		return nil, fmt.Errorf("authz: community roles: %w", err)
+	}
+	return s, nil
+}
+
+// allows — право на действие без учёта цели
+func (s *subject) allows(action Action, host bool) bool {
+	if s.hostOwner {
+		return true
+	}
+	if s.denies(action) {
+		return false
+	}
+	if !host && s.communityOwner {
+		return true
+	}
+	for _, r := range s.hostRoles {
+		if slices.Contains(r.Permissions, string(action)) {
+			return true
+		}
+	}
+	if !host {
+		for _, r := range s.roles {
+			if slices.Contains(r.Permissions, string(action)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *subject) denies(action Action) bool {
+	for _, r := range s.hostRoles {
+		if slices.Contains(r.DeniedActions, string(action)) {
+			return true
+		}
+	}
+	for _, r := range s.roles {
+		if slices.Contains(r.DeniedActions, string(action)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Уровни иерархии
+const (
+	tierNone = iota
+	tierCommunityRole
+	tierCommunityOwner
+	tierHostRole
+	tierHostOwner
+)
+
+type rank struct {
+	tier     int
+	position int
+}
+
+func (r rank) above(o rank) bool {
+	if r.tier != o.tier {
+		return r.tier > o.tier
+	}
+	return r.position > o.position
+}
+
+func roleRank(host bool, position int) rank {
+	if host {
+		return rank{tier: tierHostRole, position: position}
+	}
+	return rank{tier: tierCommunityRole, position: position}
+}
+
+// rank — место пользователя в иерархии платформы (host) или сообщества
+func (s *subject) rank(host bool) rank {
+	if s.hostOwner {
+		return rank{tier: tierHostOwner}
+	}
+	if r, ok := s.hostRank(host); ok {
+		return r
+	}
+	if host {
+		return rank{}
+	}
+	if s.communityOwner {
+		return rank{tier: tierCommunityOwner}
+	}
+	if len(s.roles) == 0 {
+		return rank{}
+	}
+	r := rank{tier: tierCommunityRole, position: int(s.roles[0].Position)}
+	for _, role := range s.roles[1:] {
+		r.position = max(r.position, int(role.Position))
+	}
+	return r
+}
+
+// hostRank — старшая роль платформы. В сообществе учитываются только роли с правами модерации:
+// декоративная роль платформы не ставит пользователя выше владельца сообщества.
+func (s *subject) hostRank(host bool) (rank, bool) {
+	found := false
+	r := rank{tier: tierHostRole}
+	for _, role := range s.hostRoles {
+		if !host && !staff(role) {
+			continue
+		}
+		if !found || int(role.Position) > r.position {
+			r.position = int(role.Position)
+		}
+		found = true
+	}
+	return r, found
+}
+
+func staff(r *ent.HostRole) bool {
+	return len(r.Permissions) > 0
+}
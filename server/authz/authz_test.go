@@ -0,0 +1,250 @@
+package authz
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"stormlink/server/ent"
+)
+
+// memStore — Store в памяти: владельцы и роли задаются прямо в тесте
+type memStore struct {
+	hostOwner       int
+	communityOwners map[int]int
+	hostRoles       map[int][]*ent.HostRole
+	roles           map[int][]*ent.Role // по пользователю
+}
+
+func (m *memStore) HostOwnerID(context.Context) (int, error) { return m.hostOwner, nil }
+
+func (m *memStore) CommunityOwnerID(_ context.Context, communityID int) (int, error) {
+	owner, ok := m.communityOwners[communityID]
+	if !ok {
+		return 0, errors.New("community not found")
+	}
+	return owner, nil
+}
+
+func (m *memStore) HostRoles(_ context.Context, userID int) ([]*ent.HostRole, error) {
+	return m.hostRoles[userID], nil
+}
+
+func (m *memStore) CommunityRoles(_ context.Context, userID, communityID int) ([]*ent.Role, error) {
+	var out []*ent.Role
+	for _, r := range m.roles[userID] {
+		if r.CommunityID == communityID {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+const (
+	hostOwner = iota + 1
+	communityOwner
+	hostModerator
+	hostBadge
+	seniorModerator
+	moderator
+	restrictedModerator
+	member
+)
+
+func newTestAuthorizer() *Authorizer {
+	return New(&memStore{
+		hostOwner:       hostOwner,
+		communityOwners: map[int]int{10: communityOwner, 20: member},
+		hostRoles: map[int][]*ent.HostRole{
+			hostModerator: {{ID: 1, Permissions: []string{string(HostBanUser), string(BanUser), string(HostBanCommunity), string(DeletePost)}, Position: 1}},
+			// Роль без прав: значок, а не должность
+			hostBadge: {{ID: 2, Title: "supporter"}},
+		},
+		roles: map[int][]*ent.Role{
+			seniorModerator: {{ID: 1, CommunityID: 10, Permissions: []string{string(BanUser), string(ManageRoles)}, Position: 5}},
+			moderator: {
+				{ID: 2, CommunityID: 10, Permissions: []string{string(BanUser)}, Position: 2},
+				{ID: 3, CommunityID: 10, Permissions: []string{string(MuteUser)}, Position: 1},
+			},
+			restrictedModerator: {
+				{ID: 2, CommunityID: 10, Permissions: []string{string(BanUser)}, Position: 2},
+				{ID: 4, CommunityID: 10, DeniedActions: []string{string(BanUser)}},
+			},
+		},
+	})
+}
+
+func TestCan(t *testing.T) {
+	a := newTestAuthorizer()
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		actor  int
+		action Action
+		res    Resource
+		want   bool
+	}{
+		{"anonymous", 0, BanUser, Community(10), false},
+		{"member has no rights", member, BanUser, Community(10), false},
+		{"community owner", communityOwner, DeletePost, Community(10), true},
+		{"community owner elsewhere", communityOwner, DeletePost, Community(20), false},
+		{"role grants", moderator, MuteUser, Community(10), true},
+		{"role does not grant", moderator, DeletePost, Community(10), false},
+		{"roles are per community", moderator, BanUser, Community(20), false},
+		{"deny overrides grant", restrictedModerator, BanUser, Community(10), false},
+		{"host role flows down", hostModerator, BanUser, Community(20), true},
+		{"host role without permission", hostModerator, MuteUser, Community(20), false},
+		{"host action", hostModerator, HostBanCommunity, Host(), true},
+		{"community role is not host staff", seniorModerator, HostBanUser, Host(), false},
+		{"host settings are owner only", hostModerator, ManageHost, Host(), false},
+		{"host owner", hostOwner, ManageHost, Host(), true},
+		{"host owner in any community", hostOwner, ManageRoles, Community(20), true},
+
+		{"ban lower role", seniorModerator, BanUser, Community(10).Against(moderator), true},
+		{"ban equal role", moderator, BanUser, Community(10).Against(restrictedModerator), true},
+		{"ban higher role", moderator, BanUser, Community(10).Against(seniorModerator), false},
+		{"ban community owner", seniorModerator, BanUser, Community(10).Against(communityOwner), false},
+		{"owner bans moderator", communityOwner, BanUser, Community(10).Against(seniorModerator), true},
+		{"owner bans host staff", communityOwner, BanUser, Community(10).Against(hostModerator), false},
+		{"owner bans host badge", communityOwner, BanUser, Community(10).Against(hostBadge), true},
+		{"host staff bans owner", hostModerator, BanUser, Community(10).Against(communityOwner), true},
+		{"host staff bans host owner", hostModerator, HostBanUser, Host().Against(hostOwner), false},
+		{"not yourself", seniorModerator, BanUser, Community(10).Against(seniorModerator), false},
+		{"host owner not yourself", hostOwner, HostBanUser, Host().Against(hostOwner), false},
+
+		{"manage lower role", seniorModerator, ManageRoles, Community(10).WithRole(3), true},
+		{"manage own level", seniorModerator, ManageRoles, Community(10).WithRole(5), true},
+		{"manage higher role", seniorModerator, ManageRoles, Community(10).WithRole(6), false},
+		{"owner manages any role", communityOwner, ManageRoles, Community(10).WithRole(100), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := a.Can(ctx, tt.actor, tt.action, tt.res)
+			if err != nil {
+				t.Fatalf("Can: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Can(%d, %s) = %v, want %v", tt.actor, tt.action, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanErrors(t *testing.T) {
+	a := newTestAuthorizer()
+	ctx := context.Background()
+
+	if _, err := a.Can(ctx, member, "community.unknown", Community(10)); err == nil {
+		t.Error("unknown action must fail")
+	}
+	if _, err := a.Can(ctx, member, BanUser, Host()); err == nil {
+		t.Error("community action without community must fail")
+	}
+	if _, err := a.Can(ctx, member, BanUser, Community(404)); err == nil {
+		t.Error("missing community must fail")
+	}
+	err := a.Require(ctx, member, BanUser, Community(10))
+	if !errors.Is(err, ErrForbidden) {
+		t.Errorf("Require = %v, want ErrForbidden", err)
+	}
+	if err := a.Require(ctx, communityOwner, BanUser, Community(10)); err != nil {
+		t.Errorf("Require = %v, want nil", err)
+	}
+}
+
+func TestPermissions(t *testing.T) {
+	a := newTestAuthorizer()
+	ctx := context.Background()
+
+	p, err := a.Permissions(ctx, communityOwner, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.CommunityOwner || p.HostOwner || !p.CommunityRolesManagement || !p.CommunityDeleteComments {
+		t.Errorf("owner permissions = %+v", p)
+	}
+
+	p, err = a.Permissions(ctx, restrictedModerator, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.CommunityUserBan || p.CommunityUserMute || p.CommunityOwner {
+		t.Errorf("restricted moderator permissions = %+v", p)
+	}
+
+	p, err = a.Permissions(ctx, hostModerator, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.CommunityUserBan || !p.CommunityDeletePost || p.CommunityUserMute {
+		t.Errorf("host moderator permissions = %+v", p)
+	}
+	if !slices.Equal(p.Permissions, []string{string(BanUser), string(DeletePost)}) {
+		t.Errorf("host moderator permission keys = %v", p.Permissions)
+	}
+}
+
+func TestParseActions(t *testing.T) {
+	if _, err := ParseActions([]string{string(BanUser), string(HostMuteUser)}); err != nil {
+		t.Errorf("ParseActions: %v", err)
+	}
+	if _, err := ParseActions([]string{"community.user.kick"}); err == nil {
+		t.Error("unknown action must fail")
+	}
+}
+
+func TestParsePermissions(t *testing.T) {
+	got, err := ParsePermissions(ScopeCommunity, []string{string(BanUser), string(MuteUser), string(BanUser)})
+	if err != nil {
+		t.Fatalf("ParsePermissions: %v", err)
+	}
+	if !slices.Equal(got, []string{string(BanUser), string(MuteUser)}) {
+		t.Errorf("ParsePermissions = %v, want duplicates removed", got)
+	}
+	if _, err := ParsePermissions(ScopeCommunity, []string{string(HostBanUser)}); err == nil {
+		t.Error("host permission in community role must fail")
+	}
+	if _, err := ParsePermissions(ScopeHost, []string{string(HostBanUser), string(BanUser)}); err != nil {
+		t.Errorf("host role may hold community permissions: %v", err)
+	}
+}
+
+func TestDefinitions(t *testing.T) {
+	all := Definitions("")
+	if len(all) != len(Definitions(ScopeHost))+len(Definitions(ScopeCommunity)) {
+		t.Error("every permission must belong to host or community scope")
+	}
+	seen := map[Action]bool{}
+	for _, d := range all {
+		if seen[d.Key] {
+			t.Errorf("duplicate permission %s", d.Key)
+		}
+		seen[d.Key] = true
+		if d.Description == "" {
+			t.Errorf("%s has no description", d.Key)
+		}
+	}
+}
+
+func TestFromLegacy(t *testing.T) {
+	got := FromLegacy(LegacyHostRoleColumns, map[string]bool{
+		"host_user_ban":                  true,
+		"host_user_mute":                 false,
+		"host_community_delete_comments": true,
+	})
+	want := []string{string(BanUser), string(DeleteComment), string(HostBanUser)}
+	if !slices.Equal(got, want) {
+		t.Errorf("FromLegacy = %v, want %v", got, want)
+	}
+	for _, columns := range []map[string][]Action{LegacyRoleColumns, LegacyHostRoleColumns} {
+		for col, actions := range columns {
+			for _, a := range actions {
+				if _, ok := Lookup(a); !ok {
+					t.Errorf("%s maps to unknown permission %s", col, a)
+				}
+			}
+		}
+	}
+}
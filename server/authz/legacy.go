@@ -0,0 +1,43 @@
+package authz
+
+// Булевы колонки прав, которые были у ролей до набора permissions, и права, в которые они
+// переходят. Используется только миграцией данных: колонки переносятся и удаляются.
+var (
+	LegacyRoleColumns = map[string][]Action{
+		"community_roles_management":             {ManageCommunity, ManageRoles},
+		"community_user_ban":                     {BanUser},
+		"community_user_mute":                    {MuteUser},
+		"community_delete_post":                  {DeletePost},
+		"community_remove_post_from_publication": {UnpublishPost},
+		"community_delete_comments":              {DeleteComment},
+	}
+	// Права роли платформы действовали и в сообществах, поэтому переходят в оба уровня
+	LegacyHostRoleColumns = map[string][]Action{
+		"community_roles_management":                  {ManageCommunity, ManageRoles},
+		"host_user_ban":                               {HostBanUser, BanUser},
+		"host_user_mute":                              {HostMuteUser, MuteUser},
+		"host_community_delete_post":                  {HostBanCommunity, HostMuteCommunity, DeletePost},
+		"host_community_remove_post_from_publication": {UnpublishPost},
+		"host_community_delete_comments":              {DeleteComment},
+	}
+)
+
+// FromLegacy собирает набор прав по включенным булевым колонкам в порядке реестра
+func FromLegacy(columns map[string][]Action, enabled map[string]bool) []string {
+	granted := map[Action]bool{}
+	for col, on := range enabled {
+		if !on {
+			continue
+		}
+		for _, a := range columns[col] {
+			granted[a] = true
+		}
+	}
+	out := []string{}
+	for _, d := range registry {
+		if granted[d.Key] {
+			out = append(out, string(d.Key))
+		}
+	}
+	return out
+}
@@ -0,0 +1,66 @@
+package authz
+
+import (
+	"context"
+
+	"stormlink/server/ent"
+	"stormlink/server/ent/role"
+	"stormlink/server/ent/user"
+)
+
+// Store загружает владельцев и роли, из которых Authorizer выводит права.
+// В тестах подменяется данными в памяти.
+type Store interface {
+	// HostOwnerID — владелец платформы; 0, если не задан
+	HostOwnerID(ctx context.Context) (int, error)
+	CommunityOwnerID(ctx context.Context, communityID int) (int, error)
+	HostRoles(ctx context.Context, userID int) ([]*ent.HostRole, error)
+	CommunityRoles(ctx context.Context, userID, communityID int) ([]*ent.Role, error)
+}
+
+type entStore struct {
+	client *ent.Client
+}
+
+// NewEntStore — Store поверх базы
+func NewEntStore(client *ent.Client) Store {
+	return &entStore{client: client}
+}
+
+func (s *entStore) HostOwnerID(ctx context.Context) (int, error) {
+	h, err := s.client.Host.Get(ctx, 1)
+	if ent.IsNotFound(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if h.OwnerID == nil {
+		return 0, nil
+	}
+	return *h.OwnerID, nil
+}
+
+func (s *entStore) CommunityOwnerID(ctx context.Context, communityID int) (int, error) {
+	cm, err := s.client.Community.Get(ctx, communityID)
+	if err != nil {
+		return 0, err
+	}
+	return cm.OwnerID, nil
+}
+
+func (s *entStore) HostRoles(ctx context.Context, userID int) ([]*ent.HostRole, error) {
+	return s.client.User.Query().
+		Where(user.IDEQ(userID)).
+		QueryHostRoles().
+		All(ctx)
+}
+
+func (s *entStore) CommunityRoles(ctx context.Context, userID, communityID int) ([]*ent.Role, error) {
+	return s.client.Role.Query().
+		Where(
+			role.CommunityIDEQ(communityID),
+			role.HasUsersWith(user.IDEQ(userID)),
+		).
+		All(ctx)
+}
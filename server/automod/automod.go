@@ -0,0 +1,325 @@
+// Package automod — правила автомодерации, которые проверяют посты и комментарии до записи.
+//
+// Действуют включенные правила сообщества и правила платформы (без сообщества). Если сработало
+// несколько правил, применяется самое строгое действие: mute > reject > hold > report.
+// Движок только выносит вердикт; применяют его резолверы.
+package automod
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"stormlink/server/ent"
+	"stormlink/server/ent/automodrule"
+	notificationuc "stormlink/server/usecase/notification"
+)
+
+// Content — проверяемая публикация
+type Content struct {
+	AuthorID    int
+	CommunityID int
+	// Text — весь текст публикации: заголовок и текст поста или текст комментария
+	Text string
+	// SkipCommentID и SkipPostID — правка комментария или поста: его прежний текст
+	// не считается повтором
+	SkipCommentID int
+	SkipPostID    int
+}
+
+// Match — сработавшее правило и почему
+type Match struct {
+	Rule   *ent.AutomodRule
+	Reason string
+}
+
+// Verdict — итог проверки; Rule == nil — контент пропущен
+type Verdict struct {
+	Matches []Match
+	// Rule и Action — правило с самым строгим действием
+	Rule   *ent.AutomodRule
+	Action automodrule.Action
+}
+
+// Reason — причина срабатывания решающего правила
+func (v *Verdict) Reason() string {
+	for _, m := range v.Matches {
+		if m.Rule == v.Rule {
+			return m.Reason
+		}
+	}
+	return ""
+}
+
+// severity — порядок строгости действий
+var severity = map[automodrule.Action]int{
+	automodrule.ActionReport: 1,
+	automodrule.ActionHold:   2,
+	automodrule.ActionReject: 3,
+	automodrule.ActionMute:   4,
+}
+
+// Defaults для правил без threshold
+const (
+	// DefaultRepeatWindow — окно поиска повторов
+	DefaultRepeatWindow = 10 * time.Minute
+	// DefaultRepeats — сколько одинаковых публикаций в окне уже было, чтобы следующая считалась повтором
+	DefaultRepeats = 1
+)
+
+// Engine проверяет контент правилами из Store
+type Engine struct {
+	store Store
+	now   func() time.Time
+
+	mu sync.Mutex
+	// patterns — скомпилированные шаблоны regex-правил по ID правила
+	patterns map[int]compiled
+}
+
+// compiled — шаблоны regex-правила в версии updatedAt
+type compiled struct {
+	updatedAt time.Time
+	res       []*regexp.Regexp
+}
+
+func New(store Store) *Engine {
+	return &Engine{store: store, now: time.Now, patterns: map[int]compiled{}}
+}
+
+// Evaluate проверяет контент действующими правилами сообщества и платформы
+func (e *Engine) Evaluate(ctx context.Context, c Content) (*Verdict, error) {
+	rules, err := e.store.Rules(ctx, c.CommunityID)
+	if err != nil {
+		return nil, fmt.Errorf("automod rules: %w", err)
+	}
+	return e.Test(ctx, c, rules)
+}
+
+// Test проверяет контент заданными правилами, в том числе несохраненными (пробный прогон)
+func (e *Engine) Test(ctx context.Context, c Content, rules []*ent.AutomodRule) (*Verdict, error) {
+	v := &Verdict{Matches: []Match{}}
+	var author *ent.User
+	for _, rule := range rules {
+		reason, err := e.match(ctx, rule, c, &author)
+		if err != nil {
+			return nil, err
+		}
+		if reason == "" {
+			continue
+		}
+		v.Matches = append(v.Matches, Match{Rule: rule, Reason: reason})
+		if v.Rule == nil || severity[rule.Action] > severity[v.Action] {
+			v.Rule, v.Action = rule, rule.Action
+		}
+	}
+	return v, nil
+}
+
+// match возвращает причину срабатывания правила или пустую строку; автор загружается один раз
+func (e *Engine) match(ctx context.Context, rule *ent.AutomodRule, c Content, author **ent.User) (string, error) {
+	loadAuthor := func() (*ent.User, error) {
+		if *author == nil {
+			u, err := e.store.Author(ctx, c.AuthorID)
+			if err != nil {
+				return nil, fmt.Errorf("automod author: %w", err)
+			}
+			*author = u
+		}
+		return *author, nil
+	}
+
+	switch rule.Kind {
+	case automodrule.KindKeywords:
+		words := " " + strings.Join(Words(c.Text), " ") + " "
+		for _, kw := range rule.Patterns {
+			if k := strings.Join(Words(kw), " "); k != "" && strings.Contains(words, " "+k+" ") {
+				return fmt.Sprintf("запрещенное слово «%s»", kw), nil
+			}
+		}
+	case automodrule.KindRegex:
+		res, err := e.regexps(rule)
+		if err != nil {
+			return "", fmt.Errorf("automod rule %d: %w", rule.ID, err)
+		}
+		for _, re := range res {
+			if re.MatchString(c.Text) {
+				return fmt.Sprintf("совпадение с шаблоном %s", re), nil
+			}
+		}
+	case automodrule.KindLinkDomains:
+		for _, host := range LinkHosts(c.Text) {
+			for _, d := range rule.Patterns {
+				if d = normalizeHost(d); d != "" && (host == d || strings.HasSuffix(host, "."+d)) {
+					return fmt.Sprintf("ссылка на запрещенный домен %s", d), nil
+				}
+			}
+		}
+	case automodrule.KindAccountAge:
+		u, err := loadAuthor()
+		if err != nil {
+			return "", err
+		}
+		minAge := time.Duration(threshold(rule, 0)) * time.Hour
+		if e.now().Sub(u.CreatedAt) < minAge {
+			return fmt.Sprintf("аккаунт моложе %d ч", threshold(rule, 0)), nil
+		}
+	case automodrule.KindVerifiedOnly:
+		u, err := loadAuthor()
+		if err != nil {
+			return "", err
+		}
+		if !u.IsVerified {
+			return "email автора не подтвержден", nil
+		}
+	case automodrule.KindMaxMentions:
+		if n := len(notificationuc.ParseMentions(c.Text)); n > threshold(rule, 0) {
+			return fmt.Sprintf("упоминаний больше %d", threshold(rule, 0)), nil
+		}
+	case automodrule.KindMaxLinks:
+		if n := len(LinkHosts(c.Text)); n > threshold(rule, 0) {
+			return fmt.Sprintf("ссылок больше %d", threshold(rule, 0)), nil
+		}
+	case automodrule.KindRepeatedContent:
+		text := strings.Join(Words(c.Text), " ")
+		if text == "" {
+			return "", nil
+		}
+		window := DefaultRepeatWindow
+		if rule.WindowMinutes != nil {
+			window = time.Duration(*rule.WindowMinutes) * time.Minute
+		}
+		recent, err := e.store.RecentTexts(ctx, c.AuthorID, e.now().Add(-window), c.SkipCommentID, c.SkipPostID)
+		if err != nil {
+			return "", fmt.Errorf("automod recent texts: %w", err)
+		}
+		repeats := 0
+		for _, t := range recent {
+			if strings.Join(Words(t), " ") == text {
+				repeats++
+			}
+		}
+		if repeats >= threshold(rule, DefaultRepeats) {
+			return "повтор недавней публикации", nil
+		}
+	}
+	return "", nil
+}
+
+// regexps — скомпилированные шаблоны правила. Сохраненное правило компилируется один раз
+// на версию (updated_at), несохраненное (пробный прогон) — при каждой проверке.
+func (e *Engine) regexps(rule *ent.AutomodRule) ([]*regexp.Regexp, error) {
+	if rule.ID != 0 {
+		e.mu.Lock()
+		c, ok := e.patterns[rule.ID]
+		e.mu.Unlock()
+		if ok && c.updatedAt.Equal(rule.UpdatedAt) {
+			return c.res, nil
+		}
+	}
+	res := make([]*regexp.Regexp, len(rule.Patterns))
+	for i, p := range rule.Patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = re
+	}
+	if rule.ID != 0 {
+		e.mu.Lock()
+		e.patterns[rule.ID] = compiled{updatedAt: rule.UpdatedAt, res: res}
+		e.mu.Unlock()
+	}
+	return res, nil
+}
+
+func threshold(rule *ent.AutomodRule, def int) int {
+	if rule.Threshold == nil {
+		return def
+	}
+	return int(*rule.Threshold)
+}
+
+// Validate проверяет параметры правила перед сохранением
+func Validate(kind automodrule.Kind, patterns []string, threshold, windowMinutes *int32) error {
+	switch kind {
+	case automodrule.KindKeywords, automodrule.KindRegex, automodrule.KindLinkDomains:
+		if len(patterns) == 0 {
+			return fmt.Errorf("%s rule requires patterns", kind)
+		}
+	case automodrule.KindAccountAge, automodrule.KindMaxMentions, automodrule.KindMaxLinks:
+		if threshold == nil {
+			return fmt.Errorf("%s rule requires threshold", kind)
+		}
+	}
+	if kind == automodrule.KindRegex {
+		for _, p := range patterns {
+			if _, err := regexp.Compile(p); err != nil {
+				return fmt.Errorf("invalid pattern %q: %w", p, err)
+			}
+		}
+	}
+	if threshold != nil && *threshold < 0 {
+		return fmt.Errorf("threshold must not be negative")
+	}
+	if windowMinutes != nil && *windowMinutes <= 0 {
+		return fmt.Errorf("windowMinutes must be positive")
+	}
+	return nil
+}
+
+// Words — слова текста в нижнем регистре (буквы и цифры, остальное — разделители)
+func Words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+var linkRe = regexp.MustCompile(`(?i)\bhttps?://[^\s"'<>]+`)
+
+// LinkHosts — хосты всех ссылок текста (без www.)
+func LinkHosts(text string) []string {
+	var hosts []string
+	for _, raw := range linkRe.FindAllString(text, -1) {
+		u, err := url.Parse(raw)
+		if err != nil || u.Hostname() == "" {
+			continue
+		}
+		hosts = append(hosts, normalizeHost(u.Hostname()))
+	}
+	return hosts
+}
+
+func normalizeHost(h string) string {
+	return strings.TrimPrefix(strings.ToLower(strings.TrimSpace(h)), "www.")
+}
+
+// PostText — текст поста для проверки: заголовок и строки содержимого редактора
+// (служебные поля блоков id и type пропускаются)
+func PostText(title string, content map[string]any) string {
+	parts := []string{title}
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case string:
+			parts = append(parts, v)
+		case []any:
+			for _, x := range v {
+				walk(x)
+			}
+		case map[string]any:
+			for k, x := range v {
+				if k != "id" && k != "type" {
+					walk(x)
+				}
+			}
+		}
+	}
+	walk(content)
+	return strings.Join(parts, "\n")
+}
@@ -0,0 +1,153 @@
+package automod
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"stormlink/server/ent"
+	"stormlink/server/ent/automodrule"
+)
+
+// memStore — Store в памяти
+type memStore struct {
+	rules  []*ent.AutomodRule
+	author *ent.User
+	recent []string
+}
+
+func (m *memStore) Rules(context.Context, int) ([]*ent.AutomodRule, error) { return m.rules, nil }
+
+func (m *memStore) Author(context.Context, int) (*ent.User, error) { return m.author, nil }
+
+func (m *memStore) RecentTexts(context.Context, int, time.Time, int, int) ([]string, error) {
+	return m.recent, nil
+}
+
+func int32p(v int32) *int32 { return &v }
+
+func rule(id int, kind automodrule.Kind, action automodrule.Action) *ent.AutomodRule {
+	return &ent.AutomodRule{ID: id, Kind: kind, Action: action}
+}
+
+func TestEvaluate_Kinds(t *testing.T) {
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	keywords := rule(1, automodrule.KindKeywords, automodrule.ActionReject)
+	keywords.Patterns = []string{"казино", "free money"}
+	regex := rule(2, automodrule.KindRegex, automodrule.ActionReject)
+	regex.Patterns = []string{`\d{3}-\d{4}`}
+	domains := rule(3, automodrule.KindLinkDomains, automodrule.ActionReject)
+	domains.Patterns = []string{"spam.example"}
+	age := rule(4, automodrule.KindAccountAge, automodrule.ActionHold)
+	age.Threshold = int32p(24)
+	verified := rule(5, automodrule.KindVerifiedOnly, automodrule.ActionHold)
+	mentions := rule(6, automodrule.KindMaxMentions, automodrule.ActionReject)
+	mentions.Threshold = int32p(2)
+	links := rule(7, automodrule.KindMaxLinks, automodrule.ActionReject)
+	links.Threshold = int32p(1)
+	repeated := rule(8, automodrule.KindRepeatedContent, automodrule.ActionReject)
+
+	cases := []struct {
+		name   string
+		rule   *ent.AutomodRule
+		text   string
+		author *ent.User
+		recent []string
+		match  bool
+	}{
+		{"keyword", keywords, "Лучшее КАЗИНО тут", nil, nil, true},
+		{"keyword inside word", keywords, "казиноман", nil, nil, false},
+		{"phrase", keywords, "get free, money!", nil, nil, true},
+		{"regex", regex, "звоните 555-1234", nil, nil, true},
+		{"regex miss", regex, "звоните завтра", nil, nil, false},
+		{"subdomain", domains, "см. https://www.promo.spam.example/x", nil, nil, true},
+		{"other domain", domains, "см. https://notspam.example", nil, nil, false},
+		{"young account", age, "привет", &ent.User{CreatedAt: now.Add(-time.Hour)}, nil, true},
+		{"old account", age, "привет", &ent.User{CreatedAt: now.Add(-48 * time.Hour)}, nil, false},
+		{"unverified", verified, "привет", &ent.User{}, nil, true},
+		{"verified", verified, "привет", &ent.User{IsVerified: true}, nil, false},
+		{"mentions", mentions, "@ann @bob @cid", nil, nil, true},
+		{"mentions at limit", mentions, "@ann @bob @ann", nil, nil, false},
+		{"links", links, "https://a.example https://b.example", nil, nil, true},
+		{"repeat", repeated, "Купите  слона!", nil, []string{"купите слона"}, true},
+		{"no repeat", repeated, "Купите слона", nil, []string{"продам слона"}, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := New(&memStore{rules: []*ent.AutomodRule{tc.rule}, author: tc.author, recent: tc.recent})
+			e.now = func() time.Time { return now }
+			v, err := e.Evaluate(context.Background(), Content{AuthorID: 1, CommunityID: 10, Text: tc.text})
+			require.NoError(t, err)
+			if tc.match {
+				require.Len(t, v.Matches, 1)
+				assert.Equal(t, tc.rule, v.Rule)
+				assert.NotEmpty(t, v.Reason())
+			} else {
+				assert.Empty(t, v.Matches)
+				assert.Nil(t, v.Rule)
+			}
+		})
+	}
+}
+
+func TestEvaluate_StrictestActionWins(t *testing.T) {
+	report := rule(1, automodrule.KindKeywords, automodrule.ActionReport)
+	report.Patterns = []string{"спам"}
+	mute := rule(2, automodrule.KindKeywords, automodrule.ActionMute)
+	mute.Patterns = []string{"спам"}
+	hold := rule(3, automodrule.KindKeywords, automodrule.ActionHold)
+	hold.Patterns = []string{"спам"}
+
+	v, err := New(&memStore{rules: []*ent.AutomodRule{report, mute, hold}}).
+		Evaluate(context.Background(), Content{Text: "это спам"})
+	require.NoError(t, err)
+	assert.Len(t, v.Matches, 3)
+	assert.Equal(t, mute, v.Rule)
+	assert.Equal(t, automodrule.ActionMute, v.Action)
+}
+
+func TestEvaluate_RegexRecompiledOnUpdate(t *testing.T) {
+	regex := rule(1, automodrule.KindRegex, automodrule.ActionReject)
+	regex.Patterns = []string{`казино`}
+	e := New(&memStore{rules: []*ent.AutomodRule{regex}})
+
+	v, err := e.Evaluate(context.Background(), Content{Text: "лото"})
+	require.NoError(t, err)
+	assert.Nil(t, v.Rule)
+
+	// Та же версия правила — шаблоны берутся из кеша
+	regex.Patterns = []string{`лото`}
+	v, err = e.Evaluate(context.Background(), Content{Text: "лото"})
+	require.NoError(t, err)
+	assert.Nil(t, v.Rule)
+
+	regex.UpdatedAt = time.Now()
+	v, err = e.Evaluate(context.Background(), Content{Text: "лото"})
+	require.NoError(t, err)
+	assert.Equal(t, regex, v.Rule)
+}
+
+func TestValidate(t *testing.T) {
+	assert.Error(t, Validate(automodrule.KindKeywords, nil, nil, nil))
+	assert.Error(t, Validate(automodrule.KindRegex, []string{"("}, nil, nil))
+	assert.Error(t, Validate(automodrule.KindMaxLinks, nil, nil, nil))
+	assert.Error(t, Validate(automodrule.KindRepeatedContent, nil, nil, int32p(0)))
+	assert.NoError(t, Validate(automodrule.KindRegex, []string{`^\w+$`}, nil, nil))
+	assert.NoError(t, Validate(automodrule.KindVerifiedOnly, nil, nil, nil))
+}
+
+func TestPostText(t *testing.T) {
+	text := PostText("Заголовок", map[string]any{
+		"blocks": []any{
+			map[string]any{"id": "abc", "type": "paragraph", "data": map[string]any{"text": "первый"}},
+			map[string]any{"id": "def", "type": "list", "data": map[string]any{"items": []any{"второй"}}},
+		},
+	})
+	assert.Contains(t, text, "Заголовок")
+	assert.Contains(t, text, "первый")
+	assert.Contains(t, text, "второй")
+	assert.NotContains(t, text, "paragraph")
+}
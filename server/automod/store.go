@@ -0,0 +1,76 @@
+package automod
+
+import (
+	"context"
+	"time"
+
+	"stormlink/server/ent"
+	"stormlink/server/ent/automodrule"
+	"stormlink/server/ent/comment"
+	"stormlink/server/ent/post"
+)
+
+// Store загружает правила и данные для их проверки. В тестах подменяется данными в памяти.
+type Store interface {
+	// Rules — включенные правила сообщества и платформы
+	Rules(ctx context.Context, communityID int) ([]*ent.AutomodRule, error)
+	Author(ctx context.Context, userID int) (*ent.User, error)
+	// RecentTexts — тексты постов и комментариев автора начиная с since, кроме комментария
+	// skipCommentID и поста skipPostID
+	RecentTexts(ctx context.Context, authorID int, since time.Time, skipCommentID, skipPostID int) ([]string, error)
+}
+
+type entStore struct {
+	client *ent.Client
+}
+
+// NewEntStore — Store поверх базы
+func NewEntStore(client *ent.Client) Store {
+	return &entStore{client: client}
+}
+
+func (s *entStore) Rules(ctx context.Context, communityID int) ([]*ent.AutomodRule, error) {
+	return s.client.AutomodRule.Query().
+		Where(
+			automodrule.EnabledEQ(true),
+			automodrule.Or(automodrule.CommunityIDIsNil(), automodrule.CommunityIDEQ(communityID)),
+		).
+		Order(ent.Asc(automodrule.FieldID)).
+		All(ctx)
+}
+
+func (s *entStore) Author(ctx context.Context, userID int) (*ent.User, error) {
+	return s.client.User.Get(ctx, userID)
+}
+
+func (s *entStore) RecentTexts(ctx context.Context, authorID int, since time.Time, skipCommentID, skipPostID int) ([]string, error) {
+	comments, err := s.client.Comment.Query().
+		Where(
+			comment.AuthorIDEQ(authorID),
+			comment.CreatedAtGTE(since),
+			comment.HasDeletedEQ(false),
+			comment.IDNEQ(skipCommentID),
+		).
+		Select(comment.FieldContent).
+		Strings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	posts, err := s.client.Post.Query().
+		Where(
+			post.AuthorIDEQ(authorID),
+			post.CreatedAtGTE(since),
+			post.VisibilityNEQ(post.VisibilityDeleted),
+			post.IDNEQ(skipPostID),
+		).
+		Select(post.FieldTitle, post.FieldContent).
+		All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := comments
+	for _, p := range posts {
+		out = append(out, PostText(p.Title, p.Content))
+	}
+	return out, nil
+}
@@ -68,6 +68,16 @@ func getEnvInt(key string, defaultValue int) int {
 func MigrateDB(client *ent.Client, reset bool, seed bool) {
 	if reset {
 		log.Println("⚠️  Полный сброс базы данных с удалением колонок и индексов...")
+		// Данные из устаревших колонок переносятся до того, как сброс их удалит
+		if err := client.Schema.Create(context.Background()); err != nil {
+			log.Fatalf("ошибка миграции схемы: %v", err)
+		}
+		if err := migrateNotificationPreferences(context.Background(), client); err != nil {
+			log.Fatalf("ошибка переноса категорий писем: %v", err)
+		}
+		if err := migrateRolePermissions(context.Background(), client); err != nil {
+			log.Fatalf("ошибка переноса прав ролей: %v", err)
+		}
 		if err := client.Schema.Create(
 			context.Background(),
 			schema.WithDropIndex(true),
@@ -83,6 +93,15 @@ func MigrateDB(client *ent.Client, reset bool, seed bool) {
 			log.Fatalf("ошибка миграции схемы: %v", err)
 		}
 		log.Println("✅ Миграция завершена.")
+		if err := migrateRolePermissions(context.Background(), client); err != nil {
+			log.Fatalf("ошибка переноса прав ролей: %v", err)
+		}
+		if err := migrateNotificationPreferences(context.Background(), client); err != nil {
+			log.Fatalf("ошибка переноса категорий писем: %v", err)
+		}
+		if err := backfillNotificationSettings(context.Background(), client); err != nil {
+			log.Fatalf("ошибка создания настроек уведомлений: %v", err)
+		}
 		log.Println("🌱 Выполняется сидинг...")
 		if err := Seed(client); err != nil {
 			log.Fatalf("❌ Ошибка сидинга: %v", err)
@@ -94,5 +113,14 @@ func MigrateDB(client *ent.Client, reset bool, seed bool) {
 			log.Fatalf("ошибка миграции схемы: %v", err)
 		}
 		log.Println("✅ Миграция завершена.")
+		if err := migrateRolePermissions(context.Background(), client); err != nil {
+			log.Fatalf("ошибка переноса прав ролей: %v", err)
+		}
+		if err := migrateNotificationPreferences(context.Background(), client); err != nil {
+			log.Fatalf("ошибка переноса категорий писем: %v", err)
+		}
+		if err := backfillNotificationSettings(context.Background(), client); err != nil {
+			log.Fatalf("ошибка создания настроек уведомлений: %v", err)
+		}
 	}
 }
@@ -4,9 +4,9 @@ package modules
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"io"
 	"log"
-	"net"
 	"net/http"
 	"os"
 	"strconv"
@@ -16,7 +16,6 @@ import (
 
 	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/99designs/gqlgen/graphql/handler/extension"
-	"github.com/99designs/gqlgen/graphql/handler/lru"
 	"github.com/99designs/gqlgen/graphql/handler/transport"
 	"github.com/99designs/gqlgen/graphql/playground"
 	"github.com/gorilla/websocket"
@@ -24,25 +23,44 @@ import (
 	"github.com/vektah/gqlparser/v2/gqlerror"
 	"golang.org/x/time/rate"
 
+	"stormlink/server/authz"
+	"stormlink/server/automod"
 	"stormlink/server/ent"
 	"stormlink/server/graphql"
+	"stormlink/server/graphql/cost"
+	"stormlink/server/graphql/dataloader"
+	"stormlink/server/graphql/persisted"
+	"stormlink/server/graphql/respcache"
 	authpb "stormlink/server/grpc/auth/protobuf"
 	mailpb "stormlink/server/grpc/mail/protobuf"
 	mediapb "stormlink/server/grpc/media/protobuf"
 	userpb "stormlink/server/grpc/user/protobuf"
+	"stormlink/server/ipban"
 	"stormlink/server/middleware"
+	"stormlink/server/sanctions"
+	"stormlink/server/shadow"
+	automodruleuc "stormlink/server/usecase/automodrule"
 	banuc "stormlink/server/usecase/ban"
+	banappealuc "stormlink/server/usecase/banappeal"
 	commentuc "stormlink/server/usecase/comment"
 	communityuc "stormlink/server/usecase/community"
 	communityroleuc "stormlink/server/usecase/communityrole"
 	communityruleuc "stormlink/server/usecase/communityrule"
+	hostipbanuc "stormlink/server/usecase/hostipban"
 	hostmuteuc "stormlink/server/usecase/hostmute"
 	hostroleuc "stormlink/server/usecase/hostrole"
 	hostruleuc "stormlink/server/usecase/hostrule"
+	moderationloguc "stormlink/server/usecase/moderationlog"
+	notificationuc "stormlink/server/usecase/notification"
+	notificationsettingsuc "stormlink/server/usecase/notificationsettings"
 	postuc "stormlink/server/usecase/post"
+	reportuc "stormlink/server/usecase/report"
 	useruc "stormlink/server/usecase/user"
+	usersessionuc "stormlink/server/usecase/usersession"
 	errorsx "stormlink/shared/errors"
 	httpWithCookies "stormlink/shared/http"
+	"stormlink/shared/presence"
+	"stormlink/shared/pubsub"
 
 	"stormlink/server/usecase/profiletableinfoitem"
 
@@ -61,20 +79,6 @@ var ipLimiters = struct {
     limiters map[string]*rate.Limiter
 }{limiters: make(map[string]*rate.Limiter)}
 
-func getClientIP(r *http.Request) string {
-    // X-Forwarded-For first
-    xf := r.Header.Get("X-Forwarded-For")
-    if xf != "" {
-        parts := strings.Split(xf, ",")
-        return strings.TrimSpace(parts[0])
-    }
-    host, _, err := net.SplitHostPort(r.RemoteAddr)
-    if err != nil {
-        return r.RemoteAddr
-    }
-    return host
-}
-
 func allowOrigin(origin string) bool {
     allowed := os.Getenv("FRONTEND_ORIGIN")
     if allowed == "" {
@@ -108,6 +112,13 @@ func StartGraphQLServer(client *ent.Client) {
     hostMuteUC := hostmuteuc.NewHostMuteUsecase(client)
     banUC := banuc.NewBanUsecase(client)
     profileTableInfoItemUC := profiletableinfoitem.NewProfileTableInfoItemUsecase(client)
+    notificationSettingsUC := notificationsettingsuc.NewNotificationSettingsUsecase(client)
+    notificationUC := notificationuc.NewNotificationUsecase(client)
+    moderationLogUC := moderationloguc.NewModerationLogUsecase(client)
+    reportUC := reportuc.NewReportUsecase(client)
+    automodRuleUC := automodruleuc.NewAutomodRuleUsecase(client)
+    banAppealUC := banappealuc.NewBanAppealUsecase(client)
+    hostIPBanUC := hostipbanuc.NewHostIPBanUsecase(client)
 
     // gRPC-клиенты к микросервисам (адреса из ENV)
     get := func(key, def string) string { v := os.Getenv(key); if v == "" { return def }; return v }
@@ -132,7 +143,15 @@ func StartGraphQLServer(client *ent.Client) {
     mediaConn, err := grpc.DialContext(context.Background(), get("MEDIA_GRPC_ADDR", "localhost:4004"), creds)
     if err != nil { log.Fatalf("❌ MEDIA gRPC dial: %v", err) }
 
-    upstreamClosers = []io.Closer{authConn, userConn, mailConn, mediaConn}
+    // Брокер подписок: с PUBSUB_BROKER=redis события доходят до клиентов на всех репликах
+    broker, err := pubsub.NewFromEnv()
+    if err != nil { log.Fatalf("❌ pubsub broker: %v", err) }
+
+    upstreamClosers = []io.Closer{authConn, userConn, mailConn, mediaConn, broker}
+
+    // Кеш санкций: с SANCTIONS_CACHE=redis (по умолчанию) снятие бана видно сразу на всех репликах
+    sanctionsCache, err := sanctions.CacheFromEnv()
+    if err != nil { log.Fatalf("❌ sanctions cache: %v", err) }
 
     authClient := authpb.NewAuthServiceClient(authConn)
     userClient := userpb.NewUserServiceClient(userConn)
@@ -142,6 +161,13 @@ func StartGraphQLServer(client *ent.Client) {
     // Инициализируем HTTPAuthMiddleware (валидация токена удалённо)
     middleware.InitHTTPAuthMiddleware(authClient)
 
+    // Теневые санкции: посты, комментарии и лайки наказанных видят только они сами и модераторы.
+    // Фильтр стоит на клиенте ent, поэтому действует во всех запросах, счетчиках и подписках.
+    authorizer := authz.New(authz.NewEntStore(client))
+    shadow.Register(client, shadow.Moderators(client, authorizer))
+    // Баны по IP: общий список действующих банов перечитывается раз в 30 секунд
+    ipBans := ipban.New(ipban.NewEntStore(client))
+
     // Резолверы
     resolver := &graphql.Resolver{
         Client:          client,
@@ -160,12 +186,34 @@ func StartGraphQLServer(client *ent.Client) {
         MailClient:      mailClient,
         MediaClient:     mediaClient,
         ProfileTableInfoItemUC: profileTableInfoItemUC,
+        NotificationSettingsUC: notificationSettingsUC,
+        NotificationUC:         notificationUC,
+        ModerationLogUC:        moderationLogUC,
+        ReportUC:               reportUC,
+        AutomodRuleUC:          automodRuleUC,
+        BanAppealUC:            banAppealUC,
+        HostIPBanUC:            hostIPBanUC,
+        Broker:                 broker,
+        Presence:               presence.New(broker),
+        Authz:                  authorizer,
+        Sanctions:              sanctions.New(sanctions.NewEntStore(client), sanctionsCache),
+        Automod:                automod.New(automod.NewEntStore(client)),
+        IPBans:                 ipBans,
+    }
+    // Учет входов по IP для связанных аккаунтов (опционально: хранит адреса пользователей)
+    if os.Getenv("ASSOCIATED_ACCOUNTS_ENABLE") == "true" {
+        resolver.UserSessionUC = usersessionuc.NewUserSessionUsecase(client)
     }
 
     // 5) Конфигурируем gqlgen‑сервер вручную (не NewDefaultServer)
     srv := handler.New(graphql.NewExecutableSchema(graphql.Config{Resolvers: resolver}))
     // Нормализованный presenter ошибок (глобально для GraphQL)
     srv.SetErrorPresenter(func(ctx context.Context, err error) *gqlerror.Error {
+        // Ошибки, которые резолвер уже снабдил кодом (например, отказ по санкциям), отдаем как есть
+        var gqlErr *gqlerror.Error
+        if errors.As(err, &gqlErr) && gqlErr.Extensions["code"] != nil {
+            return gqlErr
+        }
         // Специальный маппинг ent.NotFound → GraphQL code=NotFound
         if ent.IsNotFound(err) {
             e := gqlerror.Errorf("not found")
@@ -192,23 +240,54 @@ func StartGraphQLServer(client *ent.Client) {
     if os.Getenv("ENV") != "production" {
         srv.Use(extension.Introspection{})
     }
-    // Complexity limit (из ENV или по умолчанию)
-    maxComplexity := 300
-    if v := os.Getenv("GRAPHQL_MAX_COMPLEXITY"); v != "" {
-        if n, err := strconv.Atoi(v); err == nil && n > 0 { maxComplexity = n }
+    // Стоимость операций по @cost и скользящий бюджет на пользователя или IP (вместо лимита запросов в секунду)
+    costLimit, err := cost.FromEnv(func(ctx context.Context) string {
+        if r := httpWithCookies.GetHTTPRequest(ctx); r != nil { return httpWithCookies.ClientIP(r) }
+        return ""
+    })
+    if err != nil { log.Fatalf("❌ cost limit: %v", err) }
+    srv.Use(costLimit)
+    // Мутации с забаненного IP отклоняются; персонал платформы с правом бана может снять бан со своего адреса
+    srv.Use(ipban.Guard{Exempt: func(ctx context.Context, userID int) (bool, error) {
+        return authorizer.Can(ctx, userID, authz.HostBanUser, authz.Host())
+    }})
+    // Кеш ответов по @cacheControl (анонимам и на PUBLIC-поля); мутации сбрасывают его по тегам через resolver.Cache
+    responseCache, err := respcache.FromEnv()
+    if err != nil { log.Fatalf("❌ response cache: %v", err) }
+    if responseCache != nil {
+        srv.Use(responseCache)
+        resolver.Cache = responseCache
     }
-    srv.Use(extension.FixedComplexityLimit(maxComplexity))
-    // APQ
-    srv.Use(extension.AutomaticPersistedQuery{Cache: lru.New[string](1000)})
+    // Persisted queries: APQ (кеш в памяти или общий в Redis) или, в strict-режиме, только операции из манифеста фронтенда
+    persistedQueries, err := persisted.ExtensionFromEnv(context.Background())
+    if err != nil { log.Fatalf("❌ persisted queries: %v", err) }
+    srv.Use(persistedQueries)
+    // extensions.eventId в сообщениях подписок (для продолжения с since после переподключения)
+    srv.Use(graphql.SubscriptionEventIDs{})
+    // Dataloader: связи и счётчики элементов списков грузятся пакетно, по запросу на тип данных
+    srv.AroundOperations(dataloader.Middleware(client))
+    // Права на теневой контент проверяются один раз на запрос, а не на каждую выборку постов
+    srv.AroundOperations(shadow.Middleware())
+
+	// 5a) SSE (graphql-sse) — регистрируем раньше POST/GET: они тоже подходят под запросы с Accept: text/event-stream
+	maxStreams := 10
+	if v := os.Getenv("GRAPHQL_SSE_MAX_STREAMS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 { maxStreams = n }
+	}
+	srv.AddTransport(&graphql.SSETransport{
+		KeepAlive:           15 * time.Second,
+		MaxStreamsPerClient: maxStreams,
+		ClientKey:           httpWithCookies.ClientIP,
+	})
 
-	// 5a) HTTP POST и GET
+	// 5b) HTTP POST и GET
 	srv.AddTransport(transport.POST{})
 	srv.AddTransport(transport.GET{})
 
-	// 5b) (Опционально) multipart form (upload)
+	// 5c) (Опционально) multipart form (upload)
 	srv.AddTransport(transport.MultipartForm{})
 
-    // 5c) WebSocket для подписок
+    // 5d) WebSocket для подписок
     srv.AddTransport(&transport.Websocket{
         Upgrader: websocket.Upgrader{
             CheckOrigin: func(r *http.Request) bool {
@@ -269,45 +348,53 @@ func StartGraphQLServer(client *ent.Client) {
         mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) { http.NotFound(w, r) })
     }
 	// GraphQL endpoint с улучшенной безопасностью
+	// Бан IP клиента кладется в контекст до авторизации: его проверяет ipban.Guard
+	queryHandler := middleware.IPBanMiddleware(ipBans)(middleware.HTTPAuthMiddleware(srv))
 	graphqlHandler := middleware.SecurityAuditMiddleware(
 		middleware.AuditMiddleware(
-			middleware.RateLimitMiddleware(
-				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-					// CSRF Origin check для POST
-					if r.Method == http.MethodPost {
-						if !allowOrigin(r.Header.Get("Origin")) {
-							http.Error(w, "invalid origin", http.StatusForbidden)
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// Origin check для SSE-потоков по GET (EventSource отправляет Origin при кросс-доменных запросах)
+				if r.Method == http.MethodGet && strings.Contains(r.Header.Get("Accept"), "text/event-stream") && !allowOrigin(r.Header.Get("Origin")) {
+					http.Error(w, "invalid origin", http.StatusForbidden)
+					return
+				}
+				// CSRF Origin check для POST
+				if r.Method == http.MethodPost {
+					if !allowOrigin(r.Header.Get("Origin")) {
+						http.Error(w, "invalid origin", http.StatusForbidden)
+						return
+					}
+					// Дополнительный double-submit CSRF (опционально)
+					if os.Getenv("CSRF_ENABLE") == "true" {
+						c, err := r.Cookie("csrf_token")
+						tokenHeader := r.Header.Get("X-CSRF-Token")
+						if err != nil || c == nil || c.Value == "" || tokenHeader == "" || tokenHeader != c.Value {
+							http.Error(w, "invalid csrf token", http.StatusForbidden)
 							return
 						}
-						// Дополнительный double-submit CSRF (опционально)
-						if os.Getenv("CSRF_ENABLE") == "true" {
-							c, err := r.Cookie("csrf_token")
-							tokenHeader := r.Header.Get("X-CSRF-Token")
-							if err != nil || c == nil || c.Value == "" || tokenHeader == "" || tokenHeader != c.Value {
-								http.Error(w, "invalid csrf token", http.StatusForbidden)
-								return
-							}
-						}
-					}
-					// Лимит размера тела запроса (по умолчанию 1 МБ)
-					maxBody := int64(1 * 1024 * 1024)
-					if v := os.Getenv("GRAPHQL_MAX_BODY_BYTES"); v != "" {
-						if n, err := strconv.Atoi(v); err == nil && n > 0 { maxBody = int64(n) }
 					}
-					if r.Method == http.MethodPost {
-						r.Body = http.MaxBytesReader(w, r.Body, maxBody)
-					}
-					// Вставляем куки‑контекст и авторизацию
-					ctx := httpWithCookies.WithHTTPContext(r.Context(), w, r)
-					r = r.WithContext(ctx)
-					middleware.HTTPAuthMiddleware(srv).ServeHTTP(w, r)
-				}),
-			),
+				}
+				// Лимит размера тела запроса (по умолчанию 1 МБ)
+				maxBody := int64(1 * 1024 * 1024)
+				if v := os.Getenv("GRAPHQL_MAX_BODY_BYTES"); v != "" {
+					if n, err := strconv.Atoi(v); err == nil && n > 0 { maxBody = int64(n) }
+				}
+				if r.Method == http.MethodPost {
+					r.Body = http.MaxBytesReader(w, r.Body, maxBody)
+				}
+				// Вставляем куки‑контекст и авторизацию
+				ctx := httpWithCookies.WithHTTPContext(r.Context(), w, r)
+				r = r.WithContext(ctx)
+				queryHandler.ServeHTTP(w, r)
+			}),
 		),
 	)
 	
 	mux.Handle("/query", graphqlHandler)
 
+    // One-click отписка от писем (RFC 8058): ссылка из заголовка List-Unsubscribe
+    mux.Handle("/unsubscribe", NewUnsubscribeHandler(notificationSettingsUC))
+
     // Static storage proxy to S3 (инициализируем локальный клиент и передаем в handler)
     mux.HandleFunc("/storage/", NewStorageHandler(s3client))
 
@@ -334,7 +421,7 @@ func StartGraphQLServer(client *ent.Client) {
         IdleTimeout:       60 * time.Second,
         MaxHeaderBytes:    1 << 20, // 1MB
     }
-    log.Printf("🚀 GraphQL-сервер запущен на %s (HTTP и WS на /query, storage на /storage)", addr)
+    log.Printf("🚀 GraphQL-сервер запущен на %s (HTTP, WS и SSE на /query, storage на /storage)", addr)
     go func() {
         if err := httpSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
             log.Fatalf("❌ Ошибка при запуске GraphQL-сервера: %v", err)
@@ -0,0 +1,143 @@
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"stormlink/server/ent"
+	"stormlink/server/ent/notificationsettings"
+	"stormlink/server/ent/user"
+	"stormlink/server/model"
+	nsuc "stormlink/server/usecase/notificationsettings"
+)
+
+// migrateNotificationPreferences переносит булевы колонки email_* (категории писем до настроек
+// по типам событий) в preferences и удаляет колонки. Выключенная категория выключает письма
+// и сводку для своих типов, как отписка по ссылке; повторный запуск ничего не делает — колонок нет.
+func migrateNotificationPreferences(ctx context.Context, client *ent.Client) error {
+	legacy := make(map[string][]string, len(nsuc.LegacyCategories))
+	for category, types := range nsuc.LegacyCategories {
+		legacy["email_"+category] = types
+	}
+
+	tx, err := client.Tx(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	columns, err := legacyColumns(ctx, tx, notificationsettings.Table, legacy)
+	if err != nil || len(columns) == 0 {
+		return err
+	}
+
+	type row struct {
+		id          int
+		preferences model.NotificationPreferences
+	}
+	// Строки, где все категории включены, совпадают с настройками по умолчанию
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(
+		`SELECT id, COALESCE(preferences, '{}'), %s FROM %s WHERE NOT (%s)`,
+		strings.Join(columns, ", "), notificationsettings.Table, strings.Join(columns, " AND "),
+	))
+	if err != nil {
+		return err
+	}
+	var pending []row
+	for rows.Next() {
+		var (
+			id      int
+			current []byte
+			flags   = make([]bool, len(columns))
+		)
+		dest := []any{&id, &current}
+		for i := range flags {
+			dest = append(dest, &flags[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			rows.Close()
+			return err
+		}
+		prefs := model.NotificationPreferences{}
+		if err := json.Unmarshal(current, &prefs); err != nil {
+			rows.Close()
+			return fmt.Errorf("settings %d preferences: %w", id, err)
+		}
+		if prefs == nil {
+			prefs = model.NotificationPreferences{}
+		}
+		for i, col := range columns {
+			if flags[i] {
+				continue
+			}
+			for _, t := range legacy[col] {
+				c := prefs.Get(t)
+				c.Email, c.Digest = false, false
+				prefs[t] = c
+			}
+		}
+		pending = append(pending, row{id: id, preferences: prefs})
+	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, r := range pending {
+		b, err := json.Marshal(r.preferences)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`UPDATE %s SET preferences = $1 WHERE id = $2`, notificationsettings.Table), string(b), r.id); err != nil {
+			return err
+		}
+	}
+	for _, col := range columns {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`ALTER TABLE %s DROP COLUMN %s`, notificationsettings.Table, col)); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	log.Printf("✅ Категории писем %d настроек перенесены из колонок %s", len(pending), strings.Join(columns, ", "))
+	return nil
+}
+
+// backfillNotificationSettings создает настройки по умолчанию пользователям, зарегистрированным
+// до появления сводок: планировщик сводок обходит только строки настроек. Повторный запуск
+// ничего не делает — у всех пользователей настройки уже есть.
+func backfillNotificationSettings(ctx context.Context, client *ent.Client) error {
+	ids, err := client.User.Query().
+		Where(user.Not(user.HasNotificationSettings())).
+		IDs(ctx)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	const batch = 1000
+	next := time.Now().Add(nsuc.DigestInterval(notificationsettings.DefaultEmailFrequency))
+	for start := 0; start < len(ids); start += batch {
+		chunk := ids[start:min(start+batch, len(ids))]
+		builders := make([]*ent.NotificationSettingsCreate, len(chunk))
+		for i, id := range chunk {
+			builders[i] = client.NotificationSettings.Create().
+				SetUserID(id).
+				SetNextDigestAt(next)
+		}
+		if err := client.NotificationSettings.CreateBulk(builders...).Exec(ctx); err != nil {
+			return fmt.Errorf("users %d..%d: %w", chunk[0], chunk[len(chunk)-1], err)
+		}
+	}
+	log.Printf("✅ Настройки уведомлений по умолчанию созданы для %d пользователей", len(ids))
+	return nil
+}
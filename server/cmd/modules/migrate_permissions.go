@@ -0,0 +1,203 @@
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"stormlink/server/authz"
+	"stormlink/server/ent"
+)
+
+// migrateRolePermissions переводит наборы прав ролей, записанные при старой версии реестра
+// authz, в текущую, затем переносит булевы колонки прав в набор permissions и удаляет
+// колонки. Уже выданные права сохраняются; повторный запуск ничего не делает.
+func migrateRolePermissions(ctx context.Context, client *ent.Client) error {
+	tx, err := client.Tx(ctx)
+	if err != nil {
+		return err
+	}
+	tables := []struct {
+		name    string
+		columns map[string][]authz.Action
+	}{
+		{"roles", authz.LegacyRoleColumns},
+		{"host_roles", authz.LegacyHostRoleColumns},
+	}
+	for _, t := range tables {
+		if err := upgradeTablePermissions(ctx, tx, t.name); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("%s: %w", t.name, err)
+		}
+		if err := migrateTablePermissions(ctx, tx, t.name, t.columns); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("%s: %w", t.name, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// upgradeTablePermissions переводит permissions и denied_actions ролей с версией меньше
+// authz.RegistryVersion в ключи текущей версии
+func upgradeTablePermissions(ctx context.Context, tx *ent.Tx, table string) error {
+	type row struct {
+		id                  int
+		permissions, denied []string
+	}
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(
+		`SELECT id, COALESCE(permissions, '[]'), COALESCE(denied_actions, '[]'), permissions_version FROM %s WHERE permissions_version < $1`, table,
+	), authz.RegistryVersion)
+	if err != nil {
+		return err
+	}
+	var pending []row
+	for rows.Next() {
+		var (
+			id                  int
+			permissions, denied []byte
+			version             int
+			r                   row
+		)
+		if err := rows.Scan(&id, &permissions, &denied, &version); err != nil {
+			rows.Close()
+			return err
+		}
+		if err := json.Unmarshal(permissions, &r.permissions); err != nil {
+			rows.Close()
+			return fmt.Errorf("role %d permissions: %w", id, err)
+		}
+		if err := json.Unmarshal(denied, &r.denied); err != nil {
+			rows.Close()
+			return fmt.Errorf("role %d denied actions: %w", id, err)
+		}
+		r.id = id
+		r.permissions = authz.Upgrade(version, r.permissions)
+		r.denied = authz.Upgrade(version, r.denied)
+		pending = append(pending, r)
+	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, r := range pending {
+		permissions, err := json.Marshal(r.permissions)
+		if err != nil {
+			return err
+		}
+		denied, err := json.Marshal(r.denied)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(
+			`UPDATE %s SET permissions = $1, denied_actions = $2, permissions_version = $3 WHERE id = $4`, table,
+		), string(permissions), string(denied), authz.RegistryVersion, r.id); err != nil {
+			return err
+		}
+	}
+	if len(pending) > 0 {
+		log.Printf("✅ Права %d ролей (%s) переведены на версию реестра %d", len(pending), table, authz.RegistryVersion)
+	}
+	return nil
+}
+
+func migrateTablePermissions(ctx context.Context, tx *ent.Tx, table string, legacy map[string][]authz.Action) error {
+	columns, err := legacyColumns(ctx, tx, table, legacy)
+	if err != nil || len(columns) == 0 {
+		return err
+	}
+
+	type row struct {
+		id          int
+		permissions []string
+	}
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(
+		`SELECT id, COALESCE(permissions, '[]'), %s FROM %s`, strings.Join(columns, ", "), table,
+	))
+	if err != nil {
+		return err
+	}
+	var pending []row
+	for rows.Next() {
+		var (
+			id      int
+			current []byte
+			flags   = make([]bool, len(columns))
+		)
+		dest := []any{&id, &current}
+		for i := range flags {
+			dest = append(dest, &flags[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			rows.Close()
+			return err
+		}
+		var permissions []string
+		if err := json.Unmarshal(current, &permissions); err != nil {
+			rows.Close()
+			return fmt.Errorf("role %d permissions: %w", id, err)
+		}
+		enabled := make(map[string]bool, len(columns))
+		for i, col := range columns {
+			enabled[col] = flags[i]
+		}
+		for _, key := range authz.FromLegacy(legacy, enabled) {
+			if !slices.Contains(permissions, key) {
+				permissions = append(permissions, key)
+			}
+		}
+		pending = append(pending, row{id: id, permissions: permissions})
+	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, r := range pending {
+		b, err := json.Marshal(r.permissions)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`UPDATE %s SET permissions = $1 WHERE id = $2`, table), string(b), r.id); err != nil {
+			return err
+		}
+	}
+	for _, col := range columns {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`ALTER TABLE %s DROP COLUMN %s`, table, col)); err != nil {
+			return err
+		}
+	}
+	log.Printf("✅ Права %d ролей (%s) перенесены из колонок %s", len(pending), table, strings.Join(columns, ", "))
+	return nil
+}
+
+// legacyColumns — устаревшие колонки из legacy, которые еще остались в таблице
+func legacyColumns[V any](ctx context.Context, tx *ent.Tx, table string, legacy map[string]V) ([]string, error) {
+	rows, err := tx.QueryContext(ctx,
+		`SELECT column_name FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = $1`,
+		table,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var columns []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		if _, ok := legacy[name]; ok {
+			columns = append(columns, name)
+		}
+	}
+	slices.Sort(columns)
+	return columns, rows.Err()
+}
@@ -3,6 +3,7 @@ package modules
 import (
 	"context"
 	"log"
+	"stormlink/server/authz"
 	"stormlink/server/ent/hostrole"
 	"stormlink/server/ent/hostsidebarnavigation"
 	"stormlink/server/ent/hostsocialnavigation"
@@ -25,12 +26,8 @@ func Seed(client *ent.Client) error {
 		if _, err := client.HostRole.Create().
 			SetTitle("owner").
 			SetColor("#99AAB5").
-			SetCommunityRolesManagement(true).
-			SetHostUserBan(true).
-			SetHostUserMute(true).
-			SetHostCommunityDeletePost(true).
-			SetHostCommunityRemovePostFromPublication(true).
-			SetHostCommunityDeleteComments(true).
+			SetPermissions(authz.Keys(authz.Definitions(""))).
+			SetPermissionsVersion(authz.RegistryVersion).
 			Save(ctx); err != nil {
 			return err
 		}
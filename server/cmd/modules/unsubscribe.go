@@ -0,0 +1,59 @@
+package modules
+
+import (
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+
+	notificationsettingsuc "stormlink/server/usecase/notificationsettings"
+	"stormlink/shared/jwt"
+)
+
+var unsubscribePage = template.Must(template.New("unsubscribe").Parse(`<!doctype html>
+<html><head><meta charset="utf-8"><title>Отписка от писем</title></head>
+<body>
+{{if .Done}}
+    <p>Вы отписались от этих писем. Настройки можно изменить в профиле.</p>
+{{else}}
+    <p>Отписаться от этих писем?</p>
+    <form method="post" action="/unsubscribe?token={{.Token}}">
+        <button type="submit">Отписаться</button>
+    </form>
+{{end}}
+</body></html>`))
+
+// NewUnsubscribeHandler обрабатывает ссылки отписки из писем.
+// POST (one-click по RFC 8058 или форма) — отписывает; GET только показывает форму подтверждения,
+// чтобы сканеры ссылок в почтовых клиентах не отписывали пользователя.
+func NewUnsubscribeHandler(uc notificationsettingsuc.NotificationSettingsUsecase) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        token := r.URL.Query().Get("token")
+        claims, err := jwt.ParseUnsubscribeToken(token)
+        if err != nil {
+            http.Error(w, "invalid unsubscribe link", http.StatusBadRequest)
+            return
+        }
+
+        switch r.Method {
+        case http.MethodGet:
+            w.Header().Set("Content-Type", "text/html; charset=utf-8")
+            _ = unsubscribePage.Execute(w, map[string]any{"Token": token})
+        case http.MethodPost:
+            err := uc.Unsubscribe(r.Context(), claims.UserID, claims.Category)
+            if errors.Is(err, notificationsettingsuc.ErrUnknownCategory) {
+                http.Error(w, "invalid unsubscribe link", http.StatusBadRequest)
+                return
+            }
+            if err != nil {
+                log.Printf("❌ Unsubscribe user=%d category=%s: %v", claims.UserID, claims.Category, err)
+                http.Error(w, "failed to unsubscribe", http.StatusInternalServerError)
+                return
+            }
+            w.Header().Set("Content-Type", "text/html; charset=utf-8")
+            _ = unsubscribePage.Execute(w, map[string]any{"Done": true})
+        default:
+            http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+        }
+    }
+}
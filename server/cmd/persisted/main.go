@@ -0,0 +1,88 @@
+// Утилита для выкладки манифеста persisted queries из сборки фронтенда в Redis.
+// Реплики в strict-режиме (GRAPHQL_PERSISTED_MANIFEST=redis) подхватывают его в течение 30 с.
+//
+//	go run ./server/cmd/persisted -manifest ./persisted-documents.json check
+//	go run ./server/cmd/persisted -manifest ./persisted-documents.json push
+//	go run ./server/cmd/persisted list
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"stormlink/server/cmd/modules"
+	"stormlink/server/graphql/persisted"
+	redisx "stormlink/shared/redis"
+)
+
+func main() {
+    modules.InitEnv()
+
+    manifest := flag.String("manifest", "", "path to the frontend persisted query manifest")
+    flag.Usage = func() {
+        fmt.Fprintf(flag.CommandLine.Output(), "usage: persisted [-manifest file] check|push|list\n")
+        flag.PrintDefaults()
+    }
+    flag.Parse()
+    if flag.NArg() != 1 {
+        flag.Usage()
+        os.Exit(2)
+    }
+
+    ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+    defer cancel()
+
+    load := func() map[string]string {
+        if *manifest == "" {
+            log.Fatal("-manifest is required")
+        }
+        ops, err := persisted.FileSource(*manifest)(ctx)
+        if err != nil { log.Fatalf("manifest: %v", err) }
+        return ops
+    }
+
+    switch flag.Arg(0) {
+    case "check":
+        fmt.Printf("manifest ok: %d operations\n", len(load()))
+    case "push":
+        ops := load()
+        rdb, err := redisx.NewClient()
+        if err != nil { log.Fatalf("redis: %v", err) }
+        defer rdb.Close()
+        // Пишем во временный ключ и переименовываем: реплики не увидят наполовину записанный манифест
+        tmp := persisted.ManifestKey + ":upload"
+        values := make(map[string]any, len(ops))
+        for id, q := range ops {
+            values[id] = q
+        }
+        pipe := rdb.TxPipeline()
+        pipe.Del(ctx, tmp)
+        pipe.HSet(ctx, tmp, values)
+        pipe.Rename(ctx, tmp, persisted.ManifestKey)
+        if _, err := pipe.Exec(ctx); err != nil { log.Fatalf("push manifest: %v", err) }
+        fmt.Printf("pushed %d operations to %s\n", len(ops), persisted.ManifestKey)
+    case "list":
+        rdb, err := redisx.NewClient()
+        if err != nil { log.Fatalf("redis: %v", err) }
+        defer rdb.Close()
+        ops, err := persisted.RedisSource(rdb, persisted.ManifestKey)(ctx)
+        if err != nil { log.Fatalf("read manifest: %v", err) }
+        ids := make([]string, 0, len(ops))
+        for id := range ops {
+            ids = append(ids, id)
+        }
+        sort.Strings(ids)
+        for _, id := range ids {
+            fmt.Println(id)
+        }
+        fmt.Printf("%d operations\n", len(ids))
+    default:
+        flag.Usage()
+        os.Exit(2)
+    }
+}
@@ -21,7 +21,13 @@ func main() {
 	if err != nil {
 		log.Fatalf("failed creating entgql extension: %v", err)
 	}
-	if err := entc.Generate("./schema", &gen.Config{},
+	if err := entc.Generate("./schema", &gen.Config{
+		Features: []gen.Feature{
+			gen.FeatureLock,      // SELECT ... FOR UPDATE SKIP LOCKED для outbox relay
+			gen.FeatureExecQuery, // сырой SQL для миграций данных (перенос прав ролей)
+			gen.FeatureIntercept, // перехватчики запросов: фильтр теневых санкций (server/shadow)
+		},
+	},
 		entc.Extensions(ex),
 	); err != nil {
 		log.Fatalf("ent codegen failed: %v", err)
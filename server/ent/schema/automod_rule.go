@@ -0,0 +1,64 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+)
+
+// AutomodRule holds the schema definition for the AutomodRule entity.
+// Правило автомодерации сообщества или, без community_id, всей платформы. Правила проверяют
+// посты и комментарии до записи (server/automod); смысл patterns и threshold зависит от kind.
+type AutomodRule struct {
+	ent.Schema
+}
+
+// Fields of the AutomodRule.
+func (AutomodRule) Fields() []ent.Field {
+	return []ent.Field{
+		field.Int("id").Unique(),
+		field.Int("community_id").Optional().Nillable(),
+		field.String("title").NotEmpty(),
+
+		// keywords, regex, link_domains — по patterns; account_age — threshold в часах;
+		// max_mentions, max_links — threshold как предел; repeated_content — threshold повторов
+		// за window_minutes
+		field.Enum("kind").
+			Values("keywords", "regex", "link_domains", "account_age", "verified_only",
+				"max_mentions", "max_links", "repeated_content"),
+		field.Strings("patterns").Optional(),
+		field.Int32("threshold").Optional().Nillable(),
+		field.Int32("window_minutes").Optional().Nillable(),
+
+		// Что сделать с контентом: отклонить, задержать до проверки, отправить жалобу
+		// или отклонить и замутить автора на mute_minutes
+		field.Enum("action").
+			Values("reject", "hold", "report", "mute"),
+		field.Int32("mute_minutes").Default(60),
+		// Текст автору при отказе; без него — общий
+		field.String("message").Optional().Nillable(),
+		field.Bool("enabled").Default(true),
+
+		field.Time("created_at").Default(time.Now),
+		field.Time("updated_at").Default(time.Now).UpdateDefault(time.Now),
+	}
+}
+
+// Edges of the AutomodRule.
+func (AutomodRule) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.To("community", Community.Type).
+			Field("community_id").
+			Unique(),
+	}
+}
+
+// Indexes of the AutomodRule.
+func (AutomodRule) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields("community_id", "enabled"),
+	}
+}
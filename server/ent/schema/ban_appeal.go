@@ -0,0 +1,80 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/contrib/entgql"
+	"entgo.io/ent"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+)
+
+// BanAppeal holds the schema definition for the BanAppeal entity.
+// Апелляция забаненного пользователя: одна на бан (CommunityUserBan или HostUserBan по scope).
+// Бан удаляется при разбане и по истечении срока, поэтому ban_id — не внешний ключ, а причина
+// и срок бана копируются в апелляцию.
+type BanAppeal struct {
+	ent.Schema
+}
+
+// Fields of the BanAppeal.
+func (BanAppeal) Fields() []ent.Field {
+	return []ent.Field{
+		field.Int("id").Unique(),
+		field.Int("user_id"),
+
+		// community — бан в сообществе (разбирают модераторы сообщества и персонал платформы),
+		// host — бан на платформе
+		field.Enum("scope").
+			Values("community", "host"),
+		field.Int("ban_id").
+			Annotations(entgql.Type("ID")),
+		field.Int("community_id").Optional().Nillable(),
+		field.String("ban_reason").Default(""),
+		// Кто выдал бан; ему сообщается о новой апелляции
+		field.Int("ban_issued_by").Optional().Nillable().
+			Annotations(entgql.Type("ID")),
+		field.Time("ban_expires_at").Optional().Nillable(),
+
+		field.String("message").NotEmpty().MaxLen(2000),
+
+		field.Enum("status").
+			Values("pending", "accepted", "rejected").
+			Default("pending"),
+		field.Int("decided_by_id").Optional().Nillable(),
+		// Ответ модератора; уходит автору апелляции
+		field.String("decision_message").Optional().Nillable(),
+		field.Time("decided_at").Optional().Nillable(),
+		// Решение модераторов сообщества пересмотрено персоналом платформы
+		field.Bool("overridden").Default(false),
+
+		field.Time("created_at").Default(time.Now).Immutable(),
+		field.Time("updated_at").Default(time.Now).UpdateDefault(time.Now),
+	}
+}
+
+// Edges of the BanAppeal.
+func (BanAppeal) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.To("user", User.Type).
+			Field("user_id").
+			Required().
+			Unique(),
+		edge.To("community", Community.Type).
+			Field("community_id").
+			Unique(),
+		edge.To("decided_by", User.Type).
+			Field("decided_by_id").
+			Unique(),
+	}
+}
+
+// Indexes of the BanAppeal.
+func (BanAppeal) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields("scope", "ban_id").Unique(),
+		index.Fields("scope", "community_id", "status", "created_at", "id"),
+		index.Fields("user_id", "created_at"),
+	}
+}
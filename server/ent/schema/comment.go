@@ -22,6 +22,8 @@ func (Comment) Fields() []ent.Field {
 
 		field.Bool("has_deleted").Default(false),
 		field.Bool("has_updated").Default(false),
+		// Задержан автомодерацией: скрыт, пока модератор не отклонит жалобу
+		field.Bool("held").Default(false),
 
 		field.String("content"),
 		field.Time("created_at").Default(time.Now),
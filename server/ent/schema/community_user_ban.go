@@ -10,6 +10,13 @@ import (
 
 type CommunityUserBan struct{ ent.Schema }
 
+func (CommunityUserBan) Mixin() []ent.Mixin {
+	return []ent.Mixin{
+		SanctionMixin{},
+		ShadowMixin{},
+	}
+}
+
 func (CommunityUserBan) Fields() []ent.Field {
 	return []ent.Field{
 		field.Int("id").Unique(),
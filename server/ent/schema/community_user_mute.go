@@ -10,6 +10,13 @@ import (
 
 type CommunityUserMute struct{ ent.Schema }
 
+func (CommunityUserMute) Mixin() []ent.Mixin {
+	return []ent.Mixin{
+		SanctionMixin{},
+		ShadowMixin{},
+	}
+}
+
 func (CommunityUserMute) Fields() []ent.Field {
 	return []ent.Field{
 		field.Int("id").Unique(),
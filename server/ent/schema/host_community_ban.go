@@ -10,6 +10,12 @@ import (
 
 type HostCommunityBan struct{ ent.Schema }
 
+func (HostCommunityBan) Mixin() []ent.Mixin {
+	return []ent.Mixin{
+		SanctionMixin{},
+	}
+}
+
 func (HostCommunityBan) Fields() []ent.Field {
 	return []ent.Field{
 		field.Int("id").Unique(),
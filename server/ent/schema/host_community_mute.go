@@ -10,6 +10,12 @@ import (
 
 type HostCommunityMute struct{ ent.Schema }
 
+func (HostCommunityMute) Mixin() []ent.Mixin {
+	return []ent.Mixin{
+		SanctionMixin{},
+	}
+}
+
 func (HostCommunityMute) Fields() []ent.Field {
 	return []ent.Field{
 		field.Int("id").Unique(),
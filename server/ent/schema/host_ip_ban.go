@@ -0,0 +1,41 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+)
+
+// HostIPBan holds the schema definition for the HostIPBan entity.
+// Бан платформы по IP или подсети: с адреса нельзя регистрироваться и выполнять мутации
+// (server/ipban). Сочетается с баном аккаунта, от которого уходят повторной регистрацией.
+type HostIPBan struct {
+	ent.Schema
+}
+
+func (HostIPBan) Mixin() []ent.Mixin {
+	return []ent.Mixin{
+		SanctionMixin{},
+	}
+}
+
+// Fields of the HostIPBan.
+func (HostIPBan) Fields() []ent.Field {
+	return []ent.Field{
+		field.Int("id").Unique(),
+		// Подсеть в канонической записи (ipban.ParsePrefix): 203.0.113.7/32, 2001:db8::/48
+		field.String("cidr").NotEmpty(),
+
+		field.Time("created_at").Default(time.Now),
+		field.Time("updated_at").Default(time.Now).UpdateDefault(time.Now),
+	}
+}
+
+// Indexes of the HostIPBan.
+func (HostIPBan) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields("cidr"),
+	}
+}
@@ -10,6 +10,13 @@ import (
 
 type HostUserBan struct{ ent.Schema }
 
+func (HostUserBan) Mixin() []ent.Mixin {
+	return []ent.Mixin{
+		SanctionMixin{},
+		ShadowMixin{},
+	}
+}
+
 func (HostUserBan) Fields() []ent.Field {
 	return []ent.Field{
 		field.Int("id").Unique(),
@@ -10,6 +10,13 @@ import (
 
 type HostUserMute struct{ ent.Schema }
 
+func (HostUserMute) Mixin() []ent.Mixin {
+	return []ent.Mixin{
+		SanctionMixin{},
+		ShadowMixin{},
+	}
+}
+
 func (HostUserMute) Fields() []ent.Field {
 	return []ent.Field{
 		field.Int("id").Unique(),
@@ -0,0 +1,81 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/contrib/entgql"
+	"entgo.io/ent"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+)
+
+// ModerationAction holds the schema definition for the ModerationAction entity.
+// Журнал модерации: запись пишется в той же транзакции, что и само действие (server/modlog),
+// before/after — снимки затронутой сущности до и после действия.
+type ModerationAction struct {
+	ent.Schema
+}
+
+// Fields of the ModerationAction.
+func (ModerationAction) Fields() []ent.Field {
+	return []ent.Field{
+		field.Int("id").Unique(),
+		field.Enum("type").
+			Values(
+				"user_banned", "user_unbanned", "user_muted", "user_unmuted",
+				"community_banned", "community_unbanned", "community_muted", "community_unmuted",
+				"role_created", "role_updated", "role_deleted", "role_assigned", "role_unassigned",
+				"post_unpublished", "comment_deleted",
+				"rule_created", "rule_updated", "rule_deleted",
+				"report_resolved", "report_dismissed", "report_escalated",
+				"automod_triggered",
+				"appeal_accepted", "appeal_rejected",
+				"ip_banned", "ip_unbanned",
+			),
+		// nil — действие системы (например, воркера)
+		field.Int("moderator_id").Optional().Nillable(),
+		// nil — действие уровня платформы
+		field.Int("community_id").Optional().Nillable(),
+
+		// Затронутая сущность и, если есть, пользователь, которого действие касается
+		field.Enum("target_type").
+			Values("user", "community", "post", "comment", "role", "host_role", "rule", "host_rule", "report", "automod_rule", "ban_appeal", "ip_ban"),
+		field.Int("target_id").
+			Annotations(entgql.Type("ID")),
+		field.Int("target_user_id").Optional().Nillable(),
+
+		field.JSON("before", map[string]any{}).Optional().
+			Annotations(entgql.Type("JSON")),
+		field.JSON("after", map[string]any{}).Optional().
+			Annotations(entgql.Type("JSON")),
+
+		field.Time("created_at").Default(time.Now).Immutable(),
+	}
+}
+
+// Edges of the ModerationAction.
+func (ModerationAction) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.To("moderator", User.Type).
+			Field("moderator_id").
+			Unique(),
+		edge.To("community", Community.Type).
+			Field("community_id").
+			Unique(),
+		edge.To("target_user", User.Type).
+			Field("target_user_id").
+			Unique(),
+	}
+}
+
+// Indexes of the ModerationAction.
+func (ModerationAction) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields("community_id", "created_at", "id"),
+		index.Fields("moderator_id", "created_at"),
+		index.Fields("target_user_id", "created_at"),
+		index.Fields("target_type", "target_id"),
+		index.Fields("created_at", "id"),
+	}
+}
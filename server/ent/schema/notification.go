@@ -0,0 +1,82 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/contrib/entgql"
+	"entgo.io/ent"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+)
+
+// Notification holds the schema definition for the Notification entity.
+// Однотипные непрочитанные уведомления с одинаковым group_key схлопываются в одно
+// («5 человек оценили ваш пост»): растёт actor_count, в actor_ids — последние участники для
+// отображения, в all_actor_ids — все участники группы, по ним считается actor_count.
+type Notification struct {
+	ent.Schema
+}
+
+// Fields of the Notification.
+func (Notification) Fields() []ent.Field {
+	return []ent.Field{
+		field.Int("id").Unique(),
+		field.Int("user_id"),
+		field.Enum("type").
+			Values("comment_reply", "post_like", "comment_like", "user_follow", "mention", "moderation"),
+		field.String("group_key").NotEmpty().
+			Annotations(entgql.Skip(entgql.SkipAll)),
+
+		// Последний участник события и общее число участников группы
+		field.Int("actor_id").Optional().Nillable(),
+		field.Int32("actor_count").Default(1),
+		field.JSON("actor_ids", []int{}).Optional().
+			Annotations(entgql.Skip(entgql.SkipAll)),
+		field.JSON("all_actor_ids", []int{}).Optional().
+			Annotations(entgql.Skip(entgql.SkipAll)),
+
+		// Объекты, к которым относится уведомление
+		field.Int("post_id").Optional().Nillable(),
+		field.Int("comment_id").Optional().Nillable(),
+		field.Int("community_id").Optional().Nillable(),
+
+		// Текст для уведомлений без объекта (например, причина модерации)
+		field.String("message").Optional().Nillable(),
+
+		field.Time("read_at").Optional().Nillable(),
+		field.Time("created_at").Default(time.Now),
+		field.Time("updated_at").Default(time.Now).UpdateDefault(time.Now),
+	}
+}
+
+// Edges of the Notification.
+func (Notification) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.To("user", User.Type).
+			Field("user_id").
+			Unique().
+			Required().
+			Annotations(entgql.Skip(entgql.SkipAll)),
+		edge.To("actor", User.Type).
+			Field("actor_id").
+			Unique(),
+		edge.To("post", Post.Type).
+			Field("post_id").
+			Unique(),
+		edge.To("comment", Comment.Type).
+			Field("comment_id").
+			Unique(),
+		edge.To("community", Community.Type).
+			Field("community_id").
+			Unique(),
+	}
+}
+
+// Indexes of the Notification.
+func (Notification) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields("user_id", "updated_at", "id"),
+		index.Fields("user_id", "group_key", "read_at"),
+	}
+}
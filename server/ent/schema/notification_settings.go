@@ -0,0 +1,77 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/contrib/entgql"
+	"entgo.io/ent"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+
+	"stormlink/server/model"
+)
+
+// NotificationSettings holds the schema definition for the NotificationSettings entity.
+type NotificationSettings struct {
+	ent.Schema
+}
+
+// Fields of the NotificationSettings.
+func (NotificationSettings) Fields() []ent.Field {
+	return []ent.Field{
+		field.Int("id").Unique(),
+		field.Int("user_id"),
+
+		// Частота email-уведомлений: immediate — сводка раз в несколько минут, off — письма не отправляются
+		field.Enum("email_frequency").
+			Values("off", "immediate", "daily", "weekly").
+			Default("daily"),
+
+		// Каналы доставки по типам событий (in-app, email, digest); отсутствующие типы — по умолчанию
+		field.JSON("preferences", model.NotificationPreferences{}).Optional().
+			Annotations(entgql.Skip(entgql.SkipAll)),
+
+		// Заглушенные обсуждения (посты) и сообщества
+		field.JSON("muted_post_ids", []int{}).Optional().
+			Annotations(entgql.Skip(entgql.SkipAll)),
+		field.JSON("muted_community_ids", []int{}).Optional().
+			Annotations(entgql.Skip(entgql.SkipAll)),
+
+		// Тихие часы: минуты от полуночи в часовом поясе пользователя (интервал может переходить через полночь)
+		field.String("timezone").Default("UTC"),
+		field.Int("quiet_hours_start").Optional().Nillable().
+			Annotations(entgql.Skip(entgql.SkipAll)),
+		field.Int("quiet_hours_end").Optional().Nillable().
+			Annotations(entgql.Skip(entgql.SkipAll)),
+
+		// Окно следующей сводки
+		field.Time("last_digest_at").Optional().Nillable().
+			Annotations(entgql.Skip(entgql.SkipAll)),
+		field.Time("next_digest_at").Default(time.Now).
+			Annotations(entgql.Skip(entgql.SkipAll)),
+
+		field.Time("created_at").Default(time.Now),
+		field.Time("updated_at").Default(time.Now).UpdateDefault(time.Now),
+	}
+}
+
+// Edges of the NotificationSettings.
+func (NotificationSettings) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.From("user", User.Type).
+			Ref("notification_settings").
+			Field("user_id").
+			Unique().
+			Required().
+			Annotations(entgql.Skip(entgql.SkipAll)),
+	}
+}
+
+// Indexes of the NotificationSettings.
+func (NotificationSettings) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields("user_id").Unique(),
+		index.Fields("email_frequency", "next_digest_at"),
+	}
+}
@@ -0,0 +1,45 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/contrib/entgql"
+	"entgo.io/ent"
+	"entgo.io/ent/schema"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+)
+
+// Outbox holds the schema definition for the Outbox entity.
+// Сообщения для брокера пишутся в той же транзакции, что и доменные изменения,
+// и публикуются relay-воркером (services/workers/internal/outbox).
+type Outbox struct {
+	ent.Schema
+}
+
+// Fields of the Outbox.
+func (Outbox) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("topic").NotEmpty(),
+		field.Bytes("payload"),
+		field.Int("attempts").Default(0),
+		field.String("last_error").Optional().Nillable(),
+		field.Time("available_at").Default(time.Now),
+		field.Time("sent_at").Optional().Nillable(),
+		field.Time("created_at").Default(time.Now).Immutable(),
+	}
+}
+
+// Indexes of the Outbox.
+func (Outbox) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields("sent_at", "available_at"),
+	}
+}
+
+// Annotations of the Outbox.
+func (Outbox) Annotations() []schema.Annotation {
+	return []schema.Annotation{
+		entgql.Skip(entgql.SkipAll),
+	}
+}
@@ -35,7 +35,8 @@ func (Post) Fields() []ent.Field {
 
 		field.Int32("views").Default(0),
 		field.Enum("visibility").
-			Values("published", "draft", "deleted").
+			// held — задержан автомодерацией до решения по жалобе
+			Values("published", "draft", "deleted", "held").
 			Default("draft"),
 
 		field.Time("created_at").Default(time.Now),
@@ -0,0 +1,104 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/contrib/entgql"
+	"entgo.io/ent"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+)
+
+// Report holds the schema definition for the Report entity.
+// Жалоба пользователя на пост, комментарий, пользователя или сообщество. Жалобы на один объект
+// в одной очереди не дублируются: первая открытая — основная, остальные ссылаются на нее
+// через duplicate_of_id и закрываются вместе с ней. Жалобы автомодерации подаются без автора
+// и ссылаются на сработавшее правило.
+type Report struct {
+	ent.Schema
+}
+
+// Fields of the Report.
+func (Report) Fields() []ent.Field {
+	return []ent.Field{
+		field.Int("id").Unique(),
+		// nil — жалоба автомодерации
+		field.Int("reporter_id").Optional().Nillable(),
+		field.Int("automod_rule_id").Optional().Nillable(),
+
+		// Объект жалобы; target_user_id — автор контента или сам пользователь, community_id — где объект находится
+		field.Enum("target_type").
+			Values("post", "comment", "user", "community"),
+		field.Int("target_id").
+			Annotations(entgql.Type("ID")),
+		field.Int("target_user_id").Optional().Nillable(),
+		field.Int("community_id").Optional().Nillable(),
+
+		// Очередь: community — модераторы сообщества, host — персонал платформы
+		field.Enum("queue").
+			Values("community", "host"),
+
+		// Категория и, если указано, нарушенное правило сообщества или платформы
+		field.Enum("reason").
+			Values("spam", "harassment", "hate", "violence", "sexual", "misinformation", "rule", "other", "automod"),
+		field.Int("community_rule_id").Optional().Nillable(),
+		field.Int("host_rule_id").Optional().Nillable(),
+		field.String("details").Optional().Nillable().MaxLen(2000),
+
+		field.Enum("status").
+			Values("open", "resolved", "dismissed").
+			Default("open"),
+		field.Int("duplicate_of_id").Optional().Nillable(),
+
+		// Разбор жалобы
+		field.Int("resolved_by_id").Optional().Nillable(),
+		field.String("resolution_note").Optional().Nillable(),
+		field.Time("escalated_at").Optional().Nillable(),
+		field.String("escalation_note").Optional().Nillable(),
+		field.Time("resolved_at").Optional().Nillable(),
+
+		field.Time("created_at").Default(time.Now).Immutable(),
+		field.Time("updated_at").Default(time.Now).UpdateDefault(time.Now),
+	}
+}
+
+// Edges of the Report.
+func (Report) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.To("reporter", User.Type).
+			Field("reporter_id").
+			Unique(),
+		edge.To("automod_rule", AutomodRule.Type).
+			Field("automod_rule_id").
+			Unique(),
+		edge.To("target_user", User.Type).
+			Field("target_user_id").
+			Unique(),
+		edge.To("community", Community.Type).
+			Field("community_id").
+			Unique(),
+		edge.To("community_rule", CommunityRule.Type).
+			Field("community_rule_id").
+			Unique(),
+		edge.To("host_rule", HostRule.Type).
+			Field("host_rule_id").
+			Unique(),
+		edge.To("resolved_by", User.Type).
+			Field("resolved_by_id").
+			Unique(),
+		edge.To("duplicates", Report.Type).
+			From("duplicate_of").
+			Field("duplicate_of_id").
+			Unique(),
+	}
+}
+
+// Indexes of the Report.
+func (Report) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields("queue", "community_id", "status", "created_at", "id"),
+		index.Fields("target_type", "target_id", "status"),
+		index.Fields("reporter_id", "created_at"),
+	}
+}
@@ -3,6 +3,7 @@ package schema
 import (
 	"time"
 
+	"entgo.io/contrib/entgql"
 	"entgo.io/ent"
 	"entgo.io/ent/schema/edge"
 	"entgo.io/ent/schema/field"
@@ -18,12 +19,20 @@ func (Role) Fields() []ent.Field {
 		field.String("color").Optional().Nillable(),
 		field.Int("community_id"),
 
-		field.Bool("community_roles_management").Default(false),
-		field.Bool("community_user_ban").Default(false),
-		field.Bool("community_user_mute").Default(false),
-		field.Bool("community_delete_post").Default(false),
-		field.Bool("community_remove_post_from_publication").Default(false),
-		field.Bool("community_delete_comments").Default(false),
+		// Права роли — ключи из реестра authz (например, community.user.ban).
+		// Новое право добавляется в реестр, а не колонкой.
+		field.Strings("permissions").Optional(),
+
+		// Позиция в иерархии: роль с большей позицией старше, модератор не может
+		// применять санкции к обладателю роли не ниже своей
+		field.Int32("position").Default(0),
+		// Явные запреты (действия authz): перекрывают права любых других ролей пользователя
+		field.Strings("denied_actions").Optional(),
+		// Версия реестра authz (authz.RegistryVersion), с которой записаны permissions и
+		// denied_actions; 0 — записаны до появления версий
+		field.Int("permissions_version").
+			Default(0).
+			Annotations(entgql.Skip(entgql.SkipAll)),
 
 		field.Time("created_at").Default(time.Now),
 		field.Time("updated_at").Default(time.Now).UpdateDefault(time.Now),
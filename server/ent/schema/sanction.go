@@ -0,0 +1,55 @@
+package schema
+
+import (
+	"entgo.io/contrib/entgql"
+	"entgo.io/ent"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+	"entgo.io/ent/schema/mixin"
+)
+
+// SanctionMixin — общие поля банов и мутов: причина, выдавший модератор, срок и заметки.
+// Санкции с истекшим expires_at снимает воркер (services/workers/internal/sanctions).
+type SanctionMixin struct {
+	mixin.Schema
+}
+
+func (SanctionMixin) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("reason").Default(""),
+		field.Int("issued_by").Optional().Nillable(),
+		// nil — бессрочно
+		field.Time("expires_at").Optional().Nillable(),
+		// Публичная заметка видна наказанному, приватная — только модераторам (поле privateNote)
+		field.String("public_note").Default(""),
+		field.String("private_note").Default("").
+			Annotations(entgql.Skip(entgql.SkipAll)),
+	}
+}
+
+func (SanctionMixin) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.To("issuer", User.Type).
+			Field("issued_by").
+			Unique(),
+	}
+}
+
+func (SanctionMixin) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields("expires_at"),
+	}
+}
+
+// ShadowMixin — теневой режим санкций пользователя (теневой бан, тихий мут): действия не запрещаются,
+// но посты, комментарии и лайки видят только сам пользователь и модераторы (см. server/shadow)
+type ShadowMixin struct {
+	mixin.Schema
+}
+
+func (ShadowMixin) Fields() []ent.Field {
+	return []ent.Field{
+		field.Bool("shadow").Default(false),
+	}
+}
@@ -92,5 +92,10 @@ func (User) Edges() []ent.Edge {
 
 		// Связь с EmailVerification
 		edge.To("email_verifications", EmailVerification.Type),
+
+		// Настройки уведомлений (доступны только владельцу через myNotificationSettings)
+		edge.To("notification_settings", NotificationSettings.Type).
+			Unique().
+			Annotations(entgql.Skip(entgql.SkipAll)),
 	}
 }
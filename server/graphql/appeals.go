@@ -0,0 +1,95 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"google.golang.org/grpc/codes"
+
+	"stormlink/server/authz"
+	"stormlink/server/ent"
+	"stormlink/server/ent/banappeal"
+	"stormlink/server/graphql/models"
+	banappealuc "stormlink/server/usecase/banappeal"
+)
+
+// requireAppealModerator пропускает того, кто решает апелляцию: по бану в сообществе — модераторов
+// сообщества с правом бана, пока апелляция не решена, и персонал платформы (HostBanUser), который
+// может и пересмотреть отказ. override — решение принимает персонал платформы.
+func (r *Resolver) requireAppealModerator(ctx context.Context, userID int, a *ent.BanAppeal) (override bool, err error) {
+	host, err := r.authorizer().Can(ctx, userID, authz.HostBanUser, authz.Host())
+	if err != nil {
+		return false, err
+	}
+	if host {
+		return true, nil
+	}
+	if a.Scope == banappeal.ScopeCommunity && a.CommunityID != nil && a.Status == banappeal.StatusPending {
+		return false, r.require(ctx, userID, authz.BanUser, authz.Community(*a.CommunityID), "only community moderators can decide ban appeals")
+	}
+	return false, r.require(ctx, userID, authz.HostBanUser, authz.Host(), "only host staff can decide this ban appeal")
+}
+
+// decideAppeal принимает или отклоняет апелляцию и сообщает решение ее автору. Принятие
+// в той же транзакции снимает бан (если он еще действует).
+func (r *Resolver) decideAppeal(ctx context.Context, userID int, id string, status banappeal.Status, message *string) (*ent.BanAppeal, error) {
+	appealID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid appeal ID %q: %w", id, err)
+	}
+	a, err := r.BanAppealUC.Get(ctx, appealID)
+	if err != nil {
+		return nil, err
+	}
+	override, err := r.requireAppealModerator(ctx, userID, a)
+	if err != nil {
+		return nil, err
+	}
+	if a.Status != banappeal.StatusPending && !override {
+		return nil, banappealuc.ErrDecided
+	}
+
+	out, lifted, err := r.BanAppealUC.Decide(ctx, a.ID, userID, status, message, override)
+	if err != nil {
+		return nil, err
+	}
+	if lifted {
+		r.userSanctionsChanged(ctx, out.UserID, out.CommunityID)
+		r.publishModeration(ctx, models.ModerationEventTypeUserUnbanned, userID, out.CommunityID, &out.UserID, nil)
+	}
+
+	msg := appealOutcomeMessage(status, message)
+	r.notifyModeration(ctx, out.UserID, userID, out.CommunityID, msg)
+	return out, nil
+}
+
+// appealError дополняет отказ по паузе между апелляциями кодом и сроком в extensions
+func appealError(ctx context.Context, err error) error {
+	var cooldown *banappealuc.CooldownError
+	if !errors.As(err, &cooldown) {
+		return err
+	}
+	e := gqlerror.WrapPath(graphql.GetPath(ctx), err)
+	e.Extensions = map[string]any{
+		"code":  codes.FailedPrecondition.String(),
+		"until": cooldown.Until.Format(time.RFC3339),
+	}
+	return e
+}
+
+// appealOutcomeMessage — текст уведомления автору апелляции
+func appealOutcomeMessage(status banappeal.Status, message *string) string {
+	msg := "Ваша апелляция отклонена"
+	if status == banappeal.StatusAccepted {
+		msg = "Ваша апелляция принята, бан снят"
+	}
+	if message != nil && *message != "" {
+		msg += ". " + *message
+	}
+	return msg
+}
@@ -0,0 +1,119 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"stormlink/server/authz"
+	"stormlink/server/ent"
+	"stormlink/server/ent/post"
+	"stormlink/server/graphql/models"
+	"stormlink/shared/auth"
+)
+
+// authorizer — проверка прав; резолвер без Authz (тесты) проверяет права напрямую по базе
+func (r *Resolver) authorizer() *authz.Authorizer {
+	if r.Authz != nil {
+		return r.Authz
+	}
+	return authz.New(authz.NewEntStore(r.Client))
+}
+
+// require пропускает пользователя с правом на действие; отказ — authz.ErrForbidden с причиной
+func (r *Resolver) require(ctx context.Context, userID int, action authz.Action, res authz.Resource, reason string) error {
+	ok, err := r.authorizer().Can(ctx, userID, action, res)
+	if err != nil {
+		return fmt.Errorf("permissions: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("%w: %s", authz.ErrForbidden, reason)
+	}
+	return nil
+}
+
+// actingAs возвращает текущего пользователя; ID автора из ввода обязан совпадать с ним,
+// иначе публикация шла бы от чужого имени и в обход его санкций
+func actingAs(ctx context.Context, claimedID, field string) (int, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("unauthenticated")
+	}
+	claimed, err := strconv.Atoi(claimedID)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %w", field, claimedID, err)
+	}
+	if claimed != userID {
+		return 0, fmt.Errorf("%w: %s must be the current user", authz.ErrForbidden, field)
+	}
+	return userID, nil
+}
+
+// canSeeModerationLog — журнал сообщества видят те, у кого есть любое право в нем, и персонал
+// платформы; весь журнал — только персонал платформы (любое право уровня платформы)
+func (r *Resolver) canSeeModerationLog(ctx context.Context, userID int, communityID *int) (bool, error) {
+	if communityID != nil {
+		for _, d := range authz.Definitions(authz.ScopeCommunity) {
+			ok, err := r.authorizer().Can(ctx, userID, d.Key, authz.Community(*communityID))
+			if err != nil || ok {
+				return ok, err
+			}
+		}
+	}
+	for _, d := range authz.Definitions(authz.ScopeHost) {
+		ok, err := r.authorizer().Can(ctx, userID, d.Key, authz.Host())
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+// requirePostEdit пропускает правку поста автором; чужой пост правит модератор сообщества:
+// текст — с правом EditPost, удаление — DeletePost, другая смена видимости — UnpublishPost.
+// moderated — право, по которому модератор сменил видимость чужого поста (пусто — не менял):
+// только такая смена попадает в журнал модерации.
+func (r *Resolver) requirePostEdit(ctx context.Context, editorID int, before *ent.Post, input models.UpdatePostInput) (moderated authz.Action, err error) {
+	if editorID == 0 {
+		return "", fmt.Errorf("unauthorized")
+	}
+	if editorID == before.AuthorID {
+		return "", nil
+	}
+	res := authz.Community(before.CommunityID)
+	if input.Title != nil || input.Slug != nil || input.Content != nil || input.HeroImageID != nil || input.PublishedAt != nil {
+		if err := r.require(ctx, editorID, authz.EditPost, res, "only the author or moderators can edit a post"); err != nil {
+			return "", err
+		}
+	}
+	if input.Visibility == nil || *input.Visibility == before.Visibility {
+		return "", nil
+	}
+	action, reason := authz.UnpublishPost, "only the author or moderators can change post visibility"
+	if *input.Visibility == post.VisibilityDeleted {
+		action, reason = authz.DeletePost, "only the author or moderators can delete a post"
+	}
+	if err := r.require(ctx, editorID, action, res, reason); err != nil {
+		return "", err
+	}
+	return action, nil
+}
+
+// requireCommentEdit пропускает правку комментария автором; чужой комментарий модератор
+// сообщества может только удалить (право DeleteComment). moderated — DeleteComment, если
+// удаляет модератор: такое удаление попадает в журнал модерации.
+func (r *Resolver) requireCommentEdit(ctx context.Context, editorID int, before *ent.Comment, deleting bool) (moderated authz.Action, err error) {
+	if editorID == 0 {
+		return "", fmt.Errorf("unauthorized")
+	}
+	if editorID == before.AuthorID {
+		return "", nil
+	}
+	if !deleting {
+		return "", fmt.Errorf("%w: only the author can edit a comment", authz.ErrForbidden)
+	}
+	if err := r.require(ctx, editorID, authz.DeleteComment, authz.Community(before.CommunityID), "only the author or moderators can delete a comment"); err != nil {
+		return "", err
+	}
+	return authz.DeleteComment, nil
+}
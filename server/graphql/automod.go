@@ -0,0 +1,158 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"google.golang.org/grpc/codes"
+
+	"stormlink/server/authz"
+	"stormlink/server/automod"
+	"stormlink/server/ent"
+	"stormlink/server/ent/automodrule"
+	"stormlink/server/ent/moderationaction"
+	"stormlink/server/ent/report"
+	"stormlink/server/graphql/models"
+	"stormlink/server/model"
+	"stormlink/server/modlog"
+	"stormlink/shared/auth"
+)
+
+// automodEngine — движок автомодерации; резолвер без Automod (тесты) читает правила из Client
+func (r *Resolver) automodEngine() *automod.Engine {
+	if r.Automod != nil {
+		return r.Automod
+	}
+	return automod.New(automod.NewEntStore(r.Client))
+}
+
+// requireAutomodManager пропускает управление правилами сообщества (ManageCommunity)
+// или, без communityID, правилами платформы (ManageHost)
+func (r *Resolver) requireAutomodManager(ctx context.Context, userID int, communityID *int) error {
+	if communityID == nil {
+		return r.require(ctx, userID, authz.ManageHost, authz.Host(), "only host managers can manage host automod rules")
+	}
+	return r.require(ctx, userID, authz.ManageCommunity, authz.Community(*communityID), "only community managers can manage automod rules")
+}
+
+// automodCheck проверяет публикацию до записи; модераторы сообщества (ManageCommunity) не проверяются.
+// reject и mute — отказ с code=InvalidArgument и reason=automod в extensions. Вердикт hold и report
+// возвращается: его применяют после записи через automodApply. nil — публикация пропущена.
+func (r *Resolver) automodCheck(ctx context.Context, c automod.Content) (*automod.Verdict, error) {
+	exempt, err := r.authorizer().Can(ctx, c.AuthorID, authz.ManageCommunity, authz.Community(c.CommunityID))
+	if err != nil {
+		return nil, err
+	}
+	if exempt {
+		return nil, nil
+	}
+	v, err := r.automodEngine().Evaluate(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	if v.Rule == nil {
+		return nil, nil
+	}
+
+	switch v.Action {
+	case automodrule.ActionReject:
+		r.automodRecord(ctx, v, c.CommunityID, moderationaction.TargetTypeUser, c.AuthorID, c.AuthorID)
+		return nil, automodError(ctx, v)
+	case automodrule.ActionMute:
+		r.automodRecord(ctx, v, c.CommunityID, moderationaction.TargetTypeUser, c.AuthorID, c.AuthorID)
+		r.automodMute(ctx, v, c)
+		return nil, automodError(ctx, v)
+	}
+	return v, nil
+}
+
+// automodApply применяет hold и report к записанной публикации: запись в журнале и жалоба
+// автомодерации. Ошибки только логируются: публикация уже сохранена.
+func (r *Resolver) automodApply(ctx context.Context, v *automod.Verdict, targetType report.TargetType, targetID, authorID, communityID int) {
+	if v == nil {
+		return
+	}
+	r.automodRecord(ctx, v, communityID, moderationaction.TargetType(targetType), targetID, authorID)
+	if _, err := r.ReportUC.CreateAutomod(ctx, v.Rule, targetType, targetID, v.Reason()); err != nil {
+		log.Printf("❌ automod report %s %d: %v", targetType, targetID, err)
+	}
+	// Задержанная публикация пропадает из ответов, закешированных до ее проверки
+	r.invalidate(ctx, r.targetTags(ctx, targetType, targetID)...)
+}
+
+// automodRecord записывает срабатывание в журнал модерации от имени системы
+func (r *Resolver) automodRecord(ctx context.Context, v *automod.Verdict, communityID int, targetType moderationaction.TargetType, targetID, authorID int) {
+	err := modlog.Record(auth.WithUserID(ctx, 0), r.Client.ModerationAction, modlog.Entry{
+		Type:         moderationaction.TypeAutomodTriggered,
+		CommunityID:  &communityID,
+		TargetType:   targetType,
+		TargetID:     targetID,
+		TargetUserID: &authorID,
+		After: map[string]any{
+			"rule_id": v.Rule.ID,
+			"action":  v.Action,
+			"reason":  v.Reason(),
+		},
+	})
+	if err != nil {
+		log.Printf("❌ automod log rule %d: %v", v.Rule.ID, err)
+	}
+}
+
+// automodMute мутит автора в сообществе на mute_minutes правила от имени системы
+func (r *Resolver) automodMute(ctx context.Context, v *automod.Verdict, c automod.Content) {
+	expiresAt := time.Now().Add(time.Duration(v.Rule.MuteMinutes) * time.Minute)
+	d := model.SanctionDetails{Reason: "Автомодерация: " + v.Reason(), ExpiresAt: &expiresAt}
+	if _, err := r.BanUC.MuteUserInCommunity(auth.WithUserID(ctx, 0), c.AuthorID, c.CommunityID, d); err != nil {
+		log.Printf("❌ automod mute user %d: %v", c.AuthorID, err)
+		return
+	}
+	r.userSanctionsChanged(ctx, c.AuthorID, &c.CommunityID)
+	title := strconv.Itoa(c.CommunityID)
+	if cm, err := r.Client.Community.Get(ctx, c.CommunityID); err == nil {
+		title = cm.Title
+	}
+	r.notifyModeration(ctx, c.AuthorID, 0, &c.CommunityID, sanctionMessage(fmt.Sprintf("Вам ограничена возможность писать в сообществе «%s»", title), d))
+	r.publishModeration(ctx, models.ModerationEventTypeUserMuted, 0, &c.CommunityID, &c.AuthorID, nil)
+}
+
+// automodError — отказ в публикации с текстом правила или общим
+func automodError(ctx context.Context, v *automod.Verdict) error {
+	msg := "Публикация отклонена автомодерацией: " + v.Reason()
+	if v.Rule.Message != nil && *v.Rule.Message != "" {
+		msg = *v.Rule.Message
+	}
+	e := gqlerror.ErrorPathf(graphql.GetPath(ctx), "%s", msg)
+	e.Extensions = map[string]any{
+		"code":   codes.InvalidArgument.String(),
+		"reason": "automod",
+		"ruleID": strconv.Itoa(v.Rule.ID),
+	}
+	return e
+}
+
+// automodTestResult — результат пробного прогона для GraphQL
+func automodTestResult(v *automod.Verdict) *models.AutomodTestResult {
+	out := &models.AutomodTestResult{Rule: v.Rule, Matches: make([]*models.AutomodMatch, 0, len(v.Matches))}
+	if v.Rule != nil {
+		out.Action = &v.Action
+	}
+	for _, m := range v.Matches {
+		out.Matches = append(out.Matches, &models.AutomodMatch{Rule: m.Rule, Reason: m.Reason})
+	}
+	return out
+}
+
+// automodRuleByID загружает правило по ID из GraphQL
+func (r *Resolver) automodRuleByID(ctx context.Context, id string) (*ent.AutomodRule, error) {
+	ruleID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid automod rule ID %q: %w", id, err)
+	}
+	return r.AutomodRuleUC.GetAutomodRule(ctx, ruleID)
+}
@@ -0,0 +1,215 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	gqlgen "github.com/99designs/gqlgen/graphql"
+
+	"stormlink/server/authz"
+	"stormlink/server/ent"
+	"stormlink/server/ent/bookmark"
+	"stormlink/server/ent/community"
+	"stormlink/server/ent/communityfollow"
+	"stormlink/server/ent/communityuserban"
+	"stormlink/server/ent/communityusermute"
+	"stormlink/server/ent/post"
+	"stormlink/server/ent/role"
+	"stormlink/server/ent/user"
+	"stormlink/server/ent/userfollow"
+	"stormlink/server/graphql/models"
+	"stormlink/server/usecase/pagination"
+	"stormlink/shared/auth"
+)
+
+var (
+	postsOrder = pagination.Order[*ent.Post]{
+		Field: post.FieldCreatedAt,
+		Desc:  true,
+		Key:   func(p *ent.Post) (time.Time, int) { return p.CreatedAt, p.ID },
+	}
+	usersOrder = pagination.Order[*ent.User]{
+		Field: user.FieldCreatedAt,
+		Key:   func(u *ent.User) (time.Time, int) { return u.CreatedAt, u.ID },
+	}
+	communitiesOrder = pagination.Order[*ent.Community]{
+		Field: community.FieldCreatedAt,
+		Key:   func(c *ent.Community) (time.Time, int) { return c.CreatedAt, c.ID },
+	}
+)
+
+// connectionArgs собирает аргументы Relay; totalCount считается, только если поле запрошено
+func connectionArgs(ctx context.Context, first *int32, after *string, last *int32, before *string) pagination.Args {
+	toInt := func(v *int32) *int {
+		if v == nil {
+			return nil
+		}
+		n := int(*v)
+		return &n
+	}
+	return pagination.Args{
+		First:      toInt(first),
+		After:      after,
+		Last:       toInt(last),
+		Before:     before,
+		TotalCount: slices.Contains(gqlgen.CollectAllFields(ctx), "totalCount"),
+	}
+}
+
+func postsConnection(ctx context.Context, q *ent.PostQuery, args pagination.Args) (*models.PostsConnection, error) {
+	page, err := pagination.Paginate(ctx, q, args, postsOrder)
+	if err != nil {
+		return nil, err
+	}
+	return &models.PostsConnection{
+		Edges: pagination.MapEdges(page, func(cur string, p *ent.Post) *models.PostEdge {
+			return &models.PostEdge{Cursor: cur, Node: p}
+		}),
+		PageInfo:   page.PageInfo,
+		TotalCount: int32(page.TotalCount),
+	}, nil
+}
+
+func usersConnection(ctx context.Context, q *ent.UserQuery, args pagination.Args) (*models.UsersConnection, error) {
+	page, err := pagination.Paginate(ctx, q, args, usersOrder)
+	if err != nil {
+		return nil, err
+	}
+	return &models.UsersConnection{
+		Edges: pagination.MapEdges(page, func(cur string, u *ent.User) *models.UserEdge {
+			return &models.UserEdge{Cursor: cur, Node: u}
+		}),
+		PageInfo:   page.PageInfo,
+		TotalCount: int32(page.TotalCount),
+	}, nil
+}
+
+func communitiesConnection(ctx context.Context, q *ent.CommunityQuery, args pagination.Args) (*models.CommunitiesConnection, error) {
+	page, err := pagination.Paginate(ctx, q, args, communitiesOrder)
+	if err != nil {
+		return nil, err
+	}
+	return &models.CommunitiesConnection{
+		Edges: pagination.MapEdges(page, func(cur string, c *ent.Community) *models.CommunityEdge {
+			return &models.CommunityEdge{Cursor: cur, Node: c}
+		}),
+		PageInfo:   page.PageInfo,
+		TotalCount: int32(page.TotalCount),
+	}, nil
+}
+
+// postsQuery — посты с фильтрами по visibility, сообществу и автору
+func (r *Resolver) postsQuery(visibility *post.Visibility, communityID *string, authorID *string) (*ent.PostQuery, error) {
+	q := r.Client.Post.Query()
+	if visibility != nil {
+		q = q.Where(post.VisibilityEQ(*visibility))
+	}
+	if communityID != nil {
+		cid, err := strconv.Atoi(*communityID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid communityID %q: %w", *communityID, err)
+		}
+		q = q.Where(post.CommunityIDEQ(cid))
+	}
+	if authorID != nil {
+		aid, err := strconv.Atoi(*authorID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid authorID %q: %w", *authorID, err)
+		}
+		q = q.Where(post.AuthorIDEQ(aid))
+	}
+	return q, nil
+}
+
+// bookmarkedPostsQuery — посты в закладках текущего пользователя
+func (r *Resolver) bookmarkedPostsQuery(ctx context.Context, visibility *post.Visibility) (*ent.PostQuery, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unauthorized: %w", err)
+	}
+	q := r.Client.Post.Query().Where(post.HasBookmarksWith(bookmark.UserIDEQ(userID)))
+	if visibility != nil {
+		q = q.Where(post.VisibilityEQ(*visibility))
+	}
+	return q, nil
+}
+
+// feedPostsQuery — посты пользователей и сообществ, на которые подписан текущий пользователь
+func (r *Resolver) feedPostsQuery(ctx context.Context, visibility *post.Visibility) (*ent.PostQuery, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unauthorized: %w", err)
+	}
+	q := r.Client.Post.Query().
+		Where(
+			post.Or(
+				post.HasAuthorWith(user.HasFollowersWith(userfollow.FollowerIDEQ(userID))),
+				post.HasCommunityWith(community.HasFollowersWith(communityfollow.UserIDEQ(userID))),
+			),
+		)
+	if visibility != nil {
+		q = q.Where(post.VisibilityEQ(*visibility))
+	}
+	return q, nil
+}
+
+func (r *Resolver) communitiesQuery(onlyNotBanned *bool) *ent.CommunityQuery {
+	q := r.Client.Community.Query()
+	if onlyNotBanned == nil || *onlyNotBanned {
+		q = q.Where(community.CommunityHasBanned(false))
+	}
+	return q
+}
+
+// requireRolesManager пускает владельца сообщества или менеджера ролей
+func (r *Resolver) requireRolesManager(ctx context.Context, communityID string, what string) (int, error) {
+	currentUserID, err := auth.UserIDFromContext(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("unauthenticated")
+	}
+	cid, err := strconv.Atoi(communityID)
+	if err != nil {
+		return 0, fmt.Errorf("invalid communityID: %w", err)
+	}
+	if err := r.require(ctx, currentUserID, authz.ManageRoles, authz.Community(cid), "only owner or role manager can view "+what); err != nil {
+		return 0, err
+	}
+	return cid, nil
+}
+
+// communityUsersQuery — пользователи с ролями в сообществе
+func (r *Resolver) communityUsersQuery(ctx context.Context, communityID string) (*ent.UserQuery, error) {
+	cid, err := r.requireRolesManager(ctx, communityID, "community users")
+	if err != nil {
+		return nil, err
+	}
+	return r.Client.Role.Query().Where(role.CommunityIDEQ(cid)).QueryUsers(), nil
+}
+
+// communityFollowersQuery — подписчики сообщества с фильтром по бану и муту
+func (r *Resolver) communityFollowersQuery(ctx context.Context, communityID string, filter *models.CommunityFollowersFilter) (*ent.UserQuery, error) {
+	cid, err := r.requireRolesManager(ctx, communityID, "followers")
+	if err != nil {
+		return nil, err
+	}
+	banned := user.HasCommunitiesBansWith(communityuserban.CommunityIDEQ(cid))
+	muted := user.HasCommunitiesMutesWith(communityusermute.CommunityIDEQ(cid))
+
+	q := r.Client.User.Query().Where(user.HasCommunitiesFollowWith(communityfollow.CommunityIDEQ(cid)))
+	filterValue := models.CommunityFollowersFilterAll
+	if filter != nil {
+		filterValue = *filter
+	}
+	switch filterValue {
+	case models.CommunityFollowersFilterBanned:
+		q = q.Where(banned)
+	case models.CommunityFollowersFilterMuted:
+		q = q.Where(muted)
+	case models.CommunityFollowersFilterActive:
+		q = q.Where(user.Not(banned), user.Not(muted))
+	}
+	return q, nil
+}
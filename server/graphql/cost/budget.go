@@ -0,0 +1,134 @@
+package cost
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	redis "github.com/redis/go-redis/v9"
+)
+
+// Usage — состояние бюджета клиента после попытки списания
+type Usage struct {
+	// Used — израсходовано за окно, включая текущий запрос, если он принят
+	Used    int
+	Allowed bool
+}
+
+// Budget — скользящий бюджет стоимости запросов на ключ (пользователь или IP).
+// Расход за окно считается как расход текущего окна плюс доля предыдущего,
+// пропорциональная еще не прошедшей части окна.
+type Budget interface {
+	// Spend списывает cost, если расход за окно не превысит limit; иначе ничего не списывает
+	Spend(ctx context.Context, key string, cost, limit int) (Usage, error)
+}
+
+// rolling — расход за окно по счетчикам текущего и предыдущего окон
+func rolling(prev, cur int, elapsed, window time.Duration) int {
+	return prev*int(window-elapsed)/int(window) + cur
+}
+
+// MemoryBudget — бюджет в памяти процесса (одна реплика, тесты)
+type MemoryBudget struct {
+	window time.Duration
+	now    func() time.Time
+
+	mu        sync.Mutex
+	counters  map[string]*counter
+	lastSweep time.Time
+}
+
+type counter struct {
+	start     time.Time
+	prev, cur int
+}
+
+func NewMemoryBudget(window time.Duration) *MemoryBudget {
+	return &MemoryBudget{window: window, now: time.Now, counters: make(map[string]*counter)}
+}
+
+func (b *MemoryBudget) Spend(_ context.Context, key string, cost, limit int) (Usage, error) {
+	now := b.now()
+	start := now.Truncate(b.window)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sweep(now)
+
+	c := b.counters[key]
+	if c == nil {
+		c = &counter{start: start}
+		b.counters[key] = c
+	}
+	switch {
+	case c.start.Equal(start):
+	case c.start.Add(b.window).Equal(start):
+		c.start, c.prev, c.cur = start, c.cur, 0
+	default:
+		c.start, c.prev, c.cur = start, 0, 0
+	}
+
+	used := rolling(c.prev, c.cur, now.Sub(start), b.window)
+	if used+cost > limit {
+		return Usage{Used: used}, nil
+	}
+	c.cur += cost
+	return Usage{Used: used + cost, Allowed: true}, nil
+}
+
+// sweep раз в окно удаляет счетчики, которые уже не влияют на расход
+func (b *MemoryBudget) sweep(now time.Time) {
+	if now.Sub(b.lastSweep) < b.window {
+		return
+	}
+	b.lastSweep = now
+	for key, c := range b.counters {
+		if now.Sub(c.start) >= 2*b.window {
+			delete(b.counters, key)
+		}
+	}
+}
+
+// spendScript атомарно проверяет и списывает стоимость.
+// KEYS: счетчик предыдущего окна, текущего; ARGV: доля предыдущего окна в промилле, стоимость, лимит, TTL в мс
+var spendScript = redis.NewScript(`
+local prev = tonumber(redis.call('GET', KEYS[1]) or '0')
+local cur = tonumber(redis.call('GET', KEYS[2]) or '0')
+local used = math.floor(prev * tonumber(ARGV[1]) / 1000) + cur
+local cost = tonumber(ARGV[2])
+if used + cost > tonumber(ARGV[3]) then
+  return {used, 0}
+end
+redis.call('INCRBY', KEYS[2], cost)
+redis.call('PEXPIRE', KEYS[2], ARGV[4])
+return {used + cost, 1}
+`)
+
+// RedisBudget — бюджет в Redis, общий для всех реплик
+type RedisBudget struct {
+	rdb    *redis.Client
+	prefix string
+	window time.Duration
+	now    func() time.Time
+}
+
+func NewRedisBudget(rdb *redis.Client, prefix string, window time.Duration) *RedisBudget {
+	return &RedisBudget{rdb: rdb, prefix: prefix, window: window, now: time.Now}
+}
+
+func (b *RedisBudget) Spend(ctx context.Context, key string, cost, limit int) (Usage, error) {
+	now := b.now()
+	start := now.Truncate(b.window)
+	slot := func(t time.Time) string { return b.prefix + key + ":" + strconv.FormatInt(t.Unix(), 10) }
+	share := int64(b.window-now.Sub(start)) * 1000 / int64(b.window)
+
+	res, err := spendScript.Run(ctx, b.rdb,
+		[]string{slot(start.Add(-b.window)), slot(start)},
+		share, cost, limit, (2 * b.window).Milliseconds(),
+	).Int64Slice()
+	if err != nil {
+		return Usage{}, err
+	}
+	return Usage{Used: int(res[0]), Allowed: res[1] == 1}, nil
+}
@@ -0,0 +1,178 @@
+package cost
+
+import (
+	"encoding/json"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+const (
+	// DefaultPageSize — множитель поля с аргументами пагинации, если они не переданы (как pagination.DefaultLimit)
+	DefaultPageSize = 20
+	// DefaultListSize — ожидаемый размер списка без аргументов пагинации
+	DefaultListSize = 50
+	// MutationWeight — вес корневого поля мутации без явного @cost(weight)
+	MutationWeight = 10
+
+	// maxMultiplier не дает переполнить int огромными first/limit
+	maxMultiplier = 10000
+)
+
+var defaultMultipliers = []string{"first", "last", "limit"}
+
+// rule — параметры директивы @cost поля
+type rule struct {
+	weight      int
+	hasWeight   bool
+	multipliers []string
+	assumedSize int
+}
+
+func ruleFor(def *ast.FieldDefinition) rule {
+	r := rule{multipliers: defaultMultipliers, assumedSize: DefaultListSize}
+	d := def.Directives.ForName("cost")
+	if d == nil {
+		return r
+	}
+	if arg := d.Arguments.ForName("weight"); arg != nil {
+		if n, ok := argInt(arg.Value); ok {
+			r.weight, r.hasWeight = n, true
+		}
+	}
+	if arg := d.Arguments.ForName("assumedSize"); arg != nil {
+		if n, ok := argInt(arg.Value); ok {
+			r.assumedSize = n
+		}
+	}
+	if arg := d.Arguments.ForName("multipliers"); arg != nil {
+		r.multipliers = nil
+		for _, c := range arg.Value.Children {
+			r.multipliers = append(r.multipliers, c.Value.Raw)
+		}
+	}
+	return r
+}
+
+func argInt(v *ast.Value) (int, bool) {
+	raw, err := v.Value(nil)
+	if err != nil {
+		return 0, false
+	}
+	return toInt(raw)
+}
+
+func toInt(v any) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case int32:
+		return int(n), true
+	case int64:
+		return int(n), true
+	case float64:
+		return int(n), true
+	case json.Number:
+		i, err := n.Int64()
+		return int(i), err == nil
+	}
+	return 0, false
+}
+
+// Calculate оценивает стоимость операции до выполнения.
+//
+// Скаляры бесплатны, объект стоит 1, вес можно переопределить директивой @cost(weight).
+// Стоимость вложенной выборки умножается на сумму аргументов пагинации (first, last, limit или
+// @cost(multipliers)); если поле их объявляет, но они не переданы — на DefaultPageSize.
+// Список без аргументов пагинации умножается на @cost(assumedSize) или DefaultListSize,
+// кроме первого списка внутри уже умноженного поля (edges соединения).
+func Calculate(schema *ast.Schema, op *ast.OperationDefinition, vars map[string]any) int {
+	w := walker{schema: schema, vars: vars}
+	weight := -1
+	if op.Operation == ast.Mutation {
+		weight = MutationWeight
+	}
+	return w.selectionSet(op.SelectionSet, false, weight)
+}
+
+type walker struct {
+	schema *ast.Schema
+	vars   map[string]any
+}
+
+// selectionSet суммирует поля выборки; фрагменты раскрываются на месте
+func (w walker) selectionSet(set ast.SelectionSet, sized bool, weight int) int {
+	total := 0
+	for _, sel := range set {
+		switch s := sel.(type) {
+		case *ast.Field:
+			total += w.field(s, sized, weight)
+		case *ast.InlineFragment:
+			total += w.selectionSet(s.SelectionSet, sized, weight)
+		case *ast.FragmentSpread:
+			if s.Definition != nil {
+				total += w.selectionSet(s.Definition.SelectionSet, sized, weight)
+			}
+		}
+	}
+	return total
+}
+
+func (w walker) field(f *ast.Field, sized bool, defaultWeight int) int {
+	if f.Definition == nil {
+		return 0
+	}
+	r := ruleFor(f.Definition)
+	t := w.schema.Types[f.Definition.Type.Name()]
+	composite := t != nil && t.IsCompositeType()
+
+	weight := 0
+	switch {
+	case r.hasWeight:
+		weight = r.weight
+	case defaultWeight >= 0:
+		weight = defaultWeight
+	case composite:
+		weight = 1
+	}
+	if !composite {
+		return weight
+	}
+
+	list := f.Definition.Type.Elem != nil
+	mult, ok := w.multiplier(f, r)
+	switch {
+	case ok:
+		sized = true
+	case list && sized:
+		mult, sized = 1, false
+	case list:
+		mult = r.assumedSize
+	default:
+		mult = 1
+	}
+	return weight + mult*w.selectionSet(f.SelectionSet, sized, -1)
+}
+
+// multiplier — сумма аргументов пагинации поля; ok=false, если поле их не объявляет
+func (w walker) multiplier(f *ast.Field, r rule) (int, bool) {
+	declared := false
+	for _, name := range r.multipliers {
+		if f.Definition.Arguments.ForName(name) != nil {
+			declared = true
+		}
+	}
+	if !declared {
+		return 0, false
+	}
+	args := f.ArgumentMap(w.vars)
+	sum, given := 0, false
+	for _, name := range r.multipliers {
+		if n, ok := toInt(args[name]); ok {
+			sum, given = sum+max(n, 0), true
+		}
+	}
+	if !given {
+		return DefaultPageSize, true
+	}
+	return min(sum, maxMultiplier), true
+}
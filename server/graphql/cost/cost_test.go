@@ -0,0 +1,158 @@
+package cost
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2"
+
+	"stormlink/server/graphql"
+)
+
+var schema = graphql.NewExecutableSchema(graphql.Config{Resolvers: &graphql.Resolver{}}).Schema()
+
+func calculate(t *testing.T, query string, vars map[string]any) int {
+	t.Helper()
+	doc, errs := gqlparser.LoadQuery(schema, query)
+	require.Empty(t, errs)
+	return Calculate(schema, doc.Operations[0], vars)
+}
+
+func TestCalculate(t *testing.T) {
+	t.Run("scalars are free", func(t *testing.T) {
+		assert.Equal(t, 1, calculate(t, `{ post(id: 1) { id title } }`, nil))
+		assert.Equal(t, 2, calculate(t, `{ post(id: 1) { id author { name } } }`, nil))
+	})
+
+	t.Run("connection multiplies by first once", func(t *testing.T) {
+		q := `query($n: Int) { postsConnection(first: $n) { totalCount edges { cursor node { id author { name } } } pageInfo { hasNextPage } } }`
+		// 1 + n * (edges 1 + node 1 + author 1) + pageInfo 1
+		assert.Equal(t, 1+10*(3+1), calculate(t, q, map[string]any{"n": json.Number("10")}))
+		assert.Equal(t, 1+50*(3+1), calculate(t, q, map[string]any{"n": int64(50)}))
+		// Без first — размер страницы по умолчанию
+		assert.Equal(t, 1+DefaultPageSize*(3+1), calculate(t, q, nil))
+	})
+
+	t.Run("expensive fields weigh more", func(t *testing.T) {
+		feed := calculate(t, `{ feedPostsConnection(first: 10) { edges { node { id } } } }`, nil)
+		posts := calculate(t, `{ postsConnection(first: 10) { edges { node { id } } } }`, nil)
+		assert.Equal(t, 19, feed-posts)
+	})
+
+	t.Run("lists without arguments use assumed size", func(t *testing.T) {
+		assert.Equal(t, 1+100, calculate(t, `{ users { id avatar { id } } }`, nil))
+		assert.Equal(t, 1+DefaultListSize, calculate(t, `{ hostRoles { id badge { id } } }`, nil))
+	})
+
+	t.Run("limit argument and custom multipliers", func(t *testing.T) {
+		assert.Equal(t, 1+40*1, calculate(t, `{ commentsByPostIdPage(id: 1) { id author { id } } }`, nil))
+		// commentsWindow: before + after
+		assert.Equal(t, 1+(5+7)*(1+1), calculate(t, `{ commentsWindow(postId: 1, anchorId: 2, before: 5, after: 7) { edges { node { id } } } }`, nil))
+	})
+
+	t.Run("fragments are expanded", func(t *testing.T) {
+		q := `query { postsConnection(first: 2) { edges { node { ...P } } } } fragment P on Post { author { id } community { id } }`
+		assert.Equal(t, 1+2*(1+1+2), calculate(t, q, nil))
+	})
+
+	t.Run("mutations have base weight", func(t *testing.T) {
+		assert.Equal(t, MutationWeight, calculate(t, `mutation { markAllNotificationsRead }`, nil))
+		assert.Equal(t, 1, calculate(t, `mutation { setTyping(postId: 1, typing: true) }`, nil))
+	})
+}
+
+func TestMemoryBudget(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	b := NewMemoryBudget(time.Minute)
+	b.now = func() time.Time { return now }
+
+	u, err := b.Spend(ctx, "user:1", 60, 100)
+	require.NoError(t, err)
+	assert.Equal(t, Usage{Used: 60, Allowed: true}, u)
+
+	u, _ = b.Spend(ctx, "user:1", 50, 100)
+	assert.Equal(t, Usage{Used: 60, Allowed: false}, u, "rejected spend is not charged")
+
+	u, _ = b.Spend(ctx, "user:2", 50, 100)
+	assert.True(t, u.Allowed, "budgets are per key")
+
+	// Через 1.5 окна от предыдущего осталась половина: 60 * 0.5 = 30
+	now = now.Add(90 * time.Second)
+	u, _ = b.Spend(ctx, "user:1", 50, 100)
+	assert.Equal(t, Usage{Used: 80, Allowed: true}, u)
+
+	// Через два окна расход обнуляется
+	now = now.Add(2 * time.Minute)
+	u, _ = b.Spend(ctx, "user:1", 100, 100)
+	assert.Equal(t, Usage{Used: 100, Allowed: true}, u)
+}
+
+func TestLimit(t *testing.T) {
+	srv := handler.New(graphql.NewExecutableSchema(graphql.Config{Resolvers: &graphql.Resolver{}}))
+	srv.AddTransport(transport.POST{})
+	srv.Use(extension.Introspection{})
+	srv.Use(&Limit{
+		MaxCost:         30,
+		Budget:          NewMemoryBudget(time.Minute),
+		UserBudget:      100,
+		AnonymousBudget: 25,
+		ClientKey:       func(context.Context) string { return "203.0.113.7" },
+	})
+
+	do := func(t *testing.T, query string) map[string]any {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/query", strings.NewReader(`{"query": `+strconvQuote(query)+`}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		var resp map[string]any
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		return resp
+	}
+	firstError := func(resp map[string]any) map[string]any {
+		errs, _ := resp["errors"].([]any)
+		if len(errs) == 0 {
+			return nil
+		}
+		ext, _ := errs[0].(map[string]any)["extensions"].(map[string]any)
+		return ext
+	}
+
+	// __schema 1 + queryType 1; резолверы приложения не нужны
+	const introspection = `{ __schema { queryType { name } } }`
+	resp := do(t, introspection)
+	assert.Nil(t, resp["errors"])
+	assert.Equal(t, map[string]any{
+		"requested": float64(2),
+		"limit":     float64(30),
+		"budget":    map[string]any{"limit": float64(25), "remaining": float64(23), "window": float64(60)},
+	}, resp["extensions"].(map[string]any)["cost"])
+
+	resp = do(t, `{ postsConnection(first: 50) { edges { node { id } } } }`)
+	ext := firstError(resp)
+	require.NotNil(t, ext)
+	assert.Equal(t, errCostLimit, ext["code"])
+
+	for i := 0; i < 11; i++ {
+		require.Nil(t, do(t, introspection)["errors"])
+	}
+	ext = firstError(do(t, introspection))
+	require.NotNil(t, ext)
+	assert.Equal(t, errBudgetLimit, ext["code"])
+}
+
+func strconvQuote(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
@@ -0,0 +1,167 @@
+package cost
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	gqlgen "github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"stormlink/shared/auth"
+	redisx "stormlink/shared/redis"
+)
+
+const (
+	extensionName = "CostLimit"
+
+	errCostLimit      = "COST_LIMIT_EXCEEDED"
+	errBudgetLimit    = "COST_BUDGET_EXCEEDED"
+	budgetWindow      = time.Minute
+	budgetRedisPrefix = "stormlink:cost:"
+)
+
+// Stats — стоимость операции и остаток бюджета, отдаются в extensions.cost ответа
+type Stats struct {
+	Requested int          `json:"requested"`
+	Limit     int          `json:"limit"`
+	Budget    *BudgetStats `json:"budget,omitempty"`
+}
+
+type BudgetStats struct {
+	Limit     int `json:"limit"`
+	Remaining int `json:"remaining"`
+	// Window — длина скользящего окна в секундах
+	Window int `json:"window"`
+}
+
+// Limit оценивает стоимость операции (Calculate), отклоняет операции дороже MaxCost
+// и списывает стоимость из скользящего бюджета пользователя, а для анонимов — IP.
+type Limit struct {
+	MaxCost int
+	// Budget == nil отключает бюджеты
+	Budget          Budget
+	UserBudget      int
+	AnonymousBudget int
+	// ClientKey — IP клиента для анонимных запросов
+	ClientKey func(ctx context.Context) string
+
+	schema *ast.Schema
+}
+
+var _ interface {
+	gqlgen.OperationContextMutator
+	gqlgen.ResponseInterceptor
+	gqlgen.HandlerExtension
+} = &Limit{}
+
+func (l *Limit) ExtensionName() string { return extensionName }
+
+func (l *Limit) Validate(es gqlgen.ExecutableSchema) error {
+	if l.MaxCost <= 0 {
+		return errors.New("CostLimit.MaxCost must be positive")
+	}
+	l.schema = es.Schema()
+	return nil
+}
+
+func (l *Limit) MutateOperationContext(ctx context.Context, opCtx *gqlgen.OperationContext) *gqlerror.Error {
+	stats := &Stats{Requested: Calculate(l.schema, opCtx.Operation, opCtx.Variables), Limit: l.MaxCost}
+	opCtx.Stats.SetExtension(extensionName, stats)
+
+	if stats.Requested > l.MaxCost {
+		return rejected(stats, errCostLimit, "operation cost %d exceeds the limit of %d", stats.Requested, l.MaxCost)
+	}
+	if l.Budget == nil {
+		return nil
+	}
+
+	key, limit := l.client(ctx)
+	usage, err := l.Budget.Spend(ctx, key, stats.Requested, limit)
+	if err != nil {
+		// Недоступный Redis не должен ронять API: пропускаем без учета
+		log.Printf("⚠️ cost budget: %v", err)
+		return nil
+	}
+	stats.Budget = &BudgetStats{Limit: limit, Remaining: max(limit-usage.Used, 0), Window: int(budgetWindow.Seconds())}
+	if !usage.Allowed {
+		return rejected(stats, errBudgetLimit, "query budget exceeded: %d of %d used in the last %s", usage.Used, limit, budgetWindow)
+	}
+	return nil
+}
+
+func (l *Limit) InterceptResponse(ctx context.Context, next gqlgen.ResponseHandler) *gqlgen.Response {
+	resp := next(ctx)
+	if resp == nil || !gqlgen.HasOperationContext(ctx) {
+		return resp
+	}
+	if stats, ok := gqlgen.GetOperationContext(ctx).Stats.GetExtension(extensionName).(*Stats); ok {
+		if resp.Extensions == nil {
+			resp.Extensions = map[string]any{}
+		}
+		resp.Extensions["cost"] = stats
+	}
+	return resp
+}
+
+// client — ключ бюджета и его размер: авторизованным — по пользователю, остальным — по IP
+func (l *Limit) client(ctx context.Context) (string, int) {
+	if userID, err := auth.UserIDFromContext(ctx); err == nil {
+		return "user:" + strconv.Itoa(userID), l.UserBudget
+	}
+	ip := ""
+	if l.ClientKey != nil {
+		ip = l.ClientKey(ctx)
+	}
+	return "ip:" + ip, l.AnonymousBudget
+}
+
+func rejected(stats *Stats, code string, format string, args ...any) *gqlerror.Error {
+	err := gqlerror.Errorf(format, args...)
+	errcode.Set(err, code)
+	err.Extensions["cost"] = stats
+	return err
+}
+
+// FromEnv настраивает анализ стоимости из ENV:
+//
+//	GRAPHQL_MAX_COST — предел стоимости одной операции (по умолчанию 1000)
+//	GRAPHQL_COST_BUDGET — бюджет пользователя за минуту (по умолчанию 10000)
+//	GRAPHQL_COST_BUDGET_ANONYMOUS — бюджет IP без авторизации за минуту (по умолчанию 2000)
+//	GRAPHQL_COST_BUDGET_STORE=memory|redis|off — где хранить расход (по умолчанию memory)
+func FromEnv(clientKey func(ctx context.Context) string) (*Limit, error) {
+	l := &Limit{MaxCost: 1000, UserBudget: 10000, AnonymousBudget: 2000, ClientKey: clientKey}
+	for env, dst := range map[string]*int{
+		"GRAPHQL_MAX_COST":              &l.MaxCost,
+		"GRAPHQL_COST_BUDGET":           &l.UserBudget,
+		"GRAPHQL_COST_BUDGET_ANONYMOUS": &l.AnonymousBudget,
+	} {
+		if v := os.Getenv(env); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("invalid %s %q", env, v)
+			}
+			*dst = n
+		}
+	}
+	switch store := os.Getenv("GRAPHQL_COST_BUDGET_STORE"); store {
+	case "", "memory":
+		l.Budget = NewMemoryBudget(budgetWindow)
+	case "redis":
+		rdb, err := redisx.NewClient()
+		if err != nil {
+			return nil, err
+		}
+		l.Budget = NewRedisBudget(rdb, budgetRedisPrefix, budgetWindow)
+	case "off":
+	default:
+		return nil, fmt.Errorf("unknown GRAPHQL_COST_BUDGET_STORE %q", store)
+	}
+	return l, nil
+}
@@ -0,0 +1,145 @@
+package dataloader
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultWait — сколько ждать, пока соседние резолверы запросят свои ключи
+	DefaultWait = 2 * time.Millisecond
+	// DefaultMaxBatch — максимум ключей в одном запросе к БД
+	DefaultMaxBatch = 500
+)
+
+// FetchFunc загружает значения для набора ключей; отсутствующие ключи получают нулевое значение
+type FetchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// Loader собирает ключи, запрошенные резолверами в течение короткого окна, и загружает их одним запросом.
+// Результаты кешируются на время жизни загрузчика (одна операция GraphQL).
+// С wait = 0 загрузчик работает без пакетирования: каждый Load сразу вызывает fetch.
+type Loader[K comparable, V any] struct {
+	fetch    FetchFunc[K, V]
+	wait     time.Duration
+	maxBatch int
+
+	mu    sync.Mutex
+	cache map[K]*thunk[V]
+	batch *batch[K, V]
+}
+
+type thunk[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+type batch[K comparable, V any] struct {
+	keys   []K
+	thunks []*thunk[V]
+	full   chan struct{}
+}
+
+func NewLoader[K comparable, V any](wait time.Duration, fetch FetchFunc[K, V]) *Loader[K, V] {
+	return &Loader[K, V]{
+		fetch:    fetch,
+		wait:     wait,
+		maxBatch: DefaultMaxBatch,
+		cache:    map[K]*thunk[V]{},
+	}
+}
+
+// Load отдает значение по ключу, присоединяясь к текущему пакету загрузки
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	if l.wait <= 0 {
+		values, err := l.fetch(ctx, []K{key})
+		return values[key], err
+	}
+	return l.enqueue(ctx, key).get(ctx)
+}
+
+// LoadAll отдает значения по нескольким ключам; все ключи попадают в один пакет
+func (l *Loader[K, V]) LoadAll(ctx context.Context, keys []K) ([]V, error) {
+	out := make([]V, len(keys))
+	if len(keys) == 0 {
+		return out, nil
+	}
+	if l.wait <= 0 {
+		values, err := l.fetch(ctx, keys)
+		if err != nil {
+			return nil, err
+		}
+		for i, key := range keys {
+			out[i] = values[key]
+		}
+		return out, nil
+	}
+	thunks := make([]*thunk[V], len(keys))
+	for i, key := range keys {
+		thunks[i] = l.enqueue(ctx, key)
+	}
+	for i, t := range thunks {
+		v, err := t.get(ctx)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = v
+	}
+	return out, nil
+}
+
+// enqueue отдает закешированный результат или добавляет ключ в текущий пакет
+func (l *Loader[K, V]) enqueue(ctx context.Context, key K) *thunk[V] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if t, ok := l.cache[key]; ok {
+		return t
+	}
+	t := &thunk[V]{done: make(chan struct{})}
+	l.cache[key] = t
+	if l.batch == nil {
+		l.batch = &batch[K, V]{full: make(chan struct{})}
+		go l.run(ctx, l.batch)
+	}
+	b := l.batch
+	b.keys = append(b.keys, key)
+	b.thunks = append(b.thunks, t)
+	if len(b.keys) >= l.maxBatch {
+		// Пакет заполнен — следующие ключи попадут в новый
+		l.batch = nil
+		close(b.full)
+	}
+	return t
+}
+
+func (l *Loader[K, V]) run(ctx context.Context, b *batch[K, V]) {
+	timer := time.NewTimer(l.wait)
+	select {
+	case <-timer.C:
+		l.mu.Lock()
+		if l.batch == b {
+			l.batch = nil
+		}
+		l.mu.Unlock()
+	case <-b.full:
+		timer.Stop()
+	}
+
+	values, err := l.fetch(ctx, b.keys)
+	for i, key := range b.keys {
+		t := b.thunks[i]
+		t.value, t.err = values[key], err
+		close(t.done)
+	}
+}
+
+func (t *thunk[V]) get(ctx context.Context) (V, error) {
+	select {
+	case <-t.done:
+		return t.value, t.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
@@ -0,0 +1,103 @@
+package dataloader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingFetch удваивает ключи и запоминает состав каждого пакета
+type recordingFetch struct {
+	mu      sync.Mutex
+	batches [][]int
+}
+
+func (f *recordingFetch) fetch(_ context.Context, keys []int) (map[int]int, error) {
+	f.mu.Lock()
+	f.batches = append(f.batches, append([]int(nil), keys...))
+	f.mu.Unlock()
+	out := make(map[int]int, len(keys))
+	for _, k := range keys {
+		if k > 0 {
+			out[k] = k * 2
+		}
+	}
+	return out, nil
+}
+
+func TestLoadBatchesConcurrentKeys(t *testing.T) {
+	f := &recordingFetch{}
+	l := NewLoader(10*time.Millisecond, f.fetch)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	results := make([]int, 5)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			v, err := l.Load(ctx, i+1)
+			assert.NoError(t, err)
+			results[i] = v
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, []int{2, 4, 6, 8, 10}, results)
+	require.Len(t, f.batches, 1)
+	assert.ElementsMatch(t, []int{1, 2, 3, 4, 5}, f.batches[0])
+
+	// Повторный ключ берется из кеша, отсутствующий получает нулевое значение
+	v, err := l.Load(ctx, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 6, v)
+	v, err = l.Load(ctx, -1)
+	require.NoError(t, err)
+	assert.Zero(t, v)
+	assert.Len(t, f.batches, 2)
+}
+
+func TestLoadAllSplitsByMaxBatch(t *testing.T) {
+	f := &recordingFetch{}
+	l := NewLoader(time.Millisecond, f.fetch)
+	l.maxBatch = 2
+
+	values, err := l.LoadAll(context.Background(), []int{1, 2, 3, 2})
+	require.NoError(t, err)
+	assert.Equal(t, []int{2, 4, 6, 4}, values)
+	require.Len(t, f.batches, 2)
+	assert.Equal(t, []int{1, 2}, f.batches[0])
+	assert.Equal(t, []int{3}, f.batches[1])
+}
+
+func TestLoadPropagatesError(t *testing.T) {
+	boom := errors.New("boom")
+	l := NewLoader(time.Millisecond, func(context.Context, []int) (map[int]int, error) { return nil, boom })
+
+	_, err := l.Load(context.Background(), 1)
+	assert.ErrorIs(t, err, boom)
+	_, err = l.LoadAll(context.Background(), []int{1, 2})
+	assert.ErrorIs(t, err, boom)
+}
+
+func TestLoadWithoutWaitFetchesImmediately(t *testing.T) {
+	var calls atomic.Int32
+	l := NewLoader(0, func(_ context.Context, keys []int) (map[int]int, error) {
+		calls.Add(1)
+		return map[int]int{keys[0]: 1}, nil
+	})
+
+	// Без окна ожидания нет ни пакетирования, ни кеша: данные могли измениться между вызовами
+	for i := 0; i < 3; i++ {
+		v, err := l.Load(context.Background(), 7)
+		require.NoError(t, err)
+		assert.Equal(t, 1, v)
+	}
+	assert.EqualValues(t, 3, calls.Load())
+}
@@ -0,0 +1,263 @@
+package dataloader
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	gqlgen "github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+
+	"stormlink/server/ent"
+	"stormlink/server/ent/bookmark"
+	"stormlink/server/ent/comment"
+	"stormlink/server/ent/commentlike"
+	"stormlink/server/ent/community"
+	"stormlink/server/ent/communityfollow"
+	"stormlink/server/ent/media"
+	"stormlink/server/ent/post"
+	"stormlink/server/ent/postlike"
+	"stormlink/server/ent/user"
+	"stormlink/server/ent/userfollow"
+	"stormlink/shared/auth"
+)
+
+// PostCounts — счётчики поста
+type PostCounts struct {
+	Likes     int
+	Comments  int
+	Bookmarks int
+}
+
+// PostViewer — флаги поста для текущего пользователя
+type PostViewer struct {
+	IsLiked     bool
+	HasBookmark bool
+}
+
+// Loaders — загрузчики одной операции GraphQL. Вместо запроса на каждый родительский объект
+// резолверы списка откладывают ключи, и каждый тип данных загружается одним запросом на пакет.
+type Loaders struct {
+	client   *ent.Client
+	viewerID int
+
+	User      *Loader[int, *ent.User]
+	Media     *Loader[int, *ent.Media]
+	Community *Loader[int, *ent.Community]
+	Post      *Loader[int, *ent.Post]
+
+	PostCounts        *Loader[int, PostCounts]
+	PostViewer        *Loader[int, PostViewer]
+	CommentLikesCount *Loader[int, int]
+	CommentLiked      *Loader[int, bool]
+
+	PostLikes          *Loader[int, []*ent.PostLike]
+	PostBookmarks      *Loader[int, []*ent.Bookmark]
+	CommentLikes       *Loader[int, []*ent.CommentLike]
+	UserFollowers      *Loader[int, []*ent.UserFollow]
+	UserFollowing      *Loader[int, []*ent.UserFollow]
+	CommunityFollowers *Loader[int, []*ent.CommunityFollow]
+
+	hostOnce  sync.Once
+	hostOwner int
+}
+
+// New создает загрузчики для пользователя viewerID (0 — аноним); wait = 0 отключает пакетирование
+func New(client *ent.Client, viewerID int, wait time.Duration) *Loaders {
+	l := &Loaders{client: client, viewerID: viewerID}
+
+	l.User = NewLoader(wait, func(ctx context.Context, ids []int) (map[int]*ent.User, error) {
+		items, err := client.User.Query().Where(user.IDIn(ids...)).All(ctx)
+		return index(items, err, func(v *ent.User) int { return v.ID })
+	})
+	l.Media = NewLoader(wait, func(ctx context.Context, ids []int) (map[int]*ent.Media, error) {
+		items, err := client.Media.Query().Where(media.IDIn(ids...)).All(ctx)
+		return index(items, err, func(v *ent.Media) int { return v.ID })
+	})
+	l.Community = NewLoader(wait, func(ctx context.Context, ids []int) (map[int]*ent.Community, error) {
+		items, err := client.Community.Query().Where(community.IDIn(ids...)).All(ctx)
+		return index(items, err, func(v *ent.Community) int { return v.ID })
+	})
+
+	l.Post = NewLoader(wait, func(ctx context.Context, ids []int) (map[int]*ent.Post, error) {
+		items, err := client.Post.Query().Where(post.IDIn(ids...)).All(ctx)
+		return index(items, err, func(v *ent.Post) int { return v.ID })
+	})
+
+	l.PostCounts = NewLoader(wait, l.fetchPostCounts)
+	l.PostViewer = NewLoader(wait, l.fetchPostViewer)
+	l.CommentLikesCount = NewLoader(wait, func(ctx context.Context, ids []int) (map[int]int, error) {
+		return countBy(ctx, client.CommentLike.Query().Where(commentlike.CommentIDIn(ids...)).
+			GroupBy(commentlike.FieldCommentID).Aggregate(ent.Count()))
+	})
+	l.CommentLiked = NewLoader(wait, func(ctx context.Context, ids []int) (map[int]bool, error) {
+		if viewerID == 0 {
+			return nil, nil
+		}
+		liked, err := client.CommentLike.Query().
+			Where(commentlike.UserIDEQ(viewerID), commentlike.CommentIDIn(ids...)).
+			Select(commentlike.FieldCommentID).
+			Ints(ctx)
+		return set(liked), err
+	})
+
+	l.PostLikes = NewLoader(wait, func(ctx context.Context, ids []int) (map[int][]*ent.PostLike, error) {
+		items, err := client.PostLike.Query().Where(postlike.PostIDIn(ids...)).All(ctx)
+		return group(items, err, func(v *ent.PostLike) int { return v.PostID })
+	})
+	l.PostBookmarks = NewLoader(wait, func(ctx context.Context, ids []int) (map[int][]*ent.Bookmark, error) {
+		items, err := client.Bookmark.Query().Where(bookmark.PostIDIn(ids...)).All(ctx)
+		return group(items, err, func(v *ent.Bookmark) int { return v.PostID })
+	})
+	l.CommentLikes = NewLoader(wait, func(ctx context.Context, ids []int) (map[int][]*ent.CommentLike, error) {
+		items, err := client.CommentLike.Query().Where(commentlike.CommentIDIn(ids...)).All(ctx)
+		return group(items, err, func(v *ent.CommentLike) int { return v.CommentID })
+	})
+	l.UserFollowers = NewLoader(wait, func(ctx context.Context, ids []int) (map[int][]*ent.UserFollow, error) {
+		items, err := client.UserFollow.Query().Where(userfollow.FolloweeIDIn(ids...)).All(ctx)
+		return group(items, err, func(v *ent.UserFollow) int { return v.FolloweeID })
+	})
+	l.UserFollowing = NewLoader(wait, func(ctx context.Context, ids []int) (map[int][]*ent.UserFollow, error) {
+		items, err := client.UserFollow.Query().Where(userfollow.FollowerIDIn(ids...)).All(ctx)
+		return group(items, err, func(v *ent.UserFollow) int { return v.FollowerID })
+	})
+	l.CommunityFollowers = NewLoader(wait, func(ctx context.Context, ids []int) (map[int][]*ent.CommunityFollow, error) {
+		items, err := client.CommunityFollow.Query().Where(communityfollow.CommunityIDIn(ids...)).All(ctx)
+		return group(items, err, func(v *ent.CommunityFollow) int { return v.CommunityID })
+	})
+	return l
+}
+
+// ViewerID — пользователь, для которого считаются флаги (0 — аноним)
+func (l *Loaders) ViewerID() int { return l.viewerID }
+
+// HostOwnerID отдает владельца платформы (0 — не задан); читается один раз на операцию
+func (l *Loaders) HostOwnerID(ctx context.Context) int {
+	l.hostOnce.Do(func() {
+		if h, err := l.client.Host.Get(ctx, 1); err == nil && h.OwnerID != nil {
+			l.hostOwner = *h.OwnerID
+		}
+	})
+	return l.hostOwner
+}
+
+func (l *Loaders) fetchPostCounts(ctx context.Context, ids []int) (map[int]PostCounts, error) {
+	likes, err := countBy(ctx, l.client.PostLike.Query().Where(postlike.PostIDIn(ids...)).GroupBy(postlike.FieldPostID).Aggregate(ent.Count()))
+	if err != nil {
+		return nil, err
+	}
+	comments, err := countBy(ctx, l.client.Comment.Query().Where(comment.PostIDIn(ids...)).GroupBy(comment.FieldPostID).Aggregate(ent.Count()))
+	if err != nil {
+		return nil, err
+	}
+	bookmarks, err := countBy(ctx, l.client.Bookmark.Query().Where(bookmark.PostIDIn(ids...)).GroupBy(bookmark.FieldPostID).Aggregate(ent.Count()))
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[int]PostCounts, len(ids))
+	for _, id := range ids {
+		out[id] = PostCounts{Likes: likes[id], Comments: comments[id], Bookmarks: bookmarks[id]}
+	}
+	return out, nil
+}
+
+func (l *Loaders) fetchPostViewer(ctx context.Context, ids []int) (map[int]PostViewer, error) {
+	if l.viewerID == 0 {
+		return nil, nil
+	}
+	liked, err := l.client.PostLike.Query().
+		Where(postlike.UserIDEQ(l.viewerID), postlike.PostIDIn(ids...)).
+		Select(postlike.FieldPostID).
+		Ints(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bookmarked, err := l.client.Bookmark.Query().
+		Where(bookmark.UserIDEQ(l.viewerID), bookmark.PostIDIn(ids...)).
+		Select(bookmark.FieldPostID).
+		Ints(ctx)
+	if err != nil {
+		return nil, err
+	}
+	isLiked, hasBookmark := set(liked), set(bookmarked)
+	out := make(map[int]PostViewer, len(ids))
+	for _, id := range ids {
+		out[id] = PostViewer{IsLiked: isLiked[id], HasBookmark: hasBookmark[id]}
+	}
+	return out, nil
+}
+
+type loadersKey struct{}
+
+// FromContext отдает загрузчики текущей операции (nil — middleware не установлен или это не query)
+func FromContext(ctx context.Context) *Loaders {
+	l, _ := ctx.Value(loadersKey{}).(*Loaders)
+	return l
+}
+
+// Middleware создает загрузчики на каждую операцию query.
+// Мутации и подписки работают без общего кеша: данные в них меняются в ходе операции.
+func Middleware(client *ent.Client) gqlgen.OperationMiddleware {
+	return func(ctx context.Context, next gqlgen.OperationHandler) gqlgen.ResponseHandler {
+		op := gqlgen.GetOperationContext(ctx).Operation
+		if op == nil || op.Operation != ast.Query {
+			return next(ctx)
+		}
+		viewerID, _ := auth.UserIDFromContext(ctx)
+		return next(context.WithValue(ctx, loadersKey{}, New(client, viewerID, DefaultWait)))
+	}
+}
+
+// index раскладывает сущности по ID
+func index[T any](items []T, err error, id func(T) int) (map[int]T, error) {
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[int]T, len(items))
+	for _, it := range items {
+		out[id(it)] = it
+	}
+	return out, nil
+}
+
+// group раскладывает строки по родительскому ключу
+func group[T any](items []T, err error, key func(T) int) (map[int][]T, error) {
+	if err != nil {
+		return nil, err
+	}
+	out := map[int][]T{}
+	for _, it := range items {
+		out[key(it)] = append(out[key(it)], it)
+	}
+	return out, nil
+}
+
+// countRow — строка SELECT <key>, COUNT(*) ... GROUP BY <key>; заполнено только поле группировки
+type countRow struct {
+	PostID    int `json:"post_id"`
+	CommentID int `json:"comment_id"`
+	Count     int `json:"count"`
+}
+
+// countBy считает строки по ключу группировки (post_id или comment_id)
+func countBy(ctx context.Context, g interface {
+	Scan(context.Context, any) error
+}) (map[int]int, error) {
+	var rows []countRow
+	if err := g.Scan(ctx, &rows); err != nil {
+		return nil, err
+	}
+	out := make(map[int]int, len(rows))
+	for _, r := range rows {
+		out[r.PostID+r.CommentID] = r.Count
+	}
+	return out, nil
+}
+
+func set(ids []int) map[int]bool {
+	out := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		out[id] = true
+	}
+	return out
+}
@@ -9,16 +9,52 @@ import (
 	"fmt"
 	"stormlink/server/ent"
 	"stormlink/server/graphql/models"
+	"strconv"
 )
 
+// Author is the resolver for the author field.
+func (r *commentResolver) Author(ctx context.Context, obj *ent.Comment) (*ent.User, error) {
+	return loadEdge(ctx, obj.Edges.Author, r.loaders(ctx).User, obj.AuthorID, "user")
+}
+
+// Post is the resolver for the post field.
+func (r *commentResolver) Post(ctx context.Context, obj *ent.Comment) (*ent.Post, error) {
+	return loadEdge(ctx, obj.Edges.Post, r.loaders(ctx).Post, obj.PostID, "post")
+}
+
+// Community is the resolver for the community field.
+func (r *commentResolver) Community(ctx context.Context, obj *ent.Comment) (*ent.Community, error) {
+	return loadEdge(ctx, obj.Edges.Community, r.loaders(ctx).Community, obj.CommunityID, "community")
+}
+
+// Media is the resolver for the media field.
+func (r *commentResolver) Media(ctx context.Context, obj *ent.Comment) (*ent.Media, error) {
+	return loadOptionalEdge(ctx, obj.Edges.Media, r.loaders(ctx).Media, obj.MediaID)
+}
+
 // Likes is the resolver for the likes field.
 func (r *commentResolver) Likes(ctx context.Context, obj *ent.Comment) ([]*models.CommentLike, error) {
-	panic(fmt.Errorf("not implemented: Likes - likes"))
+	return r.commentLikes(ctx, obj)
+}
+
+// Logo is the resolver for the logo field.
+func (r *communityResolver) Logo(ctx context.Context, obj *ent.Community) (*ent.Media, error) {
+	return loadOptionalEdge(ctx, obj.Edges.Logo, r.loaders(ctx).Media, obj.LogoID)
+}
+
+// Banner is the resolver for the banner field.
+func (r *communityResolver) Banner(ctx context.Context, obj *ent.Community) (*ent.Media, error) {
+	return loadOptionalEdge(ctx, obj.Edges.Banner, r.loaders(ctx).Media, obj.BannerID)
+}
+
+// Owner is the resolver for the owner field.
+func (r *communityResolver) Owner(ctx context.Context, obj *ent.Community) (*ent.User, error) {
+	return loadEdge(ctx, obj.Edges.Owner, r.loaders(ctx).User, obj.OwnerID, "user")
 }
 
 // Followers is the resolver for the followers field.
 func (r *communityResolver) Followers(ctx context.Context, obj *ent.Community) ([]*models.CommunityFollow, error) {
-	panic(fmt.Errorf("not implemented: Followers - followers"))
+	return r.communityFollowers(ctx, obj)
 }
 
 // Rules is the resolver for the rules field.
@@ -26,14 +62,29 @@ func (r *hostResolver) Rules(ctx context.Context, obj *ent.Host) ([]*models.Host
 	panic(fmt.Errorf("not implemented: Rules - rules"))
 }
 
+// HeroImage is the resolver for the heroImage field.
+func (r *postResolver) HeroImage(ctx context.Context, obj *ent.Post) (*ent.Media, error) {
+	return loadOptionalEdge(ctx, obj.Edges.HeroImage, r.loaders(ctx).Media, obj.HeroImageID)
+}
+
+// Community is the resolver for the community field.
+func (r *postResolver) Community(ctx context.Context, obj *ent.Post) (*ent.Community, error) {
+	return loadEdge(ctx, obj.Edges.Community, r.loaders(ctx).Community, obj.CommunityID, "community")
+}
+
+// Author is the resolver for the author field.
+func (r *postResolver) Author(ctx context.Context, obj *ent.Post) (*ent.User, error) {
+	return loadEdge(ctx, obj.Edges.Author, r.loaders(ctx).User, obj.AuthorID, "user")
+}
+
 // Likes is the resolver for the likes field.
 func (r *postResolver) Likes(ctx context.Context, obj *ent.Post) ([]*models.PostLike, error) {
-	panic(fmt.Errorf("not implemented: Likes - likes"))
+	return r.postLikes(ctx, obj)
 }
 
 // Bookmarks is the resolver for the bookmarks field.
 func (r *postResolver) Bookmarks(ctx context.Context, obj *ent.Post) ([]*models.Bookmark, error) {
-	panic(fmt.Errorf("not implemented: Bookmarks - bookmarks"))
+	return r.postBookmarks(ctx, obj)
 }
 
 // Node is the resolver for the node field.
@@ -46,14 +97,53 @@ func (r *queryResolver) Nodes(ctx context.Context, ids []string) ([]ent.Noder, e
 	panic(fmt.Errorf("not implemented: Nodes - nodes"))
 }
 
+// HostRule is the resolver for the hostRule field.
+func (r *reportResolver) HostRule(ctx context.Context, obj *ent.Report) (*models.HostRule, error) {
+	if obj.HostRuleID == nil {
+		return nil, nil
+	}
+	rule, err := r.Client.HostRule.Get(ctx, *obj.HostRuleID)
+	if ent.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &models.HostRule{
+		ID:          strconv.Itoa(rule.ID),
+		Title:       rule.Title,
+		Description: rule.Description,
+		CreatedAt:   rule.CreatedAt,
+		UpdatedAt:   rule.UpdatedAt,
+	}, nil
+}
+
+// Avatar is the resolver for the avatar field.
+func (r *userResolver) Avatar(ctx context.Context, obj *ent.User) (*ent.Media, error) {
+	return loadOptionalEdge(ctx, obj.Edges.Avatar, r.loaders(ctx).Media, obj.AvatarID)
+}
+
+// Banner is the resolver for the banner field.
+func (r *userResolver) Banner(ctx context.Context, obj *ent.User) (*ent.Media, error) {
+	return loadOptionalEdge(ctx, obj.Edges.Banner, r.loaders(ctx).Media, obj.BannerID)
+}
+
 // Following is the resolver for the following field.
 func (r *userResolver) Following(ctx context.Context, obj *ent.User) ([]*models.UserFollow, error) {
-	panic(fmt.Errorf("not implemented: Following - following"))
+	follows, err := r.loaders(ctx).UserFollowing.Load(ctx, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+	return r.userFollows(ctx, obj, follows, false)
 }
 
 // Followers is the resolver for the followers field.
 func (r *userResolver) Followers(ctx context.Context, obj *ent.User) ([]*models.UserFollow, error) {
-	panic(fmt.Errorf("not implemented: Followers - followers"))
+	follows, err := r.loaders(ctx).UserFollowers.Load(ctx, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+	return r.userFollows(ctx, obj, follows, true)
 }
 
 // CommunitiesFollow is the resolver for the communitiesFollow field.
@@ -87,21 +177,67 @@ func (r *Resolver) Comment() CommentResolver { return &commentResolver{r} }
 // Community returns CommunityResolver implementation.
 func (r *Resolver) Community() CommunityResolver { return &communityResolver{r} }
 
+// CommunityUserBan returns CommunityUserBanResolver implementation.
+func (r *Resolver) CommunityUserBan() CommunityUserBanResolver { return &communityUserBanResolver{r} }
+
+// CommunityUserMute returns CommunityUserMuteResolver implementation.
+func (r *Resolver) CommunityUserMute() CommunityUserMuteResolver {
+	return &communityUserMuteResolver{r}
+}
+
 // Host returns HostResolver implementation.
 func (r *Resolver) Host() HostResolver { return &hostResolver{r} }
 
+// HostCommunityBan returns HostCommunityBanResolver implementation.
+func (r *Resolver) HostCommunityBan() HostCommunityBanResolver { return &hostCommunityBanResolver{r} }
+
+// HostCommunityMute returns HostCommunityMuteResolver implementation.
+func (r *Resolver) HostCommunityMute() HostCommunityMuteResolver {
+	return &hostCommunityMuteResolver{r}
+}
+
+// HostIPBan returns HostIPBanResolver implementation.
+func (r *Resolver) HostIPBan() HostIPBanResolver { return &hostIPBanResolver{r} }
+
+// HostUserBan returns HostUserBanResolver implementation.
+func (r *Resolver) HostUserBan() HostUserBanResolver { return &hostUserBanResolver{r} }
+
+// HostUserMute returns HostUserMuteResolver implementation.
+func (r *Resolver) HostUserMute() HostUserMuteResolver { return &hostUserMuteResolver{r} }
+
+// Notification returns NotificationResolver implementation.
+func (r *Resolver) Notification() NotificationResolver { return &notificationResolver{r} }
+
+// NotificationSettings returns NotificationSettingsResolver implementation.
+func (r *Resolver) NotificationSettings() NotificationSettingsResolver {
+	return &notificationSettingsResolver{r}
+}
+
 // Post returns PostResolver implementation.
 func (r *Resolver) Post() PostResolver { return &postResolver{r} }
 
 // Query returns QueryResolver implementation.
 func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
 
+// Report returns ReportResolver implementation.
+func (r *Resolver) Report() ReportResolver { return &reportResolver{r} }
+
 // User returns UserResolver implementation.
 func (r *Resolver) User() UserResolver { return &userResolver{r} }
 
 type commentResolver struct{ *Resolver }
 type communityResolver struct{ *Resolver }
+type communityUserBanResolver struct{ *Resolver }
+type communityUserMuteResolver struct{ *Resolver }
 type hostResolver struct{ *Resolver }
+type hostCommunityBanResolver struct{ *Resolver }
+type hostCommunityMuteResolver struct{ *Resolver }
+type hostIPBanResolver struct{ *Resolver }
+type hostUserBanResolver struct{ *Resolver }
+type hostUserMuteResolver struct{ *Resolver }
+type notificationResolver struct{ *Resolver }
+type notificationSettingsResolver struct{ *Resolver }
 type postResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
+type reportResolver struct{ *Resolver }
 type userResolver struct{ *Resolver }
@@ -0,0 +1,229 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"stormlink/server/authz"
+	"stormlink/server/ent"
+	"stormlink/server/ent/comment"
+	"stormlink/server/ent/post"
+	"stormlink/server/ent/postlike"
+	"stormlink/server/ent/userfollow"
+	"stormlink/server/graphql/models"
+	"stormlink/server/graphql/respcache"
+	"stormlink/server/shadow"
+	"stormlink/shared/auth"
+)
+
+// Топики событий постов и модерации
+const (
+	topicPostPublished    = "post.published"
+	topicPostStats        = "post.stats"
+	topicUserStats        = "user.stats"
+	topicModerationEvents = "moderation"
+)
+
+func communityTopic(base string, communityID int) string {
+	return fmt.Sprintf("%s.community.%d", base, communityID)
+}
+
+// moderationTopic — топик событий модерации сообщества; nil — события уровня платформы
+func moderationTopic(communityID *int) string {
+	if communityID == nil {
+		return topicModerationEvents + ".host"
+	}
+	return communityTopic(topicModerationEvents, *communityID)
+}
+
+// moderationEvent — событие модерации в брокере; связанные сущности перечитываются на стороне подписчика
+type moderationEvent struct {
+	Type        models.ModerationEventType `json:"type"`
+	CommunityID *int                       `json:"communityId,omitempty"`
+	ModeratorID int                        `json:"moderatorId"`
+	UserID      *int                       `json:"userId,omitempty"`
+	PostID      *int                       `json:"postId,omitempty"`
+	CreatedAt   time.Time                  `json:"createdAt"`
+}
+
+// publishPostPublished оповещает подписчиков сообщества о новом опубликованном посте
+func (r *Resolver) publishPostPublished(ctx context.Context, p *ent.Post) {
+	if p.Visibility != post.VisibilityPublished {
+		return
+	}
+	r.publish(ctx, communityTopic(topicPostPublished, p.CommunityID), p.ID)
+}
+
+// postStatsEvent — счётчики поста, посчитанные один раз при публикации события,
+// и данные для проверки видимости на стороне подписчика без запросов к базе
+type postStatsEvent struct {
+	Stats     *models.PostStats `json:"stats"`
+	AuthorID  int               `json:"authorId"`
+	Published bool              `json:"published"`
+}
+
+// publishPostStats оповещает об изменении счётчиков поста (лайки, комментарии, просмотры)
+func (r *Resolver) publishPostStats(ctx context.Context, postID int) {
+	p, err := r.Client.Post.Get(ctx, postID)
+	if err != nil {
+		log.Printf("❌ publish post stats %d: %v", postID, err)
+		return
+	}
+	// Счётчики уходят всем подписчикам, поэтому теневые лайки и комментарии автора действия
+	// в них не входят: считаем их глазами стороннего зрителя
+	public := shadow.Public(ctx)
+	likes, err := r.Client.PostLike.Query().Where(postlike.PostIDEQ(postID)).Count(public)
+	if err != nil {
+		log.Printf("❌ publish post stats %d: %v", postID, err)
+		return
+	}
+	comments, err := r.Client.Comment.Query().Where(comment.PostIDEQ(postID)).Count(public)
+	if err != nil {
+		log.Printf("❌ publish post stats %d: %v", postID, err)
+		return
+	}
+	r.publishEvent(ctx, postTopic(topicPostStats, postID), postStatsEvent{
+		Stats: &models.PostStats{
+			PostID:        strconv.Itoa(postID),
+			LikesCount:    int32(likes),
+			CommentsCount: int32(comments),
+			ViewsCount:    p.Views,
+		},
+		AuthorID:  p.AuthorID,
+		Published: p.Visibility == post.VisibilityPublished,
+	})
+}
+
+// publishUserStats оповещает об изменении числа подписчиков и подписок пользователя
+func (r *Resolver) publishUserStats(ctx context.Context, userID int) {
+	followers, err := r.Client.UserFollow.Query().Where(userfollow.FolloweeIDEQ(userID)).Count(ctx)
+	if err != nil {
+		log.Printf("❌ publish user stats %d: %v", userID, err)
+		return
+	}
+	following, err := r.Client.UserFollow.Query().Where(userfollow.FollowerIDEQ(userID)).Count(ctx)
+	if err != nil {
+		log.Printf("❌ publish user stats %d: %v", userID, err)
+		return
+	}
+	r.publishEvent(ctx, userTopic(topicUserStats, userID), models.UserStats{
+		UserID:         strconv.Itoa(userID),
+		FollowersCount: int32(followers),
+		FollowingCount: int32(following),
+	})
+}
+
+func (r *Resolver) publishModeration(ctx context.Context, t models.ModerationEventType, moderatorID int, communityID, userID, postID *int) {
+	r.publishEvent(ctx, moderationTopic(communityID), moderationEvent{
+		Type:        t,
+		CommunityID: communityID,
+		ModeratorID: moderatorID,
+		UserID:      userID,
+		PostID:      postID,
+		CreatedAt:   time.Now(),
+	})
+}
+
+// invalidate сбрасывает закешированные ответы, в которые вошли изменённые сущности
+func (r *Resolver) invalidate(ctx context.Context, tags ...string) {
+	if r.Cache != nil {
+		r.Cache.Invalidate(ctx, tags...)
+	}
+}
+
+// invalidateCommunityRule сбрасывает ответы с сообществом правила; правила платформы — с хостом
+func (r *Resolver) invalidateCommunityRule(ctx context.Context, rule *ent.CommunityRule) {
+	switch {
+	case rule == nil:
+	case rule.CommunityID != nil:
+		r.invalidate(ctx, respcache.TagCommunity(*rule.CommunityID))
+	default:
+		r.invalidate(ctx, respcache.TagHost)
+	}
+}
+
+// loadPublishedPost перечитывает пост, если он всё ещё опубликован
+func (r *Resolver) loadPublishedPost(ctx context.Context, id int) (*ent.Post, bool) {
+	p, err := r.Client.Post.Get(ctx, id)
+	if err != nil || p.Visibility != post.VisibilityPublished {
+		return nil, false
+	}
+	return p, true
+}
+
+// loadPostStats отдает счётчики из события; неопубликованный пост видит только автор
+func loadPostStats(ctx context.Context, ev postStatsEvent) (*models.PostStats, bool) {
+	if ev.Stats == nil {
+		return nil, false
+	}
+	viewerID, _ := auth.UserIDFromContext(ctx)
+	if !ev.Published && (viewerID == 0 || ev.AuthorID != viewerID) {
+		return nil, false
+	}
+	return ev.Stats, true
+}
+
+// loadModerationEvent проверяет права зрителя на событие и подгружает связанные сущности
+func (r *Resolver) loadModerationEvent(ctx context.Context, ev moderationEvent) (*models.ModerationEvent, bool) {
+	viewerID, err := auth.UserIDFromContext(ctx)
+	if err != nil || viewerID == 0 {
+		return nil, false
+	}
+	if ok, err := r.canSeeModerationEvents(ctx, viewerID, ev.CommunityID, ev.Type); err != nil || !ok {
+		return nil, false
+	}
+	out := &models.ModerationEvent{Type: ev.Type, CreatedAt: ev.CreatedAt}
+	if ev.CommunityID != nil {
+		out.Community, _ = r.Client.Community.Get(ctx, *ev.CommunityID)
+	}
+	out.Moderator, _ = r.Client.User.Get(ctx, ev.ModeratorID)
+	if ev.UserID != nil {
+		out.User, _ = r.Client.User.Get(ctx, *ev.UserID)
+	}
+	if ev.PostID != nil {
+		out.Post, _ = r.Client.Post.Get(ctx, *ev.PostID)
+	}
+	return out, true
+}
+
+// canSeeModerationEvents проверяет, может ли пользователь получать события модерации указанного типа.
+// Событие видно тем, кто может совершить соответствующее действие; пустой тип — проверка
+// при подписке: достаточно любого права модерации.
+func (r *Resolver) canSeeModerationEvents(ctx context.Context, userID int, communityID *int, t models.ModerationEventType) (bool, error) {
+	res := authz.Host()
+	if communityID != nil {
+		res = authz.Community(*communityID)
+	}
+	for _, action := range moderationActions(communityID == nil, t) {
+		ok, err := r.authorizer().Can(ctx, userID, action, res)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+// moderationActions — действия, право на любое из которых открывает событие
+func moderationActions(host bool, t models.ModerationEventType) []authz.Action {
+	if host {
+		switch t {
+		case models.ModerationEventTypeUserBanned, models.ModerationEventTypeUserUnbanned:
+			return []authz.Action{authz.HostBanUser}
+		case models.ModerationEventTypeUserMuted, models.ModerationEventTypeUserUnmuted:
+			return []authz.Action{authz.HostMuteUser}
+		}
+		return []authz.Action{authz.HostBanUser, authz.HostMuteUser, authz.HostBanCommunity, authz.HostMuteCommunity}
+	}
+	switch t {
+	case models.ModerationEventTypeUserBanned, models.ModerationEventTypeUserUnbanned:
+		return []authz.Action{authz.BanUser}
+	case models.ModerationEventTypeUserMuted, models.ModerationEventTypeUserUnmuted:
+		return []authz.Action{authz.MuteUser}
+	case models.ModerationEventTypePostUnpublished:
+		return []authz.Action{authz.UnpublishPost}
+	}
+	return []authz.Action{authz.BanUser, authz.MuteUser, authz.UnpublishPost, authz.DeletePost, authz.DeleteComment}
+}
@@ -14,26 +14,41 @@ import (
 	"math/rand"
 	"net/http"
 	"os"
+	"stormlink/server/authz"
+	"stormlink/server/automod"
 	"stormlink/server/ent"
+	"stormlink/server/ent/automodrule"
+	"stormlink/server/ent/banappeal"
 	"stormlink/server/ent/bookmark"
 	"stormlink/server/ent/commentlike"
 	"stormlink/server/ent/community"
 	"stormlink/server/ent/communityfollow"
 	"stormlink/server/ent/communityuserban"
 	"stormlink/server/ent/communityusermute"
+	"stormlink/server/ent/hostcommunityban"
+	"stormlink/server/ent/hostcommunitymute"
+	"stormlink/server/ent/hostuserban"
+	"stormlink/server/ent/hostusermute"
+	"stormlink/server/ent/moderationaction"
+	"stormlink/server/ent/notification"
 	"stormlink/server/ent/post"
 	"stormlink/server/ent/postlike"
 	"stormlink/server/ent/profiletableinfoitem"
+	"stormlink/server/ent/report"
 	"stormlink/server/ent/role"
 	"stormlink/server/ent/user"
 	"stormlink/server/ent/userfollow"
 	"stormlink/server/graphql/models"
+	"stormlink/server/graphql/respcache"
 	authpb "stormlink/server/grpc/auth/protobuf"
 	mailpb "stormlink/server/grpc/mail/protobuf"
 	mediapb "stormlink/server/grpc/media/protobuf"
 	userpb "stormlink/server/grpc/user/protobuf"
 	"stormlink/server/model"
 	"stormlink/server/model/converter"
+	"stormlink/server/modlog"
+	"stormlink/server/sanctions"
+	usersessionuc "stormlink/server/usecase/usersession"
 	"stormlink/shared/auth"
 	httpWithCookies "stormlink/shared/http"
 	sharedmapper "stormlink/shared/mapper"
@@ -53,12 +68,8 @@ import (
 
 // CommentStatus is the resolver for the commentStatus field.
 func (r *commentResolver) CommentStatus(ctx context.Context, obj *ent.Comment) (*models.CommentStatus, error) {
-	// Берём текущего пользователя из контекста, для корректного isLiked
-	uid := 0
-	if id, err := auth.UserIDFromContext(ctx); err == nil {
-		uid = id
-	}
-	return r.CommentUC.GetCommentStatus(ctx, uid, obj.ID)
+	// Счётчики и флаги текущего пользователя грузятся пакетно для всех комментариев страницы
+	return r.commentStatus(ctx, obj)
 }
 
 // ViewerPermissions для запроса сообществ.
@@ -70,38 +81,12 @@ func (r *communityResolver) ViewerPermissions(ctx context.Context, obj *ent.Comm
 		return &model.CommunityPermissions{}, nil
 	}
 
-	communityID := obj.ID
-
-	// 2) Спрашиваем именно по одному сообществу
-	permsMap, err := r.UserUC.GetPermissionsByCommunities(ctx, userID, []int{communityID})
+	// 2) Права владельцев, ролей сообщества и ролей платформы с учётом явных запретов
+	perms, err := r.authorizer().Permissions(ctx, userID, obj.ID)
 	if err != nil {
 		return nil, fmt.Errorf("failed loading perms: %w", err)
 	}
-	base := permsMap[communityID]
-	if base == nil {
-		base = &model.CommunityPermissions{}
-	}
-	cm := converter.ConvertPermissionsToCommunityPermissions(base)
-
-	// 3) Если юзер — владелец сообщества, даём ему полный набор community-прав
-	communityEntity, err := r.Client.Community.Get(ctx, communityID)
-	if err == nil && communityEntity.OwnerID == userID {
-		cm.CommunityOwner = true
-		cm.CommunityRolesManagement = true
-		cm.CommunityUserBan = true
-		cm.CommunityUserMute = true
-		cm.CommunityDeletePost = true
-		cm.CommunityDeleteComments = true
-		cm.CommunityRemovePostFromPublication = true
-	}
-
-	// 4) Если юзер — владелец платформы
-	hostEntity, err := r.Client.Host.Get(ctx, 1)
-	if err == nil && hostEntity.OwnerID != nil && *hostEntity.OwnerID == userID {
-		cm.HostOwner = true
-	}
-
-	return cm, nil
+	return converter.ConvertPermissionsToCommunityPermissions(perms), nil
 }
 
 // CommunityStatus возвращает статус сообщества для текущего пользователя.
@@ -128,8 +113,81 @@ func (r *communityResolver) CommunityStatus(ctx context.Context, obj *ent.Commun
 	return status, nil
 }
 
+// PrivateNote is the resolver for the privateNote field.
+func (r *communityUserBanResolver) PrivateNote(ctx context.Context, obj *ent.CommunityUserBan) (*string, error) {
+	return r.privateNote(ctx, authz.BanUser, authz.Community(obj.CommunityID), func() (string, error) { return obj.PrivateNote, nil })
+}
+
+// PrivateNote is the resolver for the privateNote field.
+func (r *communityUserMuteResolver) PrivateNote(ctx context.Context, obj *ent.CommunityUserMute) (*string, error) {
+	return r.privateNote(ctx, authz.MuteUser, authz.Community(obj.CommunityID), func() (string, error) { return obj.PrivateNote, nil })
+}
+
+// PrivateNote is the resolver for the privateNote field.
+func (r *hostCommunityBanResolver) PrivateNote(ctx context.Context, obj *models.HostCommunityBan) (*string, error) {
+	return r.privateNote(ctx, authz.HostBanCommunity, authz.Host(), func() (string, error) {
+		id, err := strconv.Atoi(obj.ID)
+		if err != nil {
+			return "", err
+		}
+		m, err := r.Client.HostCommunityBan.Get(ctx, id)
+		if err != nil {
+			return "", err
+		}
+		return m.PrivateNote, nil
+	})
+}
+
+// PrivateNote is the resolver for the privateNote field.
+func (r *hostCommunityMuteResolver) PrivateNote(ctx context.Context, obj *models.HostCommunityMute) (*string, error) {
+	return r.privateNote(ctx, authz.HostMuteCommunity, authz.Host(), func() (string, error) {
+		id, err := strconv.Atoi(obj.ID)
+		if err != nil {
+			return "", err
+		}
+		m, err := r.Client.HostCommunityMute.Get(ctx, id)
+		if err != nil {
+			return "", err
+		}
+		return m.PrivateNote, nil
+	})
+}
+
+// PrivateNote is the resolver for the privateNote field.
+func (r *hostIPBanResolver) PrivateNote(ctx context.Context, obj *ent.HostIPBan) (*string, error) {
+	return r.privateNote(ctx, authz.HostBanUser, authz.Host(), func() (string, error) { return obj.PrivateNote, nil })
+}
+
+// PrivateNote is the resolver for the privateNote field.
+func (r *hostUserBanResolver) PrivateNote(ctx context.Context, obj *ent.HostUserBan) (*string, error) {
+	return r.privateNote(ctx, authz.HostBanUser, authz.Host(), func() (string, error) { return obj.PrivateNote, nil })
+}
+
+// PrivateNote is the resolver for the privateNote field.
+func (r *hostUserMuteResolver) PrivateNote(ctx context.Context, obj *models.HostUserMute) (*string, error) {
+	return r.privateNote(ctx, authz.HostMuteUser, authz.Host(), func() (string, error) {
+		id, err := strconv.Atoi(obj.ID)
+		if err != nil {
+			return "", err
+		}
+		m, err := r.Client.HostUserMute.Get(ctx, id)
+		if err != nil {
+			return "", err
+		}
+		return m.PrivateNote, nil
+	})
+}
+
 // Мутация Host для настроек платформы.
 func (r *mutationResolver) Host(ctx context.Context, input models.UpdateHostInput) (*ent.Host, error) {
+	currentUserID, err := auth.UserIDFromContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unauthenticated")
+	}
+	if err := r.require(ctx, currentUserID, authz.ManageHost, authz.Host(), "only host owner can manage host settings"); err != nil {
+		return nil, err
+	}
+
 	upd := r.Client.Host.UpdateOneID(1)
 	// каждый Set* вызываем только если в input поле не nil
 	if input.Title != nil {
@@ -147,7 +205,12 @@ func (r *mutationResolver) Host(ctx context.Context, input models.UpdateHostInpu
 	if input.FirstSettings != nil {
 		upd = upd.SetFirstSettings(*input.FirstSettings)
 	}
-	return upd.Save(ctx)
+	h, err := upd.Save(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r.invalidate(ctx, respcache.TagHost)
+	return h, nil
 }
 
 // Мутация Post для редактирования поста
@@ -156,31 +219,125 @@ func (r *mutationResolver) Post(ctx context.Context, input models.UpdatePostInpu
 	if err != nil {
 		return nil, fmt.Errorf("invalid post ID %q: %w", input.ID, err)
 	}
-	upd := r.Client.Post.
-		UpdateOneID(id)
-	if input.Title != nil {
-		upd = upd.SetTitle(*input.Title)
+	before, err := r.Client.Post.Get(ctx, id)
+	if err != nil {
+		return nil, err
 	}
-	if input.Slug != nil {
-		upd = upd.SetSlug(*input.Slug)
+	editorID, err := auth.UserIDFromContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	moderated, err := r.requirePostEdit(ctx, editorID, before, input)
+	if err != nil {
+		return nil, err
 	}
-	if input.Content != nil {
-		upd = upd.SetContent(input.Content)
+	if err := r.checkSanctions(ctx, editorID, &before.CommunityID, sanctions.OpPost); err != nil {
+		return nil, err
 	}
-	if input.HeroImageID != nil {
-		heroID, err := strconv.Atoi(*input.HeroImageID)
+	// Новый заголовок или текст проверяется автомодерацией так же, как при создании
+	var verdict *automod.Verdict
+	if input.Title != nil || input.Content != nil {
+		title, content := before.Title, before.Content
+		if input.Title != nil {
+			title = *input.Title
+		}
+		if input.Content != nil {
+			content = input.Content
+		}
+		verdict, err = r.automodCheck(ctx, automod.Content{
+			AuthorID:    before.AuthorID,
+			CommunityID: before.CommunityID,
+			Text:        automod.PostText(title, content),
+			SkipPostID:  id,
+		})
 		if err != nil {
-			return nil, fmt.Errorf("invalid heroImageID %q: %w", *input.HeroImageID, err)
+			return nil, err
 		}
-		upd = upd.SetHeroImageID(heroID)
 	}
-	if input.Visibility != nil {
-		upd = upd.SetVisibility(*input.Visibility)
+	// held ставит только автомодерация, а выпускает задержанный пост модератор
+	if input.Visibility != nil && *input.Visibility != before.Visibility {
+		if *input.Visibility == post.VisibilityHeld {
+			return nil, fmt.Errorf("visibility %q is set by automoderation only", post.VisibilityHeld)
+		}
+		if before.Visibility == post.VisibilityHeld && *input.Visibility != post.VisibilityDeleted {
+			if err := r.require(ctx, editorID, authz.UnpublishPost, authz.Community(before.CommunityID), "only moderators can release a held post"); err != nil {
+				return nil, err
+			}
+		}
 	}
-	if input.PublishedAt != nil {
-		upd = upd.SetPublishedAt(*input.PublishedAt)
+	// Снятие с публикации по праву модератора — действие модерации
+	unpublished := func(p *ent.Post) bool {
+		return moderated != "" &&
+			before.Visibility == post.VisibilityPublished && p.Visibility != post.VisibilityPublished
+	}
+
+	var p *ent.Post
+	err = modlog.InTx(ctx, r.Client, func(tx *ent.Tx) error {
+		upd := tx.Post.
+			UpdateOneID(id)
+		if input.Title != nil {
+			upd = upd.SetTitle(*input.Title)
+		}
+		if input.Slug != nil {
+			upd = upd.SetSlug(*input.Slug)
+		}
+		if input.Content != nil {
+			upd = upd.SetContent(input.Content)
+		}
+		if input.HeroImageID != nil {
+			heroID, err := strconv.Atoi(*input.HeroImageID)
+			if err != nil {
+				return fmt.Errorf("invalid heroImageID %q: %w", *input.HeroImageID, err)
+			}
+			upd = upd.SetHeroImageID(heroID)
+		}
+		visibility := before.Visibility
+		if input.Visibility != nil {
+			visibility = *input.Visibility
+		}
+		// Опубликованная правка, задержанная автомодерацией, ждет решения по жалобе
+		if visibility == post.VisibilityPublished && verdict != nil && verdict.Action == automodrule.ActionHold {
+			visibility = post.VisibilityHeld
+		}
+		if visibility != before.Visibility {
+			upd = upd.SetVisibility(visibility)
+		}
+		if input.PublishedAt != nil {
+			upd = upd.SetPublishedAt(*input.PublishedAt)
+		}
+		if p, err = upd.Save(ctx); err != nil {
+			return err
+		}
+		if !unpublished(p) {
+			return nil
+		}
+		return modlog.Record(ctx, tx.ModerationAction, modlog.Entry{
+			Type:         moderationaction.TypePostUnpublished,
+			ModeratorID:  &editorID,
+			CommunityID:  &p.CommunityID,
+			TargetType:   moderationaction.TargetTypePost,
+			TargetID:     p.ID,
+			TargetUserID: &p.AuthorID,
+			Before:       before,
+			After:        p,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.invalidate(ctx, respcache.TagPost(p.ID))
+	r.automodApply(ctx, verdict, report.TargetTypePost, p.ID, p.AuthorID, p.CommunityID)
+
+	// События смены видимости: публикация — подписчикам сообщества,
+	// снятие с публикации не автором — модераторам
+	if before.Visibility != p.Visibility {
+		if p.Visibility == post.VisibilityPublished {
+			r.publishPostPublished(ctx, p)
+		} else if unpublished(p) {
+			r.publishModeration(ctx, models.ModerationEventTypePostUnpublished, editorID, &p.CommunityID, &p.AuthorID, &p.ID)
+		}
 	}
-	return upd.Save(ctx)
+	return p, nil
 }
 
 // CreatePost создает новый пост и генерирует slug.
@@ -194,9 +351,20 @@ func (r *mutationResolver) CreatePost(ctx context.Context, input models.CreatePo
 		return nil, fmt.Errorf("invalid communityID: %w", err)
 	}
 
-	authorID, err := strconv.Atoi(input.AuthorID)
+	authorID, err := actingAs(ctx, input.AuthorID, "authorID")
+	if err != nil {
+		return nil, err
+	}
+	if err := r.checkSanctions(ctx, authorID, &communityID, sanctions.OpPost); err != nil {
+		return nil, err
+	}
+	verdict, err := r.automodCheck(ctx, automod.Content{
+		AuthorID:    authorID,
+		CommunityID: communityID,
+		Text:        automod.PostText(input.Title, input.Content),
+	})
 	if err != nil {
-		return nil, fmt.Errorf("invalid authorID: %w", err)
+		return nil, err
 	}
 	builder := r.Client.Post.
 		Create().
@@ -214,7 +382,15 @@ func (r *mutationResolver) CreatePost(ctx context.Context, input models.CreatePo
 
 	// Устанавливаем visibility
 	if input.Visibility != nil {
-		builder = builder.SetVisibility(*input.Visibility)
+		if *input.Visibility == post.VisibilityHeld {
+			return nil, fmt.Errorf("visibility %q is set by automoderation only", post.VisibilityHeld)
+		}
+		// Публикация, задержанная автомодерацией, ждет решения по жалобе
+		if *input.Visibility == post.VisibilityPublished && verdict != nil && verdict.Action == automodrule.ActionHold {
+			builder = builder.SetVisibility(post.VisibilityHeld)
+		} else {
+			builder = builder.SetVisibility(*input.Visibility)
+		}
 	}
 
 	if input.PublishedAt != nil {
@@ -225,11 +401,21 @@ func (r *mutationResolver) CreatePost(ctx context.Context, input models.CreatePo
 	if err != nil {
 		return nil, fmt.Errorf("failed to create post: %w", err)
 	}
+	r.automodApply(ctx, verdict, report.TargetTypePost, newPost.ID, authorID, communityID)
+	r.publishPostPublished(ctx, newPost)
 	return newPost, nil
 }
 
 // CreateCommunity создает новое сообщество.
 func (r *mutationResolver) CreateCommunity(ctx context.Context, input models.CreateCommunityInput) (*ent.Community, error) {
+	ownerID, err := actingAs(ctx, input.OwnerID, "ownerID")
+	if err != nil {
+		return nil, err
+	}
+	if err := r.checkSanctions(ctx, ownerID, nil, sanctions.OpCommunity); err != nil {
+		return nil, err
+	}
+
 	exists, err := r.Client.Community.
 		Query().
 		Where(community.SlugEQ(input.Slug)).
@@ -247,10 +433,7 @@ func (r *mutationResolver) CreateCommunity(ctx context.Context, input models.Cre
 		SetTitle(input.Title).
 		SetSlug(input.Slug).
 		SetNillableDescription(input.Description).
-		SetOwnerID(func() int {
-			id, _ := strconv.Atoi(input.OwnerID)
-			return id
-		}()).
+		SetOwnerID(ownerID).
 		Save(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create community: %w", err)
@@ -261,12 +444,6 @@ func (r *mutationResolver) CreateCommunity(ctx context.Context, input models.Cre
 		Create().
 		SetTitle("@everyone").
 		SetColor("#99AAB5").
-		SetCommunityRolesManagement(false).
-		SetCommunityUserBan(false).
-		SetCommunityUserMute(false).
-		SetCommunityDeletePost(false).
-		SetCommunityDeleteComments(false).
-		SetCommunityRemovePostFromPublication(false).
 		SetCommunityID(newComm.ID).
 		Save(ctx)
 	if err != nil {
@@ -274,7 +451,6 @@ func (r *mutationResolver) CreateCommunity(ctx context.Context, input models.Cre
 	}
 
 	// Назначаем роль "@everyone" создателю сообщества
-	ownerID, _ := strconv.Atoi(input.OwnerID)
 	_, err = r.Client.Role.
 		UpdateOne(everyoneRole).
 		AddUserIDs(ownerID).
@@ -288,28 +464,47 @@ func (r *mutationResolver) CreateCommunity(ctx context.Context, input models.Cre
 
 // CreateComment is the resolver for the createComment field.
 func (r *mutationResolver) CreateComment(ctx context.Context, input models.CreateCommentInput) (*ent.Comment, error) {
-	authorID, err := strconv.Atoi(input.AuthorID)
+	authorID, err := actingAs(ctx, input.AuthorID, "authorID")
 	if err != nil {
-		return nil, fmt.Errorf("invalid authorID %q: %w", input.AuthorID, err)
-	}
-	communityID, err := strconv.Atoi(input.CommunityID)
-	if err != nil {
-		return nil, fmt.Errorf("invalid communityID %q: %w", input.CommunityID, err)
+		return nil, err
 	}
 	postID, err := strconv.Atoi(input.PostID)
 	if err != nil {
 		return nil, fmt.Errorf("invalid postID %q: %w", input.PostID, err)
 	}
+	// Сообщество берем у поста, а не из ввода: по нему проверяются санкции и автомодерация
+	p, err := r.Client.Post.Get(ctx, postID)
+	if err != nil {
+		return nil, fmt.Errorf("post %d: %w", postID, err)
+	}
+	communityID := p.CommunityID
+	if input.CommunityID != strconv.Itoa(communityID) {
+		return nil, fmt.Errorf("communityID %q does not match the post", input.CommunityID)
+	}
 
 	var parentID *int
+	var parent *ent.Comment
 	if input.ParentCommentID != nil {
 		pid, err := strconv.Atoi(*input.ParentCommentID)
 		if err != nil {
 			return nil, fmt.Errorf("invalid parentCommentID %q: %w", *input.ParentCommentID, err)
 		}
+		if parent, err = r.Client.Comment.Get(ctx, pid); err != nil {
+			return nil, fmt.Errorf("parent comment %d: %w", pid, err)
+		}
+		if parent.PostID != postID {
+			return nil, fmt.Errorf("parent comment %d belongs to another post", pid)
+		}
 		parentID = &pid
 	}
-	// 1) Создаём комментарий
+	if err := r.checkSanctions(ctx, authorID, &communityID, sanctions.OpComment); err != nil {
+		return nil, err
+	}
+	verdict, err := r.automodCheck(ctx, automod.Content{AuthorID: authorID, CommunityID: communityID, Text: input.Content})
+	if err != nil {
+		return nil, err
+	}
+	// 1) Создаём комментарий; задержанный автомодерацией скрыт до решения по жалобе
 	c, err := r.Client.Comment.
 		Create().
 		SetAuthorID(authorID).
@@ -317,13 +512,40 @@ func (r *mutationResolver) CreateComment(ctx context.Context, input models.Creat
 		SetPostID(postID).
 		SetContent(input.Content).
 		SetNillableParentCommentID(parentID).
+		SetHeld(verdict != nil && verdict.Action == automodrule.ActionHold).
 		Save(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("create comment: %w", err)
 	}
-
-	// 2) Публикуем событие для подписок
-	publishCommentAdded(postID, c)
+	r.automodApply(ctx, verdict, report.TargetTypeComment, c.ID, authorID, communityID)
+	if c.Held {
+		return c, nil
+	}
+
+	// 2) Публикуем события для подписок
+	r.publishCommentAdded(ctx, c)
+	r.publishPostStats(ctx, postID)
+	r.invalidate(ctx, respcache.TagPost(postID))
+
+	// 3) Уведомления: ответ автору родительского комментария и упомянутым пользователям
+	notified := []int{authorID}
+	if parent != nil {
+		r.notify(ctx, notifyEvent{
+			RecipientID: parent.AuthorID,
+			ActorID:     authorID,
+			Type:        notification.TypeCommentReply,
+			PostID:      &postID,
+			CommentID:   &c.ID,
+			CommunityID: &communityID,
+		})
+		notified = append(notified, parent.AuthorID)
+	}
+	r.notifyMentions(ctx, input.Content, notifyEvent{
+		ActorID:     authorID,
+		PostID:      &postID,
+		CommentID:   &c.ID,
+		CommunityID: &communityID,
+	}, notified...)
 
 	return c, nil
 }
@@ -336,42 +558,101 @@ func (r *mutationResolver) UpdateComment(ctx context.Context, input models.Updat
 		return nil, fmt.Errorf("invalid comment ID %q: %w", input.ID, err)
 	}
 
-	// 2) Начинаем билд запроса на обновление одной записи
-	update := r.Client.Comment.
-		UpdateOneID(cid)
+	before, err := r.Client.Comment.Get(ctx, cid)
+	if err != nil {
+		return nil, err
+	}
+	editorID, err := auth.UserIDFromContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	deleting := input.HasDeleted != nil && *input.HasDeleted
+	moderated, err := r.requireCommentEdit(ctx, editorID, before, deleting)
+	if err != nil {
+		return nil, err
+	}
 
-	// 3) Если клиент передал hasDeleted = true (удаление комментария)
-	if input.HasDeleted != nil && *input.HasDeleted {
-		update = update.
-			SetHasDeleted(true).
-			SetContent("Комментарий был удален").
-			// очищаем любое ранее прикреплённое медиа
-			ClearMediaID()
-	} else {
-		// 4) Обновляем контент, если он пришёл
-		if input.Content != nil {
-			update = update.
-				SetContent(*input.Content).
-				SetHasUpdated(true)
+	// Удалять свой комментарий можно и под санкциями; правка — публикация
+	var verdict *automod.Verdict
+	if !deleting {
+		if err := r.checkSanctions(ctx, editorID, &before.CommunityID, sanctions.OpComment); err != nil {
+			return nil, err
 		}
-		// 5) Если пришёл новый mediaID — обновляем
-		if input.MediaID != nil {
-			mid, err := strconv.Atoi(*input.MediaID)
+		if input.Content != nil {
+			verdict, err = r.automodCheck(ctx, automod.Content{
+				AuthorID:      before.AuthorID,
+				CommunityID:   before.CommunityID,
+				Text:          *input.Content,
+				SkipCommentID: cid,
+			})
 			if err != nil {
-				return nil, fmt.Errorf("invalid media ID %q: %w", *input.MediaID, err)
+				return nil, err
 			}
-			update = update.SetMediaID(mid)
 		}
 	}
 
-	// 6) Сохраняем и возвращаем обновлённый комментарий
-	comment, err := update.Save(ctx)
+	var comment *ent.Comment
+	err = modlog.InTx(ctx, r.Client, func(tx *ent.Tx) error {
+		// 2) Начинаем билд запроса на обновление одной записи
+		update := tx.Comment.
+			UpdateOneID(cid)
+
+		// 3) Если клиент передал hasDeleted = true (удаление комментария)
+		if deleting {
+			update = update.
+				SetHasDeleted(true).
+				SetContent("Комментарий был удален").
+				// очищаем любое ранее прикреплённое медиа
+				ClearMediaID()
+		} else {
+			// 4) Обновляем контент, если он пришёл
+			if input.Content != nil {
+				update = update.
+					SetContent(*input.Content).
+					SetHasUpdated(true)
+				if verdict != nil && verdict.Action == automodrule.ActionHold {
+					update = update.SetHeld(true)
+				}
+			}
+			// 5) Если пришёл новый mediaID — обновляем
+			if input.MediaID != nil {
+				mid, err := strconv.Atoi(*input.MediaID)
+				if err != nil {
+					return fmt.Errorf("invalid media ID %q: %w", *input.MediaID, err)
+				}
+				update = update.SetMediaID(mid)
+			}
+		}
+
+		// 6) Сохраняем обновлённый комментарий
+		if comment, err = update.Save(ctx); err != nil {
+			return fmt.Errorf("failed to update comment %d: %w", cid, err)
+		}
+
+		// Удаление по праву модератора — действие модерации
+		if moderated != authz.DeleteComment {
+			return nil
+		}
+		return modlog.Record(ctx, tx.ModerationAction, modlog.Entry{
+			Type:         moderationaction.TypeCommentDeleted,
+			ModeratorID:  &editorID,
+			CommunityID:  &before.CommunityID,
+			TargetType:   moderationaction.TargetTypeComment,
+			TargetID:     cid,
+			TargetUserID: &before.AuthorID,
+			Before:       before,
+			After:        comment,
+		})
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to update comment %d: %w", cid, err)
+		return nil, err
 	}
 
+	r.automodApply(ctx, verdict, report.TargetTypeComment, cid, before.AuthorID, before.CommunityID)
+
 	// 7) Публикуем обновление для подписчиков
-	publishCommentUpdated(comment.PostID, comment)
+	r.publishCommentUpdated(ctx, comment)
+	r.invalidate(ctx, respcache.TagPost(comment.PostID))
 
 	return comment, nil
 }
@@ -395,6 +676,9 @@ func (r *mutationResolver) LoginUser(ctx context.Context, input models.LoginUser
 		return nil, status.Errorf(codes.Internal, "failed to map user: %v", err)
 	}
 
+	// Запоминаем адрес входа для связанных аккаунтов
+	r.recordSession(ctx, resp.User.GetId())
+
 	// Ставим куки в HTTP-ответе
 	if w := httpWithCookies.GetHTTPResponseWriter(ctx); w != nil {
 		httpWithCookies.SetAuthCookies(w, resp.AccessToken, resp.RefreshToken)
@@ -446,6 +730,11 @@ func (r *mutationResolver) RegisterUser(ctx context.Context, input models.Regist
 		return nil, errors.New("name, email, and password are required")
 	}
 
+	// С забаненного IP новые аккаунты не регистрируются
+	if err := r.checkIPBan(ctx); err != nil {
+		return nil, err
+	}
+
 	// Вызываем gRPC-метод RegisterUser
 	resp, err := r.UserClient.RegisterUser(ctx, &userpb.RegisterUserRequest{
 		Name:     input.Name,
@@ -563,6 +852,12 @@ func (r *mutationResolver) UploadMedia(ctx context.Context, file graphql.Upload,
 		return nil, fmt.Errorf("unsupported content type: %s", ct)
 	}
 
+	if userID, err := auth.UserIDFromContext(ctx); err == nil {
+		if err := r.checkSanctions(ctx, userID, nil, sanctions.OpProfile); err != nil {
+			return nil, err
+		}
+	}
+
 	// 2) Прокидываем Authorization из контекста, если есть
 	if authHeader, _ := ctx.Value("authorization").(string); authHeader != "" {
 		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", authHeader)
@@ -602,6 +897,9 @@ func (r *mutationResolver) FollowUser(ctx context.Context, input models.FollowUs
 	if err != nil {
 		return nil, fmt.Errorf("unauthorized")
 	}
+	if err := r.checkSanctions(ctx, currentUserID, nil, sanctions.OpFollow); err != nil {
+		return nil, err
+	}
 	// 2) Создаём запись в user_follow
 	uID, _ := strconv.Atoi(input.UserID)
 	_, err = r.Client.UserFollow.
@@ -612,6 +910,9 @@ func (r *mutationResolver) FollowUser(ctx context.Context, input models.FollowUs
 	if err != nil {
 		return nil, fmt.Errorf("failed follow: %w", err)
 	}
+	r.notify(ctx, notifyEvent{RecipientID: uID, ActorID: currentUserID, Type: notification.TypeUserFollow})
+	r.publishUserStats(ctx, uID)
+	r.publishUserStats(ctx, currentUserID)
 	// 3) Возвращаем актуальный UserStatus (используя ваш usecase)
 	status, err := r.UserUC.GetUserStatus(ctx, currentUserID, uID)
 	if err != nil {
@@ -638,6 +939,8 @@ func (r *mutationResolver) UnfollowUser(ctx context.Context, input models.Unfoll
 	if err != nil {
 		return nil, fmt.Errorf("failed unfollow: %w", err)
 	}
+	r.publishUserStats(ctx, uID)
+	r.publishUserStats(ctx, currentUserID)
 	status, err := r.UserUC.GetUserStatus(ctx, currentUserID, uID)
 	if err != nil {
 		return nil, fmt.Errorf("refresh status: %w", err)
@@ -655,6 +958,9 @@ func (r *mutationResolver) FollowCommunity(ctx context.Context, input models.Fol
 
 	// 2) Создаём запись в community_follow
 	cID, _ := strconv.Atoi(input.CommunityID)
+	if err := r.checkSanctions(ctx, currentUserID, &cID, sanctions.OpFollow); err != nil {
+		return nil, err
+	}
 	_, err = r.Client.CommunityFollow.
 		Create().
 		SetUserID(currentUserID).
@@ -678,12 +984,6 @@ func (r *mutationResolver) FollowCommunity(ctx context.Context, input models.Fol
 			Create().
 			SetTitle("@everyone").
 			SetColor("#99AAB5").
-			SetCommunityRolesManagement(false).
-			SetCommunityUserBan(false).
-			SetCommunityUserMute(false).
-			SetCommunityDeletePost(false).
-			SetCommunityDeleteComments(false).
-			SetCommunityRemovePostFromPublication(false).
 			SetCommunityID(cID).
 			Save(ctx)
 		if err != nil {
@@ -743,6 +1043,13 @@ func (r *mutationResolver) LikePost(ctx context.Context, input models.LikePostIn
 	if err != nil {
 		return nil, fmt.Errorf("invalid postID: %w", err)
 	}
+	p, err := r.Client.Post.Get(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.checkSanctions(ctx, userID, &p.CommunityID, sanctions.OpReact); err != nil {
+		return nil, err
+	}
 	// idempotent: если уже лайкнул — не дублируем
 	exists, err := r.Client.PostLike.Query().Where(postlike.UserIDEQ(userID), postlike.PostIDEQ(pid)).Exist(ctx)
 	if err != nil {
@@ -752,6 +1059,15 @@ func (r *mutationResolver) LikePost(ctx context.Context, input models.LikePostIn
 		if _, err := r.Client.PostLike.Create().SetUserID(userID).SetPostID(pid).Save(ctx); err != nil {
 			return nil, fmt.Errorf("like create: %w", err)
 		}
+		r.publishPostStats(ctx, pid)
+		r.invalidate(ctx, respcache.TagPost(pid))
+		r.notify(ctx, notifyEvent{
+			RecipientID: p.AuthorID,
+			ActorID:     userID,
+			Type:        notification.TypePostLike,
+			PostID:      &pid,
+			CommunityID: &p.CommunityID,
+		})
 	}
 	return r.PostUC.GetPostStatus(ctx, userID, pid)
 }
@@ -766,8 +1082,11 @@ func (r *mutationResolver) UnlikePost(ctx context.Context, input models.UnlikePo
 	if err != nil {
 		return nil, fmt.Errorf("invalid postID: %w", err)
 	}
-	if _, err := r.Client.PostLike.Delete().Where(postlike.UserIDEQ(userID), postlike.PostIDEQ(pid)).Exec(ctx); err != nil {
+	if n, err := r.Client.PostLike.Delete().Where(postlike.UserIDEQ(userID), postlike.PostIDEQ(pid)).Exec(ctx); err != nil {
 		return nil, fmt.Errorf("like delete: %w", err)
+	} else if n > 0 {
+		r.publishPostStats(ctx, pid)
+		r.invalidate(ctx, respcache.TagPost(pid))
 	}
 	return r.PostUC.GetPostStatus(ctx, userID, pid)
 }
@@ -782,6 +1101,13 @@ func (r *mutationResolver) LikeComment(ctx context.Context, input models.LikeCom
 	if err != nil {
 		return nil, fmt.Errorf("invalid commentID: %w", err)
 	}
+	cm, err := r.Client.Comment.Get(ctx, cid)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.checkSanctions(ctx, userID, &cm.CommunityID, sanctions.OpReact); err != nil {
+		return nil, err
+	}
 	// idempotent: если уже лайкнул — не дублируем
 	exists, err := r.Client.CommentLike.Query().Where(commentlike.UserIDEQ(userID), commentlike.CommentIDEQ(cid)).Exist(ctx)
 	if err != nil {
@@ -791,6 +1117,15 @@ func (r *mutationResolver) LikeComment(ctx context.Context, input models.LikeCom
 		if _, err := r.Client.CommentLike.Create().SetUserID(userID).SetCommentID(cid).Save(ctx); err != nil {
 			return nil, fmt.Errorf("like create: %w", err)
 		}
+		r.invalidate(ctx, respcache.TagPost(cm.PostID))
+		r.notify(ctx, notifyEvent{
+			RecipientID: cm.AuthorID,
+			ActorID:     userID,
+			Type:        notification.TypeCommentLike,
+			PostID:      &cm.PostID,
+			CommentID:   &cid,
+			CommunityID: &cm.CommunityID,
+		})
 	}
 	return r.CommentUC.GetCommentStatus(ctx, userID, cid)
 }
@@ -805,8 +1140,12 @@ func (r *mutationResolver) UnlikeComment(ctx context.Context, input models.Unlik
 	if err != nil {
 		return nil, fmt.Errorf("invalid commentID: %w", err)
 	}
-	if _, err := r.Client.CommentLike.Delete().Where(commentlike.UserIDEQ(userID), commentlike.CommentIDEQ(cid)).Exec(ctx); err != nil {
+	if n, err := r.Client.CommentLike.Delete().Where(commentlike.UserIDEQ(userID), commentlike.CommentIDEQ(cid)).Exec(ctx); err != nil {
 		return nil, fmt.Errorf("like delete: %w", err)
+	} else if n > 0 {
+		if cm, err := r.Client.Comment.Get(ctx, cid); err == nil {
+			r.invalidate(ctx, respcache.TagPost(cm.PostID))
+		}
 	}
 	return r.CommentUC.GetCommentStatus(ctx, userID, cid)
 }
@@ -821,6 +1160,13 @@ func (r *mutationResolver) AddBookmarkPost(ctx context.Context, input models.Boo
 	if err != nil {
 		return nil, fmt.Errorf("invalid postID: %w", err)
 	}
+	p, err := r.Client.Post.Get(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.checkSanctions(ctx, userID, &p.CommunityID, sanctions.OpReact); err != nil {
+		return nil, err
+	}
 	exists, err := r.Client.Bookmark.Query().Where(bookmark.UserIDEQ(userID), bookmark.PostIDEQ(pid)).Exist(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("bookmark exists: %w", err)
@@ -829,6 +1175,7 @@ func (r *mutationResolver) AddBookmarkPost(ctx context.Context, input models.Boo
 		if _, err := r.Client.Bookmark.Create().SetUserID(userID).SetPostID(pid).Save(ctx); err != nil {
 			return nil, fmt.Errorf("bookmark create: %w", err)
 		}
+		r.invalidate(ctx, respcache.TagPost(pid))
 	}
 	return r.PostUC.GetPostStatus(ctx, userID, pid)
 }
@@ -843,9 +1190,13 @@ func (r *mutationResolver) DeleteBookmarkPost(ctx context.Context, input models.
 	if err != nil {
 		return nil, fmt.Errorf("invalid postID: %w", err)
 	}
-	if _, err := r.Client.Bookmark.Delete().Where(bookmark.UserIDEQ(userID), bookmark.PostIDEQ(pid)).Exec(ctx); err != nil {
+	n, err := r.Client.Bookmark.Delete().Where(bookmark.UserIDEQ(userID), bookmark.PostIDEQ(pid)).Exec(ctx)
+	if err != nil {
 		return nil, fmt.Errorf("bookmark delete: %w", err)
 	}
+	if n > 0 {
+		r.invalidate(ctx, respcache.TagPost(pid))
+	}
 	return r.PostUC.GetPostStatus(ctx, userID, pid)
 }
 
@@ -870,6 +1221,7 @@ func (r *mutationResolver) IncrementPostViews(ctx context.Context, postID string
 	if err != nil {
 		return nil, fmt.Errorf("increment views: %w", err)
 	}
+	r.publishPostStats(ctx, id)
 	return p, nil
 }
 
@@ -886,19 +1238,11 @@ func (r *mutationResolver) Community(ctx context.Context, input models.UpdateCom
 		return nil, fmt.Errorf("invalid community ID %q: %w", input.ID, err)
 	}
 
-	cm, err := r.Client.Community.Get(ctx, cid)
-	if err != nil {
+	if _, err := r.Client.Community.Get(ctx, cid); err != nil {
 		return nil, err
 	}
-	if cm.OwnerID != currentUserID {
-		permsMap, err := r.UserUC.GetPermissionsByCommunities(ctx, currentUserID, []int{cid})
-		if err != nil {
-			return nil, fmt.Errorf("permissions: %w", err)
-		}
-		perms := permsMap[cid]
-		if perms == nil || !perms.CommunityRolesManagement {
-			return nil, fmt.Errorf("forbidden")
-		}
+	if err := r.require(ctx, currentUserID, authz.ManageCommunity, authz.Community(cid), "only owner or community manager can update community"); err != nil {
+		return nil, err
 	}
 
 	upd := r.Client.Community.UpdateOneID(cid)
@@ -926,10 +1270,8 @@ func (r *mutationResolver) Community(ctx context.Context, input models.UpdateCom
 		upd = upd.SetBannerID(bid)
 	}
 
+	// Slug меняется с тем же правом ManageCommunity, что и остальные настройки (проверено выше)
 	if input.Slug != nil {
-		if cm.OwnerID != currentUserID {
-			return nil, fmt.Errorf("forbidden: only owner can change slug")
-		}
 		newSlug := slug.Make(*input.Slug)
 		if newSlug == "" {
 			return nil, fmt.Errorf("bad_request: empty slug")
@@ -943,7 +1285,12 @@ func (r *mutationResolver) Community(ctx context.Context, input models.UpdateCom
 		}
 		upd = upd.SetSlug(newSlug)
 	}
-	return upd.Save(ctx)
+	updated, err := upd.Save(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r.invalidate(ctx, respcache.TagCommunity(cid))
+	return updated, nil
 }
 
 // UpdateUser is the resolver for the updateUser field.
@@ -959,6 +1306,9 @@ func (r *mutationResolver) UpdateUser(ctx context.Context, input models.UpdateUs
 	if uid != currentUserID {
 		return nil, fmt.Errorf("forbidden")
 	}
+	if err := r.checkSanctions(ctx, currentUserID, nil, sanctions.OpProfile); err != nil {
+		return nil, err
+	}
 
 	upd := r.Client.User.UpdateOneID(uid)
 	if input.Name != nil {
@@ -991,6 +1341,7 @@ func (r *mutationResolver) UpdateUser(ctx context.Context, input models.UpdateUs
 	if _, err := upd.Save(ctx); err != nil {
 		return nil, err
 	}
+	r.invalidate(ctx, respcache.TagUser(uid))
 
 	// Upsert произвольных info
 	if input.Info != nil {
@@ -1032,19 +1383,70 @@ func (r *mutationResolver) UpdateUser(ctx context.Context, input models.UpdateUs
 	return mapped, nil
 }
 
+// UpdateNotificationSettings обновляет настройки уведомлений текущего пользователя.
+func (r *mutationResolver) UpdateNotificationSettings(ctx context.Context, input models.UpdateNotificationSettingsInput) (*ent.NotificationSettings, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil || userID == 0 {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	return r.NotificationSettingsUC.Update(ctx, userID, &input)
+}
+
+// MuteNotifications заглушает уведомления поста или сообщества для текущего пользователя.
+func (r *mutationResolver) MuteNotifications(ctx context.Context, input models.MuteNotificationsInput) (*ent.NotificationSettings, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil || userID == 0 {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	return r.NotificationSettingsUC.Mute(ctx, userID, &input)
+}
+
+// UnmuteNotifications возвращает уведомления поста или сообщества для текущего пользователя.
+func (r *mutationResolver) UnmuteNotifications(ctx context.Context, input models.MuteNotificationsInput) (*ent.NotificationSettings, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil || userID == 0 {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	return r.NotificationSettingsUC.Unmute(ctx, userID, &input)
+}
+
+// MarkNotificationsRead отмечает уведомления текущего пользователя прочитанными.
+func (r *mutationResolver) MarkNotificationsRead(ctx context.Context, ids []string) (int32, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil || userID == 0 {
+		return 0, fmt.Errorf("unauthorized")
+	}
+	nids := make([]int, 0, len(ids))
+	for _, id := range ids {
+		nid, err := strconv.Atoi(id)
+		if err != nil {
+			return 0, fmt.Errorf("invalid notification ID %q: %w", id, err)
+		}
+		nids = append(nids, nid)
+	}
+	n, err := r.NotificationUC.MarkRead(ctx, userID, nids)
+	return int32(n), err
+}
+
+// MarkAllNotificationsRead отмечает все уведомления текущего пользователя прочитанными.
+func (r *mutationResolver) MarkAllNotificationsRead(ctx context.Context) (int32, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil || userID == 0 {
+		return 0, fmt.Errorf("unauthorized")
+	}
+	n, err := r.NotificationUC.MarkAllRead(ctx, userID)
+	return int32(n), err
+}
+
 // UpdateHostSocialNavigation is the resolver for the updateHostSocialNavigation field.
 func (r *mutationResolver) UpdateHostSocialNavigation(ctx context.Context, input models.UpdateHostSocialNavigationInput) (*ent.HostSocialNavigation, error) {
 	currentUserID, err := auth.UserIDFromContext(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("unauthenticated")
 	}
-	hostEntity, err := r.Client.Host.Get(ctx, 1)
-	if err != nil {
+	if err := r.require(ctx, currentUserID, authz.ManageHost, authz.Host(), "only host owner can manage host settings"); err != nil {
 		return nil, err
 	}
-	if hostEntity.OwnerID == nil || *hostEntity.OwnerID != currentUserID {
-		return nil, fmt.Errorf("forbidden")
-	}
 
 	upd := r.Client.HostSocialNavigation.UpdateOneID(1)
 	if input.Github != nil {
@@ -1065,7 +1467,12 @@ func (r *mutationResolver) UpdateHostSocialNavigation(ctx context.Context, input
 	if input.Mastodon != nil {
 		upd = upd.SetMastodon(*input.Mastodon)
 	}
-	return upd.Save(ctx)
+	nav, err := upd.Save(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r.invalidate(ctx, respcache.TagHost)
+	return nav, nil
 }
 
 // CreateHostRole is the resolver for the createHostRole field.
@@ -1076,13 +1483,9 @@ func (r *mutationResolver) CreateHostRole(ctx context.Context, input models.Crea
 		return nil, fmt.Errorf("unauthenticated")
 	}
 
-	hostEntity, err := r.Client.Host.Get(ctx, 1)
-	if err != nil {
+	if err := r.require(ctx, currentUserID, authz.ManageHost, authz.Host(), "only host owner can create roles"); err != nil {
 		return nil, err
 	}
-	if hostEntity.OwnerID == nil || *hostEntity.OwnerID != currentUserID {
-		return nil, fmt.Errorf("forbidden: only host owner can create roles")
-	}
 
 	return r.HostRoleUC.CreateHostRole(ctx, &input)
 }
@@ -1095,13 +1498,9 @@ func (r *mutationResolver) UpdateHostRole(ctx context.Context, input models.Upda
 		return nil, fmt.Errorf("unauthenticated")
 	}
 
-	hostEntity, err := r.Client.Host.Get(ctx, 1)
-	if err != nil {
+	if err := r.require(ctx, currentUserID, authz.ManageHost, authz.Host(), "only host owner can update roles"); err != nil {
 		return nil, err
 	}
-	if hostEntity.OwnerID == nil || *hostEntity.OwnerID != currentUserID {
-		return nil, fmt.Errorf("forbidden: only host owner can update roles")
-	}
 
 	return r.HostRoleUC.UpdateHostRole(ctx, &input)
 }
@@ -1114,13 +1513,9 @@ func (r *mutationResolver) DeleteHostRole(ctx context.Context, id string) (bool,
 		return false, fmt.Errorf("unauthenticated")
 	}
 
-	hostEntity, err := r.Client.Host.Get(ctx, 1)
-	if err != nil {
+	if err := r.require(ctx, currentUserID, authz.ManageHost, authz.Host(), "only host owner can delete roles"); err != nil {
 		return false, err
 	}
-	if hostEntity.OwnerID == nil || *hostEntity.OwnerID != currentUserID {
-		return false, fmt.Errorf("forbidden: only host owner can delete roles")
-	}
 
 	roleID, err := strconv.Atoi(id)
 	if err != nil {
@@ -1143,12 +1538,8 @@ func (r *mutationResolver) AddUserToHostRole(ctx context.Context, input models.A
 	}
 
 	// Проверяем, что пользователь является владельцем платформы
-	hostEntity, err := r.Client.Host.Get(ctx, 1)
-	if err != nil {
-		return false, fmt.Errorf("host not found: %w", err)
-	}
-	if hostEntity.OwnerID == nil || *hostEntity.OwnerID != currentUserID {
-		return false, fmt.Errorf("forbidden: only host owner can manage roles")
+	if err := r.require(ctx, currentUserID, authz.ManageHost, authz.Host(), "only host owner can manage roles"); err != nil {
+		return false, err
 	}
 
 	roleID, err := strconv.Atoi(input.RoleID)
@@ -1177,12 +1568,8 @@ func (r *mutationResolver) RemoveUserFromHostRole(ctx context.Context, input mod
 	}
 
 	// Проверяем, что пользователь является владельцем платформы
-	hostEntity, err := r.Client.Host.Get(ctx, 1)
-	if err != nil {
-		return false, fmt.Errorf("host not found: %w", err)
-	}
-	if hostEntity.OwnerID == nil || *hostEntity.OwnerID != currentUserID {
-		return false, fmt.Errorf("forbidden: only host owner can manage roles")
+	if err := r.require(ctx, currentUserID, authz.ManageHost, authz.Host(), "only host owner can manage roles"); err != nil {
+		return false, err
 	}
 
 	roleID, err := strconv.Atoi(input.RoleID)
@@ -1216,19 +1603,13 @@ func (r *mutationResolver) CreateCommunityRole(ctx context.Context, input models
 	}
 
 	// Проверяем владельца/права
-	cm, err := r.Client.Community.Get(ctx, communityID)
-	if err != nil {
-		return nil, err
+	// Роль старше своей создать нельзя
+	var position int32
+	if input.Position != nil {
+		position = *input.Position
 	}
-	if cm.OwnerID != currentUserID {
-		permsMap, err := r.UserUC.GetPermissionsByCommunities(ctx, currentUserID, []int{communityID})
-		if err != nil {
-			return nil, fmt.Errorf("permissions: %w", err)
-		}
-		perms := permsMap[communityID]
-		if perms == nil || !perms.CommunityRolesManagement {
-			return nil, fmt.Errorf("forbidden: only owner or role manager can create roles")
-		}
+	if err := r.require(ctx, currentUserID, authz.ManageRoles, authz.Community(communityID).WithRole(position), "only owner or role manager can create roles"); err != nil {
+		return nil, err
 	}
 
 	return r.CommunityRoleUC.CreateCommunityRole(ctx, &input)
@@ -1254,18 +1635,13 @@ func (r *mutationResolver) UpdateCommunityRole(ctx context.Context, input models
 	}
 
 	// Проверяем владельца/права
-	cm, err := r.Client.Community.Get(ctx, role.CommunityID)
-	if err != nil {
+	if err := r.require(ctx, currentUserID, authz.ManageRoles, authz.Community(role.CommunityID).WithRole(role.Position), "only owner or role manager can update roles"); err != nil {
 		return nil, err
 	}
-	if cm.OwnerID != currentUserID {
-		permsMap, err := r.UserUC.GetPermissionsByCommunities(ctx, currentUserID, []int{role.CommunityID})
-		if err != nil {
-			return nil, fmt.Errorf("permissions: %w", err)
-		}
-		perms := permsMap[role.CommunityID]
-		if perms == nil || !perms.CommunityRolesManagement {
-			return nil, fmt.Errorf("forbidden: only owner or role manager can update roles")
+	// Поднять роль выше своей тоже нельзя
+	if input.Position != nil {
+		if err := r.require(ctx, currentUserID, authz.ManageRoles, authz.Community(role.CommunityID).WithRole(*input.Position), "cannot raise role above your own"); err != nil {
+			return nil, err
 		}
 	}
 
@@ -1292,20 +1668,9 @@ func (r *mutationResolver) DeleteCommunityRole(ctx context.Context, id string) (
 	}
 
 	// Проверяем владельца/права
-	cm, err := r.Client.Community.Get(ctx, role.CommunityID)
-	if err != nil {
+	if err := r.require(ctx, currentUserID, authz.ManageRoles, authz.Community(role.CommunityID).WithRole(role.Position), "only owner or role manager can delete roles"); err != nil {
 		return false, err
 	}
-	if cm.OwnerID != currentUserID {
-		permsMap, err := r.UserUC.GetPermissionsByCommunities(ctx, currentUserID, []int{role.CommunityID})
-		if err != nil {
-			return false, fmt.Errorf("permissions: %w", err)
-		}
-		perms := permsMap[role.CommunityID]
-		if perms == nil || !perms.CommunityRolesManagement {
-			return false, fmt.Errorf("forbidden: only owner or role manager can delete roles")
-		}
-	}
 
 	err = r.CommunityRoleUC.DeleteCommunityRole(ctx, roleID)
 	if err != nil {
@@ -1320,6 +1685,7 @@ func (r *mutationResolver) CreateHostRule(ctx context.Context, input models.Crea
 	if err != nil {
 		return nil, err
 	}
+	r.invalidate(ctx, respcache.TagHost)
 
 	return &models.HostRule{
 		ID:          strconv.Itoa(rule.ID),
@@ -1336,6 +1702,7 @@ func (r *mutationResolver) UpdateHostRule(ctx context.Context, input models.Upda
 	if err != nil {
 		return nil, err
 	}
+	r.invalidate(ctx, respcache.TagHost)
 
 	return &models.HostRule{
 		ID:          strconv.Itoa(rule.ID),
@@ -1348,229 +1715,245 @@ func (r *mutationResolver) UpdateHostRule(ctx context.Context, input models.Upda
 
 // DeleteHostRule удаляет правило платформы.
 func (r *mutationResolver) DeleteHostRule(ctx context.Context, id string) (bool, error) {
-	return r.HostRuleUC.DeleteHostRule(ctx, id)
+	ok, err := r.HostRuleUC.DeleteHostRule(ctx, id)
+	if err == nil {
+		r.invalidate(ctx, respcache.TagHost)
+	}
+	return ok, err
 }
 
 // MuteUserOnHost мутит пользователя на платформе.
 func (r *mutationResolver) MuteUserOnHost(ctx context.Context, input models.MuteUserOnHostInput) (*models.HostUserMute, error) {
-	mute, err := r.HostMuteUC.MuteUser(ctx, input.UserID)
+	moderatorID, _ := auth.UserIDFromContext(ctx)
+	d, err := sanctionDetails(moderatorID, input.Reason, input.ExpiresAt, input.PublicNote, input.PrivateNote)
 	if err != nil {
 		return nil, err
 	}
+	d.Shadow = input.Shadow
+	mute, err := r.HostMuteUC.MuteUser(ctx, input.UserID, d)
+	if err != nil {
+		return nil, err
+	}
+	if uid, err := strconv.Atoi(input.UserID); err == nil {
+		r.userSanctionsChanged(ctx, uid, nil)
+		r.notifySanction(ctx, uid, moderatorID, nil, "Вам ограничена возможность писать на платформе", d)
+		r.publishModeration(ctx, models.ModerationEventTypeUserMuted, moderatorID, nil, &uid, nil)
+	}
 
-	return &models.HostUserMute{
-		ID:        strconv.Itoa(mute.ID),
-		CreatedAt: mute.CreatedAt,
-		UpdatedAt: mute.UpdatedAt,
-	}, nil
+	return hostUserMuteModel(mute), nil
 }
 
 // UnmuteUserOnHost размучивает пользователя на платформе.
 func (r *mutationResolver) UnmuteUserOnHost(ctx context.Context, muteID string) (bool, error) {
-	return r.HostMuteUC.UnmuteUser(ctx, muteID)
+	// Пользователя запоминаем до удаления мута — для события модерации
+	var userID int
+	if id, err := strconv.Atoi(muteID); err == nil {
+		userID, _ = r.Client.HostUserMute.Query().Where(hostusermute.IDEQ(id)).QueryUser().OnlyID(ctx)
+	}
+	ok, err := r.HostMuteUC.UnmuteUser(ctx, muteID)
+	if err != nil {
+		return false, err
+	}
+	if ok && userID != 0 {
+		r.userSanctionsChanged(ctx, userID, nil)
+		moderatorID, _ := auth.UserIDFromContext(ctx)
+		r.publishModeration(ctx, models.ModerationEventTypeUserUnmuted, moderatorID, nil, &userID, nil)
+	}
+	return ok, nil
 }
 
 // MuteCommunityOnHost мутит сообщество на платформе.
 func (r *mutationResolver) MuteCommunityOnHost(ctx context.Context, input models.MuteCommunityInput) (*models.HostCommunityMute, error) {
-	mute, err := r.HostMuteUC.MuteCommunity(ctx, input.CommunityID)
+	moderatorID, _ := auth.UserIDFromContext(ctx)
+	d, err := sanctionDetails(moderatorID, input.Reason, input.ExpiresAt, input.PublicNote, input.PrivateNote)
 	if err != nil {
 		return nil, err
 	}
+	mute, err := r.HostMuteUC.MuteCommunity(ctx, input.CommunityID, d)
+	if err != nil {
+		return nil, err
+	}
+	r.communitySanctionsChanged(ctx, mute.CommunityID)
 
-	return &models.HostCommunityMute{
-		ID:          strconv.Itoa(mute.ID),
-		CommunityID: strconv.Itoa(mute.CommunityID),
-		CreatedAt:   mute.CreatedAt,
-		UpdatedAt:   mute.UpdatedAt,
-	}, nil
+	return hostCommunityMuteModel(mute), nil
 }
 
 // UnmuteCommunityOnHost размучивает сообщество на платформе.
 func (r *mutationResolver) UnmuteCommunityOnHost(ctx context.Context, muteID string) (bool, error) {
-	return r.HostMuteUC.UnmuteCommunity(ctx, muteID)
+	// Сообщество запоминаем до удаления мута — для сброса кеша санкций
+	var communityID int
+	if id, err := strconv.Atoi(muteID); err == nil {
+		if mute, err := r.Client.HostCommunityMute.Get(ctx, id); err == nil {
+			communityID = mute.CommunityID
+		}
+	}
+	ok, err := r.HostMuteUC.UnmuteCommunity(ctx, muteID)
+	if err != nil {
+		return false, err
+	}
+	if ok && communityID != 0 {
+		r.communitySanctionsChanged(ctx, communityID)
+	}
+	return ok, nil
 }
 
 // BanUserFromHost is the resolver for the banUserFromHost field.
 func (r *mutationResolver) BanUserFromHost(ctx context.Context, input models.BanUserInput) (*ent.HostUserBan, error) {
-	// Проверяем права: только владелец платформы или пользователи с hostUserBan
+	// Проверяем права: только владелец платформы или пользователи с правом host.user.ban
 	currentUserID, err := auth.UserIDFromContext(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("unauthenticated")
 	}
 
-	hostEntity, err := r.Client.Host.Get(ctx, 1)
+	userID, err := strconv.Atoi(input.UserID)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("invalid userID: %w", err)
 	}
 
-	// Проверяем владельца или роли
-	if hostEntity.OwnerID == nil || *hostEntity.OwnerID != currentUserID {
-		// Проверяем роли пользователя
-		roles, err := r.Client.User.Query().
-			Where(user.IDEQ(currentUserID)).
-			QueryHostRoles().
-			All(ctx)
-		if err != nil {
-			return nil, err
-		}
-
-		hasPermission := false
-		for _, role := range roles {
-			if role.HostUserBan {
-				hasPermission = true
-				break
-			}
-		}
-		if !hasPermission {
-			return nil, fmt.Errorf("forbidden: insufficient permissions")
-		}
+	if err := r.require(ctx, currentUserID, authz.HostBanUser, authz.Host().Against(userID), "insufficient permissions"); err != nil {
+		return nil, err
 	}
 
-	userID, err := strconv.Atoi(input.UserID)
+	d, err := sanctionDetails(currentUserID, input.Reason, input.ExpiresAt, input.PublicNote, input.PrivateNote)
 	if err != nil {
-		return nil, fmt.Errorf("invalid userID: %w", err)
+		return nil, err
 	}
+	d.Shadow = input.Shadow
 
-	return r.BanUC.BanUserFromHost(ctx, userID, 1) // hostID всегда 1
+	ban, err := r.BanUC.BanUserFromHost(ctx, userID, 1, d) // hostID всегда 1
+	if err != nil {
+		return nil, err
+	}
+	r.userSanctionsChanged(ctx, userID, nil)
+	r.notifySanction(ctx, userID, currentUserID, nil, "Ваш аккаунт заблокирован на платформе", d)
+	r.publishModeration(ctx, models.ModerationEventTypeUserBanned, currentUserID, nil, &userID, nil)
+	return ban, nil
 }
 
 // UnbanUserFromHost is the resolver for the unbanUserFromHost field.
 func (r *mutationResolver) UnbanUserFromHost(ctx context.Context, banID string) (bool, error) {
-	// Проверяем права: только владелец платформы или пользователи с hostUserBan
+	// Проверяем права: только владелец платформы или пользователи с правом host.user.ban
 	currentUserID, err := auth.UserIDFromContext(ctx)
 	if err != nil {
 		return false, fmt.Errorf("unauthenticated")
 	}
 
-	hostEntity, err := r.Client.Host.Get(ctx, 1)
-	if err != nil {
+	if err := r.require(ctx, currentUserID, authz.HostBanUser, authz.Host(), "insufficient permissions"); err != nil {
 		return false, err
 	}
 
-	// Проверяем владельца или роли
-	if hostEntity.OwnerID == nil || *hostEntity.OwnerID != currentUserID {
-		// Проверяем роли пользователя
-		roles, err := r.Client.User.Query().
-			Where(user.IDEQ(currentUserID)).
-			QueryHostRoles().
-			All(ctx)
-		if err != nil {
-			return false, err
-		}
-
-		hasPermission := false
-		for _, role := range roles {
-			if role.HostUserBan {
-				hasPermission = true
-				break
-			}
-		}
-		if !hasPermission {
-			return false, fmt.Errorf("forbidden: insufficient permissions")
-		}
-	}
-
 	id, err := strconv.Atoi(banID)
 	if err != nil {
 		return false, fmt.Errorf("invalid banID: %w", err)
 	}
+	userID, _ := r.Client.HostUserBan.Query().Where(hostuserban.IDEQ(id)).QueryUser().OnlyID(ctx)
 
 	err = r.BanUC.UnbanUserFromHost(ctx, id)
 	if err != nil {
 		return false, err
 	}
+	if userID != 0 {
+		r.userSanctionsChanged(ctx, userID, nil)
+		r.publishModeration(ctx, models.ModerationEventTypeUserUnbanned, currentUserID, nil, &userID, nil)
+	}
 	return true, nil
 }
 
 // BanCommunityFromHost is the resolver for the banCommunityFromHost field.
 func (r *mutationResolver) BanCommunityFromHost(ctx context.Context, input models.BanCommunityInput) (*models.HostCommunityBan, error) {
-	// Проверяем права: только владелец платформы или пользователи с hostCommunityDeletePost
+	// Проверяем права: только владелец платформы или пользователи с правом host.community.ban
 	currentUserID, err := auth.UserIDFromContext(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("unauthenticated")
 	}
 
-	hostEntity, err := r.Client.Host.Get(ctx, 1)
-	if err != nil {
+	if err := r.require(ctx, currentUserID, authz.HostBanCommunity, authz.Host(), "insufficient permissions"); err != nil {
 		return nil, err
 	}
 
-	// Проверяем владельца или роли
-	if hostEntity.OwnerID == nil || *hostEntity.OwnerID != currentUserID {
-		// Проверяем роли пользователя
-		roles, err := r.Client.User.Query().
-			Where(user.IDEQ(currentUserID)).
-			QueryHostRoles().
-			All(ctx)
-		if err != nil {
-			return nil, err
-		}
-
-		hasPermission := false
-		for _, role := range roles {
-			if role.HostCommunityDeletePost {
-				hasPermission = true
-				break
-			}
-		}
-		if !hasPermission {
-			return nil, fmt.Errorf("forbidden: insufficient permissions")
-		}
-	}
-
 	communityID, err := strconv.Atoi(input.CommunityID)
 	if err != nil {
 		return nil, fmt.Errorf("invalid communityID: %w", err)
 	}
 
-	ban, err := r.BanUC.BanCommunityFromHost(ctx, communityID, 1) // hostID всегда 1
+	d, err := sanctionDetails(currentUserID, input.Reason, input.ExpiresAt, input.PublicNote, input.PrivateNote)
 	if err != nil {
 		return nil, err
 	}
 
-	// Маппинг ent -> models
-	return &models.HostCommunityBan{
-		ID:          strconv.Itoa(ban.ID),
-		CommunityID: strconv.Itoa(ban.CommunityID),
-		CreatedAt:   ban.CreatedAt,
-		UpdatedAt:   ban.UpdatedAt,
-	}, nil
+	ban, err := r.BanUC.BanCommunityFromHost(ctx, communityID, 1, d) // hostID всегда 1
+	if err != nil {
+		return nil, err
+	}
+	r.communitySanctionsChanged(ctx, communityID)
+
+	return hostCommunityBanModel(ban), nil
 }
 
 // UnbanCommunityFromHost is the resolver for the unbanCommunityFromHost field.
 func (r *mutationResolver) UnbanCommunityFromHost(ctx context.Context, banID string) (bool, error) {
-	// Проверяем права: только владелец платформы или пользователи с hostCommunityDeletePost
+	// Проверяем права: только владелец платформы или пользователи с правом host.community.ban
 	currentUserID, err := auth.UserIDFromContext(ctx)
 	if err != nil {
 		return false, fmt.Errorf("unauthenticated")
 	}
 
-	hostEntity, err := r.Client.Host.Get(ctx, 1)
+	if err := r.require(ctx, currentUserID, authz.HostBanCommunity, authz.Host(), "insufficient permissions"); err != nil {
+		return false, err
+	}
+
+	id, err := strconv.Atoi(banID)
+	if err != nil {
+		return false, fmt.Errorf("invalid banID: %w", err)
+	}
+	ban, err := r.Client.HostCommunityBan.Get(ctx, id)
 	if err != nil {
 		return false, err
 	}
 
-	// Проверяем владельца или роли
-	if hostEntity.OwnerID == nil || *hostEntity.OwnerID != currentUserID {
-		// Проверяем роли пользователя
-		roles, err := r.Client.User.Query().
-			Where(user.IDEQ(currentUserID)).
-			QueryHostRoles().
-			All(ctx)
-		if err != nil {
-			return false, err
-		}
+	err = r.BanUC.UnbanCommunityFromHost(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	r.communitySanctionsChanged(ctx, ban.CommunityID)
+	return true, nil
+}
 
-		hasPermission := false
-		for _, role := range roles {
-			if role.HostCommunityDeletePost {
-				hasPermission = true
-				break
-			}
-		}
-		if !hasPermission {
-			return false, fmt.Errorf("forbidden: insufficient permissions")
-		}
+// BanIPOnHost is the resolver for the banIPOnHost field.
+func (r *mutationResolver) BanIPOnHost(ctx context.Context, input models.BanIPInput) (*ent.HostIPBan, error) {
+	// Проверяем права: только владелец платформы или пользователи с правом host.user.ban
+	currentUserID, err := auth.UserIDFromContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unauthenticated")
+	}
+
+	if err := r.require(ctx, currentUserID, authz.HostBanUser, authz.Host(), "insufficient permissions"); err != nil {
+		return nil, err
+	}
+
+	d, err := sanctionDetails(currentUserID, input.Reason, input.ExpiresAt, input.PublicNote, input.PrivateNote)
+	if err != nil {
+		return nil, err
+	}
+
+	ban, err := r.HostIPBanUC.Ban(ctx, currentUserID, input.Cidr, d)
+	if err != nil {
+		return nil, err
+	}
+	r.ipBanChecker().Invalidate()
+	return ban, nil
+}
+
+// UnbanIPOnHost is the resolver for the unbanIPOnHost field.
+func (r *mutationResolver) UnbanIPOnHost(ctx context.Context, banID string) (bool, error) {
+	// Проверяем права: только владелец платформы или пользователи с правом host.user.ban
+	currentUserID, err := auth.UserIDFromContext(ctx)
+	if err != nil {
+		return false, fmt.Errorf("unauthenticated")
+	}
+
+	if err := r.require(ctx, currentUserID, authz.HostBanUser, authz.Host(), "insufficient permissions"); err != nil {
+		return false, err
 	}
 
 	id, err := strconv.Atoi(banID)
@@ -1578,16 +1961,16 @@ func (r *mutationResolver) UnbanCommunityFromHost(ctx context.Context, banID str
 		return false, fmt.Errorf("invalid banID: %w", err)
 	}
 
-	err = r.BanUC.UnbanCommunityFromHost(ctx, id)
-	if err != nil {
+	if err := r.HostIPBanUC.Unban(ctx, currentUserID, id); err != nil {
 		return false, err
 	}
+	r.ipBanChecker().Invalidate()
 	return true, nil
 }
 
 // BanUserFromCommunity is the resolver for the banUserFromCommunity field.
 func (r *mutationResolver) BanUserFromCommunity(ctx context.Context, input models.BanUserInput) (*ent.CommunityUserBan, error) {
-	// Проверяем права: владелец сообщества или пользователи с communityUserBan
+	// Проверяем права: владелец сообщества или пользователи с правом community.user.ban
 	currentUserID, err := auth.UserIDFromContext(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("unauthenticated")
@@ -1602,33 +1985,39 @@ func (r *mutationResolver) BanUserFromCommunity(ctx context.Context, input model
 		return nil, fmt.Errorf("invalid communityID: %w", err)
 	}
 
-	// Проверяем владельца/права
+	userID, err := strconv.Atoi(input.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid userID: %w", err)
+	}
+
+	// Проверяем права и старшинство: модератор не наказывает тех, кто старше его
 	cm, err := r.Client.Community.Get(ctx, communityID)
 	if err != nil {
 		return nil, err
 	}
-	if cm.OwnerID != currentUserID {
-		permsMap, err := r.UserUC.GetPermissionsByCommunities(ctx, currentUserID, []int{communityID})
-		if err != nil {
-			return nil, fmt.Errorf("permissions: %w", err)
-		}
-		perms := permsMap[communityID]
-		if perms == nil || !perms.CommunityUserBan {
-			return nil, fmt.Errorf("forbidden: only owner or users with ban permission can ban users")
-		}
+	if err := r.require(ctx, currentUserID, authz.BanUser, authz.Community(communityID).Against(userID), "only owner or users with ban permission can ban users"); err != nil {
+		return nil, err
 	}
 
-	userID, err := strconv.Atoi(input.UserID)
+	d, err := sanctionDetails(currentUserID, input.Reason, input.ExpiresAt, input.PublicNote, input.PrivateNote)
 	if err != nil {
-		return nil, fmt.Errorf("invalid userID: %w", err)
+		return nil, err
 	}
+	d.Shadow = input.Shadow
 
-	return r.BanUC.BanUserFromCommunity(ctx, userID, communityID)
+	ban, err := r.BanUC.BanUserFromCommunity(ctx, userID, communityID, d)
+	if err != nil {
+		return nil, err
+	}
+	r.userSanctionsChanged(ctx, userID, &communityID)
+	r.notifySanction(ctx, userID, currentUserID, &communityID, fmt.Sprintf("Вы заблокированы в сообществе «%s»", cm.Title), d)
+	r.publishModeration(ctx, models.ModerationEventTypeUserBanned, currentUserID, &communityID, &userID, nil)
+	return ban, nil
 }
 
 // UnbanUserFromCommunity is the resolver for the unbanUserFromCommunity field.
 func (r *mutationResolver) UnbanUserFromCommunity(ctx context.Context, banID string) (bool, error) {
-	// Проверяем права: владелец сообщества или пользователи с communityUserBan
+	// Проверяем права: владелец сообщества или пользователи с правом community.user.ban
 	currentUserID, err := auth.UserIDFromContext(ctx)
 	if err != nil {
 		return false, fmt.Errorf("unauthenticated")
@@ -1646,31 +2035,22 @@ func (r *mutationResolver) UnbanUserFromCommunity(ctx context.Context, banID str
 	}
 
 	// Проверяем владельца/права
-	cm, err := r.Client.Community.Get(ctx, ban.CommunityID)
-	if err != nil {
+	if err := r.require(ctx, currentUserID, authz.BanUser, authz.Community(ban.CommunityID), "only owner or users with ban permission can unban users"); err != nil {
 		return false, err
 	}
-	if cm.OwnerID != currentUserID {
-		permsMap, err := r.UserUC.GetPermissionsByCommunities(ctx, currentUserID, []int{ban.CommunityID})
-		if err != nil {
-			return false, fmt.Errorf("permissions: %w", err)
-		}
-		perms := permsMap[ban.CommunityID]
-		if perms == nil || !perms.CommunityUserBan {
-			return false, fmt.Errorf("forbidden: only owner or users with ban permission can unban users")
-		}
-	}
 
 	err = r.BanUC.UnbanUserFromCommunity(ctx, id)
 	if err != nil {
 		return false, err
 	}
+	r.userSanctionsChanged(ctx, ban.UserID, &ban.CommunityID)
+	r.publishModeration(ctx, models.ModerationEventTypeUserUnbanned, currentUserID, &ban.CommunityID, &ban.UserID, nil)
 	return true, nil
 }
 
 // MuteUserInCommunity is the resolver for the muteUserInCommunity field.
 func (r *mutationResolver) MuteUserInCommunity(ctx context.Context, input models.MuteUserInput) (*ent.CommunityUserMute, error) {
-	// Проверяем права: владелец сообщества или пользователи с communityUserMute
+	// Проверяем права: владелец сообщества или пользователи с правом community.user.mute
 	currentUserID, err := auth.UserIDFromContext(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("unauthenticated")
@@ -1681,33 +2061,39 @@ func (r *mutationResolver) MuteUserInCommunity(ctx context.Context, input models
 		return nil, fmt.Errorf("invalid communityID: %w", err)
 	}
 
-	// Проверяем владельца/права
+	userID, err := strconv.Atoi(input.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid userID: %w", err)
+	}
+
+	// Проверяем права и старшинство: модератор не наказывает тех, кто старше его
 	cm, err := r.Client.Community.Get(ctx, communityID)
 	if err != nil {
 		return nil, err
 	}
-	if cm.OwnerID != currentUserID {
-		permsMap, err := r.UserUC.GetPermissionsByCommunities(ctx, currentUserID, []int{communityID})
-		if err != nil {
-			return nil, fmt.Errorf("permissions: %w", err)
-		}
-		perms := permsMap[communityID]
-		if perms == nil || !perms.CommunityUserMute {
-			return nil, fmt.Errorf("forbidden: only owner or users with mute permission can mute users")
-		}
+	if err := r.require(ctx, currentUserID, authz.MuteUser, authz.Community(communityID).Against(userID), "only owner or users with mute permission can mute users"); err != nil {
+		return nil, err
 	}
 
-	userID, err := strconv.Atoi(input.UserID)
+	d, err := sanctionDetails(currentUserID, input.Reason, input.ExpiresAt, input.PublicNote, input.PrivateNote)
 	if err != nil {
-		return nil, fmt.Errorf("invalid userID: %w", err)
+		return nil, err
 	}
+	d.Shadow = input.Shadow
 
-	return r.BanUC.MuteUserInCommunity(ctx, userID, communityID)
+	mute, err := r.BanUC.MuteUserInCommunity(ctx, userID, communityID, d)
+	if err != nil {
+		return nil, err
+	}
+	r.userSanctionsChanged(ctx, userID, &communityID)
+	r.notifySanction(ctx, userID, currentUserID, &communityID, fmt.Sprintf("Вам ограничена возможность писать в сообществе «%s»", cm.Title), d)
+	r.publishModeration(ctx, models.ModerationEventTypeUserMuted, currentUserID, &communityID, &userID, nil)
+	return mute, nil
 }
 
 // UnmuteUserInCommunity is the resolver for the unmuteUserInCommunity field.
 func (r *mutationResolver) UnmuteUserInCommunity(ctx context.Context, muteID string) (bool, error) {
-	// Проверяем права: владелец сообщества или пользователи с communityUserMute
+	// Проверяем права: владелец сообщества или пользователи с правом community.user.mute
 	currentUserID, err := auth.UserIDFromContext(ctx)
 	if err != nil {
 		return false, fmt.Errorf("unauthenticated")
@@ -1725,25 +2111,16 @@ func (r *mutationResolver) UnmuteUserInCommunity(ctx context.Context, muteID str
 	}
 
 	// Проверяем владельца/права
-	cm, err := r.Client.Community.Get(ctx, mute.CommunityID)
-	if err != nil {
+	if err := r.require(ctx, currentUserID, authz.MuteUser, authz.Community(mute.CommunityID), "only owner or users with mute permission can unmute users"); err != nil {
 		return false, err
 	}
-	if cm.OwnerID != currentUserID {
-		permsMap, err := r.UserUC.GetPermissionsByCommunities(ctx, currentUserID, []int{mute.CommunityID})
-		if err != nil {
-			return false, fmt.Errorf("permissions: %w", err)
-		}
-		perms := permsMap[mute.CommunityID]
-		if perms == nil || !perms.CommunityUserMute {
-			return false, fmt.Errorf("forbidden: only owner or users with mute permission can unmute users")
-		}
-	}
 
 	err = r.BanUC.UnmuteUserInCommunity(ctx, id)
 	if err != nil {
 		return false, err
 	}
+	r.userSanctionsChanged(ctx, mute.UserID, &mute.CommunityID)
+	r.publishModeration(ctx, models.ModerationEventTypeUserUnmuted, currentUserID, &mute.CommunityID, &mute.UserID, nil)
 	return true, nil
 }
 
@@ -1763,20 +2140,8 @@ func (r *mutationResolver) CreateProfileTableInfoItem(ctx context.Context, input
 		}
 
 		// Проверяем права на сообщество
-		cm, err := r.Client.Community.Get(ctx, cid)
-		if err != nil {
-			return nil, fmt.Errorf("community not found: %w", err)
-		}
-
-		if cm.OwnerID != currentUserID {
-			permsMap, err := r.UserUC.GetPermissionsByCommunities(ctx, currentUserID, []int{cid})
-			if err != nil {
-				return nil, fmt.Errorf("permissions: %w", err)
-			}
-			perms := permsMap[cid]
-			if perms == nil || !perms.CommunityRolesManagement {
-				return nil, fmt.Errorf("forbidden: only owner or role manager can create profile info items")
-			}
+		if err := r.require(ctx, currentUserID, authz.ManageCommunity, authz.Community(cid), "only owner or role manager can create profile info items"); err != nil {
+			return nil, err
 		}
 	} else if input.Type == "user" && input.UserID != nil {
 		uid, err := strconv.Atoi(*input.UserID)
@@ -1817,20 +2182,8 @@ func (r *mutationResolver) UpdateProfileTableInfoItem(ctx context.Context, input
 	// Проверяем права в зависимости от типа
 	if item.Type == "community" && item.CommunityID != 0 {
 		// Проверяем права на сообщество
-		cm, err := r.Client.Community.Get(ctx, item.CommunityID)
-		if err != nil {
-			return nil, fmt.Errorf("community not found: %w", err)
-		}
-
-		if cm.OwnerID != currentUserID {
-			permsMap, err := r.UserUC.GetPermissionsByCommunities(ctx, currentUserID, []int{item.CommunityID})
-			if err != nil {
-				return nil, fmt.Errorf("permissions: %w", err)
-			}
-			perms := permsMap[item.CommunityID]
-			if perms == nil || !perms.CommunityRolesManagement {
-				return nil, fmt.Errorf("forbidden: only owner or role manager can update profile info items")
-			}
+		if err := r.require(ctx, currentUserID, authz.ManageCommunity, authz.Community(item.CommunityID), "only owner or role manager can update profile info items"); err != nil {
+			return nil, err
 		}
 	} else if item.Type == "user" && item.UserID != 0 {
 		// Пользователь может обновлять элементы только своего профиля
@@ -1864,20 +2217,8 @@ func (r *mutationResolver) DeleteProfileTableInfoItem(ctx context.Context, id st
 	// Проверяем права в зависимости от типа
 	if item.Type == "community" && item.CommunityID != 0 {
 		// Проверяем права на сообщество
-		cm, err := r.Client.Community.Get(ctx, item.CommunityID)
-		if err != nil {
-			return false, fmt.Errorf("community not found: %w", err)
-		}
-
-		if cm.OwnerID != currentUserID {
-			permsMap, err := r.UserUC.GetPermissionsByCommunities(ctx, currentUserID, []int{item.CommunityID})
-			if err != nil {
-				return false, fmt.Errorf("permissions: %w", err)
-			}
-			perms := permsMap[item.CommunityID]
-			if perms == nil || !perms.CommunityRolesManagement {
-				return false, fmt.Errorf("forbidden: only owner or role manager can delete profile info items")
-			}
+		if err := r.require(ctx, currentUserID, authz.ManageCommunity, authz.Community(item.CommunityID), "only owner or role manager can delete profile info items"); err != nil {
+			return false, err
 		}
 	} else if item.Type == "user" && item.UserID != 0 {
 		// Пользователь может удалять элементы только своего профиля
@@ -1896,27 +2237,325 @@ func (r *mutationResolver) DeleteProfileTableInfoItem(ctx context.Context, id st
 
 // CreateCommunityRule is the resolver for the createCommunityRule field.
 func (r *mutationResolver) CreateCommunityRule(ctx context.Context, input models.CreateCommunityRuleInput) (*ent.CommunityRule, error) {
-	return r.CommunityRuleUsecase.CreateCommunityRule(ctx, &input)
+	rule, err := r.CommunityRuleUsecase.CreateCommunityRule(ctx, &input)
+	if err != nil {
+		return nil, err
+	}
+	r.invalidateCommunityRule(ctx, rule)
+	return rule, nil
 }
 
 // UpdateCommunityRule is the resolver for the updateCommunityRule field.
 func (r *mutationResolver) UpdateCommunityRule(ctx context.Context, input models.UpdateCommunityRuleInput) (*ent.CommunityRule, error) {
-	return r.CommunityRuleUsecase.UpdateCommunityRule(ctx, &input)
+	rule, err := r.CommunityRuleUsecase.UpdateCommunityRule(ctx, &input)
+	if err != nil {
+		return nil, err
+	}
+	r.invalidateCommunityRule(ctx, rule)
+	return rule, nil
 }
 
 // DeleteCommunityRule is the resolver for the deleteCommunityRule field.
 func (r *mutationResolver) DeleteCommunityRule(ctx context.Context, id string) (bool, error) {
-	return r.CommunityRuleUsecase.DeleteCommunityRule(ctx, id)
+	// Сообщество запоминаем до удаления правила — для сброса кеша ответов
+	rule, _ := r.CommunityRuleUsecase.GetCommunityRule(ctx, id)
+	ok, err := r.CommunityRuleUsecase.DeleteCommunityRule(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	if ok {
+		r.invalidateCommunityRule(ctx, rule)
+	}
+	return ok, nil
+}
+
+// CreateReport is the resolver for the createReport field.
+func (r *mutationResolver) CreateReport(ctx context.Context, input models.CreateReportInput) (*ent.Report, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil || userID == 0 {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	return r.ReportUC.Create(ctx, userID, input)
+}
+
+// ResolveReport is the resolver for the resolveReport field.
+func (r *mutationResolver) ResolveReport(ctx context.Context, id string, note *string) (*ent.Report, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil || userID == 0 {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	rep, err := r.moderatedReport(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return r.closeReport(ctx, userID, rep, report.StatusResolved, note)
+}
+
+// DismissReport is the resolver for the dismissReport field.
+func (r *mutationResolver) DismissReport(ctx context.Context, id string, note *string) (*ent.Report, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil || userID == 0 {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	rep, err := r.moderatedReport(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return r.closeReport(ctx, userID, rep, report.StatusDismissed, note)
+}
+
+// EscalateReport is the resolver for the escalateReport field.
+func (r *mutationResolver) EscalateReport(ctx context.Context, id string, note *string) (*ent.Report, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil || userID == 0 {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	rep, err := r.moderatedReport(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return r.ReportUC.Escalate(ctx, rep.ID, userID, note)
+}
+
+// ActOnReport is the resolver for the actOnReport field.
+func (r *mutationResolver) ActOnReport(ctx context.Context, input models.ActOnReportInput) (*ent.Report, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil || userID == 0 {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	rep, err := r.moderatedReport(ctx, userID, input.ReportID)
+	if err != nil {
+		return nil, err
+	}
+	if rep.Status != report.StatusOpen {
+		return nil, fmt.Errorf("report is already closed")
+	}
+
+	// Меры применяются обычными мутациями модерации: с их проверками прав, журналом и уведомлениями.
+	// Очередь жалобы определяет, где наказывается автор: в сообществе или на платформе
+	targetID := strconv.Itoa(rep.TargetID)
+	hostQueue := rep.Queue == report.QueueHost || rep.CommunityID == nil
+	var targetUserID, communityID string
+	if rep.TargetUserID != nil {
+		targetUserID = strconv.Itoa(*rep.TargetUserID)
+	}
+	if rep.CommunityID != nil {
+		communityID = strconv.Itoa(*rep.CommunityID)
+	}
+	switch input.Action {
+	case models.ReportActionRemoveContent:
+		switch rep.TargetType {
+		case report.TargetTypePost:
+			if err := r.require(ctx, userID, authz.DeletePost, authz.Community(*rep.CommunityID), "only owner or users with delete permission can delete posts"); err != nil {
+				return nil, err
+			}
+			deleted := post.VisibilityDeleted
+			_, err = r.Post(ctx, models.UpdatePostInput{ID: targetID, Visibility: &deleted})
+		case report.TargetTypeComment:
+			if err := r.require(ctx, userID, authz.DeleteComment, authz.Community(*rep.CommunityID), "only owner or users with delete permission can delete comments"); err != nil {
+				return nil, err
+			}
+			deleted := true
+			_, err = r.UpdateComment(ctx, models.UpdateCommentInput{ID: targetID, HasDeleted: &deleted})
+		default:
+			return nil, fmt.Errorf("%s applies only to posts and comments", input.Action)
+		}
+	case models.ReportActionBan:
+		switch {
+		case rep.TargetType == report.TargetTypeCommunity:
+			_, err = r.BanCommunityFromHost(ctx, models.BanCommunityInput{CommunityID: targetID,
+				Reason: input.Reason, ExpiresAt: input.ExpiresAt, PublicNote: input.PublicNote, PrivateNote: input.PrivateNote})
+		case hostQueue:
+			_, err = r.BanUserFromHost(ctx, models.BanUserInput{UserID: targetUserID,
+				Reason: input.Reason, ExpiresAt: input.ExpiresAt, PublicNote: input.PublicNote, PrivateNote: input.PrivateNote})
+		default:
+			_, err = r.BanUserFromCommunity(ctx, models.BanUserInput{UserID: targetUserID, CommunityID: &communityID,
+				Reason: input.Reason, ExpiresAt: input.ExpiresAt, PublicNote: input.PublicNote, PrivateNote: input.PrivateNote})
+		}
+	case models.ReportActionMute:
+		switch {
+		case rep.TargetType == report.TargetTypeCommunity:
+			_, err = r.MuteCommunityOnHost(ctx, models.MuteCommunityInput{CommunityID: targetID,
+				Reason: input.Reason, ExpiresAt: input.ExpiresAt, PublicNote: input.PublicNote, PrivateNote: input.PrivateNote})
+		case hostQueue:
+			_, err = r.MuteUserOnHost(ctx, models.MuteUserOnHostInput{UserID: targetUserID,
+				Reason: input.Reason, ExpiresAt: input.ExpiresAt, PublicNote: input.PublicNote, PrivateNote: input.PrivateNote})
+		default:
+			_, err = r.MuteUserInCommunity(ctx, models.MuteUserInput{UserID: targetUserID, CommunityID: communityID,
+				Reason: input.Reason, ExpiresAt: input.ExpiresAt, PublicNote: input.PublicNote, PrivateNote: input.PrivateNote})
+		}
+	default:
+		return nil, fmt.Errorf("unknown report action %q", input.Action)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.closeReport(ctx, userID, rep, report.StatusResolved, input.Note)
+}
+
+// AppealBan is the resolver for the appealBan field.
+func (r *mutationResolver) AppealBan(ctx context.Context, input models.AppealBanInput) (*ent.BanAppeal, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil || userID == 0 {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	banID, err := strconv.Atoi(input.BanID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid banID: %w", err)
+	}
+	// Санкции не проверяются: апелляцию подает как раз забаненный
+	a, err := r.BanAppealUC.Submit(ctx, userID, input.Scope, banID, input.Message)
+	if err != nil {
+		return nil, appealError(ctx, err)
+	}
+	if a.BanIssuedBy != nil {
+		r.notifyModeration(ctx, *a.BanIssuedBy, userID, a.CommunityID, "Подана апелляция на выданный вами бан")
+	}
+	return a, nil
+}
+
+// AcceptBanAppeal is the resolver for the acceptBanAppeal field.
+func (r *mutationResolver) AcceptBanAppeal(ctx context.Context, id string, message *string) (*ent.BanAppeal, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil || userID == 0 {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	return r.decideAppeal(ctx, userID, id, banappeal.StatusAccepted, message)
+}
+
+// RejectBanAppeal is the resolver for the rejectBanAppeal field.
+func (r *mutationResolver) RejectBanAppeal(ctx context.Context, id string, message *string) (*ent.BanAppeal, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil || userID == 0 {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	return r.decideAppeal(ctx, userID, id, banappeal.StatusRejected, message)
+}
+
+// CreateAutomodRule is the resolver for the createAutomodRule field.
+func (r *mutationResolver) CreateAutomodRule(ctx context.Context, input models.CreateAutomodRuleInput) (*ent.AutomodRule, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil || userID == 0 {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	draft, err := r.AutomodRuleUC.Draft(input)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.requireAutomodManager(ctx, userID, draft.CommunityID); err != nil {
+		return nil, err
+	}
+	return r.AutomodRuleUC.CreateAutomodRule(ctx, input)
+}
+
+// UpdateAutomodRule is the resolver for the updateAutomodRule field.
+func (r *mutationResolver) UpdateAutomodRule(ctx context.Context, input models.UpdateAutomodRuleInput) (*ent.AutomodRule, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil || userID == 0 {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	rule, err := r.automodRuleByID(ctx, input.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.requireAutomodManager(ctx, userID, rule.CommunityID); err != nil {
+		return nil, err
+	}
+	return r.AutomodRuleUC.UpdateAutomodRule(ctx, rule.ID, input)
+}
+
+// DeleteAutomodRule is the resolver for the deleteAutomodRule field.
+func (r *mutationResolver) DeleteAutomodRule(ctx context.Context, id string) (bool, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil || userID == 0 {
+		return false, fmt.Errorf("unauthorized")
+	}
+	rule, err := r.automodRuleByID(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	if err := r.requireAutomodManager(ctx, userID, rule.CommunityID); err != nil {
+		return false, err
+	}
+	if err := r.AutomodRuleUC.DeleteAutomodRule(ctx, rule.ID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// SetTyping включает или выключает индикатор набора ответа текущего пользователя.
+func (r *mutationResolver) SetTyping(ctx context.Context, postID string, typing bool) (bool, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil || userID == 0 {
+		return false, fmt.Errorf("unauthorized")
+	}
+	p, _, err := r.threadPost(ctx, postID)
+	if err != nil {
+		return false, err
+	}
+	if typing {
+		if err := r.checkSanctions(ctx, userID, &p.CommunityID, sanctions.OpComment); err != nil {
+			return false, err
+		}
+	}
+	if err := r.Presence.SetTyping(ctx, p.ID, userID, typing); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Actors отдает последних участников сгруппированного уведомления в порядке actor_ids.
+func (r *notificationResolver) Actors(ctx context.Context, obj *ent.Notification) ([]*ent.User, error) {
+	if len(obj.ActorIds) == 0 {
+		return []*ent.User{}, nil
+	}
+	users, err := r.Client.User.Query().Where(user.IDIn(obj.ActorIds...)).All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load actors: %w", err)
+	}
+	byID := make(map[int]*ent.User, len(users))
+	for _, u := range users {
+		byID[u.ID] = u
+	}
+	out := make([]*ent.User, 0, len(users))
+	for _, id := range obj.ActorIds {
+		if u, ok := byID[id]; ok {
+			out = append(out, u)
+		}
+	}
+	return out, nil
+}
+
+// Preferences отдает каналы доставки по всем типам уведомлений.
+func (r *notificationSettingsResolver) Preferences(ctx context.Context, obj *ent.NotificationSettings) ([]*models.NotificationPreference, error) {
+	return notificationPreferences(obj), nil
+}
+
+// QuietHours отдает тихие часы пользователя.
+func (r *notificationSettingsResolver) QuietHours(ctx context.Context, obj *ent.NotificationSettings) (*models.QuietHours, error) {
+	return quietHours(obj), nil
+}
+
+// MutedPosts отдает заглушенные посты.
+func (r *notificationSettingsResolver) MutedPosts(ctx context.Context, obj *ent.NotificationSettings) ([]*ent.Post, error) {
+	if len(obj.MutedPostIds) == 0 {
+		return []*ent.Post{}, nil
+	}
+	return r.Client.Post.Query().Where(post.IDIn(obj.MutedPostIds...)).All(ctx)
+}
+
+// MutedCommunities отдает заглушенные сообщества.
+func (r *notificationSettingsResolver) MutedCommunities(ctx context.Context, obj *ent.NotificationSettings) ([]*ent.Community, error) {
+	if len(obj.MutedCommunityIds) == 0 {
+		return []*ent.Community{}, nil
+	}
+	return r.Client.Community.Query().Where(community.IDIn(obj.MutedCommunityIds...)).All(ctx)
 }
 
 // PostStatus is the resolver for the postStatus field.
 func (r *postResolver) PostStatus(ctx context.Context, obj *ent.Post) (*models.PostStatus, error) {
-	// Берём текущего пользователя из контекста, для корректного isLiked/hasBookmark
-	uid := 0
-	if id, err := auth.UserIDFromContext(ctx); err == nil {
-		uid = id
-	}
-	return r.PostUC.GetPostStatus(ctx, uid, obj.ID)
+	// Счётчики и флаги isLiked/hasBookmark грузятся пакетно для всех постов страницы
+	return r.postStatus(ctx, obj)
 }
 
 // Media возвращает медиа по ID.
@@ -1954,11 +2593,12 @@ func (r *queryResolver) CommunityBySlug(ctx context.Context, slug string) (*ent.
 
 // Communities возвращает все или только не забаненные сообщества.
 func (r *queryResolver) Communities(ctx context.Context, onlyNotBanned *bool) ([]*ent.Community, error) {
-	q := r.Client.Community.Query()
-	if onlyNotBanned == nil || *onlyNotBanned {
-		q = q.Where(community.CommunityHasBanned(false))
-	}
-	return q.Order(ent.Asc("id")).All(ctx)
+	return r.communitiesQuery(onlyNotBanned).Order(ent.Asc("id")).All(ctx)
+}
+
+// CommunitiesConnection отдает сообщества постранично, в порядке создания.
+func (r *queryResolver) CommunitiesConnection(ctx context.Context, onlyNotBanned *bool, first *int32, after *string, last *int32, before *string) (*models.CommunitiesConnection, error) {
+	return communitiesConnection(ctx, r.communitiesQuery(onlyNotBanned), connectionArgs(ctx, first, after, last, before))
 }
 
 // CommunityUserBan отдает запись о бане юзера по userID и communityID.
@@ -2056,8 +2696,202 @@ func (r *queryResolver) GetMe(ctx context.Context) (*models.UserResponse, error)
 		log.Printf("❌ [LoginUser] Failed to map user: %v", err)
 		return nil, status.Errorf(codes.Internal, "failed to map user: %v", err)
 	}
-
-	return user, nil
+
+	return user, nil
+}
+
+// MyNotificationSettings отдает настройки уведомлений текущего пользователя.
+func (r *queryResolver) MyNotificationSettings(ctx context.Context) (*ent.NotificationSettings, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil || userID == 0 {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	return r.NotificationSettingsUC.GetOrCreate(ctx, userID)
+}
+
+// Notifications отдает уведомления текущего пользователя с курсорной пагинацией.
+func (r *queryResolver) Notifications(ctx context.Context, first *int32, after *string, unreadOnly *bool) (*models.NotificationsConnection, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil || userID == 0 {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	limit := 20
+	if first != nil {
+		limit = int(*first)
+	}
+	return r.NotificationUC.List(ctx, userID, limit, after, unreadOnly != nil && *unreadOnly)
+}
+
+// UnreadNotificationsCount отдает число непрочитанных уведомлений текущего пользователя.
+func (r *queryResolver) UnreadNotificationsCount(ctx context.Context) (int32, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil || userID == 0 {
+		return 0, fmt.Errorf("unauthorized")
+	}
+	n, err := r.NotificationUC.UnreadCount(ctx, userID)
+	return int32(n), err
+}
+
+// ModerationLog is the resolver for the moderationLog field.
+func (r *queryResolver) ModerationLog(ctx context.Context, first *int32, after *string, filter *models.ModerationLogFilter) (*models.ModerationActionsConnection, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil || userID == 0 {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	var communityID *int
+	if filter != nil && filter.CommunityID != nil {
+		id, err := strconv.Atoi(*filter.CommunityID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid communityID: %w", err)
+		}
+		communityID = &id
+	}
+	ok, err := r.canSeeModerationLog(ctx, userID, communityID)
+	if err != nil {
+		return nil, fmt.Errorf("permissions: %w", err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: moderation log", authz.ErrForbidden)
+	}
+	limit := 20
+	if first != nil {
+		limit = int(*first)
+	}
+	return r.ModerationLogUC.List(ctx, filter, limit, after)
+}
+
+// ReportQueue is the resolver for the reportQueue field.
+func (r *queryResolver) ReportQueue(ctx context.Context, communityID *string, status *report.Status, first *int32, after *string) (*models.ReportsConnection, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil || userID == 0 {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	var cid *int
+	action, res := authz.HostManageReports, authz.Host()
+	if communityID != nil {
+		id, err := strconv.Atoi(*communityID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid communityID: %w", err)
+		}
+		cid = &id
+		action, res = authz.ManageReports, authz.Community(id)
+	}
+	if err := r.require(ctx, userID, action, res, "only moderators of the report queue can view it"); err != nil {
+		return nil, err
+	}
+	st := report.StatusOpen
+	if status != nil {
+		st = *status
+	}
+	limit := 20
+	if first != nil {
+		limit = int(*first)
+	}
+	return r.ReportUC.Queue(ctx, cid, st, limit, after)
+}
+
+// BanAppealQueue is the resolver for the banAppealQueue field.
+func (r *queryResolver) BanAppealQueue(ctx context.Context, scope banappeal.Scope, communityID *string, status *banappeal.Status, first *int32, after *string) (*models.BanAppealsConnection, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil || userID == 0 {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	var cid *int
+	if communityID != nil {
+		id, err := strconv.Atoi(*communityID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid communityID: %w", err)
+		}
+		cid = &id
+	}
+	// Персонал платформы видит все очереди, модераторы сообщества — очередь своего сообщества
+	host, err := r.authorizer().Can(ctx, userID, authz.HostBanUser, authz.Host())
+	if err != nil {
+		return nil, err
+	}
+	if !host {
+		if scope != banappeal.ScopeCommunity || cid == nil {
+			return nil, r.require(ctx, userID, authz.HostBanUser, authz.Host(), "only host staff can view this appeal queue")
+		}
+		if err := r.require(ctx, userID, authz.BanUser, authz.Community(*cid), "only community moderators can view ban appeals"); err != nil {
+			return nil, err
+		}
+	}
+	st := banappeal.StatusPending
+	if status != nil {
+		st = *status
+	}
+	limit := 20
+	if first != nil {
+		limit = int(*first)
+	}
+	return r.BanAppealUC.Queue(ctx, scope, cid, st, limit, after)
+}
+
+// MyBanAppeals is the resolver for the myBanAppeals field.
+func (r *queryResolver) MyBanAppeals(ctx context.Context) ([]*ent.BanAppeal, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil || userID == 0 {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	return r.BanAppealUC.ByUser(ctx, userID)
+}
+
+// AutomodRules is the resolver for the automodRules field.
+func (r *queryResolver) AutomodRules(ctx context.Context, communityID *string) ([]*ent.AutomodRule, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil || userID == 0 {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	var cid *int
+	if communityID != nil {
+		id, err := strconv.Atoi(*communityID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid communityID: %w", err)
+		}
+		cid = &id
+	}
+	if err := r.requireAutomodManager(ctx, userID, cid); err != nil {
+		return nil, err
+	}
+	return r.AutomodRuleUC.GetAutomodRules(ctx, cid)
+}
+
+// TestAutomod is the resolver for the testAutomod field.
+func (r *queryResolver) TestAutomod(ctx context.Context, communityID string, text string, authorID *string, rule *models.CreateAutomodRuleInput) (*models.AutomodTestResult, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil || userID == 0 {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	cid, err := strconv.Atoi(communityID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid communityID: %w", err)
+	}
+	if err := r.requireAutomodManager(ctx, userID, &cid); err != nil {
+		return nil, err
+	}
+	content := automod.Content{AuthorID: userID, CommunityID: cid, Text: text}
+	if authorID != nil {
+		if content.AuthorID, err = strconv.Atoi(*authorID); err != nil {
+			return nil, fmt.Errorf("invalid authorID: %w", err)
+		}
+	}
+
+	// Без rule — действующие правила, с rule — только оно, без сохранения
+	var v *automod.Verdict
+	if rule == nil {
+		v, err = r.automodEngine().Evaluate(ctx, content)
+	} else {
+		var draft *ent.AutomodRule
+		if draft, err = r.AutomodRuleUC.Draft(*rule); err != nil {
+			return nil, err
+		}
+		v, err = r.automodEngine().Test(ctx, content, []*ent.AutomodRule{draft})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return automodTestResult(v), nil
 }
 
 // User отдает одного пользователя по ID.
@@ -2093,6 +2927,11 @@ func (r *queryResolver) Users(ctx context.Context) ([]*ent.User, error) {
 		All(ctx)
 }
 
+// UsersConnection отдает пользователей постранично, в порядке регистрации.
+func (r *queryResolver) UsersConnection(ctx context.Context, first *int32, after *string, last *int32, before *string) (*models.UsersConnection, error) {
+	return usersConnection(ctx, r.Client.User.Query(), connectionArgs(ctx, first, after, last, before))
+}
+
 // ProfileTableInfoItem возвращает один итем профиля по ID итема.
 func (r *queryResolver) ProfileTableInfoItem(ctx context.Context, id string) (*ent.ProfileTableInfoItem, error) {
 	itemId, err := strconv.Atoi(id)
@@ -2148,118 +2987,73 @@ func (r *queryResolver) PostBySlug(ctx context.Context, slug string) (*ent.Post,
 
 // Posts возвращает посты в зависимости от их visibility.
 func (r *queryResolver) Posts(ctx context.Context, visibility *post.Visibility, communityID *string, authorID *string) ([]*ent.Post, error) {
-	q := r.Client.Post.Query()
-
-	// 1) фильтр по visibility
-	if visibility != nil {
-		q = q.Where(post.VisibilityEQ(*visibility))
-	}
-
-	// 2) фильтр по сообществу, если передан
-	if communityID != nil {
-		cid, err := strconv.Atoi(*communityID)
-		if err != nil {
-			return nil, fmt.Errorf("invalid communityID %q: %w", *communityID, err)
-		}
-		q = q.Where(post.CommunityIDEQ(cid))
-	}
-
-	// 3) фильтр по автору, если передан
-	if authorID != nil {
-		aid, err := strconv.Atoi(*authorID)
-		if err != nil {
-			return nil, fmt.Errorf("invalid authorID %q: %w", *authorID, err)
-		}
-		q = q.Where(post.AuthorIDEQ(aid))
+	q, err := r.postsQuery(visibility, communityID, authorID)
+	if err != nil {
+		return nil, err
 	}
-
-	// 4) подгружаем связи и отдаем результат
-	posts, err := q.
+	return q.
 		WithCommunity().
 		WithAuthor().
 		All(ctx)
+}
+
+// PostsConnection отдает посты постранично, новые сверху.
+func (r *queryResolver) PostsConnection(ctx context.Context, visibility *post.Visibility, communityID *string, authorID *string, first *int32, after *string, last *int32, before *string) (*models.PostsConnection, error) {
+	q, err := r.postsQuery(visibility, communityID, authorID)
 	if err != nil {
 		return nil, err
 	}
-	return posts, nil
+	return postsConnection(ctx, q, connectionArgs(ctx, first, after, last, before))
 }
 
 // BookmarkedPosts is the resolver for the bookmarkedPosts field.
 func (r *queryResolver) BookmarkedPosts(ctx context.Context, visibility *post.Visibility) ([]*ent.Post, error) {
-	// Получаем ID текущего пользователя
-	userID, err := auth.UserIDFromContext(ctx)
+	q, err := r.bookmarkedPostsQuery(ctx, visibility)
 	if err != nil {
-		return nil, fmt.Errorf("unauthorized: %w", err)
-	}
-
-	// Получаем посты в закладках пользователя
-	q := r.Client.Post.Query().
-		Where(
-			post.HasBookmarksWith(
-				bookmark.UserIDEQ(userID),
-			),
-		)
-
-	// Фильтр по visibility
-	if visibility != nil {
-		q = q.Where(post.VisibilityEQ(*visibility))
+		return nil, err
 	}
-
 	posts, err := q.
 		Order(ent.Desc(post.FieldCreatedAt)).
 		All(ctx)
-
 	if err != nil {
 		return nil, fmt.Errorf("failed to get bookmarked posts: %w", err)
 	}
-
 	return posts, nil
 }
 
-// FeedPosts is the resolver for the feedPosts field.
-func (r *queryResolver) FeedPosts(ctx context.Context, visibility *post.Visibility) ([]*ent.Post, error) {
-	// Получаем ID текущего пользователя
-	userID, err := auth.UserIDFromContext(ctx)
+// BookmarkedPostsConnection отдает закладки текущего пользователя постранично.
+func (r *queryResolver) BookmarkedPostsConnection(ctx context.Context, visibility *post.Visibility, first *int32, after *string, last *int32, before *string) (*models.PostsConnection, error) {
+	q, err := r.bookmarkedPostsQuery(ctx, visibility)
 	if err != nil {
-		return nil, fmt.Errorf("unauthorized: %w", err)
+		return nil, err
 	}
+	return postsConnection(ctx, q, connectionArgs(ctx, first, after, last, before))
+}
 
-	// Получаем посты от пользователей на которых подписан текущий пользователь
-	// И посты из сообществ на которые подписан пользователь
-	q := r.Client.Post.Query().
-		Where(
-			post.Or(
-				// Посты от пользователей на которых подписан
-				post.HasAuthorWith(
-					user.HasFollowersWith(
-						userfollow.FollowerIDEQ(userID),
-					),
-				),
-				// Посты из сообществ на которые подписан
-				post.HasCommunityWith(
-					community.HasFollowersWith(
-						communityfollow.UserIDEQ(userID),
-					),
-				),
-			),
-		)
-
-	// Фильтр по visibility
-	if visibility != nil {
-		q = q.Where(post.VisibilityEQ(*visibility))
+// FeedPosts is the resolver for the feedPosts field.
+func (r *queryResolver) FeedPosts(ctx context.Context, visibility *post.Visibility) ([]*ent.Post, error) {
+	q, err := r.feedPostsQuery(ctx, visibility)
+	if err != nil {
+		return nil, err
 	}
-
 	posts, err := q.
 		Order(ent.Desc(post.FieldCreatedAt)).
 		All(ctx)
-
 	if err != nil {
 		return nil, fmt.Errorf("failed to get feed posts: %w", err)
 	}
-
 	return posts, nil
 }
 
+// FeedPostsConnection отдает ленту подписок постранично, новые сверху.
+func (r *queryResolver) FeedPostsConnection(ctx context.Context, visibility *post.Visibility, first *int32, after *string, last *int32, before *string) (*models.PostsConnection, error) {
+	q, err := r.feedPostsQuery(ctx, visibility)
+	if err != nil {
+		return nil, err
+	}
+	return postsConnection(ctx, q, connectionArgs(ctx, first, after, last, before))
+}
+
 // Comments возвращает все не удаленные комментарии.
 func (r *queryResolver) Comments(ctx context.Context, hasDeleted *bool) ([]*ent.Comment, error) {
 	return r.CommentUC.GetAllComments(ctx, hasDeleted)
@@ -2494,11 +3288,7 @@ func (r *queryResolver) HostUserMutes(ctx context.Context) ([]*models.HostUserMu
 	// Конвертируем ent.HostUserMute в models.HostUserMute
 	var result []*models.HostUserMute
 	for _, mute := range mutes {
-		result = append(result, &models.HostUserMute{
-			ID:        strconv.Itoa(mute.ID),
-			CreatedAt: mute.CreatedAt,
-			UpdatedAt: mute.UpdatedAt,
-		})
+		result = append(result, hostUserMuteModel(mute))
 	}
 
 	return result, nil
@@ -2511,16 +3301,16 @@ func (r *queryResolver) HostUserMute(ctx context.Context, id string) (*models.Ho
 		return nil, err
 	}
 
-	mute, err := r.Client.HostUserMute.Get(ctx, muteID)
+	mute, err := r.Client.HostUserMute.Query().
+		Where(hostusermute.IDEQ(muteID)).
+		WithUser().
+		WithIssuer().
+		Only(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	return &models.HostUserMute{
-		ID:        strconv.Itoa(mute.ID),
-		CreatedAt: mute.CreatedAt,
-		UpdatedAt: mute.UpdatedAt,
-	}, nil
+	return hostUserMuteModel(mute), nil
 }
 
 // HostCommunityMutes возвращает все муты сообществ на платформе.
@@ -2533,12 +3323,7 @@ func (r *queryResolver) HostCommunityMutes(ctx context.Context) ([]*models.HostC
 	// Конвертируем ent.HostCommunityMute в models.HostCommunityMute
 	var result []*models.HostCommunityMute
 	for _, mute := range mutes {
-		result = append(result, &models.HostCommunityMute{
-			ID:          strconv.Itoa(mute.ID),
-			CommunityID: strconv.Itoa(mute.CommunityID),
-			CreatedAt:   mute.CreatedAt,
-			UpdatedAt:   mute.UpdatedAt,
-		})
+		result = append(result, hostCommunityMuteModel(mute))
 	}
 
 	return result, nil
@@ -2551,17 +3336,78 @@ func (r *queryResolver) HostCommunityMute(ctx context.Context, id string) (*mode
 		return nil, err
 	}
 
-	mute, err := r.Client.HostCommunityMute.Get(ctx, muteID)
+	mute, err := r.Client.HostCommunityMute.Query().
+		Where(hostcommunitymute.IDEQ(muteID)).
+		WithCommunity().
+		WithIssuer().
+		Only(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	return &models.HostCommunityMute{
-		ID:          strconv.Itoa(mute.ID),
-		CommunityID: strconv.Itoa(mute.CommunityID),
-		CreatedAt:   mute.CreatedAt,
-		UpdatedAt:   mute.UpdatedAt,
-	}, nil
+	return hostCommunityMuteModel(mute), nil
+}
+
+// HostIPBans is the resolver for the hostIPBans field.
+func (r *queryResolver) HostIPBans(ctx context.Context) ([]*ent.HostIPBan, error) {
+	// Проверяем права: только владелец платформы или пользователи с правом host.user.ban
+	currentUserID, err := auth.UserIDFromContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unauthenticated")
+	}
+
+	if err := r.require(ctx, currentUserID, authz.HostBanUser, authz.Host(), "insufficient permissions"); err != nil {
+		return nil, err
+	}
+
+	return r.HostIPBanUC.List(ctx)
+}
+
+// AssociatedAccounts is the resolver for the associatedAccounts field.
+func (r *queryResolver) AssociatedAccounts(ctx context.Context, userID string, days *int32) ([]*models.AssociatedAccount, error) {
+	// Адреса входа видит только персонал платформы с правом host.user.ban
+	currentUserID, err := auth.UserIDFromContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unauthenticated")
+	}
+
+	if err := r.require(ctx, currentUserID, authz.HostBanUser, authz.Host(), "insufficient permissions"); err != nil {
+		return nil, err
+	}
+	if r.UserSessionUC == nil {
+		return nil, fmt.Errorf("associated accounts are disabled")
+	}
+
+	id, err := strconv.Atoi(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid userID: %w", err)
+	}
+	// Входы старше срока хранения уже удалены
+	window := usersessionuc.Retention
+	if days != nil && *days > 0 && time.Duration(*days)*24*time.Hour < window {
+		window = time.Duration(*days) * 24 * time.Hour
+	}
+
+	return r.UserSessionUC.AssociatedAccounts(ctx, id, time.Now().Add(-window))
+}
+
+// PermissionDefinitions отдает реестр прав authz для редактора ролей.
+func (r *queryResolver) PermissionDefinitions(ctx context.Context, scope *models.PermissionScope) ([]*models.PermissionDefinition, error) {
+	var s authz.Scope
+	if scope != nil {
+		s = authz.Scope(strings.ToLower(string(*scope)))
+	}
+	defs := authz.Definitions(s)
+	result := make([]*models.PermissionDefinition, 0, len(defs))
+	for _, d := range defs {
+		result = append(result, &models.PermissionDefinition{
+			Key:         string(d.Key),
+			Description: d.Description,
+			Scope:       models.PermissionScope(strings.ToUpper(string(d.Scope))),
+			Dangerous:   d.Dangerous,
+		})
+	}
+	return result, nil
 }
 
 // CommunityRoles is the resolver for the communityRoles field.
@@ -2569,56 +3415,29 @@ func (r *queryResolver) CommunityRoles(ctx context.Context, communityID string)
 	// Проверяем права: владелец сообщества или менеджер ролей
 	currentUserID, err := auth.UserIDFromContext(ctx)
 	if err != nil {
-		fmt.Printf("❌ CommunityRoles: unauthenticated - %v\n", err)
 		return nil, fmt.Errorf("unauthenticated")
 	}
 
-	fmt.Printf("🔍 CommunityRoles: userID=%d, communityID=%s\n", currentUserID, communityID)
-
 	cid, err := strconv.Atoi(communityID)
 	if err != nil {
-		fmt.Printf("❌ CommunityRoles: invalid communityID - %v\n", err)
 		return nil, fmt.Errorf("invalid communityID: %w", err)
 	}
 
 	// Проверяем существование сообщества
-	cm, err := r.Client.Community.Get(ctx, cid)
-	if err != nil {
-		fmt.Printf("❌ CommunityRoles: community not found - %v\n", err)
+	if _, err := r.Client.Community.Get(ctx, cid); err != nil {
 		return nil, fmt.Errorf("community not found: %w", err)
 	}
 
-	fmt.Printf("✅ CommunityRoles: found community ID=%d, OwnerID=%d\n", cm.ID, cm.OwnerID)
-
 	// Проверяем владельца/права
-	if cm.OwnerID != currentUserID {
-		fmt.Printf("🔍 CommunityRoles: user is not owner, checking permissions...\n")
-		permsMap, err := r.UserUC.GetPermissionsByCommunities(ctx, currentUserID, []int{cid})
-		if err != nil {
-			fmt.Printf("❌ CommunityRoles: failed to get permissions - %v\n", err)
-			return nil, fmt.Errorf("permissions: %w", err)
-		}
-		perms := permsMap[cid]
-		if perms == nil {
-			fmt.Printf("❌ CommunityRoles: no permissions found for user\n")
-			return nil, fmt.Errorf("forbidden: no permissions found")
-		}
-		if !perms.CommunityRolesManagement {
-			fmt.Printf("❌ CommunityRoles: user lacks CommunityRolesManagement permission\n")
-			return nil, fmt.Errorf("forbidden: only owner or role manager can view roles")
-		}
-		fmt.Printf("✅ CommunityRoles: user has CommunityRolesManagement permission\n")
-	} else {
-		fmt.Printf("✅ CommunityRoles: user is owner\n")
+	if err := r.require(ctx, currentUserID, authz.ManageRoles, authz.Community(cid), "only owner or role manager can view roles"); err != nil {
+		return nil, err
 	}
 
 	roles, err := r.CommunityRoleUC.GetCommunityRoles(ctx, cid)
 	if err != nil {
-		fmt.Printf("❌ CommunityRoles: failed to get roles - %v\n", err)
 		return nil, err
 	}
 
-	fmt.Printf("✅ CommunityRoles: found %d roles\n", len(roles))
 	return roles, nil
 }
 
@@ -2642,60 +3461,25 @@ func (r *queryResolver) CommunityRole(ctx context.Context, id string) (*ent.Role
 	}
 
 	// Проверяем владельца/права
-	cm, err := r.Client.Community.Get(ctx, role.CommunityID)
-	if err != nil {
+	if err := r.require(ctx, currentUserID, authz.ManageRoles, authz.Community(role.CommunityID), "only owner or role manager can view role"); err != nil {
 		return nil, err
 	}
-	if cm.OwnerID != currentUserID {
-		permsMap, err := r.UserUC.GetPermissionsByCommunities(ctx, currentUserID, []int{role.CommunityID})
-		if err != nil {
-			return nil, fmt.Errorf("permissions: %w", err)
-		}
-		perms := permsMap[role.CommunityID]
-		if perms == nil || !perms.CommunityRolesManagement {
-			return nil, fmt.Errorf("forbidden: only owner or role manager can view role")
-		}
-	}
 
 	return r.CommunityRoleUC.GetCommunityRole(ctx, roleID)
 }
 
 // HostCommunityBans is the resolver for the hostCommunityBans field.
 func (r *queryResolver) HostCommunityBans(ctx context.Context) ([]*models.HostCommunityBan, error) {
-	// Проверяем права: только владелец платформы или пользователи с hostCommunityDeletePost
+	// Проверяем права: только владелец платформы или пользователи с правом host.community.ban
 	currentUserID, err := auth.UserIDFromContext(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("unauthenticated")
 	}
 
-	hostEntity, err := r.Client.Host.Get(ctx, 1)
-	if err != nil {
+	if err := r.require(ctx, currentUserID, authz.HostBanCommunity, authz.Host(), "insufficient permissions"); err != nil {
 		return nil, err
 	}
 
-	// Проверяем владельца или роли
-	if hostEntity.OwnerID == nil || *hostEntity.OwnerID != currentUserID {
-		// Проверяем роли пользователя
-		roles, err := r.Client.User.Query().
-			Where(user.IDEQ(currentUserID)).
-			QueryHostRoles().
-			All(ctx)
-		if err != nil {
-			return nil, err
-		}
-
-		hasPermission := false
-		for _, role := range roles {
-			if role.HostCommunityDeletePost {
-				hasPermission = true
-				break
-			}
-		}
-		if !hasPermission {
-			return nil, fmt.Errorf("forbidden: insufficient permissions")
-		}
-	}
-
 	bans, err := r.BanUC.GetHostCommunityBans(ctx)
 	if err != nil {
 		return nil, err
@@ -2704,12 +3488,7 @@ func (r *queryResolver) HostCommunityBans(ctx context.Context) ([]*models.HostCo
 	// Маппинг ent -> models
 	result := make([]*models.HostCommunityBan, len(bans))
 	for i, ban := range bans {
-		result[i] = &models.HostCommunityBan{
-			ID:          strconv.Itoa(ban.ID),
-			CommunityID: strconv.Itoa(ban.CommunityID),
-			CreatedAt:   ban.CreatedAt,
-			UpdatedAt:   ban.UpdatedAt,
-		}
+		result[i] = hostCommunityBanModel(ban)
 	}
 
 	return result, nil
@@ -2717,62 +3496,37 @@ func (r *queryResolver) HostCommunityBans(ctx context.Context) ([]*models.HostCo
 
 // HostCommunityBan is the resolver for the hostCommunityBan field.
 func (r *queryResolver) HostCommunityBan(ctx context.Context, id string) (*models.HostCommunityBan, error) {
-	// Проверяем права: только владелец платформы или пользователи с hostCommunityDeletePost
+	// Проверяем права: только владелец платформы или пользователи с правом host.community.ban
 	currentUserID, err := auth.UserIDFromContext(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("unauthenticated")
 	}
 
-	hostEntity, err := r.Client.Host.Get(ctx, 1)
-	if err != nil {
+	if err := r.require(ctx, currentUserID, authz.HostBanCommunity, authz.Host(), "insufficient permissions"); err != nil {
 		return nil, err
 	}
 
-	// Проверяем владельца или роли
-	if hostEntity.OwnerID == nil || *hostEntity.OwnerID != currentUserID {
-		// Проверяем роли пользователя
-		roles, err := r.Client.User.Query().
-			Where(user.IDEQ(currentUserID)).
-			QueryHostRoles().
-			All(ctx)
-		if err != nil {
-			return nil, err
-		}
-
-		hasPermission := false
-		for _, role := range roles {
-			if role.HostCommunityDeletePost {
-				hasPermission = true
-				break
-			}
-		}
-		if !hasPermission {
-			return nil, fmt.Errorf("forbidden: insufficient permissions")
-		}
-	}
-
 	banID, err := strconv.Atoi(id)
 	if err != nil {
 		return nil, fmt.Errorf("invalid banID: %w", err)
 	}
 
-	ban, err := r.Client.HostCommunityBan.Get(ctx, banID)
+	ban, err := r.Client.HostCommunityBan.Query().
+		Where(hostcommunityban.IDEQ(banID)).
+		WithCommunity().
+		WithIssuer().
+		Only(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	// Маппинг ent -> models
-	return &models.HostCommunityBan{
-		ID:          strconv.Itoa(ban.ID),
-		CommunityID: strconv.Itoa(ban.CommunityID),
-		CreatedAt:   ban.CreatedAt,
-		UpdatedAt:   ban.UpdatedAt,
-	}, nil
+	return hostCommunityBanModel(ban), nil
 }
 
 // CommunityUserBans is the resolver for the communityUserBans field.
 func (r *queryResolver) CommunityUserBans(ctx context.Context, communityID string) ([]*ent.CommunityUserBan, error) {
-	// Проверяем права: владелец сообщества или пользователи с communityUserBan
+	// Проверяем права: владелец сообщества или пользователи с правом community.user.ban
 	currentUserID, err := auth.UserIDFromContext(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("unauthenticated")
@@ -2784,27 +3538,16 @@ func (r *queryResolver) CommunityUserBans(ctx context.Context, communityID strin
 	}
 
 	// Проверяем владельца/права
-	cm, err := r.Client.Community.Get(ctx, cid)
-	if err != nil {
+	if err := r.require(ctx, currentUserID, authz.BanUser, authz.Community(cid), "only owner or users with ban permission can view bans"); err != nil {
 		return nil, err
 	}
-	if cm.OwnerID != currentUserID {
-		permsMap, err := r.UserUC.GetPermissionsByCommunities(ctx, currentUserID, []int{cid})
-		if err != nil {
-			return nil, fmt.Errorf("permissions: %w", err)
-		}
-		perms := permsMap[cid]
-		if perms == nil || !perms.CommunityUserBan {
-			return nil, fmt.Errorf("forbidden: only owner or users with ban permission can view bans")
-		}
-	}
 
 	return r.BanUC.GetCommunityUserBans(ctx, cid)
 }
 
 // CommunityUserMutes is the resolver for the communityUserMutes field.
 func (r *queryResolver) CommunityUserMutes(ctx context.Context, communityID string) ([]*ent.CommunityUserMute, error) {
-	// Проверяем права: владелец сообщества или пользователи с communityUserMute
+	// Проверяем права: владелец сообщества или пользователи с правом community.user.mute
 	currentUserID, err := auth.UserIDFromContext(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("unauthenticated")
@@ -2816,22 +3559,34 @@ func (r *queryResolver) CommunityUserMutes(ctx context.Context, communityID stri
 	}
 
 	// Проверяем владельца/права
-	cm, err := r.Client.Community.Get(ctx, cid)
+	if err := r.require(ctx, currentUserID, authz.MuteUser, authz.Community(cid), "only owner or users with mute permission can view mutes"); err != nil {
+		return nil, err
+	}
+
+	return r.BanUC.GetCommunityUserMutes(ctx, cid)
+}
+
+// MySanctions отдает действующие баны и муты текущего пользователя.
+func (r *queryResolver) MySanctions(ctx context.Context) ([]*models.Sanction, error) {
+	currentUserID, err := auth.UserIDFromContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unauthenticated")
+	}
+
+	sanctions, err := r.BanUC.GetUserSanctions(ctx, currentUserID)
 	if err != nil {
 		return nil, err
 	}
-	if cm.OwnerID != currentUserID {
-		permsMap, err := r.UserUC.GetPermissionsByCommunities(ctx, currentUserID, []int{cid})
+
+	result := make([]*models.Sanction, 0, len(sanctions))
+	for _, s := range sanctions {
+		m, err := r.sanctionModel(ctx, s)
 		if err != nil {
-			return nil, fmt.Errorf("permissions: %w", err)
-		}
-		perms := permsMap[cid]
-		if perms == nil || !perms.CommunityUserMute {
-			return nil, fmt.Errorf("forbidden: only owner or users with mute permission can view mutes")
+			return nil, err
 		}
+		result = append(result, m)
 	}
-
-	return r.BanUC.GetCommunityUserMutes(ctx, cid)
+	return result, nil
 }
 
 // UsersForRole is the resolver for the usersForRole field.
@@ -2854,20 +3609,9 @@ func (r *queryResolver) UsersForRole(ctx context.Context, roleID string, search
 	}
 
 	// Проверяем владельца/права
-	cm, err := r.Client.Community.Get(ctx, role.CommunityID)
-	if err != nil {
+	if err := r.require(ctx, currentUserID, authz.ManageRoles, authz.Community(role.CommunityID), "only owner or role manager can view users for role"); err != nil {
 		return nil, err
 	}
-	if cm.OwnerID != currentUserID {
-		permsMap, err := r.UserUC.GetPermissionsByCommunities(ctx, currentUserID, []int{role.CommunityID})
-		if err != nil {
-			return nil, fmt.Errorf("permissions: %w", err)
-		}
-		perms := permsMap[role.CommunityID]
-		if perms == nil || !perms.CommunityRolesManagement {
-			return nil, fmt.Errorf("forbidden: only owner or role manager can view users for role")
-		}
-	}
 
 	// Получаем пользователей роли
 	roleEntity, err := r.Client.Role.Get(ctx, rid)
@@ -2896,197 +3640,156 @@ func (r *queryResolver) UsersForRole(ctx context.Context, roleID string, search
 
 // CommunityUsers is the resolver for the communityUsers field.
 func (r *queryResolver) CommunityUsers(ctx context.Context, communityID string) ([]*ent.User, error) {
-	// Проверяем права: владелец сообщества или менеджер ролей
-	currentUserID, err := auth.UserIDFromContext(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("unauthenticated")
-	}
-
-	cid, err := strconv.Atoi(communityID)
-	if err != nil {
-		return nil, fmt.Errorf("invalid communityID: %w", err)
-	}
-
-	// Проверяем владельца/права
-	cm, err := r.Client.Community.Get(ctx, cid)
+	q, err := r.communityUsersQuery(ctx, communityID)
 	if err != nil {
 		return nil, err
 	}
-	if cm.OwnerID != currentUserID {
-		permsMap, err := r.UserUC.GetPermissionsByCommunities(ctx, currentUserID, []int{cid})
-		if err != nil {
-			return nil, fmt.Errorf("permissions: %w", err)
-		}
-		perms := permsMap[cid]
-		if perms == nil || !perms.CommunityRolesManagement {
-			return nil, fmt.Errorf("forbidden: only owner or role manager can view community users")
-		}
-	}
+	return q.All(ctx)
+}
 
-	// Получаем пользователей сообщества через роли
-	users, err := r.Client.Role.Query().
-		Where(role.CommunityIDEQ(cid)).
-		QueryUsers().
-		All(ctx)
+// CommunityUsersConnection отдает пользователей с ролями в сообществе постранично.
+func (r *queryResolver) CommunityUsersConnection(ctx context.Context, communityID string, first *int32, after *string, last *int32, before *string) (*models.UsersConnection, error) {
+	q, err := r.communityUsersQuery(ctx, communityID)
 	if err != nil {
 		return nil, err
 	}
-
-	return users, nil
+	return usersConnection(ctx, q, connectionArgs(ctx, first, after, last, before))
 }
 
 // CommunityFollowers возвращает подписчиков сообщества с фильтрацией
 func (r *queryResolver) CommunityFollowers(ctx context.Context, communityID string, filter *models.CommunityFollowersFilter, limit *int32, offset *int32) ([]*ent.User, error) {
-	// Проверяем авторизацию
-	currentUserID, err := auth.UserIDFromContext(ctx)
+	q, err := r.communityFollowersQuery(ctx, communityID, filter)
 	if err != nil {
-		return nil, fmt.Errorf("unauthenticated")
-	}
-
-	cid, err := strconv.Atoi(communityID)
-	if err != nil {
-		return nil, fmt.Errorf("invalid communityID: %w", err)
-	}
-
-	// Проверяем права: владелец сообщества или менеджер ролей
-	cm, err := r.Client.Community.Get(ctx, cid)
-	if err != nil {
-		return nil, fmt.Errorf("community not found: %w", err)
-	}
-
-	if cm.OwnerID != currentUserID {
-		permsMap, err := r.UserUC.GetPermissionsByCommunities(ctx, currentUserID, []int{cid})
-		if err != nil {
-			return nil, fmt.Errorf("permissions: %w", err)
-		}
-		perms := permsMap[cid]
-		if perms == nil || !perms.CommunityRolesManagement {
-			return nil, fmt.Errorf("forbidden: only owner or role manager can view followers")
-		}
-	}
-
-	// Устанавливаем значения по умолчанию
-	filterValue := models.CommunityFollowersFilterAll
-	if filter != nil {
-		filterValue = *filter
+		return nil, err
 	}
 
 	limitValue := 50
 	if limit != nil {
 		limitValue = int(*limit)
 	}
-
 	offsetValue := 0
 	if offset != nil {
 		offsetValue = int(*offset)
 	}
-
-	// Базовый запрос подписчиков через community_follows
-	query := r.Client.User.Query().
-		Where(
-			user.HasCommunitiesFollowWith(
-				communityfollow.CommunityIDEQ(cid),
-			),
-		).
+	return q.
 		Limit(limitValue).
 		Offset(offsetValue).
-		Order(ent.Asc("id"))
-
-	// Применяем фильтры
-	switch filterValue {
-	case models.CommunityFollowersFilterBanned:
-		// Только забаненные пользователи
-		query = query.Where(
-			user.HasCommunitiesBansWith(
-				communityuserban.CommunityIDEQ(cid),
-			),
-		)
-
-	case models.CommunityFollowersFilterMuted:
-		// Только замученные пользователи
-		query = query.Where(
-			user.HasCommunitiesMutesWith(
-				communityusermute.CommunityIDEQ(cid),
-			),
-		)
-
-	case models.CommunityFollowersFilterActive:
-		// Только активные пользователи (не забаненные и не замученные)
-		query = query.Where(
-			user.Not(
-				user.HasCommunitiesBansWith(
-					communityuserban.CommunityIDEQ(cid),
-				),
-			),
-			user.Not(
-				user.HasCommunitiesMutesWith(
-					communityusermute.CommunityIDEQ(cid),
-				),
-			),
-		)
-
-	case models.CommunityFollowersFilterAll:
-		// Все подписчики (без дополнительных фильтров)
-		// Уже настроено выше
-	}
-
-	return query.All(ctx)
+		Order(ent.Asc("id")).
+		All(ctx)
+}
+
+// CommunityFollowersConnection отдает подписчиков сообщества постранично.
+func (r *queryResolver) CommunityFollowersConnection(ctx context.Context, communityID string, filter *models.CommunityFollowersFilter, first *int32, after *string, last *int32, before *string) (*models.UsersConnection, error) {
+	q, err := r.communityFollowersQuery(ctx, communityID, filter)
+	if err != nil {
+		return nil, err
+	}
+	return usersConnection(ctx, q, connectionArgs(ctx, first, after, last, before))
 }
 
 // CommentAdded подписка на новые комментарии.
-func (r *subscriptionResolver) CommentAdded(ctx context.Context, postID string) (<-chan *ent.Comment, error) {
+func (r *subscriptionResolver) CommentAdded(ctx context.Context, postID string, since *string) (<-chan *ent.Comment, error) {
 	pid, err := strconv.Atoi(postID)
 	if err != nil {
 		return nil, fmt.Errorf("invalid postID: %w", err)
 	}
-	subID, ch := subscribeCommentAdded(pid)
-
-	// отписка при закрытии клиента
-	go func() {
-		<-ctx.Done()
-		unsubscribeCommentAdded(pid, subID)
-	}()
-
-	return ch, nil
+	// отписка произойдёт автоматически при закрытии клиента (отмене ctx)
+	return subscribe(ctx, r.Broker, postTopic(topicCommentAdded, pid), since, r.loadVisibleComment)
 }
 
 // CommentUpdated подписка на обновления комментариев.
-func (r *subscriptionResolver) CommentUpdated(ctx context.Context, postID string) (<-chan *ent.Comment, error) {
+func (r *subscriptionResolver) CommentUpdated(ctx context.Context, postID string, since *string) (<-chan *ent.Comment, error) {
 	pid, err := strconv.Atoi(postID)
 	if err != nil {
 		return nil, fmt.Errorf("invalid postID: %w", err)
 	}
-	subID, ch := subscribeCommentUpdated(pid)
-
-	// отписка при закрытии клиента
-	go func() {
-		<-ctx.Done()
-		unsubscribeCommentUpdated(pid, subID)
-	}()
-
-	return ch, nil
+	return subscribe(ctx, r.Broker, postTopic(topicCommentUpdated, pid), since, r.loadVisibleComment)
 }
 
 // CommentAddedGlobal — глобальная подписка для общей ленты (только опубликованные посты)
-func (r *subscriptionResolver) CommentAddedGlobal(ctx context.Context) (<-chan *ent.Comment, error) {
-	subID, ch := subscribeCommentAddedGlobal()
-	// отписка при закрытии клиента
-	go func() {
-		<-ctx.Done()
-		unsubscribeCommentAddedGlobal(subID)
-	}()
-	return ch, nil
+func (r *subscriptionResolver) CommentAddedGlobal(ctx context.Context, since *string) (<-chan *ent.Comment, error) {
+	return subscribe(ctx, r.Broker, topicCommentAdded, since, r.loadVisibleComment)
 }
 
 // CommentUpdatedGlobal — глобальная подписка на обновления комментариев для общей ленты
-func (r *subscriptionResolver) CommentUpdatedGlobal(ctx context.Context) (<-chan *ent.Comment, error) {
-	subID, ch := subscribeCommentUpdatedGlobal()
-	// отписка при закрытии клиента
-	go func() {
-		<-ctx.Done()
-		unsubscribeCommentUpdatedGlobal(subID)
-	}()
-	return ch, nil
+func (r *subscriptionResolver) CommentUpdatedGlobal(ctx context.Context, since *string) (<-chan *ent.Comment, error) {
+	return subscribe(ctx, r.Broker, topicCommentUpdated, since, r.loadVisibleComment)
+}
+
+// NotificationAdded подписка на новые уведомления текущего пользователя.
+func (r *subscriptionResolver) NotificationAdded(ctx context.Context, since *string) (<-chan *ent.Notification, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil || userID == 0 {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	return subscribe(ctx, r.Broker, userTopic(topicNotificationAdded, userID), since, r.loadOwnNotification)
+}
+
+// PostPublished подписка на новые опубликованные посты сообщества.
+func (r *subscriptionResolver) PostPublished(ctx context.Context, communityID string, since *string) (<-chan *ent.Post, error) {
+	cid, err := strconv.Atoi(communityID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid communityID: %w", err)
+	}
+	return subscribe(ctx, r.Broker, communityTopic(topicPostPublished, cid), since, r.loadPublishedPost)
+}
+
+// PostStatsChanged подписка на изменения счётчиков поста.
+func (r *subscriptionResolver) PostStatsChanged(ctx context.Context, postID string, since *string) (<-chan *models.PostStats, error) {
+	pid, err := strconv.Atoi(postID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid postID: %w", err)
+	}
+	return subscribeEvents(ctx, r.Broker, postTopic(topicPostStats, pid), since, loadPostStats)
+}
+
+// UserStatsChanged подписка на изменения числа подписчиков и подписок пользователя.
+func (r *subscriptionResolver) UserStatsChanged(ctx context.Context, userID string, since *string) (<-chan *models.UserStats, error) {
+	uid, err := strconv.Atoi(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid userID: %w", err)
+	}
+	return subscribeEvents(ctx, r.Broker, userTopic(topicUserStats, uid), since, func(_ context.Context, ev models.UserStats) (*models.UserStats, bool) {
+		return &ev, ev.UserID != ""
+	})
+}
+
+// ModerationEvent подписка на события модерации сообщества или платформы (только для модераторов).
+func (r *subscriptionResolver) ModerationEvent(ctx context.Context, communityID *string, since *string) (<-chan *models.ModerationEvent, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil || userID == 0 {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	var cid *int
+	if communityID != nil {
+		id, err := strconv.Atoi(*communityID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid communityID: %w", err)
+		}
+		cid = &id
+	}
+	// Права проверяются при подписке и повторно для каждого события: их могут отозвать во время подписки
+	ok, err := r.canSeeModerationEvents(ctx, userID, cid, "")
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("forbidden: insufficient permissions")
+	}
+	return subscribeEvents(ctx, r.Broker, moderationTopic(cid), since, r.loadModerationEvent)
+}
+
+// ThreadPresence подписка на присутствие в обсуждении поста; сама подписка служит heartbeat зрителя.
+func (r *subscriptionResolver) ThreadPresence(ctx context.Context, postID string) (<-chan *models.ThreadPresence, error) {
+	p, viewerID, err := r.threadPost(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+	states, err := r.Presence.Watch(ctx, p.ID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	return r.threadPresence(ctx, p.ID, states), nil
 }
 
 // UserStatus возвращает статус пользователя.
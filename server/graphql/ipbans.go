@@ -0,0 +1,52 @@
+package graphql
+
+import (
+	"context"
+	"log"
+	"strconv"
+
+	"github.com/99designs/gqlgen/graphql"
+
+	"stormlink/server/ipban"
+	httpWithCookies "stormlink/shared/http"
+)
+
+// ipBanChecker — проверка банов по IP; резолвер без IPBans (тесты) проверяет по базе
+func (r *Resolver) ipBanChecker() *ipban.Checker {
+	if r.IPBans != nil {
+		return r.IPBans
+	}
+	return ipban.New(ipban.NewEntStore(r.Client))
+}
+
+// checkIPBan отклоняет действие с забаненного IP клиента тем же отказом, что и ipban.Guard;
+// без HTTP-запроса в ctx (gRPC, тесты) не проверяет
+func (r *Resolver) checkIPBan(ctx context.Context) error {
+	req := httpWithCookies.GetHTTPRequest(ctx)
+	if req == nil {
+		return nil
+	}
+	ban, err := r.ipBanChecker().Lookup(ctx, httpWithCookies.ClientIP(req))
+	if err != nil || ban == nil {
+		return err
+	}
+	e := ipban.Denied(ban)
+	e.Path = graphql.GetPath(ctx)
+	return e
+}
+
+// recordSession запоминает вход для связанных аккаунтов, если учет входов включен;
+// ошибка учета вход не срывает
+func (r *Resolver) recordSession(ctx context.Context, userID string) {
+	if r.UserSessionUC == nil {
+		return
+	}
+	req := httpWithCookies.GetHTTPRequest(ctx)
+	id, err := strconv.Atoi(userID)
+	if req == nil || err != nil {
+		return
+	}
+	if err := r.UserSessionUC.Record(ctx, id, httpWithCookies.ClientIP(req), req.UserAgent()); err != nil {
+		log.Printf("⚠️ [LoginUser] %v", err)
+	}
+}
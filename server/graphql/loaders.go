@@ -0,0 +1,252 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"stormlink/server/ent"
+	"stormlink/server/graphql/dataloader"
+	"stormlink/server/graphql/models"
+	"stormlink/shared/auth"
+)
+
+// loaders отдает загрузчики операции; вне query (мутации, подписки) — без пакетирования и кеша
+func (r *Resolver) loaders(ctx context.Context) *dataloader.Loaders {
+	if l := dataloader.FromContext(ctx); l != nil {
+		return l
+	}
+	viewerID, _ := auth.UserIDFromContext(ctx)
+	return dataloader.New(r.Client, viewerID, 0)
+}
+
+// loadEdge отдает обязательную связь: уже подгруженную (With...) или через загрузчик
+func loadEdge[T any](ctx context.Context, loaded *T, l *dataloader.Loader[int, *T], id int, name string) (*T, error) {
+	if loaded != nil {
+		return loaded, nil
+	}
+	v, err := l.Load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, fmt.Errorf("%s %d not found", name, id)
+	}
+	return v, nil
+}
+
+// loadOptionalEdge отдает необязательную связь (nil, если ID не задан)
+func loadOptionalEdge[T any](ctx context.Context, loaded *T, l *dataloader.Loader[int, *T], id *int) (*T, error) {
+	if loaded != nil || id == nil {
+		return loaded, nil
+	}
+	return l.Load(ctx, *id)
+}
+
+// postStatus собирает статус поста из пакетных загрузчиков
+func (r *Resolver) postStatus(ctx context.Context, p *ent.Post) (*models.PostStatus, error) {
+	l := r.loaders(ctx)
+	counts, err := l.PostCounts.Load(ctx, p.ID)
+	if err != nil {
+		return nil, fmt.Errorf("post counts: %w", err)
+	}
+	viewer, err := l.PostViewer.Load(ctx, p.ID)
+	if err != nil {
+		return nil, fmt.Errorf("post viewer flags: %w", err)
+	}
+	cm, err := loadEdge(ctx, p.Edges.Community, l.Community, p.CommunityID, "community")
+	if err != nil {
+		return nil, err
+	}
+	hostOwner := l.HostOwnerID(ctx)
+	return &models.PostStatus{
+		LikesCount:           strconv.Itoa(counts.Likes),
+		CommentsCount:        strconv.Itoa(counts.Comments),
+		BookmarksCount:       strconv.Itoa(counts.Bookmarks),
+		IsLiked:              viewer.IsLiked,
+		HasBookmark:          viewer.HasBookmark,
+		AuthorCommunityOwner: cm.OwnerID == p.AuthorID,
+		AuthorHostOwner:      hostOwner != 0 && hostOwner == p.AuthorID,
+	}, nil
+}
+
+// commentStatus собирает статус комментария из пакетных загрузчиков
+func (r *Resolver) commentStatus(ctx context.Context, c *ent.Comment) (*models.CommentStatus, error) {
+	l := r.loaders(ctx)
+	likes, err := l.CommentLikesCount.Load(ctx, c.ID)
+	if err != nil {
+		return nil, fmt.Errorf("comment likes: %w", err)
+	}
+	liked, err := l.CommentLiked.Load(ctx, c.ID)
+	if err != nil {
+		return nil, fmt.Errorf("comment viewer flags: %w", err)
+	}
+	cm, err := loadEdge(ctx, c.Edges.Community, l.Community, c.CommunityID, "community")
+	if err != nil {
+		return nil, err
+	}
+	hostOwner := l.HostOwnerID(ctx)
+	return &models.CommentStatus{
+		LikesCount:           strconv.Itoa(likes),
+		IsLiked:              liked,
+		AuthorCommunityOwner: cm.OwnerID == c.AuthorID,
+		AuthorHostOwner:      hostOwner != 0 && hostOwner == c.AuthorID,
+	}, nil
+}
+
+// loadUsers подгружает пользователей строк списка одним пакетом
+func loadUsers[T any](ctx context.Context, l *dataloader.Loaders, rows []T, userID func(T) int) ([]*ent.User, error) {
+	ids := make([]int, len(rows))
+	for i, row := range rows {
+		ids[i] = userID(row)
+	}
+	return l.User.LoadAll(ctx, ids)
+}
+
+func (r *Resolver) postLikes(ctx context.Context, p *ent.Post) ([]*models.PostLike, error) {
+	l := r.loaders(ctx)
+	likes, err := l.PostLikes.Load(ctx, p.ID)
+	if err != nil {
+		return nil, err
+	}
+	users, err := loadUsers(ctx, l, likes, func(v *ent.PostLike) int { return v.UserID })
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*models.PostLike, 0, len(likes))
+	for i, v := range likes {
+		if users[i] == nil {
+			continue
+		}
+		out = append(out, &models.PostLike{
+			ID:        strconv.Itoa(v.ID),
+			UserID:    strconv.Itoa(v.UserID),
+			PostID:    strconv.Itoa(v.PostID),
+			CreatedAt: v.CreatedAt,
+			UpdatedAt: v.UpdatedAt,
+			User:      users[i],
+			Post:      p,
+		})
+	}
+	return out, nil
+}
+
+// postBookmarks отдает только закладку текущего пользователя: чужие закладки приватны
+func (r *Resolver) postBookmarks(ctx context.Context, p *ent.Post) ([]*models.Bookmark, error) {
+	l := r.loaders(ctx)
+	if l.ViewerID() == 0 {
+		return []*models.Bookmark{}, nil
+	}
+	bookmarks, err := l.PostBookmarks.Load(ctx, p.ID)
+	if err != nil {
+		return nil, err
+	}
+	out := []*models.Bookmark{}
+	for _, v := range bookmarks {
+		if v.UserID != l.ViewerID() {
+			continue
+		}
+		u, err := l.User.Load(ctx, v.UserID)
+		if err != nil || u == nil {
+			return nil, fmt.Errorf("bookmark user: %w", err)
+		}
+		out = append(out, &models.Bookmark{
+			ID:        strconv.Itoa(v.ID),
+			UserID:    strconv.Itoa(v.UserID),
+			PostID:    strconv.Itoa(v.PostID),
+			CreatedAt: v.CreatedAt,
+			UpdatedAt: v.UpdatedAt,
+			User:      u,
+			Post:      p,
+		})
+	}
+	return out, nil
+}
+
+func (r *Resolver) commentLikes(ctx context.Context, c *ent.Comment) ([]*models.CommentLike, error) {
+	l := r.loaders(ctx)
+	likes, err := l.CommentLikes.Load(ctx, c.ID)
+	if err != nil {
+		return nil, err
+	}
+	users, err := loadUsers(ctx, l, likes, func(v *ent.CommentLike) int { return v.UserID })
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*models.CommentLike, 0, len(likes))
+	for i, v := range likes {
+		if users[i] == nil {
+			continue
+		}
+		out = append(out, &models.CommentLike{
+			ID:        strconv.Itoa(v.ID),
+			UserID:    strconv.Itoa(v.UserID),
+			CommentID: strconv.Itoa(v.CommentID),
+			CreatedAt: v.CreatedAt,
+			UpdatedAt: v.UpdatedAt,
+			User:      users[i],
+			Comment:   c,
+		})
+	}
+	return out, nil
+}
+
+// userFollows превращает подписки в модели; self — пользователь, чей список запрошен
+func (r *Resolver) userFollows(ctx context.Context, self *ent.User, follows []*ent.UserFollow, followers bool) ([]*models.UserFollow, error) {
+	other := func(v *ent.UserFollow) int { return v.FolloweeID }
+	if followers {
+		other = func(v *ent.UserFollow) int { return v.FollowerID }
+	}
+	users, err := loadUsers(ctx, r.loaders(ctx), follows, other)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*models.UserFollow, 0, len(follows))
+	for i, v := range follows {
+		if users[i] == nil {
+			continue
+		}
+		f := &models.UserFollow{
+			ID:         strconv.Itoa(v.ID),
+			FollowerID: strconv.Itoa(v.FollowerID),
+			FolloweeID: strconv.Itoa(v.FolloweeID),
+			CreatedAt:  v.CreatedAt,
+			UpdatedAt:  v.UpdatedAt,
+			Follower:   self,
+			Followee:   users[i],
+		}
+		if followers {
+			f.Follower, f.Followee = users[i], self
+		}
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+func (r *Resolver) communityFollowers(ctx context.Context, cm *ent.Community) ([]*models.CommunityFollow, error) {
+	l := r.loaders(ctx)
+	follows, err := l.CommunityFollowers.Load(ctx, cm.ID)
+	if err != nil {
+		return nil, err
+	}
+	users, err := loadUsers(ctx, l, follows, func(v *ent.CommunityFollow) int { return v.UserID })
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*models.CommunityFollow, 0, len(follows))
+	for i, v := range follows {
+		if users[i] == nil {
+			continue
+		}
+		out = append(out, &models.CommunityFollow{
+			ID:          strconv.Itoa(v.ID),
+			UserID:      strconv.Itoa(v.UserID),
+			CommunityID: strconv.Itoa(v.CommunityID),
+			CreatedAt:   v.CreatedAt,
+			UpdatedAt:   v.UpdatedAt,
+			User:        users[i],
+			Community:   cm,
+		})
+	}
+	return out, nil
+}
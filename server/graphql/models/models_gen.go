@@ -7,24 +7,371 @@ import (
 	"fmt"
 	"io"
 	"stormlink/server/ent"
+	"stormlink/server/ent/automodrule"
+	"stormlink/server/ent/banappeal"
+	"stormlink/server/ent/moderationaction"
+	"stormlink/server/ent/notification"
+	"stormlink/server/ent/notificationsettings"
 	"stormlink/server/ent/post"
 	"stormlink/server/ent/profiletableinfoitem"
+	"stormlink/server/ent/report"
 	"strconv"
 	"time"
 )
 
+type ActOnReportInput struct {
+	ReportID    string       `json:"reportID"`
+	Action      ReportAction `json:"action"`
+	Reason      *string      `json:"reason,omitempty"`
+	ExpiresAt   *time.Time   `json:"expiresAt,omitempty"`
+	PublicNote  *string      `json:"publicNote,omitempty"`
+	PrivateNote *string      `json:"privateNote,omitempty"`
+	Note        *string      `json:"note,omitempty"`
+}
+
 type AddUserToHostRoleInput struct {
 	RoleID string `json:"roleID"`
 	UserID string `json:"userID"`
 }
 
+type AppealBanInput struct {
+	Scope   banappeal.Scope `json:"scope"`
+	BanID   string          `json:"banID"`
+	Message string          `json:"message"`
+}
+
+type AssociatedAccount struct {
+	User       *ent.User `json:"user"`
+	Ips        []string  `json:"ips"`
+	LastSeenAt time.Time `json:"lastSeenAt"`
+}
+
+type AutomodMatch struct {
+	Rule   *ent.AutomodRule `json:"rule"`
+	Reason string           `json:"reason"`
+}
+
+// AutomodRuleWhereInput is used for filtering AutomodRule objects.
+// Input was generated by ent.
+type AutomodRuleWhereInput struct {
+	Not *AutomodRuleWhereInput   `json:"not,omitempty"`
+	And []*AutomodRuleWhereInput `json:"and,omitempty"`
+	Or  []*AutomodRuleWhereInput `json:"or,omitempty"`
+	// id field predicates
+	ID      *string  `json:"id,omitempty"`
+	IDNeq   *string  `json:"idNEQ,omitempty"`
+	IDIn    []string `json:"idIn,omitempty"`
+	IDNotIn []string `json:"idNotIn,omitempty"`
+	IDGt    *string  `json:"idGT,omitempty"`
+	IDGte   *string  `json:"idGTE,omitempty"`
+	IDLt    *string  `json:"idLT,omitempty"`
+	IDLte   *string  `json:"idLTE,omitempty"`
+	// community_id field predicates
+	CommunityID       *string  `json:"communityID,omitempty"`
+	CommunityIdneq    *string  `json:"communityIDNEQ,omitempty"`
+	CommunityIDIn     []string `json:"communityIDIn,omitempty"`
+	CommunityIDNotIn  []string `json:"communityIDNotIn,omitempty"`
+	CommunityIDIsNil  *bool    `json:"communityIDIsNil,omitempty"`
+	CommunityIDNotNil *bool    `json:"communityIDNotNil,omitempty"`
+	// title field predicates
+	Title             *string  `json:"title,omitempty"`
+	TitleNeq          *string  `json:"titleNEQ,omitempty"`
+	TitleIn           []string `json:"titleIn,omitempty"`
+	TitleNotIn        []string `json:"titleNotIn,omitempty"`
+	TitleGt           *string  `json:"titleGT,omitempty"`
+	TitleGte          *string  `json:"titleGTE,omitempty"`
+	TitleLt           *string  `json:"titleLT,omitempty"`
+	TitleLte          *string  `json:"titleLTE,omitempty"`
+	TitleContains     *string  `json:"titleContains,omitempty"`
+	TitleHasPrefix    *string  `json:"titleHasPrefix,omitempty"`
+	TitleHasSuffix    *string  `json:"titleHasSuffix,omitempty"`
+	TitleEqualFold    *string  `json:"titleEqualFold,omitempty"`
+	TitleContainsFold *string  `json:"titleContainsFold,omitempty"`
+	// kind field predicates
+	Kind      *automodrule.Kind  `json:"kind,omitempty"`
+	KindNeq   *automodrule.Kind  `json:"kindNEQ,omitempty"`
+	KindIn    []automodrule.Kind `json:"kindIn,omitempty"`
+	KindNotIn []automodrule.Kind `json:"kindNotIn,omitempty"`
+	// threshold field predicates
+	Threshold       *int32  `json:"threshold,omitempty"`
+	ThresholdNeq    *int32  `json:"thresholdNEQ,omitempty"`
+	ThresholdIn     []int32 `json:"thresholdIn,omitempty"`
+	ThresholdNotIn  []int32 `json:"thresholdNotIn,omitempty"`
+	ThresholdGt     *int32  `json:"thresholdGT,omitempty"`
+	ThresholdGte    *int32  `json:"thresholdGTE,omitempty"`
+	ThresholdLt     *int32  `json:"thresholdLT,omitempty"`
+	ThresholdLte    *int32  `json:"thresholdLTE,omitempty"`
+	ThresholdIsNil  *bool   `json:"thresholdIsNil,omitempty"`
+	ThresholdNotNil *bool   `json:"thresholdNotNil,omitempty"`
+	// window_minutes field predicates
+	WindowMinutes       *int32  `json:"windowMinutes,omitempty"`
+	WindowMinutesNeq    *int32  `json:"windowMinutesNEQ,omitempty"`
+	WindowMinutesIn     []int32 `json:"windowMinutesIn,omitempty"`
+	WindowMinutesNotIn  []int32 `json:"windowMinutesNotIn,omitempty"`
+	WindowMinutesGt     *int32  `json:"windowMinutesGT,omitempty"`
+	WindowMinutesGte    *int32  `json:"windowMinutesGTE,omitempty"`
+	WindowMinutesLt     *int32  `json:"windowMinutesLT,omitempty"`
+	WindowMinutesLte    *int32  `json:"windowMinutesLTE,omitempty"`
+	WindowMinutesIsNil  *bool   `json:"windowMinutesIsNil,omitempty"`
+	WindowMinutesNotNil *bool   `json:"windowMinutesNotNil,omitempty"`
+	// action field predicates
+	Action      *automodrule.Action  `json:"action,omitempty"`
+	ActionNeq   *automodrule.Action  `json:"actionNEQ,omitempty"`
+	ActionIn    []automodrule.Action `json:"actionIn,omitempty"`
+	ActionNotIn []automodrule.Action `json:"actionNotIn,omitempty"`
+	// mute_minutes field predicates
+	MuteMinutes      *int32  `json:"muteMinutes,omitempty"`
+	MuteMinutesNeq   *int32  `json:"muteMinutesNEQ,omitempty"`
+	MuteMinutesIn    []int32 `json:"muteMinutesIn,omitempty"`
+	MuteMinutesNotIn []int32 `json:"muteMinutesNotIn,omitempty"`
+	MuteMinutesGt    *int32  `json:"muteMinutesGT,omitempty"`
+	MuteMinutesGte   *int32  `json:"muteMinutesGTE,omitempty"`
+	MuteMinutesLt    *int32  `json:"muteMinutesLT,omitempty"`
+	MuteMinutesLte   *int32  `json:"muteMinutesLTE,omitempty"`
+	// message field predicates
+	Message             *string  `json:"message,omitempty"`
+	MessageNeq          *string  `json:"messageNEQ,omitempty"`
+	MessageIn           []string `json:"messageIn,omitempty"`
+	MessageNotIn        []string `json:"messageNotIn,omitempty"`
+	MessageGt           *string  `json:"messageGT,omitempty"`
+	MessageGte          *string  `json:"messageGTE,omitempty"`
+	MessageLt           *string  `json:"messageLT,omitempty"`
+	MessageLte          *string  `json:"messageLTE,omitempty"`
+	MessageContains     *string  `json:"messageContains,omitempty"`
+	MessageHasPrefix    *string  `json:"messageHasPrefix,omitempty"`
+	MessageHasSuffix    *string  `json:"messageHasSuffix,omitempty"`
+	MessageIsNil        *bool    `json:"messageIsNil,omitempty"`
+	MessageNotNil       *bool    `json:"messageNotNil,omitempty"`
+	MessageEqualFold    *string  `json:"messageEqualFold,omitempty"`
+	MessageContainsFold *string  `json:"messageContainsFold,omitempty"`
+	// enabled field predicates
+	Enabled    *bool `json:"enabled,omitempty"`
+	EnabledNeq *bool `json:"enabledNEQ,omitempty"`
+	// created_at field predicates
+	CreatedAt      *time.Time   `json:"createdAt,omitempty"`
+	CreatedAtNeq   *time.Time   `json:"createdAtNEQ,omitempty"`
+	CreatedAtIn    []*time.Time `json:"createdAtIn,omitempty"`
+	CreatedAtNotIn []*time.Time `json:"createdAtNotIn,omitempty"`
+	CreatedAtGt    *time.Time   `json:"createdAtGT,omitempty"`
+	CreatedAtGte   *time.Time   `json:"createdAtGTE,omitempty"`
+	CreatedAtLt    *time.Time   `json:"createdAtLT,omitempty"`
+	CreatedAtLte   *time.Time   `json:"createdAtLTE,omitempty"`
+	// updated_at field predicates
+	UpdatedAt      *time.Time   `json:"updatedAt,omitempty"`
+	UpdatedAtNeq   *time.Time   `json:"updatedAtNEQ,omitempty"`
+	UpdatedAtIn    []*time.Time `json:"updatedAtIn,omitempty"`
+	UpdatedAtNotIn []*time.Time `json:"updatedAtNotIn,omitempty"`
+	UpdatedAtGt    *time.Time   `json:"updatedAtGT,omitempty"`
+	UpdatedAtGte   *time.Time   `json:"updatedAtGTE,omitempty"`
+	UpdatedAtLt    *time.Time   `json:"updatedAtLT,omitempty"`
+	UpdatedAtLte   *time.Time   `json:"updatedAtLTE,omitempty"`
+	// community edge predicates
+	HasCommunity     *bool                  `json:"hasCommunity,omitempty"`
+	HasCommunityWith []*CommunityWhereInput `json:"hasCommunityWith,omitempty"`
+}
+
+type AutomodTestResult struct {
+	Action  *automodrule.Action `json:"action,omitempty"`
+	Rule    *ent.AutomodRule    `json:"rule,omitempty"`
+	Matches []*AutomodMatch     `json:"matches"`
+}
+
+type BanAppealEdge struct {
+	Cursor string         `json:"cursor"`
+	Node   *ent.BanAppeal `json:"node"`
+}
+
+// BanAppealWhereInput is used for filtering BanAppeal objects.
+// Input was generated by ent.
+type BanAppealWhereInput struct {
+	Not *BanAppealWhereInput   `json:"not,omitempty"`
+	And []*BanAppealWhereInput `json:"and,omitempty"`
+	Or  []*BanAppealWhereInput `json:"or,omitempty"`
+	// id field predicates
+	ID      *string  `json:"id,omitempty"`
+	IDNeq   *string  `json:"idNEQ,omitempty"`
+	IDIn    []string `json:"idIn,omitempty"`
+	IDNotIn []string `json:"idNotIn,omitempty"`
+	IDGt    *string  `json:"idGT,omitempty"`
+	IDGte   *string  `json:"idGTE,omitempty"`
+	IDLt    *string  `json:"idLT,omitempty"`
+	IDLte   *string  `json:"idLTE,omitempty"`
+	// user_id field predicates
+	UserID      *string  `json:"userID,omitempty"`
+	UserIdneq   *string  `json:"userIDNEQ,omitempty"`
+	UserIDIn    []string `json:"userIDIn,omitempty"`
+	UserIDNotIn []string `json:"userIDNotIn,omitempty"`
+	// scope field predicates
+	Scope      *banappeal.Scope  `json:"scope,omitempty"`
+	ScopeNeq   *banappeal.Scope  `json:"scopeNEQ,omitempty"`
+	ScopeIn    []banappeal.Scope `json:"scopeIn,omitempty"`
+	ScopeNotIn []banappeal.Scope `json:"scopeNotIn,omitempty"`
+	// ban_id field predicates
+	BanID      *string  `json:"banID,omitempty"`
+	BanIdneq   *string  `json:"banIDNEQ,omitempty"`
+	BanIDIn    []string `json:"banIDIn,omitempty"`
+	BanIDNotIn []string `json:"banIDNotIn,omitempty"`
+	BanIdgt    *string  `json:"banIDGT,omitempty"`
+	BanIdgte   *string  `json:"banIDGTE,omitempty"`
+	BanIdlt    *string  `json:"banIDLT,omitempty"`
+	BanIdlte   *string  `json:"banIDLTE,omitempty"`
+	// community_id field predicates
+	CommunityID       *string  `json:"communityID,omitempty"`
+	CommunityIdneq    *string  `json:"communityIDNEQ,omitempty"`
+	CommunityIDIn     []string `json:"communityIDIn,omitempty"`
+	CommunityIDNotIn  []string `json:"communityIDNotIn,omitempty"`
+	CommunityIDIsNil  *bool    `json:"communityIDIsNil,omitempty"`
+	CommunityIDNotNil *bool    `json:"communityIDNotNil,omitempty"`
+	// ban_reason field predicates
+	BanReason             *string  `json:"banReason,omitempty"`
+	BanReasonNeq          *string  `json:"banReasonNEQ,omitempty"`
+	BanReasonIn           []string `json:"banReasonIn,omitempty"`
+	BanReasonNotIn        []string `json:"banReasonNotIn,omitempty"`
+	BanReasonGt           *string  `json:"banReasonGT,omitempty"`
+	BanReasonGte          *string  `json:"banReasonGTE,omitempty"`
+	BanReasonLt           *string  `json:"banReasonLT,omitempty"`
+	BanReasonLte          *string  `json:"banReasonLTE,omitempty"`
+	BanReasonContains     *string  `json:"banReasonContains,omitempty"`
+	BanReasonHasPrefix    *string  `json:"banReasonHasPrefix,omitempty"`
+	BanReasonHasSuffix    *string  `json:"banReasonHasSuffix,omitempty"`
+	BanReasonEqualFold    *string  `json:"banReasonEqualFold,omitempty"`
+	BanReasonContainsFold *string  `json:"banReasonContainsFold,omitempty"`
+	// ban_issued_by field predicates
+	BanIssuedBy       *string  `json:"banIssuedBy,omitempty"`
+	BanIssuedByNeq    *string  `json:"banIssuedByNEQ,omitempty"`
+	BanIssuedByIn     []string `json:"banIssuedByIn,omitempty"`
+	BanIssuedByNotIn  []string `json:"banIssuedByNotIn,omitempty"`
+	BanIssuedByGt     *string  `json:"banIssuedByGT,omitempty"`
+	BanIssuedByGte    *string  `json:"banIssuedByGTE,omitempty"`
+	BanIssuedByLt     *string  `json:"banIssuedByLT,omitempty"`
+	BanIssuedByLte    *string  `json:"banIssuedByLTE,omitempty"`
+	BanIssuedByIsNil  *bool    `json:"banIssuedByIsNil,omitempty"`
+	BanIssuedByNotNil *bool    `json:"banIssuedByNotNil,omitempty"`
+	// ban_expires_at field predicates
+	BanExpiresAt       *time.Time   `json:"banExpiresAt,omitempty"`
+	BanExpiresAtNeq    *time.Time   `json:"banExpiresAtNEQ,omitempty"`
+	BanExpiresAtIn     []*time.Time `json:"banExpiresAtIn,omitempty"`
+	BanExpiresAtNotIn  []*time.Time `json:"banExpiresAtNotIn,omitempty"`
+	BanExpiresAtGt     *time.Time   `json:"banExpiresAtGT,omitempty"`
+	BanExpiresAtGte    *time.Time   `json:"banExpiresAtGTE,omitempty"`
+	BanExpiresAtLt     *time.Time   `json:"banExpiresAtLT,omitempty"`
+	BanExpiresAtLte    *time.Time   `json:"banExpiresAtLTE,omitempty"`
+	BanExpiresAtIsNil  *bool        `json:"banExpiresAtIsNil,omitempty"`
+	BanExpiresAtNotNil *bool        `json:"banExpiresAtNotNil,omitempty"`
+	// message field predicates
+	Message             *string  `json:"message,omitempty"`
+	MessageNeq          *string  `json:"messageNEQ,omitempty"`
+	MessageIn           []string `json:"messageIn,omitempty"`
+	MessageNotIn        []string `json:"messageNotIn,omitempty"`
+	MessageGt           *string  `json:"messageGT,omitempty"`
+	MessageGte          *string  `json:"messageGTE,omitempty"`
+	MessageLt           *string  `json:"messageLT,omitempty"`
+	MessageLte          *string  `json:"messageLTE,omitempty"`
+	MessageContains     *string  `json:"messageContains,omitempty"`
+	MessageHasPrefix    *string  `json:"messageHasPrefix,omitempty"`
+	MessageHasSuffix    *string  `json:"messageHasSuffix,omitempty"`
+	MessageEqualFold    *string  `json:"messageEqualFold,omitempty"`
+	MessageContainsFold *string  `json:"messageContainsFold,omitempty"`
+	// status field predicates
+	Status      *banappeal.Status  `json:"status,omitempty"`
+	StatusNeq   *banappeal.Status  `json:"statusNEQ,omitempty"`
+	StatusIn    []banappeal.Status `json:"statusIn,omitempty"`
+	StatusNotIn []banappeal.Status `json:"statusNotIn,omitempty"`
+	// decided_by_id field predicates
+	DecidedByID       *string  `json:"decidedByID,omitempty"`
+	DecidedByIdneq    *string  `json:"decidedByIDNEQ,omitempty"`
+	DecidedByIDIn     []string `json:"decidedByIDIn,omitempty"`
+	DecidedByIDNotIn  []string `json:"decidedByIDNotIn,omitempty"`
+	DecidedByIDIsNil  *bool    `json:"decidedByIDIsNil,omitempty"`
+	DecidedByIDNotNil *bool    `json:"decidedByIDNotNil,omitempty"`
+	// decision_message field predicates
+	DecisionMessage             *string  `json:"decisionMessage,omitempty"`
+	DecisionMessageNeq          *string  `json:"decisionMessageNEQ,omitempty"`
+	DecisionMessageIn           []string `json:"decisionMessageIn,omitempty"`
+	DecisionMessageNotIn        []string `json:"decisionMessageNotIn,omitempty"`
+	DecisionMessageGt           *string  `json:"decisionMessageGT,omitempty"`
+	DecisionMessageGte          *string  `json:"decisionMessageGTE,omitempty"`
+	DecisionMessageLt           *string  `json:"decisionMessageLT,omitempty"`
+	DecisionMessageLte          *string  `json:"decisionMessageLTE,omitempty"`
+	DecisionMessageContains     *string  `json:"decisionMessageContains,omitempty"`
+	DecisionMessageHasPrefix    *string  `json:"decisionMessageHasPrefix,omitempty"`
+	DecisionMessageHasSuffix    *string  `json:"decisionMessageHasSuffix,omitempty"`
+	DecisionMessageIsNil        *bool    `json:"decisionMessageIsNil,omitempty"`
+	DecisionMessageNotNil       *bool    `json:"decisionMessageNotNil,omitempty"`
+	DecisionMessageEqualFold    *string  `json:"decisionMessageEqualFold,omitempty"`
+	DecisionMessageContainsFold *string  `json:"decisionMessageContainsFold,omitempty"`
+	// decided_at field predicates
+	DecidedAt       *time.Time   `json:"decidedAt,omitempty"`
+	DecidedAtNeq    *time.Time   `json:"decidedAtNEQ,omitempty"`
+	DecidedAtIn     []*time.Time `json:"decidedAtIn,omitempty"`
+	DecidedAtNotIn  []*time.Time `json:"decidedAtNotIn,omitempty"`
+	DecidedAtGt     *time.Time   `json:"decidedAtGT,omitempty"`
+	DecidedAtGte    *time.Time   `json:"decidedAtGTE,omitempty"`
+	DecidedAtLt     *time.Time   `json:"decidedAtLT,omitempty"`
+	DecidedAtLte    *time.Time   `json:"decidedAtLTE,omitempty"`
+	DecidedAtIsNil  *bool        `json:"decidedAtIsNil,omitempty"`
+	DecidedAtNotNil *bool        `json:"decidedAtNotNil,omitempty"`
+	// overridden field predicates
+	Overridden    *bool `json:"overridden,omitempty"`
+	OverriddenNeq *bool `json:"overriddenNEQ,omitempty"`
+	// created_at field predicates
+	CreatedAt      *time.Time   `json:"createdAt,omitempty"`
+	CreatedAtNeq   *time.Time   `json:"createdAtNEQ,omitempty"`
+	CreatedAtIn    []*time.Time `json:"createdAtIn,omitempty"`
+	CreatedAtNotIn []*time.Time `json:"createdAtNotIn,omitempty"`
+	CreatedAtGt    *time.Time   `json:"createdAtGT,omitempty"`
+	CreatedAtGte   *time.Time   `json:"createdAtGTE,omitempty"`
+	CreatedAtLt    *time.Time   `json:"createdAtLT,omitempty"`
+	CreatedAtLte   *time.Time   `json:"createdAtLTE,omitempty"`
+	// updated_at field predicates
+	UpdatedAt      *time.Time   `json:"updatedAt,omitempty"`
+	UpdatedAtNeq   *time.Time   `json:"updatedAtNEQ,omitempty"`
+	UpdatedAtIn    []*time.Time `json:"updatedAtIn,omitempty"`
+	UpdatedAtNotIn []*time.Time `json:"updatedAtNotIn,omitempty"`
+	UpdatedAtGt    *time.Time   `json:"updatedAtGT,omitempty"`
+	UpdatedAtGte   *time.Time   `json:"updatedAtGTE,omitempty"`
+	UpdatedAtLt    *time.Time   `json:"updatedAtLT,omitempty"`
+	UpdatedAtLte   *time.Time   `json:"updatedAtLTE,omitempty"`
+	// user edge predicates
+	HasUser     *bool             `json:"hasUser,omitempty"`
+	HasUserWith []*UserWhereInput `json:"hasUserWith,omitempty"`
+	// community edge predicates
+	HasCommunity     *bool                  `json:"hasCommunity,omitempty"`
+	HasCommunityWith []*CommunityWhereInput `json:"hasCommunityWith,omitempty"`
+	// decided_by edge predicates
+	HasDecidedBy     *bool             `json:"hasDecidedBy,omitempty"`
+	HasDecidedByWith []*UserWhereInput `json:"hasDecidedByWith,omitempty"`
+}
+
+type BanAppealsConnection struct {
+	Edges    []*BanAppealEdge `json:"edges"`
+	PageInfo *PageInfo        `json:"pageInfo"`
+}
+
 type BanCommunityInput struct {
-	CommunityID string `json:"communityID"`
+	CommunityID string     `json:"communityID"`
+	Reason      *string    `json:"reason,omitempty"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+	PublicNote  *string    `json:"publicNote,omitempty"`
+	PrivateNote *string    `json:"privateNote,omitempty"`
+}
+
+type BanIPInput struct {
+	Cidr        string     `json:"cidr"`
+	Reason      *string    `json:"reason,omitempty"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+	PublicNote  *string    `json:"publicNote,omitempty"`
+	PrivateNote *string    `json:"privateNote,omitempty"`
 }
 
 type BanUserInput struct {
-	UserID      string  `json:"userID"`
-	CommunityID *string `json:"communityID,omitempty"`
+	UserID      string     `json:"userID"`
+	CommunityID *string    `json:"communityID,omitempty"`
+	Reason      *string    `json:"reason,omitempty"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+	PublicNote  *string    `json:"publicNote,omitempty"`
+	PrivateNote *string    `json:"privateNote,omitempty"`
+	Shadow      bool       `json:"shadow"`
 }
 
 type Bookmark struct {
@@ -219,6 +566,9 @@ type CommentWhereInput struct {
 	// has_updated field predicates
 	HasUpdated    *bool `json:"hasUpdated,omitempty"`
 	HasUpdatedNeq *bool `json:"hasUpdatedNEQ,omitempty"`
+	// held field predicates
+	Held    *bool `json:"held,omitempty"`
+	HeldNeq *bool `json:"heldNEQ,omitempty"`
 	// content field predicates
 	Content             *string  `json:"content,omitempty"`
 	ContentNeq          *string  `json:"contentNEQ,omitempty"`
@@ -279,6 +629,17 @@ type CommentsConnection struct {
 	PageInfo *PageInfo      `json:"pageInfo"`
 }
 
+type CommunitiesConnection struct {
+	Edges      []*CommunityEdge `json:"edges"`
+	PageInfo   *PageInfo        `json:"pageInfo"`
+	TotalCount int32            `json:"totalCount"`
+}
+
+type CommunityEdge struct {
+	Cursor string         `json:"cursor"`
+	Node   *ent.Community `json:"node"`
+}
+
 type CommunityFollow struct {
 	ID          string         `json:"id"`
 	UserID      string         `json:"userID"`
@@ -491,6 +852,55 @@ type CommunityUserBanWhereInput struct {
 	IDGte   *string  `json:"idGTE,omitempty"`
 	IDLt    *string  `json:"idLT,omitempty"`
 	IDLte   *string  `json:"idLTE,omitempty"`
+	// reason field predicates
+	Reason             *string  `json:"reason,omitempty"`
+	ReasonNeq          *string  `json:"reasonNEQ,omitempty"`
+	ReasonIn           []string `json:"reasonIn,omitempty"`
+	ReasonNotIn        []string `json:"reasonNotIn,omitempty"`
+	ReasonGt           *string  `json:"reasonGT,omitempty"`
+	ReasonGte          *string  `json:"reasonGTE,omitempty"`
+	ReasonLt           *string  `json:"reasonLT,omitempty"`
+	ReasonLte          *string  `json:"reasonLTE,omitempty"`
+	ReasonContains     *string  `json:"reasonContains,omitempty"`
+	ReasonHasPrefix    *string  `json:"reasonHasPrefix,omitempty"`
+	ReasonHasSuffix    *string  `json:"reasonHasSuffix,omitempty"`
+	ReasonEqualFold    *string  `json:"reasonEqualFold,omitempty"`
+	ReasonContainsFold *string  `json:"reasonContainsFold,omitempty"`
+	// issued_by field predicates
+	IssuedBy       *string  `json:"issuedBy,omitempty"`
+	IssuedByNeq    *string  `json:"issuedByNEQ,omitempty"`
+	IssuedByIn     []string `json:"issuedByIn,omitempty"`
+	IssuedByNotIn  []string `json:"issuedByNotIn,omitempty"`
+	IssuedByIsNil  *bool    `json:"issuedByIsNil,omitempty"`
+	IssuedByNotNil *bool    `json:"issuedByNotNil,omitempty"`
+	// expires_at field predicates
+	ExpiresAt       *time.Time   `json:"expiresAt,omitempty"`
+	ExpiresAtNeq    *time.Time   `json:"expiresAtNEQ,omitempty"`
+	ExpiresAtIn     []*time.Time `json:"expiresAtIn,omitempty"`
+	ExpiresAtNotIn  []*time.Time `json:"expiresAtNotIn,omitempty"`
+	ExpiresAtGt     *time.Time   `json:"expiresAtGT,omitempty"`
+	ExpiresAtGte    *time.Time   `json:"expiresAtGTE,omitempty"`
+	ExpiresAtLt     *time.Time   `json:"expiresAtLT,omitempty"`
+	ExpiresAtLte    *time.Time   `json:"expiresAtLTE,omitempty"`
+	ExpiresAtIsNil  *bool        `json:"expiresAtIsNil,omitempty"`
+	ExpiresAtNotNil *bool        `json:"expiresAtNotNil,omitempty"`
+	// public_note field predicates
+	PublicNote             *string  `json:"publicNote,omitempty"`
+	PublicNoteNeq          *string  `json:"publicNoteNEQ,omitempty"`
+	PublicNoteIn           []string `json:"publicNoteIn,omitempty"`
+	PublicNoteNotIn        []string `json:"publicNoteNotIn,omitempty"`
+	PublicNoteGt           *string  `json:"publicNoteGT,omitempty"`
+	PublicNoteGte          *string  `json:"publicNoteGTE,omitempty"`
+	PublicNoteLt           *string  `json:"publicNoteLT,omitempty"`
+	PublicNoteLte          *string  `json:"publicNoteLTE,omitempty"`
+	PublicNoteContains     *string  `json:"publicNoteContains,omitempty"`
+	PublicNoteHasPrefix    *string  `json:"publicNoteHasPrefix,omitempty"`
+	PublicNoteHasSuffix    *string  `json:"publicNoteHasSuffix,omitempty"`
+	PublicNoteEqualFold    *string  `json:"publicNoteEqualFold,omitempty"`
+	PublicNoteContainsFold *string  `json:"publicNoteContainsFold,omitempty"`
+	// shadow field predicates
+	Shadow    *bool `json:"shadow,omitempty"`
+	ShadowNeq *bool `json:"shadowNEQ,omitempty"`
 	// user_id field predicates
 	UserID      *string  `json:"userID,omitempty"`
 	UserIdneq   *string  `json:"userIDNEQ,omitempty"`
@@ -519,6 +929,9 @@ type CommunityUserBanWhereInput struct {
 	UpdatedAtGte   *time.Time   `json:"updatedAtGTE,omitempty"`
 	UpdatedAtLt    *time.Time   `json:"updatedAtLT,omitempty"`
 	UpdatedAtLte   *time.Time   `json:"updatedAtLTE,omitempty"`
+	// issuer edge predicates
+	HasIssuer     *bool             `json:"hasIssuer,omitempty"`
+	HasIssuerWith []*UserWhereInput `json:"hasIssuerWith,omitempty"`
 	// user edge predicates
 	HasUser     *bool             `json:"hasUser,omitempty"`
 	HasUserWith []*UserWhereInput `json:"hasUserWith,omitempty"`
@@ -542,6 +955,55 @@ type CommunityUserMuteWhereInput struct {
 	IDGte   *string  `json:"idGTE,omitempty"`
 	IDLt    *string  `json:"idLT,omitempty"`
 	IDLte   *string  `json:"idLTE,omitempty"`
+	// reason field predicates
+	Reason             *string  `json:"reason,omitempty"`
+	ReasonNeq          *string  `json:"reasonNEQ,omitempty"`
+	ReasonIn           []string `json:"reasonIn,omitempty"`
+	ReasonNotIn        []string `json:"reasonNotIn,omitempty"`
+	ReasonGt           *string  `json:"reasonGT,omitempty"`
+	ReasonGte          *string  `json:"reasonGTE,omitempty"`
+	ReasonLt           *string  `json:"reasonLT,omitempty"`
+	ReasonLte          *string  `json:"reasonLTE,omitempty"`
+	ReasonContains     *string  `json:"reasonContains,omitempty"`
+	ReasonHasPrefix    *string  `json:"reasonHasPrefix,omitempty"`
+	ReasonHasSuffix    *string  `json:"reasonHasSuffix,omitempty"`
+	ReasonEqualFold    *string  `json:"reasonEqualFold,omitempty"`
+	ReasonContainsFold *string  `json:"reasonContainsFold,omitempty"`
+	// issued_by field predicates
+	IssuedBy       *string  `json:"issuedBy,omitempty"`
+	IssuedByNeq    *string  `json:"issuedByNEQ,omitempty"`
+	IssuedByIn     []string `json:"issuedByIn,omitempty"`
+	IssuedByNotIn  []string `json:"issuedByNotIn,omitempty"`
+	IssuedByIsNil  *bool    `json:"issuedByIsNil,omitempty"`
+	IssuedByNotNil *bool    `json:"issuedByNotNil,omitempty"`
+	// expires_at field predicates
+	ExpiresAt       *time.Time   `json:"expiresAt,omitempty"`
+	ExpiresAtNeq    *time.Time   `json:"expiresAtNEQ,omitempty"`
+	ExpiresAtIn     []*time.Time `json:"expiresAtIn,omitempty"`
+	ExpiresAtNotIn  []*time.Time `json:"expiresAtNotIn,omitempty"`
+	ExpiresAtGt     *time.Time   `json:"expiresAtGT,omitempty"`
+	ExpiresAtGte    *time.Time   `json:"expiresAtGTE,omitempty"`
+	ExpiresAtLt     *time.Time   `json:"expiresAtLT,omitempty"`
+	ExpiresAtLte    *time.Time   `json:"expiresAtLTE,omitempty"`
+	ExpiresAtIsNil  *bool        `json:"expiresAtIsNil,omitempty"`
+	ExpiresAtNotNil *bool        `json:"expiresAtNotNil,omitempty"`
+	// public_note field predicates
+	PublicNote             *string  `json:"publicNote,omitempty"`
+	PublicNoteNeq          *string  `json:"publicNoteNEQ,omitempty"`
+	PublicNoteIn           []string `json:"publicNoteIn,omitempty"`
+	PublicNoteNotIn        []string `json:"publicNoteNotIn,omitempty"`
+	PublicNoteGt           *string  `json:"publicNoteGT,omitempty"`
+	PublicNoteGte          *string  `json:"publicNoteGTE,omitempty"`
+	PublicNoteLt           *string  `json:"publicNoteLT,omitempty"`
+	PublicNoteLte          *string  `json:"publicNoteLTE,omitempty"`
+	PublicNoteContains     *string  `json:"publicNoteContains,omitempty"`
+	PublicNoteHasPrefix    *string  `json:"publicNoteHasPrefix,omitempty"`
+	PublicNoteHasSuffix    *string  `json:"publicNoteHasSuffix,omitempty"`
+	PublicNoteEqualFold    *string  `json:"publicNoteEqualFold,omitempty"`
+	PublicNoteContainsFold *string  `json:"publicNoteContainsFold,omitempty"`
+	// shadow field predicates
+	Shadow    *bool `json:"shadow,omitempty"`
+	ShadowNeq *bool `json:"shadowNEQ,omitempty"`
 	// user_id field predicates
 	UserID      *string  `json:"userID,omitempty"`
 	UserIdneq   *string  `json:"userIDNEQ,omitempty"`
@@ -570,6 +1032,9 @@ type CommunityUserMuteWhereInput struct {
 	UpdatedAtGte   *time.Time   `json:"updatedAtGTE,omitempty"`
 	UpdatedAtLt    *time.Time   `json:"updatedAtLT,omitempty"`
 	UpdatedAtLte   *time.Time   `json:"updatedAtLTE,omitempty"`
+	// issuer edge predicates
+	HasIssuer     *bool             `json:"hasIssuer,omitempty"`
+	HasIssuerWith []*UserWhereInput `json:"hasIssuerWith,omitempty"`
 	// user edge predicates
 	HasUser     *bool             `json:"hasUser,omitempty"`
 	HasUserWith []*UserWhereInput `json:"hasUserWith,omitempty"`
@@ -731,6 +1196,19 @@ type CommunityWhereInput struct {
 	HasCommentsWith []*CommentWhereInput `json:"hasCommentsWith,omitempty"`
 }
 
+type CreateAutomodRuleInput struct {
+	CommunityID   *string            `json:"communityID,omitempty"`
+	Title         string             `json:"title"`
+	Kind          automodrule.Kind   `json:"kind"`
+	Patterns      []string           `json:"patterns,omitempty"`
+	Threshold     *int32             `json:"threshold,omitempty"`
+	WindowMinutes *int32             `json:"windowMinutes,omitempty"`
+	Action        automodrule.Action `json:"action"`
+	MuteMinutes   *int32             `json:"muteMinutes,omitempty"`
+	Message       *string            `json:"message,omitempty"`
+	Enabled       *bool              `json:"enabled,omitempty"`
+}
+
 type CreateCommentInput struct {
 	AuthorID        string  `json:"authorID"`
 	CommunityID     string  `json:"communityID"`
@@ -748,17 +1226,14 @@ type CreateCommunityInput struct {
 }
 
 type CreateCommunityRoleInput struct {
-	Title                              string   `json:"title"`
-	Color                              *string  `json:"color,omitempty"`
-	BadgeID                            *string  `json:"badgeID,omitempty"`
-	CommunityID                        string   `json:"communityID"`
-	CommunityRolesManagement           *bool    `json:"communityRolesManagement,omitempty"`
-	CommunityUserBan                   *bool    `json:"communityUserBan,omitempty"`
-	CommunityUserMute                  *bool    `json:"communityUserMute,omitempty"`
-	CommunityDeletePost                *bool    `json:"communityDeletePost,omitempty"`
-	CommunityDeleteComments            *bool    `json:"communityDeleteComments,omitempty"`
-	CommunityRemovePostFromPublication *bool    `json:"communityRemovePostFromPublication,omitempty"`
-	UserIDs                            []string `json:"userIDs,omitempty"`
+	Title         string   `json:"title"`
+	Color         *string  `json:"color,omitempty"`
+	BadgeID       *string  `json:"badgeID,omitempty"`
+	CommunityID   string   `json:"communityID"`
+	Permissions   []string `json:"permissions,omitempty"`
+	Position      *int32   `json:"position,omitempty"`
+	DeniedActions []string `json:"deniedActions,omitempty"`
+	UserIDs       []string `json:"userIDs,omitempty"`
 }
 
 type CreateCommunityRuleInput struct {
@@ -768,16 +1243,13 @@ type CreateCommunityRuleInput struct {
 }
 
 type CreateHostRoleInput struct {
-	Title                                  string   `json:"title"`
-	Color                                  *string  `json:"color,omitempty"`
-	BadgeID                                *string  `json:"badgeID,omitempty"`
-	CommunityRolesManagement               *bool    `json:"communityRolesManagement,omitempty"`
-	HostUserBan                            *bool    `json:"hostUserBan,omitempty"`
-	HostUserMute                           *bool    `json:"hostUserMute,omitempty"`
-	HostCommunityDeletePost                *bool    `json:"hostCommunityDeletePost,omitempty"`
-	HostCommunityRemovePostFromPublication *bool    `json:"hostCommunityRemovePostFromPublication,omitempty"`
-	HostCommunityDeleteComments            *bool    `json:"hostCommunityDeleteComments,omitempty"`
-	UserIDs                                []string `json:"userIDs,omitempty"`
+	Title         string   `json:"title"`
+	Color         *string  `json:"color,omitempty"`
+	BadgeID       *string  `json:"badgeID,omitempty"`
+	Permissions   []string `json:"permissions,omitempty"`
+	Position      *int32   `json:"position,omitempty"`
+	DeniedActions []string `json:"deniedActions,omitempty"`
+	UserIDs       []string `json:"userIDs,omitempty"`
 }
 
 type CreateHostRuleInput struct {
@@ -803,6 +1275,15 @@ type CreateProfileTableInfoItemInput struct {
 	UserID      *string                   `json:"userID,omitempty"`
 }
 
+type CreateReportInput struct {
+	TargetType      report.TargetType `json:"targetType"`
+	TargetID        string            `json:"targetID"`
+	Reason          report.Reason     `json:"reason"`
+	CommunityRuleID *string           `json:"communityRuleID,omitempty"`
+	HostRuleID      *string           `json:"hostRuleID,omitempty"`
+	Details         *string           `json:"details,omitempty"`
+}
+
 type DeleteBookmarkPostInput struct {
 	PostID string `json:"postID"`
 }
@@ -879,10 +1360,16 @@ type FollowUserInput struct {
 
 type HostCommunityBan struct {
 	ID          string         `json:"id"`
+	Reason      string         `json:"reason"`
+	IssuedBy    *string        `json:"issuedBy,omitempty"`
+	ExpiresAt   *time.Time     `json:"expiresAt,omitempty"`
+	PublicNote  string         `json:"publicNote"`
 	CommunityID string         `json:"communityID"`
 	CreatedAt   time.Time      `json:"createdAt"`
 	UpdatedAt   time.Time      `json:"updatedAt"`
+	Issuer      *ent.User      `json:"issuer,omitempty"`
 	Community   *ent.Community `json:"community"`
+	PrivateNote *string        `json:"privateNote,omitempty"`
 }
 
 func (HostCommunityBan) IsNode() {}
@@ -902,6 +1389,52 @@ type HostCommunityBanWhereInput struct {
 	IDGte   *string  `json:"idGTE,omitempty"`
 	IDLt    *string  `json:"idLT,omitempty"`
 	IDLte   *string  `json:"idLTE,omitempty"`
+	// reason field predicates
+	Reason             *string  `json:"reason,omitempty"`
+	ReasonNeq          *string  `json:"reasonNEQ,omitempty"`
+	ReasonIn           []string `json:"reasonIn,omitempty"`
+	ReasonNotIn        []string `json:"reasonNotIn,omitempty"`
+	ReasonGt           *string  `json:"reasonGT,omitempty"`
+	ReasonGte          *string  `json:"reasonGTE,omitempty"`
+	ReasonLt           *string  `json:"reasonLT,omitempty"`
+	ReasonLte          *string  `json:"reasonLTE,omitempty"`
+	ReasonContains     *string  `json:"reasonContains,omitempty"`
+	ReasonHasPrefix    *string  `json:"reasonHasPrefix,omitempty"`
+	ReasonHasSuffix    *string  `json:"reasonHasSuffix,omitempty"`
+	ReasonEqualFold    *string  `json:"reasonEqualFold,omitempty"`
+	ReasonContainsFold *string  `json:"reasonContainsFold,omitempty"`
+	// issued_by field predicates
+	IssuedBy       *string  `json:"issuedBy,omitempty"`
+	IssuedByNeq    *string  `json:"issuedByNEQ,omitempty"`
+	IssuedByIn     []string `json:"issuedByIn,omitempty"`
+	IssuedByNotIn  []string `json:"issuedByNotIn,omitempty"`
+	IssuedByIsNil  *bool    `json:"issuedByIsNil,omitempty"`
+	IssuedByNotNil *bool    `json:"issuedByNotNil,omitempty"`
+	// expires_at field predicates
+	ExpiresAt       *time.Time   `json:"expiresAt,omitempty"`
+	ExpiresAtNeq    *time.Time   `json:"expiresAtNEQ,omitempty"`
+	ExpiresAtIn     []*time.Time `json:"expiresAtIn,omitempty"`
+	ExpiresAtNotIn  []*time.Time `json:"expiresAtNotIn,omitempty"`
+	ExpiresAtGt     *time.Time   `json:"expiresAtGT,omitempty"`
+	ExpiresAtGte    *time.Time   `json:"expiresAtGTE,omitempty"`
+	ExpiresAtLt     *time.Time   `json:"expiresAtLT,omitempty"`
+	ExpiresAtLte    *time.Time   `json:"expiresAtLTE,omitempty"`
+	ExpiresAtIsNil  *bool        `json:"expiresAtIsNil,omitempty"`
+	ExpiresAtNotNil *bool        `json:"expiresAtNotNil,omitempty"`
+	// public_note field predicates
+	PublicNote             *string  `json:"publicNote,omitempty"`
+	PublicNoteNeq          *string  `json:"publicNoteNEQ,omitempty"`
+	PublicNoteIn           []string `json:"publicNoteIn,omitempty"`
+	PublicNoteNotIn        []string `json:"publicNoteNotIn,omitempty"`
+	PublicNoteGt           *string  `json:"publicNoteGT,omitempty"`
+	PublicNoteGte          *string  `json:"publicNoteGTE,omitempty"`
+	PublicNoteLt           *string  `json:"publicNoteLT,omitempty"`
+	PublicNoteLte          *string  `json:"publicNoteLTE,omitempty"`
+	PublicNoteContains     *string  `json:"publicNoteContains,omitempty"`
+	PublicNoteHasPrefix    *string  `json:"publicNoteHasPrefix,omitempty"`
+	PublicNoteHasSuffix    *string  `json:"publicNoteHasSuffix,omitempty"`
+	PublicNoteEqualFold    *string  `json:"publicNoteEqualFold,omitempty"`
+	PublicNoteContainsFold *string  `json:"publicNoteContainsFold,omitempty"`
 	// community_id field predicates
 	CommunityID      *string  `json:"communityID,omitempty"`
 	CommunityIdneq   *string  `json:"communityIDNEQ,omitempty"`
@@ -925,6 +1458,9 @@ type HostCommunityBanWhereInput struct {
 	UpdatedAtGte   *time.Time   `json:"updatedAtGTE,omitempty"`
 	UpdatedAtLt    *time.Time   `json:"updatedAtLT,omitempty"`
 	UpdatedAtLte   *time.Time   `json:"updatedAtLTE,omitempty"`
+	// issuer edge predicates
+	HasIssuer     *bool             `json:"hasIssuer,omitempty"`
+	HasIssuerWith []*UserWhereInput `json:"hasIssuerWith,omitempty"`
 	// community edge predicates
 	HasCommunity     *bool                  `json:"hasCommunity,omitempty"`
 	HasCommunityWith []*CommunityWhereInput `json:"hasCommunityWith,omitempty"`
@@ -932,10 +1468,16 @@ type HostCommunityBanWhereInput struct {
 
 type HostCommunityMute struct {
 	ID          string         `json:"id"`
+	Reason      string         `json:"reason"`
+	IssuedBy    *string        `json:"issuedBy,omitempty"`
+	ExpiresAt   *time.Time     `json:"expiresAt,omitempty"`
+	PublicNote  string         `json:"publicNote"`
 	CommunityID string         `json:"communityID"`
 	CreatedAt   time.Time      `json:"createdAt"`
 	UpdatedAt   time.Time      `json:"updatedAt"`
+	Issuer      *ent.User      `json:"issuer,omitempty"`
 	Community   *ent.Community `json:"community"`
+	PrivateNote *string        `json:"privateNote,omitempty"`
 }
 
 func (HostCommunityMute) IsNode() {}
@@ -955,6 +1497,52 @@ type HostCommunityMuteWhereInput struct {
 	IDGte   *string  `json:"idGTE,omitempty"`
 	IDLt    *string  `json:"idLT,omitempty"`
 	IDLte   *string  `json:"idLTE,omitempty"`
+	// reason field predicates
+	Reason             *string  `json:"reason,omitempty"`
+	ReasonNeq          *string  `json:"reasonNEQ,omitempty"`
+	ReasonIn           []string `json:"reasonIn,omitempty"`
+	ReasonNotIn        []string `json:"reasonNotIn,omitempty"`
+	ReasonGt           *string  `json:"reasonGT,omitempty"`
+	ReasonGte          *string  `json:"reasonGTE,omitempty"`
+	ReasonLt           *string  `json:"reasonLT,omitempty"`
+	ReasonLte          *string  `json:"reasonLTE,omitempty"`
+	ReasonContains     *string  `json:"reasonContains,omitempty"`
+	ReasonHasPrefix    *string  `json:"reasonHasPrefix,omitempty"`
+	ReasonHasSuffix    *string  `json:"reasonHasSuffix,omitempty"`
+	ReasonEqualFold    *string  `json:"reasonEqualFold,omitempty"`
+	ReasonContainsFold *string  `json:"reasonContainsFold,omitempty"`
+	// issued_by field predicates
+	IssuedBy       *string  `json:"issuedBy,omitempty"`
+	IssuedByNeq    *string  `json:"issuedByNEQ,omitempty"`
+	IssuedByIn     []string `json:"issuedByIn,omitempty"`
+	IssuedByNotIn  []string `json:"issuedByNotIn,omitempty"`
+	IssuedByIsNil  *bool    `json:"issuedByIsNil,omitempty"`
+	IssuedByNotNil *bool    `json:"issuedByNotNil,omitempty"`
+	// expires_at field predicates
+	ExpiresAt       *time.Time   `json:"expiresAt,omitempty"`
+	ExpiresAtNeq    *time.Time   `json:"expiresAtNEQ,omitempty"`
+	ExpiresAtIn     []*time.Time `json:"expiresAtIn,omitempty"`
+	ExpiresAtNotIn  []*time.Time `json:"expiresAtNotIn,omitempty"`
+	ExpiresAtGt     *time.Time   `json:"expiresAtGT,omitempty"`
+	ExpiresAtGte    *time.Time   `json:"expiresAtGTE,omitempty"`
+	ExpiresAtLt     *time.Time   `json:"expiresAtLT,omitempty"`
+	ExpiresAtLte    *time.Time   `json:"expiresAtLTE,omitempty"`
+	ExpiresAtIsNil  *bool        `json:"expiresAtIsNil,omitempty"`
+	ExpiresAtNotNil *bool        `json:"expiresAtNotNil,omitempty"`
+	// public_note field predicates
+	PublicNote             *string  `json:"publicNote,omitempty"`
+	PublicNoteNeq          *string  `json:"publicNoteNEQ,omitempty"`
+	PublicNoteIn           []string `json:"publicNoteIn,omitempty"`
+	PublicNoteNotIn        []string `json:"publicNoteNotIn,omitempty"`
+	PublicNoteGt           *string  `json:"publicNoteGT,omitempty"`
+	PublicNoteGte          *string  `json:"publicNoteGTE,omitempty"`
+	PublicNoteLt           *string  `json:"publicNoteLT,omitempty"`
+	PublicNoteLte          *string  `json:"publicNoteLTE,omitempty"`
+	PublicNoteContains     *string  `json:"publicNoteContains,omitempty"`
+	PublicNoteHasPrefix    *string  `json:"publicNoteHasPrefix,omitempty"`
+	PublicNoteHasSuffix    *string  `json:"publicNoteHasSuffix,omitempty"`
+	PublicNoteEqualFold    *string  `json:"publicNoteEqualFold,omitempty"`
+	PublicNoteContainsFold *string  `json:"publicNoteContainsFold,omitempty"`
 	// community_id field predicates
 	CommunityID      *string  `json:"communityID,omitempty"`
 	CommunityIdneq   *string  `json:"communityIDNEQ,omitempty"`
@@ -978,11 +1566,112 @@ type HostCommunityMuteWhereInput struct {
 	UpdatedAtGte   *time.Time   `json:"updatedAtGTE,omitempty"`
 	UpdatedAtLt    *time.Time   `json:"updatedAtLT,omitempty"`
 	UpdatedAtLte   *time.Time   `json:"updatedAtLTE,omitempty"`
+	// issuer edge predicates
+	HasIssuer     *bool             `json:"hasIssuer,omitempty"`
+	HasIssuerWith []*UserWhereInput `json:"hasIssuerWith,omitempty"`
 	// community edge predicates
 	HasCommunity     *bool                  `json:"hasCommunity,omitempty"`
 	HasCommunityWith []*CommunityWhereInput `json:"hasCommunityWith,omitempty"`
 }
 
+// HostIPBanWhereInput is used for filtering HostIPBan objects.
+// Input was generated by ent.
+type HostIPBanWhereInput struct {
+	Not *HostIPBanWhereInput   `json:"not,omitempty"`
+	And []*HostIPBanWhereInput `json:"and,omitempty"`
+	Or  []*HostIPBanWhereInput `json:"or,omitempty"`
+	// id field predicates
+	ID      *string  `json:"id,omitempty"`
+	IDNeq   *string  `json:"idNEQ,omitempty"`
+	IDIn    []string `json:"idIn,omitempty"`
+	IDNotIn []string `json:"idNotIn,omitempty"`
+	IDGt    *string  `json:"idGT,omitempty"`
+	IDGte   *string  `json:"idGTE,omitempty"`
+	IDLt    *string  `json:"idLT,omitempty"`
+	IDLte   *string  `json:"idLTE,omitempty"`
+	// reason field predicates
+	Reason             *string  `json:"reason,omitempty"`
+	ReasonNeq          *string  `json:"reasonNEQ,omitempty"`
+	ReasonIn           []string `json:"reasonIn,omitempty"`
+	ReasonNotIn        []string `json:"reasonNotIn,omitempty"`
+	ReasonGt           *string  `json:"reasonGT,omitempty"`
+	ReasonGte          *string  `json:"reasonGTE,omitempty"`
+	ReasonLt           *string  `json:"reasonLT,omitempty"`
+	ReasonLte          *string  `json:"reasonLTE,omitempty"`
+	ReasonContains     *string  `json:"reasonContains,omitempty"`
+	ReasonHasPrefix    *string  `json:"reasonHasPrefix,omitempty"`
+	ReasonHasSuffix    *string  `json:"reasonHasSuffix,omitempty"`
+	ReasonEqualFold    *string  `json:"reasonEqualFold,omitempty"`
+	ReasonContainsFold *string  `json:"reasonContainsFold,omitempty"`
+	// issued_by field predicates
+	IssuedBy       *string  `json:"issuedBy,omitempty"`
+	IssuedByNeq    *string  `json:"issuedByNEQ,omitempty"`
+	IssuedByIn     []string `json:"issuedByIn,omitempty"`
+	IssuedByNotIn  []string `json:"issuedByNotIn,omitempty"`
+	IssuedByIsNil  *bool    `json:"issuedByIsNil,omitempty"`
+	IssuedByNotNil *bool    `json:"issuedByNotNil,omitempty"`
+	// expires_at field predicates
+	ExpiresAt       *time.Time   `json:"expiresAt,omitempty"`
+	ExpiresAtNeq    *time.Time   `json:"expiresAtNEQ,omitempty"`
+	ExpiresAtIn     []*time.Time `json:"expiresAtIn,omitempty"`
+	ExpiresAtNotIn  []*time.Time `json:"expiresAtNotIn,omitempty"`
+	ExpiresAtGt     *time.Time   `json:"expiresAtGT,omitempty"`
+	ExpiresAtGte    *time.Time   `json:"expiresAtGTE,omitempty"`
+	ExpiresAtLt     *time.Time   `json:"expiresAtLT,omitempty"`
+	ExpiresAtLte    *time.Time   `json:"expiresAtLTE,omitempty"`
+	ExpiresAtIsNil  *bool        `json:"expiresAtIsNil,omitempty"`
+	ExpiresAtNotNil *bool        `json:"expiresAtNotNil,omitempty"`
+	// public_note field predicates
+	PublicNote             *string  `json:"publicNote,omitempty"`
+	PublicNoteNeq          *string  `json:"publicNoteNEQ,omitempty"`
+	PublicNoteIn           []string `json:"publicNoteIn,omitempty"`
+	PublicNoteNotIn        []string `json:"publicNoteNotIn,omitempty"`
+	PublicNoteGt           *string  `json:"publicNoteGT,omitempty"`
+	PublicNoteGte          *string  `json:"publicNoteGTE,omitempty"`
+	PublicNoteLt           *string  `json:"publicNoteLT,omitempty"`
+	PublicNoteLte          *string  `json:"publicNoteLTE,omitempty"`
+	PublicNoteContains     *string  `json:"publicNoteContains,omitempty"`
+	PublicNoteHasPrefix    *string  `json:"publicNoteHasPrefix,omitempty"`
+	PublicNoteHasSuffix    *string  `json:"publicNoteHasSuffix,omitempty"`
+	PublicNoteEqualFold    *string  `json:"publicNoteEqualFold,omitempty"`
+	PublicNoteContainsFold *string  `json:"publicNoteContainsFold,omitempty"`
+	// cidr field predicates
+	Cidr             *string  `json:"cidr,omitempty"`
+	CidrNeq          *string  `json:"cidrNEQ,omitempty"`
+	CidrIn           []string `json:"cidrIn,omitempty"`
+	CidrNotIn        []string `json:"cidrNotIn,omitempty"`
+	CidrGt           *string  `json:"cidrGT,omitempty"`
+	CidrGte          *string  `json:"cidrGTE,omitempty"`
+	CidrLt           *string  `json:"cidrLT,omitempty"`
+	CidrLte          *string  `json:"cidrLTE,omitempty"`
+	CidrContains     *string  `json:"cidrContains,omitempty"`
+	CidrHasPrefix    *string  `json:"cidrHasPrefix,omitempty"`
+	CidrHasSuffix    *string  `json:"cidrHasSuffix,omitempty"`
+	CidrEqualFold    *string  `json:"cidrEqualFold,omitempty"`
+	CidrContainsFold *string  `json:"cidrContainsFold,omitempty"`
+	// created_at field predicates
+	CreatedAt      *time.Time   `json:"createdAt,omitempty"`
+	CreatedAtNeq   *time.Time   `json:"createdAtNEQ,omitempty"`
+	CreatedAtIn    []*time.Time `json:"createdAtIn,omitempty"`
+	CreatedAtNotIn []*time.Time `json:"createdAtNotIn,omitempty"`
+	CreatedAtGt    *time.Time   `json:"createdAtGT,omitempty"`
+	CreatedAtGte   *time.Time   `json:"createdAtGTE,omitempty"`
+	CreatedAtLt    *time.Time   `json:"createdAtLT,omitempty"`
+	CreatedAtLte   *time.Time   `json:"createdAtLTE,omitempty"`
+	// updated_at field predicates
+	UpdatedAt      *time.Time   `json:"updatedAt,omitempty"`
+	UpdatedAtNeq   *time.Time   `json:"updatedAtNEQ,omitempty"`
+	UpdatedAtIn    []*time.Time `json:"updatedAtIn,omitempty"`
+	UpdatedAtNotIn []*time.Time `json:"updatedAtNotIn,omitempty"`
+	UpdatedAtGt    *time.Time   `json:"updatedAtGT,omitempty"`
+	UpdatedAtGte   *time.Time   `json:"updatedAtGTE,omitempty"`
+	UpdatedAtLt    *time.Time   `json:"updatedAtLT,omitempty"`
+	UpdatedAtLte   *time.Time   `json:"updatedAtLTE,omitempty"`
+	// issuer edge predicates
+	HasIssuer     *bool             `json:"hasIssuer,omitempty"`
+	HasIssuerWith []*UserWhereInput `json:"hasIssuerWith,omitempty"`
+}
+
 // HostRoleWhereInput is used for filtering HostRole objects.
 // Input was generated by ent.
 type HostRoleWhereInput struct {
@@ -1035,24 +1724,15 @@ type HostRoleWhereInput struct {
 	ColorNotNil       *bool    `json:"colorNotNil,omitempty"`
 	ColorEqualFold    *string  `json:"colorEqualFold,omitempty"`
 	ColorContainsFold *string  `json:"colorContainsFold,omitempty"`
-	// community_roles_management field predicates
-	CommunityRolesManagement    *bool `json:"communityRolesManagement,omitempty"`
-	CommunityRolesManagementNeq *bool `json:"communityRolesManagementNEQ,omitempty"`
-	// host_user_ban field predicates
-	HostUserBan    *bool `json:"hostUserBan,omitempty"`
-	HostUserBanNeq *bool `json:"hostUserBanNEQ,omitempty"`
-	// host_user_mute field predicates
-	HostUserMute    *bool `json:"hostUserMute,omitempty"`
-	HostUserMuteNeq *bool `json:"hostUserMuteNEQ,omitempty"`
-	// host_community_delete_post field predicates
-	HostCommunityDeletePost    *bool `json:"hostCommunityDeletePost,omitempty"`
-	HostCommunityDeletePostNeq *bool `json:"hostCommunityDeletePostNEQ,omitempty"`
-	// host_community_remove_post_from_publication field predicates
-	HostCommunityRemovePostFromPublication    *bool `json:"hostCommunityRemovePostFromPublication,omitempty"`
-	HostCommunityRemovePostFromPublicationNeq *bool `json:"hostCommunityRemovePostFromPublicationNEQ,omitempty"`
-	// host_community_delete_comments field predicates
-	HostCommunityDeleteComments    *bool `json:"hostCommunityDeleteComments,omitempty"`
-	HostCommunityDeleteCommentsNeq *bool `json:"hostCommunityDeleteCommentsNEQ,omitempty"`
+	// position field predicates
+	Position      *int32  `json:"position,omitempty"`
+	PositionNeq   *int32  `json:"positionNEQ,omitempty"`
+	PositionIn    []int32 `json:"positionIn,omitempty"`
+	PositionNotIn []int32 `json:"positionNotIn,omitempty"`
+	PositionGt    *int32  `json:"positionGT,omitempty"`
+	PositionGte   *int32  `json:"positionGTE,omitempty"`
+	PositionLt    *int32  `json:"positionLT,omitempty"`
+	PositionLte   *int32  `json:"positionLTE,omitempty"`
 	// created_at field predicates
 	CreatedAt      *time.Time   `json:"createdAt,omitempty"`
 	CreatedAtNeq   *time.Time   `json:"createdAtNEQ,omitempty"`
@@ -1403,6 +2083,55 @@ type HostUserBanWhereInput struct {
 	IDGte   *string  `json:"idGTE,omitempty"`
 	IDLt    *string  `json:"idLT,omitempty"`
 	IDLte   *string  `json:"idLTE,omitempty"`
+	// reason field predicates
+	Reason             *string  `json:"reason,omitempty"`
+	ReasonNeq          *string  `json:"reasonNEQ,omitempty"`
+	ReasonIn           []string `json:"reasonIn,omitempty"`
+	ReasonNotIn        []string `json:"reasonNotIn,omitempty"`
+	ReasonGt           *string  `json:"reasonGT,omitempty"`
+	ReasonGte          *string  `json:"reasonGTE,omitempty"`
+	ReasonLt           *string  `json:"reasonLT,omitempty"`
+	ReasonLte          *string  `json:"reasonLTE,omitempty"`
+	ReasonContains     *string  `json:"reasonContains,omitempty"`
+	ReasonHasPrefix    *string  `json:"reasonHasPrefix,omitempty"`
+	ReasonHasSuffix    *string  `json:"reasonHasSuffix,omitempty"`
+	ReasonEqualFold    *string  `json:"reasonEqualFold,omitempty"`
+	ReasonContainsFold *string  `json:"reasonContainsFold,omitempty"`
+	// issued_by field predicates
+	IssuedBy       *string  `json:"issuedBy,omitempty"`
+	IssuedByNeq    *string  `json:"issuedByNEQ,omitempty"`
+	IssuedByIn     []string `json:"issuedByIn,omitempty"`
+	IssuedByNotIn  []string `json:"issuedByNotIn,omitempty"`
+	IssuedByIsNil  *bool    `json:"issuedByIsNil,omitempty"`
+	IssuedByNotNil *bool    `json:"issuedByNotNil,omitempty"`
+	// expires_at field predicates
+	ExpiresAt       *time.Time   `json:"expiresAt,omitempty"`
+	ExpiresAtNeq    *time.Time   `json:"expiresAtNEQ,omitempty"`
+	ExpiresAtIn     []*time.Time `json:"expiresAtIn,omitempty"`
+	ExpiresAtNotIn  []*time.Time `json:"expiresAtNotIn,omitempty"`
+	ExpiresAtGt     *time.Time   `json:"expiresAtGT,omitempty"`
+	ExpiresAtGte    *time.Time   `json:"expiresAtGTE,omitempty"`
+	ExpiresAtLt     *time.Time   `json:"expiresAtLT,omitempty"`
+	ExpiresAtLte    *time.Time   `json:"expiresAtLTE,omitempty"`
+	ExpiresAtIsNil  *bool        `json:"expiresAtIsNil,omitempty"`
+	ExpiresAtNotNil *bool        `json:"expiresAtNotNil,omitempty"`
+	// public_note field predicates
+	PublicNote             *string  `json:"publicNote,omitempty"`
+	PublicNoteNeq          *string  `json:"publicNoteNEQ,omitempty"`
+	PublicNoteIn           []string `json:"publicNoteIn,omitempty"`
+	PublicNoteNotIn        []string `json:"publicNoteNotIn,omitempty"`
+	PublicNoteGt           *string  `json:"publicNoteGT,omitempty"`
+	PublicNoteGte          *string  `json:"publicNoteGTE,omitempty"`
+	PublicNoteLt           *string  `json:"publicNoteLT,omitempty"`
+	PublicNoteLte          *string  `json:"publicNoteLTE,omitempty"`
+	PublicNoteContains     *string  `json:"publicNoteContains,omitempty"`
+	PublicNoteHasPrefix    *string  `json:"publicNoteHasPrefix,omitempty"`
+	PublicNoteHasSuffix    *string  `json:"publicNoteHasSuffix,omitempty"`
+	PublicNoteEqualFold    *string  `json:"publicNoteEqualFold,omitempty"`
+	PublicNoteContainsFold *string  `json:"publicNoteContainsFold,omitempty"`
+	// shadow field predicates
+	Shadow    *bool `json:"shadow,omitempty"`
+	ShadowNeq *bool `json:"shadowNEQ,omitempty"`
 	// created_at field predicates
 	CreatedAt      *time.Time   `json:"createdAt,omitempty"`
 	CreatedAtNeq   *time.Time   `json:"createdAtNEQ,omitempty"`
@@ -1421,16 +2150,26 @@ type HostUserBanWhereInput struct {
 	UpdatedAtGte   *time.Time   `json:"updatedAtGTE,omitempty"`
 	UpdatedAtLt    *time.Time   `json:"updatedAtLT,omitempty"`
 	UpdatedAtLte   *time.Time   `json:"updatedAtLTE,omitempty"`
+	// issuer edge predicates
+	HasIssuer     *bool             `json:"hasIssuer,omitempty"`
+	HasIssuerWith []*UserWhereInput `json:"hasIssuerWith,omitempty"`
 	// user edge predicates
 	HasUser     *bool             `json:"hasUser,omitempty"`
 	HasUserWith []*UserWhereInput `json:"hasUserWith,omitempty"`
 }
 
 type HostUserMute struct {
-	ID        string    `json:"id"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
-	User      *ent.User `json:"user"`
+	ID          string     `json:"id"`
+	Reason      string     `json:"reason"`
+	IssuedBy    *string    `json:"issuedBy,omitempty"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+	PublicNote  string     `json:"publicNote"`
+	Shadow      bool       `json:"shadow"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+	Issuer      *ent.User  `json:"issuer,omitempty"`
+	User        *ent.User  `json:"user"`
+	PrivateNote *string    `json:"privateNote,omitempty"`
 }
 
 func (HostUserMute) IsNode() {}
@@ -1450,6 +2189,55 @@ type HostUserMuteWhereInput struct {
 	IDGte   *string  `json:"idGTE,omitempty"`
 	IDLt    *string  `json:"idLT,omitempty"`
 	IDLte   *string  `json:"idLTE,omitempty"`
+	// reason field predicates
+	Reason             *string  `json:"reason,omitempty"`
+	ReasonNeq          *string  `json:"reasonNEQ,omitempty"`
+	ReasonIn           []string `json:"reasonIn,omitempty"`
+	ReasonNotIn        []string `json:"reasonNotIn,omitempty"`
+	ReasonGt           *string  `json:"reasonGT,omitempty"`
+	ReasonGte          *string  `json:"reasonGTE,omitempty"`
+	ReasonLt           *string  `json:"reasonLT,omitempty"`
+	ReasonLte          *string  `json:"reasonLTE,omitempty"`
+	ReasonContains     *string  `json:"reasonContains,omitempty"`
+	ReasonHasPrefix    *string  `json:"reasonHasPrefix,omitempty"`
+	ReasonHasSuffix    *string  `json:"reasonHasSuffix,omitempty"`
+	ReasonEqualFold    *string  `json:"reasonEqualFold,omitempty"`
+	ReasonContainsFold *string  `json:"reasonContainsFold,omitempty"`
+	// issued_by field predicates
+	IssuedBy       *string  `json:"issuedBy,omitempty"`
+	IssuedByNeq    *string  `json:"issuedByNEQ,omitempty"`
+	IssuedByIn     []string `json:"issuedByIn,omitempty"`
+	IssuedByNotIn  []string `json:"issuedByNotIn,omitempty"`
+	IssuedByIsNil  *bool    `json:"issuedByIsNil,omitempty"`
+	IssuedByNotNil *bool    `json:"issuedByNotNil,omitempty"`
+	// expires_at field predicates
+	ExpiresAt       *time.Time   `json:"expiresAt,omitempty"`
+	ExpiresAtNeq    *time.Time   `json:"expiresAtNEQ,omitempty"`
+	ExpiresAtIn     []*time.Time `json:"expiresAtIn,omitempty"`
+	ExpiresAtNotIn  []*time.Time `json:"expiresAtNotIn,omitempty"`
+	ExpiresAtGt     *time.Time   `json:"expiresAtGT,omitempty"`
+	ExpiresAtGte    *time.Time   `json:"expiresAtGTE,omitempty"`
+	ExpiresAtLt     *time.Time   `json:"expiresAtLT,omitempty"`
+	ExpiresAtLte    *time.Time   `json:"expiresAtLTE,omitempty"`
+	ExpiresAtIsNil  *bool        `json:"expiresAtIsNil,omitempty"`
+	ExpiresAtNotNil *bool        `json:"expiresAtNotNil,omitempty"`
+	// public_note field predicates
+	PublicNote             *string  `json:"publicNote,omitempty"`
+	PublicNoteNeq          *string  `json:"publicNoteNEQ,omitempty"`
+	PublicNoteIn           []string `json:"publicNoteIn,omitempty"`
+	PublicNoteNotIn        []string `json:"publicNoteNotIn,omitempty"`
+	PublicNoteGt           *string  `json:"publicNoteGT,omitempty"`
+	PublicNoteGte          *string  `json:"publicNoteGTE,omitempty"`
+	PublicNoteLt           *string  `json:"publicNoteLT,omitempty"`
+	PublicNoteLte          *string  `json:"publicNoteLTE,omitempty"`
+	PublicNoteContains     *string  `json:"publicNoteContains,omitempty"`
+	PublicNoteHasPrefix    *string  `json:"publicNoteHasPrefix,omitempty"`
+	PublicNoteHasSuffix    *string  `json:"publicNoteHasSuffix,omitempty"`
+	PublicNoteEqualFold    *string  `json:"publicNoteEqualFold,omitempty"`
+	PublicNoteContainsFold *string  `json:"publicNoteContainsFold,omitempty"`
+	// shadow field predicates
+	Shadow    *bool `json:"shadow,omitempty"`
+	ShadowNeq *bool `json:"shadowNEQ,omitempty"`
 	// created_at field predicates
 	CreatedAt      *time.Time   `json:"createdAt,omitempty"`
 	CreatedAtNeq   *time.Time   `json:"createdAtNEQ,omitempty"`
@@ -1468,6 +2256,9 @@ type HostUserMuteWhereInput struct {
 	UpdatedAtGte   *time.Time   `json:"updatedAtGTE,omitempty"`
 	UpdatedAtLt    *time.Time   `json:"updatedAtLT,omitempty"`
 	UpdatedAtLte   *time.Time   `json:"updatedAtLTE,omitempty"`
+	// issuer edge predicates
+	HasIssuer     *bool             `json:"hasIssuer,omitempty"`
+	HasIssuerWith []*UserWhereInput `json:"hasIssuerWith,omitempty"`
 	// user edge predicates
 	HasUser     *bool             `json:"hasUser,omitempty"`
 	HasUserWith []*UserWhereInput `json:"hasUserWith,omitempty"`
@@ -1740,53 +2531,171 @@ type MediaWhereInput struct {
 	UpdatedAtLte   *time.Time   `json:"updatedAtLTE,omitempty"`
 }
 
+type ModerationActionEdge struct {
+	Cursor string                `json:"cursor"`
+	Node   *ent.ModerationAction `json:"node"`
+}
+
+// ModerationActionWhereInput is used for filtering ModerationAction objects.
+// Input was generated by ent.
+type ModerationActionWhereInput struct {
+	Not *ModerationActionWhereInput   `json:"not,omitempty"`
+	And []*ModerationActionWhereInput `json:"and,omitempty"`
+	Or  []*ModerationActionWhereInput `json:"or,omitempty"`
+	// id field predicates
+	ID      *string  `json:"id,omitempty"`
+	IDNeq   *string  `json:"idNEQ,omitempty"`
+	IDIn    []string `json:"idIn,omitempty"`
+	IDNotIn []string `json:"idNotIn,omitempty"`
+	IDGt    *string  `json:"idGT,omitempty"`
+	IDGte   *string  `json:"idGTE,omitempty"`
+	IDLt    *string  `json:"idLT,omitempty"`
+	IDLte   *string  `json:"idLTE,omitempty"`
+	// type field predicates
+	Type      *moderationaction.Type  `json:"type,omitempty"`
+	TypeNeq   *moderationaction.Type  `json:"typeNEQ,omitempty"`
+	TypeIn    []moderationaction.Type `json:"typeIn,omitempty"`
+	TypeNotIn []moderationaction.Type `json:"typeNotIn,omitempty"`
+	// moderator_id field predicates
+	ModeratorID       *string  `json:"moderatorID,omitempty"`
+	ModeratorIdneq    *string  `json:"moderatorIDNEQ,omitempty"`
+	ModeratorIDIn     []string `json:"moderatorIDIn,omitempty"`
+	ModeratorIDNotIn  []string `json:"moderatorIDNotIn,omitempty"`
+	ModeratorIDIsNil  *bool    `json:"moderatorIDIsNil,omitempty"`
+	ModeratorIDNotNil *bool    `json:"moderatorIDNotNil,omitempty"`
+	// community_id field predicates
+	CommunityID       *string  `json:"communityID,omitempty"`
+	CommunityIdneq    *string  `json:"communityIDNEQ,omitempty"`
+	CommunityIDIn     []string `json:"communityIDIn,omitempty"`
+	CommunityIDNotIn  []string `json:"communityIDNotIn,omitempty"`
+	CommunityIDIsNil  *bool    `json:"communityIDIsNil,omitempty"`
+	CommunityIDNotNil *bool    `json:"communityIDNotNil,omitempty"`
+	// target_type field predicates
+	TargetType      *moderationaction.TargetType  `json:"targetType,omitempty"`
+	TargetTypeNeq   *moderationaction.TargetType  `json:"targetTypeNEQ,omitempty"`
+	TargetTypeIn    []moderationaction.TargetType `json:"targetTypeIn,omitempty"`
+	TargetTypeNotIn []moderationaction.TargetType `json:"targetTypeNotIn,omitempty"`
+	// target_id field predicates
+	TargetID      *string  `json:"targetID,omitempty"`
+	TargetIdneq   *string  `json:"targetIDNEQ,omitempty"`
+	TargetIDIn    []string `json:"targetIDIn,omitempty"`
+	TargetIDNotIn []string `json:"targetIDNotIn,omitempty"`
+	TargetIdgt    *string  `json:"targetIDGT,omitempty"`
+	TargetIdgte   *string  `json:"targetIDGTE,omitempty"`
+	TargetIdlt    *string  `json:"targetIDLT,omitempty"`
+	TargetIdlte   *string  `json:"targetIDLTE,omitempty"`
+	// target_user_id field predicates
+	TargetUserID       *string  `json:"targetUserID,omitempty"`
+	TargetUserIdneq    *string  `json:"targetUserIDNEQ,omitempty"`
+	TargetUserIDIn     []string `json:"targetUserIDIn,omitempty"`
+	TargetUserIDNotIn  []string `json:"targetUserIDNotIn,omitempty"`
+	TargetUserIDIsNil  *bool    `json:"targetUserIDIsNil,omitempty"`
+	TargetUserIDNotNil *bool    `json:"targetUserIDNotNil,omitempty"`
+	// created_at field predicates
+	CreatedAt      *time.Time   `json:"createdAt,omitempty"`
+	CreatedAtNeq   *time.Time   `json:"createdAtNEQ,omitempty"`
+	CreatedAtIn    []*time.Time `json:"createdAtIn,omitempty"`
+	CreatedAtNotIn []*time.Time `json:"createdAtNotIn,omitempty"`
+	CreatedAtGt    *time.Time   `json:"createdAtGT,omitempty"`
+	CreatedAtGte   *time.Time   `json:"createdAtGTE,omitempty"`
+	CreatedAtLt    *time.Time   `json:"createdAtLT,omitempty"`
+	CreatedAtLte   *time.Time   `json:"createdAtLTE,omitempty"`
+	// moderator edge predicates
+	HasModerator     *bool             `json:"hasModerator,omitempty"`
+	HasModeratorWith []*UserWhereInput `json:"hasModeratorWith,omitempty"`
+	// community edge predicates
+	HasCommunity     *bool                  `json:"hasCommunity,omitempty"`
+	HasCommunityWith []*CommunityWhereInput `json:"hasCommunityWith,omitempty"`
+	// target_user edge predicates
+	HasTargetUser     *bool             `json:"hasTargetUser,omitempty"`
+	HasTargetUserWith []*UserWhereInput `json:"hasTargetUserWith,omitempty"`
+}
+
+type ModerationActionsConnection struct {
+	Edges    []*ModerationActionEdge `json:"edges"`
+	PageInfo *PageInfo               `json:"pageInfo"`
+}
+
+type ModerationEvent struct {
+	Type      ModerationEventType `json:"type"`
+	Community *ent.Community      `json:"community,omitempty"`
+	Moderator *ent.User           `json:"moderator,omitempty"`
+	User      *ent.User           `json:"user,omitempty"`
+	Post      *ent.Post           `json:"post,omitempty"`
+	CreatedAt time.Time           `json:"createdAt"`
+}
+
+type ModerationLogFilter struct {
+	CommunityID  *string                      `json:"communityID,omitempty"`
+	ModeratorID  *string                      `json:"moderatorID,omitempty"`
+	TargetUserID *string                      `json:"targetUserID,omitempty"`
+	TargetType   *moderationaction.TargetType `json:"targetType,omitempty"`
+	TargetID     *string                      `json:"targetID,omitempty"`
+	Types        []moderationaction.Type      `json:"types,omitempty"`
+	From         *time.Time                   `json:"from,omitempty"`
+	To           *time.Time                   `json:"to,omitempty"`
+}
+
 type Mutation struct {
 }
 
 type MuteCommunityInput struct {
-	CommunityID string `json:"communityID"`
+	CommunityID string     `json:"communityID"`
+	Reason      *string    `json:"reason,omitempty"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+	PublicNote  *string    `json:"publicNote,omitempty"`
+	PrivateNote *string    `json:"privateNote,omitempty"`
+}
+
+type MuteNotificationsInput struct {
+	PostID      *string `json:"postID,omitempty"`
+	CommunityID *string `json:"communityID,omitempty"`
 }
 
 type MuteUserInput struct {
-	UserID      string `json:"userID"`
-	CommunityID string `json:"communityID"`
+	UserID      string     `json:"userID"`
+	CommunityID string     `json:"communityID"`
+	Reason      *string    `json:"reason,omitempty"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+	PublicNote  *string    `json:"publicNote,omitempty"`
+	PrivateNote *string    `json:"privateNote,omitempty"`
+	Shadow      bool       `json:"shadow"`
 }
 
 type MuteUserOnHostInput struct {
-	UserID string `json:"userID"`
+	UserID      string     `json:"userID"`
+	Reason      *string    `json:"reason,omitempty"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+	PublicNote  *string    `json:"publicNote,omitempty"`
+	PrivateNote *string    `json:"privateNote,omitempty"`
+	Shadow      bool       `json:"shadow"`
 }
 
-// Information about pagination in a connection.
-// https://relay.dev/graphql/connections.htm#sec-undefined.PageInfo
-type PageInfo struct {
-	// When paginating forwards, are there more items?
-	HasNextPage bool `json:"hasNextPage"`
-	// When paginating backwards, are there more items?
-	HasPreviousPage bool `json:"hasPreviousPage"`
-	// When paginating backwards, the cursor to continue.
-	StartCursor *string `json:"startCursor,omitempty"`
-	// When paginating forwards, the cursor to continue.
-	EndCursor *string `json:"endCursor,omitempty"`
+type NotificationEdge struct {
+	Cursor string            `json:"cursor"`
+	Node   *ent.Notification `json:"node"`
 }
 
-type PostLike struct {
-	ID        string    `json:"id"`
-	UserID    string    `json:"userID"`
-	PostID    string    `json:"postID"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
-	User      *ent.User `json:"user"`
-	Post      *ent.Post `json:"post"`
+type NotificationPreference struct {
+	Type   NotificationPreferenceType `json:"type"`
+	InApp  bool                       `json:"inApp"`
+	Email  bool                       `json:"email"`
+	Digest bool                       `json:"digest"`
 }
 
-func (PostLike) IsNode() {}
+type NotificationPreferenceInput struct {
+	Type   NotificationPreferenceType `json:"type"`
+	InApp  *bool                      `json:"inApp,omitempty"`
+	Email  *bool                      `json:"email,omitempty"`
+	Digest *bool                      `json:"digest,omitempty"`
+}
 
-// PostLikeWhereInput is used for filtering PostLike objects.
+// NotificationSettingsWhereInput is used for filtering NotificationSettings objects.
 // Input was generated by ent.
-type PostLikeWhereInput struct {
-	Not *PostLikeWhereInput   `json:"not,omitempty"`
-	And []*PostLikeWhereInput `json:"and,omitempty"`
-	Or  []*PostLikeWhereInput `json:"or,omitempty"`
+type NotificationSettingsWhereInput struct {
+	Not *NotificationSettingsWhereInput   `json:"not,omitempty"`
+	And []*NotificationSettingsWhereInput `json:"and,omitempty"`
+	Or  []*NotificationSettingsWhereInput `json:"or,omitempty"`
 	// id field predicates
 	ID      *string  `json:"id,omitempty"`
 	IDNeq   *string  `json:"idNEQ,omitempty"`
@@ -1801,11 +2710,25 @@ type PostLikeWhereInput struct {
 	UserIdneq   *string  `json:"userIDNEQ,omitempty"`
 	UserIDIn    []string `json:"userIDIn,omitempty"`
 	UserIDNotIn []string `json:"userIDNotIn,omitempty"`
-	// post_id field predicates
-	PostID      *string  `json:"postID,omitempty"`
-	PostIdneq   *string  `json:"postIDNEQ,omitempty"`
-	PostIDIn    []string `json:"postIDIn,omitempty"`
-	PostIDNotIn []string `json:"postIDNotIn,omitempty"`
+	// email_frequency field predicates
+	EmailFrequency      *notificationsettings.EmailFrequency  `json:"emailFrequency,omitempty"`
+	EmailFrequencyNeq   *notificationsettings.EmailFrequency  `json:"emailFrequencyNEQ,omitempty"`
+	EmailFrequencyIn    []notificationsettings.EmailFrequency `json:"emailFrequencyIn,omitempty"`
+	EmailFrequencyNotIn []notificationsettings.EmailFrequency `json:"emailFrequencyNotIn,omitempty"`
+	// timezone field predicates
+	Timezone             *string  `json:"timezone,omitempty"`
+	TimezoneNeq          *string  `json:"timezoneNEQ,omitempty"`
+	TimezoneIn           []string `json:"timezoneIn,omitempty"`
+	TimezoneNotIn        []string `json:"timezoneNotIn,omitempty"`
+	TimezoneGt           *string  `json:"timezoneGT,omitempty"`
+	TimezoneGte          *string  `json:"timezoneGTE,omitempty"`
+	TimezoneLt           *string  `json:"timezoneLT,omitempty"`
+	TimezoneLte          *string  `json:"timezoneLTE,omitempty"`
+	TimezoneContains     *string  `json:"timezoneContains,omitempty"`
+	TimezoneHasPrefix    *string  `json:"timezoneHasPrefix,omitempty"`
+	TimezoneHasSuffix    *string  `json:"timezoneHasSuffix,omitempty"`
+	TimezoneEqualFold    *string  `json:"timezoneEqualFold,omitempty"`
+	TimezoneContainsFold *string  `json:"timezoneContainsFold,omitempty"`
 	// created_at field predicates
 	CreatedAt      *time.Time   `json:"createdAt,omitempty"`
 	CreatedAtNeq   *time.Time   `json:"createdAtNEQ,omitempty"`
@@ -1824,16 +2747,232 @@ type PostLikeWhereInput struct {
 	UpdatedAtGte   *time.Time   `json:"updatedAtGTE,omitempty"`
 	UpdatedAtLt    *time.Time   `json:"updatedAtLT,omitempty"`
 	UpdatedAtLte   *time.Time   `json:"updatedAtLTE,omitempty"`
-	// user edge predicates
-	HasUser     *bool             `json:"hasUser,omitempty"`
-	HasUserWith []*UserWhereInput `json:"hasUserWith,omitempty"`
-	// post edge predicates
-	HasPost     *bool             `json:"hasPost,omitempty"`
-	HasPostWith []*PostWhereInput `json:"hasPostWith,omitempty"`
 }
 
-type PostStatus struct {
-	LikesCount           string `json:"likesCount"`
+// NotificationWhereInput is used for filtering Notification objects.
+// Input was generated by ent.
+type NotificationWhereInput struct {
+	Not *NotificationWhereInput   `json:"not,omitempty"`
+	And []*NotificationWhereInput `json:"and,omitempty"`
+	Or  []*NotificationWhereInput `json:"or,omitempty"`
+	// id field predicates
+	ID      *string  `json:"id,omitempty"`
+	IDNeq   *string  `json:"idNEQ,omitempty"`
+	IDIn    []string `json:"idIn,omitempty"`
+	IDNotIn []string `json:"idNotIn,omitempty"`
+	IDGt    *string  `json:"idGT,omitempty"`
+	IDGte   *string  `json:"idGTE,omitempty"`
+	IDLt    *string  `json:"idLT,omitempty"`
+	IDLte   *string  `json:"idLTE,omitempty"`
+	// user_id field predicates
+	UserID      *string  `json:"userID,omitempty"`
+	UserIdneq   *string  `json:"userIDNEQ,omitempty"`
+	UserIDIn    []string `json:"userIDIn,omitempty"`
+	UserIDNotIn []string `json:"userIDNotIn,omitempty"`
+	// type field predicates
+	Type      *notification.Type  `json:"type,omitempty"`
+	TypeNeq   *notification.Type  `json:"typeNEQ,omitempty"`
+	TypeIn    []notification.Type `json:"typeIn,omitempty"`
+	TypeNotIn []notification.Type `json:"typeNotIn,omitempty"`
+	// actor_id field predicates
+	ActorID       *string  `json:"actorID,omitempty"`
+	ActorIdneq    *string  `json:"actorIDNEQ,omitempty"`
+	ActorIDIn     []string `json:"actorIDIn,omitempty"`
+	ActorIDNotIn  []string `json:"actorIDNotIn,omitempty"`
+	ActorIDIsNil  *bool    `json:"actorIDIsNil,omitempty"`
+	ActorIDNotNil *bool    `json:"actorIDNotNil,omitempty"`
+	// actor_count field predicates
+	ActorCount      *int32  `json:"actorCount,omitempty"`
+	ActorCountNeq   *int32  `json:"actorCountNEQ,omitempty"`
+	ActorCountIn    []int32 `json:"actorCountIn,omitempty"`
+	ActorCountNotIn []int32 `json:"actorCountNotIn,omitempty"`
+	ActorCountGt    *int32  `json:"actorCountGT,omitempty"`
+	ActorCountGte   *int32  `json:"actorCountGTE,omitempty"`
+	ActorCountLt    *int32  `json:"actorCountLT,omitempty"`
+	ActorCountLte   *int32  `json:"actorCountLTE,omitempty"`
+	// post_id field predicates
+	PostID       *string  `json:"postID,omitempty"`
+	PostIdneq    *string  `json:"postIDNEQ,omitempty"`
+	PostIDIn     []string `json:"postIDIn,omitempty"`
+	PostIDNotIn  []string `json:"postIDNotIn,omitempty"`
+	PostIDIsNil  *bool    `json:"postIDIsNil,omitempty"`
+	PostIDNotNil *bool    `json:"postIDNotNil,omitempty"`
+	// comment_id field predicates
+	CommentID       *string  `json:"commentID,omitempty"`
+	CommentIdneq    *string  `json:"commentIDNEQ,omitempty"`
+	CommentIDIn     []string `json:"commentIDIn,omitempty"`
+	CommentIDNotIn  []string `json:"commentIDNotIn,omitempty"`
+	CommentIDIsNil  *bool    `json:"commentIDIsNil,omitempty"`
+	CommentIDNotNil *bool    `json:"commentIDNotNil,omitempty"`
+	// community_id field predicates
+	CommunityID       *string  `json:"communityID,omitempty"`
+	CommunityIdneq    *string  `json:"communityIDNEQ,omitempty"`
+	CommunityIDIn     []string `json:"communityIDIn,omitempty"`
+	CommunityIDNotIn  []string `json:"communityIDNotIn,omitempty"`
+	CommunityIDIsNil  *bool    `json:"communityIDIsNil,omitempty"`
+	CommunityIDNotNil *bool    `json:"communityIDNotNil,omitempty"`
+	// message field predicates
+	Message             *string  `json:"message,omitempty"`
+	MessageNeq          *string  `json:"messageNEQ,omitempty"`
+	MessageIn           []string `json:"messageIn,omitempty"`
+	MessageNotIn        []string `json:"messageNotIn,omitempty"`
+	MessageGt           *string  `json:"messageGT,omitempty"`
+	MessageGte          *string  `json:"messageGTE,omitempty"`
+	MessageLt           *string  `json:"messageLT,omitempty"`
+	MessageLte          *string  `json:"messageLTE,omitempty"`
+	MessageContains     *string  `json:"messageContains,omitempty"`
+	MessageHasPrefix    *string  `json:"messageHasPrefix,omitempty"`
+	MessageHasSuffix    *string  `json:"messageHasSuffix,omitempty"`
+	MessageIsNil        *bool    `json:"messageIsNil,omitempty"`
+	MessageNotNil       *bool    `json:"messageNotNil,omitempty"`
+	MessageEqualFold    *string  `json:"messageEqualFold,omitempty"`
+	MessageContainsFold *string  `json:"messageContainsFold,omitempty"`
+	// read_at field predicates
+	ReadAt       *time.Time   `json:"readAt,omitempty"`
+	ReadAtNeq    *time.Time   `json:"readAtNEQ,omitempty"`
+	ReadAtIn     []*time.Time `json:"readAtIn,omitempty"`
+	ReadAtNotIn  []*time.Time `json:"readAtNotIn,omitempty"`
+	ReadAtGt     *time.Time   `json:"readAtGT,omitempty"`
+	ReadAtGte    *time.Time   `json:"readAtGTE,omitempty"`
+	ReadAtLt     *time.Time   `json:"readAtLT,omitempty"`
+	ReadAtLte    *time.Time   `json:"readAtLTE,omitempty"`
+	ReadAtIsNil  *bool        `json:"readAtIsNil,omitempty"`
+	ReadAtNotNil *bool        `json:"readAtNotNil,omitempty"`
+	// created_at field predicates
+	CreatedAt      *time.Time   `json:"createdAt,omitempty"`
+	CreatedAtNeq   *time.Time   `json:"createdAtNEQ,omitempty"`
+	CreatedAtIn    []*time.Time `json:"createdAtIn,omitempty"`
+	CreatedAtNotIn []*time.Time `json:"createdAtNotIn,omitempty"`
+	CreatedAtGt    *time.Time   `json:"createdAtGT,omitempty"`
+	CreatedAtGte   *time.Time   `json:"createdAtGTE,omitempty"`
+	CreatedAtLt    *time.Time   `json:"createdAtLT,omitempty"`
+	CreatedAtLte   *time.Time   `json:"createdAtLTE,omitempty"`
+	// updated_at field predicates
+	UpdatedAt      *time.Time   `json:"updatedAt,omitempty"`
+	UpdatedAtNeq   *time.Time   `json:"updatedAtNEQ,omitempty"`
+	UpdatedAtIn    []*time.Time `json:"updatedAtIn,omitempty"`
+	UpdatedAtNotIn []*time.Time `json:"updatedAtNotIn,omitempty"`
+	UpdatedAtGt    *time.Time   `json:"updatedAtGT,omitempty"`
+	UpdatedAtGte   *time.Time   `json:"updatedAtGTE,omitempty"`
+	UpdatedAtLt    *time.Time   `json:"updatedAtLT,omitempty"`
+	UpdatedAtLte   *time.Time   `json:"updatedAtLTE,omitempty"`
+	// actor edge predicates
+	HasActor     *bool             `json:"hasActor,omitempty"`
+	HasActorWith []*UserWhereInput `json:"hasActorWith,omitempty"`
+	// post edge predicates
+	HasPost     *bool             `json:"hasPost,omitempty"`
+	HasPostWith []*PostWhereInput `json:"hasPostWith,omitempty"`
+	// comment edge predicates
+	HasComment     *bool                `json:"hasComment,omitempty"`
+	HasCommentWith []*CommentWhereInput `json:"hasCommentWith,omitempty"`
+	// community edge predicates
+	HasCommunity     *bool                  `json:"hasCommunity,omitempty"`
+	HasCommunityWith []*CommunityWhereInput `json:"hasCommunityWith,omitempty"`
+}
+
+type NotificationsConnection struct {
+	Edges       []*NotificationEdge `json:"edges"`
+	PageInfo    *PageInfo           `json:"pageInfo"`
+	UnreadCount int32               `json:"unreadCount"`
+}
+
+// Information about pagination in a connection.
+// https://relay.dev/graphql/connections.htm#sec-undefined.PageInfo
+type PageInfo struct {
+	// When paginating forwards, are there more items?
+	HasNextPage bool `json:"hasNextPage"`
+	// When paginating backwards, are there more items?
+	HasPreviousPage bool `json:"hasPreviousPage"`
+	// When paginating backwards, the cursor to continue.
+	StartCursor *string `json:"startCursor,omitempty"`
+	// When paginating forwards, the cursor to continue.
+	EndCursor *string `json:"endCursor,omitempty"`
+}
+
+type PermissionDefinition struct {
+	Key         string          `json:"key"`
+	Description string          `json:"description"`
+	Scope       PermissionScope `json:"scope"`
+	Dangerous   bool            `json:"dangerous"`
+}
+
+type PostEdge struct {
+	Cursor string    `json:"cursor"`
+	Node   *ent.Post `json:"node"`
+}
+
+type PostLike struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"userID"`
+	PostID    string    `json:"postID"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	User      *ent.User `json:"user"`
+	Post      *ent.Post `json:"post"`
+}
+
+func (PostLike) IsNode() {}
+
+// PostLikeWhereInput is used for filtering PostLike objects.
+// Input was generated by ent.
+type PostLikeWhereInput struct {
+	Not *PostLikeWhereInput   `json:"not,omitempty"`
+	And []*PostLikeWhereInput `json:"and,omitempty"`
+	Or  []*PostLikeWhereInput `json:"or,omitempty"`
+	// id field predicates
+	ID      *string  `json:"id,omitempty"`
+	IDNeq   *string  `json:"idNEQ,omitempty"`
+	IDIn    []string `json:"idIn,omitempty"`
+	IDNotIn []string `json:"idNotIn,omitempty"`
+	IDGt    *string  `json:"idGT,omitempty"`
+	IDGte   *string  `json:"idGTE,omitempty"`
+	IDLt    *string  `json:"idLT,omitempty"`
+	IDLte   *string  `json:"idLTE,omitempty"`
+	// user_id field predicates
+	UserID      *string  `json:"userID,omitempty"`
+	UserIdneq   *string  `json:"userIDNEQ,omitempty"`
+	UserIDIn    []string `json:"userIDIn,omitempty"`
+	UserIDNotIn []string `json:"userIDNotIn,omitempty"`
+	// post_id field predicates
+	PostID      *string  `json:"postID,omitempty"`
+	PostIdneq   *string  `json:"postIDNEQ,omitempty"`
+	PostIDIn    []string `json:"postIDIn,omitempty"`
+	PostIDNotIn []string `json:"postIDNotIn,omitempty"`
+	// created_at field predicates
+	CreatedAt      *time.Time   `json:"createdAt,omitempty"`
+	CreatedAtNeq   *time.Time   `json:"createdAtNEQ,omitempty"`
+	CreatedAtIn    []*time.Time `json:"createdAtIn,omitempty"`
+	CreatedAtNotIn []*time.Time `json:"createdAtNotIn,omitempty"`
+	CreatedAtGt    *time.Time   `json:"createdAtGT,omitempty"`
+	CreatedAtGte   *time.Time   `json:"createdAtGTE,omitempty"`
+	CreatedAtLt    *time.Time   `json:"createdAtLT,omitempty"`
+	CreatedAtLte   *time.Time   `json:"createdAtLTE,omitempty"`
+	// updated_at field predicates
+	UpdatedAt      *time.Time   `json:"updatedAt,omitempty"`
+	UpdatedAtNeq   *time.Time   `json:"updatedAtNEQ,omitempty"`
+	UpdatedAtIn    []*time.Time `json:"updatedAtIn,omitempty"`
+	UpdatedAtNotIn []*time.Time `json:"updatedAtNotIn,omitempty"`
+	UpdatedAtGt    *time.Time   `json:"updatedAtGT,omitempty"`
+	UpdatedAtGte   *time.Time   `json:"updatedAtGTE,omitempty"`
+	UpdatedAtLt    *time.Time   `json:"updatedAtLT,omitempty"`
+	UpdatedAtLte   *time.Time   `json:"updatedAtLTE,omitempty"`
+	// user edge predicates
+	HasUser     *bool             `json:"hasUser,omitempty"`
+	HasUserWith []*UserWhereInput `json:"hasUserWith,omitempty"`
+	// post edge predicates
+	HasPost     *bool             `json:"hasPost,omitempty"`
+	HasPostWith []*PostWhereInput `json:"hasPostWith,omitempty"`
+}
+
+type PostStats struct {
+	PostID        string `json:"postId"`
+	LikesCount    int32  `json:"likesCount"`
+	CommentsCount int32  `json:"commentsCount"`
+	ViewsCount    int32  `json:"viewsCount"`
+}
+
+type PostStatus struct {
+	LikesCount           string `json:"likesCount"`
 	CommentsCount        string `json:"commentsCount"`
 	BookmarksCount       string `json:"bookmarksCount"`
 	IsLiked              bool   `json:"isLiked"`
@@ -1968,6 +3107,12 @@ type PostWhereInput struct {
 	HasBookmarksWith []*BookmarkWhereInput `json:"hasBookmarksWith,omitempty"`
 }
 
+type PostsConnection struct {
+	Edges      []*PostEdge `json:"edges"`
+	PageInfo   *PageInfo   `json:"pageInfo"`
+	TotalCount int32       `json:"totalCount"`
+}
+
 // ProfileTableInfoItemWhereInput is used for filtering ProfileTableInfoItem objects.
 // Input was generated by ent.
 type ProfileTableInfoItemWhereInput struct {
@@ -2059,6 +3204,16 @@ type ProfileTableInfoItemWhereInput struct {
 type Query struct {
 }
 
+type QuietHours struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+type QuietHoursInput struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
 type RefreshTokenResponse struct {
 	AccessToken  string `json:"accessToken"`
 	RefreshToken string `json:"refreshToken"`
@@ -2079,6 +3234,233 @@ type RemoveUserFromHostRoleInput struct {
 	UserID string `json:"userID"`
 }
 
+type ReportEdge struct {
+	Cursor string      `json:"cursor"`
+	Node   *ent.Report `json:"node"`
+}
+
+// ReportWhereInput is used for filtering Report objects.
+// Input was generated by ent.
+type ReportWhereInput struct {
+	Not *ReportWhereInput   `json:"not,omitempty"`
+	And []*ReportWhereInput `json:"and,omitempty"`
+	Or  []*ReportWhereInput `json:"or,omitempty"`
+	// id field predicates
+	ID      *string  `json:"id,omitempty"`
+	IDNeq   *string  `json:"idNEQ,omitempty"`
+	IDIn    []string `json:"idIn,omitempty"`
+	IDNotIn []string `json:"idNotIn,omitempty"`
+	IDGt    *string  `json:"idGT,omitempty"`
+	IDGte   *string  `json:"idGTE,omitempty"`
+	IDLt    *string  `json:"idLT,omitempty"`
+	IDLte   *string  `json:"idLTE,omitempty"`
+	// reporter_id field predicates
+	ReporterID       *string  `json:"reporterID,omitempty"`
+	ReporterIdneq    *string  `json:"reporterIDNEQ,omitempty"`
+	ReporterIDIn     []string `json:"reporterIDIn,omitempty"`
+	ReporterIDNotIn  []string `json:"reporterIDNotIn,omitempty"`
+	ReporterIDIsNil  *bool    `json:"reporterIDIsNil,omitempty"`
+	ReporterIDNotNil *bool    `json:"reporterIDNotNil,omitempty"`
+	// automod_rule_id field predicates
+	AutomodRuleID       *string  `json:"automodRuleID,omitempty"`
+	AutomodRuleIdneq    *string  `json:"automodRuleIDNEQ,omitempty"`
+	AutomodRuleIDIn     []string `json:"automodRuleIDIn,omitempty"`
+	AutomodRuleIDNotIn  []string `json:"automodRuleIDNotIn,omitempty"`
+	AutomodRuleIDIsNil  *bool    `json:"automodRuleIDIsNil,omitempty"`
+	AutomodRuleIDNotNil *bool    `json:"automodRuleIDNotNil,omitempty"`
+	// target_type field predicates
+	TargetType      *report.TargetType  `json:"targetType,omitempty"`
+	TargetTypeNeq   *report.TargetType  `json:"targetTypeNEQ,omitempty"`
+	TargetTypeIn    []report.TargetType `json:"targetTypeIn,omitempty"`
+	TargetTypeNotIn []report.TargetType `json:"targetTypeNotIn,omitempty"`
+	// target_id field predicates
+	TargetID      *string  `json:"targetID,omitempty"`
+	TargetIdneq   *string  `json:"targetIDNEQ,omitempty"`
+	TargetIDIn    []string `json:"targetIDIn,omitempty"`
+	TargetIDNotIn []string `json:"targetIDNotIn,omitempty"`
+	TargetIdgt    *string  `json:"targetIDGT,omitempty"`
+	TargetIdgte   *string  `json:"targetIDGTE,omitempty"`
+	TargetIdlt    *string  `json:"targetIDLT,omitempty"`
+	TargetIdlte   *string  `json:"targetIDLTE,omitempty"`
+	// target_user_id field predicates
+	TargetUserID       *string  `json:"targetUserID,omitempty"`
+	TargetUserIdneq    *string  `json:"targetUserIDNEQ,omitempty"`
+	TargetUserIDIn     []string `json:"targetUserIDIn,omitempty"`
+	TargetUserIDNotIn  []string `json:"targetUserIDNotIn,omitempty"`
+	TargetUserIDIsNil  *bool    `json:"targetUserIDIsNil,omitempty"`
+	TargetUserIDNotNil *bool    `json:"targetUserIDNotNil,omitempty"`
+	// community_id field predicates
+	CommunityID       *string  `json:"communityID,omitempty"`
+	CommunityIdneq    *string  `json:"communityIDNEQ,omitempty"`
+	CommunityIDIn     []string `json:"communityIDIn,omitempty"`
+	CommunityIDNotIn  []string `json:"communityIDNotIn,omitempty"`
+	CommunityIDIsNil  *bool    `json:"communityIDIsNil,omitempty"`
+	CommunityIDNotNil *bool    `json:"communityIDNotNil,omitempty"`
+	// queue field predicates
+	Queue      *report.Queue  `json:"queue,omitempty"`
+	QueueNeq   *report.Queue  `json:"queueNEQ,omitempty"`
+	QueueIn    []report.Queue `json:"queueIn,omitempty"`
+	QueueNotIn []report.Queue `json:"queueNotIn,omitempty"`
+	// reason field predicates
+	Reason      *report.Reason  `json:"reason,omitempty"`
+	ReasonNeq   *report.Reason  `json:"reasonNEQ,omitempty"`
+	ReasonIn    []report.Reason `json:"reasonIn,omitempty"`
+	ReasonNotIn []report.Reason `json:"reasonNotIn,omitempty"`
+	// community_rule_id field predicates
+	CommunityRuleID       *string  `json:"communityRuleID,omitempty"`
+	CommunityRuleIdneq    *string  `json:"communityRuleIDNEQ,omitempty"`
+	CommunityRuleIDIn     []string `json:"communityRuleIDIn,omitempty"`
+	CommunityRuleIDNotIn  []string `json:"communityRuleIDNotIn,omitempty"`
+	CommunityRuleIDIsNil  *bool    `json:"communityRuleIDIsNil,omitempty"`
+	CommunityRuleIDNotNil *bool    `json:"communityRuleIDNotNil,omitempty"`
+	// host_rule_id field predicates
+	HostRuleID       *string  `json:"hostRuleID,omitempty"`
+	HostRuleIdneq    *string  `json:"hostRuleIDNEQ,omitempty"`
+	HostRuleIDIn     []string `json:"hostRuleIDIn,omitempty"`
+	HostRuleIDNotIn  []string `json:"hostRuleIDNotIn,omitempty"`
+	HostRuleIDIsNil  *bool    `json:"hostRuleIDIsNil,omitempty"`
+	HostRuleIDNotNil *bool    `json:"hostRuleIDNotNil,omitempty"`
+	// details field predicates
+	Details             *string  `json:"details,omitempty"`
+	DetailsNeq          *string  `json:"detailsNEQ,omitempty"`
+	DetailsIn           []string `json:"detailsIn,omitempty"`
+	DetailsNotIn        []string `json:"detailsNotIn,omitempty"`
+	DetailsGt           *string  `json:"detailsGT,omitempty"`
+	DetailsGte          *string  `json:"detailsGTE,omitempty"`
+	DetailsLt           *string  `json:"detailsLT,omitempty"`
+	DetailsLte          *string  `json:"detailsLTE,omitempty"`
+	DetailsContains     *string  `json:"detailsContains,omitempty"`
+	DetailsHasPrefix    *string  `json:"detailsHasPrefix,omitempty"`
+	DetailsHasSuffix    *string  `json:"detailsHasSuffix,omitempty"`
+	DetailsIsNil        *bool    `json:"detailsIsNil,omitempty"`
+	DetailsNotNil       *bool    `json:"detailsNotNil,omitempty"`
+	DetailsEqualFold    *string  `json:"detailsEqualFold,omitempty"`
+	DetailsContainsFold *string  `json:"detailsContainsFold,omitempty"`
+	// status field predicates
+	Status      *report.Status  `json:"status,omitempty"`
+	StatusNeq   *report.Status  `json:"statusNEQ,omitempty"`
+	StatusIn    []report.Status `json:"statusIn,omitempty"`
+	StatusNotIn []report.Status `json:"statusNotIn,omitempty"`
+	// duplicate_of_id field predicates
+	DuplicateOfID       *string  `json:"duplicateOfID,omitempty"`
+	DuplicateOfIdneq    *string  `json:"duplicateOfIDNEQ,omitempty"`
+	DuplicateOfIDIn     []string `json:"duplicateOfIDIn,omitempty"`
+	DuplicateOfIDNotIn  []string `json:"duplicateOfIDNotIn,omitempty"`
+	DuplicateOfIDIsNil  *bool    `json:"duplicateOfIDIsNil,omitempty"`
+	DuplicateOfIDNotNil *bool    `json:"duplicateOfIDNotNil,omitempty"`
+	// resolved_by_id field predicates
+	ResolvedByID       *string  `json:"resolvedByID,omitempty"`
+	ResolvedByIdneq    *string  `json:"resolvedByIDNEQ,omitempty"`
+	ResolvedByIDIn     []string `json:"resolvedByIDIn,omitempty"`
+	ResolvedByIDNotIn  []string `json:"resolvedByIDNotIn,omitempty"`
+	ResolvedByIDIsNil  *bool    `json:"resolvedByIDIsNil,omitempty"`
+	ResolvedByIDNotNil *bool    `json:"resolvedByIDNotNil,omitempty"`
+	// resolution_note field predicates
+	ResolutionNote             *string  `json:"resolutionNote,omitempty"`
+	ResolutionNoteNeq          *string  `json:"resolutionNoteNEQ,omitempty"`
+	ResolutionNoteIn           []string `json:"resolutionNoteIn,omitempty"`
+	ResolutionNoteNotIn        []string `json:"resolutionNoteNotIn,omitempty"`
+	ResolutionNoteGt           *string  `json:"resolutionNoteGT,omitempty"`
+	ResolutionNoteGte          *string  `json:"resolutionNoteGTE,omitempty"`
+	ResolutionNoteLt           *string  `json:"resolutionNoteLT,omitempty"`
+	ResolutionNoteLte          *string  `json:"resolutionNoteLTE,omitempty"`
+	ResolutionNoteContains     *string  `json:"resolutionNoteContains,omitempty"`
+	ResolutionNoteHasPrefix    *string  `json:"resolutionNoteHasPrefix,omitempty"`
+	ResolutionNoteHasSuffix    *string  `json:"resolutionNoteHasSuffix,omitempty"`
+	ResolutionNoteIsNil        *bool    `json:"resolutionNoteIsNil,omitempty"`
+	ResolutionNoteNotNil       *bool    `json:"resolutionNoteNotNil,omitempty"`
+	ResolutionNoteEqualFold    *string  `json:"resolutionNoteEqualFold,omitempty"`
+	ResolutionNoteContainsFold *string  `json:"resolutionNoteContainsFold,omitempty"`
+	// escalated_at field predicates
+	EscalatedAt       *time.Time   `json:"escalatedAt,omitempty"`
+	EscalatedAtNeq    *time.Time   `json:"escalatedAtNEQ,omitempty"`
+	EscalatedAtIn     []*time.Time `json:"escalatedAtIn,omitempty"`
+	EscalatedAtNotIn  []*time.Time `json:"escalatedAtNotIn,omitempty"`
+	EscalatedAtGt     *time.Time   `json:"escalatedAtGT,omitempty"`
+	EscalatedAtGte    *time.Time   `json:"escalatedAtGTE,omitempty"`
+	EscalatedAtLt     *time.Time   `json:"escalatedAtLT,omitempty"`
+	EscalatedAtLte    *time.Time   `json:"escalatedAtLTE,omitempty"`
+	EscalatedAtIsNil  *bool        `json:"escalatedAtIsNil,omitempty"`
+	EscalatedAtNotNil *bool        `json:"escalatedAtNotNil,omitempty"`
+	// escalation_note field predicates
+	EscalationNote             *string  `json:"escalationNote,omitempty"`
+	EscalationNoteNeq          *string  `json:"escalationNoteNEQ,omitempty"`
+	EscalationNoteIn           []string `json:"escalationNoteIn,omitempty"`
+	EscalationNoteNotIn        []string `json:"escalationNoteNotIn,omitempty"`
+	EscalationNoteGt           *string  `json:"escalationNoteGT,omitempty"`
+	EscalationNoteGte          *string  `json:"escalationNoteGTE,omitempty"`
+	EscalationNoteLt           *string  `json:"escalationNoteLT,omitempty"`
+	EscalationNoteLte          *string  `json:"escalationNoteLTE,omitempty"`
+	EscalationNoteContains     *string  `json:"escalationNoteContains,omitempty"`
+	EscalationNoteHasPrefix    *string  `json:"escalationNoteHasPrefix,omitempty"`
+	EscalationNoteHasSuffix    *string  `json:"escalationNoteHasSuffix,omitempty"`
+	EscalationNoteIsNil        *bool    `json:"escalationNoteIsNil,omitempty"`
+	EscalationNoteNotNil       *bool    `json:"escalationNoteNotNil,omitempty"`
+	EscalationNoteEqualFold    *string  `json:"escalationNoteEqualFold,omitempty"`
+	EscalationNoteContainsFold *string  `json:"escalationNoteContainsFold,omitempty"`
+	// resolved_at field predicates
+	ResolvedAt       *time.Time   `json:"resolvedAt,omitempty"`
+	ResolvedAtNeq    *time.Time   `json:"resolvedAtNEQ,omitempty"`
+	ResolvedAtIn     []*time.Time `json:"resolvedAtIn,omitempty"`
+	ResolvedAtNotIn  []*time.Time `json:"resolvedAtNotIn,omitempty"`
+	ResolvedAtGt     *time.Time   `json:"resolvedAtGT,omitempty"`
+	ResolvedAtGte    *time.Time   `json:"resolvedAtGTE,omitempty"`
+	ResolvedAtLt     *time.Time   `json:"resolvedAtLT,omitempty"`
+	ResolvedAtLte    *time.Time   `json:"resolvedAtLTE,omitempty"`
+	ResolvedAtIsNil  *bool        `json:"resolvedAtIsNil,omitempty"`
+	ResolvedAtNotNil *bool        `json:"resolvedAtNotNil,omitempty"`
+	// created_at field predicates
+	CreatedAt      *time.Time   `json:"createdAt,omitempty"`
+	CreatedAtNeq   *time.Time   `json:"createdAtNEQ,omitempty"`
+	CreatedAtIn    []*time.Time `json:"createdAtIn,omitempty"`
+	CreatedAtNotIn []*time.Time `json:"createdAtNotIn,omitempty"`
+	CreatedAtGt    *time.Time   `json:"createdAtGT,omitempty"`
+	CreatedAtGte   *time.Time   `json:"createdAtGTE,omitempty"`
+	CreatedAtLt    *time.Time   `json:"createdAtLT,omitempty"`
+	CreatedAtLte   *time.Time   `json:"createdAtLTE,omitempty"`
+	// updated_at field predicates
+	UpdatedAt      *time.Time   `json:"updatedAt,omitempty"`
+	UpdatedAtNeq   *time.Time   `json:"updatedAtNEQ,omitempty"`
+	UpdatedAtIn    []*time.Time `json:"updatedAtIn,omitempty"`
+	UpdatedAtNotIn []*time.Time `json:"updatedAtNotIn,omitempty"`
+	UpdatedAtGt    *time.Time   `json:"updatedAtGT,omitempty"`
+	UpdatedAtGte   *time.Time   `json:"updatedAtGTE,omitempty"`
+	UpdatedAtLt    *time.Time   `json:"updatedAtLT,omitempty"`
+	UpdatedAtLte   *time.Time   `json:"updatedAtLTE,omitempty"`
+	// reporter edge predicates
+	HasReporter     *bool             `json:"hasReporter,omitempty"`
+	HasReporterWith []*UserWhereInput `json:"hasReporterWith,omitempty"`
+	// automod_rule edge predicates
+	HasAutomodRule     *bool                    `json:"hasAutomodRule,omitempty"`
+	HasAutomodRuleWith []*AutomodRuleWhereInput `json:"hasAutomodRuleWith,omitempty"`
+	// target_user edge predicates
+	HasTargetUser     *bool             `json:"hasTargetUser,omitempty"`
+	HasTargetUserWith []*UserWhereInput `json:"hasTargetUserWith,omitempty"`
+	// community edge predicates
+	HasCommunity     *bool                  `json:"hasCommunity,omitempty"`
+	HasCommunityWith []*CommunityWhereInput `json:"hasCommunityWith,omitempty"`
+	// community_rule edge predicates
+	HasCommunityRule     *bool                      `json:"hasCommunityRule,omitempty"`
+	HasCommunityRuleWith []*CommunityRuleWhereInput `json:"hasCommunityRuleWith,omitempty"`
+	// host_rule edge predicates
+	HasHostRule     *bool                 `json:"hasHostRule,omitempty"`
+	HasHostRuleWith []*HostRuleWhereInput `json:"hasHostRuleWith,omitempty"`
+	// resolved_by edge predicates
+	HasResolvedBy     *bool             `json:"hasResolvedBy,omitempty"`
+	HasResolvedByWith []*UserWhereInput `json:"hasResolvedByWith,omitempty"`
+	// duplicate_of edge predicates
+	HasDuplicateOf     *bool               `json:"hasDuplicateOf,omitempty"`
+	HasDuplicateOfWith []*ReportWhereInput `json:"hasDuplicateOfWith,omitempty"`
+	// duplicates edge predicates
+	HasDuplicates     *bool               `json:"hasDuplicates,omitempty"`
+	HasDuplicatesWith []*ReportWhereInput `json:"hasDuplicatesWith,omitempty"`
+}
+
+type ReportsConnection struct {
+	Edges    []*ReportEdge `json:"edges"`
+	PageInfo *PageInfo     `json:"pageInfo"`
+}
+
 type ResendVerifyEmailInput struct {
 	Email string `json:"email"`
 }
@@ -2144,24 +3526,15 @@ type RoleWhereInput struct {
 	CommunityIdneq   *string  `json:"communityIDNEQ,omitempty"`
 	CommunityIDIn    []string `json:"communityIDIn,omitempty"`
 	CommunityIDNotIn []string `json:"communityIDNotIn,omitempty"`
-	// community_roles_management field predicates
-	CommunityRolesManagement    *bool `json:"communityRolesManagement,omitempty"`
-	CommunityRolesManagementNeq *bool `json:"communityRolesManagementNEQ,omitempty"`
-	// community_user_ban field predicates
-	CommunityUserBan    *bool `json:"communityUserBan,omitempty"`
-	CommunityUserBanNeq *bool `json:"communityUserBanNEQ,omitempty"`
-	// community_user_mute field predicates
-	CommunityUserMute    *bool `json:"communityUserMute,omitempty"`
-	CommunityUserMuteNeq *bool `json:"communityUserMuteNEQ,omitempty"`
-	// community_delete_post field predicates
-	CommunityDeletePost    *bool `json:"communityDeletePost,omitempty"`
-	CommunityDeletePostNeq *bool `json:"communityDeletePostNEQ,omitempty"`
-	// community_remove_post_from_publication field predicates
-	CommunityRemovePostFromPublication    *bool `json:"communityRemovePostFromPublication,omitempty"`
-	CommunityRemovePostFromPublicationNeq *bool `json:"communityRemovePostFromPublicationNEQ,omitempty"`
-	// community_delete_comments field predicates
-	CommunityDeleteComments    *bool `json:"communityDeleteComments,omitempty"`
-	CommunityDeleteCommentsNeq *bool `json:"communityDeleteCommentsNEQ,omitempty"`
+	// position field predicates
+	Position      *int32  `json:"position,omitempty"`
+	PositionNeq   *int32  `json:"positionNEQ,omitempty"`
+	PositionIn    []int32 `json:"positionIn,omitempty"`
+	PositionNotIn []int32 `json:"positionNotIn,omitempty"`
+	PositionGt    *int32  `json:"positionGT,omitempty"`
+	PositionGte   *int32  `json:"positionGTE,omitempty"`
+	PositionLt    *int32  `json:"positionLT,omitempty"`
+	PositionLte   *int32  `json:"positionLTE,omitempty"`
 	// created_at field predicates
 	CreatedAt      *time.Time   `json:"createdAt,omitempty"`
 	CreatedAtNeq   *time.Time   `json:"createdAtNEQ,omitempty"`
@@ -2191,9 +3564,25 @@ type RoleWhereInput struct {
 	HasUsersWith []*UserWhereInput `json:"hasUsersWith,omitempty"`
 }
 
+type Sanction struct {
+	ID         string         `json:"id"`
+	Kind       SanctionKind   `json:"kind"`
+	Community  *ent.Community `json:"community,omitempty"`
+	Reason     string         `json:"reason"`
+	PublicNote string         `json:"publicNote"`
+	ExpiresAt  *time.Time     `json:"expiresAt,omitempty"`
+	CreatedAt  time.Time      `json:"createdAt"`
+}
+
 type Subscription struct {
 }
 
+type ThreadPresence struct {
+	PostID  string      `json:"postId"`
+	Readers int32       `json:"readers"`
+	Typing  []*ent.User `json:"typing"`
+}
+
 type UnfollowCommunityInput struct {
 	CommunityID string `json:"communityID"`
 }
@@ -2210,6 +3599,18 @@ type UnlikePostInput struct {
 	PostID string `json:"postID"`
 }
 
+type UpdateAutomodRuleInput struct {
+	ID            string              `json:"id"`
+	Title         *string             `json:"title,omitempty"`
+	Patterns      []string            `json:"patterns,omitempty"`
+	Threshold     *int32              `json:"threshold,omitempty"`
+	WindowMinutes *int32              `json:"windowMinutes,omitempty"`
+	Action        *automodrule.Action `json:"action,omitempty"`
+	MuteMinutes   *int32              `json:"muteMinutes,omitempty"`
+	Message       *string             `json:"message,omitempty"`
+	Enabled       *bool               `json:"enabled,omitempty"`
+}
+
 type UpdateCommentInput struct {
 	ID         string  `json:"id"`
 	Content    *string `json:"content,omitempty"`
@@ -2228,17 +3629,14 @@ type UpdateCommunityInput struct {
 }
 
 type UpdateCommunityRoleInput struct {
-	ID                                 string   `json:"id"`
-	Title                              *string  `json:"title,omitempty"`
-	Color                              *string  `json:"color,omitempty"`
-	BadgeID                            *string  `json:"badgeID,omitempty"`
-	CommunityRolesManagement           *bool    `json:"communityRolesManagement,omitempty"`
-	CommunityUserBan                   *bool    `json:"communityUserBan,omitempty"`
-	CommunityUserMute                  *bool    `json:"communityUserMute,omitempty"`
-	CommunityDeletePost                *bool    `json:"communityDeletePost,omitempty"`
-	CommunityDeleteComments            *bool    `json:"communityDeleteComments,omitempty"`
-	CommunityRemovePostFromPublication *bool    `json:"communityRemovePostFromPublication,omitempty"`
-	UserIDs                            []string `json:"userIDs,omitempty"`
+	ID            string   `json:"id"`
+	Title         *string  `json:"title,omitempty"`
+	Color         *string  `json:"color,omitempty"`
+	BadgeID       *string  `json:"badgeID,omitempty"`
+	Permissions   []string `json:"permissions,omitempty"`
+	Position      *int32   `json:"position,omitempty"`
+	DeniedActions []string `json:"deniedActions,omitempty"`
+	UserIDs       []string `json:"userIDs,omitempty"`
 }
 
 type UpdateCommunityRuleInput struct {
@@ -2259,17 +3657,14 @@ type UpdateHostInput struct {
 }
 
 type UpdateHostRoleInput struct {
-	ID                                     string   `json:"id"`
-	Title                                  *string  `json:"title,omitempty"`
-	Color                                  *string  `json:"color,omitempty"`
-	BadgeID                                *string  `json:"badgeID,omitempty"`
-	CommunityRolesManagement               *bool    `json:"communityRolesManagement,omitempty"`
-	HostUserBan                            *bool    `json:"hostUserBan,omitempty"`
-	HostUserMute                           *bool    `json:"hostUserMute,omitempty"`
-	HostCommunityDeletePost                *bool    `json:"hostCommunityDeletePost,omitempty"`
-	HostCommunityRemovePostFromPublication *bool    `json:"hostCommunityRemovePostFromPublication,omitempty"`
-	HostCommunityDeleteComments            *bool    `json:"hostCommunityDeleteComments,omitempty"`
-	UserIDs                                []string `json:"userIDs,omitempty"`
+	ID            string   `json:"id"`
+	Title         *string  `json:"title,omitempty"`
+	Color         *string  `json:"color,omitempty"`
+	BadgeID       *string  `json:"badgeID,omitempty"`
+	Permissions   []string `json:"permissions,omitempty"`
+	Position      *int32   `json:"position,omitempty"`
+	DeniedActions []string `json:"deniedActions,omitempty"`
+	UserIDs       []string `json:"userIDs,omitempty"`
 }
 
 type UpdateHostRuleInput struct {
@@ -2287,6 +3682,14 @@ type UpdateHostSocialNavigationInput struct {
 	Mastodon  *string `json:"mastodon,omitempty"`
 }
 
+type UpdateNotificationSettingsInput struct {
+	EmailFrequency  *notificationsettings.EmailFrequency `json:"emailFrequency,omitempty"`
+	Timezone        *string                              `json:"timezone,omitempty"`
+	QuietHours      *QuietHoursInput                     `json:"quietHours,omitempty"`
+	ClearQuietHours *bool                                `json:"clearQuietHours,omitempty"`
+	Preferences     []*NotificationPreferenceInput       `json:"preferences,omitempty"`
+}
+
 type UpdatePostInput struct {
 	ID          string           `json:"id"`
 	Title       *string          `json:"title,omitempty"`
@@ -2330,6 +3733,11 @@ type UserCommunityRoleResponse struct {
 	CommunityRemovePostFromPublication bool   `json:"communityRemovePostFromPublication"`
 }
 
+type UserEdge struct {
+	Cursor string    `json:"cursor"`
+	Node   *ent.User `json:"node"`
+}
+
 type UserFollow struct {
 	ID         string    `json:"id"`
 	FollowerID string    `json:"followerID"`
@@ -2431,6 +3839,12 @@ type UserResponse struct {
 	UpdatedAt        string                       `json:"updatedAt"`
 }
 
+type UserStats struct {
+	UserID         string `json:"userId"`
+	FollowersCount int32  `json:"followersCount"`
+	FollowingCount int32  `json:"followingCount"`
+}
+
 type UserStatus struct {
 	FollowersCount string `json:"followersCount"`
 	FollowingCount string `json:"followingCount"`
@@ -2604,6 +4018,12 @@ type UserWhereInput struct {
 	HasEmailVerificationsWith []*EmailVerificationWhereInput `json:"hasEmailVerificationsWith,omitempty"`
 }
 
+type UsersConnection struct {
+	Edges      []*UserEdge `json:"edges"`
+	PageInfo   *PageInfo   `json:"pageInfo"`
+	TotalCount int32       `json:"totalCount"`
+}
+
 type VerifyEmailInput struct {
 	Token string `json:"token"`
 }
@@ -2612,6 +4032,61 @@ type VerifyEmailResponse struct {
 	Message string `json:"message"`
 }
 
+type CacheControlScope string
+
+const (
+	CacheControlScopePublic  CacheControlScope = "PUBLIC"
+	CacheControlScopePrivate CacheControlScope = "PRIVATE"
+)
+
+var AllCacheControlScope = []CacheControlScope{
+	CacheControlScopePublic,
+	CacheControlScopePrivate,
+}
+
+func (e CacheControlScope) IsValid() bool {
+	switch e {
+	case CacheControlScopePublic, CacheControlScopePrivate:
+		return true
+	}
+	return false
+}
+
+func (e CacheControlScope) String() string {
+	return string(e)
+}
+
+func (e *CacheControlScope) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = CacheControlScope(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid CacheControlScope", str)
+	}
+	return nil
+}
+
+func (e CacheControlScope) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *CacheControlScope) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e CacheControlScope) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
 type CommunityFollowersFilter string
 
 const (
@@ -2671,6 +4146,132 @@ func (e CommunityFollowersFilter) MarshalJSON() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+type ModerationEventType string
+
+const (
+	ModerationEventTypeUserBanned      ModerationEventType = "user_banned"
+	ModerationEventTypeUserUnbanned    ModerationEventType = "user_unbanned"
+	ModerationEventTypeUserMuted       ModerationEventType = "user_muted"
+	ModerationEventTypeUserUnmuted     ModerationEventType = "user_unmuted"
+	ModerationEventTypePostUnpublished ModerationEventType = "post_unpublished"
+)
+
+var AllModerationEventType = []ModerationEventType{
+	ModerationEventTypeUserBanned,
+	ModerationEventTypeUserUnbanned,
+	ModerationEventTypeUserMuted,
+	ModerationEventTypeUserUnmuted,
+	ModerationEventTypePostUnpublished,
+}
+
+func (e ModerationEventType) IsValid() bool {
+	switch e {
+	case ModerationEventTypeUserBanned, ModerationEventTypeUserUnbanned, ModerationEventTypeUserMuted, ModerationEventTypeUserUnmuted, ModerationEventTypePostUnpublished:
+		return true
+	}
+	return false
+}
+
+func (e ModerationEventType) String() string {
+	return string(e)
+}
+
+func (e *ModerationEventType) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ModerationEventType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ModerationEventType", str)
+	}
+	return nil
+}
+
+func (e ModerationEventType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ModerationEventType) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ModerationEventType) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type NotificationPreferenceType string
+
+const (
+	NotificationPreferenceTypeCommentReply  NotificationPreferenceType = "comment_reply"
+	NotificationPreferenceTypeMention       NotificationPreferenceType = "mention"
+	NotificationPreferenceTypeUserFollow    NotificationPreferenceType = "user_follow"
+	NotificationPreferenceTypePostLike      NotificationPreferenceType = "post_like"
+	NotificationPreferenceTypeCommentLike   NotificationPreferenceType = "comment_like"
+	NotificationPreferenceTypeCommunityPost NotificationPreferenceType = "community_post"
+	NotificationPreferenceTypeModeration    NotificationPreferenceType = "moderation"
+)
+
+var AllNotificationPreferenceType = []NotificationPreferenceType{
+	NotificationPreferenceTypeCommentReply,
+	NotificationPreferenceTypeMention,
+	NotificationPreferenceTypeUserFollow,
+	NotificationPreferenceTypePostLike,
+	NotificationPreferenceTypeCommentLike,
+	NotificationPreferenceTypeCommunityPost,
+	NotificationPreferenceTypeModeration,
+}
+
+func (e NotificationPreferenceType) IsValid() bool {
+	switch e {
+	case NotificationPreferenceTypeCommentReply, NotificationPreferenceTypeMention, NotificationPreferenceTypeUserFollow, NotificationPreferenceTypePostLike, NotificationPreferenceTypeCommentLike, NotificationPreferenceTypeCommunityPost, NotificationPreferenceTypeModeration:
+		return true
+	}
+	return false
+}
+
+func (e NotificationPreferenceType) String() string {
+	return string(e)
+}
+
+func (e *NotificationPreferenceType) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = NotificationPreferenceType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid NotificationPreferenceType", str)
+	}
+	return nil
+}
+
+func (e NotificationPreferenceType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *NotificationPreferenceType) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e NotificationPreferenceType) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
 // Possible directions in which to order a list of items when provided an `orderBy` argument.
 type OrderDirection string
 
@@ -2728,3 +4329,174 @@ func (e OrderDirection) MarshalJSON() ([]byte, error) {
 	e.MarshalGQL(&buf)
 	return buf.Bytes(), nil
 }
+
+type PermissionScope string
+
+const (
+	PermissionScopeHost      PermissionScope = "HOST"
+	PermissionScopeCommunity PermissionScope = "COMMUNITY"
+)
+
+var AllPermissionScope = []PermissionScope{
+	PermissionScopeHost,
+	PermissionScopeCommunity,
+}
+
+func (e PermissionScope) IsValid() bool {
+	switch e {
+	case PermissionScopeHost, PermissionScopeCommunity:
+		return true
+	}
+	return false
+}
+
+func (e PermissionScope) String() string {
+	return string(e)
+}
+
+func (e *PermissionScope) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = PermissionScope(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid PermissionScope", str)
+	}
+	return nil
+}
+
+func (e PermissionScope) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *PermissionScope) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e PermissionScope) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type ReportAction string
+
+const (
+	ReportActionRemoveContent ReportAction = "REMOVE_CONTENT"
+	ReportActionBan           ReportAction = "BAN"
+	ReportActionMute          ReportAction = "MUTE"
+)
+
+var AllReportAction = []ReportAction{
+	ReportActionRemoveContent,
+	ReportActionBan,
+	ReportActionMute,
+}
+
+func (e ReportAction) IsValid() bool {
+	switch e {
+	case ReportActionRemoveContent, ReportActionBan, ReportActionMute:
+		return true
+	}
+	return false
+}
+
+func (e ReportAction) String() string {
+	return string(e)
+}
+
+func (e *ReportAction) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ReportAction(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ReportAction", str)
+	}
+	return nil
+}
+
+func (e ReportAction) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ReportAction) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ReportAction) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type SanctionKind string
+
+const (
+	SanctionKindCommunityBan  SanctionKind = "COMMUNITY_BAN"
+	SanctionKindCommunityMute SanctionKind = "COMMUNITY_MUTE"
+	SanctionKindHostBan       SanctionKind = "HOST_BAN"
+	SanctionKindHostMute      SanctionKind = "HOST_MUTE"
+)
+
+var AllSanctionKind = []SanctionKind{
+	SanctionKindCommunityBan,
+	SanctionKindCommunityMute,
+	SanctionKindHostBan,
+	SanctionKindHostMute,
+}
+
+func (e SanctionKind) IsValid() bool {
+	switch e {
+	case SanctionKindCommunityBan, SanctionKindCommunityMute, SanctionKindHostBan, SanctionKindHostMute:
+		return true
+	}
+	return false
+}
+
+func (e SanctionKind) String() string {
+	return string(e)
+}
+
+func (e *SanctionKind) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = SanctionKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid SanctionKind", str)
+	}
+	return nil
+}
+
+func (e SanctionKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *SanctionKind) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e SanctionKind) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,100 @@
+package graphql
+
+import (
+	"context"
+	"log"
+
+	"stormlink/server/ent"
+	"stormlink/server/ent/notification"
+	"stormlink/server/ent/user"
+	"stormlink/server/graphql/models"
+	"stormlink/server/model"
+	notificationuc "stormlink/server/usecase/notification"
+	nsuc "stormlink/server/usecase/notificationsettings"
+)
+
+// notifyEvent — событие для центра уведомлений (см. notificationuc.Event)
+type notifyEvent = notificationuc.Event
+
+// notify создаёт уведомление и доставляет его подписчикам notificationAdded
+// (если получатель не отключил in-app канал и у него не тихие часы).
+// Ошибки только логируются: уведомление не должно ломать основное действие.
+// Действия пользователя под теневой санкцией никого не оповещают.
+func (r *Resolver) notify(ctx context.Context, ev notificationuc.Event) {
+	if ev.ActorID != 0 {
+		hidden, err := r.sanctionChecker().Shadowed(ctx, ev.ActorID, ev.CommunityID)
+		if err != nil {
+			log.Printf("❌ notify %s: shadow check user %d: %v", ev.Type, ev.ActorID, err)
+		}
+		if hidden {
+			return
+		}
+	}
+	n, push, err := r.NotificationUC.Notify(ctx, ev)
+	if err != nil {
+		log.Printf("❌ notify %s -> user %d: %v", ev.Type, ev.RecipientID, err)
+		return
+	}
+	if push {
+		r.publishNotificationAdded(ctx, n)
+	}
+}
+
+// notifyMentions уведомляет упомянутых через @slug пользователей (кроме skip — уже уведомлённых)
+func (r *Resolver) notifyMentions(ctx context.Context, content string, ev notificationuc.Event, skip ...int) {
+	slugs := notificationuc.ParseMentions(content)
+	if len(slugs) == 0 {
+		return
+	}
+	ids, err := r.Client.User.Query().Where(user.SlugIn(slugs...)).IDs(ctx)
+	if err != nil {
+		log.Printf("❌ notify mentions: %v", err)
+		return
+	}
+	skipped := map[int]bool{}
+	for _, id := range skip {
+		skipped[id] = true
+	}
+	for _, id := range ids {
+		if skipped[id] {
+			continue
+		}
+		ev.RecipientID = id
+		ev.Type = notification.TypeMention
+		r.notify(ctx, ev)
+	}
+}
+
+// notifyModeration уведомляет пользователя о применённой к нему мере модерации
+func (r *Resolver) notifyModeration(ctx context.Context, userID, moderatorID int, communityID *int, message string) {
+	r.notify(ctx, notifyEvent{
+		RecipientID: userID,
+		ActorID:     moderatorID,
+		Type:        notification.TypeModeration,
+		CommunityID: communityID,
+		Message:     &message,
+	})
+}
+
+// notificationPreferences разворачивает настройки каналов по всем типам событий с учётом значений по умолчанию
+func notificationPreferences(s *ent.NotificationSettings) []*models.NotificationPreference {
+	out := make([]*models.NotificationPreference, 0, len(model.NotificationTypes))
+	for _, t := range model.NotificationTypes {
+		c := nsuc.Channels(s, t)
+		out = append(out, &models.NotificationPreference{
+			Type:   models.NotificationPreferenceType(t),
+			InApp:  c.InApp,
+			Email:  c.Email,
+			Digest: c.Digest,
+		})
+	}
+	return out
+}
+
+// quietHours отдает тихие часы в формате "HH:MM" (nil — не заданы)
+func quietHours(s *ent.NotificationSettings) *models.QuietHours {
+	if s.QuietHoursStart == nil || s.QuietHoursEnd == nil {
+		return nil
+	}
+	return &models.QuietHours{Start: nsuc.FormatClock(*s.QuietHoursStart), End: nsuc.FormatClock(*s.QuietHoursEnd)}
+}
@@ -0,0 +1,151 @@
+package persisted
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync/atomic"
+	"time"
+
+	redis "github.com/redis/go-redis/v9"
+)
+
+// ManifestKey — Redis-хеш "id операции → текст запроса", куда сборка фронтенда выкладывает манифест
+const ManifestKey = "stormlink:persisted:manifest"
+
+// Manifest — разрешённые операции. Замена содержимого атомарна: запросы во время перезагрузки
+// видят либо старый, либо новый манифест целиком.
+type Manifest struct {
+	data atomic.Pointer[manifestData]
+}
+
+type manifestData struct {
+	byID   map[string]string
+	hashes map[string]struct{}
+}
+
+// NewManifest создает манифест из пар "id → запрос"
+func NewManifest(ops map[string]string) *Manifest {
+	m := &Manifest{}
+	m.Replace(ops)
+	return m
+}
+
+// Replace подменяет список разрешённых операций
+func (m *Manifest) Replace(ops map[string]string) {
+	d := &manifestData{byID: make(map[string]string, len(ops)), hashes: make(map[string]struct{}, len(ops))}
+	for id, query := range ops {
+		d.byID[id] = query
+		d.hashes[Hash(query)] = struct{}{}
+	}
+	m.data.Store(d)
+}
+
+// Lookup отдает текст запроса по id операции (sha256Hash из расширения persistedQuery)
+func (m *Manifest) Lookup(id string) (string, bool) {
+	q, ok := m.data.Load().byID[id]
+	return q, ok
+}
+
+// Allowed сообщает, есть ли текст запроса в манифесте
+func (m *Manifest) Allowed(query string) bool {
+	_, ok := m.data.Load().hashes[Hash(query)]
+	return ok
+}
+
+func (m *Manifest) Len() int { return len(m.data.Load().byID) }
+
+// Hash — sha256 запроса в hex, как в APQ
+func Hash(query string) string {
+	b := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(b[:])
+}
+
+// ParseManifest разбирает манифест сборки фронтенда. Поддерживаются два формата:
+// объект {"<sha256>": "<query>"} (graphql-codegen persistedDocuments) и
+// {"format": "apollo-persisted-query-manifest", "operations": [{"id", "body"}]}.
+func ParseManifest(data []byte) (map[string]string, error) {
+	var apollo struct {
+		Format     string `json:"format"`
+		Operations []struct {
+			ID   string `json:"id"`
+			Body string `json:"body"`
+		} `json:"operations"`
+	}
+	if err := json.Unmarshal(data, &apollo); err == nil && apollo.Format != "" {
+		ops := make(map[string]string, len(apollo.Operations))
+		for _, op := range apollo.Operations {
+			if op.ID == "" || op.Body == "" {
+				return nil, fmt.Errorf("manifest operation without id or body")
+			}
+			ops[op.ID] = op.Body
+		}
+		return ops, nil
+	}
+
+	var ops map[string]string
+	if err := json.Unmarshal(data, &ops); err != nil {
+		return nil, fmt.Errorf("invalid persisted query manifest: %w", err)
+	}
+	// В этом формате ключ — хеш запроса: расхождение значит, что манифест собран другим алгоритмом
+	for id, query := range ops {
+		if Hash(query) != id {
+			return nil, fmt.Errorf("manifest hash %s does not match its query", id)
+		}
+	}
+	return ops, nil
+}
+
+// Source загружает актуальный манифест
+type Source func(ctx context.Context) (map[string]string, error)
+
+// FileSource читает манифест из файла
+func FileSource(path string) Source {
+	return func(context.Context) (map[string]string, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return ParseManifest(data)
+	}
+}
+
+// RedisSource читает манифест из Redis-хеша key
+func RedisSource(rdb *redis.Client, key string) Source {
+	return func(ctx context.Context) (map[string]string, error) {
+		ops, err := rdb.HGetAll(ctx, key).Result()
+		if err != nil {
+			return nil, err
+		}
+		if len(ops) == 0 {
+			return nil, fmt.Errorf("persisted query manifest %s is empty", key)
+		}
+		return ops, nil
+	}
+}
+
+// Watch перечитывает манифест каждые every, пока не отменён ctx. Ошибка загрузки оставляет прежний манифест.
+func (m *Manifest) Watch(ctx context.Context, src Source, every time.Duration) {
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			ops, err := src(ctx)
+			if err != nil {
+				log.Printf("⚠️ persisted query manifest reload: %v", err)
+				continue
+			}
+			if len(ops) != m.Len() {
+				log.Printf("🔄 persisted query manifest: %d operations", len(ops))
+			}
+			m.Replace(ops)
+		}
+	}
+}
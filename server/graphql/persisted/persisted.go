@@ -0,0 +1,153 @@
+package persisted
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	gqlgen "github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	redis "github.com/redis/go-redis/v9"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	redisx "stormlink/shared/redis"
+)
+
+const (
+	errNotAllowed     = "PersistedQueryNotAllowed"
+	errNotAllowedCode = "PERSISTED_QUERY_NOT_ALLOWED"
+
+	// manifestRefresh — как часто реплики перечитывают манифест из Redis
+	manifestRefresh = 30 * time.Second
+)
+
+// Allowlist — строгий режим: выполняются только операции из манифеста.
+// Клиент может прислать id операции (extensions.persistedQuery.sha256Hash) или полный текст запроса;
+// неизвестный id и текст вне манифеста отклоняются. APQ в этом режиме не регистрирует новые запросы.
+type Allowlist struct {
+	Manifest *Manifest
+}
+
+var _ interface {
+	gqlgen.OperationParameterMutator
+	gqlgen.HandlerExtension
+} = Allowlist{}
+
+func (Allowlist) ExtensionName() string { return "PersistedQueryAllowlist" }
+
+func (a Allowlist) Validate(gqlgen.ExecutableSchema) error {
+	if a.Manifest == nil {
+		return errors.New("Allowlist.Manifest can not be nil")
+	}
+	return nil
+}
+
+func (a Allowlist) MutateOperationParameters(ctx context.Context, rawParams *gqlgen.RawParams) *gqlerror.Error {
+	if ext := rawParams.Extensions["persistedQuery"]; ext != nil {
+		pq, _ := ext.(map[string]any)
+		id, _ := pq["sha256Hash"].(string)
+		if id == "" {
+			return gqlerror.Errorf("invalid persisted query extension data")
+		}
+		query, ok := a.Manifest.Lookup(id)
+		if !ok {
+			return notAllowed()
+		}
+		if rawParams.Query != "" && rawParams.Query != query {
+			return gqlerror.Errorf("provided persisted query hash does not match query")
+		}
+		rawParams.Query = query
+		return nil
+	}
+	if !a.Manifest.Allowed(rawParams.Query) {
+		return notAllowed()
+	}
+	return nil
+}
+
+func notAllowed() *gqlerror.Error {
+	err := gqlerror.Errorf(errNotAllowed)
+	errcode.Set(err, errNotAllowedCode)
+	return err
+}
+
+// RedisCache — кеш APQ в Redis, общий для всех реплик
+type RedisCache struct {
+	rdb    *redis.Client
+	prefix string
+	ttl    time.Duration
+}
+
+var _ gqlgen.Cache[string] = (*RedisCache)(nil)
+
+func NewRedisCache(rdb *redis.Client, prefix string, ttl time.Duration) *RedisCache {
+	return &RedisCache{rdb: rdb, prefix: prefix, ttl: ttl}
+}
+
+// Get при ошибке Redis отдает промах: клиент повторит запрос с полным текстом
+func (c *RedisCache) Get(ctx context.Context, key string) (string, bool) {
+	q, err := c.rdb.Get(ctx, c.prefix+key).Result()
+	if err != nil {
+		return "", false
+	}
+	return q, true
+}
+
+func (c *RedisCache) Add(ctx context.Context, key string, value string) {
+	_ = c.rdb.Set(ctx, c.prefix+key, value, c.ttl).Err()
+}
+
+// ExtensionFromEnv настраивает persisted queries из ENV:
+//
+//	GRAPHQL_PERSISTED_QUERIES=apq (по умолчанию) — APQ, кеш запросов GRAPHQL_APQ_CACHE=memory|redis
+//	GRAPHQL_PERSISTED_QUERIES=strict — только операции из манифеста GRAPHQL_PERSISTED_MANIFEST
+//	  (путь к файлу или "redis" — хеш ManifestKey, перечитывается каждые 30 с)
+//
+// Манифест из Redis перечитывается, пока не отменён ctx.
+func ExtensionFromEnv(ctx context.Context) (gqlgen.HandlerExtension, error) {
+	switch mode := os.Getenv("GRAPHQL_PERSISTED_QUERIES"); mode {
+	case "", "apq":
+		switch os.Getenv("GRAPHQL_APQ_CACHE") {
+		case "", "memory":
+			return extension.AutomaticPersistedQuery{Cache: lru.New[string](1000)}, nil
+		case "redis":
+			rdb, err := redisx.NewClient()
+			if err != nil {
+				return nil, err
+			}
+			return extension.AutomaticPersistedQuery{Cache: NewRedisCache(rdb, "stormlink:apq:", 24*time.Hour)}, nil
+		default:
+			return nil, fmt.Errorf("unknown GRAPHQL_APQ_CACHE %q", os.Getenv("GRAPHQL_APQ_CACHE"))
+		}
+	case "strict":
+		var src Source
+		refresh := false
+		switch path := os.Getenv("GRAPHQL_PERSISTED_MANIFEST"); path {
+		case "":
+			return nil, fmt.Errorf("GRAPHQL_PERSISTED_MANIFEST is required in strict mode")
+		case "redis":
+			rdb, err := redisx.NewClient()
+			if err != nil {
+				return nil, err
+			}
+			src, refresh = RedisSource(rdb, ManifestKey), true
+		default:
+			src = FileSource(path)
+		}
+		ops, err := src(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("load persisted query manifest: %w", err)
+		}
+		m := NewManifest(ops)
+		if refresh {
+			go m.Watch(ctx, src, manifestRefresh)
+		}
+		return Allowlist{Manifest: m}, nil
+	default:
+		return nil, fmt.Errorf("unknown GRAPHQL_PERSISTED_QUERIES %q", mode)
+	}
+}
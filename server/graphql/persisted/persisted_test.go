@@ -0,0 +1,111 @@
+package persisted
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"stormlink/server/graphql"
+)
+
+const typenameQuery = "{ __typename }"
+
+func TestParseManifest(t *testing.T) {
+	t.Run("hash map", func(t *testing.T) {
+		ops, err := ParseManifest([]byte(`{"` + Hash(typenameQuery) + `": "{ __typename }"}`))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{Hash(typenameQuery): typenameQuery}, ops)
+	})
+
+	t.Run("apollo format", func(t *testing.T) {
+		ops, err := ParseManifest([]byte(`{"format":"apollo-persisted-query-manifest","version":1,
+			"operations":[{"id":"op-1","name":"Typename","type":"query","body":"{ __typename }"}]}`))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"op-1": typenameQuery}, ops)
+	})
+
+	t.Run("hash mismatch", func(t *testing.T) {
+		_, err := ParseManifest([]byte(`{"deadbeef": "{ __typename }"}`))
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		_, err := ParseManifest([]byte(`[1, 2]`))
+		assert.Error(t, err)
+	})
+}
+
+func TestManifest(t *testing.T) {
+	m := NewManifest(map[string]string{"op-1": typenameQuery})
+	q, ok := m.Lookup("op-1")
+	assert.True(t, ok)
+	assert.Equal(t, typenameQuery, q)
+	assert.True(t, m.Allowed(typenameQuery))
+	assert.False(t, m.Allowed("{ me { id } }"))
+
+	m.Replace(map[string]string{"op-2": "{ me { id } }"})
+	_, ok = m.Lookup("op-1")
+	assert.False(t, ok)
+	assert.True(t, m.Allowed("{ me { id } }"))
+	assert.Equal(t, 1, m.Len())
+}
+
+func TestAllowlist(t *testing.T) {
+	srv := handler.New(graphql.NewExecutableSchema(graphql.Config{Resolvers: &graphql.Resolver{}}))
+	srv.AddTransport(transport.POST{})
+	srv.Use(Allowlist{Manifest: NewManifest(map[string]string{"op-1": typenameQuery})})
+
+	do := func(t *testing.T, body string) map[string]any {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/query", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		var resp map[string]any
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		return resp
+	}
+	errorCode := func(resp map[string]any) string {
+		errs, _ := resp["errors"].([]any)
+		if len(errs) == 0 {
+			return ""
+		}
+		ext, _ := errs[0].(map[string]any)["extensions"].(map[string]any)
+		code, _ := ext["code"].(string)
+		return code
+	}
+
+	t.Run("query text from manifest", func(t *testing.T) {
+		resp := do(t, `{"query": "{ __typename }"}`)
+		assert.Nil(t, resp["errors"])
+		assert.Equal(t, map[string]any{"__typename": "Query"}, resp["data"])
+	})
+
+	t.Run("operation id only", func(t *testing.T) {
+		resp := do(t, `{"extensions": {"persistedQuery": {"version": 1, "sha256Hash": "op-1"}}}`)
+		assert.Nil(t, resp["errors"])
+		assert.Equal(t, map[string]any{"__typename": "Query"}, resp["data"])
+	})
+
+	t.Run("query outside manifest", func(t *testing.T) {
+		resp := do(t, `{"query": "{ __schema { types { name } } }"}`)
+		assert.Equal(t, errNotAllowedCode, errorCode(resp))
+	})
+
+	t.Run("unknown operation id", func(t *testing.T) {
+		resp := do(t, `{"extensions": {"persistedQuery": {"version": 1, "sha256Hash": "op-2"}}}`)
+		assert.Equal(t, errNotAllowedCode, errorCode(resp))
+	})
+
+	t.Run("id with a different query", func(t *testing.T) {
+		resp := do(t, `{"query": "{ __schema { types { name } } }", "extensions": {"persistedQuery": {"version": 1, "sha256Hash": "op-1"}}}`)
+		assert.NotEmpty(t, resp["errors"])
+	})
+}
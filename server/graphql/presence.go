@@ -0,0 +1,54 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+
+	"stormlink/server/ent"
+	"stormlink/server/ent/post"
+	"stormlink/server/ent/user"
+	"stormlink/server/graphql/models"
+	"stormlink/shared/auth"
+	"stormlink/shared/presence"
+)
+
+// threadPost отдает пост, обсуждение которого доступно зрителю: опубликованный или свой
+func (r *Resolver) threadPost(ctx context.Context, postID string) (*ent.Post, int, error) {
+	pid, err := strconv.Atoi(postID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid postID: %w", err)
+	}
+	viewerID, _ := auth.UserIDFromContext(ctx)
+	p, err := r.Client.Post.Get(ctx, pid)
+	if err != nil || (p.Visibility != post.VisibilityPublished && p.AuthorID != viewerID) {
+		return nil, 0, fmt.Errorf("post not found")
+	}
+	return p, viewerID, nil
+}
+
+// threadPresence превращает состояния трекера в ThreadPresence, подгружая набирающих ответ пользователей
+func (r *Resolver) threadPresence(ctx context.Context, postID int, states <-chan presence.State) <-chan *models.ThreadPresence {
+	out := make(chan *models.ThreadPresence, 1)
+	go func() {
+		defer close(out)
+		for st := range states {
+			typing := []*ent.User{}
+			if len(st.Typing) > 0 {
+				users, err := r.Client.User.Query().Where(user.IDIn(st.Typing...)).All(ctx)
+				if err != nil {
+					log.Printf("⚠️ presence: load typing users: %v", err)
+				} else {
+					typing = users
+				}
+			}
+			select {
+			case out <- &models.ThreadPresence{PostID: strconv.Itoa(postID), Readers: int32(st.Readers), Typing: typing}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
@@ -1,127 +1,142 @@
 package graphql
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+
 	"stormlink/server/ent"
-	"sync"
+	"stormlink/server/ent/comment"
+	"stormlink/server/ent/post"
+	"stormlink/shared/auth"
+	"stormlink/shared/pubsub"
 )
 
-// Храним для каждой подписки map[subscriberID]chan *ent.Comment
-var (
-  commentAddedSubs   = map[int]map[string]chan *ent.Comment{}
-  commentUpdatedSubs = map[int]map[string]chan *ent.Comment{}
-  // глобальные каналы для общей ленты
-  commentAddedGlobalSubs   = map[string]chan *ent.Comment{}
-  commentUpdatedGlobalSubs = map[string]chan *ent.Comment{}
-  subsMu             = sync.RWMutex{}
-  nextSubID          = 0
+// Топики подписок. Через брокер передаются только ссылки на сущности (subscriptionEvent с ID
+// или небольшие DTO событий): каждая реплика перечитывает данные из БД и проверяет, может ли зритель их видеть.
+const (
+	topicCommentAdded      = "comment.added"
+	topicCommentUpdated    = "comment.updated"
+	topicNotificationAdded = "notification.added"
 )
 
-func subscribeCommentAdded(postID int) (string, <-chan *ent.Comment) {
-  subsMu.Lock()
-  defer subsMu.Unlock()
-  if commentAddedSubs[postID] == nil {
-    commentAddedSubs[postID] = make(map[string]chan *ent.Comment)
-  }
-  id := fmt.Sprintf("add-%d", nextSubID)
-  nextSubID++
-  ch := make(chan *ent.Comment, 1)
-  commentAddedSubs[postID][id] = ch
-  return id, ch
+type subscriptionEvent struct {
+	ID int `json:"id"`
 }
 
-func unsubscribeCommentAdded(postID int, subID string) {
-  subsMu.Lock()
-  defer subsMu.Unlock()
-  delete(commentAddedSubs[postID], subID)
+func postTopic(base string, postID int) string { return fmt.Sprintf("%s.post.%d", base, postID) }
+func userTopic(base string, userID int) string { return fmt.Sprintf("%s.user.%d", base, userID) }
+
+// publishEvent отправляет событие в брокер; ошибки только логируются, чтобы не ломать мутацию
+func (r *Resolver) publishEvent(ctx context.Context, topic string, ev any) {
+	payload, err := json.Marshal(ev)
+	if err == nil {
+		_, err = r.Broker.Publish(ctx, topic, payload)
+	}
+	if err != nil {
+		log.Printf("❌ publish %s: %v", topic, err)
+	}
 }
 
-func publishCommentAdded(postID int, comment *ent.Comment) {
-  subsMu.RLock()
-  defer subsMu.RUnlock()
-  for _, ch := range commentAddedSubs[postID] {
-    select {
-    case ch <- comment:
-    default:
-    }
-  }
-  // также оповещаем глобальных подписчиков
-  for _, ch := range commentAddedGlobalSubs {
-    select {
-    case ch <- comment:
-    default:
-    }
-  }
+func (r *Resolver) publish(ctx context.Context, topic string, id int) {
+	r.publishEvent(ctx, topic, subscriptionEvent{ID: id})
 }
 
-// Глобальная подписка
-func subscribeCommentAddedGlobal() (string, <-chan *ent.Comment) {
-  subsMu.Lock()
-  defer subsMu.Unlock()
-  id := fmt.Sprintf("global-%d", nextSubID)
-  nextSubID++
-  ch := make(chan *ent.Comment, 1)
-  commentAddedGlobalSubs[id] = ch
-  return id, ch
+func (r *Resolver) publishCommentAdded(ctx context.Context, c *ent.Comment) {
+	// Задержанный автомодерацией комментарий появится после проверки
+	if c.Held {
+		return
+	}
+	r.publish(ctx, postTopic(topicCommentAdded, c.PostID), c.ID)
+	// также оповещаем глобальных подписчиков
+	r.publish(ctx, topicCommentAdded, c.ID)
 }
 
-func unsubscribeCommentAddedGlobal(subID string) {
-	subsMu.Lock()
-	defer subsMu.Unlock()
-	delete(commentAddedGlobalSubs, subID)
+func (r *Resolver) publishCommentUpdated(ctx context.Context, c *ent.Comment) {
+	if c.Held {
+		return
+	}
+	r.publish(ctx, postTopic(topicCommentUpdated, c.PostID), c.ID)
+	// всегда оповещаем глобальных подписчиков (в т.ч. при hasDeleted = true)
+	r.publish(ctx, topicCommentUpdated, c.ID)
 }
 
-// Глобальная подписка на обновления комментариев
-func subscribeCommentUpdatedGlobal() (string, <-chan *ent.Comment) {
-	subsMu.Lock()
-	defer subsMu.Unlock()
-	id := fmt.Sprintf("update-global-%d", nextSubID)
-	nextSubID++
-	ch := make(chan *ent.Comment, 1)
-	commentUpdatedGlobalSubs[id] = ch
-	return id, ch
+func (r *Resolver) publishNotificationAdded(ctx context.Context, n *ent.Notification) {
+	r.publish(ctx, userTopic(topicNotificationAdded, n.UserID), n.ID)
 }
 
-func unsubscribeCommentUpdatedGlobal(subID string) {
-	subsMu.Lock()
-	defer subsMu.Unlock()
-	delete(commentUpdatedGlobalSubs, subID)
+// subscribe подписывается на топик событий subscriptionEvent и превращает их в сущности через load
+func subscribe[T any](ctx context.Context, b pubsub.Broker, topic string, since *string, load func(context.Context, int) (T, bool)) (<-chan T, error) {
+	return subscribeEvents(ctx, b, topic, since, func(ctx context.Context, ev subscriptionEvent) (T, bool) {
+		return load(ctx, ev.ID)
+	})
 }
 
-// Функции для подписки на обновления комментариев
-func subscribeCommentUpdated(postID int) (string, <-chan *ent.Comment) {
-	subsMu.Lock()
-	defer subsMu.Unlock()
-	if commentUpdatedSubs[postID] == nil {
-		commentUpdatedSubs[postID] = make(map[string]chan *ent.Comment)
+// subscribeEvents подписывается на топик (с повтором событий после since) и превращает события E в значения T через load.
+// load возвращает false, если сущность удалена или недоступна зрителю — такое событие пропускается.
+// ID события попадает в extensions.eventId сообщения (см. SubscriptionEventIDs).
+func subscribeEvents[E, T any](ctx context.Context, b pubsub.Broker, topic string, since *string, load func(context.Context, E) (T, bool)) (<-chan T, error) {
+	from := ""
+	if since != nil {
+		from = *since
+	}
+	msgs, err := b.Subscribe(ctx, topic, from)
+	if errors.Is(err, pubsub.ErrReplayUnavailable) {
+		return nil, fmt.Errorf("events since %s are no longer available, refetch and subscribe without since", from)
 	}
-	id := fmt.Sprintf("update-%d", nextSubID)
-	nextSubID++
-	ch := make(chan *ent.Comment, 1)
-	commentUpdatedSubs[postID][id] = ch
-	return id, ch
+	if err != nil {
+		return nil, fmt.Errorf("subscribe %s: %w", topic, err)
+	}
+	ids := eventIDsFromContext(ctx)
+	out := make(chan T, 1)
+	go func() {
+		defer close(out)
+		for msg := range msgs {
+			var ev E
+			if err := json.Unmarshal(msg.Payload, &ev); err != nil {
+				log.Printf("❌ subscription %s: bad event: %v", topic, err)
+				continue
+			}
+			v, ok := load(ctx, ev)
+			if !ok {
+				continue
+			}
+			ids.push(msg.ID)
+			select {
+			case out <- v:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
 }
 
-func unsubscribeCommentUpdated(postID int, subID string) {
-	subsMu.Lock()
-	defer subsMu.Unlock()
-	delete(commentUpdatedSubs[postID], subID)
+// loadVisibleComment перечитывает комментарий; комментарии к неопубликованным постам видит только автор поста
+func (r *Resolver) loadVisibleComment(ctx context.Context, id int) (*ent.Comment, bool) {
+	c, err := r.Client.Comment.Query().Where(comment.IDEQ(id)).WithPost().Only(ctx)
+	if err != nil {
+		return nil, false
+	}
+	viewerID, _ := auth.UserIDFromContext(ctx)
+	p := c.Edges.Post
+	if p == nil || (p.Visibility != post.VisibilityPublished && p.AuthorID != viewerID) {
+		return nil, false
+	}
+	return c, true
 }
 
-func publishCommentUpdated(postID int, comment *ent.Comment) {
-	subsMu.RLock()
-	defer subsMu.RUnlock()
-	for _, ch := range commentUpdatedSubs[postID] {
-		select {
-		case ch <- comment:
-		default:
-		}
+// loadOwnNotification перечитывает уведомление, если оно адресовано зрителю
+func (r *Resolver) loadOwnNotification(ctx context.Context, id int) (*ent.Notification, bool) {
+	viewerID, err := auth.UserIDFromContext(ctx)
+	if err != nil || viewerID == 0 {
+		return nil, false
 	}
-	// всегда оповещаем глобальных подписчиков (в т.ч. при hasDeleted = true)
-	for _, ch := range commentUpdatedGlobalSubs {
-		select {
-		case ch <- comment:
-		default:
-		}
+	n, err := r.Client.Notification.Get(ctx, id)
+	if err != nil || n.UserID != viewerID {
+		return nil, false
 	}
+	return n, true
 }
@@ -0,0 +1,137 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"stormlink/server/authz"
+	"stormlink/server/ent"
+	"stormlink/server/ent/notification"
+	"stormlink/server/ent/post"
+	"stormlink/server/ent/report"
+	"stormlink/server/graphql/respcache"
+)
+
+// reportPermission — право на разбор жалоб очереди, в которой лежит жалоба
+func reportPermission(rep *ent.Report) (authz.Action, authz.Resource) {
+	if rep.Queue == report.QueueCommunity && rep.CommunityID != nil {
+		return authz.ManageReports, authz.Community(*rep.CommunityID)
+	}
+	return authz.HostManageReports, authz.Host()
+}
+
+// requireReportModerator пропускает того, кто разбирает очередь жалобы
+func (r *Resolver) requireReportModerator(ctx context.Context, userID int, rep *ent.Report) error {
+	action, res := reportPermission(rep)
+	return r.require(ctx, userID, action, res, "only moderators of the report queue can handle reports")
+}
+
+// moderatedReport отдает основную жалобу по ID, если текущий пользователь разбирает ее очередь
+func (r *Resolver) moderatedReport(ctx context.Context, userID int, id string) (*ent.Report, error) {
+	reportID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid report ID %q: %w", id, err)
+	}
+	rep, err := r.ReportUC.Get(ctx, reportID)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.requireReportModerator(ctx, userID, rep); err != nil {
+		return nil, err
+	}
+	return rep, nil
+}
+
+// closeReport закрывает жалобу с дубликатами и сообщает авторам жалоб решение
+func (r *Resolver) closeReport(ctx context.Context, userID int, rep *ent.Report, status report.Status, note *string) (*ent.Report, error) {
+	held := r.heldTarget(ctx, rep)
+	closed, err := r.ReportUC.Close(ctx, rep.ID, userID, status, note)
+	if err != nil {
+		return nil, err
+	}
+	r.invalidate(ctx, r.targetTags(ctx, rep.TargetType, rep.TargetID)...)
+	// Отклоненная жалоба выпустила задержанную публикацию — оповещаем о ней как о новой
+	if held && status == report.StatusDismissed {
+		r.publishReleased(ctx, rep)
+	}
+	var out *ent.Report
+	for _, c := range closed {
+		if c.ID == rep.ID {
+			out = c
+		}
+		// Жалобам автомодерации сообщать некому
+		if c.ReporterID == nil {
+			continue
+		}
+		msg := reportOutcomeMessage(status, note)
+		r.notify(ctx, notifyEvent{
+			RecipientID: *c.ReporterID,
+			Type:        notification.TypeModeration,
+			CommunityID: c.CommunityID,
+			Message:     &msg,
+		})
+	}
+	return out, nil
+}
+
+// heldTarget сообщает, задержан ли автомодерацией пост или комментарий жалобы
+func (r *Resolver) heldTarget(ctx context.Context, rep *ent.Report) bool {
+	switch rep.TargetType {
+	case report.TargetTypePost:
+		p, err := r.Client.Post.Get(ctx, rep.TargetID)
+		return err == nil && p.Visibility == post.VisibilityHeld
+	case report.TargetTypeComment:
+		c, err := r.Client.Comment.Get(ctx, rep.TargetID)
+		return err == nil && c.Held
+	}
+	return false
+}
+
+// publishReleased публикует события выпущенного после проверки поста или комментария
+func (r *Resolver) publishReleased(ctx context.Context, rep *ent.Report) {
+	switch rep.TargetType {
+	case report.TargetTypePost:
+		if p, err := r.Client.Post.Get(ctx, rep.TargetID); err == nil {
+			r.publishPostPublished(ctx, p)
+		}
+	case report.TargetTypeComment:
+		if c, err := r.Client.Comment.Get(ctx, rep.TargetID); err == nil {
+			r.publishCommentAdded(ctx, c)
+			r.publishPostStats(ctx, c.PostID)
+		}
+	}
+}
+
+// targetTags — теги закешированных ответов с объектом жалобы или автомодерации
+func (r *Resolver) targetTags(ctx context.Context, targetType report.TargetType, targetID int) []string {
+	switch targetType {
+	case report.TargetTypePost:
+		tags := []string{respcache.TagPost(targetID)}
+		if p, err := r.Client.Post.Get(ctx, targetID); err == nil {
+			tags = append(tags, respcache.TagCommunity(p.CommunityID))
+		}
+		return tags
+	case report.TargetTypeComment:
+		if c, err := r.Client.Comment.Get(ctx, targetID); err == nil {
+			return []string{respcache.TagPost(c.PostID)}
+		}
+	case report.TargetTypeUser:
+		return []string{respcache.TagUser(targetID)}
+	case report.TargetTypeCommunity:
+		return []string{respcache.TagCommunity(targetID)}
+	}
+	return nil
+}
+
+// reportOutcomeMessage — текст уведомления автору жалобы; модератор не раскрывается
+func reportOutcomeMessage(status report.Status, note *string) string {
+	msg := "Ваша жалоба рассмотрена: нарушений не найдено"
+	if status == report.StatusResolved {
+		msg = "Ваша жалоба рассмотрена, меры приняты"
+	}
+	if note != nil && *note != "" {
+		msg += ". " + *note
+	}
+	return msg
+}
@@ -1,27 +1,50 @@
 package graphql
 
 import (
+	"stormlink/server/authz"
+	"stormlink/server/automod"
 	"stormlink/server/ent"
+	"stormlink/server/ipban"
+	"stormlink/server/sanctions"
+	"stormlink/server/usecase/automodrule"
 	"stormlink/server/usecase/ban"
+	"stormlink/server/usecase/banappeal"
 	"stormlink/server/usecase/comment"
 	"stormlink/server/usecase/community"
 	"stormlink/server/usecase/communityrole"
 	"stormlink/server/usecase/communityrule"
+	"stormlink/server/usecase/hostipban"
 	"stormlink/server/usecase/hostmute"
 	"stormlink/server/usecase/hostrole"
 	"stormlink/server/usecase/hostrule"
+	"stormlink/server/usecase/moderationlog"
+	"stormlink/server/usecase/notification"
+	"stormlink/server/usecase/notificationsettings"
 	"stormlink/server/usecase/post"
 	"stormlink/server/usecase/profiletableinfoitem"
+	"stormlink/server/usecase/report"
 	"stormlink/server/usecase/user"
+	"stormlink/server/usecase/usersession"
 
 	authpb "stormlink/server/grpc/auth/protobuf"
 	mailpb "stormlink/server/grpc/mail/protobuf"
 	mediapb "stormlink/server/grpc/media/protobuf"
 	userpb "stormlink/server/grpc/user/protobuf"
+	"stormlink/server/graphql/respcache"
+	"stormlink/shared/presence"
+	"stormlink/shared/pubsub"
 )
 
 type Resolver struct {
 	Client *ent.Client
+	// Authz — проверка прав; nil — authz поверх Client
+	Authz *authz.Authorizer
+	// Sanctions — проверка банов и мутов перед записью; nil — без кеша поверх Client
+	Sanctions *sanctions.Checker
+	// Automod — автомодерация публикаций; nil — правила из Client
+	Automod *automod.Engine
+	// IPBans — проверка банов по IP; nil — без кеша поверх Client
+	IPBans *ipban.Checker
 	UserUC user.UserUsecase
 	CommunityUC community.CommunityUsecase
 	PostUC post.PostUsecase
@@ -33,6 +56,19 @@ type Resolver struct {
 	HostMuteUC hostmute.HostMuteUsecase
 	BanUC ban.BanUsecase
 	ProfileTableInfoItemUC profiletableinfoitem.ProfileTableInfoItemUsecase
+	NotificationSettingsUC notificationsettings.NotificationSettingsUsecase
+	NotificationUC notification.NotificationUsecase
+	ModerationLogUC moderationlog.ModerationLogUsecase
+	ReportUC report.ReportUsecase
+	AutomodRuleUC automodrule.AutomodRuleUsecase
+	BanAppealUC banappeal.BanAppealUsecase
+	HostIPBanUC hostipban.HostIPBanUsecase
+	// UserSessionUC — учет входов для связанных аккаунтов; nil — учет выключен
+	UserSessionUC usersession.UserSessionUsecase
+	Broker pubsub.Broker
+	Presence *presence.Tracker
+	// Cache — кеш ответов анонимам; nil, если выключен
+	Cache respcache.Invalidator
 	AuthClient authpb.AuthServiceClient
 	UserClient userpb.UserServiceClient
 	MailClient mailpb.MailServiceClient
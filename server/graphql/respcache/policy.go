@@ -0,0 +1,174 @@
+package respcache
+
+import (
+	"time"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// Scope — кому можно отдавать закешированный ответ
+type Scope int
+
+const (
+	// ScopeAnonymous — ответ одинаков для всех анонимов (подсказка без scope)
+	ScopeAnonymous Scope = iota
+	// ScopePublic — ответ не зависит от пользователя, кешируется и для авторизованных
+	ScopePublic
+	// ScopePrivate — ответ зависит от пользователя: авторизованным не отдается
+	ScopePrivate
+)
+
+func (s Scope) String() string {
+	switch s {
+	case ScopePublic:
+		return "PUBLIC"
+	case ScopePrivate:
+		return "PRIVATE"
+	}
+	return "ANONYMOUS"
+}
+
+// Policy — итоговая политика кеширования операции
+type Policy struct {
+	MaxAge time.Duration
+	Scope  Scope
+}
+
+// Cacheable сообщает, можно ли кешировать ответ для анонимного или авторизованного клиента
+func (p Policy) Cacheable(authenticated bool) bool {
+	if p.MaxAge <= 0 {
+		return false
+	}
+	return !authenticated || p.Scope == ScopePublic
+}
+
+type hint struct {
+	maxAge    time.Duration
+	hasMaxAge bool
+	scope     Scope
+	hasScope  bool
+}
+
+// hintFor — подсказка @cacheControl поля, иначе типа, который поле возвращает
+func hintFor(schema *ast.Schema, def *ast.FieldDefinition) (hint, bool) {
+	d := def.Directives.ForName("cacheControl")
+	if d == nil {
+		if t := schema.Types[def.Type.Name()]; t != nil {
+			d = t.Directives.ForName("cacheControl")
+		}
+	}
+	if d == nil {
+		return hint{}, false
+	}
+	var h hint
+	if arg := d.Arguments.ForName("maxAge"); arg != nil {
+		if v, err := arg.Value.Value(nil); err == nil {
+			if n, ok := v.(int64); ok {
+				h.maxAge, h.hasMaxAge = time.Duration(n)*time.Second, true
+			}
+		}
+	}
+	if arg := d.Arguments.ForName("scope"); arg != nil {
+		h.hasScope = true
+		switch arg.Value.Raw {
+		case "PUBLIC":
+			h.scope = ScopePublic
+		case "PRIVATE":
+			h.scope = ScopePrivate
+		}
+	}
+	return h, true
+}
+
+// Calculate собирает политику операции из подсказок @cacheControl.
+//
+// Корневое поле без подсказки запрещает кеширование. Вложенные поля наследуют maxAge родителя,
+// а своей подсказкой (на поле или на возвращаемом типе) могут его уменьшить. maxAge операции —
+// минимум по всем полям. Scope PRIVATE на любом поле делает ответ недоступным авторизованным,
+// PUBLIC операция получает, только если все корневые поля помечены PUBLIC.
+func Calculate(schema *ast.Schema, op *ast.OperationDefinition) Policy {
+	if op == nil || op.Operation != ast.Query {
+		return Policy{}
+	}
+	w := &walker{schema: schema, policy: Policy{MaxAge: -1, Scope: ScopePublic}}
+	w.root(op.SelectionSet)
+	if w.policy.MaxAge < 0 {
+		return Policy{}
+	}
+	return w.policy
+}
+
+type walker struct {
+	schema *ast.Schema
+	policy Policy
+}
+
+func (w *walker) root(set ast.SelectionSet) {
+	for _, f := range fields(set) {
+		if f.Definition == nil || f.Name == "__typename" {
+			continue
+		}
+		h, ok := hintFor(w.schema, f.Definition)
+		if !ok || !h.hasMaxAge {
+			w.lower(0)
+			return
+		}
+		if !h.hasScope || h.scope != ScopePublic {
+			w.restrict(h.scope)
+		}
+		w.lower(h.maxAge)
+		w.nested(f.SelectionSet, h.maxAge)
+	}
+}
+
+func (w *walker) nested(set ast.SelectionSet, parent time.Duration) {
+	for _, f := range fields(set) {
+		if f.Definition == nil {
+			continue
+		}
+		maxAge := parent
+		if h, ok := hintFor(w.schema, f.Definition); ok {
+			if h.hasMaxAge && h.maxAge < maxAge {
+				maxAge = h.maxAge
+			}
+			if h.hasScope {
+				w.restrict(h.scope)
+			}
+		}
+		w.lower(maxAge)
+		w.nested(f.SelectionSet, maxAge)
+	}
+}
+
+func (w *walker) lower(maxAge time.Duration) {
+	if w.policy.MaxAge < 0 || maxAge < w.policy.MaxAge {
+		w.policy.MaxAge = maxAge
+	}
+}
+
+// restrict сужает scope операции: PUBLIC → ANONYMOUS → PRIVATE
+func (w *walker) restrict(s Scope) {
+	if s == ScopePrivate {
+		w.policy.Scope = ScopePrivate
+	} else if w.policy.Scope == ScopePublic && s != ScopePublic {
+		w.policy.Scope = ScopeAnonymous
+	}
+}
+
+// fields раскрывает фрагменты выборки в плоский список полей
+func fields(set ast.SelectionSet) []*ast.Field {
+	var out []*ast.Field
+	for _, sel := range set {
+		switch s := sel.(type) {
+		case *ast.Field:
+			out = append(out, s)
+		case *ast.InlineFragment:
+			out = append(out, fields(s.SelectionSet)...)
+		case *ast.FragmentSpread:
+			if s.Definition != nil {
+				out = append(out, fields(s.Definition.SelectionSet)...)
+			}
+		}
+	}
+	return out
+}
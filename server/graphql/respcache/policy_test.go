@@ -0,0 +1,59 @@
+package respcache_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2"
+
+	"stormlink/server/graphql"
+	"stormlink/server/graphql/respcache"
+)
+
+var schema = graphql.NewExecutableSchema(graphql.Config{Resolvers: &graphql.Resolver{}}).Schema()
+
+func policy(t *testing.T, query string) respcache.Policy {
+	t.Helper()
+	doc, errs := gqlparser.LoadQuery(schema, query)
+	require.Empty(t, errs)
+	return respcache.Calculate(schema, doc.Operations[0])
+}
+
+func TestCalculate(t *testing.T) {
+	t.Run("hinted root field", func(t *testing.T) {
+		p := policy(t, `{ postBySlug(slug: "a") { id title author { name } } }`)
+		assert.Equal(t, respcache.Policy{MaxAge: time.Minute, Scope: respcache.ScopeAnonymous}, p)
+		assert.True(t, p.Cacheable(false))
+		assert.False(t, p.Cacheable(true))
+	})
+
+	t.Run("root field without hint disables caching", func(t *testing.T) {
+		assert.False(t, policy(t, `{ postBySlug(slug: "a") { id } media(id: 1) { id } }`).Cacheable(false))
+		assert.False(t, policy(t, `{ feedPosts { id } }`).Cacheable(false))
+	})
+
+	t.Run("minimum maxAge wins", func(t *testing.T) {
+		p := policy(t, `{ host { id } postBySlug(slug: "a") { id } }`)
+		assert.Equal(t, time.Minute, p.MaxAge)
+		assert.Equal(t, respcache.ScopeAnonymous, p.Scope)
+	})
+
+	t.Run("public operation is shared with authenticated users", func(t *testing.T) {
+		p := policy(t, `{ host { id title } hostRules { id title } }`)
+		assert.Equal(t, respcache.Policy{MaxAge: 5 * time.Minute, Scope: respcache.ScopePublic}, p)
+		assert.True(t, p.Cacheable(true))
+	})
+
+	t.Run("private type restricts scope", func(t *testing.T) {
+		p := policy(t, `query { postBySlug(slug: "a") { ...F } } fragment F on Post { postStatus { isLiked } }`)
+		assert.Equal(t, respcache.ScopePrivate, p.Scope)
+		assert.True(t, p.Cacheable(false))
+		assert.False(t, p.Cacheable(true))
+	})
+
+	t.Run("mutations are never cached", func(t *testing.T) {
+		assert.Equal(t, respcache.Policy{}, policy(t, `mutation { markAllNotificationsRead }`))
+	})
+}
@@ -0,0 +1,237 @@
+package respcache
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+
+	gqlgen "github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/formatter"
+
+	"stormlink/server/ent"
+	"stormlink/server/graphql/models"
+	"stormlink/shared/auth"
+	redisx "stormlink/shared/redis"
+)
+
+const extensionName = "ResponseCache"
+
+// Теги ответов: мутации сбрасывают все ответы, в которых встречалась сущность.
+// Посты и комментарии помечают ответ еще и тегом автора — для санкций, меняющих их видимость.
+const TagHost = "Host"
+
+func TagPost(id int) string      { return "Post:" + strconv.Itoa(id) }
+func TagCommunity(id int) string { return "Community:" + strconv.Itoa(id) }
+func TagUser(id int) string      { return "User:" + strconv.Itoa(id) }
+
+// Invalidator сбрасывает закешированные ответы по тегам
+type Invalidator interface {
+	Invalidate(ctx context.Context, tags ...string)
+}
+
+// Cache — кеш ответов на запросы (query) по политике @cacheControl.
+// Ключ — нормализованный текст операции, имя операции, переменные и роль клиента
+// ("anonymous" или "public" для ответов со scope PUBLIC). Теги собираются из сущностей,
+// которые резолверы вернули при выполнении, и из корневых аргументов postId/communityId.
+type Cache struct {
+	Store Store
+
+	schema *ast.Schema
+}
+
+var _ interface {
+	gqlgen.ResponseInterceptor
+	gqlgen.FieldInterceptor
+	gqlgen.HandlerExtension
+	Invalidator
+} = &Cache{}
+
+func (c *Cache) ExtensionName() string { return extensionName }
+
+func (c *Cache) Validate(es gqlgen.ExecutableSchema) error {
+	if c.Store == nil {
+		return fmt.Errorf("ResponseCache.Store can not be nil")
+	}
+	c.schema = es.Schema()
+	return nil
+}
+
+func (c *Cache) Invalidate(ctx context.Context, tags ...string) {
+	c.Store.Invalidate(ctx, tags...)
+}
+
+// Stats отдается в extensions.cacheControl закешированных операций
+type Stats struct {
+	MaxAge int    `json:"maxAge"`
+	Scope  string `json:"scope"`
+	Hit    bool   `json:"hit"`
+}
+
+func (c *Cache) InterceptResponse(ctx context.Context, next gqlgen.ResponseHandler) *gqlgen.Response {
+	if !gqlgen.HasOperationContext(ctx) {
+		return next(ctx)
+	}
+	opCtx := gqlgen.GetOperationContext(ctx)
+	policy := Calculate(c.schema, opCtx.Operation)
+	_, err := auth.UserIDFromContext(ctx)
+	if !policy.Cacheable(err == nil) {
+		return next(ctx)
+	}
+	policy.MaxAge = min(policy.MaxAge, maxTagTTL)
+	key, err := cacheKey(opCtx, policy)
+	if err != nil {
+		return next(ctx)
+	}
+	stats := &Stats{MaxAge: int(policy.MaxAge.Seconds()), Scope: policy.Scope.String()}
+
+	if data, ok := c.Store.Get(ctx, key); ok {
+		stats.Hit = true
+		return &gqlgen.Response{Data: data, Extensions: map[string]any{"cacheControl": stats}}
+	}
+
+	tags := &tagSet{}
+	rootTags(opCtx, tags)
+	resp := next(context.WithValue(ctx, tagsKey{}, tags))
+	if resp == nil || len(resp.Errors) > 0 || resp.Data == nil {
+		return resp
+	}
+	c.Store.Set(ctx, key, resp.Data, policy.MaxAge, tags.list())
+	if resp.Extensions == nil {
+		resp.Extensions = map[string]any{}
+	}
+	resp.Extensions["cacheControl"] = stats
+	return resp
+}
+
+// InterceptField запоминает теги сущностей, которые вошли в кешируемый ответ
+func (c *Cache) InterceptField(ctx context.Context, next gqlgen.Resolver) (any, error) {
+	res, err := next(ctx)
+	tags, ok := ctx.Value(tagsKey{}).(*tagSet)
+	if !ok || err != nil {
+		return res, err
+	}
+	switch v := res.(type) {
+	case *ent.Post:
+		if v != nil {
+			tags.add(TagPost(v.ID))
+			tags.add(TagUser(v.AuthorID))
+		}
+	case []*ent.Post:
+		for _, p := range v {
+			tags.add(TagPost(p.ID))
+			tags.add(TagUser(p.AuthorID))
+		}
+	case *ent.Comment:
+		if v != nil {
+			tags.add(TagPost(v.PostID))
+			tags.add(TagUser(v.AuthorID))
+		}
+	case []*ent.Comment:
+		for _, cm := range v {
+			tags.add(TagPost(cm.PostID))
+			tags.add(TagUser(cm.AuthorID))
+		}
+	case *ent.Community:
+		if v != nil {
+			tags.add(TagCommunity(v.ID))
+		}
+	case []*ent.Community:
+		for _, cm := range v {
+			tags.add(TagCommunity(cm.ID))
+		}
+	case *ent.User:
+		if v != nil {
+			tags.add(TagUser(v.ID))
+		}
+	case *ent.Host, *ent.HostSocialNavigation, *ent.HostSidebarNavigation, []*models.HostRule:
+		tags.add(TagHost)
+	}
+	return res, err
+}
+
+type tagsKey struct{}
+
+// tagSet — теги одного ответа; поля резолвятся параллельно
+type tagSet struct {
+	mu   sync.Mutex
+	tags []string
+}
+
+func (t *tagSet) add(tag string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !slices.Contains(t.tags, tag) {
+		t.tags = append(t.tags, tag)
+	}
+}
+
+func (t *tagSet) list() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return slices.Clone(t.tags)
+}
+
+// rootTags — теги по аргументам корневых полей: пустое окно комментариев тоже должно сброситься
+// при первом комментарии к посту
+func rootTags(opCtx *gqlgen.OperationContext, tags *tagSet) {
+	for _, f := range fields(opCtx.Operation.SelectionSet) {
+		if f.Definition == nil {
+			continue
+		}
+		args := f.ArgumentMap(opCtx.Variables)
+		for name, tag := range map[string]func(int) string{"postId": TagPost, "communityId": TagCommunity} {
+			if v, ok := args[name]; ok && v != nil {
+				if n, err := strconv.Atoi(fmt.Sprint(v)); err == nil {
+					tags.add(tag(n))
+				}
+			}
+		}
+	}
+}
+
+// cacheKey — хеш нормализованного текста операции, переменных и роли
+func cacheKey(opCtx *gqlgen.OperationContext, p Policy) (string, error) {
+	var buf bytes.Buffer
+	formatter.NewFormatter(&buf, formatter.WithoutDescription()).FormatQueryDocument(opCtx.Doc)
+	vars, err := json.Marshal(opCtx.Variables)
+	if err != nil {
+		return "", err
+	}
+	role := "anonymous"
+	if p.Scope == ScopePublic {
+		role = "public"
+	}
+	h := sha256.New()
+	for _, part := range [][]byte{buf.Bytes(), []byte(opCtx.OperationName), vars, []byte(role)} {
+		h.Write(part)
+		h.Write([]byte{0})
+	}
+	return role + ":" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// FromEnv настраивает кеш ответов из ENV: GRAPHQL_RESPONSE_CACHE=memory (по умолчанию), redis или off.
+// nil означает, что кеш выключен.
+func FromEnv() (*Cache, error) {
+	switch store := os.Getenv("GRAPHQL_RESPONSE_CACHE"); store {
+	case "", "memory":
+		return &Cache{Store: NewMemoryStore(10000)}, nil
+	case "redis":
+		rdb, err := redisx.NewClient()
+		if err != nil {
+			return nil, err
+		}
+		return &Cache{Store: NewRedisStore(rdb, "stormlink:respcache:")}, nil
+	case "off":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown GRAPHQL_RESPONSE_CACHE %q", store)
+	}
+}
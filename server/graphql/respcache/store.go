@@ -0,0 +1,168 @@
+package respcache
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	redis "github.com/redis/go-redis/v9"
+)
+
+// Store хранит сериализованные ответы с тегами для инвалидации. Ошибки хранилища
+// не должны ломать запрос: промах при чтении, запись и инвалидация только логируются.
+type Store interface {
+	Get(ctx context.Context, key string) ([]byte, bool)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration, tags []string)
+	Invalidate(ctx context.Context, tags ...string)
+}
+
+// MemoryStore — кеш в памяти процесса. Инвалидация действует только на своей реплике,
+// остальные отдают старый ответ не дольше его maxAge.
+type MemoryStore struct {
+	maxEntries int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+	tags    map[string]map[string]struct{}
+}
+
+type memoryEntry struct {
+	value   []byte
+	expires time.Time
+	tags    []string
+}
+
+func NewMemoryStore(maxEntries int) *MemoryStore {
+	return &MemoryStore{
+		maxEntries: maxEntries,
+		now:        time.Now,
+		entries:    make(map[string]memoryEntry),
+		tags:       make(map[string]map[string]struct{}),
+	}
+}
+
+func (s *MemoryStore) Get(_ context.Context, key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !s.now().Before(e.expires) {
+		s.remove(key)
+		return nil, false
+	}
+	return e.value, true
+}
+
+func (s *MemoryStore) Set(_ context.Context, key string, value []byte, ttl time.Duration, tags []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.entries[key]; !exists && len(s.entries) >= s.maxEntries {
+		s.sweep()
+		if len(s.entries) >= s.maxEntries {
+			return
+		}
+	}
+	s.remove(key)
+	s.entries[key] = memoryEntry{value: value, expires: s.now().Add(ttl), tags: tags}
+	for _, tag := range tags {
+		keys := s.tags[tag]
+		if keys == nil {
+			keys = make(map[string]struct{})
+			s.tags[tag] = keys
+		}
+		keys[key] = struct{}{}
+	}
+}
+
+func (s *MemoryStore) Invalidate(_ context.Context, tags ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, tag := range tags {
+		for key := range s.tags[tag] {
+			s.remove(key)
+		}
+	}
+}
+
+func (s *MemoryStore) remove(key string) {
+	e, ok := s.entries[key]
+	if !ok {
+		return
+	}
+	delete(s.entries, key)
+	for _, tag := range e.tags {
+		delete(s.tags[tag], key)
+		if len(s.tags[tag]) == 0 {
+			delete(s.tags, tag)
+		}
+	}
+}
+
+// sweep удаляет просроченные записи, когда кеш заполнен
+func (s *MemoryStore) sweep() {
+	now := s.now()
+	for key, e := range s.entries {
+		if !now.Before(e.expires) {
+			s.remove(key)
+		}
+	}
+}
+
+// RedisStore — кеш в Redis, общий для всех реплик. Для каждого тега хранится множество ключей
+// ответов; множество живет не дольше maxTagTTL и обновляется при каждой записи.
+type RedisStore struct {
+	rdb    *redis.Client
+	prefix string
+}
+
+const maxTagTTL = time.Hour
+
+func NewRedisStore(rdb *redis.Client, prefix string) *RedisStore {
+	return &RedisStore{rdb: rdb, prefix: prefix}
+}
+
+func (s *RedisStore) tagKey(tag string) string { return s.prefix + "tag:" + tag }
+
+func (s *RedisStore) Get(ctx context.Context, key string) ([]byte, bool) {
+	v, err := s.rdb.Get(ctx, s.prefix+key).Bytes()
+	if err != nil {
+		if err != redis.Nil {
+			log.Printf("⚠️ response cache get: %v", err)
+		}
+		return nil, false
+	}
+	return v, true
+}
+
+func (s *RedisStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration, tags []string) {
+	pipe := s.rdb.TxPipeline()
+	pipe.Set(ctx, s.prefix+key, value, ttl)
+	for _, tag := range tags {
+		pipe.SAdd(ctx, s.tagKey(tag), key)
+		pipe.Expire(ctx, s.tagKey(tag), maxTagTTL)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("⚠️ response cache set: %v", err)
+	}
+}
+
+func (s *RedisStore) Invalidate(ctx context.Context, tags ...string) {
+	for _, tag := range tags {
+		keys, err := s.rdb.SMembers(ctx, s.tagKey(tag)).Result()
+		if err != nil {
+			log.Printf("⚠️ response cache invalidate %s: %v", tag, err)
+			continue
+		}
+		del := []string{s.tagKey(tag)}
+		for _, key := range keys {
+			del = append(del, s.prefix+key)
+		}
+		if err := s.rdb.Del(ctx, del...).Err(); err != nil {
+			log.Printf("⚠️ response cache invalidate %s: %v", tag, err)
+		}
+	}
+}
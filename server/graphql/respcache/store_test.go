@@ -0,0 +1,42 @@
+package respcache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := NewMemoryStore(2)
+	s.now = func() time.Time { return now }
+
+	s.Set(ctx, "a", []byte("A"), time.Minute, []string{TagPost(1), TagHost})
+	s.Set(ctx, "b", []byte("B"), time.Second, []string{TagPost(2)})
+	v, ok := s.Get(ctx, "a")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("A"), v)
+
+	// Кеш полон: новая запись не вытесняет живые
+	s.Set(ctx, "c", []byte("C"), time.Minute, nil)
+	_, ok = s.Get(ctx, "c")
+	assert.False(t, ok)
+
+	// После истечения срока b место освобождается
+	now = now.Add(2 * time.Second)
+	_, ok = s.Get(ctx, "b")
+	assert.False(t, ok)
+	s.Set(ctx, "c", []byte("C"), time.Minute, []string{TagPost(1)})
+	_, ok = s.Get(ctx, "c")
+	assert.True(t, ok)
+
+	s.Invalidate(ctx, TagPost(1))
+	_, ok = s.Get(ctx, "a")
+	assert.False(t, ok)
+	_, ok = s.Get(ctx, "c")
+	assert.False(t, ok)
+	assert.Empty(t, s.tags, "tag index is cleaned up with entries")
+}
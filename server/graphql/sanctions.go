@@ -0,0 +1,195 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"google.golang.org/grpc/codes"
+
+	"stormlink/server/authz"
+	"stormlink/server/ent"
+	"stormlink/server/graphql/models"
+	"stormlink/server/graphql/respcache"
+	"stormlink/server/model"
+	"stormlink/server/sanctions"
+	"stormlink/shared/auth"
+)
+
+// sanctionChecker — проверка санкций; резолвер без Sanctions (тесты) проверяет по базе без кеша
+func (r *Resolver) sanctionChecker() *sanctions.Checker {
+	if r.Sanctions != nil {
+		return r.Sanctions
+	}
+	return sanctions.New(sanctions.NewEntStore(r.Client), nil)
+}
+
+// userSanctionsChanged сбрасывает кеш санкций пользователя (в сообществе или на платформе при
+// communityID == nil) и закешированные ответы с ним и его публикациями: теневая санкция
+// меняет их видимость для остальных
+func (r *Resolver) userSanctionsChanged(ctx context.Context, userID int, communityID *int) {
+	if communityID == nil {
+		r.sanctionChecker().InvalidateUser(ctx, userID)
+		r.invalidate(ctx, respcache.TagUser(userID))
+		return
+	}
+	r.sanctionChecker().InvalidateMember(ctx, *communityID, userID)
+	r.invalidate(ctx, respcache.TagUser(userID), respcache.TagCommunity(*communityID))
+}
+
+// communitySanctionsChanged сбрасывает кеш санкций сообщества и закешированные ответы с ним
+func (r *Resolver) communitySanctionsChanged(ctx context.Context, communityID int) {
+	r.sanctionChecker().InvalidateCommunity(ctx, communityID)
+	r.invalidate(ctx, respcache.TagCommunity(communityID))
+}
+
+// checkSanctions пропускает действие, если его не запрещает бан или мут. Отказ — ошибка
+// с code=PermissionDenied, кодом причины reason и сроком expiresAt в extensions.
+func (r *Resolver) checkSanctions(ctx context.Context, userID int, communityID *int, op sanctions.Op) error {
+	err := r.sanctionChecker().Check(ctx, userID, communityID, op)
+	var denied *sanctions.DeniedError
+	if !errors.As(err, &denied) {
+		return err
+	}
+	e := gqlerror.WrapPath(graphql.GetPath(ctx), err)
+	e.Extensions = map[string]any{
+		"code":   codes.PermissionDenied.String(),
+		"reason": string(denied.Reason),
+	}
+	if denied.ExpiresAt != nil {
+		e.Extensions["expiresAt"] = denied.ExpiresAt.Format(time.RFC3339)
+	}
+	return e
+}
+
+// sanctionDetails собирает сведения санкции из полей входа; срок должен быть в будущем
+func sanctionDetails(issuedBy int, reason *string, expiresAt *time.Time, publicNote, privateNote *string) (model.SanctionDetails, error) {
+	d := model.SanctionDetails{IssuedBy: issuedBy, ExpiresAt: expiresAt}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return d, fmt.Errorf("expiresAt must be in the future")
+	}
+	if reason != nil {
+		d.Reason = *reason
+	}
+	if publicNote != nil {
+		d.PublicNote = *publicNote
+	}
+	if privateNote != nil {
+		d.PrivateNote = *privateNote
+	}
+	return d, nil
+}
+
+// notifySanction сообщает пользователю о выданной санкции; о теневой санкции не сообщается
+func (r *Resolver) notifySanction(ctx context.Context, userID, moderatorID int, communityID *int, base string, d model.SanctionDetails) {
+	if d.Shadow {
+		return
+	}
+	r.notifyModeration(ctx, userID, moderatorID, communityID, sanctionMessage(base, d))
+}
+
+// sanctionMessage дополняет уведомление о санкции сроком и причиной
+func sanctionMessage(base string, d model.SanctionDetails) string {
+	msg := base
+	if d.ExpiresAt != nil {
+		msg += " до " + d.ExpiresAt.Format("02.01.2006 15:04")
+	}
+	if d.Reason != "" {
+		msg += ". Причина: " + d.Reason
+	}
+	return msg
+}
+
+// privateNote отдает приватную заметку санкции, если у зрителя есть право на эту санкцию
+func (r *Resolver) privateNote(ctx context.Context, action authz.Action, res authz.Resource, note func() (string, error)) (*string, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil {
+		return nil, nil
+	}
+	ok, err := r.authorizer().Can(ctx, userID, action, res)
+	if err != nil || !ok {
+		return nil, err
+	}
+	s, err := note()
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func optionalID(id *int) *string {
+	if id == nil {
+		return nil
+	}
+	s := strconv.Itoa(*id)
+	return &s
+}
+
+// Маппинг санкций платформы, которые отдаются через модели GraphQL
+
+func hostUserMuteModel(m *ent.HostUserMute) *models.HostUserMute {
+	return &models.HostUserMute{
+		ID:         strconv.Itoa(m.ID),
+		Reason:     m.Reason,
+		IssuedBy:   optionalID(m.IssuedBy),
+		ExpiresAt:  m.ExpiresAt,
+		PublicNote: m.PublicNote,
+		Shadow:     m.Shadow,
+		CreatedAt:  m.CreatedAt,
+		UpdatedAt:  m.UpdatedAt,
+		Issuer:     m.Edges.Issuer,
+		User:       m.Edges.User,
+	}
+}
+
+func hostCommunityMuteModel(m *ent.HostCommunityMute) *models.HostCommunityMute {
+	return &models.HostCommunityMute{
+		ID:          strconv.Itoa(m.ID),
+		CommunityID: strconv.Itoa(m.CommunityID),
+		Reason:      m.Reason,
+		IssuedBy:    optionalID(m.IssuedBy),
+		ExpiresAt:   m.ExpiresAt,
+		PublicNote:  m.PublicNote,
+		CreatedAt:   m.CreatedAt,
+		UpdatedAt:   m.UpdatedAt,
+		Issuer:      m.Edges.Issuer,
+		Community:   m.Edges.Community,
+	}
+}
+
+func hostCommunityBanModel(b *ent.HostCommunityBan) *models.HostCommunityBan {
+	return &models.HostCommunityBan{
+		ID:          strconv.Itoa(b.ID),
+		CommunityID: strconv.Itoa(b.CommunityID),
+		Reason:      b.Reason,
+		IssuedBy:    optionalID(b.IssuedBy),
+		ExpiresAt:   b.ExpiresAt,
+		PublicNote:  b.PublicNote,
+		CreatedAt:   b.CreatedAt,
+		UpdatedAt:   b.UpdatedAt,
+		Issuer:      b.Edges.Issuer,
+		Community:   b.Edges.Community,
+	}
+}
+
+// sanctionModel — санкция для самого пользователя (mySanctions)
+func (r *Resolver) sanctionModel(ctx context.Context, s *model.Sanction) (*models.Sanction, error) {
+	cm, err := loadOptionalEdge(ctx, nil, r.loaders(ctx).Community, s.CommunityID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.Sanction{
+		ID:         strconv.Itoa(s.ID),
+		Kind:       models.SanctionKind(strings.ToUpper(s.Kind)),
+		Community:  cm,
+		Reason:     s.Reason,
+		PublicNote: s.PublicNote,
+		ExpiresAt:  s.ExpiresAt,
+		CreatedAt:  s.CreatedAt,
+	}, nil
+}
@@ -0,0 +1,212 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	gqlgen "github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"stormlink/shared/auth"
+)
+
+// SSETransport — транспорт GraphQL over Server-Sent Events (протокол graphql-sse, режим distinct connections):
+// каждая операция — отдельный HTTP-запрос POST (JSON-тело) или GET (параметры в query string, подходит для EventSource)
+// с Accept: text/event-stream. Ответы приходят событиями "next", завершение — событием "complete".
+// В отличие от WebSocket работает через прокси, которые режут Upgrade.
+type SSETransport struct {
+	// KeepAlive — период комментариев-пингов, чтобы прокси не закрывали «молчащий» поток
+	KeepAlive time.Duration
+	// MaxStreamsPerClient — лимит одновременных потоков на клиента (пользователь или IP); 0 — без лимита
+	MaxStreamsPerClient int
+	// ClientKey определяет IP клиента (по умолчанию — адрес из RemoteAddr)
+	ClientKey func(r *http.Request) string
+
+	mu     sync.Mutex
+	active map[string]int
+}
+
+var _ gqlgen.Transport = (*SSETransport)(nil)
+
+func (t *SSETransport) Supports(r *http.Request) bool {
+	if !strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		return false
+	}
+	switch r.Method {
+	case http.MethodGet:
+		return r.URL.Query().Get("query") != ""
+	case http.MethodPost:
+		mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		return err == nil && mediaType == "application/json"
+	}
+	return false
+}
+
+// clientKey — ключ для лимита потоков: авторизованный пользователь или IP
+func (t *SSETransport) clientKey(r *http.Request) string {
+	if userID, err := auth.UserIDFromContext(r.Context()); err == nil && userID > 0 {
+		return fmt.Sprintf("user:%d", userID)
+	}
+	if t.ClientKey != nil {
+		return "ip:" + t.ClientKey(r)
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+func (t *SSETransport) acquire(key string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.active == nil {
+		t.active = map[string]int{}
+	}
+	if t.MaxStreamsPerClient > 0 && t.active[key] >= t.MaxStreamsPerClient {
+		return false
+	}
+	t.active[key]++
+	return true
+}
+
+func (t *SSETransport) release(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.active[key]--; t.active[key] <= 0 {
+		delete(t.active, key)
+	}
+}
+
+func (t *SSETransport) Do(w http.ResponseWriter, r *http.Request, exec gqlgen.GraphExecutor) {
+	ctx := r.Context()
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		transport.SendErrorf(w, http.StatusInternalServerError, "streaming unsupported")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+
+	key := t.clientKey(r)
+	if !t.acquire(key) {
+		transport.SendErrorf(w, http.StatusTooManyRequests, "too many concurrent subscriptions (max %d)", t.MaxStreamsPerClient)
+		return
+	}
+	defer t.release(key)
+
+	params, err := sseParams(r)
+	if err != nil {
+		transport.SendErrorf(w, http.StatusBadRequest, "%s", err)
+		return
+	}
+	params.Headers = r.Header
+	start := gqlgen.Now()
+	params.ReadTime = gqlgen.TraceTiming{Start: start, End: gqlgen.Now()}
+
+	// Поток живёт дольше WriteTimeout HTTP-сервера: снимаем дедлайн записи для этого запроса
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	var mu sync.Mutex
+	write := func(format string, args ...any) {
+		mu.Lock()
+		defer mu.Unlock()
+		_, _ = fmt.Fprintf(w, format, args...)
+		flusher.Flush()
+	}
+	write(":\n\n")
+
+	if t.KeepAlive > 0 {
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			ticker := time.NewTicker(t.KeepAlive)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-done:
+					return
+				case <-ticker.C:
+					write(": ping\n\n")
+				}
+			}
+		}()
+	}
+
+	rc, opErr := exec.CreateOperationContext(ctx, params)
+	ctx = gqlgen.WithOperationContext(ctx, rc)
+	// GET не защищён CSRF-проверками — мутации только через POST
+	if opErr == nil && r.Method == http.MethodGet && rc.Operation != nil && rc.Operation.Operation == ast.Mutation {
+		opErr = gqlerror.List{gqlerror.Errorf("mutations are not allowed over GET")}
+	}
+	if opErr != nil {
+		writeSSEResponse(write, exec.DispatchError(ctx, opErr))
+	} else {
+		responses, ctx := exec.DispatchOperation(ctx, rc)
+		for {
+			resp := responses(ctx)
+			if resp == nil {
+				break
+			}
+			writeSSEResponse(write, resp)
+		}
+	}
+	write("event: complete\ndata:\n\n")
+}
+
+func writeSSEResponse(write func(string, ...any), resp *gqlgen.Response) {
+	b, err := json.Marshal(resp)
+	if err != nil {
+		b, _ = json.Marshal(&gqlgen.Response{Errors: gqlerror.List{gqlerror.Errorf("marshal response: %s", err)}})
+	}
+	write("event: next\ndata: %s\n\n", b)
+}
+
+// sseParams читает операцию из JSON-тела (POST) или из query string (GET)
+func sseParams(r *http.Request) (*gqlgen.RawParams, error) {
+	params := &gqlgen.RawParams{}
+	if r.Method == http.MethodPost {
+		if err := decodeJSON(r.Body, params); err != nil {
+			return nil, fmt.Errorf("json request body could not be decoded: %w", err)
+		}
+		return params, nil
+	}
+
+	q := r.URL.Query()
+	params.Query = q.Get("query")
+	params.OperationName = q.Get("operationName")
+	if v := q.Get("variables"); v != "" {
+		if err := decodeJSON(strings.NewReader(v), &params.Variables); err != nil {
+			return nil, fmt.Errorf("variables could not be decoded: %w", err)
+		}
+	}
+	if v := q.Get("extensions"); v != "" {
+		if err := decodeJSON(strings.NewReader(v), &params.Extensions); err != nil {
+			return nil, fmt.Errorf("extensions could not be decoded: %w", err)
+		}
+	}
+	return params, nil
+}
+
+// decodeJSON декодирует с UseNumber, как стандартные транспорты gqlgen (целые переменные не превращаются во float)
+func decodeJSON(r io.Reader, v any) error {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	return dec.Decode(v)
+}
@@ -0,0 +1,79 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+
+	gqlgen "github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// eventIDQueue — ID событий, отправленных в канал подписки, в порядке отправки.
+// Каждое сообщение подписки соответствует ровно одному значению из канала, поэтому
+// при формировании ответа достаточно снять первый ID из очереди.
+type eventIDQueue struct {
+	mu  sync.Mutex
+	ids []string
+}
+
+func (q *eventIDQueue) push(id string) {
+	if q == nil {
+		return
+	}
+	q.mu.Lock()
+	q.ids = append(q.ids, id)
+	q.mu.Unlock()
+}
+
+func (q *eventIDQueue) pop() (string, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.ids) == 0 {
+		return "", false
+	}
+	id := q.ids[0]
+	q.ids = q.ids[1:]
+	return id, true
+}
+
+type eventIDsKey struct{}
+
+func eventIDsFromContext(ctx context.Context) *eventIDQueue {
+	q, _ := ctx.Value(eventIDsKey{}).(*eventIDQueue)
+	return q
+}
+
+// SubscriptionEventIDs — расширение gqlgen: добавляет ID события брокера в extensions.eventId
+// каждого сообщения подписки, чтобы клиент мог продолжить подписку с since после переподключения.
+type SubscriptionEventIDs struct{}
+
+var _ interface {
+	gqlgen.HandlerExtension
+	gqlgen.OperationInterceptor
+} = SubscriptionEventIDs{}
+
+func (SubscriptionEventIDs) ExtensionName() string { return "SubscriptionEventIDs" }
+
+func (SubscriptionEventIDs) Validate(gqlgen.ExecutableSchema) error { return nil }
+
+func (SubscriptionEventIDs) InterceptOperation(ctx context.Context, next gqlgen.OperationHandler) gqlgen.ResponseHandler {
+	op := gqlgen.GetOperationContext(ctx).Operation
+	if op == nil || op.Operation != ast.Subscription {
+		return next(ctx)
+	}
+	q := &eventIDQueue{}
+	h := next(context.WithValue(ctx, eventIDsKey{}, q))
+	return func(ctx context.Context) *gqlgen.Response {
+		resp := h(ctx)
+		if resp == nil {
+			return nil
+		}
+		if id, ok := q.pop(); ok {
+			if resp.Extensions == nil {
+				resp.Extensions = map[string]any{}
+			}
+			resp.Extensions["eventId"] = id
+		}
+		return resp
+	}
+}
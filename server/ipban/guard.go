@@ -0,0 +1,80 @@
+package ipban
+
+import (
+	"context"
+	"time"
+
+	gqlgen "github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"google.golang.org/grpc/codes"
+
+	"stormlink/shared/auth"
+)
+
+// Denied — отказ с забаненного адреса: code=PermissionDenied, reason=IP_BANNED
+// и срок бана expiresAt в extensions
+func Denied(b *Ban) *gqlerror.Error {
+	e := gqlerror.Errorf("requests from this network are banned")
+	e.Extensions = map[string]any{
+		"code":   codes.PermissionDenied.String(),
+		"reason": Reason,
+	}
+	if b.ExpiresAt != nil {
+		e.Extensions["expiresAt"] = b.ExpiresAt.Format(time.RFC3339)
+	}
+	return e
+}
+
+// sessionFields — мутации, которые не отклоняются: без входа персонал платформы
+// не сможет снять бан со своего адреса
+var sessionFields = map[string]bool{
+	"loginUser":        true,
+	"logoutUser":       true,
+	"userRefreshToken": true,
+	"__typename":       true,
+}
+
+// Guard отклоняет мутации с адреса, бан которого положен в ctx (WithBan). Пропускаются вход,
+// выход и обновление токена, а также пользователи, для которых Exempt вернул true.
+type Guard struct {
+	// Exempt — пользователю бан по IP не мешает (персонал платформы); nil — исключений нет
+	Exempt func(ctx context.Context, userID int) (bool, error)
+}
+
+var _ interface {
+	gqlgen.OperationContextMutator
+	gqlgen.HandlerExtension
+} = Guard{}
+
+func (Guard) ExtensionName() string { return "IPBanGuard" }
+
+func (Guard) Validate(gqlgen.ExecutableSchema) error { return nil }
+
+func (g Guard) MutateOperationContext(ctx context.Context, opCtx *gqlgen.OperationContext) *gqlerror.Error {
+	ban := FromContext(ctx)
+	if ban == nil || opCtx.Operation == nil || opCtx.Operation.Operation != ast.Mutation || sessionOnly(opCtx.Operation.SelectionSet) {
+		return nil
+	}
+	if userID, err := auth.UserIDFromContext(ctx); err == nil && userID != 0 && g.Exempt != nil {
+		ok, err := g.Exempt(ctx, userID)
+		if err != nil {
+			return gqlerror.Errorf("ip ban check failed: %v", err)
+		}
+		if ok {
+			return nil
+		}
+	}
+	return Denied(ban)
+}
+
+// sessionOnly — мутация состоит только из входа, выхода и обновления токена; фрагменты не разбираются
+func sessionOnly(set ast.SelectionSet) bool {
+	for _, sel := range set {
+		f, ok := sel.(*ast.Field)
+		if !ok || !sessionFields[f.Name] {
+			return false
+		}
+	}
+	return true
+}
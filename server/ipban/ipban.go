@@ -0,0 +1,163 @@
+// Package ipban — баны платформы по IP и подсетям (HostIPBan).
+//
+// Адрес клиента определяется так же, как для лимитов анонимов (shared/http.ClientIP).
+// HTTP-middleware находит бан адреса и кладет его в ctx (WithBan), Guard отклоняет мутации
+// с забаненного адреса, а регистрацию резолвер RegisterUser проверяет еще и сам.
+// Бан с истекшим expires_at не действует, даже если воркер еще не успел его снять.
+package ipban
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Reason — код причины отказа для клиента (extensions.reason, как у отказов по санкциям)
+const Reason = "IP_BANNED"
+
+// Самые широкие подсети, которые можно забанить: защита от опечатки, блокирующей всех
+const (
+	MinIPv4Bits = 8
+	MinIPv6Bits = 16
+)
+
+// Ban — действующий бан подсети
+type Ban struct {
+	ID     int
+	Prefix netip.Prefix
+	// nil — бессрочно
+	ExpiresAt *time.Time
+}
+
+func (b *Ban) active(now time.Time) bool {
+	return b.ExpiresAt == nil || b.ExpiresAt.After(now)
+}
+
+// longest — бан, который действует дольше (бессрочный дольше любого)
+func longest(a, b *Ban) *Ban {
+	switch {
+	case a == nil:
+		return b
+	case a.ExpiresAt == nil:
+		return a
+	case b.ExpiresAt == nil || b.ExpiresAt.After(*a.ExpiresAt):
+		return b
+	default:
+		return a
+	}
+}
+
+// ParsePrefix разбирает адрес (203.0.113.7) или подсеть (203.0.113.0/24, 2001:db8::/48)
+// в каноническую подсеть: адрес — подсеть из одного адреса, биты хоста обнуляются,
+// IPv4 в записи IPv6 (::ffff:203.0.113.7) приводится к IPv4
+func ParsePrefix(s string) (netip.Prefix, error) {
+	s = strings.TrimSpace(s)
+	var p netip.Prefix
+	if strings.Contains(s, "/") {
+		var err error
+		if p, err = netip.ParsePrefix(s); err != nil {
+			return netip.Prefix{}, fmt.Errorf("invalid cidr %q", s)
+		}
+		if p.Addr().Is4In6() && p.Bits() >= 96 {
+			p = netip.PrefixFrom(p.Addr().Unmap(), p.Bits()-96)
+		}
+	} else {
+		addr, err := parseAddr(s)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("invalid ip %q", s)
+		}
+		p = netip.PrefixFrom(addr, addr.BitLen())
+	}
+	minBits := MinIPv6Bits
+	if p.Addr().Is4() {
+		minBits = MinIPv4Bits
+	}
+	if p.Bits() < minBits {
+		return netip.Prefix{}, fmt.Errorf("cidr %q is too wide: at least /%d is required", s, minBits)
+	}
+	return p.Masked(), nil
+}
+
+func parseAddr(s string) (netip.Addr, error) {
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Addr{}, err
+	}
+	return addr.Unmap().WithZone(""), nil
+}
+
+// Checker ищет бан адреса среди действующих банов. Список перечитывается раз в ttl
+// и сразу после выдачи или снятия бана через этот экземпляр (Invalidate).
+type Checker struct {
+	store Store
+	ttl   time.Duration
+	now   func() time.Time
+
+	mu       sync.Mutex
+	bans     []*Ban
+	loadedAt time.Time
+}
+
+// New — проверка поверх store
+func New(store Store) *Checker {
+	return &Checker{store: store, ttl: 30 * time.Second, now: time.Now}
+}
+
+// Lookup — действующий бан адреса ip (при нескольких — самый долгий); nil — адрес не забанен
+// или не разбирается
+func (c *Checker) Lookup(ctx context.Context, ip string) (*Ban, error) {
+	addr, err := parseAddr(ip)
+	if err != nil {
+		return nil, nil
+	}
+	bans, err := c.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := c.now()
+	var found *Ban
+	for _, b := range bans {
+		if b.active(now) && b.Prefix.Contains(addr) {
+			found = longest(found, b)
+		}
+	}
+	return found, nil
+}
+
+// Invalidate сбрасывает список банов: следующая проверка перечитает его из store
+func (c *Checker) Invalidate() {
+	c.mu.Lock()
+	c.loadedAt = time.Time{}
+	c.mu.Unlock()
+}
+
+func (c *Checker) load(ctx context.Context) ([]*Ban, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	if !c.loadedAt.IsZero() && now.Sub(c.loadedAt) < c.ttl {
+		return c.bans, nil
+	}
+	bans, err := c.store.Active(ctx, now)
+	if err != nil {
+		return nil, fmt.Errorf("ip bans: %w", err)
+	}
+	c.bans, c.loadedAt = bans, now
+	return bans, nil
+}
+
+type banKey struct{}
+
+// WithBan кладет в ctx бан адреса клиента; nil — адрес не забанен
+func WithBan(ctx context.Context, b *Ban) context.Context {
+	return context.WithValue(ctx, banKey{}, b)
+}
+
+// FromContext — бан адреса клиента из ctx (см. WithBan)
+func FromContext(ctx context.Context) *Ban {
+	b, _ := ctx.Value(banKey{}).(*Ban)
+	return b
+}
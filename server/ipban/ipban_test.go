@@ -0,0 +1,123 @@
+package ipban
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"testing"
+	"time"
+
+	gqlgen "github.com/99designs/gqlgen/graphql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/ast"
+
+	"stormlink/shared/auth"
+)
+
+func TestParsePrefix(t *testing.T) {
+	for in, want := range map[string]string{
+		"203.0.113.7":            "203.0.113.7/32",
+		" 203.0.113.7 ":          "203.0.113.7/32",
+		"203.0.113.77/24":        "203.0.113.0/24",
+		"::ffff:203.0.113.7":     "203.0.113.7/32",
+		"::ffff:203.0.113.7/120": "203.0.113.0/24",
+		"2001:db8:1:2::5/48":     "2001:db8:1::/48",
+		"2001:db8::1":            "2001:db8::1/128",
+		"fe80::1%eth0":           "fe80::1/128",
+	} {
+		p, err := ParsePrefix(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, p.String(), in)
+	}
+
+	for _, in := range []string{"", "example.com", "203.0.113.0/33", "10.0.0.0/7", "2001::/15"} {
+		_, err := ParsePrefix(in)
+		assert.Error(t, err, in)
+	}
+}
+
+type fakeStore struct {
+	bans  []*Ban
+	loads int
+	err   error
+}
+
+func (s *fakeStore) Active(context.Context, time.Time) ([]*Ban, error) {
+	s.loads++
+	return s.bans, s.err
+}
+
+func TestChecker_Lookup(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	soon, later := now.Add(time.Hour), now.Add(24*time.Hour)
+	past := now.Add(-time.Minute)
+	store := &fakeStore{bans: []*Ban{
+		{ID: 1, Prefix: netip.MustParsePrefix("203.0.113.0/24"), ExpiresAt: &soon},
+		{ID: 2, Prefix: netip.MustParsePrefix("203.0.113.7/32"), ExpiresAt: &later},
+		{ID: 3, Prefix: netip.MustParsePrefix("198.51.100.0/24"), ExpiresAt: &past},
+		{ID: 4, Prefix: netip.MustParsePrefix("2001:db8::/32")},
+	}}
+	c := New(store)
+	c.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for ip, want := range map[string]int{
+		"203.0.113.9": 1,
+		// Из нескольких банов — самый долгий
+		"203.0.113.7":        2,
+		"::ffff:203.0.113.7": 2,
+		"2001:db8::42":       4,
+		// Истекший бан не действует
+		"198.51.100.1": 0,
+		"192.0.2.1":    0,
+		"not an ip":    0,
+	} {
+		b, err := c.Lookup(ctx, ip)
+		require.NoError(t, err, ip)
+		if want == 0 {
+			assert.Nil(t, b, ip)
+			continue
+		}
+		require.NotNil(t, b, ip)
+		assert.Equal(t, want, b.ID, ip)
+	}
+	assert.Equal(t, 1, store.loads, "bans are cached")
+
+	c.Invalidate()
+	_, err := c.Lookup(ctx, "192.0.2.1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, store.loads)
+
+	now = now.Add(time.Minute)
+	store.err = errors.New("db down")
+	_, err = c.Lookup(ctx, "192.0.2.1")
+	assert.Error(t, err)
+}
+
+func TestGuard(t *testing.T) {
+	expires := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	ban := &Ban{ID: 1, Prefix: netip.MustParsePrefix("203.0.113.0/24"), ExpiresAt: &expires}
+	g := Guard{Exempt: func(_ context.Context, userID int) (bool, error) { return userID == 1, nil }}
+	op := func(kind ast.Operation, fields ...string) *gqlgen.OperationContext {
+		set := ast.SelectionSet{}
+		for _, f := range fields {
+			set = append(set, &ast.Field{Name: f})
+		}
+		return &gqlgen.OperationContext{Operation: &ast.OperationDefinition{Operation: kind, SelectionSet: set}}
+	}
+	banned := WithBan(context.Background(), ban)
+
+	assert.Nil(t, g.MutateOperationContext(context.Background(), op(ast.Mutation, "createPost")), "address is not banned")
+	assert.Nil(t, g.MutateOperationContext(banned, op(ast.Query, "posts")), "reads are allowed")
+	assert.Nil(t, g.MutateOperationContext(banned, op(ast.Mutation, "loginUser")), "login is allowed")
+	assert.Nil(t, g.MutateOperationContext(auth.WithUserID(banned, 1), op(ast.Mutation, "unbanIPOnHost")), "host staff is exempt")
+
+	for _, ctx := range []context.Context{banned, auth.WithUserID(banned, 2)} {
+		err := g.MutateOperationContext(ctx, op(ast.Mutation, "loginUser", "createPost"))
+		require.NotNil(t, err)
+		assert.Equal(t, "PermissionDenied", err.Extensions["code"])
+		assert.Equal(t, Reason, err.Extensions["reason"])
+		assert.Equal(t, "2026-02-01T00:00:00Z", err.Extensions["expiresAt"])
+	}
+}
@@ -0,0 +1,45 @@
+package ipban
+
+import (
+	"context"
+	"log"
+	"net/netip"
+	"time"
+
+	"stormlink/server/ent"
+	"stormlink/server/ent/hostipban"
+)
+
+// Store загружает действующие баны. В тестах подменяется данными в памяти.
+type Store interface {
+	// Active — баны, не истекшие к моменту now
+	Active(ctx context.Context, now time.Time) ([]*Ban, error)
+}
+
+type entStore struct {
+	client *ent.Client
+}
+
+// NewEntStore — Store поверх базы
+func NewEntStore(client *ent.Client) Store {
+	return &entStore{client: client}
+}
+
+func (s *entStore) Active(ctx context.Context, now time.Time) ([]*Ban, error) {
+	rows, err := s.client.HostIPBan.Query().
+		Where(hostipban.Or(hostipban.ExpiresAtIsNil(), hostipban.ExpiresAtGT(now))).
+		All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bans := make([]*Ban, 0, len(rows))
+	for _, r := range rows {
+		p, err := netip.ParsePrefix(r.Cidr)
+		if err != nil {
+			log.Printf("⚠️ ip ban %d: invalid cidr %q", r.ID, r.Cidr)
+			continue
+		}
+		bans = append(bans, &Ban{ID: r.ID, Prefix: p, ExpiresAt: r.ExpiresAt})
+	}
+	return bans, nil
+}
@@ -73,6 +73,11 @@ func (rw *responseWriter) Flush() {
 	}
 }
 
+// Unwrap отдаёт исходный ResponseWriter для http.ResponseController (например, снятие дедлайна записи у SSE)
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // CloseNotify реализует http.CloseNotifier для поддержки уведомлений о закрытии соединения
 func (rw *responseWriter) CloseNotify() <-chan bool {
 	if notifier, ok := rw.ResponseWriter.(http.CloseNotifier); ok {
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+
+	"stormlink/server/ipban"
+	httpCookies "stormlink/shared/http"
+)
+
+// IPBanMiddleware находит действующий бан IP клиента и кладет его в контекст запроса:
+// мутации с забаненного адреса отклоняет ipban.Guard. Ошибка проверки запрос не блокирует.
+func IPBanMiddleware(checker *ipban.Checker) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ban, err := checker.Lookup(r.Context(), httpCookies.ClientIP(r))
+			if err != nil {
+				log.Printf("⚠️ IPBanMiddleware: %v", err)
+			}
+			if ban != nil {
+				r = r.WithContext(ipban.WithBan(r.Context(), ban))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
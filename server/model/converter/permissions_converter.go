@@ -6,9 +6,10 @@ import (
 
 func ConvertPermissionsToCommunityPermissions(p *model.CommunityPermissions) *model.CommunityPermissions {
 	if p == nil {
-			return &model.CommunityPermissions{}
+			return &model.CommunityPermissions{Permissions: []string{}}
 	}
 	return &model.CommunityPermissions{
+			Permissions:                         p.Permissions,
 			CommunityRolesManagement:            p.CommunityRolesManagement,
 			CommunityUserBan:                    p.CommunityUserBan,
 			CommunityUserMute:                   p.CommunityUserMute,
@@ -0,0 +1,53 @@
+package model
+
+// Типы событий в настройках уведомлений: совпадают с типами Notification,
+// плюс community_post — новые посты в подписанных сообществах (только письма и сводки)
+const (
+	NotifyCommentReply  = "comment_reply"
+	NotifyPostLike      = "post_like"
+	NotifyCommentLike   = "comment_like"
+	NotifyUserFollow    = "user_follow"
+	NotifyMention       = "mention"
+	NotifyModeration    = "moderation"
+	NotifyCommunityPost = "community_post"
+)
+
+// NotificationTypes — все типы в порядке отображения в настройках
+var NotificationTypes = []string{
+	NotifyCommentReply,
+	NotifyMention,
+	NotifyUserFollow,
+	NotifyPostLike,
+	NotifyCommentLike,
+	NotifyCommunityPost,
+	NotifyModeration,
+}
+
+// NotificationChannels — каналы доставки уведомлений одного типа
+type NotificationChannels struct {
+	InApp  bool `json:"inApp"`
+	Email  bool `json:"email"`
+	Digest bool `json:"digest"`
+}
+
+// DefaultNotificationChannels — значения для типов, которые пользователь не настраивал
+var DefaultNotificationChannels = map[string]NotificationChannels{
+	NotifyCommentReply:  {InApp: true, Digest: true},
+	NotifyMention:       {InApp: true, Digest: true},
+	NotifyUserFollow:    {InApp: true, Digest: true},
+	NotifyPostLike:      {InApp: true},
+	NotifyCommentLike:   {InApp: true},
+	NotifyCommunityPost: {Digest: true},
+	NotifyModeration:    {InApp: true, Email: true},
+}
+
+// NotificationPreferences — настройки каналов по типам событий
+type NotificationPreferences map[string]NotificationChannels
+
+// Get возвращает каналы для типа с учётом значений по умолчанию
+func (p NotificationPreferences) Get(t string) NotificationChannels {
+	if c, ok := p[t]; ok {
+		return c
+	}
+	return DefaultNotificationChannels[t]
+}
@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+// SanctionDetails — сведения, с которыми модератор выдает бан или мут
+type SanctionDetails struct {
+	Reason string
+	// IssuedBy — выдавший модератор; 0 — выдано системой
+	IssuedBy int
+	// ExpiresAt — окончание санкции; nil — бессрочно
+	ExpiresAt   *time.Time
+	PublicNote  string
+	PrivateNote string
+	// Shadow — теневой режим: действия не запрещаются, контент видят только автор и модераторы.
+	// Только для санкций пользователя
+	Shadow bool
+}
+
+// Виды санкций, которые видит сам пользователь
+const (
+	SanctionCommunityBan  = "community_ban"
+	SanctionCommunityMute = "community_mute"
+	SanctionHostBan       = "host_ban"
+	SanctionHostMute      = "host_mute"
+)
+
+// Sanction — действующая санкция пользователя без приватной заметки модераторов
+type Sanction struct {
+	ID          int
+	Kind        string
+	CommunityID *int
+	Reason      string
+	PublicNote  string
+	ExpiresAt   *time.Time
+	CreatedAt   time.Time
+}
@@ -0,0 +1,131 @@
+// Package modlog — журнал модерации (сущность ModerationAction).
+//
+// Запись делается клиентом транзакции (tx.ModerationAction) вместе с самим действием:
+// если действие откатится, записи не будет, и наоборот — в журнале нет действий, которых не было.
+package modlog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"stormlink/server/ent"
+	"stormlink/server/ent/moderationaction"
+	sharedauth "stormlink/shared/auth"
+)
+
+// Entry — одно действие модерации
+type Entry struct {
+	Type moderationaction.Type
+	// ModeratorID — кто совершил действие; nil — пользователь из контекста, а без него — система
+	ModeratorID *int
+	// CommunityID — nil для действий уровня платформы
+	CommunityID  *int
+	TargetType   moderationaction.TargetType
+	TargetID     int
+	TargetUserID *int
+	// Before и After — сущность до и после действия (ent-сущность или любое JSON-значение); nil — нет
+	Before, After any
+}
+
+// Record сохраняет запись журнала
+func Record(ctx context.Context, c *ent.ModerationActionClient, e Entry) error {
+	before, err := Snapshot(e.Before)
+	if err != nil {
+		return err
+	}
+	after, err := Snapshot(e.After)
+	if err != nil {
+		return err
+	}
+	moderatorID := e.ModeratorID
+	if moderatorID == nil {
+		if id, err := sharedauth.UserIDFromContext(ctx); err == nil && id != 0 {
+			moderatorID = &id
+		}
+	}
+	create := c.Create().
+		SetType(e.Type).
+		SetNillableModeratorID(moderatorID).
+		SetNillableCommunityID(e.CommunityID).
+		SetTargetType(e.TargetType).
+		SetTargetID(e.TargetID).
+		SetNillableTargetUserID(e.TargetUserID)
+	if before != nil {
+		create = create.SetBefore(before)
+	}
+	if after != nil {
+		create = create.SetAfter(after)
+	}
+	if err := create.Exec(ctx); err != nil {
+		return fmt.Errorf("save moderation action %s: %w", e.Type, err)
+	}
+	return nil
+}
+
+// RecordMembers записывает изменение состава роли: role_assigned на каждого добавленного пользователя
+// и role_unassigned на каждого убранного. В e задаются роль (TargetType, TargetID), сообщество и снимок роли.
+func RecordMembers(ctx context.Context, c *ent.ModerationActionClient, e Entry, before, after []int) error {
+	was := make(map[int]bool, len(before))
+	for _, id := range before {
+		was[id] = true
+	}
+	now := make(map[int]bool, len(after))
+	for _, id := range after {
+		now[id] = true
+		if !was[id] {
+			assigned := e
+			assigned.Type = moderationaction.TypeRoleAssigned
+			assigned.TargetUserID = &id
+			if err := Record(ctx, c, assigned); err != nil {
+				return err
+			}
+		}
+	}
+	for _, id := range before {
+		if !now[id] {
+			unassigned := e
+			unassigned.Type = moderationaction.TypeRoleUnassigned
+			unassigned.TargetUserID = &id
+			if err := Record(ctx, c, unassigned); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Snapshot превращает значение в JSON-объект для before/after.
+// nil и nil-указатель дают пустой снимок. Загруженные связи ent-сущностей (edges) не сохраняются:
+// снимок описывает только саму сущность.
+func Snapshot(v any) (map[string]any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("marshal moderation snapshot: %w", err)
+	}
+	var out map[string]any
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, fmt.Errorf("moderation snapshot must be an object: %w", err)
+	}
+	delete(out, "edges")
+	return out, nil
+}
+
+// InTx выполняет fn в транзакции: действие и его запись в журнале фиксируются вместе
+func InTx(ctx context.Context, client *ent.Client, fn func(tx *ent.Tx) error) error {
+	tx, err := client.Tx(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package modlog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"stormlink/server/ent"
+)
+
+func TestSnapshot_DropsEdges(t *testing.T) {
+	ban := &ent.CommunityUserBan{ID: 3, UserID: 7, Reason: "spam"}
+	ban.Edges.User = &ent.User{ID: 7}
+
+	got, err := Snapshot(ban)
+	require.NoError(t, err)
+	assert.Equal(t, float64(3), got["id"])
+	assert.Equal(t, "spam", got["reason"])
+	assert.NotContains(t, got, "edges")
+}
+
+func TestSnapshot_Empty(t *testing.T) {
+	got, err := Snapshot(nil)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	var ban *ent.CommunityUserBan
+	got, err = Snapshot(ban)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+func TestSnapshot_RejectsNonObject(t *testing.T) {
+	_, err := Snapshot([]int{1, 2})
+	assert.Error(t, err)
+}
@@ -0,0 +1,95 @@
+package sanctions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	redis "github.com/redis/go-redis/v9"
+
+	redisx "stormlink/shared/redis"
+)
+
+// Cache хранит состояния санкций. Ошибки кеша не ломают проверку: она идет в базу.
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, bool)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration)
+	Delete(ctx context.Context, keys ...string)
+}
+
+// RedisCache — кеш в Redis, общий для всех реплик: снятие санкции видно сразу везде
+type RedisCache struct {
+	rdb    *redis.Client
+	prefix string
+}
+
+func NewRedisCache(rdb *redis.Client, prefix string) *RedisCache {
+	return &RedisCache{rdb: rdb, prefix: prefix}
+}
+
+func (c *RedisCache) Get(ctx context.Context, key string) ([]byte, bool) {
+	v, err := c.rdb.Get(ctx, c.prefix+key).Bytes()
+	if err != nil {
+		if err != redis.Nil {
+			log.Printf("⚠️ sanctions cache get: %v", err)
+		}
+		return nil, false
+	}
+	return v, true
+}
+
+func (c *RedisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) {
+	if err := c.rdb.Set(ctx, c.prefix+key, value, ttl).Err(); err != nil {
+		log.Printf("⚠️ sanctions cache set: %v", err)
+	}
+}
+
+func (c *RedisCache) Delete(ctx context.Context, keys ...string) {
+	full := make([]string, len(keys))
+	for i, k := range keys {
+		full[i] = c.prefix + k
+	}
+	if err := c.rdb.Del(ctx, full...).Err(); err != nil {
+		log.Printf("⚠️ sanctions cache delete: %v", err)
+	}
+}
+
+// CacheFromEnv настраивает кеш из ENV: SANCTIONS_CACHE=redis (по умолчанию) или off.
+// nil означает, что каждая проверка идет в базу.
+func CacheFromEnv() (Cache, error) {
+	switch v := os.Getenv("SANCTIONS_CACHE"); v {
+	case "", "redis":
+		rdb, err := redisx.NewClient()
+		if err != nil {
+			return nil, err
+		}
+		return NewRedisCache(rdb, "stormlink:sanctions:"), nil
+	case "off":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown SANCTIONS_CACHE %q", v)
+	}
+}
+
+// cached читает состояние из кеша или загружает его и кладет в кеш
+func cached[T any](ctx context.Context, c *Checker, key string, load func() (T, error)) (T, error) {
+	var v T
+	if c.cache != nil {
+		if b, ok := c.cache.Get(ctx, key); ok && json.Unmarshal(b, &v) == nil {
+			return v, nil
+		}
+	}
+	v, err := load()
+	if err != nil {
+		return v, fmt.Errorf("load sanctions %s: %w", key, err)
+	}
+	if c.cache != nil {
+		if b, err := json.Marshal(v); err == nil {
+			c.cache.Set(ctx, key, b, c.ttl)
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,236 @@
+// Package sanctions — единая проверка банов и мутов перед любым действием пользователя.
+//
+// Бан запрещает любые действия там, где он выдан: бан на платформе — везде, бан в сообществе,
+// бан сообщества на платформе и отключенное сообщество (community_has_banned) — в этом сообществе.
+// Мут запрещает только публикацию: посты, комментарии и создание сообществ.
+// Санкция с истекшим expires_at не действует, даже если воркер еще не успел ее снять.
+// Теневые санкции (shadow) ничего не запрещают: контент наказанного скрывает server/shadow.
+package sanctions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrDenied — действие запрещено санкцией; конкретная причина в *DeniedError
+var ErrDenied = errors.New("sanctioned")
+
+// Op — действие пользователя, которое могут запретить санкции
+type Op string
+
+const (
+	// OpPost — создание и правка постов
+	OpPost Op = "post"
+	// OpComment — комментарии и индикатор набора
+	OpComment Op = "comment"
+	// OpReact — лайки и закладки
+	OpReact Op = "react"
+	// OpFollow — подписки на сообщества и пользователей
+	OpFollow Op = "follow"
+	// OpCommunity — создание сообществ
+	OpCommunity Op = "community"
+	// OpProfile — профиль и загрузка медиа
+	OpProfile Op = "profile"
+)
+
+// publishes — действие публикует контент и запрещается мутом
+func (o Op) publishes() bool {
+	return o == OpPost || o == OpComment || o == OpCommunity
+}
+
+// Reason — код причины отказа для клиента
+type Reason string
+
+const (
+	HostBanned          Reason = "HOST_BANNED"
+	HostMuted           Reason = "HOST_MUTED"
+	CommunityBanned     Reason = "COMMUNITY_BANNED"
+	CommunityMuted      Reason = "COMMUNITY_MUTED"
+	CommunityDisabled   Reason = "COMMUNITY_DISABLED"
+	CommunityHostBanned Reason = "COMMUNITY_HOST_BANNED"
+	CommunityHostMuted  Reason = "COMMUNITY_HOST_MUTED"
+)
+
+// DeniedError — отказ с причиной и сроком санкции (nil — бессрочно)
+type DeniedError struct {
+	Reason    Reason
+	ExpiresAt *time.Time
+}
+
+func (e *DeniedError) Error() string {
+	if e.ExpiresAt != nil {
+		return fmt.Sprintf("%s: %s until %s", ErrDenied, e.Reason, e.ExpiresAt.Format(time.RFC3339))
+	}
+	return fmt.Sprintf("%s: %s", ErrDenied, e.Reason)
+}
+
+func (e *DeniedError) Is(target error) bool { return target == ErrDenied }
+
+// Sanction — действующая запись бана или мута; nil — санкции нет
+type Sanction struct {
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+func (s *Sanction) active(now time.Time) bool {
+	return s != nil && (s.ExpiresAt == nil || s.ExpiresAt.After(now))
+}
+
+// longest — санкция, которая действует дольше (бессрочная дольше любой)
+func longest(a, b *Sanction) *Sanction {
+	switch {
+	case a == nil:
+		return b
+	case b == nil || a.ExpiresAt == nil:
+		return a
+	case b.ExpiresAt == nil || b.ExpiresAt.After(*a.ExpiresAt):
+		return b
+	default:
+		return a
+	}
+}
+
+// UserState — санкции пользователя на платформе
+type UserState struct {
+	Ban  *Sanction `json:"ban,omitempty"`
+	Mute *Sanction `json:"mute,omitempty"`
+	// Shadow — теневой бан или мут
+	Shadow *Sanction `json:"shadow,omitempty"`
+}
+
+// CommunityState — санкции сообщества на платформе
+type CommunityState struct {
+	Disabled bool      `json:"disabled,omitempty"`
+	Ban      *Sanction `json:"ban,omitempty"`
+	Mute     *Sanction `json:"mute,omitempty"`
+}
+
+// MemberState — санкции пользователя в сообществе
+type MemberState struct {
+	Ban  *Sanction `json:"ban,omitempty"`
+	Mute *Sanction `json:"mute,omitempty"`
+	// Shadow — теневой бан или мут
+	Shadow *Sanction `json:"shadow,omitempty"`
+}
+
+// Checker проверяет санкции; состояния кешируются, кеш сбрасывается при выдаче и снятии санкций
+type Checker struct {
+	store Store
+	cache Cache
+	ttl   time.Duration
+	now   func() time.Time
+}
+
+// New — проверка поверх store; cache может быть nil
+func New(store Store, cache Cache) *Checker {
+	return &Checker{store: store, cache: cache, ttl: 5 * time.Minute, now: time.Now}
+}
+
+// Check разрешает действие op пользователя userID (в сообществе, если communityID задан)
+// или возвращает *DeniedError
+func (c *Checker) Check(ctx context.Context, userID int, communityID *int, op Op) error {
+	now := c.now()
+	u, err := c.user(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if u.Ban.active(now) {
+		return &DeniedError{Reason: HostBanned, ExpiresAt: u.Ban.ExpiresAt}
+	}
+
+	var cs CommunityState
+	var m MemberState
+	if communityID != nil {
+		if cs, err = c.community(ctx, *communityID); err != nil {
+			return err
+		}
+		if cs.Disabled {
+			return &DeniedError{Reason: CommunityDisabled}
+		}
+		if cs.Ban.active(now) {
+			return &DeniedError{Reason: CommunityHostBanned, ExpiresAt: cs.Ban.ExpiresAt}
+		}
+		if m, err = c.member(ctx, *communityID, userID); err != nil {
+			return err
+		}
+		if m.Ban.active(now) {
+			return &DeniedError{Reason: CommunityBanned, ExpiresAt: m.Ban.ExpiresAt}
+		}
+	}
+
+	if !op.publishes() {
+		return nil
+	}
+	if u.Mute.active(now) {
+		return &DeniedError{Reason: HostMuted, ExpiresAt: u.Mute.ExpiresAt}
+	}
+	if cs.Mute.active(now) {
+		return &DeniedError{Reason: CommunityHostMuted, ExpiresAt: cs.Mute.ExpiresAt}
+	}
+	if m.Mute.active(now) {
+		return &DeniedError{Reason: CommunityMuted, ExpiresAt: m.Mute.ExpiresAt}
+	}
+	return nil
+}
+
+// Shadowed сообщает, что контент пользователя скрыт теневой санкцией
+// на платформе или в сообществе communityID (если задан)
+func (c *Checker) Shadowed(ctx context.Context, userID int, communityID *int) (bool, error) {
+	now := c.now()
+	u, err := c.user(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if u.Shadow.active(now) || communityID == nil {
+		return u.Shadow.active(now), nil
+	}
+	m, err := c.member(ctx, *communityID, userID)
+	if err != nil {
+		return false, err
+	}
+	return m.Shadow.active(now), nil
+}
+
+// InvalidateUser сбрасывает кеш санкций пользователя на платформе
+func (c *Checker) InvalidateUser(ctx context.Context, userID int) {
+	c.invalidate(ctx, userKey(userID))
+}
+
+// InvalidateCommunity сбрасывает кеш санкций сообщества на платформе
+func (c *Checker) InvalidateCommunity(ctx context.Context, communityID int) {
+	c.invalidate(ctx, communityKey(communityID))
+}
+
+// InvalidateMember сбрасывает кеш санкций пользователя в сообществе
+func (c *Checker) InvalidateMember(ctx context.Context, communityID, userID int) {
+	c.invalidate(ctx, memberKey(communityID, userID))
+}
+
+func (c *Checker) invalidate(ctx context.Context, key string) {
+	if c.cache != nil {
+		c.cache.Delete(ctx, key)
+	}
+}
+
+func userKey(userID int) string           { return fmt.Sprintf("user:%d", userID) }
+func communityKey(communityID int) string { return fmt.Sprintf("community:%d", communityID) }
+func memberKey(communityID, userID int) string {
+	return fmt.Sprintf("community:%d:user:%d", communityID, userID)
+}
+
+func (c *Checker) user(ctx context.Context, userID int) (UserState, error) {
+	return cached(ctx, c, userKey(userID), func() (UserState, error) { return c.store.User(ctx, userID) })
+}
+
+func (c *Checker) community(ctx context.Context, communityID int) (CommunityState, error) {
+	return cached(ctx, c, communityKey(communityID), func() (CommunityState, error) {
+		return c.store.Community(ctx, communityID)
+	})
+}
+
+func (c *Checker) member(ctx context.Context, communityID, userID int) (MemberState, error) {
+	return cached(ctx, c, memberKey(communityID, userID), func() (MemberState, error) {
+		return c.store.Member(ctx, communityID, userID)
+	})
+}
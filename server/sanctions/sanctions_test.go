@@ -0,0 +1,175 @@
+package sanctions
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memStore — Store в памяти; loads считает обращения, чтобы проверить кеш
+type memStore struct {
+	users       map[int]UserState
+	communities map[int]CommunityState
+	members     map[[2]int]MemberState
+	loads       int
+}
+
+func (m *memStore) User(_ context.Context, userID int) (UserState, error) {
+	m.loads++
+	return m.users[userID], nil
+}
+
+func (m *memStore) Community(_ context.Context, communityID int) (CommunityState, error) {
+	m.loads++
+	return m.communities[communityID], nil
+}
+
+func (m *memStore) Member(_ context.Context, communityID, userID int) (MemberState, error) {
+	m.loads++
+	return m.members[[2]int{communityID, userID}], nil
+}
+
+// memCache — Cache в памяти без срока жизни
+type memCache map[string][]byte
+
+func (c memCache) Get(_ context.Context, key string) ([]byte, bool) {
+	v, ok := c[key]
+	return v, ok
+}
+
+func (c memCache) Set(_ context.Context, key string, value []byte, _ time.Duration) { c[key] = value }
+
+func (c memCache) Delete(_ context.Context, keys ...string) {
+	for _, k := range keys {
+		delete(c, k)
+	}
+}
+
+const (
+	testUser      = 1
+	testCommunity = 10
+)
+
+var allOps = []Op{OpPost, OpComment, OpReact, OpFollow, OpCommunity, OpProfile}
+
+func TestCheck_Matrix(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	until := now.Add(time.Hour)
+	permanent := &Sanction{}
+	temporary := &Sanction{ExpiresAt: &until}
+
+	cases := []struct {
+		name   string
+		store  memStore
+		reason Reason
+		// onlyPublishing — санкция запрещает только публикацию (мут)
+		onlyPublishing bool
+		expires        *time.Time
+	}{
+		{"host ban", memStore{users: map[int]UserState{testUser: {Ban: permanent}}}, HostBanned, false, nil},
+		{"host mute", memStore{users: map[int]UserState{testUser: {Mute: temporary}}}, HostMuted, true, &until},
+		{"community disabled", memStore{communities: map[int]CommunityState{testCommunity: {Disabled: true}}}, CommunityDisabled, false, nil},
+		{"community host ban", memStore{communities: map[int]CommunityState{testCommunity: {Ban: temporary}}}, CommunityHostBanned, false, &until},
+		{"community host mute", memStore{communities: map[int]CommunityState{testCommunity: {Mute: permanent}}}, CommunityHostMuted, true, nil},
+		{"community ban", memStore{members: map[[2]int]MemberState{{testCommunity, testUser}: {Ban: temporary}}}, CommunityBanned, false, &until},
+		{"community mute", memStore{members: map[[2]int]MemberState{{testCommunity, testUser}: {Mute: permanent}}}, CommunityMuted, true, nil},
+	}
+	for _, tc := range cases {
+		c := New(&tc.store, nil)
+		c.now = func() time.Time { return now }
+		hostLevel := tc.reason == HostBanned || tc.reason == HostMuted
+		for _, op := range allOps {
+			for _, inCommunity := range []bool{true, false} {
+				var cid *int
+				if inCommunity {
+					id := testCommunity
+					cid = &id
+				}
+				err := c.Check(ctx, testUser, cid, op)
+				denied := (inCommunity || hostLevel) && (!tc.onlyPublishing || op.publishes())
+				if !denied {
+					assert.NoError(t, err, "%s: %s in community=%v", tc.name, op, inCommunity)
+					continue
+				}
+				var d *DeniedError
+				require.True(t, errors.As(err, &d), "%s: %s in community=%v", tc.name, op, inCommunity)
+				assert.ErrorIs(t, err, ErrDenied)
+				assert.Equal(t, tc.reason, d.Reason, "%s: %s", tc.name, op)
+				assert.Equal(t, tc.expires, d.ExpiresAt, "%s: %s", tc.name, op)
+			}
+		}
+	}
+}
+
+func TestCheck_ExpiredSanctionIgnored(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Minute)
+	c := New(&memStore{users: map[int]UserState{testUser: {Ban: &Sanction{ExpiresAt: &past}}}}, nil)
+	c.now = func() time.Time { return now }
+	assert.NoError(t, c.Check(context.Background(), testUser, nil, OpPost))
+}
+
+func TestShadowed(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Minute)
+	id, other := testCommunity, testCommunity+1
+	store := &memStore{
+		users:   map[int]UserState{2: {Shadow: &Sanction{}}, 3: {Shadow: &Sanction{ExpiresAt: &past}}},
+		members: map[[2]int]MemberState{{testCommunity, testUser}: {Shadow: &Sanction{}}},
+	}
+	c := New(store, nil)
+	c.now = func() time.Time { return now }
+
+	// Теневая санкция ничего не запрещает
+	for _, op := range allOps {
+		assert.NoError(t, c.Check(ctx, testUser, &id, op))
+		assert.NoError(t, c.Check(ctx, 2, &id, op))
+	}
+
+	shadowed := func(userID int, communityID *int) bool {
+		ok, err := c.Shadowed(ctx, userID, communityID)
+		require.NoError(t, err)
+		return ok
+	}
+	assert.True(t, shadowed(testUser, &id))
+	assert.False(t, shadowed(testUser, &other))
+	assert.False(t, shadowed(testUser, nil))
+	assert.True(t, shadowed(2, nil), "host shadow applies everywhere")
+	assert.True(t, shadowed(2, &other))
+	assert.False(t, shadowed(3, nil), "expired shadow sanction is ignored")
+}
+
+func TestCheck_CachedUntilInvalidated(t *testing.T) {
+	ctx := context.Background()
+	id := testCommunity
+	store := &memStore{users: map[int]UserState{}}
+	c := New(store, memCache{})
+
+	require.NoError(t, c.Check(ctx, testUser, &id, OpPost))
+	loads := store.loads
+	require.NoError(t, c.Check(ctx, testUser, &id, OpPost))
+	assert.Equal(t, loads, store.loads, "second check must be served from cache")
+
+	// Бан выдан: без сброса кеша проверка его не видит, после сброса — видит
+	store.users[testUser] = UserState{Ban: &Sanction{}}
+	assert.NoError(t, c.Check(ctx, testUser, &id, OpPost))
+	c.InvalidateUser(ctx, testUser)
+	assert.ErrorIs(t, c.Check(ctx, testUser, &id, OpPost), ErrDenied)
+}
+
+func TestLongest(t *testing.T) {
+	soon := time.Now().Add(time.Minute)
+	later := soon.Add(time.Hour)
+	a, b, forever := &Sanction{ExpiresAt: &soon}, &Sanction{ExpiresAt: &later}, &Sanction{}
+	assert.Same(t, b, longest(a, b))
+	assert.Same(t, b, longest(b, a))
+	assert.Same(t, forever, longest(a, forever))
+	assert.Same(t, forever, longest(forever, b))
+	assert.Same(t, a, longest(nil, a))
+}
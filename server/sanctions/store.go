@@ -0,0 +1,151 @@
+package sanctions
+
+import (
+	"context"
+	"time"
+
+	"stormlink/server/ent"
+	"stormlink/server/ent/community"
+	"stormlink/server/ent/communityuserban"
+	"stormlink/server/ent/communityusermute"
+	"stormlink/server/ent/hostcommunityban"
+	"stormlink/server/ent/hostcommunitymute"
+	"stormlink/server/ent/hostuserban"
+	"stormlink/server/ent/hostusermute"
+	"stormlink/server/ent/user"
+	"stormlink/server/shadow"
+)
+
+// Store загружает действующие санкции. В тестах подменяется данными в памяти.
+type Store interface {
+	User(ctx context.Context, userID int) (UserState, error)
+	Community(ctx context.Context, communityID int) (CommunityState, error)
+	Member(ctx context.Context, communityID, userID int) (MemberState, error)
+}
+
+type entStore struct {
+	client *ent.Client
+}
+
+// NewEntStore — Store поверх базы
+func NewEntStore(client *ent.Client) Store {
+	return &entStore{client: client}
+}
+
+func (s *entStore) User(ctx context.Context, userID int) (UserState, error) {
+	now := time.Now()
+	var st UserState
+	bans, err := s.client.HostUserBan.Query().
+		Where(
+			hostuserban.HasUserWith(user.IDEQ(userID)),
+			hostuserban.Or(hostuserban.ExpiresAtIsNil(), hostuserban.ExpiresAtGT(now)),
+		).
+		All(ctx)
+	if err != nil {
+		return st, err
+	}
+	for _, b := range bans {
+		if b.Shadow {
+			st.Shadow = longest(st.Shadow, &Sanction{ExpiresAt: b.ExpiresAt})
+			continue
+		}
+		st.Ban = longest(st.Ban, &Sanction{ExpiresAt: b.ExpiresAt})
+	}
+	mutes, err := s.client.HostUserMute.Query().
+		Where(
+			hostusermute.HasUserWith(user.IDEQ(userID)),
+			hostusermute.Or(hostusermute.ExpiresAtIsNil(), hostusermute.ExpiresAtGT(now)),
+		).
+		All(ctx)
+	if err != nil {
+		return st, err
+	}
+	for _, m := range mutes {
+		if m.Shadow {
+			st.Shadow = longest(st.Shadow, &Sanction{ExpiresAt: m.ExpiresAt})
+			continue
+		}
+		st.Mute = longest(st.Mute, &Sanction{ExpiresAt: m.ExpiresAt})
+	}
+	return st, nil
+}
+
+func (s *entStore) Community(ctx context.Context, communityID int) (CommunityState, error) {
+	now := time.Now()
+	var st CommunityState
+	cm, err := s.client.Community.Query().
+		Where(community.IDEQ(communityID)).
+		Select(community.FieldCommunityHasBanned).
+		Only(ctx)
+	if err != nil {
+		return st, err
+	}
+	st.Disabled = cm.CommunityHasBanned
+	bans, err := s.client.HostCommunityBan.Query().
+		Where(
+			hostcommunityban.CommunityIDEQ(communityID),
+			hostcommunityban.Or(hostcommunityban.ExpiresAtIsNil(), hostcommunityban.ExpiresAtGT(now)),
+		).
+		All(ctx)
+	if err != nil {
+		return st, err
+	}
+	for _, b := range bans {
+		st.Ban = longest(st.Ban, &Sanction{ExpiresAt: b.ExpiresAt})
+	}
+	mutes, err := s.client.HostCommunityMute.Query().
+		Where(
+			hostcommunitymute.CommunityIDEQ(communityID),
+			hostcommunitymute.Or(hostcommunitymute.ExpiresAtIsNil(), hostcommunitymute.ExpiresAtGT(now)),
+		).
+		All(ctx)
+	if err != nil {
+		return st, err
+	}
+	for _, m := range mutes {
+		st.Mute = longest(st.Mute, &Sanction{ExpiresAt: m.ExpiresAt})
+	}
+	return st, nil
+}
+
+func (s *entStore) Member(ctx context.Context, communityID, userID int) (MemberState, error) {
+	// Теневые санкции скрыты от зрителя, но проверке они нужны
+	ctx = shadow.Unfiltered(ctx)
+	now := time.Now()
+	var st MemberState
+	bans, err := s.client.CommunityUserBan.Query().
+		Where(
+			communityuserban.CommunityIDEQ(communityID),
+			communityuserban.UserIDEQ(userID),
+			communityuserban.Or(communityuserban.ExpiresAtIsNil(), communityuserban.ExpiresAtGT(now)),
+		).
+		All(ctx)
+	if err != nil {
+		return st, err
+	}
+	for _, b := range bans {
+		if b.Shadow {
+			st.Shadow = longest(st.Shadow, &Sanction{ExpiresAt: b.ExpiresAt})
+			continue
+		}
+		st.Ban = longest(st.Ban, &Sanction{ExpiresAt: b.ExpiresAt})
+	}
+	mutes, err := s.client.CommunityUserMute.Query().
+		Where(
+			communityusermute.CommunityIDEQ(communityID),
+			communityusermute.UserIDEQ(userID),
+			communityusermute.Or(communityusermute.ExpiresAtIsNil(), communityusermute.ExpiresAtGT(now)),
+		).
+		All(ctx)
+	if err != nil {
+		return st, err
+	}
+	for _, m := range mutes {
+		if m.Shadow {
+			st.Shadow = longest(st.Shadow, &Sanction{ExpiresAt: m.ExpiresAt})
+			continue
+		}
+		st.Mute = longest(st.Mute, &Sanction{ExpiresAt: m.ExpiresAt})
+	}
+	return st, nil
+}
@@ -0,0 +1,71 @@
+package shadow
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	gqlgen "github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+
+	"stormlink/server/authz"
+	"stormlink/server/ent"
+	"stormlink/server/ent/community"
+	"stormlink/server/ent/role"
+	"stormlink/server/ent/user"
+)
+
+// Moderators — исключения по правам authz: персонал платформы с правом бана или мута пользователей
+// видит весь теневой контент, модераторы сообщества с теми же правами — контент своего сообщества
+func Moderators(client *ent.Client, a *authz.Authorizer) Moderation {
+	return func(ctx context.Context, viewerID int) (Exemption, error) {
+		for _, action := range []authz.Action{authz.HostBanUser, authz.HostMuteUser} {
+			ok, err := a.Can(ctx, viewerID, action, authz.Host())
+			if err != nil {
+				return Exemption{}, fmt.Errorf("shadow: %w", err)
+			}
+			if ok {
+				return Exemption{All: true}, nil
+			}
+		}
+
+		// Права в сообществе бывают только у владельца и участников ролей
+		owned, err := client.Community.Query().Where(community.OwnerIDEQ(viewerID)).IDs(ctx)
+		if err != nil {
+			return Exemption{}, fmt.Errorf("shadow: owned communities: %w", err)
+		}
+		withRoles, err := client.Role.Query().
+			Where(role.HasUsersWith(user.IDEQ(viewerID))).
+			Select(role.FieldCommunityID).
+			Ints(ctx)
+		if err != nil {
+			return Exemption{}, fmt.Errorf("shadow: role communities: %w", err)
+		}
+		candidates := append(owned, withRoles...)
+		slices.Sort(candidates)
+
+		var ex Exemption
+		for _, id := range slices.Compact(candidates) {
+			p, err := a.Permissions(ctx, viewerID, id)
+			if err != nil {
+				return Exemption{}, fmt.Errorf("shadow: %w", err)
+			}
+			if p.CommunityUserBan || p.CommunityUserMute {
+				ex.Communities = append(ex.Communities, id)
+			}
+		}
+		return ex, nil
+	}
+}
+
+// Middleware запоминает исключения зрителя на время запроса или мутации (см. WithMemo).
+// Подписки живут долго, поэтому в них права перепроверяются на каждое событие.
+func Middleware() gqlgen.OperationMiddleware {
+	return func(ctx context.Context, next gqlgen.OperationHandler) gqlgen.ResponseHandler {
+		op := gqlgen.GetOperationContext(ctx).Operation
+		if op == nil || op.Operation == ast.Subscription {
+			return next(ctx)
+		}
+		return next(WithMemo(ctx))
+	}
+}
@@ -0,0 +1,234 @@
+// Package shadow — теневые санкции (теневой бан и тихий мут, поле shadow санкций пользователя).
+//
+// Пользователь под теневой санкцией пишет как обычно, но его посты, комментарии и лайки видят
+// только он сам и модераторы: санкция на платформе скрывает контент везде, санкция в сообществе —
+// в этом сообществе. Фильтр ставится перехватчиками на клиент ent (Register) и действует во всех
+// запросах постов, комментариев и лайков, включая счетчики, загрузку связей и подписки.
+// Сами теневые баны и муты в сообществе (Community.bans и mutes) видят только модераторы: для
+// остальных, в том числе наказанного, их нет. Служебные проверки санкций читают их в ctx
+// Unfiltered.
+package shadow
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"entgo.io/ent/dialect/sql"
+
+	"stormlink/server/ent"
+	"stormlink/server/ent/comment"
+	"stormlink/server/ent/commentlike"
+	"stormlink/server/ent/communityuserban"
+	"stormlink/server/ent/communityusermute"
+	"stormlink/server/ent/hostuserban"
+	"stormlink/server/ent/hostusermute"
+	"stormlink/server/ent/intercept"
+	"stormlink/server/ent/post"
+	"stormlink/server/ent/postlike"
+	"stormlink/server/ent/predicate"
+	"stormlink/shared/auth"
+)
+
+// Exemption — где зритель видит теневой контент: везде (персонал платформы)
+// или в сообществах, где он модерирует
+type Exemption struct {
+	All         bool
+	Communities []int
+}
+
+// Moderation определяет исключения авторизованного зрителя
+type Moderation func(ctx context.Context, viewerID int) (Exemption, error)
+
+// Register ставит фильтр на запросы постов, комментариев и их лайков, банов и мутов в сообществе
+func Register(client *ent.Client, moderation Moderation) {
+	f := &filter{moderation: moderation, now: time.Now}
+	client.CommunityUserBan.Intercept(intercept.TraverseCommunityUserBan(func(ctx context.Context, q *ent.CommunityUserBanQuery) error {
+		r, err := f.rule(ctx)
+		if err != nil || r == nil {
+			return err
+		}
+		q.Where(communityuserban.Or(communityuserban.ShadowEQ(false), communityuserban.CommunityIDIn(r.exempt...)))
+		return nil
+	}))
+	client.CommunityUserMute.Intercept(intercept.TraverseCommunityUserMute(func(ctx context.Context, q *ent.CommunityUserMuteQuery) error {
+		r, err := f.rule(ctx)
+		if err != nil || r == nil {
+			return err
+		}
+		q.Where(communityusermute.Or(communityusermute.ShadowEQ(false), communityusermute.CommunityIDIn(r.exempt...)))
+		return nil
+	}))
+	client.Post.Intercept(intercept.TraversePost(func(ctx context.Context, q *ent.PostQuery) error {
+		r, err := f.rule(ctx)
+		if err != nil || r == nil {
+			return err
+		}
+		q.Where(predicate.Post(func(s *sql.Selector) {
+			s.Where(sql.Not(r.hidden(s.Dialect(), s.C(post.FieldAuthorID), s.C(post.FieldCommunityID))))
+		}))
+		return nil
+	}))
+	client.Comment.Intercept(intercept.TraverseComment(func(ctx context.Context, q *ent.CommentQuery) error {
+		r, err := f.rule(ctx)
+		if err != nil || r == nil {
+			return err
+		}
+		q.Where(predicate.Comment(func(s *sql.Selector) {
+			s.Where(sql.Not(r.hidden(s.Dialect(), s.C(comment.FieldAuthorID), s.C(comment.FieldCommunityID))))
+		}))
+		return nil
+	}))
+	client.PostLike.Intercept(intercept.TraversePostLike(func(ctx context.Context, q *ent.PostLikeQuery) error {
+		r, err := f.rule(ctx)
+		if err != nil || r == nil {
+			return err
+		}
+		q.Where(predicate.PostLike(func(s *sql.Selector) {
+			s.Where(sql.Not(r.hiddenVia(s, s.C(postlike.FieldUserID), post.Table, s.C(postlike.FieldPostID), post.FieldCommunityID)))
+		}))
+		return nil
+	}))
+	client.CommentLike.Intercept(intercept.TraverseCommentLike(func(ctx context.Context, q *ent.CommentLikeQuery) error {
+		r, err := f.rule(ctx)
+		if err != nil || r == nil {
+			return err
+		}
+		q.Where(predicate.CommentLike(func(s *sql.Selector) {
+			s.Where(sql.Not(r.hiddenVia(s, s.C(commentlike.FieldUserID), comment.Table, s.C(commentlike.FieldCommentID), comment.FieldCommunityID)))
+		}))
+		return nil
+	}))
+}
+
+type filter struct {
+	moderation Moderation
+	now        func() time.Time
+}
+
+// rule — условие фильтра для зрителя из ctx; nil — фильтр не нужен
+func (f *filter) rule(ctx context.Context) (*rule, error) {
+	if unfiltered, _ := ctx.Value(unfilteredKey{}).(bool); unfiltered {
+		return nil, nil
+	}
+	viewerID, _ := auth.UserIDFromContext(ctx)
+	var ex Exemption
+	if viewerID != 0 {
+		var err error
+		if ex, err = exemption(ctx, f.moderation, viewerID); err != nil {
+			return nil, err
+		}
+	}
+	if ex.All {
+		return nil, nil
+	}
+	return &rule{viewerID: viewerID, exempt: ex.Communities, now: f.now()}, nil
+}
+
+// rule скрывает контент чужих авторов под теневой санкцией вне сообществ exempt
+type rule struct {
+	viewerID int
+	exempt   []int
+	now      time.Time
+}
+
+// hidden — контент автора author в сообществе community (колонки внешнего запроса) скрыт от зрителя
+func (r *rule) hidden(dialect, author, community string) *sql.Predicate {
+	preds := []*sql.Predicate{sql.NEQ(author, r.viewerID)}
+	if len(r.exempt) > 0 {
+		exempt := make([]any, len(r.exempt))
+		for i, id := range r.exempt {
+			exempt[i] = id
+		}
+		preds = append(preds, sql.NotIn(community, exempt...))
+	}
+	preds = append(preds, sql.Or(
+		r.sanction(dialect, hostuserban.Table, func(t *sql.SelectTable) *sql.Predicate {
+			return sql.ColumnsEQ(t.C(hostuserban.UserColumn), author)
+		}),
+		r.sanction(dialect, hostusermute.Table, func(t *sql.SelectTable) *sql.Predicate {
+			return sql.ColumnsEQ(t.C(hostusermute.UserColumn), author)
+		}),
+		r.sanction(dialect, communityuserban.Table, func(t *sql.SelectTable) *sql.Predicate {
+			return sql.And(
+				sql.ColumnsEQ(t.C(communityuserban.FieldUserID), author),
+				sql.ColumnsEQ(t.C(communityuserban.FieldCommunityID), community),
+			)
+		}),
+		r.sanction(dialect, communityusermute.Table, func(t *sql.SelectTable) *sql.Predicate {
+			return sql.And(
+				sql.ColumnsEQ(t.C(communityusermute.FieldUserID), author),
+				sql.ColumnsEQ(t.C(communityusermute.FieldCommunityID), community),
+			)
+		}),
+	))
+	return sql.And(preds...)
+}
+
+// hiddenVia — hidden для лайка: сообщество берется из родительской строки parent (пост или комментарий)
+func (r *rule) hiddenVia(s *sql.Selector, author, parent, parentID, community string) *sql.Predicate {
+	t := sql.Table(parent)
+	return sql.Exists(sql.Dialect(s.Dialect()).Select(t.C("id")).From(t).Where(sql.And(
+		sql.ColumnsEQ(t.C("id"), parentID),
+		r.hidden(s.Dialect(), author, t.C(community)),
+	)))
+}
+
+// sanction — есть действующая теневая санкция из таблицы table, подходящая под match
+func (r *rule) sanction(dialect, table string, match func(*sql.SelectTable) *sql.Predicate) *sql.Predicate {
+	t := sql.Table(table)
+	return sql.Exists(sql.Dialect(dialect).Select(t.C("id")).From(t).Where(sql.And(
+		match(t),
+		sql.EQ(t.C("shadow"), true),
+		sql.Or(sql.IsNull(t.C("expires_at")), sql.GT(t.C("expires_at"), r.now)),
+	)))
+}
+
+type unfilteredKey struct{}
+
+// Unfiltered снимает фильтр с запросов в ctx: проверкам санкций нужны и теневые санкции,
+// которых не видит сам зритель
+func Unfiltered(ctx context.Context) context.Context {
+	return context.WithValue(ctx, unfilteredKey{}, true)
+}
+
+// Public — ctx анонимного зрителя: в нем не видно теневого контента ни одного автора.
+// Так считается то, что рассылается всем подписчикам сразу, а не одному зрителю
+func Public(ctx context.Context) context.Context {
+	return auth.WithUserID(ctx, 0)
+}
+
+type memoKey struct{}
+
+// memo — исключения зрителей, вычисленные в рамках одной операции
+type memo struct {
+	mu     sync.Mutex
+	byUser map[int]Exemption
+}
+
+// WithMemo запоминает исключения зрителей в ctx: права проверяются один раз на операцию,
+// а не на каждый запрос постов и комментариев
+func WithMemo(ctx context.Context) context.Context {
+	return context.WithValue(ctx, memoKey{}, &memo{byUser: map[int]Exemption{}})
+}
+
+func exemption(ctx context.Context, moderation Moderation, viewerID int) (Exemption, error) {
+	m, _ := ctx.Value(memoKey{}).(*memo)
+	if m == nil {
+		return moderation(ctx, viewerID)
+	}
+	m.mu.Lock()
+	ex, ok := m.byUser[viewerID]
+	m.mu.Unlock()
+	if ok {
+		return ex, nil
+	}
+	ex, err := moderation(ctx, viewerID)
+	if err != nil {
+		return ex, err
+	}
+	m.mu.Lock()
+	m.byUser[viewerID] = ex
+	m.mu.Unlock()
+	return ex, nil
+}
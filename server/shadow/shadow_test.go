@@ -0,0 +1,68 @@
+package shadow
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"entgo.io/ent/dialect"
+	"entgo.io/ent/dialect/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"stormlink/server/ent/post"
+	"stormlink/shared/auth"
+)
+
+func TestRule_Hidden(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	r := &rule{viewerID: 7, exempt: []int{3, 4}, now: now}
+	s := sql.Dialect(dialect.Postgres).Select("*").From(sql.Table(post.Table))
+	s.Where(sql.Not(r.hidden(s.Dialect(), s.C(post.FieldAuthorID), s.C(post.FieldCommunityID))))
+	query, args := s.Query()
+
+	// Свои посты и посты сообществ, где зритель модерирует, не скрываются
+	assert.Contains(t, query, `"posts"."author_id" <> $1`)
+	assert.Contains(t, query, `"posts"."community_id" NOT IN ($2, $3)`)
+	for _, table := range []string{"host_user_bans", "host_user_mutes", "community_user_bans", "community_user_mutes"} {
+		assert.Contains(t, query, `FROM "`+table+`"`)
+	}
+	assert.Contains(t, query, `"community_user_bans"."community_id" = "posts"."community_id"`)
+	assert.Equal(t, 4, strings.Count(query, `"shadow" AND`))
+	assert.Equal(t, []any{7, 3, 4}, args[:3])
+	assert.Contains(t, args, now)
+}
+
+func TestFilter_Rule(t *testing.T) {
+	calls := 0
+	f := &filter{now: time.Now, moderation: func(_ context.Context, viewerID int) (Exemption, error) {
+		calls++
+		return Exemption{All: viewerID == 1, Communities: []int{viewerID}}, nil
+	}}
+	ctx := context.Background()
+
+	r, err := f.rule(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, r, "anonymous viewers are filtered")
+	assert.Empty(t, r.exempt)
+	assert.Zero(t, calls, "anonymous viewers have no exemptions to look up")
+
+	r, err = f.rule(auth.WithUserID(ctx, 1))
+	require.NoError(t, err)
+	assert.Nil(t, r, "host staff sees everything")
+
+	// В рамках операции права зрителя проверяются один раз
+	calls = 0
+	op := WithMemo(auth.WithUserID(ctx, 2))
+	for range 3 {
+		r, err = f.rule(op)
+		require.NoError(t, err)
+		assert.Equal(t, []int{2}, r.exempt)
+	}
+	assert.Equal(t, 1, calls)
+
+	r, err = f.rule(Unfiltered(auth.WithUserID(ctx, 2)))
+	require.NoError(t, err)
+	assert.Nil(t, r, "service checks see shadow sanctions")
+}
@@ -0,0 +1,198 @@
+package automodrule
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"stormlink/server/automod"
+	"stormlink/server/ent"
+	"stormlink/server/ent/automodrule"
+	"stormlink/server/ent/moderationaction"
+	"stormlink/server/graphql/models"
+	"stormlink/server/modlog"
+)
+
+// AutomodRuleUsecase — правила автомодерации сообществ и платформы. Права проверяет вызывающий.
+type AutomodRuleUsecase interface {
+	CreateAutomodRule(ctx context.Context, input models.CreateAutomodRuleInput) (*ent.AutomodRule, error)
+	UpdateAutomodRule(ctx context.Context, id int, input models.UpdateAutomodRuleInput) (*ent.AutomodRule, error)
+	DeleteAutomodRule(ctx context.Context, id int) error
+	GetAutomodRule(ctx context.Context, id int) (*ent.AutomodRule, error)
+	// GetAutomodRules — правила сообщества; communityID == nil — правила платформы
+	GetAutomodRules(ctx context.Context, communityID *int) ([]*ent.AutomodRule, error)
+	// Draft собирает несохраненное правило из входа для пробного прогона
+	Draft(input models.CreateAutomodRuleInput) (*ent.AutomodRule, error)
+}
+
+type automodRuleUsecase struct {
+	client *ent.Client
+}
+
+func NewAutomodRuleUsecase(client *ent.Client) AutomodRuleUsecase {
+	return &automodRuleUsecase{client: client}
+}
+
+func (uc *automodRuleUsecase) CreateAutomodRule(ctx context.Context, input models.CreateAutomodRuleInput) (*ent.AutomodRule, error) {
+	draft, err := uc.Draft(input)
+	if err != nil {
+		return nil, err
+	}
+
+	// Создаем правило вместе с записью в журнале модерации
+	var rule *ent.AutomodRule
+	err = modlog.InTx(ctx, uc.client, func(tx *ent.Tx) error {
+		rule, err = tx.AutomodRule.Create().
+			SetNillableCommunityID(draft.CommunityID).
+			SetTitle(draft.Title).
+			SetKind(draft.Kind).
+			SetPatterns(draft.Patterns).
+			SetNillableThreshold(draft.Threshold).
+			SetNillableWindowMinutes(draft.WindowMinutes).
+			SetAction(draft.Action).
+			SetMuteMinutes(draft.MuteMinutes).
+			SetNillableMessage(draft.Message).
+			SetEnabled(draft.Enabled).
+			Save(ctx)
+		if err != nil {
+			return fmt.Errorf("create automod rule: %w", err)
+		}
+		return modlog.Record(ctx, tx.ModerationAction, ruleEntry(moderationaction.TypeRuleCreated, rule, nil, rule))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (uc *automodRuleUsecase) UpdateAutomodRule(ctx context.Context, id int, input models.UpdateAutomodRuleInput) (*ent.AutomodRule, error) {
+	before, err := uc.client.AutomodRule.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("rule not found: %w", err)
+	}
+
+	// Параметры проверяются в итоговом виде: новые значения поверх прежних
+	patterns, threshold, window := before.Patterns, before.Threshold, before.WindowMinutes
+	if input.Patterns != nil {
+		patterns = input.Patterns
+	}
+	if input.Threshold != nil {
+		threshold = input.Threshold
+	}
+	if input.WindowMinutes != nil {
+		window = input.WindowMinutes
+	}
+	if err := automod.Validate(before.Kind, patterns, threshold, window); err != nil {
+		return nil, err
+	}
+	if input.MuteMinutes != nil && *input.MuteMinutes <= 0 {
+		return nil, fmt.Errorf("muteMinutes must be positive")
+	}
+
+	var rule *ent.AutomodRule
+	err = modlog.InTx(ctx, uc.client, func(tx *ent.Tx) error {
+		update := tx.AutomodRule.UpdateOneID(id).
+			SetPatterns(patterns).
+			SetNillableThreshold(threshold).
+			SetNillableWindowMinutes(window).
+			SetNillableMuteMinutes(input.MuteMinutes).
+			SetNillableEnabled(input.Enabled)
+		if input.Title != nil {
+			update = update.SetTitle(*input.Title)
+		}
+		if input.Action != nil {
+			update = update.SetAction(*input.Action)
+		}
+		if input.Message != nil {
+			update = update.SetMessage(*input.Message)
+		}
+		if rule, err = update.Save(ctx); err != nil {
+			return fmt.Errorf("update automod rule: %w", err)
+		}
+		return modlog.Record(ctx, tx.ModerationAction, ruleEntry(moderationaction.TypeRuleUpdated, rule, before, rule))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (uc *automodRuleUsecase) DeleteAutomodRule(ctx context.Context, id int) error {
+	rule, err := uc.client.AutomodRule.Get(ctx, id)
+	if err != nil {
+		return fmt.Errorf("rule not found: %w", err)
+	}
+
+	// Удаляем правило вместе с записью в журнале модерации
+	return modlog.InTx(ctx, uc.client, func(tx *ent.Tx) error {
+		if err := tx.AutomodRule.DeleteOneID(id).Exec(ctx); err != nil {
+			return fmt.Errorf("delete automod rule: %w", err)
+		}
+		return modlog.Record(ctx, tx.ModerationAction, ruleEntry(moderationaction.TypeRuleDeleted, rule, rule, nil))
+	})
+}
+
+func (uc *automodRuleUsecase) GetAutomodRule(ctx context.Context, id int) (*ent.AutomodRule, error) {
+	return uc.client.AutomodRule.Get(ctx, id)
+}
+
+func (uc *automodRuleUsecase) GetAutomodRules(ctx context.Context, communityID *int) ([]*ent.AutomodRule, error) {
+	q := uc.client.AutomodRule.Query()
+	if communityID != nil {
+		q = q.Where(automodrule.CommunityIDEQ(*communityID))
+	} else {
+		q = q.Where(automodrule.CommunityIDIsNil())
+	}
+	return q.Order(ent.Asc(automodrule.FieldID)).All(ctx)
+}
+
+func (uc *automodRuleUsecase) Draft(input models.CreateAutomodRuleInput) (*ent.AutomodRule, error) {
+	if err := automod.Validate(input.Kind, input.Patterns, input.Threshold, input.WindowMinutes); err != nil {
+		return nil, err
+	}
+	rule := &ent.AutomodRule{
+		Title:         input.Title,
+		Kind:          input.Kind,
+		Patterns:      input.Patterns,
+		Threshold:     input.Threshold,
+		WindowMinutes: input.WindowMinutes,
+		Action:        input.Action,
+		MuteMinutes:   60,
+		Message:       input.Message,
+		Enabled:       true,
+	}
+	if input.CommunityID != nil {
+		id, err := strconv.Atoi(*input.CommunityID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid communityID: %w", err)
+		}
+		rule.CommunityID = &id
+	}
+	if input.MuteMinutes != nil {
+		if *input.MuteMinutes <= 0 {
+			return nil, fmt.Errorf("muteMinutes must be positive")
+		}
+		rule.MuteMinutes = *input.MuteMinutes
+	}
+	if input.Enabled != nil {
+		rule.Enabled = *input.Enabled
+	}
+	return rule, nil
+}
+
+// ruleEntry — запись журнала модерации о правиле автомодерации
+func ruleEntry(t moderationaction.Type, rule, before, after *ent.AutomodRule) modlog.Entry {
+	e := modlog.Entry{
+		Type:        t,
+		CommunityID: rule.CommunityID,
+		TargetType:  moderationaction.TargetTypeAutomodRule,
+		TargetID:    rule.ID,
+	}
+	if before != nil {
+		e.Before = before
+	}
+	if after != nil {
+		e.After = after
+	}
+	return e
+}
@@ -3,26 +3,32 @@ package ban
 import (
 	"context"
 	"fmt"
+	"sort"
 	"stormlink/server/ent"
 	"stormlink/server/ent/community"
 	"stormlink/server/ent/communityuserban"
 	"stormlink/server/ent/communityusermute"
 	"stormlink/server/ent/hostcommunityban"
 	"stormlink/server/ent/hostuserban"
+	"stormlink/server/ent/hostusermute"
+	"stormlink/server/ent/moderationaction"
 	"stormlink/server/ent/user"
+	"stormlink/server/model"
+	"stormlink/server/modlog"
+	"time"
 )
 
 type BanUsecase interface {
 	// Host bans
-	BanUserFromHost(ctx context.Context, userID, hostID int) (*ent.HostUserBan, error)
+	BanUserFromHost(ctx context.Context, userID, hostID int, d model.SanctionDetails) (*ent.HostUserBan, error)
 	UnbanUserFromHost(ctx context.Context, banID int) error
-	BanCommunityFromHost(ctx context.Context, communityID, hostID int) (*ent.HostCommunityBan, error)
+	BanCommunityFromHost(ctx context.Context, communityID, hostID int, d model.SanctionDetails) (*ent.HostCommunityBan, error)
 	UnbanCommunityFromHost(ctx context.Context, banID int) error
 
 	// Community bans/mutes
-	BanUserFromCommunity(ctx context.Context, userID, communityID int) (*ent.CommunityUserBan, error)
+	BanUserFromCommunity(ctx context.Context, userID, communityID int, d model.SanctionDetails) (*ent.CommunityUserBan, error)
 	UnbanUserFromCommunity(ctx context.Context, banID int) error
-	MuteUserInCommunity(ctx context.Context, userID, communityID int) (*ent.CommunityUserMute, error)
+	MuteUserInCommunity(ctx context.Context, userID, communityID int, d model.SanctionDetails) (*ent.CommunityUserMute, error)
 	UnmuteUserInCommunity(ctx context.Context, muteID int) error
 
 	// Queries
@@ -30,6 +36,8 @@ type BanUsecase interface {
 	GetHostCommunityBans(ctx context.Context) ([]*ent.HostCommunityBan, error)
 	GetCommunityUserBans(ctx context.Context, communityID int) ([]*ent.CommunityUserBan, error)
 	GetCommunityUserMutes(ctx context.Context, communityID int) ([]*ent.CommunityUserMute, error)
+	// GetUserSanctions — действующие баны и муты пользователя, новые первыми; теневые не показываются
+	GetUserSanctions(ctx context.Context, userID int) ([]*model.Sanction, error)
 }
 
 type banUsecase struct {
@@ -40,10 +48,13 @@ func NewBanUsecase(client *ent.Client) BanUsecase {
 	return &banUsecase{client: client}
 }
 
-func (uc *banUsecase) BanUserFromHost(ctx context.Context, userID, hostID int) (*ent.HostUserBan, error) {
+func (uc *banUsecase) BanUserFromHost(ctx context.Context, userID, hostID int, d model.SanctionDetails) (*ent.HostUserBan, error) {
 	// Проверяем, что пользователь не забанен уже
 	exists, err := uc.client.HostUserBan.Query().
-		Where(hostuserban.HasUserWith(user.IDEQ(userID))).
+		Where(
+			hostuserban.HasUserWith(user.IDEQ(userID)),
+			hostuserban.Or(hostuserban.ExpiresAtIsNil(), hostuserban.ExpiresAtGT(time.Now())),
+		).
 		Exist(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("check existing ban: %w", err)
@@ -52,12 +63,32 @@ func (uc *banUsecase) BanUserFromHost(ctx context.Context, userID, hostID int) (
 		return nil, fmt.Errorf("user already banned from host")
 	}
 
-	// Создаем бан
-	ban, err := uc.client.HostUserBan.Create().
-		SetUserID(userID).
-		Save(ctx)
+	// Создаем бан вместе с записью в журнале модерации
+	var ban *ent.HostUserBan
+	err = modlog.InTx(ctx, uc.client, func(tx *ent.Tx) error {
+		ban, err = tx.HostUserBan.Create().
+			SetUserID(userID).
+			SetReason(d.Reason).
+			SetNillableIssuedBy(issuer(d)).
+			SetNillableExpiresAt(d.ExpiresAt).
+			SetPublicNote(d.PublicNote).
+			SetPrivateNote(d.PrivateNote).
+			SetShadow(d.Shadow).
+			Save(ctx)
+		if err != nil {
+			return fmt.Errorf("create host user ban: %w", err)
+		}
+		return modlog.Record(ctx, tx.ModerationAction, modlog.Entry{
+			Type:         moderationaction.TypeUserBanned,
+			ModeratorID:  issuer(d),
+			TargetType:   moderationaction.TargetTypeUser,
+			TargetID:     userID,
+			TargetUserID: &userID,
+			After:        ban,
+		})
+	})
 	if err != nil {
-		return nil, fmt.Errorf("create host user ban: %w", err)
+		return nil, err
 	}
 
 	return ban, nil
@@ -65,24 +96,37 @@ func (uc *banUsecase) BanUserFromHost(ctx context.Context, userID, hostID int) (
 
 func (uc *banUsecase) UnbanUserFromHost(ctx context.Context, banID int) error {
 	// Проверяем, что бан существует
-	ban, err := uc.client.HostUserBan.Get(ctx, banID)
+	ban, err := uc.client.HostUserBan.Query().
+		Where(hostuserban.IDEQ(banID)).
+		WithUser().
+		Only(ctx)
 	if err != nil {
 		return fmt.Errorf("ban not found: %w", err)
 	}
-
-	// Удаляем бан
-	err = uc.client.HostUserBan.DeleteOne(ban).Exec(ctx)
-	if err != nil {
-		return fmt.Errorf("delete host user ban: %w", err)
-	}
-
-	return nil
+	userID := ban.Edges.User.ID
+
+	// Удаляем бан вместе с записью в журнале модерации
+	return modlog.InTx(ctx, uc.client, func(tx *ent.Tx) error {
+		if err := tx.HostUserBan.DeleteOneID(ban.ID).Exec(ctx); err != nil {
+			return fmt.Errorf("delete host user ban: %w", err)
+		}
+		return modlog.Record(ctx, tx.ModerationAction, modlog.Entry{
+			Type:         moderationaction.TypeUserUnbanned,
+			TargetType:   moderationaction.TargetTypeUser,
+			TargetID:     userID,
+			TargetUserID: &userID,
+			Before:       ban,
+		})
+	})
 }
 
-func (uc *banUsecase) BanCommunityFromHost(ctx context.Context, communityID, hostID int) (*ent.HostCommunityBan, error) {
+func (uc *banUsecase) BanCommunityFromHost(ctx context.Context, communityID, hostID int, d model.SanctionDetails) (*ent.HostCommunityBan, error) {
 	// Проверяем, что сообщество не забанено уже
 	exists, err := uc.client.HostCommunityBan.Query().
-		Where(hostcommunityban.HasCommunityWith(community.IDEQ(communityID))).
+		Where(
+			hostcommunityban.HasCommunityWith(community.IDEQ(communityID)),
+			hostcommunityban.Or(hostcommunityban.ExpiresAtIsNil(), hostcommunityban.ExpiresAtGT(time.Now())),
+		).
 		Exist(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("check existing ban: %w", err)
@@ -91,12 +135,31 @@ func (uc *banUsecase) BanCommunityFromHost(ctx context.Context, communityID, hos
 		return nil, fmt.Errorf("community already banned from host")
 	}
 
-	// Создаем бан
-	ban, err := uc.client.HostCommunityBan.Create().
-		SetCommunityID(communityID).
-		Save(ctx)
+	// Создаем бан вместе с записью в журнале модерации
+	var ban *ent.HostCommunityBan
+	err = modlog.InTx(ctx, uc.client, func(tx *ent.Tx) error {
+		ban, err = tx.HostCommunityBan.Create().
+			SetCommunityID(communityID).
+			SetReason(d.Reason).
+			SetNillableIssuedBy(issuer(d)).
+			SetNillableExpiresAt(d.ExpiresAt).
+			SetPublicNote(d.PublicNote).
+			SetPrivateNote(d.PrivateNote).
+			Save(ctx)
+		if err != nil {
+			return fmt.Errorf("create host community ban: %w", err)
+		}
+		return modlog.Record(ctx, tx.ModerationAction, modlog.Entry{
+			Type:        moderationaction.TypeCommunityBanned,
+			ModeratorID: issuer(d),
+			CommunityID: &communityID,
+			TargetType:  moderationaction.TargetTypeCommunity,
+			TargetID:    communityID,
+			After:       ban,
+		})
+	})
 	if err != nil {
-		return nil, fmt.Errorf("create host community ban: %w", err)
+		return nil, err
 	}
 
 	return ban, nil
@@ -109,21 +172,28 @@ func (uc *banUsecase) UnbanCommunityFromHost(ctx context.Context, banID int) err
 		return fmt.Errorf("ban not found: %w", err)
 	}
 
-	// Удаляем бан
-	err = uc.client.HostCommunityBan.DeleteOne(ban).Exec(ctx)
-	if err != nil {
-		return fmt.Errorf("delete host community ban: %w", err)
-	}
-
-	return nil
+	// Удаляем бан вместе с записью в журнале модерации
+	return modlog.InTx(ctx, uc.client, func(tx *ent.Tx) error {
+		if err := tx.HostCommunityBan.DeleteOneID(ban.ID).Exec(ctx); err != nil {
+			return fmt.Errorf("delete host community ban: %w", err)
+		}
+		return modlog.Record(ctx, tx.ModerationAction, modlog.Entry{
+			Type:        moderationaction.TypeCommunityUnbanned,
+			CommunityID: &ban.CommunityID,
+			TargetType:  moderationaction.TargetTypeCommunity,
+			TargetID:    ban.CommunityID,
+			Before:      ban,
+		})
+	})
 }
 
-func (uc *banUsecase) BanUserFromCommunity(ctx context.Context, userID, communityID int) (*ent.CommunityUserBan, error) {
+func (uc *banUsecase) BanUserFromCommunity(ctx context.Context, userID, communityID int, d model.SanctionDetails) (*ent.CommunityUserBan, error) {
 	// Проверяем, что пользователь не забанен уже
 	exists, err := uc.client.CommunityUserBan.Query().
 		Where(
 			communityuserban.UserIDEQ(userID),
 			communityuserban.CommunityIDEQ(communityID),
+			communityuserban.Or(communityuserban.ExpiresAtIsNil(), communityuserban.ExpiresAtGT(time.Now())),
 		).
 		Exist(ctx)
 	if err != nil {
@@ -133,13 +203,34 @@ func (uc *banUsecase) BanUserFromCommunity(ctx context.Context, userID, communit
 		return nil, fmt.Errorf("user already banned from community")
 	}
 
-	// Создаем бан
-	ban, err := uc.client.CommunityUserBan.Create().
-		SetUserID(userID).
-		SetCommunityID(communityID).
-		Save(ctx)
+	// Создаем бан вместе с записью в журнале модерации
+	var ban *ent.CommunityUserBan
+	err = modlog.InTx(ctx, uc.client, func(tx *ent.Tx) error {
+		ban, err = tx.CommunityUserBan.Create().
+			SetUserID(userID).
+			SetCommunityID(communityID).
+			SetReason(d.Reason).
+			SetNillableIssuedBy(issuer(d)).
+			SetNillableExpiresAt(d.ExpiresAt).
+			SetPublicNote(d.PublicNote).
+			SetPrivateNote(d.PrivateNote).
+			SetShadow(d.Shadow).
+			Save(ctx)
+		if err != nil {
+			return fmt.Errorf("create community user ban: %w", err)
+		}
+		return modlog.Record(ctx, tx.ModerationAction, modlog.Entry{
+			Type:         moderationaction.TypeUserBanned,
+			ModeratorID:  issuer(d),
+			CommunityID:  &communityID,
+			TargetType:   moderationaction.TargetTypeUser,
+			TargetID:     userID,
+			TargetUserID: &userID,
+			After:        ban,
+		})
+	})
 	if err != nil {
-		return nil, fmt.Errorf("create community user ban: %w", err)
+		return nil, err
 	}
 
 	return ban, nil
@@ -152,21 +243,29 @@ func (uc *banUsecase) UnbanUserFromCommunity(ctx context.Context, banID int) err
 		return fmt.Errorf("ban not found: %w", err)
 	}
 
-	// Удаляем бан
-	err = uc.client.CommunityUserBan.DeleteOne(ban).Exec(ctx)
-	if err != nil {
-		return fmt.Errorf("delete community user ban: %w", err)
-	}
-
-	return nil
+	// Удаляем бан вместе с записью в журнале модерации
+	return modlog.InTx(ctx, uc.client, func(tx *ent.Tx) error {
+		if err := tx.CommunityUserBan.DeleteOneID(ban.ID).Exec(ctx); err != nil {
+			return fmt.Errorf("delete community user ban: %w", err)
+		}
+		return modlog.Record(ctx, tx.ModerationAction, modlog.Entry{
+			Type:         moderationaction.TypeUserUnbanned,
+			CommunityID:  &ban.CommunityID,
+			TargetType:   moderationaction.TargetTypeUser,
+			TargetID:     ban.UserID,
+			TargetUserID: &ban.UserID,
+			Before:       ban,
+		})
+	})
 }
 
-func (uc *banUsecase) MuteUserInCommunity(ctx context.Context, userID, communityID int) (*ent.CommunityUserMute, error) {
+func (uc *banUsecase) MuteUserInCommunity(ctx context.Context, userID, communityID int, d model.SanctionDetails) (*ent.CommunityUserMute, error) {
 	// Проверяем, что пользователь не замьючен уже
 	exists, err := uc.client.CommunityUserMute.Query().
 		Where(
 			communityusermute.UserIDEQ(userID),
 			communityusermute.CommunityIDEQ(communityID),
+			communityusermute.Or(communityusermute.ExpiresAtIsNil(), communityusermute.ExpiresAtGT(time.Now())),
 		).
 		Exist(ctx)
 	if err != nil {
@@ -176,13 +275,34 @@ func (uc *banUsecase) MuteUserInCommunity(ctx context.Context, userID, community
 		return nil, fmt.Errorf("user already muted in community")
 	}
 
-	// Создаем мут
-	mute, err := uc.client.CommunityUserMute.Create().
-		SetUserID(userID).
-		SetCommunityID(communityID).
-		Save(ctx)
+	// Создаем мут вместе с записью в журнале модерации
+	var mute *ent.CommunityUserMute
+	err = modlog.InTx(ctx, uc.client, func(tx *ent.Tx) error {
+		mute, err = tx.CommunityUserMute.Create().
+			SetUserID(userID).
+			SetCommunityID(communityID).
+			SetReason(d.Reason).
+			SetNillableIssuedBy(issuer(d)).
+			SetNillableExpiresAt(d.ExpiresAt).
+			SetPublicNote(d.PublicNote).
+			SetPrivateNote(d.PrivateNote).
+			SetShadow(d.Shadow).
+			Save(ctx)
+		if err != nil {
+			return fmt.Errorf("create community user mute: %w", err)
+		}
+		return modlog.Record(ctx, tx.ModerationAction, modlog.Entry{
+			Type:         moderationaction.TypeUserMuted,
+			ModeratorID:  issuer(d),
+			CommunityID:  &communityID,
+			TargetType:   moderationaction.TargetTypeUser,
+			TargetID:     userID,
+			TargetUserID: &userID,
+			After:        mute,
+		})
+	})
 	if err != nil {
-		return nil, fmt.Errorf("create community user mute: %w", err)
+		return nil, err
 	}
 
 	return mute, nil
@@ -195,18 +315,26 @@ func (uc *banUsecase) UnmuteUserInCommunity(ctx context.Context, muteID int) err
 		return fmt.Errorf("mute not found: %w", err)
 	}
 
-	// Удаляем мут
-	err = uc.client.CommunityUserMute.DeleteOne(mute).Exec(ctx)
-	if err != nil {
-		return fmt.Errorf("delete community user mute: %w", err)
-	}
-
-	return nil
+	// Удаляем мут вместе с записью в журнале модерации
+	return modlog.InTx(ctx, uc.client, func(tx *ent.Tx) error {
+		if err := tx.CommunityUserMute.DeleteOneID(mute.ID).Exec(ctx); err != nil {
+			return fmt.Errorf("delete community user mute: %w", err)
+		}
+		return modlog.Record(ctx, tx.ModerationAction, modlog.Entry{
+			Type:         moderationaction.TypeUserUnmuted,
+			CommunityID:  &mute.CommunityID,
+			TargetType:   moderationaction.TargetTypeUser,
+			TargetID:     mute.UserID,
+			TargetUserID: &mute.UserID,
+			Before:       mute,
+		})
+	})
 }
 
 func (uc *banUsecase) GetHostUserBans(ctx context.Context) ([]*ent.HostUserBan, error) {
 	return uc.client.HostUserBan.Query().
 		WithUser().
+		WithIssuer().
 		Order(ent.Desc("created_at")).
 		All(ctx)
 }
@@ -214,6 +342,7 @@ func (uc *banUsecase) GetHostUserBans(ctx context.Context) ([]*ent.HostUserBan,
 func (uc *banUsecase) GetHostCommunityBans(ctx context.Context) ([]*ent.HostCommunityBan, error) {
 	return uc.client.HostCommunityBan.Query().
 		WithCommunity().
+		WithIssuer().
 		Order(ent.Desc("created_at")).
 		All(ctx)
 }
@@ -222,6 +351,7 @@ func (uc *banUsecase) GetCommunityUserBans(ctx context.Context, communityID int)
 	return uc.client.CommunityUserBan.Query().
 		Where(communityuserban.CommunityIDEQ(communityID)).
 		WithUser().
+		WithIssuer().
 		Order(ent.Desc("created_at")).
 		All(ctx)
 }
@@ -230,6 +360,91 @@ func (uc *banUsecase) GetCommunityUserMutes(ctx context.Context, communityID int
 	return uc.client.CommunityUserMute.Query().
 		Where(communityusermute.CommunityIDEQ(communityID)).
 		WithUser().
+		WithIssuer().
 		Order(ent.Desc("created_at")).
 		All(ctx)
 }
+
+func (uc *banUsecase) GetUserSanctions(ctx context.Context, userID int) ([]*model.Sanction, error) {
+	now := time.Now()
+	var out []*model.Sanction
+
+	communityBans, err := uc.client.CommunityUserBan.Query().
+		Where(
+			communityuserban.UserIDEQ(userID),
+			communityuserban.Or(communityuserban.ExpiresAtIsNil(), communityuserban.ExpiresAtGT(now)),
+			communityuserban.ShadowEQ(false),
+		).
+		All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get community bans: %w", err)
+	}
+	for _, b := range communityBans {
+		out = append(out, &model.Sanction{
+			ID: b.ID, Kind: model.SanctionCommunityBan, CommunityID: &b.CommunityID,
+			Reason: b.Reason, PublicNote: b.PublicNote, ExpiresAt: b.ExpiresAt, CreatedAt: b.CreatedAt,
+		})
+	}
+
+	communityMutes, err := uc.client.CommunityUserMute.Query().
+		Where(
+			communityusermute.UserIDEQ(userID),
+			communityusermute.Or(communityusermute.ExpiresAtIsNil(), communityusermute.ExpiresAtGT(now)),
+			communityusermute.ShadowEQ(false),
+		).
+		All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get community mutes: %w", err)
+	}
+	for _, m := range communityMutes {
+		out = append(out, &model.Sanction{
+			ID: m.ID, Kind: model.SanctionCommunityMute, CommunityID: &m.CommunityID,
+			Reason: m.Reason, PublicNote: m.PublicNote, ExpiresAt: m.ExpiresAt, CreatedAt: m.CreatedAt,
+		})
+	}
+
+	hostBans, err := uc.client.HostUserBan.Query().
+		Where(
+			hostuserban.HasUserWith(user.IDEQ(userID)),
+			hostuserban.Or(hostuserban.ExpiresAtIsNil(), hostuserban.ExpiresAtGT(now)),
+			hostuserban.ShadowEQ(false),
+		).
+		All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get host bans: %w", err)
+	}
+	for _, b := range hostBans {
+		out = append(out, &model.Sanction{
+			ID: b.ID, Kind: model.SanctionHostBan,
+			Reason: b.Reason, PublicNote: b.PublicNote, ExpiresAt: b.ExpiresAt, CreatedAt: b.CreatedAt,
+		})
+	}
+
+	hostMutes, err := uc.client.HostUserMute.Query().
+		Where(
+			hostusermute.HasUserWith(user.IDEQ(userID)),
+			hostusermute.Or(hostusermute.ExpiresAtIsNil(), hostusermute.ExpiresAtGT(now)),
+			hostusermute.ShadowEQ(false),
+		).
+		All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get host mutes: %w", err)
+	}
+	for _, m := range hostMutes {
+		out = append(out, &model.Sanction{
+			ID: m.ID, Kind: model.SanctionHostMute,
+			Reason: m.Reason, PublicNote: m.PublicNote, ExpiresAt: m.ExpiresAt, CreatedAt: m.CreatedAt,
+		})
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out, nil
+}
+
+// issuer — выдавший модератор для SetNillableIssuedBy
+func issuer(d model.SanctionDetails) *int {
+	if d.IssuedBy == 0 {
+		return nil
+	}
+	return &d.IssuedBy
+}
@@ -0,0 +1,279 @@
+package banappeal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"stormlink/server/ent"
+	"stormlink/server/ent/banappeal"
+	"stormlink/server/ent/hostuserban"
+	"stormlink/server/ent/moderationaction"
+	"stormlink/server/graphql/models"
+	"stormlink/server/modlog"
+	"stormlink/server/usecase/pagination"
+)
+
+// Cooldown — пауза между апелляциями пользователя в одном сообществе (или на платформе)
+const Cooldown = 7 * 24 * time.Hour
+
+var (
+	ErrNotYourBan      = errors.New("ban belongs to another user")
+	ErrAlreadyAppealed = errors.New("ban has already been appealed")
+	ErrDecided         = errors.New("appeal is already decided")
+)
+
+// CooldownError — новая апелляция раньше, чем прошел Cooldown с предыдущей
+type CooldownError struct {
+	Until time.Time
+}
+
+func (e *CooldownError) Error() string {
+	return fmt.Sprintf("next appeal is allowed after %s", e.Until.Format(time.RFC3339))
+}
+
+// BanAppealUsecase — апелляции на баны. Права проверяет вызывающий.
+type BanAppealUsecase interface {
+	// Submit подает апелляцию на действующий бан пользователя: одну на бан и не чаще Cooldown
+	Submit(ctx context.Context, userID int, scope banappeal.Scope, banID int, message string) (*ent.BanAppeal, error)
+	Get(ctx context.Context, id int) (*ent.BanAppeal, error)
+	// Queue — апелляции, старые первыми: scope host — на баны платформы; community — на баны
+	// сообщества communityID, а без него — всех сообществ
+	Queue(ctx context.Context, scope banappeal.Scope, communityID *int, status banappeal.Status, first int, after *string) (*models.BanAppealsConnection, error)
+	// ByUser — апелляции пользователя, новые первыми
+	ByUser(ctx context.Context, userID int) ([]*ent.BanAppeal, error)
+	// Decide принимает или отклоняет апелляцию; принятие в той же транзакции снимает бан,
+	// если он еще действует (lifted). Апелляция, решенная параллельно, — ErrDecided.
+	// override — пересмотр уже отклоненной апелляции на бан в сообществе персоналом платформы.
+	Decide(ctx context.Context, id, moderatorID int, status banappeal.Status, message *string, override bool) (a *ent.BanAppeal, lifted bool, err error)
+}
+
+type banAppealUsecase struct {
+	client *ent.Client
+}
+
+func NewBanAppealUsecase(client *ent.Client) BanAppealUsecase {
+	return &banAppealUsecase{client: client}
+}
+
+// ban — сведения бана, которые копируются в апелляцию
+type ban struct {
+	userID      int
+	communityID *int
+	reason      string
+	issuedBy    *int
+	expiresAt   *time.Time
+	shadow      bool
+}
+
+func (uc *banAppealUsecase) Submit(ctx context.Context, userID int, scope banappeal.Scope, banID int, message string) (*ent.BanAppeal, error) {
+	b, err := uc.ban(ctx, scope, banID)
+	if err != nil {
+		return nil, err
+	}
+	// О теневом бане наказанный не знает, и обжаловать его нельзя
+	if b.shadow {
+		return nil, fmt.Errorf("ban not found")
+	}
+	if b.userID != userID {
+		return nil, ErrNotYourBan
+	}
+	if b.expiresAt != nil && !b.expiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("ban has expired")
+	}
+
+	exists, err := uc.client.BanAppeal.Query().
+		Where(banappeal.ScopeEQ(scope), banappeal.BanIDEQ(banID)).
+		Exist(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("check existing appeal: %w", err)
+	}
+	if exists {
+		return nil, ErrAlreadyAppealed
+	}
+
+	// Cooldown считается от последней апелляции в том же сообществе или на платформе
+	last := uc.client.BanAppeal.Query().Where(banappeal.UserIDEQ(userID), banappeal.ScopeEQ(scope))
+	if b.communityID != nil {
+		last = last.Where(banappeal.CommunityIDEQ(*b.communityID))
+	}
+	prev, err := last.Order(ent.Desc(banappeal.FieldCreatedAt)).First(ctx)
+	switch {
+	case err == nil:
+		if until := prev.CreatedAt.Add(Cooldown); until.After(time.Now()) {
+			return nil, &CooldownError{Until: until}
+		}
+	case !ent.IsNotFound(err):
+		return nil, fmt.Errorf("find previous appeal: %w", err)
+	}
+
+	a, err := uc.client.BanAppeal.Create().
+		SetUserID(userID).
+		SetScope(scope).
+		SetBanID(banID).
+		SetNillableCommunityID(b.communityID).
+		SetBanReason(b.reason).
+		SetNillableBanIssuedBy(b.issuedBy).
+		SetNillableBanExpiresAt(b.expiresAt).
+		SetMessage(message).
+		Save(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("create ban appeal: %w", err)
+	}
+	return a, nil
+}
+
+// ban находит бан апелляции
+func (uc *banAppealUsecase) ban(ctx context.Context, scope banappeal.Scope, id int) (ban, error) {
+	switch scope {
+	case banappeal.ScopeCommunity:
+		b, err := uc.client.CommunityUserBan.Get(ctx, id)
+		if err != nil {
+			return ban{}, fmt.Errorf("ban not found: %w", err)
+		}
+		return ban{userID: b.UserID, communityID: &b.CommunityID, reason: b.Reason, issuedBy: b.IssuedBy, expiresAt: b.ExpiresAt, shadow: b.Shadow}, nil
+	case banappeal.ScopeHost:
+		b, err := uc.client.HostUserBan.Query().Where(hostuserban.IDEQ(id)).WithUser().Only(ctx)
+		if err != nil {
+			return ban{}, fmt.Errorf("ban not found: %w", err)
+		}
+		return ban{userID: b.Edges.User.ID, reason: b.Reason, issuedBy: b.IssuedBy, expiresAt: b.ExpiresAt, shadow: b.Shadow}, nil
+	}
+	return ban{}, fmt.Errorf("unknown scope %q", scope)
+}
+
+func (uc *banAppealUsecase) Get(ctx context.Context, id int) (*ent.BanAppeal, error) {
+	return uc.client.BanAppeal.Get(ctx, id)
+}
+
+func (uc *banAppealUsecase) Queue(ctx context.Context, scope banappeal.Scope, communityID *int, status banappeal.Status, first int, after *string) (*models.BanAppealsConnection, error) {
+	if first <= 0 {
+		first = pagination.DefaultLimit
+	}
+	base := uc.client.BanAppeal.Query().Where(banappeal.ScopeEQ(scope), banappeal.StatusEQ(status))
+	if communityID != nil {
+		base = base.Where(banappeal.CommunityIDEQ(*communityID))
+	}
+	page, err := pagination.Paginate(ctx, base, pagination.Args{First: &first, After: after}, pagination.Order[*ent.BanAppeal]{
+		Field: banappeal.FieldCreatedAt,
+		Key:   func(a *ent.BanAppeal) (time.Time, int) { return a.CreatedAt, a.ID },
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list ban appeals: %w", err)
+	}
+	edges := pagination.MapEdges(page, func(cur string, a *ent.BanAppeal) *models.BanAppealEdge {
+		return &models.BanAppealEdge{Cursor: cur, Node: a}
+	})
+	return &models.BanAppealsConnection{Edges: edges, PageInfo: page.PageInfo}, nil
+}
+
+func (uc *banAppealUsecase) ByUser(ctx context.Context, userID int) ([]*ent.BanAppeal, error) {
+	return uc.client.BanAppeal.Query().
+		Where(banappeal.UserIDEQ(userID)).
+		Order(ent.Desc(banappeal.FieldCreatedAt), ent.Desc(banappeal.FieldID)).
+		All(ctx)
+}
+
+func (uc *banAppealUsecase) Decide(ctx context.Context, id, moderatorID int, status banappeal.Status, message *string, override bool) (*ent.BanAppeal, bool, error) {
+	if status == banappeal.StatusPending {
+		return nil, false, fmt.Errorf("invalid status %q", status)
+	}
+	before, err := uc.client.BanAppeal.Get(ctx, id)
+	if err != nil {
+		return nil, false, err
+	}
+	// Пересмотреть можно только отказ по бану в сообществе
+	overriding := override && before.Scope == banappeal.ScopeCommunity && before.Status == banappeal.StatusRejected
+	if before.Status != banappeal.StatusPending && !overriding {
+		return nil, false, ErrDecided
+	}
+
+	var (
+		out    *ent.BanAppeal
+		lifted bool
+	)
+	err = modlog.InTx(ctx, uc.client, func(tx *ent.Tx) error {
+		// Решение записывается, только если статус не сменился с момента чтения:
+		// из двух параллельных решений проходит одно
+		n, err := tx.BanAppeal.Update().
+			Where(banappeal.IDEQ(id), banappeal.StatusEQ(before.Status)).
+			SetStatus(status).
+			SetDecidedByID(moderatorID).
+			SetNillableDecisionMessage(message).
+			SetDecidedAt(time.Now()).
+			SetOverridden(overriding).
+			Save(ctx)
+		if err != nil {
+			return fmt.Errorf("decide ban appeal: %w", err)
+		}
+		if n == 0 {
+			return ErrDecided
+		}
+		if out, err = tx.BanAppeal.Get(ctx, id); err != nil {
+			return fmt.Errorf("decide ban appeal: %w", err)
+		}
+		if status == banappeal.StatusAccepted {
+			if lifted, err = liftBan(ctx, tx, before, moderatorID); err != nil {
+				return err
+			}
+		}
+		t := moderationaction.TypeAppealAccepted
+		if status == banappeal.StatusRejected {
+			t = moderationaction.TypeAppealRejected
+		}
+		return modlog.Record(ctx, tx.ModerationAction, modlog.Entry{
+			Type:         t,
+			ModeratorID:  &moderatorID,
+			CommunityID:  before.CommunityID,
+			TargetType:   moderationaction.TargetTypeBanAppeal,
+			TargetID:     before.ID,
+			TargetUserID: &before.UserID,
+			Before:       before,
+			After:        out,
+		})
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return out, lifted, nil
+}
+
+// liftBan снимает бан апелляции вместе с записью в журнале модерации; бан, снятый раньше
+// (вручную или по сроку), пропускается
+func liftBan(ctx context.Context, tx *ent.Tx, a *ent.BanAppeal, moderatorID int) (bool, error) {
+	entry := modlog.Entry{
+		Type:         moderationaction.TypeUserUnbanned,
+		ModeratorID:  &moderatorID,
+		CommunityID:  a.CommunityID,
+		TargetType:   moderationaction.TargetTypeUser,
+		TargetID:     a.UserID,
+		TargetUserID: &a.UserID,
+	}
+	if a.Scope == banappeal.ScopeHost {
+		b, err := tx.HostUserBan.Get(ctx, a.BanID)
+		if ent.IsNotFound(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("get host user ban: %w", err)
+		}
+		if err := tx.HostUserBan.DeleteOne(b).Exec(ctx); err != nil {
+			return false, fmt.Errorf("delete host user ban: %w", err)
+		}
+		entry.Before = b
+	} else {
+		b, err := tx.CommunityUserBan.Get(ctx, a.BanID)
+		if ent.IsNotFound(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("get community user ban: %w", err)
+		}
+		if err := tx.CommunityUserBan.DeleteOne(b).Exec(ctx); err != nil {
+			return false, fmt.Errorf("delete community user ban: %w", err)
+		}
+		entry.Before = b
+	}
+	return true, modlog.Record(ctx, tx.ModerationAction, entry)
+}
@@ -2,14 +2,12 @@ package comment
 
 import (
 	"context"
-	"encoding/base64"
 	"fmt"
 	"stormlink/server/ent"
 	"stormlink/server/ent/comment"
 	"stormlink/server/ent/post"
 	"stormlink/server/graphql/models"
-	"strconv"
-	"strings"
+	"stormlink/server/usecase/pagination"
 	"time"
 )
 
@@ -60,7 +58,7 @@ func (uc *commentUsecase) GetCommentsByPostID(ctx context.Context, postID int, h
 // GetCommentsByPostIDLight возвращает облегченную версию комментариев к посту (только основные поля + media для URL)
 func (uc *commentUsecase) GetCommentsByPostIDLight(ctx context.Context, postID int, hasDeleted *bool) ([]*ent.Comment, error) {
 	q := uc.client.Comment.Query().
-		Where(comment.PostIDEQ(postID))
+		Where(comment.PostIDEQ(postID), comment.HeldEQ(false))
 	
 	if hasDeleted != nil {
 		q = q.Where(comment.HasDeletedEQ(*hasDeleted))
@@ -77,7 +75,7 @@ func (uc *commentUsecase) GetCommentsByPostIDLight(ctx context.Context, postID i
 // GetCommentsByPostIDLightPaginated возвращает облегченную версию комментариев к посту с пагинацией
 func (uc *commentUsecase) GetCommentsByPostIDLightPaginated(ctx context.Context, postID int, hasDeleted *bool, limit int32, offset int32) ([]*ent.Comment, error) {
 	q := uc.client.Comment.Query().
-		Where(comment.PostIDEQ(postID))
+		Where(comment.PostIDEQ(postID), comment.HeldEQ(false))
 
 	if hasDeleted != nil {
 		q = q.Where(comment.HasDeletedEQ(*hasDeleted))
@@ -99,7 +97,7 @@ func (uc *commentUsecase) GetCommentsByPostIDLightPaginated(ctx context.Context,
 // GetCommentsFeed возвращает плоский список последних комментариев для общей ленты
 func (uc *commentUsecase) GetCommentsFeed(ctx context.Context, limit int32) ([]*ent.Comment, error) {
 	return uc.client.Comment.Query().
-		Where(comment.HasDeletedEQ(false)).
+		Where(comment.HasDeletedEQ(false), comment.HeldEQ(false)).
 		Where(comment.HasPostWith(post.VisibilityEQ(post.VisibilityPublished))).
 		WithAuthor().
 		WithPost().
@@ -112,7 +110,8 @@ func (uc *commentUsecase) GetCommentsFeed(ctx context.Context, limit int32) ([]*
 
 // GetAllComments возвращает все комментарии платформы (плоский список)
 func (uc *commentUsecase) GetAllComments(ctx context.Context, hasDeleted *bool) ([]*ent.Comment, error) {
-	q := uc.client.Comment.Query()
+	// Задержанные автомодерацией комментарии не видны до проверки
+	q := uc.client.Comment.Query().Where(comment.HeldEQ(false))
 	
 	if hasDeleted != nil {
 		q = q.Where(comment.HasDeletedEQ(*hasDeleted))
@@ -137,111 +136,36 @@ func (uc *commentUsecase) CommentByID(ctx context.Context, id int) (*ent.Comment
 	return uc.client.Comment.Get(ctx, id)
 }
 
-// cursorKey создает строку ключа курсора "createdAt|id"
-func cursorKey(c *ent.Comment) string {
-	return c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + strconv.Itoa(c.ID)
-}
-
-// encodeCursor кодирует ключ в base64
-func encodeCursor(key string) string {
-	return base64.StdEncoding.EncodeToString([]byte(key))
+// commentsOrder — порядок комментариев по (createdAt, id)
+func commentsOrder(desc bool) pagination.Order[*ent.Comment] {
+	return pagination.Order[*ent.Comment]{
+		Field: comment.FieldCreatedAt,
+		Desc:  desc,
+		Key:   func(c *ent.Comment) (time.Time, int) { return c.CreatedAt, c.ID },
+	}
 }
 
-// decodeCursor декодирует base64 в (createdAt, id)
-func decodeCursor(cur string) (time.Time, int, error) {
-	b, err := base64.StdEncoding.DecodeString(cur)
-	if err != nil { return time.Time{}, 0, err }
-	parts := strings.SplitN(string(b), "|", 2)
-	if len(parts) != 2 { return time.Time{}, 0, fmt.Errorf("invalid cursor") }
-	t, err := time.Parse(time.RFC3339Nano, parts[0])
-	if err != nil { return time.Time{}, 0, err }
-	id, err := strconv.Atoi(parts[1])
-	if err != nil { return time.Time{}, 0, err }
-	return t, id, nil
+// commentsConnection выполняет keyset-пагинацию; без first и last отдаёт пустое окно
+func commentsConnection(ctx context.Context, q *ent.CommentQuery, desc bool, first *int, after *string, last *int, before *string) (*models.CommentsConnection, error) {
+	if first == nil && last == nil {
+		return &models.CommentsConnection{Edges: []*models.CommentEdge{}, PageInfo: &models.PageInfo{HasNextPage: false, HasPreviousPage: false}}, nil
+	}
+	page, err := pagination.Paginate(ctx, q, pagination.Args{First: first, After: after, Last: last, Before: before}, commentsOrder(desc))
+	if err != nil { return nil, err }
+	return &models.CommentsConnection{
+		Edges: pagination.MapEdges(page, func(cur string, c *ent.Comment) *models.CommentEdge {
+			return &models.CommentEdge{Cursor: cur, Node: c}
+		}),
+		PageInfo: page.PageInfo,
+	}, nil
 }
 
 // CommentsByPostConnection реализация двунаправленной keyset пагинации
 func (uc *commentUsecase) CommentsByPostConnection(ctx context.Context, postID int, hasDeleted *bool, first *int, after *string, last *int, before *string) (*models.CommentsConnection, error) {
-	// базовый запрос
-	base := uc.client.Comment.Query().Where(comment.PostIDEQ(postID))
+	base := uc.client.Comment.Query().Where(comment.PostIDEQ(postID), comment.HeldEQ(false))
 	if hasDeleted != nil { base = base.Where(comment.HasDeletedEQ(*hasDeleted)) }
-
-	var edges []*models.CommentEdge
-	var startCur, endCur *string
-	var hasPrev, hasNext bool
-
-	// Ветка загрузки вниз (first/after)
-	if first != nil {
-		lim := *first
-		q := base.Clone()
-		if after != nil && *after != "" {
-			ta, ia, err := decodeCursor(*after)
-			if err != nil { return nil, err }
-			q = q.Where(
-				comment.Or(
-					comment.CreatedAtGT(ta),
-					comment.And(comment.CreatedAtEQ(ta), comment.IDGT(ia)),
-				),
-			)
-		}
-		items, err := q.
-			Order(ent.Asc(comment.FieldCreatedAt), ent.Asc(comment.FieldID)).
-			Limit(lim + 1).
-			All(ctx)
-		if err != nil { return nil, err }
-		if len(items) > lim { hasNext = true; items = items[:lim] }
-		for _, c := range items {
-			cur := encodeCursor(cursorKey(c))
-			edges = append(edges, &models.CommentEdge{ Cursor: cur, Node: c })
-		}
-	} else if last != nil { // Ветка загрузки вверх (last/before)
-		lim := *last
-		q := base.Clone()
-		if before != nil && *before != "" {
-			tb, ib, err := decodeCursor(*before)
-			if err != nil { return nil, err }
-			q = q.Where(
-				comment.Or(
-					comment.CreatedAtLT(tb),
-					comment.And(comment.CreatedAtEQ(tb), comment.IDLT(ib)),
-				),
-			)
-		}
-		items, err := q.
-			Order(ent.Desc(comment.FieldCreatedAt), ent.Desc(comment.FieldID)).
-			Limit(lim + 1).
-			All(ctx)
-		if err != nil { return nil, err }
-		if len(items) > lim { hasPrev = true; items = items[:lim] }
-		// разворачиваем в ASC для клиента
-		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
-			items[i], items[j] = items[j], items[i]
-		}
-		for _, c := range items {
-			cur := encodeCursor(cursorKey(c))
-			edges = append(edges, &models.CommentEdge{ Cursor: cur, Node: c })
-		}
-	} else {
-		// если не указаны ни first, ни last — вернём пустое окно
-		return &models.CommentsConnection{Edges: []*models.CommentEdge{}, PageInfo: &models.PageInfo{HasNextPage: false, HasPreviousPage: false}}, nil
-	}
-
-	if len(edges) > 0 {
-		s := edges[0].Cursor
-		e := edges[len(edges)-1].Cursor
-		startCur = &s
-		endCur = &e
-	}
-
-	return &models.CommentsConnection{
-		Edges: edges,
-		PageInfo: &models.PageInfo{
-			HasNextPage: hasNext,
-			HasPreviousPage: hasPrev,
-			StartCursor:    startCur,
-			EndCursor:      endCur,
-		},
-	}, nil
+	// Обсуждение читается сверху вниз: старые первыми
+	return commentsConnection(ctx, base, false, first, after, last, before)
 }
 
 // CommentsWindow возвращает окно вокруг якоря
@@ -252,7 +176,7 @@ func (uc *commentUsecase) CommentsWindow(ctx context.Context, postID int, anchor
 	if anchor.PostID != postID { return nil, fmt.Errorf("anchor does not belong to post") }
 	if hasDeleted != nil && *hasDeleted != anchor.HasDeleted { /* допускаем, фильтрация ниже */ }
 
-	base := uc.client.Comment.Query().Where(comment.PostIDEQ(postID))
+	base := uc.client.Comment.Query().Where(comment.PostIDEQ(postID), comment.HeldEQ(false))
 	if hasDeleted != nil { base = base.Where(comment.HasDeletedEQ(*hasDeleted)) }
 
 	// before (меньше якоря)
@@ -281,12 +205,12 @@ func (uc *commentUsecase) CommentsWindow(ctx context.Context, postID int, anchor
 
 	// собираем edges: before + anchor + after
 	var edges []*models.CommentEdge
-	for _, c := range beforeItems { edges = append(edges, &models.CommentEdge{ Cursor: encodeCursor(cursorKey(c)), Node: c }) }
+	for _, c := range beforeItems { edges = append(edges, &models.CommentEdge{ Cursor: pagination.Encode(c.CreatedAt, c.ID), Node: c }) }
 	// включаем якорь, если не отфильтрован
-	if hasDeleted == nil || anchor.HasDeleted == *hasDeleted {
-		edges = append(edges, &models.CommentEdge{ Cursor: encodeCursor(cursorKey(anchor)), Node: anchor })
+	if (hasDeleted == nil || anchor.HasDeleted == *hasDeleted) && !anchor.Held {
+		edges = append(edges, &models.CommentEdge{ Cursor: pagination.Encode(anchor.CreatedAt, anchor.ID), Node: anchor })
 	}
-	for _, c := range afterItems { edges = append(edges, &models.CommentEdge{ Cursor: encodeCursor(cursorKey(c)), Node: c }) }
+	for _, c := range afterItems { edges = append(edges, &models.CommentEdge{ Cursor: pagination.Encode(c.CreatedAt, c.ID), Node: c }) }
 
 	var startCur, endCur *string
 	if len(edges) > 0 {
@@ -316,58 +240,9 @@ func (uc *commentUsecase) CommentsWindow(ctx context.Context, postID int, anchor
 // CommentsFeedConnection — двунаправленная keyset пагинация по всем комментариям
 func (uc *commentUsecase) CommentsFeedConnection(ctx context.Context, hasDeleted *bool, first *int, after *string, last *int, before *string) (*models.CommentsConnection, error) {
     base := uc.client.Comment.Query().
-        Where(comment.HasPostWith(post.VisibilityEQ(post.VisibilityPublished)))
+        Where(comment.HasPostWith(post.VisibilityEQ(post.VisibilityPublished)), comment.HeldEQ(false))
     if hasDeleted != nil { base = base.Where(comment.HasDeletedEQ(*hasDeleted)) } else { base = base.Where(comment.HasDeletedEQ(false)) }
 
-    var edges []*models.CommentEdge
-    var startCur, endCur *string
-    var hasPrev, hasNext bool
-
-    if first != nil {
-        // Вниз по ленте (к более старым), выдаём в DESC (новые сверху)
-        lim := *first
-        q := base.Clone()
-        if after != nil && *after != "" {
-            ta, ia, err := decodeCursor(*after)
-            if err != nil { return nil, err }
-            // После курсора в DESC-порядке = строго старее курсора
-            q = q.Where(comment.Or(
-                comment.CreatedAtLT(ta),
-                comment.And(comment.CreatedAtEQ(ta), comment.IDLT(ia)),
-            ))
-        }
-        items, err := q.Order(ent.Desc(comment.FieldCreatedAt), ent.Desc(comment.FieldID)).Limit(lim + 1).All(ctx)
-        if err != nil { return nil, err }
-        if len(items) > lim { hasNext = true; items = items[:lim] }
-        for _, c := range items { cur := encodeCursor(cursorKey(c)); edges = append(edges, &models.CommentEdge{Cursor: cur, Node: c}) }
-    } else if last != nil {
-        // Вверх по ленте (к более новым), отдаём в DESC
-        lim := *last
-        q := base.Clone()
-        if before != nil && *before != "" {
-            tb, ib, err := decodeCursor(*before)
-            if err != nil { return nil, err }
-            // Перед курсором в DESC-порядке = строго новее курсора
-            q = q.Where(comment.Or(
-                comment.CreatedAtGT(tb),
-                comment.And(comment.CreatedAtEQ(tb), comment.IDGT(ib)),
-            ))
-        }
-        // Грузим ASC, чтобы затем взять ближние к курсору и развернуть в DESC
-        items, err := q.Order(ent.Asc(comment.FieldCreatedAt), ent.Asc(comment.FieldID)).Limit(lim + 1).All(ctx)
-        if err != nil { return nil, err }
-        if len(items) > lim { hasPrev = true; items = items[:lim] }
-        // Разворот в DESC для клиента
-        for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 { items[i], items[j] = items[j], items[i] }
-        for _, c := range items { cur := encodeCursor(cursorKey(c)); edges = append(edges, &models.CommentEdge{Cursor: cur, Node: c}) }
-    } else {
-        return &models.CommentsConnection{Edges: []*models.CommentEdge{}, PageInfo: &models.PageInfo{HasNextPage: false, HasPreviousPage: false}}, nil
-    }
-
-    if len(edges) > 0 { s := edges[0].Cursor; e := edges[len(edges)-1].Cursor; startCur = &s; endCur = &e }
-
-    return &models.CommentsConnection{
-        Edges: edges,
-        PageInfo: &models.PageInfo{ HasNextPage: hasNext, HasPreviousPage: hasPrev, StartCursor: startCur, EndCursor: endCur },
-    }, nil
+    // Лента: новые сверху; after — к более старым, before — к более новым
+    return commentsConnection(ctx, base, true, first, after, last, before)
 }
@@ -2,7 +2,6 @@ package comment
 
 import (
 	"context"
-	"encoding/base64"
 	"fmt"
 	"testing"
 	"time"
@@ -676,52 +675,6 @@ func TestCommentUsecase_CommentsFeedConnection(t *testing.T) {
 	})
 }
 
-func TestCommentUsecase_CursorEncoding(t *testing.T) {
-	// Test cursor encoding/decoding functions
-	testTime := time.Now().UTC()
-	testID := 123
-
-	t.Run("encode and decode cursor", func(t *testing.T) {
-		// Create mock comment for cursor generation
-		comment := &ent.Comment{
-			ID:        testID,
-			CreatedAt: testTime,
-		}
-
-		// Generate cursor
-		key := cursorKey(comment)
-		cursor := encodeCursor(key)
-
-		// Decode cursor
-		decodedTime, decodedID, err := decodeCursor(cursor)
-
-		assert.NoError(t, err)
-		assert.True(t, testTime.Equal(decodedTime))
-		assert.Equal(t, testID, decodedID)
-	})
-
-	t.Run("invalid cursor formats", func(t *testing.T) {
-		// Test invalid base64
-		_, _, err := decodeCursor("invalid-base64!")
-		assert.Error(t, err)
-
-		// Test invalid cursor structure
-		invalidCursor := base64.StdEncoding.EncodeToString([]byte("invalid"))
-		_, _, err = decodeCursor(invalidCursor)
-		assert.Error(t, err)
-
-		// Test invalid time format
-		invalidTimeCursor := base64.StdEncoding.EncodeToString([]byte("invalid-time|123"))
-		_, _, err = decodeCursor(invalidTimeCursor)
-		assert.Error(t, err)
-
-		// Test invalid ID format
-		invalidIDCursor := base64.StdEncoding.EncodeToString([]byte("2023-01-01T00:00:00Z|invalid-id"))
-		_, _, err = decodeCursor(invalidIDCursor)
-		assert.Error(t, err)
-	})
-}
-
 func TestCommentUsecase_EdgeCases(t *testing.T) {
 	client, helper := setupTestClient(t)
 	defer helper.Cleanup()
@@ -8,6 +8,7 @@ import (
 	"stormlink/server/ent/communityusermute"
 	"stormlink/server/ent/post"
 	"stormlink/server/graphql/models"
+	"time"
 )
 
 func (uc *communityUsecase) GetCommunityStatus(
@@ -51,6 +52,7 @@ func (uc *communityUsecase) GetCommunityStatus(
         Where(
             communityuserban.CommunityIDEQ(communityID),
             communityuserban.UserIDEQ(userID),
+            communityuserban.Or(communityuserban.ExpiresAtIsNil(), communityuserban.ExpiresAtGT(time.Now())),
         ).
         Exist(ctx)
     if err != nil {
@@ -63,6 +65,7 @@ func (uc *communityUsecase) GetCommunityStatus(
         Where(
             communityusermute.CommunityIDEQ(communityID),
             communityusermute.UserIDEQ(userID),
+            communityusermute.Or(communityusermute.ExpiresAtIsNil(), communityusermute.ExpiresAtGT(time.Now())),
         ).
         Exist(ctx)
     if err != nil {
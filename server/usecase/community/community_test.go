@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"stormlink/server/authz"
 	"stormlink/server/ent"
 	"stormlink/tests/fixtures"
 	"stormlink/tests/testhelper"
@@ -123,8 +124,7 @@ func TestCommunityUsecase_GetCommunityWithRoles(t *testing.T) {
 		_, err := client.Role.Create().
 			SetTitle("Moderator").
 			SetCommunityID(fixtures.TestCommunity1.ID).
-			SetCommunityUserBan(true).
-			SetCommunityDeletePost(true).
+			SetPermissions([]string{string(authz.BanUser), string(authz.DeletePost)}).
 			SetCreatedAt(time.Now()).
 			SetUpdatedAt(time.Now()).
 			Save(ctx)
@@ -162,12 +162,12 @@ func TestCommunityUsecase_GetCommunityWithRoles(t *testing.T) {
 
 		// Verify permissions
 		moderator := roleMap["Moderator"]
-		assert.True(t, moderator.CommunityUserBan)
-		assert.True(t, moderator.CommunityDeletePost)
+		assert.Contains(t, moderator.Permissions, string(authz.BanUser))
+		assert.Contains(t, moderator.Permissions, string(authz.DeletePost))
 
 		member := roleMap["Member"]
-		assert.False(t, member.CommunityUserBan)
-		assert.False(t, member.CommunityDeletePost)
+		assert.NotContains(t, member.Permissions, string(authz.BanUser))
+		assert.NotContains(t, member.Permissions, string(authz.DeletePost))
 	})
 }
 
@@ -226,8 +226,7 @@ func TestCommunityUsecase_GetCommunityStatus(t *testing.T) {
 		_, err := client.Role.Create().
 			SetTitle("Moderator").
 			SetCommunityID(fixtures.TestCommunity1.ID).
-			SetCommunityUserBan(true).
-			SetCommunityDeletePost(true).
+			SetPermissions([]string{string(authz.BanUser), string(authz.DeletePost)}).
 			SetCreatedAt(time.Now()).
 			SetUpdatedAt(time.Now()).
 			Save(ctx)
@@ -337,9 +336,7 @@ func TestCommunityUsecase_CommunityWithRolesAndLogo(t *testing.T) {
 		_, err = client.Role.Create().
 			SetTitle("Admin").
 			SetCommunityID(fixtures.TestCommunity1.ID).
-			SetCommunityRolesManagement(true).
-			SetCommunityUserBan(true).
-			SetCommunityUserMute(true).
+			SetPermissions([]string{string(authz.ManageRoles), string(authz.BanUser), string(authz.MuteUser)}).
 			SetCreatedAt(time.Now()).
 			SetUpdatedAt(time.Now()).
 			Save(ctx)
@@ -348,9 +345,7 @@ func TestCommunityUsecase_CommunityWithRolesAndLogo(t *testing.T) {
 		_, err = client.Role.Create().
 			SetTitle("Moderator").
 			SetCommunityID(fixtures.TestCommunity1.ID).
-			SetCommunityDeletePost(true).
-			SetCommunityUserBan(true).
-			SetCommunityUserMute(true).
+			SetPermissions([]string{string(authz.DeletePost), string(authz.BanUser), string(authz.MuteUser)}).
 			SetCreatedAt(time.Now()).
 			SetUpdatedAt(time.Now()).
 			Save(ctx)
@@ -396,15 +391,15 @@ func TestCommunityUsecase_CommunityWithRolesAndLogo(t *testing.T) {
 		assert.Contains(t, roleMap, "Member")
 
 		// Verify admin permissions
-		assert.True(t, roleMap["Admin"].CommunityRolesManagement)
-		assert.True(t, roleMap["Admin"].CommunityUserBan)
+		assert.Contains(t, roleMap["Admin"].Permissions, string(authz.ManageRoles))
+		assert.Contains(t, roleMap["Admin"].Permissions, string(authz.BanUser))
 
 		// Verify moderator permissions
-		assert.True(t, roleMap["Moderator"].CommunityDeletePost)
-		assert.True(t, roleMap["Moderator"].CommunityUserBan)
+		assert.Contains(t, roleMap["Moderator"].Permissions, string(authz.DeletePost))
+		assert.Contains(t, roleMap["Moderator"].Permissions, string(authz.BanUser))
 
 		// Verify member permissions
-		assert.False(t, roleMap["Member"].CommunityUserBan)
+		assert.NotContains(t, roleMap["Member"].Permissions, string(authz.BanUser))
 	})
 }
 
@@ -424,7 +419,7 @@ func TestCommunityUsecase_ComplexScenarios(t *testing.T) {
 		_, err := client.Role.Create().
 			SetTitle("Custom Moderator").
 			SetCommunityID(fixtures.TestCommunity1.ID).
-			SetCommunityUserBan(true).
+			SetPermissions([]string{string(authz.BanUser)}).
 			SetCreatedAt(time.Now()).
 			SetUpdatedAt(time.Now()).
 			Save(ctx)
@@ -570,12 +565,7 @@ func TestCommunityUsecase_RolePermissions(t *testing.T) {
 		_, err = client.Role.Create().
 			SetTitle("Admin").
 			SetCommunityID(fixtures.TestCommunity1.ID).
-			SetCommunityRolesManagement(true).
-			SetCommunityUserBan(true).
-			SetCommunityUserMute(true).
-			SetCommunityDeletePost(true).
-			SetCommunityRemovePostFromPublication(true).
-			SetCommunityDeleteComments(true).
+			SetPermissions([]string{string(authz.ManageRoles), string(authz.BanUser), string(authz.MuteUser), string(authz.DeletePost), string(authz.UnpublishPost), string(authz.DeleteComment)}).
 			SetCreatedAt(time.Now()).
 			SetUpdatedAt(time.Now()).
 			Save(ctx)
@@ -584,9 +574,7 @@ func TestCommunityUsecase_RolePermissions(t *testing.T) {
 		_, err = client.Role.Create().
 			SetTitle("Moderator").
 			SetCommunityID(fixtures.TestCommunity1.ID).
-			SetCommunityDeletePost(true).
-			SetCommunityUserBan(true).
-			SetCommunityUserMute(true).
+			SetPermissions([]string{string(authz.DeletePost), string(authz.BanUser), string(authz.MuteUser)}).
 			SetCreatedAt(time.Now()).
 			SetUpdatedAt(time.Now()).
 			Save(ctx)
@@ -627,26 +615,26 @@ func TestCommunityUsecase_RolePermissions(t *testing.T) {
 		// Verify admin permissions
 		admin := roleMap["Admin"]
 		assert.NotNil(t, admin)
-		assert.True(t, admin.CommunityRolesManagement)
-		assert.True(t, admin.CommunityUserBan)
+		assert.Contains(t, admin.Permissions, string(authz.ManageRoles))
+		assert.Contains(t, admin.Permissions, string(authz.BanUser))
 
 		// Verify moderator permissions
 		moderator := roleMap["Moderator"]
 		assert.NotNil(t, moderator)
-		assert.True(t, moderator.CommunityDeletePost)
-		assert.True(t, moderator.CommunityUserBan)
-		assert.False(t, moderator.CommunityRolesManagement)
+		assert.Contains(t, moderator.Permissions, string(authz.DeletePost))
+		assert.Contains(t, moderator.Permissions, string(authz.BanUser))
+		assert.NotContains(t, moderator.Permissions, string(authz.ManageRoles))
 
 		// Verify member permissions
 		member := roleMap["Member"]
 		assert.NotNil(t, member)
-		assert.False(t, member.CommunityDeletePost)
-		assert.False(t, member.CommunityUserBan)
+		assert.NotContains(t, member.Permissions, string(authz.DeletePost))
+		assert.NotContains(t, member.Permissions, string(authz.BanUser))
 
 		// Verify read-only permissions
 		readOnly := roleMap["Read Only"]
 		assert.NotNil(t, readOnly)
-		assert.False(t, readOnly.CommunityDeletePost)
-		assert.False(t, readOnly.CommunityUserBan)
+		assert.NotContains(t, readOnly.Permissions, string(authz.DeletePost))
+		assert.NotContains(t, readOnly.Permissions, string(authz.BanUser))
 	})
 }
@@ -3,9 +3,12 @@ package communityrole
 import (
 	"context"
 	"fmt"
+	"stormlink/server/authz"
 	"stormlink/server/ent"
+	"stormlink/server/ent/moderationaction"
 	"stormlink/server/ent/role"
 	"stormlink/server/graphql/models"
+	"stormlink/server/modlog"
 	"strconv"
 )
 
@@ -33,58 +36,67 @@ func (uc *communityRoleUsecase) CreateCommunityRole(ctx context.Context, input *
 		return nil, fmt.Errorf("invalid communityID: %w", err)
 	}
 
-	create := uc.client.Role.Create().
-		SetTitle(input.Title).
-		SetCommunityID(communityID)
-
-	if input.Color != nil {
-		create = create.SetColor(*input.Color)
-	}
+	var badgeID *int
 	if input.BadgeID != nil {
-		badgeID, err := strconv.Atoi(*input.BadgeID)
+		id, err := strconv.Atoi(*input.BadgeID)
 		if err != nil {
 			return nil, fmt.Errorf("invalid badgeID: %w", err)
 		}
-		create = create.SetBadgeID(badgeID)
-	}
-	if input.CommunityRolesManagement != nil {
-		create = create.SetCommunityRolesManagement(*input.CommunityRolesManagement)
-	}
-	if input.CommunityUserBan != nil {
-		create = create.SetCommunityUserBan(*input.CommunityUserBan)
-	}
-	if input.CommunityUserMute != nil {
-		create = create.SetCommunityUserMute(*input.CommunityUserMute)
+		badgeID = &id
 	}
-	if input.CommunityDeletePost != nil {
-		create = create.SetCommunityDeletePost(*input.CommunityDeletePost)
-	}
-	if input.CommunityDeleteComments != nil {
-		create = create.SetCommunityDeleteComments(*input.CommunityDeleteComments)
+	var permissions []string
+	if input.Permissions != nil {
+		p, err := authz.ParsePermissions(authz.ScopeCommunity, input.Permissions)
+		if err != nil {
+			return nil, fmt.Errorf("invalid permissions: %w", err)
+		}
+		permissions = p
 	}
-	if input.CommunityRemovePostFromPublication != nil {
-		create = create.SetCommunityRemovePostFromPublication(*input.CommunityRemovePostFromPublication)
+	var denied []string
+	if input.DeniedActions != nil {
+		d, err := authz.ParseActions(input.DeniedActions)
+		if err != nil {
+			return nil, fmt.Errorf("invalid deniedActions: %w", err)
+		}
+		denied = d
 	}
-
-	role, err := create.Save(ctx)
+	userIDs, err := parseUserIDs(input.UserIDs)
 	if err != nil {
-		return nil, fmt.Errorf("create community role: %w", err)
+		return nil, err
 	}
 
-	// Добавляем пользователей если есть
-	if len(input.UserIDs) > 0 {
-		userIDs := make([]int, len(input.UserIDs))
-		for i, userIDStr := range input.UserIDs {
-			userID, err := strconv.Atoi(userIDStr)
-			if err != nil {
-				return nil, fmt.Errorf("invalid userID %s: %w", userIDStr, err)
-			}
-			userIDs[i] = userID
+	// Создаем роль и добавляем пользователей вместе с записями в журнале модерации
+	var role *ent.Role
+	err = modlog.InTx(ctx, uc.client, func(tx *ent.Tx) error {
+		create := tx.Role.Create().
+			SetTitle(input.Title).
+			SetCommunityID(communityID).
+			SetNillableColor(input.Color).
+			SetNillableBadgeID(badgeID).
+			SetNillablePosition(input.Position).
+			SetPermissionsVersion(authz.RegistryVersion).
+			AddUserIDs(userIDs...)
+		if input.Permissions != nil {
+			create = create.SetPermissions(permissions)
 		}
-		err = uc.AddUsersToRole(ctx, role.ID, userIDs)
+		if input.DeniedActions != nil {
+			create = create.SetDeniedActions(denied)
+		}
+		role, err = create.Save(ctx)
 		if err != nil {
-			return nil, fmt.Errorf("add users to role: %w", err)
+			return fmt.Errorf("create community role: %w", err)
+		}
+
+		entry := roleEntry(role)
+		entry.Type = moderationaction.TypeRoleCreated
+		entry.After = role
+		if err := modlog.Record(ctx, tx.ModerationAction, entry); err != nil {
+			return err
 		}
+		return modlog.RecordMembers(ctx, tx.ModerationAction, entry, nil, userIDs)
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return role, nil
@@ -96,68 +108,79 @@ func (uc *communityRoleUsecase) UpdateCommunityRole(ctx context.Context, input *
 		return nil, fmt.Errorf("invalid role ID: %w", err)
 	}
 
-	update := uc.client.Role.UpdateOneID(roleID)
-
-	if input.Title != nil {
-		update = update.SetTitle(*input.Title)
-	}
-	if input.Color != nil {
-		update = update.SetColor(*input.Color)
-	}
-	if input.BadgeID != nil {
-		badgeID, err := strconv.Atoi(*input.BadgeID)
-		if err != nil {
-			return nil, fmt.Errorf("invalid badgeID: %w", err)
-		}
-		update = update.SetBadgeID(badgeID)
-	}
-	if input.CommunityRolesManagement != nil {
-		update = update.SetCommunityRolesManagement(*input.CommunityRolesManagement)
-	}
-	if input.CommunityUserBan != nil {
-		update = update.SetCommunityUserBan(*input.CommunityUserBan)
-	}
-	if input.CommunityUserMute != nil {
-		update = update.SetCommunityUserMute(*input.CommunityUserMute)
-	}
-	if input.CommunityDeletePost != nil {
-		update = update.SetCommunityDeletePost(*input.CommunityDeletePost)
-	}
-	if input.CommunityDeleteComments != nil {
-		update = update.SetCommunityDeleteComments(*input.CommunityDeleteComments)
-	}
-	if input.CommunityRemovePostFromPublication != nil {
-		update = update.SetCommunityRemovePostFromPublication(*input.CommunityRemovePostFromPublication)
+	before, err := uc.client.Role.Get(ctx, roleID)
+	if err != nil {
+		return nil, fmt.Errorf("role not found: %w", err)
 	}
-
-	role, err := update.Save(ctx)
+	membersBefore, err := uc.client.Role.QueryUsers(before).IDs(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("update community role: %w", err)
+		return nil, fmt.Errorf("get role users: %w", err)
 	}
 
-	// Обновляем пользователей если есть
-	if input.UserIDs != nil {
-		// Сначала очищаем всех пользователей
-		_, err = uc.client.Role.UpdateOneID(roleID).ClearUsers().Save(ctx)
-		if err != nil {
-			return nil, fmt.Errorf("clear users from role: %w", err)
-		}
-
-		// Добавляем новых пользователей
-		if len(input.UserIDs) > 0 {
-			userIDs := make([]int, len(input.UserIDs))
-			for i, userIDStr := range input.UserIDs {
-				userID, err := strconv.Atoi(userIDStr)
-				if err != nil {
-					return nil, fmt.Errorf("invalid userID %s: %w", userIDStr, err)
-				}
-				userIDs[i] = userID
+	var role *ent.Role
+	err = modlog.InTx(ctx, uc.client, func(tx *ent.Tx) error {
+		update := tx.Role.UpdateOneID(roleID)
+
+		if input.Title != nil {
+			update = update.SetTitle(*input.Title)
+		}
+		if input.Color != nil {
+			update = update.SetColor(*input.Color)
+		}
+		if input.BadgeID != nil {
+			badgeID, err := strconv.Atoi(*input.BadgeID)
+			if err != nil {
+				return fmt.Errorf("invalid badgeID: %w", err)
 			}
-			err = uc.AddUsersToRole(ctx, roleID, userIDs)
+			update = update.SetBadgeID(badgeID)
+		}
+		if input.Permissions != nil {
+			permissions, err := authz.ParsePermissions(authz.ScopeCommunity, input.Permissions)
 			if err != nil {
-				return nil, fmt.Errorf("add users to role: %w", err)
+				return fmt.Errorf("invalid permissions: %w", err)
 			}
+			update = update.SetPermissions(permissions)
+		}
+		if input.Permissions != nil || input.DeniedActions != nil {
+			update = update.SetPermissionsVersion(authz.RegistryVersion)
+		}
+		if input.Position != nil {
+			update = update.SetPosition(*input.Position)
+		}
+		if input.DeniedActions != nil {
+			denied, err := authz.ParseActions(input.DeniedActions)
+			if err != nil {
+				return fmt.Errorf("invalid deniedActions: %w", err)
+			}
+			update = update.SetDeniedActions(denied)
+		}
+
+		// Состав роли заменяется целиком, если передан
+		membersAfter := membersBefore
+		if input.UserIDs != nil {
+			if membersAfter, err = parseUserIDs(input.UserIDs); err != nil {
+				return err
+			}
+			update = update.ClearUsers().AddUserIDs(membersAfter...)
+		}
+
+		role, err = update.Save(ctx)
+		if err != nil {
+			return fmt.Errorf("update community role: %w", err)
+		}
+
+		entry := roleEntry(role)
+		entry.Type = moderationaction.TypeRoleUpdated
+		entry.Before = before
+		entry.After = role
+		if err := modlog.Record(ctx, tx.ModerationAction, entry); err != nil {
+			return err
 		}
+		entry.Before = nil
+		return modlog.RecordMembers(ctx, tx.ModerationAction, entry, membersBefore, membersAfter)
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return role, nil
@@ -165,18 +188,21 @@ func (uc *communityRoleUsecase) UpdateCommunityRole(ctx context.Context, input *
 
 func (uc *communityRoleUsecase) DeleteCommunityRole(ctx context.Context, id int) error {
 	// Проверяем, что роль существует
-	_, err := uc.client.Role.Get(ctx, id)
+	role, err := uc.client.Role.Get(ctx, id)
 	if err != nil {
 		return fmt.Errorf("role not found: %w", err)
 	}
 
-	// Удаляем роль
-	err = uc.client.Role.DeleteOneID(id).Exec(ctx)
-	if err != nil {
-		return fmt.Errorf("delete community role: %w", err)
-	}
-
-	return nil
+	// Удаляем роль вместе с записью в журнале модерации
+	return modlog.InTx(ctx, uc.client, func(tx *ent.Tx) error {
+		if err := tx.Role.DeleteOneID(id).Exec(ctx); err != nil {
+			return fmt.Errorf("delete community role: %w", err)
+		}
+		entry := roleEntry(role)
+		entry.Type = moderationaction.TypeRoleDeleted
+		entry.Before = role
+		return modlog.Record(ctx, tx.ModerationAction, entry)
+	})
 }
 
 func (uc *communityRoleUsecase) GetCommunityRoles(ctx context.Context, communityID int) ([]*ent.Role, error) {
@@ -213,14 +239,20 @@ func (uc *communityRoleUsecase) AddUsersToRole(ctx context.Context, roleID int,
 	if err != nil {
 		return fmt.Errorf("role not found: %w", err)
 	}
-
-	// Добавляем пользователей
-	_, err = uc.client.Role.UpdateOne(role).AddUserIDs(userIDs...).Save(ctx)
+	members, err := uc.client.Role.QueryUsers(role).IDs(ctx)
 	if err != nil {
-		return fmt.Errorf("add users to role: %w", err)
+		return fmt.Errorf("get role users: %w", err)
 	}
 
-	return nil
+	// Добавляем пользователей вместе с записями в журнале модерации
+	return modlog.InTx(ctx, uc.client, func(tx *ent.Tx) error {
+		if err := tx.Role.UpdateOne(role).AddUserIDs(userIDs...).Exec(ctx); err != nil {
+			return fmt.Errorf("add users to role: %w", err)
+		}
+		entry := roleEntry(role)
+		entry.After = role
+		return modlog.RecordMembers(ctx, tx.ModerationAction, entry, members, append(members, userIDs...))
+	})
 }
 
 func (uc *communityRoleUsecase) RemoveUserFromRole(ctx context.Context, roleID int, userID int) error {
@@ -230,11 +262,34 @@ func (uc *communityRoleUsecase) RemoveUserFromRole(ctx context.Context, roleID i
 		return fmt.Errorf("role not found: %w", err)
 	}
 
-	// Удаляем пользователя
-	_, err = uc.client.Role.UpdateOne(role).RemoveUserIDs(userID).Save(ctx)
-	if err != nil {
-		return fmt.Errorf("remove user from role: %w", err)
+	// Удаляем пользователя вместе с записью в журнале модерации
+	return modlog.InTx(ctx, uc.client, func(tx *ent.Tx) error {
+		if err := tx.Role.UpdateOne(role).RemoveUserIDs(userID).Exec(ctx); err != nil {
+			return fmt.Errorf("remove user from role: %w", err)
+		}
+		entry := roleEntry(role)
+		entry.Before = role
+		return modlog.RecordMembers(ctx, tx.ModerationAction, entry, []int{userID}, nil)
+	})
+}
+
+// roleEntry — запись журнала о роли сообщества; тип и снимки заполняет вызывающий
+func roleEntry(role *ent.Role) modlog.Entry {
+	return modlog.Entry{
+		CommunityID: &role.CommunityID,
+		TargetType:  moderationaction.TargetTypeRole,
+		TargetID:    role.ID,
 	}
+}
 
-	return nil
+func parseUserIDs(ids []string) ([]int, error) {
+	out := make([]int, len(ids))
+	for i, s := range ids {
+		id, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid userID %s: %w", s, err)
+		}
+		out[i] = id
+	}
+	return out, nil
 }
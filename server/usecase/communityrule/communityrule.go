@@ -3,10 +3,12 @@ package communityrule
 import (
 	"context"
 	"fmt"
+	"stormlink/server/authz"
 	"stormlink/server/ent"
-	"stormlink/server/ent/community"
 	"stormlink/server/ent/communityrule"
+	"stormlink/server/ent/moderationaction"
 	"stormlink/server/graphql/models"
+	"stormlink/server/modlog"
 	sharedauth "stormlink/shared/auth"
 	"strconv"
 )
@@ -21,10 +23,11 @@ type CommunityRuleUsecase interface {
 
 type communityRuleUsecase struct {
 	client *ent.Client
+	authz  *authz.Authorizer
 }
 
 func NewCommunityRuleUsecase(client *ent.Client) CommunityRuleUsecase {
-	return &communityRuleUsecase{client: client}
+	return &communityRuleUsecase{client: client, authz: authz.New(authz.NewEntStore(client))}
 }
 
 func (uc *communityRuleUsecase) CreateCommunityRule(ctx context.Context, input *models.CreateCommunityRuleInput) (*ent.CommunityRule, error) {
@@ -49,15 +52,22 @@ func (uc *communityRuleUsecase) CreateCommunityRule(ctx context.Context, input *
 		return nil, fmt.Errorf("insufficient permissions to manage community rules")
 	}
 
-	// Создаем правило
-	rule, err := uc.client.CommunityRule.
-		Create().
-		SetTitle(input.Title).
-		SetDescription(input.Description).
-		SetCommunityID(communityIDInt).
-		Save(ctx)
+	// Создаем правило вместе с записью в журнале модерации
+	var rule *ent.CommunityRule
+	err = modlog.InTx(ctx, uc.client, func(tx *ent.Tx) error {
+		rule, err = tx.CommunityRule.
+			Create().
+			SetTitle(input.Title).
+			SetDescription(input.Description).
+			SetCommunityID(communityIDInt).
+			Save(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to create community rule: %w", err)
+		}
+		return modlog.Record(ctx, tx.ModerationAction, ruleEntry(moderationaction.TypeRuleCreated, rule, nil, rule))
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create community rule: %w", err)
+		return nil, err
 	}
 
 	return rule, nil
@@ -95,19 +105,26 @@ func (uc *communityRuleUsecase) UpdateCommunityRule(ctx context.Context, input *
 		return nil, fmt.Errorf("insufficient permissions to manage community rules")
 	}
 
-	// Обновляем правило
-	update := uc.client.CommunityRule.UpdateOneID(ruleIDInt)
-	
-	if input.Title != nil {
-		update = update.SetTitle(*input.Title)
-	}
-	if input.Description != nil {
-		update = update.SetDescription(*input.Description)
-	}
+	// Обновляем правило вместе с записью в журнале модерации
+	var updatedRule *ent.CommunityRule
+	err = modlog.InTx(ctx, uc.client, func(tx *ent.Tx) error {
+		update := tx.CommunityRule.UpdateOneID(ruleIDInt)
+
+		if input.Title != nil {
+			update = update.SetTitle(*input.Title)
+		}
+		if input.Description != nil {
+			update = update.SetDescription(*input.Description)
+		}
 
-	updatedRule, err := update.Save(ctx)
+		updatedRule, err = update.Save(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to update community rule: %w", err)
+		}
+		return modlog.Record(ctx, tx.ModerationAction, ruleEntry(moderationaction.TypeRuleUpdated, updatedRule, rule, updatedRule))
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to update community rule: %w", err)
+		return nil, err
 	}
 
 	return updatedRule, nil
@@ -145,10 +162,15 @@ func (uc *communityRuleUsecase) DeleteCommunityRule(ctx context.Context, id stri
 		return false, fmt.Errorf("insufficient permissions to manage community rules")
 	}
 
-	// Удаляем правило
-	err = uc.client.CommunityRule.DeleteOneID(ruleIDInt).Exec(ctx)
+	// Удаляем правило вместе с записью в журнале модерации
+	err = modlog.InTx(ctx, uc.client, func(tx *ent.Tx) error {
+		if err := tx.CommunityRule.DeleteOneID(ruleIDInt).Exec(ctx); err != nil {
+			return fmt.Errorf("failed to delete community rule: %w", err)
+		}
+		return modlog.Record(ctx, tx.ModerationAction, ruleEntry(moderationaction.TypeRuleDeleted, rule, rule, nil))
+	})
 	if err != nil {
-		return false, fmt.Errorf("failed to delete community rule: %w", err)
+		return false, err
 	}
 
 	return true, nil
@@ -190,23 +212,19 @@ func (uc *communityRuleUsecase) GetCommunityRules(ctx context.Context, community
 	return rules, nil
 }
 
-// canManageCommunity проверяет, может ли пользователь управлять сообществом
-func (uc *communityRuleUsecase) canManageCommunity(ctx context.Context, userID, communityID int) (bool, error) {
-	// Проверяем, является ли пользователь владельцем сообщества
-	comm, err := uc.client.Community.
-		Query().
-		Where(community.IDEQ(communityID)).
-		Only(ctx)
-	if err != nil {
-		return false, fmt.Errorf("community not found: %w", err)
-	}
-
-	if comm.OwnerID == userID {
-		return true, nil
+// ruleEntry — запись журнала о правиле сообщества; before и after — nil, если снимка нет
+func ruleEntry(t moderationaction.Type, rule, before, after *ent.CommunityRule) modlog.Entry {
+	return modlog.Entry{
+		Type:        t,
+		CommunityID: rule.CommunityID,
+		TargetType:  moderationaction.TargetTypeRule,
+		TargetID:    rule.ID,
+		Before:      before,
+		After:       after,
 	}
+}
 
-	// TODO: Здесь можно добавить проверку на роли модераторов
-	// Пока что только владелец может управлять правилами
-
-	return false, nil
+// canManageCommunity проверяет, может ли пользователь управлять сообществом
+func (uc *communityRuleUsecase) canManageCommunity(ctx context.Context, userID, communityID int) (bool, error) {
+	return uc.authz.Can(ctx, userID, authz.ManageCommunity, authz.Community(communityID))
 }
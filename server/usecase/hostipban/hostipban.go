@@ -0,0 +1,117 @@
+package hostipban
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"stormlink/server/ent"
+	"stormlink/server/ent/hostipban"
+	"stormlink/server/ent/moderationaction"
+	"stormlink/server/ipban"
+	"stormlink/server/model"
+	"stormlink/server/modlog"
+)
+
+// HostIPBanUsecase — баны платформы по IP и подсетям. Права проверяет вызывающий;
+// кеш проверки (ipban.Checker) сбрасывает тоже он.
+type HostIPBanUsecase interface {
+	// Ban блокирует адрес или подсеть cidr (см. ipban.ParsePrefix)
+	Ban(ctx context.Context, moderatorID int, cidr string, d model.SanctionDetails) (*ent.HostIPBan, error)
+	Unban(ctx context.Context, moderatorID, banID int) error
+	Get(ctx context.Context, id int) (*ent.HostIPBan, error)
+	// List — баны, новые первыми
+	List(ctx context.Context) ([]*ent.HostIPBan, error)
+}
+
+type hostIPBanUsecase struct {
+	client *ent.Client
+}
+
+func NewHostIPBanUsecase(client *ent.Client) HostIPBanUsecase {
+	return &hostIPBanUsecase{client: client}
+}
+
+func (uc *hostIPBanUsecase) Ban(ctx context.Context, moderatorID int, cidr string, d model.SanctionDetails) (*ent.HostIPBan, error) {
+	prefix, err := ipban.ParsePrefix(cidr)
+	if err != nil {
+		return nil, err
+	}
+
+	// Проверяем, не забанена ли уже подсеть (истекшие баны не считаются)
+	banned, err := uc.client.HostIPBan.Query().
+		Where(
+			hostipban.CidrEQ(prefix.String()),
+			hostipban.Or(hostipban.ExpiresAtIsNil(), hostipban.ExpiresAtGT(time.Now())),
+		).
+		Exist(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing ip ban: %w", err)
+	}
+	if banned {
+		return nil, fmt.Errorf("%s is already banned", prefix)
+	}
+
+	// Создаем бан вместе с записью в журнале модерации
+	var ban *ent.HostIPBan
+	err = modlog.InTx(ctx, uc.client, func(tx *ent.Tx) error {
+		ban, err = tx.HostIPBan.Create().
+			SetCidr(prefix.String()).
+			SetReason(d.Reason).
+			SetIssuedBy(moderatorID).
+			SetNillableExpiresAt(d.ExpiresAt).
+			SetPublicNote(d.PublicNote).
+			SetPrivateNote(d.PrivateNote).
+			Save(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to ban ip: %w", err)
+		}
+		return modlog.Record(ctx, tx.ModerationAction, modlog.Entry{
+			Type:        moderationaction.TypeIPBanned,
+			ModeratorID: &moderatorID,
+			TargetType:  moderationaction.TargetTypeIPBan,
+			TargetID:    ban.ID,
+			After:       ban,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ban, nil
+}
+
+func (uc *hostIPBanUsecase) Unban(ctx context.Context, moderatorID, banID int) error {
+	ban, err := uc.client.HostIPBan.Get(ctx, banID)
+	if err != nil {
+		return fmt.Errorf("failed to unban ip: %w", err)
+	}
+
+	// Удаляем бан вместе с записью в журнале модерации
+	return modlog.InTx(ctx, uc.client, func(tx *ent.Tx) error {
+		if err := tx.HostIPBan.DeleteOneID(banID).Exec(ctx); err != nil {
+			return fmt.Errorf("failed to unban ip: %w", err)
+		}
+		return modlog.Record(ctx, tx.ModerationAction, modlog.Entry{
+			Type:        moderationaction.TypeIPUnbanned,
+			ModeratorID: &moderatorID,
+			TargetType:  moderationaction.TargetTypeIPBan,
+			TargetID:    ban.ID,
+			Before:      ban,
+		})
+	})
+}
+
+func (uc *hostIPBanUsecase) Get(ctx context.Context, id int) (*ent.HostIPBan, error) {
+	return uc.client.HostIPBan.Get(ctx, id)
+}
+
+func (uc *hostIPBanUsecase) List(ctx context.Context) ([]*ent.HostIPBan, error) {
+	bans, err := uc.client.HostIPBan.Query().
+		WithIssuer().
+		Order(ent.Desc(hostipban.FieldCreatedAt), ent.Desc(hostipban.FieldID)).
+		All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ip bans: %w", err)
+	}
+	return bans, nil
+}
@@ -3,32 +3,38 @@ package hostmute
 import (
 	"context"
 	"fmt"
+	"stormlink/server/authz"
 	"stormlink/server/ent"
 	"stormlink/server/ent/hostcommunitymute"
 	"stormlink/server/ent/hostusermute"
+	"stormlink/server/ent/moderationaction"
 	"stormlink/server/ent/user"
+	"stormlink/server/model"
+	"stormlink/server/modlog"
 	sharedauth "stormlink/shared/auth"
 	"strconv"
+	"time"
 )
 
 type HostMuteUsecase interface {
-	MuteUser(ctx context.Context, userID string) (*ent.HostUserMute, error)
+	MuteUser(ctx context.Context, userID string, d model.SanctionDetails) (*ent.HostUserMute, error)
 	UnmuteUser(ctx context.Context, muteID string) (bool, error)
 	GetUserMutes(ctx context.Context) ([]*ent.HostUserMute, error)
-	MuteCommunity(ctx context.Context, communityID string) (*ent.HostCommunityMute, error)
+	MuteCommunity(ctx context.Context, communityID string, d model.SanctionDetails) (*ent.HostCommunityMute, error)
 	UnmuteCommunity(ctx context.Context, muteID string) (bool, error)
 	GetCommunityMutes(ctx context.Context) ([]*ent.HostCommunityMute, error)
 }
 
 type hostMuteUsecase struct {
 	client *ent.Client
+	authz  *authz.Authorizer
 }
 
 func NewHostMuteUsecase(client *ent.Client) HostMuteUsecase {
-	return &hostMuteUsecase{client: client}
+	return &hostMuteUsecase{client: client, authz: authz.New(authz.NewEntStore(client))}
 }
 
-func (uc *hostMuteUsecase) MuteUser(ctx context.Context, userID string) (*ent.HostUserMute, error) {
+func (uc *hostMuteUsecase) MuteUser(ctx context.Context, userID string, d model.SanctionDetails) (*ent.HostUserMute, error) {
 	// Проверяем авторизацию
 	currentUserID, err := sharedauth.UserIDFromContext(ctx)
 	if err != nil {
@@ -55,22 +61,57 @@ func (uc *hostMuteUsecase) MuteUser(ctx context.Context, userID string) (*ent.Ho
 		return nil, fmt.Errorf("cannot mute yourself")
 	}
 
-	// Проверяем, не замучен ли уже пользователь
-	_, err = uc.client.HostUserMute.
+	// Старших по иерархии платформы мутить нельзя
+	outranks, err := uc.authz.Can(ctx, currentUserID, authz.HostMuteUser, authz.Host().Against(userIDInt))
+	if err != nil {
+		return nil, fmt.Errorf("failed to check permissions: %w", err)
+	}
+	if !outranks {
+		return nil, fmt.Errorf("insufficient permissions to mute this user")
+	}
+
+	// Проверяем, не замучен ли уже пользователь (истекшие муты не считаются)
+	muted, err := uc.client.HostUserMute.
 		Query().
-		Where(hostusermute.HasUserWith(user.IDEQ(userIDInt))).
-		Only(ctx)
-	if err == nil {
+		Where(
+			hostusermute.HasUserWith(user.IDEQ(userIDInt)),
+			hostusermute.Or(hostusermute.ExpiresAtIsNil(), hostusermute.ExpiresAtGT(time.Now())),
+		).
+		Exist(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing mute: %w", err)
+	}
+	if muted {
 		return nil, fmt.Errorf("user is already muted")
 	}
 
-	// Создаем мут
-	mute, err := uc.client.HostUserMute.
-		Create().
-		SetUserID(userIDInt).
-		Save(ctx)
+	// Создаем мут вместе с записью в журнале модерации
+	var mute *ent.HostUserMute
+	err = modlog.InTx(ctx, uc.client, func(tx *ent.Tx) error {
+		mute, err = tx.HostUserMute.
+			Create().
+			SetUserID(userIDInt).
+			SetReason(d.Reason).
+			SetIssuedBy(currentUserID).
+			SetNillableExpiresAt(d.ExpiresAt).
+			SetPublicNote(d.PublicNote).
+			SetPrivateNote(d.PrivateNote).
+			SetShadow(d.Shadow).
+			Save(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to mute user: %w", err)
+		}
+		return modlog.Record(ctx, tx.ModerationAction, modlog.Entry{
+			Type:         moderationaction.TypeUserMuted,
+			ModeratorID:  &currentUserID,
+			TargetType:   moderationaction.TargetTypeUser,
+			TargetID:     userIDInt,
+			TargetUserID: &userIDInt,
+			After:        mute,
+		})
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to mute user: %w", err)
+		return nil, err
 	}
 
 	return mute, nil
@@ -98,11 +139,32 @@ func (uc *hostMuteUsecase) UnmuteUser(ctx context.Context, muteID string) (bool,
 		return false, fmt.Errorf("invalid mute ID: %w", err)
 	}
 
-	// Удаляем мут
-	err = uc.client.HostUserMute.DeleteOneID(muteIDInt).Exec(ctx)
+	mute, err := uc.client.HostUserMute.Query().
+		Where(hostusermute.IDEQ(muteIDInt)).
+		WithUser().
+		Only(ctx)
 	if err != nil {
 		return false, fmt.Errorf("failed to unmute user: %w", err)
 	}
+	userID := mute.Edges.User.ID
+
+	// Удаляем мут вместе с записью в журнале модерации
+	err = modlog.InTx(ctx, uc.client, func(tx *ent.Tx) error {
+		if err := tx.HostUserMute.DeleteOneID(muteIDInt).Exec(ctx); err != nil {
+			return fmt.Errorf("failed to unmute user: %w", err)
+		}
+		return modlog.Record(ctx, tx.ModerationAction, modlog.Entry{
+			Type:         moderationaction.TypeUserUnmuted,
+			ModeratorID:  &currentUserID,
+			TargetType:   moderationaction.TargetTypeUser,
+			TargetID:     userID,
+			TargetUserID: &userID,
+			Before:       mute,
+		})
+	})
+	if err != nil {
+		return false, err
+	}
 
 	return true, nil
 }
@@ -111,6 +173,7 @@ func (uc *hostMuteUsecase) GetUserMutes(ctx context.Context) ([]*ent.HostUserMut
 	mutes, err := uc.client.HostUserMute.
 		Query().
 		WithUser().
+		WithIssuer().
 		Order(ent.Asc(hostusermute.FieldCreatedAt)).
 		All(ctx)
 	if err != nil {
@@ -120,7 +183,7 @@ func (uc *hostMuteUsecase) GetUserMutes(ctx context.Context) ([]*ent.HostUserMut
 	return mutes, nil
 }
 
-func (uc *hostMuteUsecase) MuteCommunity(ctx context.Context, communityID string) (*ent.HostCommunityMute, error) {
+func (uc *hostMuteUsecase) MuteCommunity(ctx context.Context, communityID string, d model.SanctionDetails) (*ent.HostCommunityMute, error) {
 	// Проверяем авторизацию
 	currentUserID, err := sharedauth.UserIDFromContext(ctx)
 	if err != nil {
@@ -142,22 +205,47 @@ func (uc *hostMuteUsecase) MuteCommunity(ctx context.Context, communityID string
 		return nil, fmt.Errorf("invalid community ID: %w", err)
 	}
 
-	// Проверяем, не замучено ли уже сообщество
-	_, err = uc.client.HostCommunityMute.
+	// Проверяем, не замучено ли уже сообщество (истекшие муты не считаются)
+	muted, err := uc.client.HostCommunityMute.
 		Query().
-		Where(hostcommunitymute.CommunityIDEQ(communityIDInt)).
-		Only(ctx)
-	if err == nil {
+		Where(
+			hostcommunitymute.CommunityIDEQ(communityIDInt),
+			hostcommunitymute.Or(hostcommunitymute.ExpiresAtIsNil(), hostcommunitymute.ExpiresAtGT(time.Now())),
+		).
+		Exist(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing mute: %w", err)
+	}
+	if muted {
 		return nil, fmt.Errorf("community is already muted")
 	}
 
-	// Создаем мут
-	mute, err := uc.client.HostCommunityMute.
-		Create().
-		SetCommunityID(communityIDInt).
-		Save(ctx)
+	// Создаем мут вместе с записью в журнале модерации
+	var mute *ent.HostCommunityMute
+	err = modlog.InTx(ctx, uc.client, func(tx *ent.Tx) error {
+		mute, err = tx.HostCommunityMute.
+			Create().
+			SetCommunityID(communityIDInt).
+			SetReason(d.Reason).
+			SetIssuedBy(currentUserID).
+			SetNillableExpiresAt(d.ExpiresAt).
+			SetPublicNote(d.PublicNote).
+			SetPrivateNote(d.PrivateNote).
+			Save(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to mute community: %w", err)
+		}
+		return modlog.Record(ctx, tx.ModerationAction, modlog.Entry{
+			Type:        moderationaction.TypeCommunityMuted,
+			ModeratorID: &currentUserID,
+			CommunityID: &communityIDInt,
+			TargetType:  moderationaction.TargetTypeCommunity,
+			TargetID:    communityIDInt,
+			After:       mute,
+		})
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to mute community: %w", err)
+		return nil, err
 	}
 
 	return mute, nil
@@ -185,12 +273,29 @@ func (uc *hostMuteUsecase) UnmuteCommunity(ctx context.Context, muteID string) (
 		return false, fmt.Errorf("invalid mute ID: %w", err)
 	}
 
-	// Удаляем мут
-	err = uc.client.HostCommunityMute.DeleteOneID(muteIDInt).Exec(ctx)
+	mute, err := uc.client.HostCommunityMute.Get(ctx, muteIDInt)
 	if err != nil {
 		return false, fmt.Errorf("failed to unmute community: %w", err)
 	}
 
+	// Удаляем мут вместе с записью в журнале модерации
+	err = modlog.InTx(ctx, uc.client, func(tx *ent.Tx) error {
+		if err := tx.HostCommunityMute.DeleteOneID(muteIDInt).Exec(ctx); err != nil {
+			return fmt.Errorf("failed to unmute community: %w", err)
+		}
+		return modlog.Record(ctx, tx.ModerationAction, modlog.Entry{
+			Type:        moderationaction.TypeCommunityUnmuted,
+			ModeratorID: &currentUserID,
+			CommunityID: &mute.CommunityID,
+			TargetType:  moderationaction.TargetTypeCommunity,
+			TargetID:    mute.CommunityID,
+			Before:      mute,
+		})
+	})
+	if err != nil {
+		return false, err
+	}
+
 	return true, nil
 }
 
@@ -198,6 +303,7 @@ func (uc *hostMuteUsecase) GetCommunityMutes(ctx context.Context) ([]*ent.HostCo
 	mutes, err := uc.client.HostCommunityMute.
 		Query().
 		WithCommunity().
+		WithIssuer().
 		Order(ent.Asc(hostcommunitymute.FieldCreatedAt)).
 		All(ctx)
 	if err != nil {
@@ -209,38 +315,10 @@ func (uc *hostMuteUsecase) GetCommunityMutes(ctx context.Context) ([]*ent.HostCo
 
 // canMuteUsers проверяет, может ли пользователь мутить других пользователей
 func (uc *hostMuteUsecase) canMuteUsers(ctx context.Context, userID int) (bool, error) {
-	// 1. Проверяем, является ли пользователь владельцем платформы
-	host, err := uc.client.Host.Get(ctx, 1)
-	if err != nil {
-		return false, fmt.Errorf("host not found: %w", err)
-	}
-	
-	if host.OwnerID != nil && *host.OwnerID == userID {
-		return true, nil
-	}
-	
-	// 2. Проверяем роли пользователя на платформе
-	// TODO: Добавить проверку ролей когда будет реализована система ролей платформы
-	// Пока что только владелец может мутить пользователей
-	
-	return false, nil
+	return uc.authz.Can(ctx, userID, authz.HostMuteUser, authz.Host())
 }
 
 // canMuteCommunities проверяет, может ли пользователь мутить сообщества
 func (uc *hostMuteUsecase) canMuteCommunities(ctx context.Context, userID int) (bool, error) {
-	// 1. Проверяем, является ли пользователь владельцем платформы
-	host, err := uc.client.Host.Get(ctx, 1)
-	if err != nil {
-		return false, fmt.Errorf("host not found: %w", err)
-	}
-	
-	if host.OwnerID != nil && *host.OwnerID == userID {
-		return true, nil
-	}
-	
-	// 2. Проверяем роли пользователя на платформе
-	// TODO: Добавить проверку ролей когда будет реализована система ролей платформы
-	// Пока что только владелец может мутить сообщества
-	
-	return false, nil
+	return uc.authz.Can(ctx, userID, authz.HostMuteCommunity, authz.Host())
 }
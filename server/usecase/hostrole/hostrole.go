@@ -3,9 +3,12 @@ package hostrole
 import (
 	"context"
 	"fmt"
+	"stormlink/server/authz"
 	"stormlink/server/ent"
 	"stormlink/server/ent/hostrole"
+	"stormlink/server/ent/moderationaction"
 	"stormlink/server/graphql/models"
+	"stormlink/server/modlog"
 	"strconv"
 )
 
@@ -28,57 +31,66 @@ func NewHostRoleUsecase(client *ent.Client) HostRoleUsecase {
 }
 
 func (uc *hostRoleUsecase) CreateHostRole(ctx context.Context, input *models.CreateHostRoleInput) (*ent.HostRole, error) {
-	create := uc.client.HostRole.Create().
-		SetTitle(input.Title)
-
-	if input.Color != nil {
-		create = create.SetColor(*input.Color)
-	}
+	var badgeID *int
 	if input.BadgeID != nil {
-		badgeID, err := strconv.Atoi(*input.BadgeID)
+		id, err := strconv.Atoi(*input.BadgeID)
 		if err != nil {
 			return nil, fmt.Errorf("invalid badgeID: %w", err)
 		}
-		create = create.SetBadgeID(badgeID)
-	}
-	if input.CommunityRolesManagement != nil {
-		create = create.SetCommunityRolesManagement(*input.CommunityRolesManagement)
-	}
-	if input.HostUserBan != nil {
-		create = create.SetHostUserBan(*input.HostUserBan)
-	}
-	if input.HostUserMute != nil {
-		create = create.SetHostUserMute(*input.HostUserMute)
+		badgeID = &id
 	}
-	if input.HostCommunityDeletePost != nil {
-		create = create.SetHostCommunityDeletePost(*input.HostCommunityDeletePost)
-	}
-	if input.HostCommunityRemovePostFromPublication != nil {
-		create = create.SetHostCommunityRemovePostFromPublication(*input.HostCommunityRemovePostFromPublication)
+	var permissions []string
+	if input.Permissions != nil {
+		p, err := authz.ParsePermissions(authz.ScopeHost, input.Permissions)
+		if err != nil {
+			return nil, fmt.Errorf("invalid permissions: %w", err)
+		}
+		permissions = p
 	}
-	if input.HostCommunityDeleteComments != nil {
-		create = create.SetHostCommunityDeleteComments(*input.HostCommunityDeleteComments)
+	var denied []string
+	if input.DeniedActions != nil {
+		d, err := authz.ParseActions(input.DeniedActions)
+		if err != nil {
+			return nil, fmt.Errorf("invalid deniedActions: %w", err)
+		}
+		denied = d
 	}
-
-	role, err := create.Save(ctx)
+	userIDs, err := parseUserIDs(input.UserIDs)
 	if err != nil {
-		return nil, fmt.Errorf("create host role: %w", err)
+		return nil, err
 	}
 
-	// Добавляем пользователей если есть
-	if len(input.UserIDs) > 0 {
-		userIDs := make([]int, len(input.UserIDs))
-		for i, userIDStr := range input.UserIDs {
-			userID, err := strconv.Atoi(userIDStr)
-			if err != nil {
-				return nil, fmt.Errorf("invalid userID %s: %w", userIDStr, err)
-			}
-			userIDs[i] = userID
+	// Создаем роль и добавляем пользователей вместе с записями в журнале модерации
+	var role *ent.HostRole
+	err = modlog.InTx(ctx, uc.client, func(tx *ent.Tx) error {
+		create := tx.HostRole.Create().
+			SetTitle(input.Title).
+			SetNillableColor(input.Color).
+			SetNillableBadgeID(badgeID).
+			SetNillablePosition(input.Position).
+			SetPermissionsVersion(authz.RegistryVersion).
+			AddUserIDs(userIDs...)
+		if input.Permissions != nil {
+			create = create.SetPermissions(permissions)
 		}
-		err = uc.AddUsersToRole(ctx, role.ID, userIDs)
+		if input.DeniedActions != nil {
+			create = create.SetDeniedActions(denied)
+		}
+		role, err = create.Save(ctx)
 		if err != nil {
-			return nil, fmt.Errorf("add users to role: %w", err)
+			return fmt.Errorf("create host role: %w", err)
+		}
+
+		entry := roleEntry(role)
+		entry.Type = moderationaction.TypeRoleCreated
+		entry.After = role
+		if err := modlog.Record(ctx, tx.ModerationAction, entry); err != nil {
+			return err
 		}
+		return modlog.RecordMembers(ctx, tx.ModerationAction, entry, nil, userIDs)
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return role, nil
@@ -90,68 +102,79 @@ func (uc *hostRoleUsecase) UpdateHostRole(ctx context.Context, input *models.Upd
 		return nil, fmt.Errorf("invalid role ID: %w", err)
 	}
 
-	update := uc.client.HostRole.UpdateOneID(roleID)
-
-	if input.Title != nil {
-		update = update.SetTitle(*input.Title)
-	}
-	if input.Color != nil {
-		update = update.SetColor(*input.Color)
-	}
-	if input.BadgeID != nil {
-		badgeID, err := strconv.Atoi(*input.BadgeID)
-		if err != nil {
-			return nil, fmt.Errorf("invalid badgeID: %w", err)
-		}
-		update = update.SetBadgeID(badgeID)
-	}
-	if input.CommunityRolesManagement != nil {
-		update = update.SetCommunityRolesManagement(*input.CommunityRolesManagement)
-	}
-	if input.HostUserBan != nil {
-		update = update.SetHostUserBan(*input.HostUserBan)
-	}
-	if input.HostUserMute != nil {
-		update = update.SetHostUserMute(*input.HostUserMute)
-	}
-	if input.HostCommunityDeletePost != nil {
-		update = update.SetHostCommunityDeletePost(*input.HostCommunityDeletePost)
-	}
-	if input.HostCommunityRemovePostFromPublication != nil {
-		update = update.SetHostCommunityRemovePostFromPublication(*input.HostCommunityRemovePostFromPublication)
-	}
-	if input.HostCommunityDeleteComments != nil {
-		update = update.SetHostCommunityDeleteComments(*input.HostCommunityDeleteComments)
+	before, err := uc.client.HostRole.Get(ctx, roleID)
+	if err != nil {
+		return nil, fmt.Errorf("role not found: %w", err)
 	}
-
-	role, err := update.Save(ctx)
+	membersBefore, err := uc.client.HostRole.QueryUsers(before).IDs(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("update host role: %w", err)
+		return nil, fmt.Errorf("get role users: %w", err)
 	}
 
-	// Обновляем пользователей если есть
-	if input.UserIDs != nil {
-		// Сначала очищаем всех пользователей
-		_, err = uc.client.HostRole.UpdateOneID(roleID).ClearUsers().Save(ctx)
-		if err != nil {
-			return nil, fmt.Errorf("clear users from role: %w", err)
-		}
-
-		// Добавляем новых пользователей
-		if len(input.UserIDs) > 0 {
-			userIDs := make([]int, len(input.UserIDs))
-			for i, userIDStr := range input.UserIDs {
-				userID, err := strconv.Atoi(userIDStr)
-				if err != nil {
-					return nil, fmt.Errorf("invalid userID %s: %w", userIDStr, err)
-				}
-				userIDs[i] = userID
+	var role *ent.HostRole
+	err = modlog.InTx(ctx, uc.client, func(tx *ent.Tx) error {
+		update := tx.HostRole.UpdateOneID(roleID)
+
+		if input.Title != nil {
+			update = update.SetTitle(*input.Title)
+		}
+		if input.Color != nil {
+			update = update.SetColor(*input.Color)
+		}
+		if input.BadgeID != nil {
+			badgeID, err := strconv.Atoi(*input.BadgeID)
+			if err != nil {
+				return fmt.Errorf("invalid badgeID: %w", err)
 			}
-			err = uc.AddUsersToRole(ctx, roleID, userIDs)
+			update = update.SetBadgeID(badgeID)
+		}
+		if input.Permissions != nil {
+			permissions, err := authz.ParsePermissions(authz.ScopeHost, input.Permissions)
 			if err != nil {
-				return nil, fmt.Errorf("add users to role: %w", err)
+				return fmt.Errorf("invalid permissions: %w", err)
 			}
+			update = update.SetPermissions(permissions)
 		}
+		if input.Permissions != nil || input.DeniedActions != nil {
+			update = update.SetPermissionsVersion(authz.RegistryVersion)
+		}
+		if input.Position != nil {
+			update = update.SetPosition(*input.Position)
+		}
+		if input.DeniedActions != nil {
+			denied, err := authz.ParseActions(input.DeniedActions)
+			if err != nil {
+				return fmt.Errorf("invalid deniedActions: %w", err)
+			}
+			update = update.SetDeniedActions(denied)
+		}
+
+		// Состав роли заменяется целиком, если передан
+		membersAfter := membersBefore
+		if input.UserIDs != nil {
+			if membersAfter, err = parseUserIDs(input.UserIDs); err != nil {
+				return err
+			}
+			update = update.ClearUsers().AddUserIDs(membersAfter...)
+		}
+
+		role, err = update.Save(ctx)
+		if err != nil {
+			return fmt.Errorf("update host role: %w", err)
+		}
+
+		entry := roleEntry(role)
+		entry.Type = moderationaction.TypeRoleUpdated
+		entry.Before = before
+		entry.After = role
+		if err := modlog.Record(ctx, tx.ModerationAction, entry); err != nil {
+			return err
+		}
+		entry.Before = nil
+		return modlog.RecordMembers(ctx, tx.ModerationAction, entry, membersBefore, membersAfter)
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return role, nil
@@ -159,18 +182,21 @@ func (uc *hostRoleUsecase) UpdateHostRole(ctx context.Context, input *models.Upd
 
 func (uc *hostRoleUsecase) DeleteHostRole(ctx context.Context, id int) error {
 	// Проверяем, что роль существует
-	_, err := uc.client.HostRole.Get(ctx, id)
+	role, err := uc.client.HostRole.Get(ctx, id)
 	if err != nil {
 		return fmt.Errorf("role not found: %w", err)
 	}
 
-	// Удаляем роль
-	err = uc.client.HostRole.DeleteOneID(id).Exec(ctx)
-	if err != nil {
-		return fmt.Errorf("delete host role: %w", err)
-	}
-
-	return nil
+	// Удаляем роль вместе с записью в журнале модерации
+	return modlog.InTx(ctx, uc.client, func(tx *ent.Tx) error {
+		if err := tx.HostRole.DeleteOneID(id).Exec(ctx); err != nil {
+			return fmt.Errorf("delete host role: %w", err)
+		}
+		entry := roleEntry(role)
+		entry.Type = moderationaction.TypeRoleDeleted
+		entry.Before = role
+		return modlog.Record(ctx, tx.ModerationAction, entry)
+	})
 }
 
 func (uc *hostRoleUsecase) GetHostRoles(ctx context.Context) ([]*ent.HostRole, error) {
@@ -195,14 +221,20 @@ func (uc *hostRoleUsecase) AddUsersToRole(ctx context.Context, roleID int, userI
 	if err != nil {
 		return fmt.Errorf("role not found: %w", err)
 	}
-
-	// Добавляем пользователей
-	_, err = uc.client.HostRole.UpdateOne(role).AddUserIDs(userIDs...).Save(ctx)
+	members, err := uc.client.HostRole.QueryUsers(role).IDs(ctx)
 	if err != nil {
-		return fmt.Errorf("add users to role: %w", err)
+		return fmt.Errorf("get role users: %w", err)
 	}
 
-	return nil
+	// Добавляем пользователей вместе с записями в журнале модерации
+	return modlog.InTx(ctx, uc.client, func(tx *ent.Tx) error {
+		if err := tx.HostRole.UpdateOne(role).AddUserIDs(userIDs...).Exec(ctx); err != nil {
+			return fmt.Errorf("add users to role: %w", err)
+		}
+		entry := roleEntry(role)
+		entry.After = role
+		return modlog.RecordMembers(ctx, tx.ModerationAction, entry, members, append(members, userIDs...))
+	})
 }
 
 func (uc *hostRoleUsecase) RemoveUserFromRole(ctx context.Context, roleID int, userID int) error {
@@ -212,11 +244,33 @@ func (uc *hostRoleUsecase) RemoveUserFromRole(ctx context.Context, roleID int, u
 		return fmt.Errorf("role not found: %w", err)
 	}
 
-	// Удаляем пользователя
-	_, err = uc.client.HostRole.UpdateOne(role).RemoveUserIDs(userID).Save(ctx)
-	if err != nil {
-		return fmt.Errorf("remove user from role: %w", err)
+	// Удаляем пользователя вместе с записью в журнале модерации
+	return modlog.InTx(ctx, uc.client, func(tx *ent.Tx) error {
+		if err := tx.HostRole.UpdateOne(role).RemoveUserIDs(userID).Exec(ctx); err != nil {
+			return fmt.Errorf("remove user from role: %w", err)
+		}
+		entry := roleEntry(role)
+		entry.Before = role
+		return modlog.RecordMembers(ctx, tx.ModerationAction, entry, []int{userID}, nil)
+	})
+}
+
+// roleEntry — запись журнала о роли платформы; тип и снимки заполняет вызывающий
+func roleEntry(role *ent.HostRole) modlog.Entry {
+	return modlog.Entry{
+		TargetType: moderationaction.TargetTypeHostRole,
+		TargetID:   role.ID,
 	}
+}
 
-	return nil
+func parseUserIDs(ids []string) ([]int, error) {
+	out := make([]int, len(ids))
+	for i, s := range ids {
+		id, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid userID %s: %w", s, err)
+		}
+		out[i] = id
+	}
+	return out, nil
 }
@@ -3,9 +3,12 @@ package hostrule
 import (
 	"context"
 	"fmt"
+	"stormlink/server/authz"
 	"stormlink/server/ent"
 	"stormlink/server/ent/hostrule"
+	"stormlink/server/ent/moderationaction"
 	"stormlink/server/graphql/models"
+	"stormlink/server/modlog"
 	sharedauth "stormlink/shared/auth"
 	"strconv"
 )
@@ -20,10 +23,11 @@ type HostRuleUsecase interface {
 
 type hostRuleUsecase struct {
 	client *ent.Client
+	authz  *authz.Authorizer
 }
 
 func NewHostRuleUsecase(client *ent.Client) HostRuleUsecase {
-	return &hostRuleUsecase{client: client}
+	return &hostRuleUsecase{client: client, authz: authz.New(authz.NewEntStore(client))}
 }
 
 func (uc *hostRuleUsecase) CreateHostRule(ctx context.Context, input *models.CreateHostRuleInput) (*ent.HostRule, error) {
@@ -42,15 +46,22 @@ func (uc *hostRuleUsecase) CreateHostRule(ctx context.Context, input *models.Cre
 		return nil, fmt.Errorf("insufficient permissions to manage platform rules")
 	}
 
-	// Создаем правило (всегда для хоста с ID = 1)
-	rule, err := uc.client.HostRule.
-		Create().
-		SetTitle(input.Title).
-		SetDescription(input.Description).
-		SetHostID(1). // Фиксированный ID хоста
-		Save(ctx)
+	// Создаем правило (всегда для хоста с ID = 1) вместе с записью в журнале модерации
+	var rule *ent.HostRule
+	err = modlog.InTx(ctx, uc.client, func(tx *ent.Tx) error {
+		rule, err = tx.HostRule.
+			Create().
+			SetTitle(input.Title).
+			SetDescription(input.Description).
+			SetHostID(1). // Фиксированный ID хоста
+			Save(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to create host rule: %w", err)
+		}
+		return modlog.Record(ctx, tx.ModerationAction, ruleEntry(moderationaction.TypeRuleCreated, rule.ID, nil, rule))
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create host rule: %w", err)
+		return nil, err
 	}
 
 	return rule, nil
@@ -78,19 +89,31 @@ func (uc *hostRuleUsecase) UpdateHostRule(ctx context.Context, input *models.Upd
 		return nil, fmt.Errorf("insufficient permissions to manage platform rules")
 	}
 
-	// Обновляем правило
-	update := uc.client.HostRule.UpdateOneID(ruleIDInt)
-	
-	if input.Title != nil {
-		update = update.SetTitle(*input.Title)
-	}
-	if input.Description != nil {
-		update = update.SetDescription(*input.Description)
+	before, err := uc.client.HostRule.Get(ctx, ruleIDInt)
+	if err != nil {
+		return nil, fmt.Errorf("rule not found: %w", err)
 	}
 
-	updatedRule, err := update.Save(ctx)
+	// Обновляем правило вместе с записью в журнале модерации
+	var updatedRule *ent.HostRule
+	err = modlog.InTx(ctx, uc.client, func(tx *ent.Tx) error {
+		update := tx.HostRule.UpdateOneID(ruleIDInt)
+
+		if input.Title != nil {
+			update = update.SetTitle(*input.Title)
+		}
+		if input.Description != nil {
+			update = update.SetDescription(*input.Description)
+		}
+
+		updatedRule, err = update.Save(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to update host rule: %w", err)
+		}
+		return modlog.Record(ctx, tx.ModerationAction, ruleEntry(moderationaction.TypeRuleUpdated, ruleIDInt, before, updatedRule))
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to update host rule: %w", err)
+		return nil, err
 	}
 
 	return updatedRule, nil
@@ -118,10 +141,20 @@ func (uc *hostRuleUsecase) DeleteHostRule(ctx context.Context, id string) (bool,
 		return false, fmt.Errorf("insufficient permissions to manage platform rules")
 	}
 
-	// Удаляем правило
-	err = uc.client.HostRule.DeleteOneID(ruleIDInt).Exec(ctx)
+	rule, err := uc.client.HostRule.Get(ctx, ruleIDInt)
+	if err != nil {
+		return false, fmt.Errorf("rule not found: %w", err)
+	}
+
+	// Удаляем правило вместе с записью в журнале модерации
+	err = modlog.InTx(ctx, uc.client, func(tx *ent.Tx) error {
+		if err := tx.HostRule.DeleteOneID(ruleIDInt).Exec(ctx); err != nil {
+			return fmt.Errorf("failed to delete host rule: %w", err)
+		}
+		return modlog.Record(ctx, tx.ModerationAction, ruleEntry(moderationaction.TypeRuleDeleted, ruleIDInt, rule, nil))
+	})
 	if err != nil {
-		return false, fmt.Errorf("failed to delete host rule: %w", err)
+		return false, err
 	}
 
 	return true, nil
@@ -159,21 +192,18 @@ func (uc *hostRuleUsecase) GetHostRules(ctx context.Context) ([]*ent.HostRule, e
 	return rules, nil
 }
 
+// ruleEntry — запись журнала о правиле платформы; before и after — nil, если снимка нет
+func ruleEntry(t moderationaction.Type, ruleID int, before, after *ent.HostRule) modlog.Entry {
+	return modlog.Entry{
+		Type:       t,
+		TargetType: moderationaction.TargetTypeHostRule,
+		TargetID:   ruleID,
+		Before:     before,
+		After:      after,
+	}
+}
+
 // canManageHostRules проверяет, может ли пользователь управлять правилами платформы
 func (uc *hostRuleUsecase) canManageHostRules(ctx context.Context, userID int) (bool, error) {
-	// 1. Проверяем, является ли пользователь владельцем платформы
-	host, err := uc.client.Host.Get(ctx, 1)
-	if err != nil {
-		return false, fmt.Errorf("host not found: %w", err)
-	}
-	
-	if host.OwnerID != nil && *host.OwnerID == userID {
-		return true, nil
-	}
-	
-	// 2. Проверяем роли пользователя на платформе
-	// TODO: Добавить проверку ролей когда будет реализована система ролей платформы
-	// Пока что только владелец может управлять правилами
-	
-	return false, nil
+	return uc.authz.Can(ctx, userID, authz.ManageHost, authz.Host())
 }
@@ -0,0 +1,101 @@
+package moderationlog
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"stormlink/server/ent"
+	"stormlink/server/ent/moderationaction"
+	"stormlink/server/ent/predicate"
+	"stormlink/server/graphql/models"
+	"stormlink/server/usecase/pagination"
+)
+
+// ModerationLogUsecase читает журнал модерации; записи делают сами действия через server/modlog
+type ModerationLogUsecase interface {
+	// List — записи журнала, новые первыми. Права на просмотр проверяет вызывающий.
+	List(ctx context.Context, filter *models.ModerationLogFilter, first int, after *string) (*models.ModerationActionsConnection, error)
+}
+
+type moderationLogUsecase struct {
+	client *ent.Client
+}
+
+func NewModerationLogUsecase(client *ent.Client) ModerationLogUsecase {
+	return &moderationLogUsecase{client: client}
+}
+
+func (uc *moderationLogUsecase) List(ctx context.Context, filter *models.ModerationLogFilter, first int, after *string) (*models.ModerationActionsConnection, error) {
+	if first <= 0 {
+		first = pagination.DefaultLimit
+	}
+	where, err := predicates(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	base := uc.client.ModerationAction.Query().Where(where...)
+	page, err := pagination.Paginate(ctx, base, pagination.Args{First: &first, After: after}, pagination.Order[*ent.ModerationAction]{
+		Field: moderationaction.FieldCreatedAt,
+		Desc:  true,
+		Key:   func(a *ent.ModerationAction) (time.Time, int) { return a.CreatedAt, a.ID },
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list moderation actions: %w", err)
+	}
+	edges := pagination.MapEdges(page, func(cur string, a *ent.ModerationAction) *models.ModerationActionEdge {
+		return &models.ModerationActionEdge{Cursor: cur, Node: a}
+	})
+	return &models.ModerationActionsConnection{Edges: edges, PageInfo: page.PageInfo}, nil
+}
+
+// predicates переводит фильтр GraphQL в условия запроса
+func predicates(f *models.ModerationLogFilter) ([]predicate.ModerationAction, error) {
+	if f == nil {
+		return nil, nil
+	}
+	var out []predicate.ModerationAction
+	if f.CommunityID != nil {
+		id, err := strconv.Atoi(*f.CommunityID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid communityID: %w", err)
+		}
+		out = append(out, moderationaction.CommunityIDEQ(id))
+	}
+	if f.ModeratorID != nil {
+		id, err := strconv.Atoi(*f.ModeratorID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid moderatorID: %w", err)
+		}
+		out = append(out, moderationaction.ModeratorIDEQ(id))
+	}
+	if f.TargetUserID != nil {
+		id, err := strconv.Atoi(*f.TargetUserID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid targetUserID: %w", err)
+		}
+		out = append(out, moderationaction.TargetUserIDEQ(id))
+	}
+	if f.TargetType != nil {
+		out = append(out, moderationaction.TargetTypeEQ(*f.TargetType))
+	}
+	if f.TargetID != nil {
+		id, err := strconv.Atoi(*f.TargetID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid targetID: %w", err)
+		}
+		out = append(out, moderationaction.TargetIDEQ(id))
+	}
+	if len(f.Types) > 0 {
+		out = append(out, moderationaction.TypeIn(f.Types...))
+	}
+	if f.From != nil {
+		out = append(out, moderationaction.CreatedAtGTE(*f.From))
+	}
+	if f.To != nil {
+		out = append(out, moderationaction.CreatedAtLT(*f.To))
+	}
+	return out, nil
+}
@@ -0,0 +1,317 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+
+	"stormlink/server/ent"
+	"stormlink/server/ent/notification"
+	"stormlink/server/ent/notificationsettings"
+	"stormlink/server/graphql/models"
+	nsuc "stormlink/server/usecase/notificationsettings"
+	"stormlink/server/usecase/pagination"
+	"stormlink/shared/outbox"
+)
+
+// maxActors — сколько последних участников группы храним для отображения («alice, bob и ещё 3»)
+const maxActors = 5
+
+// Event — доменное событие, из которого создаётся (или дополняется) уведомление
+type Event struct {
+	RecipientID int
+	ActorID     int
+	Type        notification.Type
+	PostID      *int
+	CommentID   *int
+	CommunityID *int
+	Message     *string
+}
+
+// JobNotificationEmail — задача отправки письма о событии (канал email в настройках уведомлений)
+const JobNotificationEmail = "notification_email"
+
+// EmailJob — событие для мгновенного письма; пишется в outbox вместе с уведомлением
+type EmailJob struct {
+	UserID      int     `json:"user_id"`
+	ActorID     int     `json:"actor_id,omitempty"`
+	Type        string  `json:"type"`
+	PostID      *int    `json:"post_id,omitempty"`
+	CommentID   *int    `json:"comment_id,omitempty"`
+	CommunityID *int    `json:"community_id,omitempty"`
+	Message     *string `json:"message,omitempty"`
+}
+
+type NotificationUsecase interface {
+	// Notify применяет настройки получателя (заглушенные обсуждения, каналы, тихие часы),
+	// создаёт уведомление или схлопывает его с непрочитанным того же group_key и ставит письмо в outbox.
+	// n == nil, если in-app уведомление не создавалось; push == false, если realtime-доставку нужно пропустить.
+	Notify(ctx context.Context, ev Event) (n *ent.Notification, push bool, err error)
+	List(ctx context.Context, userID int, first int, after *string, unreadOnly bool) (*models.NotificationsConnection, error)
+	UnreadCount(ctx context.Context, userID int) (int, error)
+	MarkRead(ctx context.Context, userID int, ids []int) (int, error)
+	MarkAllRead(ctx context.Context, userID int) (int, error)
+}
+
+type notificationUsecase struct {
+	client *ent.Client
+}
+
+func NewNotificationUsecase(client *ent.Client) NotificationUsecase {
+	return &notificationUsecase{client: client}
+}
+
+// GroupKey определяет, какие события схлопываются: лайки — по объекту, подписки — все вместе,
+// ответы — по посту; упоминания и модерация не группируются.
+func GroupKey(ev Event) string {
+	switch ev.Type {
+	case notification.TypePostLike:
+		return fmt.Sprintf("post_like:%d", deref(ev.PostID))
+	case notification.TypeCommentLike:
+		return fmt.Sprintf("comment_like:%d", deref(ev.CommentID))
+	case notification.TypeUserFollow:
+		return "user_follow"
+	case notification.TypeCommentReply:
+		return fmt.Sprintf("comment_reply:post:%d", deref(ev.PostID))
+	case notification.TypeMention:
+		return fmt.Sprintf("mention:comment:%d", deref(ev.CommentID))
+	default:
+		return fmt.Sprintf("%s:%d", ev.Type, time.Now().UnixNano())
+	}
+}
+
+func (uc *notificationUsecase) Notify(ctx context.Context, ev Event) (*ent.Notification, bool, error) {
+	if ev.RecipientID == 0 || ev.RecipientID == ev.ActorID {
+		return nil, false, nil
+	}
+
+	settings, err := uc.client.NotificationSettings.Query().
+		Where(notificationsettings.UserIDEQ(ev.RecipientID)).
+		Only(ctx)
+	if err != nil && !ent.IsNotFound(err) {
+		return nil, false, fmt.Errorf("load notification settings: %w", err)
+	}
+	// Заглушить можно обсуждение или сообщество, но не уведомления модерации
+	if ev.Type != notification.TypeModeration && nsuc.IsMuted(settings, ev.PostID, ev.CommunityID) {
+		return nil, false, nil
+	}
+	channels := nsuc.Channels(settings, string(ev.Type))
+	sendEmail := channels.Email && (settings == nil || settings.EmailFrequency != notificationsettings.EmailFrequencyOff)
+	if !channels.InApp && !sendEmail {
+		return nil, false, nil
+	}
+
+	tx, err := uc.client.Tx(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var n *ent.Notification
+	if channels.InApp {
+		if n, err = upsert(ctx, tx, ev); err != nil {
+			return nil, false, err
+		}
+	}
+	now := time.Now()
+	if sendEmail {
+		job := EmailJob{
+			UserID:      ev.RecipientID,
+			ActorID:     ev.ActorID,
+			Type:        string(ev.Type),
+			PostID:      ev.PostID,
+			CommentID:   ev.CommentID,
+			CommunityID: ev.CommunityID,
+			Message:     ev.Message,
+		}
+		// В тихие часы письмо откладывается до их окончания
+		if err := outbox.AddAt(ctx, tx.Outbox, JobNotificationEmail, job, nsuc.QuietHoursEnd(settings, now)); err != nil {
+			return nil, false, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, false, fmt.Errorf("commit notification: %w", err)
+	}
+	return n, n != nil && !nsuc.InQuietHours(settings, now), nil
+}
+
+// upsert дополняет непрочитанное уведомление той же группы или создаёт новое. Параллельные
+// события одной группы выполняются по очереди (блокировка до конца транзакции), иначе оба
+// не найдут группу и создадут две или одно обновление затрёт другое.
+func upsert(ctx context.Context, tx *ent.Tx, ev Event) (*ent.Notification, error) {
+	key := GroupKey(ev)
+
+	if _, err := tx.ExecContext(ctx,
+		`SELECT pg_advisory_xact_lock(hashtextextended($1, 0))`,
+		fmt.Sprintf("notification:%d:%s", ev.RecipientID, key),
+	); err != nil {
+		return nil, fmt.Errorf("lock notification group: %w", err)
+	}
+
+	existing, err := tx.Notification.Query().
+		Where(
+			notification.UserIDEQ(ev.RecipientID),
+			notification.GroupKeyEQ(key),
+			notification.ReadAtIsNil(),
+		).
+		Order(ent.Desc(notification.FieldUpdatedAt)).
+		First(ctx)
+	if err != nil && !ent.IsNotFound(err) {
+		return nil, fmt.Errorf("find notification group: %w", err)
+	}
+
+	if existing != nil {
+		display, all, added := mergeActors(existing.ActorIds, existing.AllActorIds, ev.ActorID)
+		upd := tx.Notification.UpdateOne(existing).
+			SetActorIds(display).
+			SetAllActorIds(all).
+			SetNillableCommentID(ev.CommentID).
+			SetNillableMessage(ev.Message)
+		if ev.ActorID != 0 {
+			upd.SetActorID(ev.ActorID)
+		}
+		if added {
+			upd.AddActorCount(1)
+		}
+		n, err := upd.Save(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("update notification group: %w", err)
+		}
+		return n, nil
+	}
+
+	create := tx.Notification.Create().
+		SetUserID(ev.RecipientID).
+		SetType(ev.Type).
+		SetGroupKey(key).
+		SetNillablePostID(ev.PostID).
+		SetNillableCommentID(ev.CommentID).
+		SetNillableCommunityID(ev.CommunityID).
+		SetNillableMessage(ev.Message)
+	if ev.ActorID != 0 {
+		create.SetActorID(ev.ActorID).SetActorIds([]int{ev.ActorID}).SetAllActorIds([]int{ev.ActorID})
+	}
+	n, err := create.Save(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("create notification: %w", err)
+	}
+	return n, nil
+}
+
+// mergeActors ставит участника первым в списке для отображения (не длиннее maxActors)
+// и добавляет в список всех участников группы; added=false, если он уже был в группе —
+// даже если вытеснен из списка для отображения
+func mergeActors(display, all []int, actorID int) ([]int, []int, bool) {
+	if actorID == 0 {
+		return display, all, true
+	}
+	// Группы, созданные до появления all_actor_ids: участники известны только по display
+	if len(all) == 0 {
+		all = slices.Clone(display)
+	}
+	added := !slices.Contains(all, actorID)
+	if added {
+		all = append(all, actorID)
+	}
+
+	out := []int{actorID}
+	for _, id := range display {
+		if id != actorID {
+			out = append(out, id)
+		}
+	}
+	if len(out) > maxActors {
+		out = out[:maxActors]
+	}
+	return out, all, added
+}
+
+func (uc *notificationUsecase) List(ctx context.Context, userID int, first int, after *string, unreadOnly bool) (*models.NotificationsConnection, error) {
+	if first <= 0 {
+		first = pagination.DefaultLimit
+	}
+
+	base := uc.client.Notification.Query().Where(notification.UserIDEQ(userID))
+	if unreadOnly {
+		base = base.Where(notification.ReadAtIsNil())
+	}
+
+	page, err := pagination.Paginate(ctx, base, pagination.Args{First: &first, After: after}, pagination.Order[*ent.Notification]{
+		Field: notification.FieldUpdatedAt,
+		Desc:  true,
+		Key:   func(n *ent.Notification) (time.Time, int) { return n.UpdatedAt, n.ID },
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list notifications: %w", err)
+	}
+	edges := pagination.MapEdges(page, func(cur string, n *ent.Notification) *models.NotificationEdge {
+		return &models.NotificationEdge{Cursor: cur, Node: n}
+	})
+
+	unread, err := uc.UnreadCount(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.NotificationsConnection{Edges: edges, PageInfo: page.PageInfo, UnreadCount: int32(unread)}, nil
+}
+
+func (uc *notificationUsecase) UnreadCount(ctx context.Context, userID int) (int, error) {
+	n, err := uc.client.Notification.Query().
+		Where(notification.UserIDEQ(userID), notification.ReadAtIsNil()).
+		Count(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("count unread notifications: %w", err)
+	}
+	return n, nil
+}
+
+func (uc *notificationUsecase) MarkRead(ctx context.Context, userID int, ids []int) (int, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	n, err := uc.client.Notification.Update().
+		Where(notification.UserIDEQ(userID), notification.IDIn(ids...), notification.ReadAtIsNil()).
+		SetReadAt(time.Now()).
+		Save(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("mark notifications read: %w", err)
+	}
+	return n, nil
+}
+
+func (uc *notificationUsecase) MarkAllRead(ctx context.Context, userID int) (int, error) {
+	n, err := uc.client.Notification.Update().
+		Where(notification.UserIDEQ(userID), notification.ReadAtIsNil()).
+		SetReadAt(time.Now()).
+		Save(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("mark all notifications read: %w", err)
+	}
+	return n, nil
+}
+
+var mentionRe = regexp.MustCompile(`(?:^|[^\w@])@([A-Za-z0-9_-]{2,64})`)
+
+// ParseMentions извлекает уникальные slug'и упомянутых через @slug пользователей
+func ParseMentions(content string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, m := range mentionRe.FindAllStringSubmatch(content, -1) {
+		slug := m[1]
+		if seen[slug] {
+			continue
+		}
+		seen[slug] = true
+		out = append(out, slug)
+	}
+	return out
+}
+
+func deref(p *int) int {
+	if p == nil {
+		return 0
+	}
+	return *p
+}
@@ -0,0 +1,56 @@
+package notification
+
+import (
+	"testing"
+
+	"stormlink/server/ent/notification"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupKey(t *testing.T) {
+	post, comment := 7, 42
+
+	assert.Equal(t, "post_like:7", GroupKey(Event{Type: notification.TypePostLike, PostID: &post}))
+	assert.Equal(t, "comment_like:42", GroupKey(Event{Type: notification.TypeCommentLike, CommentID: &comment}))
+	assert.Equal(t, "user_follow", GroupKey(Event{Type: notification.TypeUserFollow}))
+	assert.Equal(t, "comment_reply:post:7", GroupKey(Event{Type: notification.TypeCommentReply, PostID: &post, CommentID: &comment}))
+
+	// модерация не группируется
+	a := GroupKey(Event{Type: notification.TypeModeration})
+	b := GroupKey(Event{Type: notification.TypeModeration})
+	assert.NotEqual(t, a, b)
+}
+
+func TestMergeActors(t *testing.T) {
+	display, all, added := mergeActors([]int{1, 2, 3}, []int{3, 2, 1}, 4)
+	assert.True(t, added)
+	assert.Equal(t, []int{4, 1, 2, 3}, display)
+	assert.Equal(t, []int{3, 2, 1, 4}, all)
+
+	// повторное действие того же пользователя не увеличивает счётчик, но поднимает его наверх
+	display, _, added = mergeActors([]int{1, 2, 3}, []int{3, 2, 1}, 2)
+	assert.False(t, added)
+	assert.Equal(t, []int{2, 1, 3}, display)
+
+	display, all, added = mergeActors([]int{5, 4, 3, 2, 1}, []int{1, 2, 3, 4, 5}, 6)
+	assert.True(t, added)
+	assert.Len(t, display, maxActors)
+	assert.Equal(t, 6, display[0])
+
+	// участник, вытесненный из списка для отображения, повторно не считается
+	display, _, added = mergeActors(display, all, 1)
+	assert.False(t, added)
+	assert.Equal(t, []int{1, 6, 5, 4, 3}, display)
+
+	// группы, созданные до появления all_actor_ids
+	_, all, added = mergeActors([]int{2, 1}, nil, 3)
+	assert.True(t, added)
+	assert.Equal(t, []int{2, 1, 3}, all)
+}
+
+func TestParseMentions(t *testing.T) {
+	assert.Equal(t, []string{"alice", "bob_2"}, ParseMentions("@alice привет, @bob_2 и снова @alice"))
+	assert.Empty(t, ParseMentions("почта user@example.com не упоминание"))
+	assert.Empty(t, ParseMentions("@a слишком короткий"))
+}
@@ -0,0 +1,262 @@
+package notificationsettings
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"stormlink/server/ent"
+	"stormlink/server/ent/notificationsettings"
+	"stormlink/server/graphql/models"
+	"stormlink/server/model"
+)
+
+// CategoryAll — отписка от всех писем; остальные категории в токенах отписки совпадают с типами событий
+const CategoryAll = "all"
+
+// LegacyCategories — категории писем до настроек по типам событий: они остались в уже
+// отправленных ссылках отписки и в колонках email_* старых настроек
+var LegacyCategories = map[string][]string{
+	"replies":         {model.NotifyCommentReply},
+	"follows":         {model.NotifyUserFollow},
+	"community_posts": {model.NotifyCommunityPost},
+}
+
+// ErrUnknownCategory — категория отписки не существует (ссылка подделана или устарела)
+var ErrUnknownCategory = errors.New("unknown unsubscribe category")
+
+type NotificationSettingsUsecase interface {
+	GetOrCreate(ctx context.Context, userID int) (*ent.NotificationSettings, error)
+	// Get возвращает настройки без создания; nil — пользователь ещё ничего не настраивал
+	Get(ctx context.Context, userID int) (*ent.NotificationSettings, error)
+	Update(ctx context.Context, userID int, input *models.UpdateNotificationSettingsInput) (*ent.NotificationSettings, error)
+	Mute(ctx context.Context, userID int, input *models.MuteNotificationsInput) (*ent.NotificationSettings, error)
+	Unmute(ctx context.Context, userID int, input *models.MuteNotificationsInput) (*ent.NotificationSettings, error)
+	Unsubscribe(ctx context.Context, userID int, category string) error
+}
+
+type notificationSettingsUsecase struct {
+	client *ent.Client
+}
+
+func NewNotificationSettingsUsecase(client *ent.Client) NotificationSettingsUsecase {
+	return &notificationSettingsUsecase{client: client}
+}
+
+// DigestInterval возвращает период между сводками для выбранной частоты
+func DigestInterval(f notificationsettings.EmailFrequency) time.Duration {
+	switch f {
+	case notificationsettings.EmailFrequencyImmediate:
+		return 5 * time.Minute
+	case notificationsettings.EmailFrequencyWeekly:
+		return 7 * 24 * time.Hour
+	default:
+		return 24 * time.Hour
+	}
+}
+
+func (uc *notificationSettingsUsecase) Get(ctx context.Context, userID int) (*ent.NotificationSettings, error) {
+	s, err := uc.client.NotificationSettings.Query().
+		Where(notificationsettings.UserIDEQ(userID)).
+		Only(ctx)
+	if ent.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification settings: %w", err)
+	}
+	return s, nil
+}
+
+// GetOrCreate возвращает настройки пользователя, создавая их со значениями по умолчанию
+func (uc *notificationSettingsUsecase) GetOrCreate(ctx context.Context, userID int) (*ent.NotificationSettings, error) {
+	s, err := uc.client.NotificationSettings.Query().
+		Where(notificationsettings.UserIDEQ(userID)).
+		Only(ctx)
+	if err == nil {
+		return s, nil
+	}
+	if !ent.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to get notification settings: %w", err)
+	}
+
+	s, err = uc.client.NotificationSettings.Create().
+		SetUserID(userID).
+		SetNextDigestAt(time.Now().Add(DigestInterval(notificationsettings.DefaultEmailFrequency))).
+		Save(ctx)
+	if ent.IsConstraintError(err) {
+		// Параллельный запрос уже создал настройки
+		return uc.client.NotificationSettings.Query().
+			Where(notificationsettings.UserIDEQ(userID)).
+			Only(ctx)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create notification settings: %w", err)
+	}
+	return s, nil
+}
+
+func (uc *notificationSettingsUsecase) Update(ctx context.Context, userID int, input *models.UpdateNotificationSettingsInput) (*ent.NotificationSettings, error) {
+	s, err := uc.GetOrCreate(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	upd := uc.client.NotificationSettings.UpdateOne(s)
+	if input.EmailFrequency != nil && *input.EmailFrequency != s.EmailFrequency {
+		if err := notificationsettings.EmailFrequencyValidator(*input.EmailFrequency); err != nil {
+			return nil, fmt.Errorf("invalid email frequency: %w", err)
+		}
+		// Новый период отсчитываем от текущего момента
+		upd.SetEmailFrequency(*input.EmailFrequency).
+			SetNextDigestAt(time.Now().Add(DigestInterval(*input.EmailFrequency)))
+	}
+	if input.Timezone != nil {
+		if _, err := time.LoadLocation(*input.Timezone); err != nil || *input.Timezone == "" {
+			return nil, fmt.Errorf("invalid timezone: %s", *input.Timezone)
+		}
+		upd.SetTimezone(*input.Timezone)
+	}
+	if input.ClearQuietHours != nil && *input.ClearQuietHours {
+		upd.ClearQuietHoursStart().ClearQuietHoursEnd()
+	} else if input.QuietHours != nil {
+		start, err := ParseClock(input.QuietHours.Start)
+		if err != nil {
+			return nil, err
+		}
+		end, err := ParseClock(input.QuietHours.End)
+		if err != nil {
+			return nil, err
+		}
+		if start == end {
+			return nil, fmt.Errorf("quiet hours start and end must differ")
+		}
+		upd.SetQuietHoursStart(start).SetQuietHoursEnd(end)
+	}
+	if len(input.Preferences) > 0 {
+		prefs := make(model.NotificationPreferences, len(s.Preferences)+len(input.Preferences))
+		for t, c := range s.Preferences {
+			prefs[t] = c
+		}
+		for _, p := range input.Preferences {
+			t := string(p.Type)
+			if !slices.Contains(model.NotificationTypes, t) {
+				return nil, fmt.Errorf("unknown notification type: %s", t)
+			}
+			c := prefs.Get(t)
+			if p.InApp != nil {
+				c.InApp = *p.InApp
+			}
+			if p.Email != nil {
+				c.Email = *p.Email
+			}
+			if p.Digest != nil {
+				c.Digest = *p.Digest
+			}
+			prefs[t] = c
+		}
+		upd.SetPreferences(prefs)
+	}
+
+	s, err = upd.Save(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update notification settings: %w", err)
+	}
+	return s, nil
+}
+
+// Unsubscribe отключает категорию писем (all — все письма) по ссылке из письма
+func (uc *notificationSettingsUsecase) Unsubscribe(ctx context.Context, userID int, category string) error {
+	s, err := uc.GetOrCreate(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	types := []string{category}
+	if category == CategoryAll {
+		types = model.NotificationTypes
+	} else if legacy, ok := LegacyCategories[category]; ok {
+		types = legacy
+	} else if !slices.Contains(model.NotificationTypes, category) {
+		return fmt.Errorf("%w: %s", ErrUnknownCategory, category)
+	}
+
+	// Отписка отключает и мгновенные письма, и включение событий в сводку
+	prefs := make(model.NotificationPreferences, len(model.NotificationTypes))
+	for t, c := range s.Preferences {
+		prefs[t] = c
+	}
+	for _, t := range types {
+		c := prefs.Get(t)
+		c.Email, c.Digest = false, false
+		prefs[t] = c
+	}
+
+	upd := uc.client.NotificationSettings.UpdateOne(s).SetPreferences(prefs)
+	if category == CategoryAll {
+		upd.SetEmailFrequency(notificationsettings.EmailFrequencyOff)
+	}
+
+	if err := upd.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to unsubscribe: %w", err)
+	}
+	return nil
+}
+
+// Mute заглушает уведомления обсуждения (поста) и/или сообщества
+func (uc *notificationSettingsUsecase) Mute(ctx context.Context, userID int, input *models.MuteNotificationsInput) (*ent.NotificationSettings, error) {
+	return uc.updateMuted(ctx, userID, input, func(ids []int, id int) []int {
+		if slices.Contains(ids, id) {
+			return ids
+		}
+		return append(ids, id)
+	})
+}
+
+// Unmute возвращает уведомления обсуждения и/или сообщества
+func (uc *notificationSettingsUsecase) Unmute(ctx context.Context, userID int, input *models.MuteNotificationsInput) (*ent.NotificationSettings, error) {
+	return uc.updateMuted(ctx, userID, input, func(ids []int, id int) []int {
+		return slices.DeleteFunc(ids, func(v int) bool { return v == id })
+	})
+}
+
+func (uc *notificationSettingsUsecase) updateMuted(ctx context.Context, userID int, input *models.MuteNotificationsInput, apply func([]int, int) []int) (*ent.NotificationSettings, error) {
+	if input.PostID == nil && input.CommunityID == nil {
+		return nil, fmt.Errorf("postID or communityID is required")
+	}
+	s, err := uc.GetOrCreate(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	upd := uc.client.NotificationSettings.UpdateOne(s)
+	if input.PostID != nil {
+		pid, err := strconv.Atoi(*input.PostID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid post ID: %w", err)
+		}
+		if _, err := uc.client.Post.Get(ctx, pid); err != nil {
+			return nil, fmt.Errorf("post not found: %w", err)
+		}
+		upd.SetMutedPostIds(apply(slices.Clone(s.MutedPostIds), pid))
+	}
+	if input.CommunityID != nil {
+		cid, err := strconv.Atoi(*input.CommunityID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid community ID: %w", err)
+		}
+		if _, err := uc.client.Community.Get(ctx, cid); err != nil {
+			return nil, fmt.Errorf("community not found: %w", err)
+		}
+		upd.SetMutedCommunityIds(apply(slices.Clone(s.MutedCommunityIds), cid))
+	}
+
+	s, err = upd.Save(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update muted notifications: %w", err)
+	}
+	return s, nil
+}
@@ -0,0 +1,86 @@
+package notificationsettings
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"stormlink/server/ent"
+	"stormlink/server/model"
+)
+
+// Channels возвращает каналы доставки для типа события; s == nil — значения по умолчанию
+func Channels(s *ent.NotificationSettings, t string) model.NotificationChannels {
+	if s == nil {
+		return model.DefaultNotificationChannels[t]
+	}
+	return s.Preferences.Get(t)
+}
+
+// IsMuted сообщает, заглушено ли обсуждение или сообщество, к которому относится событие
+func IsMuted(s *ent.NotificationSettings, postID, communityID *int) bool {
+	if s == nil {
+		return false
+	}
+	if postID != nil && slices.Contains(s.MutedPostIds, *postID) {
+		return true
+	}
+	return communityID != nil && slices.Contains(s.MutedCommunityIds, *communityID)
+}
+
+// Location возвращает часовой пояс пользователя (UTC, если пояс не задан или неизвестен)
+func Location(s *ent.NotificationSettings) *time.Location {
+	if s == nil || s.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// InQuietHours сообщает, попадает ли момент now в тихие часы пользователя
+func InQuietHours(s *ent.NotificationSettings, now time.Time) bool {
+	if s == nil || s.QuietHoursStart == nil || s.QuietHoursEnd == nil {
+		return false
+	}
+	start, end := *s.QuietHoursStart, *s.QuietHoursEnd
+	if start == end {
+		return false
+	}
+	t := now.In(Location(s))
+	m := t.Hour()*60 + t.Minute()
+	if start < end {
+		return m >= start && m < end
+	}
+	// Интервал через полночь, например 22:00–07:00
+	return m >= start || m < end
+}
+
+// QuietHoursEnd возвращает ближайший момент окончания тихих часов после now
+func QuietHoursEnd(s *ent.NotificationSettings, now time.Time) time.Time {
+	if !InQuietHours(s, now) {
+		return now
+	}
+	t := now.In(Location(s))
+	end := time.Date(t.Year(), t.Month(), t.Day(), 0, *s.QuietHoursEnd, 0, 0, t.Location())
+	if !end.After(t) {
+		end = time.Date(t.Year(), t.Month(), t.Day()+1, 0, *s.QuietHoursEnd, 0, 0, t.Location())
+	}
+	return end
+}
+
+// ParseClock разбирает время "HH:MM" в минуты от полуночи
+func ParseClock(v string) (int, error) {
+	t, err := time.Parse("15:04", v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", v)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// FormatClock форматирует минуты от полуночи как "HH:MM"
+func FormatClock(m int) string {
+	return fmt.Sprintf("%02d:%02d", m/60, m%60)
+}
@@ -0,0 +1,83 @@
+package notificationsettings
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"stormlink/server/ent"
+	"stormlink/server/model"
+)
+
+func quiet(start, end int, tz string) *ent.NotificationSettings {
+	return &ent.NotificationSettings{QuietHoursStart: &start, QuietHoursEnd: &end, Timezone: tz}
+}
+
+func TestInQuietHours(t *testing.T) {
+	day := func(h, m int) time.Time { return time.Date(2025, 3, 10, h, m, 0, 0, time.UTC) }
+
+	// 22:00–07:00, интервал через полночь
+	s := quiet(22*60, 7*60, "UTC")
+	assert.True(t, InQuietHours(s, day(23, 0)))
+	assert.True(t, InQuietHours(s, day(3, 30)))
+	assert.False(t, InQuietHours(s, day(7, 0)))
+	assert.False(t, InQuietHours(s, day(12, 0)))
+
+	// 13:00–14:00 в пределах суток
+	s = quiet(13*60, 14*60, "UTC")
+	assert.True(t, InQuietHours(s, day(13, 59)))
+	assert.False(t, InQuietHours(s, day(14, 0)))
+
+	assert.False(t, InQuietHours(nil, day(3, 0)))
+	assert.False(t, InQuietHours(&ent.NotificationSettings{}, day(3, 0)))
+}
+
+func TestInQuietHoursTimezone(t *testing.T) {
+	// 23:00 UTC = 02:00 по Москве
+	s := quiet(0, 6*60, "Europe/Moscow")
+	assert.True(t, InQuietHours(s, time.Date(2025, 3, 10, 23, 0, 0, 0, time.UTC)))
+	assert.False(t, InQuietHours(s, time.Date(2025, 3, 10, 6, 0, 0, 0, time.UTC)))
+}
+
+func TestQuietHoursEnd(t *testing.T) {
+	s := quiet(22*60, 7*60, "UTC")
+
+	end := QuietHoursEnd(s, time.Date(2025, 3, 10, 23, 0, 0, 0, time.UTC))
+	assert.Equal(t, time.Date(2025, 3, 11, 7, 0, 0, 0, time.UTC), end.UTC())
+
+	end = QuietHoursEnd(s, time.Date(2025, 3, 11, 5, 0, 0, 0, time.UTC))
+	assert.Equal(t, time.Date(2025, 3, 11, 7, 0, 0, 0, time.UTC), end.UTC())
+
+	now := time.Date(2025, 3, 11, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, now, QuietHoursEnd(s, now))
+}
+
+func TestChannelsAndMuting(t *testing.T) {
+	assert.Equal(t, model.DefaultNotificationChannels[model.NotifyPostLike], Channels(nil, model.NotifyPostLike))
+
+	s := &ent.NotificationSettings{
+		Preferences:       model.NotificationPreferences{model.NotifyPostLike: {Email: true}},
+		MutedPostIds:      []int{5},
+		MutedCommunityIds: []int{9},
+	}
+	assert.Equal(t, model.NotificationChannels{Email: true}, Channels(s, model.NotifyPostLike))
+	assert.Equal(t, model.DefaultNotificationChannels[model.NotifyMention], Channels(s, model.NotifyMention))
+
+	five, nine, one := 5, 9, 1
+	assert.True(t, IsMuted(s, &five, nil))
+	assert.True(t, IsMuted(s, &one, &nine))
+	assert.False(t, IsMuted(s, &one, &one))
+	assert.False(t, IsMuted(nil, &five, nil))
+}
+
+func TestClock(t *testing.T) {
+	m, err := ParseClock("22:30")
+	require.NoError(t, err)
+	assert.Equal(t, 22*60+30, m)
+	assert.Equal(t, "07:05", FormatClock(7*60+5))
+
+	_, err = ParseClock("25:00")
+	assert.Error(t, err)
+}
@@ -0,0 +1,213 @@
+package pagination
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"entgo.io/ent/dialect/sql"
+
+	"stormlink/server/graphql/models"
+)
+
+const (
+	// DefaultLimit — размер страницы, если не заданы ни first, ни last
+	DefaultLimit = 20
+	// MaxLimit — верхняя граница first/last: запрос большей страницы отклоняется
+	MaxLimit = 100
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ErrLimitExceeded — first/last больше MaxLimit
+var ErrLimitExceeded = fmt.Errorf("first/last must not exceed %d", MaxLimit)
+
+// Cursor — ключ keyset-пагинации: время сортировки и ID как тай-брейкер
+type Cursor struct {
+	Time time.Time
+	ID   int
+}
+
+// Encode кодирует ключ "time|id" в base64
+func Encode(t time.Time, id int) string {
+	key := t.UTC().Format(time.RFC3339Nano) + "|" + strconv.Itoa(id)
+	return base64.StdEncoding.EncodeToString([]byte(key))
+}
+
+// Decode декодирует base64 в (time, id)
+func Decode(cur string) (Cursor, error) {
+	b, err := base64.StdEncoding.DecodeString(cur)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	parts := strings.SplitN(string(b), "|", 2)
+	if len(parts) != 2 {
+		return Cursor{}, ErrInvalidCursor
+	}
+	t, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return Cursor{}, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	id, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return Cursor{}, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	return Cursor{Time: t, ID: id}, nil
+}
+
+// Args — аргументы Relay-соединения
+type Args struct {
+	First  *int
+	After  *string
+	Last   *int
+	Before *string
+	// TotalCount — считать общее число элементов (отдельный COUNT-запрос)
+	TotalCount bool
+}
+
+// Order — порядок выборки по колонке времени и ID
+type Order[T any] struct {
+	Field string
+	Desc  bool
+	Key   func(T) (time.Time, int)
+}
+
+type Edge[T any] struct {
+	Cursor string
+	Node   T
+}
+
+type Page[T any] struct {
+	Edges      []Edge[T]
+	PageInfo   *models.PageInfo
+	TotalCount int
+}
+
+// Query — часть сгенерированного ent-запроса, нужная пагинации
+type Query[Q, T any, P, O ~func(*sql.Selector)] interface {
+	Clone() Q
+	Where(...P) Q
+	Order(...O) Q
+	Limit(int) Q
+	All(context.Context) ([]T, error)
+	Count(context.Context) (int, error)
+}
+
+// Paginate отдает окно выборки q: first после after или last перед before.
+// Узлы всегда идут в порядке order, курсоры стабильны при вставке новых строк.
+func Paginate[Q Query[Q, T, P, O], T any, P, O ~func(*sql.Selector)](ctx context.Context, q Q, args Args, order Order[T]) (*Page[T], error) {
+	limit, backward, err := window(args)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &Page[T]{PageInfo: &models.PageInfo{}}
+	if args.TotalCount {
+		if page.TotalCount, err = q.Clone().Count(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	rows := q.Clone()
+	if args.After != nil && *args.After != "" {
+		cur, err := Decode(*args.After)
+		if err != nil {
+			return nil, err
+		}
+		rows = rows.Where(beyond(order, cur, true))
+	}
+	if args.Before != nil && *args.Before != "" {
+		cur, err := Decode(*args.Before)
+		if err != nil {
+			return nil, err
+		}
+		rows = rows.Where(beyond(order, cur, false))
+	}
+
+	// Назад грузим в обратном порядке, чтобы взять ближайшие к курсору, затем разворачиваем
+	desc := order.Desc != backward
+	items, err := rows.
+		Order(orderBy(order.Field, desc), orderBy("id", desc)).
+		Limit(limit + 1).
+		All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	more := len(items) > limit
+	if more {
+		items = items[:limit]
+	}
+	// С противоположной стороны окна есть как минимум сам курсор
+	if backward {
+		page.PageInfo.HasPreviousPage = more
+		page.PageInfo.HasNextPage = args.Before != nil && *args.Before != ""
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	} else {
+		page.PageInfo.HasNextPage = more
+		page.PageInfo.HasPreviousPage = args.After != nil && *args.After != ""
+	}
+
+	page.Edges = make([]Edge[T], len(items))
+	for i, it := range items {
+		page.Edges[i] = Edge[T]{Cursor: Encode(order.Key(it)), Node: it}
+	}
+	if len(page.Edges) > 0 {
+		s, e := page.Edges[0].Cursor, page.Edges[len(page.Edges)-1].Cursor
+		page.PageInfo.StartCursor, page.PageInfo.EndCursor = &s, &e
+	}
+	return page, nil
+}
+
+// MapEdges превращает ребра страницы в ребра GraphQL-соединения
+func MapEdges[T, E any](p *Page[T], edge func(cursor string, node T) E) []E {
+	out := make([]E, len(p.Edges))
+	for i, e := range p.Edges {
+		out[i] = edge(e.Cursor, e.Node)
+	}
+	return out
+}
+
+// window проверяет first/last и отдает размер страницы и направление
+func window(args Args) (limit int, backward bool, err error) {
+	switch {
+	case args.First != nil:
+		limit = *args.First
+	case args.Last != nil:
+		limit, backward = *args.Last, true
+	default:
+		return DefaultLimit, false, nil
+	}
+	if limit < 0 {
+		return 0, false, fmt.Errorf("first/last must be non-negative")
+	}
+	if limit > MaxLimit {
+		return 0, false, ErrLimitExceeded
+	}
+	return limit, backward, nil
+}
+
+// beyond — строки строго после (after) или строго до курсора в порядке order
+func beyond[T any](order Order[T], cur Cursor, after bool) func(*sql.Selector) {
+	cmp := sql.FieldGT
+	if order.Desc == after {
+		cmp = sql.FieldLT
+	}
+	return sql.OrPredicates(
+		cmp(order.Field, cur.Time),
+		sql.AndPredicates(sql.FieldEQ(order.Field, cur.Time), cmp("id", cur.ID)),
+	)
+}
+
+func orderBy(field string, desc bool) func(*sql.Selector) {
+	opt := sql.OrderAsc()
+	if desc {
+		opt = sql.OrderDesc()
+	}
+	return sql.OrderByField(field, opt).ToFunc()
+}
@@ -0,0 +1,150 @@
+package pagination
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"testing"
+	"time"
+
+	"stormlink/server/ent"
+	"stormlink/server/ent/post"
+	"stormlink/tests/fixtures"
+	"stormlink/tests/testhelper"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var postsOrder = Order[*ent.Post]{
+	Field: post.FieldCreatedAt,
+	Desc:  true,
+	Key:   func(p *ent.Post) (time.Time, int) { return p.CreatedAt, p.ID },
+}
+
+func ids(p *Page[*ent.Post]) []int {
+	return MapEdges(p, func(_ string, n *ent.Post) int { return n.ID })
+}
+
+func intp(v int) *int { return &v }
+
+func TestCursorEncoding(t *testing.T) {
+	t.Run("encode and decode cursor", func(t *testing.T) {
+		testTime := time.Now().UTC()
+		cur, err := Decode(Encode(testTime, 123))
+
+		assert.NoError(t, err)
+		assert.True(t, testTime.Equal(cur.Time))
+		assert.Equal(t, 123, cur.ID)
+	})
+
+	t.Run("invalid cursor formats", func(t *testing.T) {
+		for _, raw := range []string{"invalid", "invalid-time|123", "2023-01-01T00:00:00Z|invalid-id"} {
+			_, err := Decode(base64.StdEncoding.EncodeToString([]byte(raw)))
+			assert.ErrorIs(t, err, ErrInvalidCursor, raw)
+		}
+		_, err := Decode("invalid-base64!")
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
+}
+
+func TestWindow(t *testing.T) {
+	limit, backward, err := window(Args{})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultLimit, limit)
+	assert.False(t, backward)
+
+	limit, backward, err = window(Args{Last: intp(MaxLimit)})
+	require.NoError(t, err)
+	assert.Equal(t, MaxLimit, limit)
+	assert.True(t, backward)
+
+	_, _, err = window(Args{Last: intp(MaxLimit + 1)})
+	assert.ErrorIs(t, err, ErrLimitExceeded)
+
+	_, _, err = window(Args{First: intp(-1)})
+	assert.Error(t, err)
+}
+
+func TestPaginate(t *testing.T) {
+	helper := testhelper.NewPostgresTestHelper(t)
+	defer helper.Cleanup()
+	helper.WaitForDatabase(t)
+	helper.CleanDatabase(t)
+	client := helper.GetClient()
+	ctx := context.Background()
+	require.NoError(t, fixtures.SeedBasicData(ctx, client))
+	cm, err := fixtures.CreateTestCommunity(ctx, client, fixtures.CommunityFixture{
+		Name: "Pagination", Slug: fixtures.RandomSlug(), OwnerID: fixtures.TestUser1.ID, CreatedAt: time.Now(),
+	})
+	require.NoError(t, err)
+
+	// 7 постов, у двух одинаковое время: порядок между ними решает ID
+	base := time.Now().Add(-time.Hour).Truncate(time.Second)
+	var want []int
+	for i := 0; i < 7; i++ {
+		created := base.Add(time.Duration(i) * time.Minute)
+		if i == 4 {
+			created = base.Add(3 * time.Minute)
+		}
+		p, err := fixtures.CreateTestPost(ctx, client, fixtures.PostFixture{
+			Title: fmt.Sprintf("Post %d", i), Content: "content",
+			CommunityID: cm.ID, AuthorID: fixtures.TestUser1.ID, CreatedAt: created,
+		})
+		require.NoError(t, err)
+		// Новые сверху; у совпавших по времени больший ID идет первым
+		want = append([]int{p.ID}, want...)
+	}
+
+	q := client.Post.Query().Where(post.CommunityIDEQ(cm.ID))
+
+	t.Run("forward pages cover all rows once", func(t *testing.T) {
+		var got []int
+		var after *string
+		for i := 0; ; i++ {
+			page, err := Paginate(ctx, q, Args{First: intp(3), After: after, TotalCount: i == 0}, postsOrder)
+			require.NoError(t, err)
+			if i == 0 {
+				assert.Equal(t, 7, page.TotalCount)
+				assert.False(t, page.PageInfo.HasPreviousPage)
+			}
+			got = append(got, ids(page)...)
+			if !page.PageInfo.HasNextPage {
+				break
+			}
+			after = page.PageInfo.EndCursor
+		}
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("backward page before cursor keeps order", func(t *testing.T) {
+		first, err := Paginate(ctx, q, Args{First: intp(5)}, postsOrder)
+		require.NoError(t, err)
+
+		page, err := Paginate(ctx, q, Args{Last: intp(2), Before: first.PageInfo.EndCursor}, postsOrder)
+		require.NoError(t, err)
+		assert.Equal(t, want[2:4], ids(page))
+		assert.True(t, page.PageInfo.HasPreviousPage)
+		assert.True(t, page.PageInfo.HasNextPage)
+
+		page, err = Paginate(ctx, q, Args{Last: intp(5), Before: page.PageInfo.StartCursor}, postsOrder)
+		require.NoError(t, err)
+		assert.Equal(t, want[:2], ids(page))
+		assert.False(t, page.PageInfo.HasPreviousPage)
+	})
+
+	t.Run("total count ignores cursors", func(t *testing.T) {
+		first, err := Paginate(ctx, q, Args{First: intp(2)}, postsOrder)
+		require.NoError(t, err)
+		page, err := Paginate(ctx, q.Clone().Where(post.IDNEQ(want[6])), Args{First: intp(2), After: first.PageInfo.EndCursor, TotalCount: true}, postsOrder)
+		require.NoError(t, err)
+		assert.Equal(t, 6, page.TotalCount)
+		assert.Equal(t, want[2:4], ids(page))
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		bad := "invalid-cursor"
+		_, err := Paginate(ctx, q, Args{First: intp(2), After: &bad}, postsOrder)
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
+}
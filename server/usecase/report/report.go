@@ -0,0 +1,433 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"stormlink/server/ent"
+	"stormlink/server/ent/comment"
+	"stormlink/server/ent/moderationaction"
+	"stormlink/server/ent/post"
+	"stormlink/server/ent/report"
+	"stormlink/server/graphql/models"
+	"stormlink/server/modlog"
+	"stormlink/server/usecase/pagination"
+)
+
+var (
+	ErrSelfReport = errors.New("cannot report yourself")
+	ErrClosed     = errors.New("report is already closed")
+)
+
+// ReportUsecase — жалобы пользователей и очереди их разбора. Права проверяет вызывающий.
+type ReportUsecase interface {
+	// Create подает жалобу и направляет ее в очередь. Повторная жалоба того же пользователя на объект
+	// с открытой жалобой возвращает прежнюю; жалоба другого пользователя становится дубликатом основной.
+	Create(ctx context.Context, reporterID int, input models.CreateReportInput) (*ent.Report, error)
+	// CreateAutomod подает жалобу от автомодерации (без автора) по сработавшему правилу. Жалоба правила
+	// сообщества идет в очередь сообщества, правила платформы — в очередь платформы.
+	CreateAutomod(ctx context.Context, rule *ent.AutomodRule, targetType report.TargetType, targetID int, details string) (*ent.Report, error)
+	// Get отдает жалобу, а для дубликата — основную жалобу, которую и разбирают модераторы
+	Get(ctx context.Context, id int) (*ent.Report, error)
+	// Queue — основные жалобы очереди сообщества, старые первыми; communityID == nil — очередь платформы
+	Queue(ctx context.Context, communityID *int, status report.Status, first int, after *string) (*models.ReportsConnection, error)
+	// Close закрывает открытую основную жалобу вместе с дубликатами и отдает все закрытые жалобы.
+	// Отклоненная жалоба выпускает задержанный автомодерацией пост или комментарий.
+	Close(ctx context.Context, id, moderatorID int, status report.Status, note *string) ([]*ent.Report, error)
+	// Escalate переносит открытую жалобу сообщества вместе с дубликатами в очередь платформы
+	Escalate(ctx context.Context, id, moderatorID int, note *string) (*ent.Report, error)
+}
+
+type reportUsecase struct {
+	client *ent.Client
+}
+
+func NewReportUsecase(client *ent.Client) ReportUsecase {
+	return &reportUsecase{client: client}
+}
+
+// target — объект жалобы: где он находится и кого коснутся меры
+type target struct {
+	userID      *int
+	communityID *int
+}
+
+func (uc *reportUsecase) Create(ctx context.Context, reporterID int, input models.CreateReportInput) (*ent.Report, error) {
+	targetID, err := strconv.Atoi(input.TargetID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid targetID: %w", err)
+	}
+	t, err := uc.target(ctx, input.TargetType, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if t.userID != nil && *t.userID == reporterID {
+		return nil, ErrSelfReport
+	}
+	communityRuleID, hostRuleID, err := uc.rule(ctx, input, t)
+	if err != nil {
+		return nil, err
+	}
+
+	// Пользователи, сообщества и нарушения правил платформы разбирает платформа
+	queue := report.QueueCommunity
+	if hostRuleID != nil || t.communityID == nil || input.TargetType == report.TargetTypeCommunity {
+		queue = report.QueueHost
+	}
+
+	var out *ent.Report
+	err = modlog.InTx(ctx, uc.client, func(tx *ent.Tx) error {
+		open := tx.Report.Query().Where(
+			report.TargetTypeEQ(input.TargetType),
+			report.TargetIDEQ(targetID),
+			report.StatusEQ(report.StatusOpen),
+		)
+		existing, err := open.Clone().Where(report.ReporterIDEQ(reporterID)).First(ctx)
+		if err == nil {
+			out = existing
+			return nil
+		}
+		if !ent.IsNotFound(err) {
+			return fmt.Errorf("find duplicate report: %w", err)
+		}
+		primary, err := open.Where(report.QueueEQ(queue), report.DuplicateOfIDIsNil()).First(ctx)
+		if err != nil && !ent.IsNotFound(err) {
+			return fmt.Errorf("find primary report: %w", err)
+		}
+
+		create := tx.Report.Create().
+			SetReporterID(reporterID).
+			SetTargetType(input.TargetType).
+			SetTargetID(targetID).
+			SetNillableTargetUserID(t.userID).
+			SetNillableCommunityID(t.communityID).
+			SetQueue(queue).
+			SetReason(input.Reason).
+			SetNillableCommunityRuleID(communityRuleID).
+			SetNillableHostRuleID(hostRuleID).
+			SetNillableDetails(input.Details)
+		if primary != nil {
+			create = create.SetDuplicateOfID(primary.ID)
+		}
+		out, err = create.Save(ctx)
+		if err != nil {
+			return fmt.Errorf("create report: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (uc *reportUsecase) CreateAutomod(ctx context.Context, rule *ent.AutomodRule, targetType report.TargetType, targetID int, details string) (*ent.Report, error) {
+	t, err := uc.target(ctx, targetType, targetID)
+	if err != nil {
+		return nil, err
+	}
+	queue := report.QueueCommunity
+	if rule.CommunityID == nil || t.communityID == nil {
+		queue = report.QueueHost
+	}
+
+	var out *ent.Report
+	err = modlog.InTx(ctx, uc.client, func(tx *ent.Tx) error {
+		open := tx.Report.Query().Where(
+			report.TargetTypeEQ(targetType),
+			report.TargetIDEQ(targetID),
+			report.StatusEQ(report.StatusOpen),
+		)
+		existing, err := open.Clone().Where(report.AutomodRuleIDEQ(rule.ID)).First(ctx)
+		if err == nil {
+			out = existing
+			return nil
+		}
+		if !ent.IsNotFound(err) {
+			return fmt.Errorf("find duplicate report: %w", err)
+		}
+		primary, err := open.Where(report.QueueEQ(queue), report.DuplicateOfIDIsNil()).First(ctx)
+		if err != nil && !ent.IsNotFound(err) {
+			return fmt.Errorf("find primary report: %w", err)
+		}
+
+		create := tx.Report.Create().
+			SetTargetType(targetType).
+			SetTargetID(targetID).
+			SetNillableTargetUserID(t.userID).
+			SetNillableCommunityID(t.communityID).
+			SetQueue(queue).
+			SetReason(report.ReasonAutomod).
+			SetAutomodRuleID(rule.ID).
+			SetDetails(details)
+		if primary != nil {
+			create = create.SetDuplicateOfID(primary.ID)
+		}
+		if out, err = create.Save(ctx); err != nil {
+			return fmt.Errorf("create automod report: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// target находит объект жалобы
+func (uc *reportUsecase) target(ctx context.Context, t report.TargetType, id int) (target, error) {
+	switch t {
+	case report.TargetTypePost:
+		p, err := uc.client.Post.Get(ctx, id)
+		if err != nil {
+			return target{}, fmt.Errorf("post not found: %w", err)
+		}
+		return target{userID: &p.AuthorID, communityID: &p.CommunityID}, nil
+	case report.TargetTypeComment:
+		c, err := uc.client.Comment.Get(ctx, id)
+		if err != nil {
+			return target{}, fmt.Errorf("comment not found: %w", err)
+		}
+		return target{userID: &c.AuthorID, communityID: &c.CommunityID}, nil
+	case report.TargetTypeUser:
+		u, err := uc.client.User.Get(ctx, id)
+		if err != nil {
+			return target{}, fmt.Errorf("user not found: %w", err)
+		}
+		return target{userID: &u.ID}, nil
+	case report.TargetTypeCommunity:
+		c, err := uc.client.Community.Get(ctx, id)
+		if err != nil {
+			return target{}, fmt.Errorf("community not found: %w", err)
+		}
+		return target{communityID: &c.ID}, nil
+	}
+	return target{}, fmt.Errorf("unknown target type %q", t)
+}
+
+// rule проверяет нарушенное правило: оно указывается только с причиной rule, ровно одно,
+// и правило сообщества должно принадлежать сообществу объекта
+func (uc *reportUsecase) rule(ctx context.Context, input models.CreateReportInput, t target) (communityRuleID, hostRuleID *int, err error) {
+	given := input.CommunityRuleID != nil || input.HostRuleID != nil
+	if input.Reason != report.ReasonRule {
+		if given {
+			return nil, nil, fmt.Errorf("rule can be set only with reason %q", report.ReasonRule)
+		}
+		return nil, nil, nil
+	}
+	if input.CommunityRuleID != nil && input.HostRuleID != nil || !given {
+		return nil, nil, fmt.Errorf("reason %q requires exactly one of communityRuleID and hostRuleID", report.ReasonRule)
+	}
+
+	if input.HostRuleID != nil {
+		id, err := strconv.Atoi(*input.HostRuleID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid hostRuleID: %w", err)
+		}
+		if _, err := uc.client.HostRule.Get(ctx, id); err != nil {
+			return nil, nil, fmt.Errorf("host rule not found: %w", err)
+		}
+		return nil, &id, nil
+	}
+
+	id, err := strconv.Atoi(*input.CommunityRuleID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid communityRuleID: %w", err)
+	}
+	rule, err := uc.client.CommunityRule.Get(ctx, id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("community rule not found: %w", err)
+	}
+	if t.communityID == nil || rule.CommunityID == nil || *rule.CommunityID != *t.communityID {
+		return nil, nil, fmt.Errorf("community rule %d does not belong to the reported object's community", id)
+	}
+	return &id, nil, nil
+}
+
+func (uc *reportUsecase) Get(ctx context.Context, id int) (*ent.Report, error) {
+	r, err := uc.client.Report.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if r.DuplicateOfID != nil {
+		return uc.client.Report.Get(ctx, *r.DuplicateOfID)
+	}
+	return r, nil
+}
+
+func (uc *reportUsecase) Queue(ctx context.Context, communityID *int, status report.Status, first int, after *string) (*models.ReportsConnection, error) {
+	if first <= 0 {
+		first = pagination.DefaultLimit
+	}
+	base := uc.client.Report.Query().Where(report.StatusEQ(status), report.DuplicateOfIDIsNil())
+	if communityID != nil {
+		base = base.Where(report.QueueEQ(report.QueueCommunity), report.CommunityIDEQ(*communityID))
+	} else {
+		base = base.Where(report.QueueEQ(report.QueueHost))
+	}
+	page, err := pagination.Paginate(ctx, base, pagination.Args{First: &first, After: after}, pagination.Order[*ent.Report]{
+		Field: report.FieldCreatedAt,
+		Key:   func(r *ent.Report) (time.Time, int) { return r.CreatedAt, r.ID },
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list reports: %w", err)
+	}
+	edges := pagination.MapEdges(page, func(cur string, r *ent.Report) *models.ReportEdge {
+		return &models.ReportEdge{Cursor: cur, Node: r}
+	})
+	return &models.ReportsConnection{Edges: edges, PageInfo: page.PageInfo}, nil
+}
+
+func (uc *reportUsecase) Close(ctx context.Context, id, moderatorID int, status report.Status, note *string) ([]*ent.Report, error) {
+	if status == report.StatusOpen {
+		return nil, fmt.Errorf("invalid status %q", status)
+	}
+	before, err := uc.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if before.Status != report.StatusOpen {
+		return nil, ErrClosed
+	}
+
+	var closed []*ent.Report
+	err = modlog.InTx(ctx, uc.client, func(tx *ent.Tx) error {
+		if _, err := tx.Report.Update().
+			Where(
+				report.Or(report.IDEQ(before.ID), report.DuplicateOfIDEQ(before.ID)),
+				report.StatusEQ(report.StatusOpen),
+			).
+			SetStatus(status).
+			SetResolvedByID(moderatorID).
+			SetNillableResolutionNote(note).
+			SetResolvedAt(time.Now()).
+			Save(ctx); err != nil {
+			return fmt.Errorf("close reports: %w", err)
+		}
+		if status == report.StatusDismissed {
+			if err := release(ctx, tx, before); err != nil {
+				return err
+			}
+		}
+		closed, err = tx.Report.Query().
+			Where(report.Or(report.IDEQ(before.ID), report.DuplicateOfIDEQ(before.ID))).
+			Order(ent.Asc(report.FieldID)).
+			All(ctx)
+		if err != nil {
+			return fmt.Errorf("get closed reports: %w", err)
+		}
+
+		t := moderationaction.TypeReportResolved
+		if status == report.StatusDismissed {
+			t = moderationaction.TypeReportDismissed
+		}
+		for _, r := range closed {
+			if r.ID == before.ID {
+				return modlog.Record(ctx, tx.ModerationAction, logEntry(t, moderatorID, before, r))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return closed, nil
+}
+
+func (uc *reportUsecase) Escalate(ctx context.Context, id, moderatorID int, note *string) (*ent.Report, error) {
+	before, err := uc.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if before.Status != report.StatusOpen {
+		return nil, ErrClosed
+	}
+	if before.Queue != report.QueueCommunity {
+		return nil, fmt.Errorf("report %d is already in the host queue", before.ID)
+	}
+
+	var out *ent.Report
+	err = modlog.InTx(ctx, uc.client, func(tx *ent.Tx) error {
+		group := report.Or(report.IDEQ(before.ID), report.DuplicateOfIDEQ(before.ID))
+		update := tx.Report.Update().
+			Where(group, report.StatusEQ(report.StatusOpen)).
+			SetQueue(report.QueueHost).
+			SetEscalatedAt(time.Now()).
+			SetNillableEscalationNote(note)
+
+		// Если на объект уже есть открытая жалоба платформы, эскалированные становятся ее дубликатами
+		primary, err := tx.Report.Query().
+			Where(
+				report.TargetTypeEQ(before.TargetType),
+				report.TargetIDEQ(before.TargetID),
+				report.StatusEQ(report.StatusOpen),
+				report.QueueEQ(report.QueueHost),
+				report.DuplicateOfIDIsNil(),
+			).
+			First(ctx)
+		switch {
+		case err == nil:
+			update = update.SetDuplicateOfID(primary.ID)
+		case !ent.IsNotFound(err):
+			return fmt.Errorf("find host report: %w", err)
+		}
+		if _, err := update.Save(ctx); err != nil {
+			return fmt.Errorf("escalate reports: %w", err)
+		}
+
+		if out, err = tx.Report.Get(ctx, before.ID); err != nil {
+			return fmt.Errorf("get escalated report: %w", err)
+		}
+		return modlog.Record(ctx, tx.ModerationAction, logEntry(moderationaction.TypeReportEscalated, moderatorID, before, out))
+	})
+	if err != nil {
+		return nil, err
+	}
+	if out.DuplicateOfID != nil {
+		return uc.client.Report.Get(ctx, *out.DuplicateOfID)
+	}
+	return out, nil
+}
+
+// release публикует задержанный автомодерацией объект жалобы: нарушения не нашлось
+func release(ctx context.Context, tx *ent.Tx, rep *ent.Report) error {
+	switch rep.TargetType {
+	case report.TargetTypePost:
+		p, err := tx.Post.Get(ctx, rep.TargetID)
+		if err != nil || p.Visibility != post.VisibilityHeld {
+			return err
+		}
+		update := tx.Post.UpdateOne(p).SetVisibility(post.VisibilityPublished)
+		if p.PublishedAt == nil {
+			update = update.SetPublishedAt(time.Now())
+		}
+		if err := update.Exec(ctx); err != nil {
+			return fmt.Errorf("release post: %w", err)
+		}
+	case report.TargetTypeComment:
+		if err := tx.Comment.Update().
+			Where(comment.IDEQ(rep.TargetID), comment.HeldEQ(true)).
+			SetHeld(false).
+			Exec(ctx); err != nil {
+			return fmt.Errorf("release comment: %w", err)
+		}
+	}
+	return nil
+}
+
+// logEntry — запись журнала модерации о разборе жалобы
+func logEntry(t moderationaction.Type, moderatorID int, before, after *ent.Report) modlog.Entry {
+	return modlog.Entry{
+		Type:         t,
+		ModeratorID:  &moderatorID,
+		CommunityID:  before.CommunityID,
+		TargetType:   moderationaction.TargetTypeReport,
+		TargetID:     before.ID,
+		TargetUserID: before.TargetUserID,
+		Before:       before,
+		After:        after,
+	}
+}
@@ -2,6 +2,7 @@ package user
 
 import (
 	"context"
+	"stormlink/server/authz"
 	"stormlink/server/ent"
 	"stormlink/server/ent/user"
 	"stormlink/server/graphql/models"
@@ -16,10 +17,11 @@ type UserUsecase interface {
 
 type userUsecase struct {
 	client *ent.Client
+	authz  *authz.Authorizer
 }
 
 func NewUserUsecase(client *ent.Client) UserUsecase {
-	return &userUsecase{client: client}
+	return &userUsecase{client: client, authz: authz.New(authz.NewEntStore(client))}
 }
 
 func (uc *userUsecase) GetUserByID(ctx context.Context, id int) (*ent.User, error) {
@@ -9,6 +9,7 @@ import (
 	"stormlink/server/ent/user"
 	"stormlink/server/ent/userfollow"
 	"stormlink/server/graphql/models"
+	"time"
 )
 
 func (uc *userUsecase) GetUserStatus(
@@ -60,6 +61,7 @@ func (uc *userUsecase) GetUserStatus(
         Query().
         Where(
             hostuserban.HasUserWith(user.IDEQ(userID)),
+            hostuserban.Or(hostuserban.ExpiresAtIsNil(), hostuserban.ExpiresAtGT(time.Now())),
         ).
         Exist(ctx)
     if err != nil {
@@ -71,6 +73,7 @@ func (uc *userUsecase) GetUserStatus(
         Query().
         Where(
             hostusermute.HasUserWith(user.IDEQ(userID)),
+            hostusermute.Or(hostusermute.ExpiresAtIsNil(), hostusermute.ExpiresAtGT(time.Now())),
         ).
         Exist(ctx)
     if err != nil {
@@ -0,0 +1,116 @@
+package usersession
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"stormlink/server/ent"
+	"stormlink/server/ent/user"
+	"stormlink/server/ent/usersession"
+	"stormlink/server/graphql/models"
+)
+
+// Retention — сколько хранятся входы пользователя
+const Retention = 90 * 24 * time.Hour
+
+// maxSessions — сколько чужих входов с общих адресов разбирается за раз: на адресе
+// публичной сети их может быть очень много
+const maxSessions = 1000
+
+// UserSessionUsecase — учет входов пользователей по IP для поиска связанных аккаунтов
+// (мультиаккаунтов, обходящих бан). Права проверяет вызывающий.
+type UserSessionUsecase interface {
+	// Record запоминает вход пользователя с адреса ip и удаляет его входы старше Retention
+	Record(ctx context.Context, userID int, ip, userAgent string) error
+	// AssociatedAccounts — другие аккаунты, входившие с адресов пользователя начиная с since,
+	// входившие позже первыми
+	AssociatedAccounts(ctx context.Context, userID int, since time.Time) ([]*models.AssociatedAccount, error)
+}
+
+type userSessionUsecase struct {
+	client *ent.Client
+}
+
+func NewUserSessionUsecase(client *ent.Client) UserSessionUsecase {
+	return &userSessionUsecase{client: client}
+}
+
+func (uc *userSessionUsecase) Record(ctx context.Context, userID int, ip, userAgent string) error {
+	if ip == "" {
+		return nil
+	}
+	if _, err := uc.client.UserSession.Create().
+		SetUserID(userID).
+		SetIP(ip).
+		SetUserAgent(userAgent).
+		Save(ctx); err != nil {
+		return fmt.Errorf("failed to record session: %w", err)
+	}
+	if _, err := uc.client.UserSession.Delete().
+		Where(usersession.UserIDEQ(userID), usersession.CreatedAtLT(time.Now().Add(-Retention))).
+		Exec(ctx); err != nil {
+		return fmt.Errorf("failed to prune sessions: %w", err)
+	}
+	return nil
+}
+
+func (uc *userSessionUsecase) AssociatedAccounts(ctx context.Context, userID int, since time.Time) ([]*models.AssociatedAccount, error) {
+	ips, err := uc.client.UserSession.Query().
+		Where(usersession.UserIDEQ(userID), usersession.CreatedAtGTE(since)).
+		Unique(true).
+		Select(usersession.FieldIP).
+		Strings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user ips: %w", err)
+	}
+	if len(ips) == 0 {
+		return []*models.AssociatedAccount{}, nil
+	}
+
+	sessions, err := uc.client.UserSession.Query().
+		Where(
+			usersession.IPIn(ips...),
+			usersession.UserIDNEQ(userID),
+			usersession.CreatedAtGTE(since),
+		).
+		Order(ent.Desc(usersession.FieldCreatedAt), ent.Desc(usersession.FieldID)).
+		Limit(maxSessions).
+		All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get associated sessions: %w", err)
+	}
+
+	// Входы идут от новых к старым: первый вход аккаунта — последний по времени
+	var (
+		order    []int
+		accounts = map[int]*models.AssociatedAccount{}
+	)
+	for _, s := range sessions {
+		a, ok := accounts[s.UserID]
+		if !ok {
+			a = &models.AssociatedAccount{Ips: []string{}, LastSeenAt: s.CreatedAt}
+			accounts[s.UserID] = a
+			order = append(order, s.UserID)
+		}
+		if !slices.Contains(a.Ips, s.IP) {
+			a.Ips = append(a.Ips, s.IP)
+		}
+	}
+
+	users, err := uc.client.User.Query().Where(user.IDIn(order...)).All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get associated users: %w", err)
+	}
+	for _, u := range users {
+		accounts[u.ID].User = u
+	}
+	out := make([]*models.AssociatedAccount, 0, len(order))
+	for _, id := range order {
+		if a := accounts[id]; a.User != nil {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
@@ -17,6 +17,7 @@ import (
 	useruc "stormlink/server/usecase/user"
 	errorsx "stormlink/shared/errors"
 	"stormlink/shared/jwt"
+	"stormlink/shared/outbox"
 	"stormlink/shared/rabbitmq"
 )
 
@@ -43,7 +44,13 @@ func (s *UserService) RegisterUser(ctx context.Context, req *userpb.RegisterUser
     if err != nil { return nil, fmt.Errorf("error hashing password: %v", err) }
     passwordHash := base64.StdEncoding.EncodeToString(rawHash)
 
-    newUser, err := s.client.User.Create().
+    // Пользователь, роли, запись верификации и задача на письмо сохраняются атомарно:
+    // письмо отправит outbox relay только после фиксации транзакции
+    tx, err := s.client.Tx(ctx)
+    if err != nil { return nil, errorsx.FromGRPCCode(codes.Internal, "failed to start transaction", err) }
+    defer func() { _ = tx.Rollback() }()
+
+    newUser, err := tx.User.Create().
         SetName(req.GetName()).
         SetSlug(req.GetName()).
         SetEmail(req.GetEmail()).
@@ -53,37 +60,49 @@ func (s *UserService) RegisterUser(ctx context.Context, req *userpb.RegisterUser
         Save(ctx)
     if err != nil { return nil, errorsx.FromGRPCCode(codes.Internal, "error creating user", err) }
 
-    hostFirst, err := s.client.Host.Query().Where(enth.IDEQ(1)).Only(ctx)
+    hostFirst, err := tx.Host.Query().Where(enth.IDEQ(1)).Only(ctx)
     if err != nil { return nil, errorsx.FromGRPCCode(codes.Internal, "failed to get host", err) }
     if hostFirst.FirstSettings {
-        if err := s.client.Host.UpdateOne(hostFirst).SetOwnerID(newUser.ID).SetFirstSettings(false).Exec(ctx); err != nil {
+        if err := tx.Host.UpdateOne(hostFirst).SetOwnerID(newUser.ID).SetFirstSettings(false).Exec(ctx); err != nil {
             return nil, errorsx.FromGRPCCode(codes.Internal, "failed to update host owner and first_settings", err)
         }
-        ownerRole, err := s.client.HostRole.Query().Where(enthr.TitleEQ("owner")).Only(ctx)
+        ownerRole, err := tx.HostRole.Query().Where(enthr.TitleEQ("owner")).Only(ctx)
         if err != nil { return nil, errorsx.FromGRPCCode(codes.Internal, "failed to find host owner role", err) }
-        if err := s.client.User.UpdateOne(newUser).AddHostRoles(ownerRole).Exec(ctx); err != nil {
+        if err := tx.User.UpdateOne(newUser).AddHostRoles(ownerRole).Exec(ctx); err != nil {
             return nil, errorsx.FromGRPCCode(codes.Internal, "failed to assign host owner role", err)
         }
     }
 
-    everyone, err := s.client.HostRole.Query().Where(enthr.TitleEQ("@everyone")).Only(ctx)
+    everyone, err := tx.HostRole.Query().Where(enthr.TitleEQ("@everyone")).Only(ctx)
     if err != nil { return nil, errorsx.FromGRPCCode(codes.Internal, "failed to find @everyone role", err) }
-    if err := s.client.User.UpdateOne(newUser).AddHostRoles(everyone).Exec(ctx); err != nil {
+    if err := tx.User.UpdateOne(newUser).AddHostRoles(everyone).Exec(ctx); err != nil {
         return nil, errorsx.FromGRPCCode(codes.Internal, "failed to assign @everyone role", err)
     }
 
+    // Настройки уведомлений по умолчанию: ежедневная сводка, первая — через сутки
+    if err := tx.NotificationSettings.Create().
+        SetUserID(newUser.ID).
+        SetNextDigestAt(time.Now().Add(24 * time.Hour)).
+        Exec(ctx); err != nil {
+        return nil, errorsx.FromGRPCCode(codes.Internal, "failed to create notification settings", err)
+    }
+
     token, err := jwt.GenerateToken(16)
     if err != nil { return nil, errorsx.FromGRPCCode(codes.Internal, "failed to generate verification token", err) }
     expiresAt := time.Now().Add(24 * time.Hour)
-    if _, err := s.client.EmailVerification.Create().SetToken(token).SetExpiresAt(expiresAt).SetUser(newUser).Save(ctx); err != nil {
+    if _, err := tx.EmailVerification.Create().SetToken(token).SetExpiresAt(expiresAt).SetUser(newUser).Save(ctx); err != nil {
         return nil, errorsx.FromGRPCCode(codes.Internal, "failed to create email verification record", err)
     }
 
     job := rabbitmq.EmailJob{To: newUser.Email, Token: token}
-    if err := rabbitmq.PublishEmailJob(job); err != nil {
+    if err := outbox.Add(ctx, tx.Outbox, rabbitmq.EmailVerificationQueue, job); err != nil {
         return nil, errorsx.FromGRPCCode(codes.Internal, "Не удалось поставить задачу на отправку письма", err)
     }
 
+    if err := tx.Commit(); err != nil {
+        return nil, errorsx.FromGRPCCode(codes.Internal, "failed to commit registration", err)
+    }
+
     return &userpb.RegisterUserResponse{UserId: fmt.Sprint(newUser.ID), Message: "User registered successfully. Please check your email to verify your account."}, nil
 }
 
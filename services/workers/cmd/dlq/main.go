@@ -0,0 +1,78 @@
+// Утилита для просмотра и повторной отправки сообщений из dead-letter очередей.
+//
+//	go run ./services/workers/cmd/dlq -job email_verification list
+//	go run ./services/workers/cmd/dlq -job email_verification -limit 10 replay
+//	go run ./services/workers/cmd/dlq -job email_verification purge
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	"stormlink/server/cmd/modules"
+	"stormlink/shared/jobs"
+)
+
+func main() {
+    modules.InitEnv()
+
+    job := flag.String("job", "email_verification", "job name")
+    limit := flag.Int("limit", 20, "max messages to list/replay (0 = all)")
+    flag.Usage = func() {
+        fmt.Fprintf(flag.CommandLine.Output(), "usage: dlq [-job name] [-limit n] list|replay|purge\n")
+        flag.PrintDefaults()
+    }
+    flag.Parse()
+    if flag.NArg() != 1 {
+        flag.Usage()
+        os.Exit(2)
+    }
+
+    rabbitURL := os.Getenv("RABBITMQ_URL")
+    if rabbitURL == "" {
+        log.Fatal("RABBITMQ_URL is empty")
+    }
+    conn, err := amqp.Dial(rabbitURL)
+    if err != nil {
+        log.Fatalf("rabbitmq dial error: %v", err)
+    }
+    defer conn.Close()
+    ch, err := conn.Channel()
+    if err != nil {
+        log.Fatalf("rabbitmq channel error: %v", err)
+    }
+    defer ch.Close()
+
+    switch flag.Arg(0) {
+    case "list":
+        items, err := jobs.InspectDeadLetters(ch, *job, *limit)
+        if err != nil {
+            log.Fatalf("list: %v", err)
+        }
+        for _, it := range items {
+            fmt.Printf("%s\tattempts=%d\tfailed_at=%s\terror=%q\n\t%s\n",
+                it.MessageID, it.Attempts, it.FailedAt.Format("2006-01-02 15:04:05"), it.LastError, it.Body)
+        }
+        fmt.Printf("%d message(s) in %s\n", len(items), jobs.DeadLetterQueueName(*job))
+    case "replay":
+        n, err := jobs.ReplayDeadLetters(context.Background(), ch, *job, *limit)
+        if err != nil {
+            log.Fatalf("replay: %v (replayed %d)", err, n)
+        }
+        fmt.Printf("✅ replayed %d message(s) to %s\n", n, jobs.QueueName(*job))
+    case "purge":
+        n, err := jobs.PurgeDeadLetters(ch, *job)
+        if err != nil {
+            log.Fatalf("purge: %v", err)
+        }
+        fmt.Printf("🗑 purged %d message(s) from %s\n", n, jobs.DeadLetterQueueName(*job))
+    default:
+        flag.Usage()
+        os.Exit(2)
+    }
+}
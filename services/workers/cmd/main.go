@@ -5,17 +5,27 @@ import (
 	"flag"
 	"log"
 	"net/http"
+	"os"
 	"os/signal"
 	"syscall"
 
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	"stormlink/server/authz"
 	"stormlink/server/cmd/modules"
+	"stormlink/server/shadow"
+	"stormlink/services/workers/internal/digest"
 	mailworker "stormlink/services/workers/internal/mail"
+	"stormlink/services/workers/internal/outbox"
+	"stormlink/services/workers/internal/sanctions"
+	"stormlink/shared/jobs"
+	"stormlink/shared/rabbitmq"
 )
 
 func main() {
     modules.InitEnv()
 
-    mail := flag.Bool("mail", false, "run mail worker only")
+    mail := flag.Bool("mail", false, "run mail worker and outbox relay only")
     healthAddr := flag.String("health-addr", ":8090", "http health endpoint addr")
     flag.Parse()
 
@@ -35,22 +45,54 @@ func main() {
         }
     }()
 
-    if *mail {
-        log.Println("📬 starting mail worker...")
-        if err := mailworker.Run(ctx); err != nil {
-            log.Fatalf("mail worker error: %v", err)
-        }
-        _ = srv.Shutdown(context.Background())
-        return
+    rabbitURL := os.Getenv("RABBITMQ_URL")
+    if rabbitURL == "" {
+        log.Fatal("RABBITMQ_URL is empty")
+    }
+    conn, err := amqp.Dial(rabbitURL)
+    if err != nil {
+        log.Fatalf("rabbitmq dial error: %v", err)
     }
+    defer conn.Close()
 
-    // Запуск всех доступных воркеров (пока почтовый)
-    log.Println("🛠 starting all workers (mail)...")
-    done := make(chan error, 1)
-    go func() { done <- mailworker.Run(ctx) }()
+    client := modules.ConnectDB()
+    defer client.Close()
+    // Теневой контент не должен попадать в письма тем, кто не видит его на сайте
+    shadow.Register(client, shadow.Moderators(client, authz.New(authz.NewEntStore(client))))
+
+    // Реестр задач: каждый воркер регистрирует свои обработчики
+    reg := jobs.NewRegistry()
+    mailworker.Register(reg)
+    digest.Register(reg, client)
+
+    done := make(chan error, 5)
+    running := 0
+    start := func(run func(context.Context) error) {
+        running++
+        go func() { done <- run(ctx) }()
+    }
+    start(jobs.NewWorker(conn, reg).Run)
+    // Outbox relay нужен и в режиме -mail: письма ставятся в очередь через outbox,
+    // без него почтовому воркеру нечего обрабатывать
+    pub := rabbitmq.NewConnection(rabbitURL)
+    defer pub.Close()
+    start(outbox.NewRelay(client, pub).Run)
+
+    if *mail {
+        log.Println("📬 starting mail worker and outbox relay...")
+    } else {
+        log.Println("🛠 starting all workers (mail, outbox relay, digest scheduler, sanction expirer)...")
+        // Планировщик email-сводок
+        start(digest.NewScheduler(client).Run)
+        // Снятие банов и мутов с истекшим сроком
+        start(sanctions.NewExpirer(client).Run)
+    }
 
     select {
     case <-ctx.Done():
+        for ; running > 0; running-- {
+            <-done
+        }
         log.Println("👋 workers shutdown")
     case err := <-done:
         if err != nil {
@@ -59,5 +101,3 @@ func main() {
     }
     _ = srv.Shutdown(context.Background())
 }
-
-
@@ -0,0 +1,141 @@
+package digest
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"stormlink/server/ent"
+	"stormlink/server/ent/comment"
+	"stormlink/server/ent/community"
+	"stormlink/server/ent/communityfollow"
+	"stormlink/server/ent/post"
+	"stormlink/server/ent/userfollow"
+	"stormlink/server/model"
+	"stormlink/shared/auth"
+)
+
+// maxItems — сколько событий каждой категории показываем в письме (остальные — только счётчиком)
+const maxItems = 10
+
+type ReplyItem struct {
+    Author    string
+    PostTitle string
+    Excerpt   string
+    URL       string
+}
+
+type FollowerItem struct {
+    Name string
+    URL  string
+}
+
+type PostItem struct {
+    Title     string
+    Community string
+    URL       string
+}
+
+// Digest — события пользователя за окно сводки
+type Digest struct {
+    Replies        []ReplyItem
+    RepliesTotal   int
+    Followers      []FollowerItem
+    FollowersTotal int
+    Posts          []PostItem
+    PostsTotal     int
+}
+
+func (d *Digest) Empty() bool {
+    return d.RepliesTotal == 0 && d.FollowersTotal == 0 && d.PostsTotal == 0
+}
+
+// Collect агрегирует события за (since, until] с учётом канала digest в настройках и заглушенных обсуждений.
+// Запросы идут от имени получателя: теневой контент в сводку попадает так же, как на сайт
+func Collect(ctx context.Context, client *ent.Client, settings *ent.NotificationSettings, since, until time.Time, publicURL string) (*Digest, error) {
+    uid := settings.UserID
+    ctx = auth.WithUserID(ctx, uid)
+    prefs := settings.Preferences
+    d := &Digest{}
+
+    if prefs.Get(model.NotifyCommentReply).Digest {
+        q := client.Comment.Query().Where(
+            comment.HasParentCommentWith(comment.AuthorIDEQ(uid)),
+            comment.AuthorIDNEQ(uid),
+            comment.HasDeletedEQ(false),
+            comment.CreatedAtGT(since),
+            comment.CreatedAtLTE(until),
+        )
+        if len(settings.MutedPostIds) > 0 { q = q.Where(comment.PostIDNotIn(settings.MutedPostIds...)) }
+        if len(settings.MutedCommunityIds) > 0 { q = q.Where(comment.CommunityIDNotIn(settings.MutedCommunityIds...)) }
+        total, err := q.Clone().Count(ctx)
+        if err != nil { return nil, fmt.Errorf("count replies: %w", err) }
+        d.RepliesTotal = total
+        if total > 0 {
+            rows, err := q.WithAuthor().WithPost().Order(ent.Desc(comment.FieldCreatedAt)).Limit(maxItems).All(ctx)
+            if err != nil { return nil, fmt.Errorf("load replies: %w", err) }
+            for _, c := range rows {
+                item := ReplyItem{Excerpt: excerpt(c.Content, 140)}
+                if c.Edges.Author != nil { item.Author = c.Edges.Author.Name }
+                if c.Edges.Post != nil {
+                    item.PostTitle = c.Edges.Post.Title
+                    item.URL = fmt.Sprintf("%s/post/%s#comment-%d", publicURL, c.Edges.Post.Slug, c.ID)
+                }
+                d.Replies = append(d.Replies, item)
+            }
+        }
+    }
+
+    if prefs.Get(model.NotifyUserFollow).Digest {
+        q := client.UserFollow.Query().Where(
+            userfollow.FolloweeIDEQ(uid),
+            userfollow.CreatedAtGT(since),
+            userfollow.CreatedAtLTE(until),
+        )
+        total, err := q.Clone().Count(ctx)
+        if err != nil { return nil, fmt.Errorf("count followers: %w", err) }
+        d.FollowersTotal = total
+        if total > 0 {
+            rows, err := q.WithFollower().Order(ent.Desc(userfollow.FieldCreatedAt)).Limit(maxItems).All(ctx)
+            if err != nil { return nil, fmt.Errorf("load followers: %w", err) }
+            for _, f := range rows {
+                if f.Edges.Follower == nil { continue }
+                d.Followers = append(d.Followers, FollowerItem{
+                    Name: f.Edges.Follower.Name,
+                    URL:  fmt.Sprintf("%s/user/%s", publicURL, f.Edges.Follower.Slug),
+                })
+            }
+        }
+    }
+
+    if prefs.Get(model.NotifyCommunityPost).Digest {
+        q := client.Post.Query().Where(
+            post.VisibilityEQ(post.VisibilityPublished),
+            post.AuthorIDNEQ(uid),
+            post.PublishedAtGT(since),
+            post.PublishedAtLTE(until),
+            post.HasCommunityWith(community.HasFollowersWith(communityfollow.UserIDEQ(uid))),
+        )
+        if len(settings.MutedCommunityIds) > 0 { q = q.Where(post.CommunityIDNotIn(settings.MutedCommunityIds...)) }
+        total, err := q.Clone().Count(ctx)
+        if err != nil { return nil, fmt.Errorf("count posts: %w", err) }
+        d.PostsTotal = total
+        if total > 0 {
+            rows, err := q.WithCommunity().Order(ent.Desc(post.FieldPublishedAt)).Limit(maxItems).All(ctx)
+            if err != nil { return nil, fmt.Errorf("load posts: %w", err) }
+            for _, p := range rows {
+                item := PostItem{Title: p.Title, URL: fmt.Sprintf("%s/post/%s", publicURL, p.Slug)}
+                if p.Edges.Community != nil { item.Community = p.Edges.Community.Title }
+                d.Posts = append(d.Posts, item)
+            }
+        }
+    }
+
+    return d, nil
+}
+
+func excerpt(s string, n int) string {
+    r := []rune(s)
+    if len(r) <= n { return s }
+    return string(r[:n]) + "…"
+}
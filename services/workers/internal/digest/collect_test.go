@@ -0,0 +1,81 @@
+package digest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"stormlink/server/authz"
+	"stormlink/server/ent"
+	"stormlink/server/shadow"
+	"stormlink/tests/fixtures"
+	"stormlink/tests/testhelper"
+)
+
+type CollectTestSuite struct {
+	suite.Suite
+	ctx    context.Context
+	helper *testhelper.PostgresTestHelper
+}
+
+func (suite *CollectTestSuite) SetupSuite() {
+	suite.ctx = context.Background()
+	suite.helper = testhelper.NewPostgresTestHelper(suite.T())
+	suite.helper.WaitForDatabase(suite.T())
+}
+
+func (suite *CollectTestSuite) TearDownSuite() {
+	if suite.helper != nil {
+		suite.helper.Cleanup()
+	}
+}
+
+// TestShadowReplyHidden — ответ под теневым баном не попадает в сводку получателя
+func (suite *CollectTestSuite) TestShadowReplyHidden() {
+	suite.helper.CleanDatabase(suite.T())
+	client := suite.helper.GetClient()
+	shadow.Register(client, shadow.Moderators(client, authz.New(authz.NewEntStore(client))))
+	now := time.Now()
+	newUser := func(name string) *ent.User {
+		u, err := fixtures.CreateTestUser(suite.ctx, client, fixtures.UserFixture{
+			Name: name, Slug: fixtures.RandomSlug(), Email: fixtures.RandomEmail(),
+			Password: "password123", Salt: "salt", IsVerified: true, CreatedAt: now,
+		})
+		suite.Require().NoError(err)
+		return u
+	}
+	owner, recipient, friend, spammer := newUser("Owner"), newUser("Recipient"), newUser("Friend"), newUser("Spammer")
+	community, err := fixtures.CreateTestCommunity(suite.ctx, client, fixtures.CommunityFixture{
+		Name: "Community", Slug: fixtures.RandomSlug(), OwnerID: owner.ID, CreatedAt: now,
+	})
+	suite.Require().NoError(err)
+	post, err := fixtures.CreateTestPost(suite.ctx, client, fixtures.PostFixture{
+		Title: "Post", Content: "content", CommunityID: community.ID, AuthorID: owner.ID, CreatedAt: now,
+	})
+	suite.Require().NoError(err)
+	parent, err := fixtures.CreateTestComment(suite.ctx, client, fixtures.CommentFixture{
+		Content: "question", PostID: post.ID, AuthorID: recipient.ID, CreatedAt: now.Add(-time.Hour),
+	})
+	suite.Require().NoError(err)
+	suite.Require().NoError(client.CommunityUserBan.Create().
+		SetUserID(spammer.ID).SetCommunityID(community.ID).SetShadow(true).Exec(suite.ctx))
+	for _, author := range []*ent.User{friend, spammer} {
+		_, err := fixtures.CreateTestComment(suite.ctx, client, fixtures.CommentFixture{
+			Content: "reply from " + author.Name, PostID: post.ID, AuthorID: author.ID, ParentID: &parent.ID, CreatedAt: now,
+		})
+		suite.Require().NoError(err)
+	}
+
+	settings := &ent.NotificationSettings{UserID: recipient.ID}
+	d, err := Collect(suite.ctx, client, settings, now.Add(-time.Minute), now.Add(time.Minute), "http://x")
+	suite.Require().NoError(err)
+	suite.Equal(1, d.RepliesTotal)
+	suite.Require().Len(d.Replies, 1)
+	suite.Equal(friend.Name, d.Replies[0].Author)
+}
+
+func TestCollectTestSuite(t *testing.T) {
+	suite.Run(t, new(CollectTestSuite))
+}
@@ -0,0 +1,41 @@
+package digest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender_OnlyNonEmptyCategories(t *testing.T) {
+	d := &Digest{
+		Replies:      []ReplyItem{{Author: "alice", PostTitle: "Hello", Excerpt: "<b>hi</b>", URL: "http://x/post/hello#comment-1"}},
+		RepliesTotal: 3,
+	}
+	html, err := Render(View{
+		Name:               "bob",
+		Digest:             d,
+		UnsubscribeAll:     "http://api/unsubscribe?token=all",
+		UnsubscribeReplies: "http://api/unsubscribe?token=replies",
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, html, "Ответы на ваши комментарии (3)")
+	assert.Contains(t, html, "и ещё 2")
+	assert.NotContains(t, html, "Новые подписчики")
+	assert.NotContains(t, html, "<b>hi</b>", "содержимое комментария должно экранироваться")
+	assert.Contains(t, html, "token=replies")
+	assert.True(t, strings.Contains(html, "token=all"))
+}
+
+func TestDigest_EmptyAndSubject(t *testing.T) {
+	assert.True(t, (&Digest{}).Empty())
+	assert.Equal(t, "У вас новые подписчики", Subject(&Digest{FollowersTotal: 1}))
+	assert.Equal(t, "Вам ответили в обсуждении", Subject(&Digest{FollowersTotal: 1, RepliesTotal: 1}))
+}
+
+func TestExcerpt(t *testing.T) {
+	assert.Equal(t, "привет", excerpt("привет", 10))
+	assert.Equal(t, "при…", excerpt("привет", 3))
+}
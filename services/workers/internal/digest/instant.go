@@ -0,0 +1,113 @@
+package digest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"log"
+
+	"stormlink/server/ent"
+	"stormlink/server/ent/notificationsettings"
+	"stormlink/server/model"
+	notificationuc "stormlink/server/usecase/notification"
+	nsuc "stormlink/server/usecase/notificationsettings"
+	"stormlink/shared/auth"
+	"stormlink/shared/jobs"
+	sharedmail "stormlink/shared/mail"
+)
+
+// InstantView — данные письма об отдельном событии (канал email в настройках уведомлений)
+type InstantView struct {
+    Name            string
+    Text            string
+    Message         string
+    URL             string
+    SettingsURL     string
+    UnsubscribeType string
+    UnsubscribeAll  string
+}
+
+var instantTemplate = template.Must(template.New("instant").Parse(`
+<h2>{{.Name}}, {{.Text}}</h2>
+{{if .Message}}<p>{{.Message}}</p>{{end}}
+{{if .URL}}<p><a href="{{.URL}}">Открыть</a></p>{{end}}
+<hr>
+<p style="font-size:12px;color:#888">
+Настроить уведомления можно в <a href="{{.SettingsURL}}">настройках</a>.<br>
+Отписаться: <a href="{{.UnsubscribeType}}">от таких писем</a> · <a href="{{.UnsubscribeAll}}">от всех писем</a>
+</p>
+`))
+
+// instantText — описание события для темы и заголовка письма
+func instantText(t, actor string) string {
+    if actor == "" { actor = "Кто-то" }
+    switch t {
+    case model.NotifyCommentReply:
+        return actor + " ответил(а) на ваш комментарий"
+    case model.NotifyMention:
+        return actor + " упомянул(а) вас"
+    case model.NotifyUserFollow:
+        return actor + " подписался(ась) на вас"
+    case model.NotifyPostLike:
+        return actor + " оценил(а) ваш пост"
+    case model.NotifyCommentLike:
+        return actor + " оценил(а) ваш комментарий"
+    case model.NotifyModeration:
+        return "к вашему аккаунту применена мера модерации"
+    default:
+        return "у вас новое уведомление"
+    }
+}
+
+func (h *handler) handleInstant(ctx context.Context, job notificationuc.EmailJob) error {
+    settings, err := h.client.NotificationSettings.Query().
+        Where(notificationsettings.UserIDEQ(job.UserID)).
+        WithUser().
+        Only(ctx)
+    if err != nil && !ent.IsNotFound(err) { return err }
+
+    // Настройки могли измениться, пока задача ждала в outbox
+    if settings != nil && settings.EmailFrequency == notificationsettings.EmailFrequencyOff { return nil }
+    if !nsuc.Channels(settings, job.Type).Email { return nil }
+
+    var u *ent.User
+    if settings != nil {
+        u = settings.Edges.User
+    } else if u, err = h.client.User.Get(ctx, job.UserID); err != nil {
+        if ent.IsNotFound(err) { return jobs.Permanent(err) }
+        return err
+    }
+    if u == nil || !u.IsVerified { return nil }
+
+    publicURL := sharedmail.PublicURL()
+    view := InstantView{Name: u.Name, SettingsURL: publicURL + "/settings/notifications"}
+    actor := ""
+    if job.ActorID != 0 {
+        if a, err := h.client.User.Get(ctx, job.ActorID); err == nil { actor = a.Name }
+    }
+    view.Text = instantText(job.Type, actor)
+    if job.Message != nil { view.Message = *job.Message }
+    if job.PostID != nil {
+        if p, err := h.client.Post.Get(auth.WithUserID(ctx, u.ID), *job.PostID); err == nil {
+            view.URL = fmt.Sprintf("%s/post/%s", publicURL, p.Slug)
+            if job.CommentID != nil { view.URL += fmt.Sprintf("#comment-%d", *job.CommentID) }
+        }
+    }
+    if view.UnsubscribeType, err = UnsubscribeURL(u.ID, job.Type); err != nil { return err }
+    if view.UnsubscribeAll, err = UnsubscribeURL(u.ID, nsuc.CategoryAll); err != nil { return err }
+
+    var buf bytes.Buffer
+    if err := instantTemplate.Execute(&buf, view); err != nil {
+        return jobs.Permanent(fmt.Errorf("render notification email: %w", err))
+    }
+    headers := map[string]string{
+        "List-Unsubscribe":      "<" + view.UnsubscribeType + ">",
+        "List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+    }
+    if err := sharedmail.Send(u.Email, view.Text, buf.String(), headers); err != nil {
+        return err
+    }
+    log.Printf("📨 notification email %s sent to user %d", job.Type, u.ID)
+    return nil
+}
@@ -0,0 +1,92 @@
+package digest
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"stormlink/server/ent"
+	"stormlink/server/ent/notificationsettings"
+	"stormlink/server/model"
+	notificationuc "stormlink/server/usecase/notification"
+	nsuc "stormlink/server/usecase/notificationsettings"
+	"stormlink/shared/jobs"
+	"stormlink/shared/jwt"
+	sharedmail "stormlink/shared/mail"
+)
+
+// JobEmailDigest — задача отправки сводки одному пользователю
+const JobEmailDigest = "email_digest"
+
+// DigestJob — окно событий (Since, Until] для пользователя
+type DigestJob struct {
+    UserID int       `json:"user_id"`
+    Since  time.Time `json:"since"`
+    Until  time.Time `json:"until"`
+}
+
+// Register регистрирует обработчики сводок и мгновенных писем об уведомлениях в реестре воркеров
+func Register(reg *jobs.Registry, client *ent.Client) {
+    h := &handler{client: client}
+    jobs.Register(reg, JobEmailDigest, h.handle, jobs.WithMaxAttempts(5), jobs.WithConcurrency(4))
+    jobs.Register(reg, notificationuc.JobNotificationEmail, h.handleInstant, jobs.WithMaxAttempts(5), jobs.WithConcurrency(4))
+}
+
+type handler struct {
+    client *ent.Client
+}
+
+func (h *handler) handle(ctx context.Context, job DigestJob) error {
+    settings, err := h.client.NotificationSettings.Query().
+        Where(notificationsettings.UserIDEQ(job.UserID)).
+        WithUser().
+        Only(ctx)
+    if ent.IsNotFound(err) {
+        return jobs.Permanent(fmt.Errorf("notification settings for user %d not found", job.UserID))
+    }
+    if err != nil { return err }
+
+    // Настройки могли измениться, пока задача ждала в очереди
+    if settings.EmailFrequency == notificationsettings.EmailFrequencyOff { return nil }
+    u := settings.Edges.User
+    if u == nil || !u.IsVerified { return nil }
+
+    publicURL := sharedmail.PublicURL()
+    d, err := Collect(ctx, h.client, settings, job.Since, job.Until, publicURL)
+    if err != nil { return err }
+    if d.Empty() { return nil }
+
+    view := View{Name: u.Name, Digest: d, SettingsURL: publicURL + "/settings/notifications"}
+    links := map[string]*string{
+        nsuc.CategoryAll:          &view.UnsubscribeAll,
+        model.NotifyCommentReply:  &view.UnsubscribeReplies,
+        model.NotifyUserFollow:    &view.UnsubscribeFollows,
+        model.NotifyCommunityPost: &view.UnsubscribeCommunityPosts,
+    }
+    for category, dst := range links {
+        link, err := UnsubscribeURL(u.ID, category)
+        if err != nil { return err }
+        *dst = link
+    }
+
+    body, err := Render(view)
+    if err != nil { return jobs.Permanent(fmt.Errorf("render digest: %w", err)) }
+
+    headers := map[string]string{
+        "List-Unsubscribe":      "<" + view.UnsubscribeAll + ">",
+        "List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+    }
+    if err := sharedmail.Send(u.Email, Subject(d), body, headers); err != nil {
+        return err
+    }
+    log.Printf("📨 digest sent to user %d (replies=%d, followers=%d, posts=%d)", u.ID, d.RepliesTotal, d.FollowersTotal, d.PostsTotal)
+    return nil
+}
+
+// UnsubscribeURL формирует подписанную ссылку отписки от категории писем (тип события или all)
+func UnsubscribeURL(userID int, category string) (string, error) {
+    token, err := jwt.GenerateUnsubscribeToken(userID, category)
+    if err != nil { return "", fmt.Errorf("sign unsubscribe token: %w", err) }
+    return fmt.Sprintf("%s/unsubscribe?token=%s", sharedmail.APIPublicURL(), token), nil
+}
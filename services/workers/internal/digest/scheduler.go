@@ -0,0 +1,93 @@
+package digest
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"entgo.io/ent/dialect/sql"
+
+	"stormlink/server/ent"
+	"stormlink/server/ent/notificationsettings"
+	nsuc "stormlink/server/usecase/notificationsettings"
+	"stormlink/shared/outbox"
+)
+
+// Scheduler раз в минуту ставит задачи сводок для пользователей, у которых подошло время
+type Scheduler struct {
+    client   *ent.Client
+    interval time.Duration
+    batch    int
+}
+
+func NewScheduler(client *ent.Client) *Scheduler {
+    return &Scheduler{client: client, interval: time.Minute, batch: 200}
+}
+
+func (s *Scheduler) Run(ctx context.Context) error {
+    log.Println("🗓 Digest scheduler: started")
+    ticker := time.NewTicker(s.interval)
+    defer ticker.Stop()
+    for {
+        n, err := s.schedule(ctx)
+        if err != nil {
+            log.Printf("❌ Digest scheduler: %v", err)
+        }
+        if err == nil && n == s.batch {
+            continue
+        }
+        select {
+        case <-ctx.Done():
+            return nil
+        case <-ticker.C:
+        }
+    }
+}
+
+// schedule в одной транзакции сдвигает окно сводки и пишет задачу в outbox
+func (s *Scheduler) schedule(ctx context.Context) (int, error) {
+    tx, err := s.client.Tx(ctx)
+    if err != nil { return 0, fmt.Errorf("begin tx: %w", err) }
+    defer func() { _ = tx.Rollback() }()
+
+    now := time.Now()
+    due, err := tx.NotificationSettings.Query().
+        Where(
+            notificationsettings.EmailFrequencyNEQ(notificationsettings.EmailFrequencyOff),
+            notificationsettings.NextDigestAtLTE(now),
+        ).
+        Order(ent.Asc(notificationsettings.FieldNextDigestAt)).
+        Limit(s.batch).
+        ForUpdate(sql.WithLockAction(sql.SkipLocked)).
+        All(ctx)
+    if err != nil { return 0, fmt.Errorf("select due settings: %w", err) }
+
+    for _, st := range due {
+        since := st.CreatedAt
+        if st.LastDigestAt != nil { since = *st.LastDigestAt }
+
+        // В тихие часы пользователя сводка переносится на их окончание
+        if nsuc.InQuietHours(st, now) {
+            if err := tx.NotificationSettings.UpdateOne(st).
+                SetNextDigestAt(nsuc.QuietHoursEnd(st, now)).
+                Exec(ctx); err != nil {
+                return 0, fmt.Errorf("postpone digest for user %d: %w", st.UserID, err)
+            }
+            continue
+        }
+
+        job := DigestJob{UserID: st.UserID, Since: since, Until: now}
+        if err := outbox.Add(ctx, tx.Outbox, JobEmailDigest, job); err != nil { return 0, err }
+
+        if err := tx.NotificationSettings.UpdateOne(st).
+            SetLastDigestAt(now).
+            SetNextDigestAt(now.Add(nsuc.DigestInterval(st.EmailFrequency))).
+            Exec(ctx); err != nil {
+            return 0, fmt.Errorf("advance digest window for user %d: %w", st.UserID, err)
+        }
+    }
+
+    if err := tx.Commit(); err != nil { return 0, fmt.Errorf("commit: %w", err) }
+    return len(due), nil
+}
@@ -0,0 +1,81 @@
+package digest
+
+import (
+	"bytes"
+	"html/template"
+)
+
+// View — данные шаблона письма
+type View struct {
+    Name        string
+    Digest      *Digest
+    SettingsURL string
+    // Ссылки отписки по категориям (GET-страница подтверждения)
+    UnsubscribeAll            string
+    UnsubscribeReplies        string
+    UnsubscribeFollows        string
+    UnsubscribeCommunityPosts string
+}
+
+var digestTemplate = template.Must(template.New("digest").Funcs(template.FuncMap{
+    "more": func(total int, shown int) int { return total - shown },
+}).Parse(`
+<h2>{{.Name}}, вот что произошло, пока вас не было</h2>
+
+{{with .Digest}}
+{{if .RepliesTotal}}
+<h3>Ответы на ваши комментарии ({{.RepliesTotal}})</h3>
+<ul>
+{{range .Replies}}<li><b>{{.Author}}</b> в «<a href="{{.URL}}">{{.PostTitle}}</a>»: {{.Excerpt}}</li>
+{{end}}</ul>
+{{if gt (more .RepliesTotal (len .Replies)) 0}}<p>и ещё {{more .RepliesTotal (len .Replies)}}</p>{{end}}
+{{end}}
+
+{{if .FollowersTotal}}
+<h3>Новые подписчики ({{.FollowersTotal}})</h3>
+<ul>
+{{range .Followers}}<li><a href="{{.URL}}">{{.Name}}</a></li>
+{{end}}</ul>
+{{if gt (more .FollowersTotal (len .Followers)) 0}}<p>и ещё {{more .FollowersTotal (len .Followers)}}</p>{{end}}
+{{end}}
+
+{{if .PostsTotal}}
+<h3>Новые посты в ваших сообществах ({{.PostsTotal}})</h3>
+<ul>
+{{range .Posts}}<li><a href="{{.URL}}">{{.Title}}</a>{{if .Community}} — {{.Community}}{{end}}</li>
+{{end}}</ul>
+{{if gt (more .PostsTotal (len .Posts)) 0}}<p>и ещё {{more .PostsTotal (len .Posts)}}</p>{{end}}
+{{end}}
+{{end}}
+
+<hr>
+<p style="font-size:12px;color:#888">
+Изменить частоту писем можно в <a href="{{.SettingsURL}}">настройках уведомлений</a>.<br>
+Отписаться:
+{{if .Digest.RepliesTotal}}<a href="{{.UnsubscribeReplies}}">от ответов</a> · {{end}}
+{{if .Digest.FollowersTotal}}<a href="{{.UnsubscribeFollows}}">от подписчиков</a> · {{end}}
+{{if .Digest.PostsTotal}}<a href="{{.UnsubscribeCommunityPosts}}">от постов сообществ</a> · {{end}}
+<a href="{{.UnsubscribeAll}}">от всех писем</a>
+</p>
+`))
+
+// Render рендерит HTML письма со сводкой
+func Render(v View) (string, error) {
+    var buf bytes.Buffer
+    if err := digestTemplate.Execute(&buf, v); err != nil {
+        return "", err
+    }
+    return buf.String(), nil
+}
+
+// Subject формирует тему письма
+func Subject(d *Digest) string {
+    switch {
+    case d.RepliesTotal > 0:
+        return "Вам ответили в обсуждении"
+    case d.FollowersTotal > 0:
+        return "У вас новые подписчики"
+    default:
+        return "Новые посты в ваших сообществах"
+    }
+}
@@ -0,0 +1,126 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"entgo.io/ent/dialect/sql"
+
+	"stormlink/server/ent"
+	entob "stormlink/server/ent/outbox"
+	"stormlink/shared/jobs"
+	"stormlink/shared/rabbitmq"
+)
+
+// Relay публикует неотправленные сообщения из outbox в RabbitMQ с publisher confirms
+type Relay struct {
+    client    *ent.Client
+    conn      *rabbitmq.Connection
+    batch     int
+    interval  time.Duration
+    retention time.Duration
+    backoff   jobs.Backoff
+}
+
+func NewRelay(client *ent.Client, conn *rabbitmq.Connection) *Relay {
+    return &Relay{
+        client:    client,
+        conn:      conn,
+        batch:     100,
+        interval:  time.Second,
+        retention: 7 * 24 * time.Hour,
+        backoff:   jobs.Backoff{Base: time.Second, Max: time.Minute},
+    }
+}
+
+// Run опрашивает outbox до отмены контекста
+func (r *Relay) Run(ctx context.Context) error {
+    log.Println("📤 Outbox relay: started")
+    ticker := time.NewTicker(r.interval)
+    defer ticker.Stop()
+    cleanup := time.NewTicker(time.Hour)
+    defer cleanup.Stop()
+
+    for {
+        n, err := r.flush(ctx)
+        if err != nil {
+            log.Printf("❌ Outbox relay: %v", err)
+        }
+        // Полная пачка — скорее всего есть ещё сообщения, не ждём тика
+        if err == nil && n == r.batch {
+            continue
+        }
+        select {
+        case <-ctx.Done():
+            return nil
+        case <-cleanup.C:
+            r.cleanup(ctx)
+        case <-ticker.C:
+        }
+    }
+}
+
+// flush забирает пачку готовых к отправке сообщений под блокировкой (SKIP LOCKED позволяет запускать несколько relay),
+// публикует их и помечает отправленными в той же транзакции.
+func (r *Relay) flush(ctx context.Context) (int, error) {
+    tx, err := r.client.Tx(ctx)
+    if err != nil { return 0, fmt.Errorf("begin tx: %w", err) }
+    defer func() { _ = tx.Rollback() }()
+
+    now := time.Now()
+    rows, err := tx.Outbox.Query().
+        Where(entob.SentAtIsNil(), entob.AvailableAtLTE(now)).
+        Order(ent.Asc(entob.FieldID)).
+        Limit(r.batch).
+        ForUpdate(sql.WithLockAction(sql.SkipLocked)).
+        All(ctx)
+    if err != nil { return 0, fmt.Errorf("select outbox: %w", err) }
+    if len(rows) == 0 { return 0, nil }
+
+    sent := make([]int, 0, len(rows))
+    for _, row := range rows {
+        if err := r.conn.PublishConfirmed(ctx, jobs.QueueName(row.Topic), jobs.NewMessage(row.Topic, row.Payload)); err != nil {
+            // Брокер недоступен — откладываем сообщение и прекращаем пачку, остальные подождут следующего прохода
+            attempt := row.Attempts + 1
+            if uerr := tx.Outbox.UpdateOne(row).
+                SetAttempts(attempt).
+                SetLastError(err.Error()).
+                SetAvailableAt(now.Add(r.backoff.Delay(attempt + 1))).
+                Exec(ctx); uerr != nil {
+                return 0, fmt.Errorf("update outbox %d: %w", row.ID, uerr)
+            }
+            if cerr := r.markSent(ctx, tx, sent); cerr != nil { return 0, cerr }
+            if cerr := tx.Commit(); cerr != nil { return 0, fmt.Errorf("commit: %w", cerr) }
+            return len(sent), fmt.Errorf("publish outbox %d (%s): %w", row.ID, row.Topic, err)
+        }
+        sent = append(sent, row.ID)
+    }
+
+    if err := r.markSent(ctx, tx, sent); err != nil { return 0, err }
+    if err := tx.Commit(); err != nil { return 0, fmt.Errorf("commit: %w", err) }
+    return len(sent), nil
+}
+
+func (r *Relay) markSent(ctx context.Context, tx *ent.Tx, ids []int) error {
+    if len(ids) == 0 { return nil }
+    if err := tx.Outbox.Update().Where(entob.IDIn(ids...)).SetSentAt(time.Now()).ClearLastError().Exec(ctx); err != nil {
+        return fmt.Errorf("mark outbox sent: %w", err)
+    }
+    return nil
+}
+
+// cleanup удаляет давно отправленные сообщения
+func (r *Relay) cleanup(ctx context.Context) {
+    n, err := r.client.Outbox.Delete().
+        Where(entob.SentAtNotNil(), entob.SentAtLT(time.Now().Add(-r.retention))).
+        Exec(ctx)
+    if err != nil {
+        log.Printf("❌ Outbox relay: cleanup failed: %v", err)
+        return
+    }
+    if n > 0 {
+        log.Printf("🧹 Outbox relay: removed %d sent messages", n)
+    }
+}
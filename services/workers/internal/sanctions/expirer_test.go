@@ -0,0 +1,110 @@
+package sanctions
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"stormlink/server/ent"
+	"stormlink/server/ent/communityuserban"
+	"stormlink/server/ent/communityusermute"
+	"stormlink/server/ent/moderationaction"
+	"stormlink/server/ent/notification"
+	"stormlink/tests/fixtures"
+	"stormlink/tests/testhelper"
+)
+
+type ExpirerTestSuite struct {
+	suite.Suite
+	ctx    context.Context
+	helper *testhelper.PostgresTestHelper
+}
+
+func (suite *ExpirerTestSuite) SetupSuite() {
+	suite.ctx = context.Background()
+	suite.helper = testhelper.NewPostgresTestHelper(suite.T())
+	suite.helper.WaitForDatabase(suite.T())
+}
+
+func (suite *ExpirerTestSuite) TearDownSuite() {
+	if suite.helper != nil {
+		suite.helper.Cleanup()
+	}
+}
+
+func (suite *ExpirerTestSuite) SetupTest() {
+	suite.helper.CleanDatabase(suite.T())
+}
+
+// TestExpire — истекшие санкции снимаются, действующие остаются; о каждой снятой обычной
+// санкции приходит ровно одно уведомление, о теневой — ни одного
+func (suite *ExpirerTestSuite) TestExpire() {
+	client := suite.helper.GetClient()
+	now := time.Now()
+	expired, active := now.Add(-time.Minute), now.Add(time.Hour)
+	newUser := func(name string) *ent.User {
+		u, err := fixtures.CreateTestUser(suite.ctx, client, fixtures.UserFixture{
+			Name: name, Slug: fixtures.RandomSlug(), Email: fixtures.RandomEmail(),
+			Password: "password123", Salt: "salt", IsVerified: true, CreatedAt: now,
+		})
+		suite.Require().NoError(err)
+		return u
+	}
+	owner, banned, shadowed, muted := newUser("Owner"), newUser("Banned"), newUser("Shadowed"), newUser("Muted")
+	community, err := fixtures.CreateTestCommunity(suite.ctx, client, fixtures.CommunityFixture{
+		Name: "Go", Slug: fixtures.RandomSlug(), OwnerID: owner.ID, CreatedAt: now,
+	})
+	suite.Require().NoError(err)
+
+	suite.Require().NoError(client.CommunityUserBan.Create().
+		SetUserID(banned.ID).SetCommunityID(community.ID).SetExpiresAt(expired).Exec(suite.ctx))
+	suite.Require().NoError(client.HostUserMute.Create().
+		SetUserID(banned.ID).SetExpiresAt(expired).Exec(suite.ctx))
+	suite.Require().NoError(client.CommunityUserMute.Create().
+		SetUserID(shadowed.ID).SetCommunityID(community.ID).SetExpiresAt(expired).SetShadow(true).Exec(suite.ctx))
+	suite.Require().NoError(client.HostUserMute.Create().
+		SetUserID(muted.ID).SetExpiresAt(active).Exec(suite.ctx))
+
+	more, err := NewExpirer(client).expire(suite.ctx)
+	suite.Require().NoError(err)
+	suite.False(more)
+
+	left, err := client.CommunityUserBan.Query().Where(communityuserban.UserIDEQ(banned.ID)).Count(suite.ctx)
+	suite.Require().NoError(err)
+	suite.Zero(left, "истекший бан снят")
+	left, err = client.CommunityUserMute.Query().Where(communityusermute.UserIDEQ(shadowed.ID)).Count(suite.ctx)
+	suite.Require().NoError(err)
+	suite.Zero(left, "истекший теневой мут снят")
+	mutes, err := client.HostUserMute.Query().WithUser().All(suite.ctx)
+	suite.Require().NoError(err)
+	suite.Require().Len(mutes, 1, "действующий мут остается")
+	suite.Equal(muted.ID, mutes[0].Edges.User.ID)
+
+	notifications := func(u *ent.User) []*ent.Notification {
+		ns, err := client.Notification.Query().
+			Where(notification.UserIDEQ(u.ID), notification.TypeEQ(notification.TypeModeration)).
+			All(suite.ctx)
+		suite.Require().NoError(err)
+		return ns
+	}
+	suite.Len(notifications(banned), 2, "по уведомлению на бан в сообществе и мут на платформе")
+	suite.Empty(notifications(shadowed), "о снятии теневой санкции не сообщаем")
+	suite.Empty(notifications(muted))
+
+	lifted, err := client.ModerationAction.Query().
+		Where(moderationaction.TypeIn(moderationaction.TypeUserUnbanned, moderationaction.TypeUserUnmuted)).
+		Count(suite.ctx)
+	suite.Require().NoError(err)
+	suite.Equal(3, lifted, "снятие каждой санкции, в том числе теневой, попадает в журнал")
+
+	more, err = NewExpirer(client).expire(suite.ctx)
+	suite.Require().NoError(err)
+	suite.False(more)
+	suite.Len(notifications(banned), 2, "повторный проход ничего не снимает")
+}
+
+func TestExpirerTestSuite(t *testing.T) {
+	suite.Run(t, new(ExpirerTestSuite))
+}
@@ -0,0 +1,315 @@
+package sanctions
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"entgo.io/ent/dialect/sql"
+
+	"stormlink/server/ent"
+	"stormlink/server/ent/communityuserban"
+	"stormlink/server/ent/communityusermute"
+	"stormlink/server/ent/hostcommunityban"
+	"stormlink/server/ent/hostcommunitymute"
+	"stormlink/server/ent/hostipban"
+	"stormlink/server/ent/hostuserban"
+	"stormlink/server/ent/hostusermute"
+	"stormlink/server/ent/moderationaction"
+	"stormlink/server/ent/notification"
+	"stormlink/server/model"
+	"stormlink/server/modlog"
+	"stormlink/server/shadow"
+	notificationuc "stormlink/server/usecase/notification"
+)
+
+// Expirer раз в минуту снимает баны и муты с истекшим сроком и уведомляет наказанных
+type Expirer struct {
+	client   *ent.Client
+	notify   notificationuc.NotificationUsecase
+	interval time.Duration
+	batch    int
+}
+
+func NewExpirer(client *ent.Client) *Expirer {
+	return &Expirer{
+		client:   client,
+		notify:   notificationuc.NewNotificationUsecase(client),
+		interval: time.Minute,
+		batch:    200,
+	}
+}
+
+func (e *Expirer) Run(ctx context.Context) error {
+	log.Println("⏳ Sanction expirer: started")
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+	for {
+		more, err := e.expire(ctx)
+		if err != nil {
+			log.Printf("❌ Sanction expirer: %v", err)
+		}
+		if err == nil && more {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// lifted — снятая санкция, о которой нужно уведомить
+type lifted struct {
+	kind        string
+	recipientID int
+	communityID *int
+	title       string
+}
+
+// expire в одной транзакции удаляет пачку истекших санкций каждого вида и пишет снятие
+// в журнал модерации от имени системы; уведомления отправляются после коммита. more — хотя бы один вид выбран полной пачкой.
+func (e *Expirer) expire(ctx context.Context) (more bool, err error) {
+	// Истекают и теневые санкции, а фильтр без зрителя их скрывает
+	ctx = shadow.Unfiltered(ctx)
+	tx, err := e.client.Tx(ctx)
+	if err != nil {
+		return false, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	now := time.Now()
+	lock := sql.WithLockAction(sql.SkipLocked)
+	var out []lifted
+	var entries []modlog.Entry
+
+	communityBans, err := tx.CommunityUserBan.Query().
+		Where(communityuserban.ExpiresAtLTE(now)).
+		WithCommunity().
+		Limit(e.batch).
+		ForUpdate(lock).
+		All(ctx)
+	if err != nil {
+		return false, fmt.Errorf("select community bans: %w", err)
+	}
+	ids := make([]int, len(communityBans))
+	for i, b := range communityBans {
+		ids[i] = b.ID
+		// О снятии теневой санкции не сообщаем: наказанный о ней не знал
+		if !b.Shadow {
+			out = append(out, lifted{model.SanctionCommunityBan, b.UserID, &b.CommunityID, communityTitle(b.Edges.Community)})
+		}
+		entries = append(entries, modlog.Entry{
+			Type: moderationaction.TypeUserUnbanned, CommunityID: &b.CommunityID,
+			TargetType: moderationaction.TargetTypeUser, TargetID: b.UserID, TargetUserID: &b.UserID, Before: b,
+		})
+	}
+	if _, err := tx.CommunityUserBan.Delete().Where(communityuserban.IDIn(ids...)).Exec(ctx); err != nil {
+		return false, fmt.Errorf("delete community bans: %w", err)
+	}
+	more = more || len(communityBans) == e.batch
+
+	communityMutes, err := tx.CommunityUserMute.Query().
+		Where(communityusermute.ExpiresAtLTE(now)).
+		WithCommunity().
+		Limit(e.batch).
+		ForUpdate(lock).
+		All(ctx)
+	if err != nil {
+		return false, fmt.Errorf("select community mutes: %w", err)
+	}
+	ids = make([]int, len(communityMutes))
+	for i, m := range communityMutes {
+		ids[i] = m.ID
+		if !m.Shadow {
+			out = append(out, lifted{model.SanctionCommunityMute, m.UserID, &m.CommunityID, communityTitle(m.Edges.Community)})
+		}
+		entries = append(entries, modlog.Entry{
+			Type: moderationaction.TypeUserUnmuted, CommunityID: &m.CommunityID,
+			TargetType: moderationaction.TargetTypeUser, TargetID: m.UserID, TargetUserID: &m.UserID, Before: m,
+		})
+	}
+	if _, err := tx.CommunityUserMute.Delete().Where(communityusermute.IDIn(ids...)).Exec(ctx); err != nil {
+		return false, fmt.Errorf("delete community mutes: %w", err)
+	}
+	more = more || len(communityMutes) == e.batch
+
+	hostBans, err := tx.HostUserBan.Query().
+		Where(hostuserban.ExpiresAtLTE(now)).
+		WithUser().
+		Limit(e.batch).
+		ForUpdate(lock).
+		All(ctx)
+	if err != nil {
+		return false, fmt.Errorf("select host bans: %w", err)
+	}
+	ids = make([]int, len(hostBans))
+	for i, b := range hostBans {
+		ids[i] = b.ID
+		if u := b.Edges.User; u != nil {
+			if !b.Shadow {
+				out = append(out, lifted{kind: model.SanctionHostBan, recipientID: u.ID})
+			}
+			entries = append(entries, modlog.Entry{
+				Type:       moderationaction.TypeUserUnbanned,
+				TargetType: moderationaction.TargetTypeUser, TargetID: u.ID, TargetUserID: &u.ID, Before: b,
+			})
+		}
+	}
+	if _, err := tx.HostUserBan.Delete().Where(hostuserban.IDIn(ids...)).Exec(ctx); err != nil {
+		return false, fmt.Errorf("delete host bans: %w", err)
+	}
+	more = more || len(hostBans) == e.batch
+
+	hostMutes, err := tx.HostUserMute.Query().
+		Where(hostusermute.ExpiresAtLTE(now)).
+		WithUser().
+		Limit(e.batch).
+		ForUpdate(lock).
+		All(ctx)
+	if err != nil {
+		return false, fmt.Errorf("select host mutes: %w", err)
+	}
+	ids = make([]int, len(hostMutes))
+	for i, m := range hostMutes {
+		ids[i] = m.ID
+		if u := m.Edges.User; u != nil {
+			if !m.Shadow {
+				out = append(out, lifted{kind: model.SanctionHostMute, recipientID: u.ID})
+			}
+			entries = append(entries, modlog.Entry{
+				Type:       moderationaction.TypeUserUnmuted,
+				TargetType: moderationaction.TargetTypeUser, TargetID: u.ID, TargetUserID: &u.ID, Before: m,
+			})
+		}
+	}
+	if _, err := tx.HostUserMute.Delete().Where(hostusermute.IDIn(ids...)).Exec(ctx); err != nil {
+		return false, fmt.Errorf("delete host mutes: %w", err)
+	}
+	more = more || len(hostMutes) == e.batch
+
+	// Санкции сообществ на платформе: уведомляем владельца сообщества
+	communityHostBans, err := tx.HostCommunityBan.Query().
+		Where(hostcommunityban.ExpiresAtLTE(now)).
+		WithCommunity().
+		Limit(e.batch).
+		ForUpdate(lock).
+		All(ctx)
+	if err != nil {
+		return false, fmt.Errorf("select host community bans: %w", err)
+	}
+	ids = make([]int, len(communityHostBans))
+	for i, b := range communityHostBans {
+		ids[i] = b.ID
+		if c := b.Edges.Community; c != nil {
+			out = append(out, lifted{model.SanctionHostBan, c.OwnerID, &b.CommunityID, c.Title})
+		}
+		entries = append(entries, modlog.Entry{
+			Type: moderationaction.TypeCommunityUnbanned, CommunityID: &b.CommunityID,
+			TargetType: moderationaction.TargetTypeCommunity, TargetID: b.CommunityID, Before: b,
+		})
+	}
+	if _, err := tx.HostCommunityBan.Delete().Where(hostcommunityban.IDIn(ids...)).Exec(ctx); err != nil {
+		return false, fmt.Errorf("delete host community bans: %w", err)
+	}
+	more = more || len(communityHostBans) == e.batch
+
+	communityHostMutes, err := tx.HostCommunityMute.Query().
+		Where(hostcommunitymute.ExpiresAtLTE(now)).
+		WithCommunity().
+		Limit(e.batch).
+		ForUpdate(lock).
+		All(ctx)
+	if err != nil {
+		return false, fmt.Errorf("select host community mutes: %w", err)
+	}
+	ids = make([]int, len(communityHostMutes))
+	for i, m := range communityHostMutes {
+		ids[i] = m.ID
+		if c := m.Edges.Community; c != nil {
+			out = append(out, lifted{model.SanctionHostMute, c.OwnerID, &m.CommunityID, c.Title})
+		}
+		entries = append(entries, modlog.Entry{
+			Type: moderationaction.TypeCommunityUnmuted, CommunityID: &m.CommunityID,
+			TargetType: moderationaction.TargetTypeCommunity, TargetID: m.CommunityID, Before: m,
+		})
+	}
+	if _, err := tx.HostCommunityMute.Delete().Where(hostcommunitymute.IDIn(ids...)).Exec(ctx); err != nil {
+		return false, fmt.Errorf("delete host community mutes: %w", err)
+	}
+	more = more || len(communityHostMutes) == e.batch
+
+	// Баны по IP: уведомлять некого
+	ipBans, err := tx.HostIPBan.Query().
+		Where(hostipban.ExpiresAtLTE(now)).
+		Limit(e.batch).
+		ForUpdate(lock).
+		All(ctx)
+	if err != nil {
+		return false, fmt.Errorf("select ip bans: %w", err)
+	}
+	ids = make([]int, len(ipBans))
+	for i, b := range ipBans {
+		ids[i] = b.ID
+		entries = append(entries, modlog.Entry{
+			Type: moderationaction.TypeIPUnbanned, TargetType: moderationaction.TargetTypeIPBan, TargetID: b.ID, Before: b,
+		})
+	}
+	if _, err := tx.HostIPBan.Delete().Where(hostipban.IDIn(ids...)).Exec(ctx); err != nil {
+		return false, fmt.Errorf("delete ip bans: %w", err)
+	}
+	more = more || len(ipBans) == e.batch
+
+	for _, entry := range entries {
+		if err := modlog.Record(ctx, tx.ModerationAction, entry); err != nil {
+			return false, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("commit: %w", err)
+	}
+
+	for _, l := range out {
+		msg := liftedMessage(l)
+		if _, _, err := e.notify.Notify(ctx, notificationuc.Event{
+			RecipientID: l.recipientID,
+			Type:        notification.TypeModeration,
+			CommunityID: l.communityID,
+			Message:     &msg,
+		}); err != nil {
+			log.Printf("❌ Sanction expirer: notify user %d: %v", l.recipientID, err)
+		}
+	}
+	if len(out) > 0 {
+		log.Printf("⏳ Sanction expirer: lifted %d sanctions", len(out))
+	}
+	return more, nil
+}
+
+func communityTitle(c *ent.Community) string {
+	if c == nil {
+		return ""
+	}
+	return c.Title
+}
+
+// liftedMessage — текст уведомления о снятой по сроку санкции
+func liftedMessage(l lifted) string {
+	switch {
+	case l.kind == model.SanctionCommunityBan:
+		return fmt.Sprintf("Срок блокировки в сообществе «%s» истек", l.title)
+	case l.kind == model.SanctionCommunityMute:
+		return fmt.Sprintf("Вы снова можете писать в сообществе «%s»", l.title)
+	case l.kind == model.SanctionHostBan && l.communityID != nil:
+		return fmt.Sprintf("Срок блокировки сообщества «%s» на платформе истек", l.title)
+	case l.kind == model.SanctionHostMute && l.communityID != nil:
+		return fmt.Sprintf("Ограничения сообщества «%s» на платформе сняты", l.title)
+	case l.kind == model.SanctionHostBan:
+		return "Срок блокировки вашего аккаунта на платформе истек"
+	default:
+		return "Вы снова можете писать на платформе"
+	}
+}
@@ -0,0 +1,23 @@
+package sanctions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"stormlink/server/model"
+)
+
+func TestLiftedMessage(t *testing.T) {
+	community := 7
+	assert.Equal(t, "Срок блокировки в сообществе «Go» истек",
+		liftedMessage(lifted{kind: model.SanctionCommunityBan, communityID: &community, title: "Go"}))
+	assert.Equal(t, "Вы снова можете писать в сообществе «Go»",
+		liftedMessage(lifted{kind: model.SanctionCommunityMute, communityID: &community, title: "Go"}))
+	assert.Equal(t, "Срок блокировки сообщества «Go» на платформе истек",
+		liftedMessage(lifted{kind: model.SanctionHostBan, communityID: &community, title: "Go"}))
+	assert.Equal(t, "Срок блокировки вашего аккаунта на платформе истек",
+		liftedMessage(lifted{kind: model.SanctionHostBan}))
+	assert.Equal(t, "Вы снова можете писать на платформе",
+		liftedMessage(lifted{kind: model.SanctionHostMute}))
+}
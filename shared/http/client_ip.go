@@ -0,0 +1,73 @@
+package http
+
+import (
+	"net"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// ClientIP — IP клиента. По нему считаются лимиты анонимов, потоки SSE и баны по IP.
+// X-Forwarded-For учитывается, только если соединение пришло от доверенного прокси (TRUSTED_PROXIES):
+// цепочка разбирается справа налево, и клиент — первый адрес не из доверенных. Левые адреса
+// заголовка присылает сам клиент, поэтому им не верим. Без доверенных прокси — адрес соединения.
+func ClientIP(r *http.Request) string {
+	remote := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		remote = host
+	}
+	trusted := trustedProxies()
+	if !isTrusted(trusted, remote) {
+		return remote
+	}
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	client := remote
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if net.ParseIP(hop) == nil {
+			// Испорченную цепочку дальше не разбираем: левее может быть что угодно
+			break
+		}
+		client = hop
+		if !isTrusted(trusted, hop) {
+			break
+		}
+	}
+	return client
+}
+
+// trustedProxies — сети доверенных прокси из TRUSTED_PROXIES: адреса или CIDR через запятую.
+// Некорректные записи пропускаются.
+func trustedProxies() []*net.IPNet {
+	var nets []*net.IPNet
+	for _, s := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		if !strings.Contains(s, "/") {
+			if ip := net.ParseIP(s); ip != nil && ip.To4() != nil {
+				s += "/32"
+			} else {
+				s += "/128"
+			}
+		}
+		if _, n, err := net.ParseCIDR(s); err == nil {
+			nets = append(nets, n)
+		}
+	}
+	return nets
+}
+
+func isTrusted(nets []*net.IPNet, addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package http
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientIP(t *testing.T) {
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.0/8, 192.0.2.1")
+	cases := []struct {
+		name   string
+		remote string
+		xff    string
+		want   string
+	}{
+		{"no proxy", "198.51.100.1:1234", "", "198.51.100.1"},
+		{"untrusted connection ignores header", "198.51.100.1:1234", "203.0.113.5", "198.51.100.1"},
+		{"trusted proxy", "192.0.2.1:1234", "203.0.113.5", "203.0.113.5"},
+		{"chain of proxies", "192.0.2.1:1234", "203.0.113.5, 10.0.0.2, 10.0.0.1", "203.0.113.5"},
+		{"spoofed leftmost hop", "192.0.2.1:1234", "1.1.1.1, 203.0.113.5, 10.0.0.1", "203.0.113.5"},
+		{"garbage hop stops the walk", "192.0.2.1:1234", "1.1.1.1, junk, 10.0.0.1", "10.0.0.1"},
+		{"trusted proxy without header", "192.0.2.1:1234", "", "192.0.2.1"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tc.remote
+			if tc.xff != "" {
+				r.Header.Set("X-Forwarded-For", tc.xff)
+			}
+			assert.Equal(t, tc.want, ClientIP(r))
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package jobs
+
+import "time"
+
+const (
+    // DefaultMaxAttempts — число попыток по умолчанию, включая первую
+    DefaultMaxAttempts = 5
+)
+
+// DefaultBackoff: 10s, 20s, 40s, 80s ... но не больше 15 минут
+var DefaultBackoff = Backoff{Base: 10 * time.Second, Max: 15 * time.Minute}
+
+// Backoff — экспоненциальная задержка между попытками
+type Backoff struct {
+    Base time.Duration
+    Max  time.Duration
+}
+
+// Delay возвращает задержку перед попыткой attempt (попытки нумеруются с 1, retry начинается со 2-й)
+func (b Backoff) Delay(attempt int) time.Duration {
+    if attempt < 2 { return 0 }
+    base := b.Base
+    if base <= 0 { base = DefaultBackoff.Base }
+    d := base
+    for i := 2; i < attempt; i++ {
+        d *= 2
+        if b.Max > 0 && d >= b.Max { return b.Max }
+    }
+    if b.Max > 0 && d > b.Max { return b.Max }
+    return d
+}
+
+// Outcome — что делать с сообщением после обработки
+type Outcome int
+
+const (
+    OutcomeAck Outcome = iota
+    OutcomeRetry
+    OutcomeDead
+)
+
+// Decide определяет судьбу сообщения по результату попытки attempt
+func Decide(attempt, maxAttempts int, err error) Outcome {
+    if err == nil { return OutcomeAck }
+    if IsPermanent(err) { return OutcomeDead }
+    if attempt >= maxAttempts { return OutcomeDead }
+    return OutcomeRetry
+}
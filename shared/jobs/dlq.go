@@ -0,0 +1,75 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// DeadLetter — сообщение из DLQ в удобном для просмотра виде
+type DeadLetter struct {
+    MessageID string
+    Attempts  int
+    LastError string
+    FailedAt  time.Time
+    Body      []byte
+}
+
+func toDeadLetter(d amqp.Delivery) DeadLetter {
+    dl := DeadLetter{MessageID: d.MessageId, Attempts: Attempt(d.Headers), Body: d.Body}
+    if s, ok := d.Headers[HeaderLastError].(string); ok { dl.LastError = s }
+    if s, ok := d.Headers[HeaderFailedAt].(string); ok {
+        dl.FailedAt, _ = time.Parse(time.RFC3339, s)
+    }
+    return dl
+}
+
+// InspectDeadLetters читает до limit сообщений из DLQ без удаления (limit <= 0 — все)
+func InspectDeadLetters(ch *amqp.Channel, job string, limit int) ([]DeadLetter, error) {
+    var (
+        out     []DeadLetter
+        lastTag uint64
+    )
+    for limit <= 0 || len(out) < limit {
+        d, ok, err := ch.Get(DeadLetterQueueName(job), false)
+        if err != nil { return nil, fmt.Errorf("get from dlq: %w", err) }
+        if !ok { break }
+        lastTag = d.DeliveryTag
+        out = append(out, toDeadLetter(d))
+    }
+    // Возвращаем все прочитанные сообщения обратно в очередь
+    if lastTag > 0 {
+        if err := ch.Nack(lastTag, true, true); err != nil {
+            return nil, fmt.Errorf("requeue dlq messages: %w", err)
+        }
+    }
+    return out, nil
+}
+
+// ReplayDeadLetters перекладывает до limit сообщений из DLQ в основную очередь, сбрасывая счётчик попыток
+func ReplayDeadLetters(ctx context.Context, ch *amqp.Channel, job string, limit int) (int, error) {
+    n := 0
+    for limit <= 0 || n < limit {
+        d, ok, err := ch.Get(DeadLetterQueueName(job), false)
+        if err != nil { return n, fmt.Errorf("get from dlq: %w", err) }
+        if !ok { break }
+
+        msg := redeliver(d, amqp.Table{HeaderAttempt: int32(1)})
+        delete(msg.Headers, HeaderLastError)
+        delete(msg.Headers, HeaderFailedAt)
+        if err := ch.PublishWithContext(ctx, "", QueueName(job), false, false, msg); err != nil {
+            _ = d.Nack(false, true)
+            return n, fmt.Errorf("republish: %w", err)
+        }
+        if err := d.Ack(false); err != nil { return n, err }
+        n++
+    }
+    return n, nil
+}
+
+// PurgeDeadLetters удаляет все сообщения из DLQ
+func PurgeDeadLetters(ch *amqp.Channel, job string) (int, error) {
+    return ch.QueuePurge(DeadLetterQueueName(job), false)
+}
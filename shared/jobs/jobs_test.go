@@ -0,0 +1,71 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackoff_Delay(t *testing.T) {
+	b := Backoff{Base: time.Second, Max: 5 * time.Second}
+
+	assert.Equal(t, time.Duration(0), b.Delay(1))
+	assert.Equal(t, 1*time.Second, b.Delay(2))
+	assert.Equal(t, 2*time.Second, b.Delay(3))
+	assert.Equal(t, 4*time.Second, b.Delay(4))
+	assert.Equal(t, 5*time.Second, b.Delay(5))
+	assert.Equal(t, 5*time.Second, b.Delay(50))
+}
+
+func TestDecide(t *testing.T) {
+	fail := errors.New("smtp down")
+
+	assert.Equal(t, OutcomeAck, Decide(1, 3, nil))
+	assert.Equal(t, OutcomeRetry, Decide(1, 3, fail))
+	assert.Equal(t, OutcomeRetry, Decide(2, 3, fail))
+	assert.Equal(t, OutcomeDead, Decide(3, 3, fail))
+	assert.Equal(t, OutcomeDead, Decide(1, 3, Permanent(fail)))
+}
+
+func TestRegister_TypedHandler(t *testing.T) {
+	type payload struct {
+		To string `json:"to"`
+	}
+	reg := NewRegistry()
+	var got payload
+	Register(reg, "test", func(ctx context.Context, p payload) error {
+		got = p
+		return nil
+	}, WithMaxAttempts(3), WithConcurrency(2))
+
+	def, ok := reg.Get("test")
+	require.True(t, ok)
+	assert.Equal(t, 3, def.MaxAttempts)
+	assert.Equal(t, 2, def.Concurrency)
+
+	require.NoError(t, def.Handler(context.Background(), []byte(`{"to":"a@b.c"}`)))
+	assert.Equal(t, "a@b.c", got.To)
+
+	err := def.Handler(context.Background(), []byte(`not json`))
+	assert.True(t, IsPermanent(err))
+
+	assert.Panics(t, func() { reg.RegisterRaw("test", def.Handler) })
+}
+
+func TestCall_RecoversPanic(t *testing.T) {
+	err := call(context.Background(), func(ctx context.Context, body []byte) error { panic("boom") }, nil)
+	require.Error(t, err)
+	assert.False(t, IsPermanent(err))
+}
+
+func TestAttemptHeader(t *testing.T) {
+	assert.Equal(t, 1, Attempt(nil))
+	assert.Equal(t, 4, Attempt(amqp.Table{HeaderAttempt: int32(4)}))
+	assert.Equal(t, 2, Attempt(amqp.Table{HeaderAttempt: int64(2)}))
+	assert.Equal(t, "email_verification.retry.10s", RetryQueueName("email_verification", 10*time.Second))
+}
@@ -0,0 +1,112 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const (
+    // HeaderAttempt — номер текущей попытки (начиная с 1)
+    HeaderAttempt = "x-attempt"
+    // HeaderJob — имя задачи
+    HeaderJob = "x-job"
+    // HeaderLastError — текст ошибки последней попытки
+    HeaderLastError = "x-last-error"
+    // HeaderFailedAt — время попадания в DLQ (RFC3339)
+    HeaderFailedAt = "x-failed-at"
+)
+
+// QueueName — основная очередь задачи (совпадает с именем задачи)
+func QueueName(job string) string { return job }
+
+// DeadLetterQueueName — очередь «мёртвых» сообщений задачи
+func DeadLetterQueueName(job string) string { return job + ".dlq" }
+
+// RetryQueueName — очередь ожидания с TTL delay; по истечении сообщение возвращается в основную очередь.
+// Имя зависит от задержки, поэтому смена политики backoff не конфликтует с уже объявленными очередями.
+func RetryQueueName(job string, delay time.Duration) string {
+    return fmt.Sprintf("%s.retry.%s", job, delay)
+}
+
+// DeclareTopology объявляет основную очередь, очереди ожидания для каждой попытки и DLQ
+func DeclareTopology(ch *amqp.Channel, d *Definition) error {
+    if _, err := ch.QueueDeclare(QueueName(d.Name), true, false, false, false, nil); err != nil {
+        return fmt.Errorf("declare queue %s: %w", d.Name, err)
+    }
+    if _, err := ch.QueueDeclare(DeadLetterQueueName(d.Name), true, false, false, false, nil); err != nil {
+        return fmt.Errorf("declare dlq %s: %w", d.Name, err)
+    }
+    for attempt := 2; attempt <= d.MaxAttempts; attempt++ {
+        delay := d.Backoff.Delay(attempt)
+        args := amqp.Table{
+            "x-message-ttl":             delay.Milliseconds(),
+            "x-dead-letter-exchange":    "",
+            "x-dead-letter-routing-key": QueueName(d.Name),
+        }
+        if _, err := ch.QueueDeclare(RetryQueueName(d.Name, delay), true, false, false, false, args); err != nil {
+            return fmt.Errorf("declare retry queue %s: %w", d.Name, err)
+        }
+    }
+    return nil
+}
+
+// Enqueue публикует задачу в основную очередь; job сериализуется в JSON
+func Enqueue(ctx context.Context, ch *amqp.Channel, name string, job any) error {
+    body, err := json.Marshal(job)
+    if err != nil {
+        return fmt.Errorf("marshal %s: %w", name, err)
+    }
+    if _, err := ch.QueueDeclare(QueueName(name), true, false, false, false, nil); err != nil {
+        return fmt.Errorf("declare queue %s: %w", name, err)
+    }
+    return ch.PublishWithContext(ctx, "", QueueName(name), false, false, NewMessage(name, body))
+}
+
+// NewMessage формирует persistent-сообщение первой попытки задачи
+func NewMessage(name string, body []byte) amqp.Publishing {
+    return amqp.Publishing{
+        DeliveryMode: amqp.Persistent,
+        ContentType:  "application/json",
+        MessageId:    uuid.NewString(),
+        Timestamp:    time.Now(),
+        Headers:      amqp.Table{HeaderJob: name, HeaderAttempt: int32(1)},
+        Body:         body,
+    }
+}
+
+// Attempt извлекает номер попытки из заголовков (по умолчанию 1 — для сообщений без заголовка)
+func Attempt(h amqp.Table) int {
+    switch v := h[HeaderAttempt].(type) {
+    case int32:
+        return int(v)
+    case int64:
+        return int(v)
+    case int:
+        return v
+    case int16:
+        return int(v)
+    case int8:
+        return int(v)
+    }
+    return 1
+}
+
+// redeliver копирует сообщение с новыми заголовками
+func redeliver(d amqp.Delivery, headers amqp.Table) amqp.Publishing {
+    h := amqp.Table{}
+    for k, v := range d.Headers { h[k] = v }
+    for k, v := range headers { h[k] = v }
+    return amqp.Publishing{
+        DeliveryMode: amqp.Persistent,
+        ContentType:  d.ContentType,
+        MessageId:    d.MessageId,
+        Timestamp:    d.Timestamp,
+        Headers:      h,
+        Body:         d.Body,
+    }
+}
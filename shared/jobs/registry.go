@@ -0,0 +1,126 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Handler обрабатывает сырое тело задачи
+type Handler func(ctx context.Context, body []byte) error
+
+// Definition описывает зарегистрированный тип задачи
+type Definition struct {
+    Name        string
+    Handler     Handler
+    MaxAttempts int
+    Concurrency int
+    Backoff     Backoff
+}
+
+// Option настраивает параметры задачи при регистрации
+type Option func(*Definition)
+
+// WithMaxAttempts задаёт число попыток (включая первую) до отправки в DLQ
+func WithMaxAttempts(n int) Option {
+    return func(d *Definition) {
+        if n > 0 { d.MaxAttempts = n }
+    }
+}
+
+// WithConcurrency задаёт число параллельных обработчиков очереди
+func WithConcurrency(n int) Option {
+    return func(d *Definition) {
+        if n > 0 { d.Concurrency = n }
+    }
+}
+
+// WithBackoff задаёт базовую и максимальную задержку между попытками
+func WithBackoff(base, max time.Duration) Option {
+    return func(d *Definition) {
+        d.Backoff = Backoff{Base: base, Max: max}
+    }
+}
+
+// Registry хранит обработчики задач по имени
+type Registry struct {
+    mu   sync.RWMutex
+    defs map[string]*Definition
+}
+
+func NewRegistry() *Registry {
+    return &Registry{defs: make(map[string]*Definition)}
+}
+
+// Register регистрирует типизированный обработчик: тело задачи декодируется из JSON в T.
+// Некорректный JSON считается неисправимой ошибкой и сразу уходит в DLQ.
+func Register[T any](r *Registry, name string, fn func(ctx context.Context, job T) error, opts ...Option) {
+    r.RegisterRaw(name, func(ctx context.Context, body []byte) error {
+        var job T
+        if err := json.Unmarshal(body, &job); err != nil {
+            return Permanent(fmt.Errorf("decode %s: %w", name, err))
+        }
+        return fn(ctx, job)
+    }, opts...)
+}
+
+// RegisterRaw регистрирует обработчик без декодирования тела
+func (r *Registry) RegisterRaw(name string, h Handler, opts ...Option) {
+    if name == "" || h == nil {
+        panic("jobs: empty name or nil handler")
+    }
+    d := &Definition{
+        Name:        name,
+        Handler:     h,
+        MaxAttempts: DefaultMaxAttempts,
+        Concurrency: 1,
+        Backoff:     DefaultBackoff,
+    }
+    for _, o := range opts { o(d) }
+
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    if _, exists := r.defs[name]; exists {
+        panic("jobs: duplicate job " + name)
+    }
+    r.defs[name] = d
+}
+
+// Get возвращает описание задачи по имени
+func (r *Registry) Get(name string) (*Definition, bool) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+    d, ok := r.defs[name]
+    return d, ok
+}
+
+// Definitions возвращает все задачи, отсортированные по имени
+func (r *Registry) Definitions() []*Definition {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+    out := make([]*Definition, 0, len(r.defs))
+    for _, d := range r.defs { out = append(out, d) }
+    sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+    return out
+}
+
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent помечает ошибку как неисправимую: задача не будет повторяться
+func Permanent(err error) error {
+    if err == nil { return nil }
+    return &permanentError{err: err}
+}
+
+// IsPermanent сообщает, помечена ли ошибка как неисправимая
+func IsPermanent(err error) bool {
+    var pe *permanentError
+    return errors.As(err, &pe)
+}
@@ -0,0 +1,130 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Worker потребляет очереди всех зарегистрированных задач
+type Worker struct {
+    conn *amqp.Connection
+    reg  *Registry
+
+    pubMu sync.Mutex
+    pub   *amqp.Channel
+}
+
+func NewWorker(conn *amqp.Connection, reg *Registry) *Worker {
+    return &Worker{conn: conn, reg: reg}
+}
+
+// Run объявляет топологию, запускает обработчики и блокируется до отмены контекста или разрыва соединения
+func (w *Worker) Run(ctx context.Context) error {
+    defs := w.reg.Definitions()
+    if len(defs) == 0 {
+        return errors.New("jobs: no handlers registered")
+    }
+
+    pub, err := w.conn.Channel()
+    if err != nil { return err }
+    defer pub.Close()
+    w.pub = pub
+
+    var wg sync.WaitGroup
+    for _, def := range defs {
+        ch, err := w.conn.Channel()
+        if err != nil { return err }
+        defer ch.Close()
+
+        if err := DeclareTopology(ch, def); err != nil { return err }
+        if err := ch.Qos(def.Concurrency, 0, false); err != nil { return err }
+
+        msgs, err := ch.Consume(QueueName(def.Name), "", false, false, false, false, nil)
+        if err != nil { return fmt.Errorf("consume %s: %w", def.Name, err) }
+
+        for i := 0; i < def.Concurrency; i++ {
+            wg.Add(1)
+            go func(def *Definition) {
+                defer wg.Done()
+                w.loop(ctx, def, msgs)
+            }(def)
+        }
+        log.Printf("📬 jobs: %s (attempts=%d, concurrency=%d) waiting messages...", def.Name, def.MaxAttempts, def.Concurrency)
+    }
+
+    closed := w.conn.NotifyClose(make(chan *amqp.Error, 1))
+    select {
+    case <-ctx.Done():
+        wg.Wait()
+        return nil
+    case e := <-closed:
+        if e == nil { return nil }
+        return fmt.Errorf("rabbitmq connection closed: %w", e)
+    }
+}
+
+func (w *Worker) loop(ctx context.Context, def *Definition, msgs <-chan amqp.Delivery) {
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case d, ok := <-msgs:
+            if !ok { return }
+            w.handle(ctx, def, d)
+        }
+    }
+}
+
+func (w *Worker) handle(ctx context.Context, def *Definition, d amqp.Delivery) {
+    attempt := Attempt(d.Headers)
+    err := call(ctx, def.Handler, d.Body)
+
+    switch Decide(attempt, def.MaxAttempts, err) {
+    case OutcomeAck:
+        _ = d.Ack(false)
+        return
+    case OutcomeRetry:
+        next := attempt + 1
+        delay := def.Backoff.Delay(next)
+        log.Printf("⚠️ jobs: %s attempt %d/%d failed, retry in %s: %v", def.Name, attempt, def.MaxAttempts, delay, err)
+        msg := redeliver(d, amqp.Table{HeaderAttempt: int32(next), HeaderLastError: err.Error()})
+        w.forward(ctx, d, RetryQueueName(def.Name, delay), msg)
+    case OutcomeDead:
+        log.Printf("❌ jobs: %s attempt %d/%d failed, moving to DLQ: %v", def.Name, attempt, def.MaxAttempts, err)
+        msg := redeliver(d, amqp.Table{
+            HeaderLastError: err.Error(),
+            HeaderFailedAt:  time.Now().UTC().Format(time.RFC3339),
+        })
+        w.forward(ctx, d, DeadLetterQueueName(def.Name), msg)
+    }
+}
+
+// forward перекладывает сообщение в другую очередь и подтверждает оригинал.
+// Если публикация не удалась — возвращаем оригинал в очередь, чтобы не потерять его.
+func (w *Worker) forward(ctx context.Context, d amqp.Delivery, queue string, msg amqp.Publishing) {
+    w.pubMu.Lock()
+    err := w.pub.PublishWithContext(ctx, "", queue, false, false, msg)
+    w.pubMu.Unlock()
+    if err != nil {
+        log.Printf("❌ jobs: failed to publish to %s: %v", queue, err)
+        _ = d.Nack(false, true)
+        return
+    }
+    _ = d.Ack(false)
+}
+
+// call вызывает обработчик, превращая панику в ошибку
+func call(ctx context.Context, h Handler, body []byte) (err error) {
+    defer func() {
+        if r := recover(); r != nil {
+            err = fmt.Errorf("panic: %v", r)
+        }
+    }()
+    return h(ctx, body)
+}
@@ -83,3 +83,33 @@ func ParseRefreshToken(tokenString string) (*RefreshTokenClaims, error) {
 }
 
 
+
+// Токены отписки живут долго: ссылки из писем должны работать и через несколько месяцев
+const unsubscribeTokenTTL = 180 * 24 * time.Hour
+
+type UnsubscribeTokenClaims struct {
+    UserID   int
+    Category string
+}
+
+// GenerateUnsubscribeToken подписывает ссылку отписки пользователя от категории писем
+func GenerateUnsubscribeToken(userID int, category string) (string, error) {
+    claims := jwt.MapClaims{
+        "user_id":  strconv.Itoa(userID),
+        "category": category,
+        "exp":      time.Now().Add(unsubscribeTokenTTL).Unix(),
+        "type":     "unsubscribe",
+    }
+    token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+    return token.SignedString(getJWTSecret())
+}
+
+func ParseUnsubscribeToken(tokenString string) (*UnsubscribeTokenClaims, error) {
+    claims, err := ParseToken(tokenString)
+    if err != nil { return nil, err }
+    t, ok := claims["type"].(string); if !ok || t != "unsubscribe" { return nil, errors.New("invalid token type") }
+    uid, ok := claims["user_id"].(string); if !ok { return nil, errors.New("user_id not found or invalid") }
+    id, err := strconv.Atoi(uid); if err != nil { return nil, errors.New("invalid user_id format") }
+    category, ok := claims["category"].(string); if !ok || category == "" { return nil, errors.New("category not found or invalid") }
+    return &UnsubscribeTokenClaims{UserID: id, Category: category}, nil
+}
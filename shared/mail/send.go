@@ -3,33 +3,57 @@ package mail
 import (
 	"fmt"
 	"log"
+	"mime"
 	"net/smtp"
 	"os"
+	"strings"
 )
 
-func SendVerifyEmail(to, token string) error {
+// Send отправляет HTML-письмо; headers добавляются к стандартным заголовкам (например, List-Unsubscribe)
+func Send(to, subject, html string, headers map[string]string) error {
     config := NewEmailConfig()
-    publicURL := os.Getenv("APP_PUBLIC_URL")
-    if publicURL == "" { publicURL = "http://localhost:3000" }
-    verificationLink := fmt.Sprintf("%s/verify-email?token=%s", publicURL, token)
 
-    subject := "Subject: Подтверждение почты\r\n"
-    mime := "MIME-version: 1.0;\r\nContent-Type: text/html; charset=\"UTF-8\";\r\n\r\n"
-    body := fmt.Sprintf(`
-        <h2>Подтверждение почты</h2>
-        <p>Подтвердите свою почту, перейдя по ссылке:</p>
-        <a href="%s">Подтвердить почту</a>
-        <p>Эта ссылка будет доступна следующие 24 часа.</p>
-    `, verificationLink)
-    message := []byte(subject + mime + body)
+    var b strings.Builder
+    fmt.Fprintf(&b, "From: %s\r\n", config.FromEmail)
+    fmt.Fprintf(&b, "To: %s\r\n", to)
+    fmt.Fprintf(&b, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", subject))
+    for k, v := range headers {
+        fmt.Fprintf(&b, "%s: %s\r\n", k, v)
+    }
+    b.WriteString("MIME-version: 1.0;\r\nContent-Type: text/html; charset=\"UTF-8\";\r\n\r\n")
+    b.WriteString(html)
 
     addr := fmt.Sprintf("%s:%d", config.SMTPHost, config.SMTPPort)
     auth := smtp.PlainAuth("", config.SMTPUsername, config.SMTPPassword, config.SMTPHost)
-    if err := smtp.SendMail(addr, auth, config.FromEmail, []string{to}, message); err != nil {
+    if err := smtp.SendMail(addr, auth, config.FromEmail, []string{to}, []byte(b.String())); err != nil {
         log.Printf("Ошибка отправки письма на %s: %v", to, err)
         return err
     }
     return nil
 }
 
+// PublicURL — адрес фронтенда для ссылок в письмах
+func PublicURL() string {
+    publicURL := os.Getenv("APP_PUBLIC_URL")
+    if publicURL == "" { publicURL = "http://localhost:3000" }
+    return strings.TrimRight(publicURL, "/")
+}
+
+// APIPublicURL — публичный адрес GraphQL-сервера (для one-click отписки)
+func APIPublicURL() string {
+    apiURL := os.Getenv("API_PUBLIC_URL")
+    if apiURL == "" { apiURL = "http://localhost:8080" }
+    return strings.TrimRight(apiURL, "/")
+}
+
+func SendVerifyEmail(to, token string) error {
+    verificationLink := fmt.Sprintf("%s/verify-email?token=%s", PublicURL(), token)
 
+    body := fmt.Sprintf(`
+        <h2>Подтверждение почты</h2>
+        <p>Подтвердите свою почту, перейдя по ссылке:</p>
+        <a href="%s">Подтвердить почту</a>
+        <p>Эта ссылка будет доступна следующие 24 часа.</p>
+    `, verificationLink)
+    return Send(to, "Подтверждение почты", body, nil)
+}
@@ -1,9 +1,11 @@
 package mapper
 
 import (
+	"slices"
 	"strconv"
 	"time"
 
+	"stormlink/server/authz"
 	"stormlink/server/ent"
 	authpb "stormlink/server/grpc/auth/protobuf"
 )
@@ -26,12 +28,12 @@ func UserToProto(u *ent.User) *authpb.User {
                 Id:   strconv.Itoa(role.ID),
                 Title: role.Title,
                 Color: *role.Color,
-                CommunityRolesManagement: role.CommunityRolesManagement,
-                HostUserBan: role.HostUserBan,
-                HostUserMute: role.HostUserMute,
-                HostCommunityDeletePost: role.HostCommunityDeletePost,
-                HostCommunityDeleteComments: role.HostCommunityDeleteComments,
-                HostCommunityRemovePostFromPublication: role.HostCommunityRemovePostFromPublication,
+                CommunityRolesManagement: hasPermission(role.Permissions, authz.ManageRoles),
+                HostUserBan: hasPermission(role.Permissions, authz.HostBanUser),
+                HostUserMute: hasPermission(role.Permissions, authz.HostMuteUser),
+                HostCommunityDeletePost: hasPermission(role.Permissions, authz.DeletePost),
+                HostCommunityDeleteComments: hasPermission(role.Permissions, authz.DeleteComment),
+                HostCommunityRemovePostFromPublication: hasPermission(role.Permissions, authz.UnpublishPost),
             })
         }
     }
@@ -42,12 +44,12 @@ func UserToProto(u *ent.User) *authpb.User {
                 Id:   strconv.Itoa(role.ID),
                 Title: role.Title,
                 Color: *role.Color,
-                CommunityRolesManagement: role.CommunityRolesManagement,
-                CommunityUserBan: role.CommunityUserBan,
-                CommunityUserMute: role.CommunityUserMute,
-                CommunityDeletePost: role.CommunityDeletePost,
-                CommunityDeleteComments: role.CommunityDeleteComments,
-                CommunityRemovePostFromPublication: role.CommunityRemovePostFromPublication,
+                CommunityRolesManagement: hasPermission(role.Permissions, authz.ManageRoles),
+                CommunityUserBan: hasPermission(role.Permissions, authz.BanUser),
+                CommunityUserMute: hasPermission(role.Permissions, authz.MuteUser),
+                CommunityDeletePost: hasPermission(role.Permissions, authz.DeletePost),
+                CommunityDeleteComments: hasPermission(role.Permissions, authz.DeleteComment),
+                CommunityRemovePostFromPublication: hasPermission(role.Permissions, authz.UnpublishPost),
             })
         }
     }
@@ -67,4 +69,7 @@ func UserToProto(u *ent.User) *authpb.User {
     }
 }
 
-
+// hasPermission — флаг прав для gRPC-ответа из набора permissions роли
+func hasPermission(permissions []string, action authz.Action) bool {
+    return slices.Contains(permissions, string(action))
+}
@@ -0,0 +1,29 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"stormlink/server/ent"
+)
+
+// Add записывает сообщение для брокера в таблицу outbox.
+// Передавайте клиент транзакции (tx.Outbox), чтобы сообщение сохранялось атомарно с доменными изменениями:
+// если транзакция откатится — сообщение не будет опубликовано, если зафиксируется — relay доставит его.
+func Add(ctx context.Context, c *ent.OutboxClient, topic string, msg any) error {
+    return AddAt(ctx, c, topic, msg, time.Now())
+}
+
+// AddAt как Add, но relay опубликует сообщение не раньше момента at (отложенная доставка)
+func AddAt(ctx context.Context, c *ent.OutboxClient, topic string, msg any, at time.Time) error {
+    payload, err := json.Marshal(msg)
+    if err != nil {
+        return fmt.Errorf("marshal outbox message %s: %w", topic, err)
+    }
+    if err := c.Create().SetTopic(topic).SetPayload(payload).SetAvailableAt(at).Exec(ctx); err != nil {
+        return fmt.Errorf("save outbox message %s: %w", topic, err)
+    }
+    return nil
+}
@@ -0,0 +1,50 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"stormlink/tests/testhelper"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdd_IsBoundToTransaction(t *testing.T) {
+	helper := testhelper.NewPostgresTestHelper(t)
+	defer helper.Cleanup()
+	helper.WaitForDatabase(t)
+	helper.CleanDatabase(t)
+
+	client := helper.GetClient()
+	ctx := context.Background()
+	msg := map[string]string{"to": "user@example.com", "token": "abc"}
+
+	t.Run("rollback drops message", func(t *testing.T) {
+		tx, err := client.Tx(ctx)
+		require.NoError(t, err)
+		require.NoError(t, Add(ctx, tx.Outbox, "email_verification", msg))
+		require.NoError(t, tx.Rollback())
+
+		count, err := client.Outbox.Query().Count(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+	})
+
+	t.Run("commit keeps pending message", func(t *testing.T) {
+		tx, err := client.Tx(ctx)
+		require.NoError(t, err)
+		require.NoError(t, Add(ctx, tx.Outbox, "email_verification", msg))
+		require.NoError(t, tx.Commit())
+
+		row, err := client.Outbox.Query().Only(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "email_verification", row.Topic)
+		assert.Nil(t, row.SentAt)
+
+		var got map[string]string
+		require.NoError(t, json.Unmarshal(row.Payload, &got))
+		assert.Equal(t, msg, got)
+	})
+}
@@ -0,0 +1,250 @@
+package presence
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"stormlink/shared/pubsub"
+)
+
+// Эфемерное присутствие в обсуждениях постов: кто читает и кто пишет ответ.
+// Ничего не пишется в БД: каждая живая подписка периодически публикует heartbeat в брокер,
+// а состояние собирается из событий последних TTL секунд (журнал брокера отдаёт их новому зрителю).
+
+const (
+    DefaultTTL          = 45 * time.Second
+    DefaultHeartbeat    = 15 * time.Second
+    DefaultTypingTTL    = 6 * time.Second
+    DefaultMinInterval  = time.Second
+    DefaultTypingRepeat = 2 * time.Second
+)
+
+const (
+    actionHeartbeat = "hb"
+    actionTyping    = "typing"
+    actionIdle      = "idle"
+    actionLeave     = "leave"
+)
+
+// message — событие присутствия в брокере
+type message struct {
+    Key    string `json:"k,omitempty"` // сессия зрителя (подписка); у typing/idle не задан
+    UserID int    `json:"u,omitempty"`
+    Action string `json:"a"`
+    At     int64  `json:"t"` // unix ms
+}
+
+// State — присутствие в обсуждении поста
+type State struct {
+    Readers int
+    Typing  []int // ID пользователей, набирающих ответ
+}
+
+// Tracker публикует и собирает присутствие через брокер подписок (memory или Redis)
+type Tracker struct {
+    Broker pubsub.Broker
+    // TTL — через сколько без heartbeat сессия считается ушедшей
+    TTL time.Duration
+    // Heartbeat — период heartbeat живой подписки
+    Heartbeat time.Duration
+    // TypingTTL — сколько держится индикатор набора без повторного setTyping
+    TypingTTL time.Duration
+    // MinInterval — не чаще одного обновления состояния подписчику
+    MinInterval time.Duration
+    // TypingRepeat — повторные setTyping(true) одного пользователя чаще этого отбрасываются
+    TypingRepeat time.Duration
+
+    mu      sync.Mutex
+    lastTyp map[string]time.Time
+}
+
+func New(b pubsub.Broker) *Tracker {
+    return &Tracker{
+        Broker:       b,
+        TTL:          DefaultTTL,
+        Heartbeat:    DefaultHeartbeat,
+        TypingTTL:    DefaultTypingTTL,
+        MinInterval:  DefaultMinInterval,
+        TypingRepeat: DefaultTypingRepeat,
+        lastTyp:      map[string]time.Time{},
+    }
+}
+
+func topic(postID int) string { return fmt.Sprintf("presence.post.%d", postID) }
+
+func newSessionKey() string {
+    b := make([]byte, 8)
+    _, _ = rand.Read(b)
+    return hex.EncodeToString(b)
+}
+
+func (t *Tracker) publish(ctx context.Context, postID int, m message) error {
+    m.At = time.Now().UnixMilli()
+    payload, err := json.Marshal(m)
+    if err != nil { return err }
+    _, err = t.Broker.Publish(ctx, topic(postID), payload)
+    return err
+}
+
+// SetTyping включает или выключает индикатор набора ответа.
+// Повторные включения чаще TypingRepeat не публикуются — клиент может вызывать его на каждое нажатие клавиши.
+func (t *Tracker) SetTyping(ctx context.Context, postID, userID int, typing bool) error {
+    if userID <= 0 { return errors.New("presence: typing requires a user") }
+    key := fmt.Sprintf("%d:%d", postID, userID)
+    now := time.Now()
+
+    t.mu.Lock()
+    last, ok := t.lastTyp[key]
+    if typing && ok && now.Sub(last) < t.TypingRepeat {
+        t.mu.Unlock()
+        return nil
+    }
+    if typing {
+        t.lastTyp[key] = now
+    } else {
+        delete(t.lastTyp, key)
+    }
+    // Чистим устаревшие отметки, чтобы карта не росла
+    if len(t.lastTyp) > 10000 {
+        for k, v := range t.lastTyp {
+            if now.Sub(v) > t.TypingTTL { delete(t.lastTyp, k) }
+        }
+    }
+    t.mu.Unlock()
+
+    action := actionIdle
+    if typing { action = actionTyping }
+    return t.publish(ctx, postID, message{UserID: userID, Action: action})
+}
+
+// Watch регистрирует зрителя в обсуждении (userID 0 — аноним) и возвращает канал состояний.
+// Пока ctx жив, зритель шлёт heartbeat; при отмене ctx публикуется уход и канал закрывается.
+func (t *Tracker) Watch(ctx context.Context, postID, userID int) (<-chan State, error) {
+    key := newSessionKey()
+
+    // Повторяем события за последние TTL: так новый зритель сразу видит уже присутствующих
+    since := fmt.Sprintf("%d-0", time.Now().Add(-t.TTL).UnixMilli())
+    events, err := t.Broker.Subscribe(ctx, topic(postID), since)
+    if errors.Is(err, pubsub.ErrReplayUnavailable) {
+        // Журнал уже вытеснен (очень оживлённый пост) — остальные появятся со следующим heartbeat
+        events, err = t.Broker.Subscribe(ctx, topic(postID), "")
+    }
+    if err != nil { return nil, fmt.Errorf("presence subscribe: %w", err) }
+
+    hb := message{Key: key, UserID: userID, Action: actionHeartbeat}
+    if err := t.publish(ctx, postID, hb); err != nil { return nil, fmt.Errorf("presence heartbeat: %w", err) }
+
+    out := make(chan State, 1)
+    go t.run(ctx, postID, hb, events, out)
+    return out, nil
+}
+
+func (t *Tracker) run(ctx context.Context, postID int, hb message, events <-chan pubsub.Event, out chan<- State) {
+    defer close(out)
+    defer func() {
+        // ctx уже отменён — уход публикуем с отдельным коротким контекстом
+        lctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+        defer cancel()
+        if err := t.publish(lctx, postID, message{Key: hb.Key, UserID: hb.UserID, Action: actionLeave}); err != nil {
+            log.Printf("⚠️ presence: leave post %d: %v", postID, err)
+        }
+    }()
+
+    sessions := map[string]time.Time{} // сессия → истечение
+    owners := map[string]int{}         // сессия → пользователь (0 — аноним)
+    typing := map[int]time.Time{}      // пользователь → истечение индикатора
+
+    heartbeat := time.NewTicker(t.Heartbeat)
+    defer heartbeat.Stop()
+    // Тикер одновременно ограничивает частоту обновлений и убирает истёкшие сессии
+    tick := time.NewTicker(t.MinInterval)
+    defer tick.Stop()
+
+    var sent *State
+    dirty := true
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case <-heartbeat.C:
+            if err := t.publish(ctx, postID, hb); err != nil && ctx.Err() == nil {
+                log.Printf("⚠️ presence: heartbeat post %d: %v", postID, err)
+            }
+        case ev, ok := <-events:
+            if !ok { return }
+            var m message
+            if err := json.Unmarshal(ev.Payload, &m); err != nil { continue }
+            at := time.UnixMilli(m.At)
+            switch m.Action {
+            case actionHeartbeat:
+                sessions[m.Key] = at.Add(t.TTL)
+                owners[m.Key] = m.UserID
+            case actionLeave:
+                delete(sessions, m.Key)
+                delete(owners, m.Key)
+            case actionTyping:
+                typing[m.UserID] = at.Add(t.TypingTTL)
+            case actionIdle:
+                delete(typing, m.UserID)
+            }
+            dirty = true
+        case now := <-tick.C:
+            for k, until := range sessions {
+                if !until.After(now) {
+                    delete(sessions, k)
+                    delete(owners, k)
+                    dirty = true
+                }
+            }
+            for id, until := range typing {
+                if !until.After(now) {
+                    delete(typing, id)
+                    dirty = true
+                }
+            }
+            if !dirty { continue }
+            dirty = false
+            st := snapshot(owners, typing)
+            if sent != nil && equal(*sent, st) { continue }
+            select {
+            case out <- st:
+                sent = &st
+            case <-ctx.Done():
+                return
+            }
+        }
+    }
+}
+
+// snapshot считает читателей: вкладки одного пользователя — один читатель, каждый аноним — отдельный
+func snapshot(owners map[string]int, typing map[int]time.Time) State {
+    users := map[int]struct{}{}
+    st := State{Typing: []int{}}
+    for _, id := range owners {
+        if id == 0 {
+            st.Readers++
+        } else if _, ok := users[id]; !ok {
+            users[id] = struct{}{}
+            st.Readers++
+        }
+    }
+    for id := range typing { st.Typing = append(st.Typing, id) }
+    sort.Ints(st.Typing)
+    return st
+}
+
+func equal(a, b State) bool {
+    if a.Readers != b.Readers || len(a.Typing) != len(b.Typing) { return false }
+    for i := range a.Typing {
+        if a.Typing[i] != b.Typing[i] { return false }
+    }
+    return true
+}
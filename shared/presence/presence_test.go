@@ -0,0 +1,118 @@
+package presence
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"stormlink/shared/pubsub"
+)
+
+func newTestTracker() *Tracker {
+	t := New(pubsub.NewMemoryBroker(pubsub.DefaultBuffer))
+	t.TTL = 300 * time.Millisecond
+	t.Heartbeat = 100 * time.Millisecond
+	t.TypingTTL = 200 * time.Millisecond
+	t.MinInterval = 10 * time.Millisecond
+	t.TypingRepeat = 50 * time.Millisecond
+	return t
+}
+
+// waitState ждёт состояние, удовлетворяющее условию
+func waitState(t *testing.T, ch <-chan State, cond func(State) bool) State {
+	t.Helper()
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case st, ok := <-ch:
+			require.True(t, ok, "channel closed")
+			if cond(st) {
+				return st
+			}
+		case <-timeout:
+			t.Fatal("state not reached")
+			return State{}
+		}
+	}
+}
+
+func TestWatchCountsReaders(t *testing.T) {
+	tr := newTestTracker()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	a, err := tr.Watch(ctx, 1, 10)
+	require.NoError(t, err)
+	waitState(t, a, func(s State) bool { return s.Readers == 1 })
+
+	// Новый зритель сразу видит уже присутствующих (повтор журнала), вкладки одного пользователя не удваиваются
+	bctx, bcancel := context.WithCancel(ctx)
+	b, err := tr.Watch(bctx, 1, 20)
+	require.NoError(t, err)
+	waitState(t, b, func(s State) bool { return s.Readers == 2 })
+	_, err = tr.Watch(ctx, 1, 20)
+	require.NoError(t, err)
+	_, err = tr.Watch(ctx, 1, 0)
+	require.NoError(t, err)
+	waitState(t, a, func(s State) bool { return s.Readers == 3 })
+
+	// Уход одной вкладки не убирает пользователя с другой открытой вкладкой
+	bcancel()
+	select {
+	case st := <-a:
+		assert.Equal(t, 3, st.Readers)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestSessionExpiresWithoutHeartbeat(t *testing.T) {
+	tr := newTestTracker()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	a, err := tr.Watch(ctx, 1, 10)
+	require.NoError(t, err)
+	// Сессия, от которой перестали приходить heartbeat (упавшая реплика), исчезает по TTL
+	require.NoError(t, tr.publish(ctx, 1, message{Key: "ghost", UserID: 99, Action: actionHeartbeat}))
+	waitState(t, a, func(s State) bool { return s.Readers == 2 })
+	waitState(t, a, func(s State) bool { return s.Readers == 1 })
+}
+
+func TestTypingIndicator(t *testing.T) {
+	tr := newTestTracker()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	a, err := tr.Watch(ctx, 1, 10)
+	require.NoError(t, err)
+
+	require.NoError(t, tr.SetTyping(ctx, 1, 20, true))
+	waitState(t, a, func(s State) bool { return assert.ObjectsAreEqual([]int{20}, s.Typing) })
+	// Индикатор гаснет сам по TypingTTL
+	waitState(t, a, func(s State) bool { return len(s.Typing) == 0 })
+
+	require.NoError(t, tr.SetTyping(ctx, 1, 20, true))
+	waitState(t, a, func(s State) bool { return len(s.Typing) == 1 })
+	require.NoError(t, tr.SetTyping(ctx, 1, 20, false))
+	waitState(t, a, func(s State) bool { return len(s.Typing) == 0 })
+
+	assert.Error(t, tr.SetTyping(ctx, 1, 0, true))
+}
+
+func TestSetTypingThrottled(t *testing.T) {
+	b := pubsub.NewMemoryBroker(pubsub.DefaultBuffer)
+	tr := New(b)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := b.Subscribe(ctx, topic(1), "")
+	require.NoError(t, err)
+	for i := 0; i < 5; i++ {
+		require.NoError(t, tr.SetTyping(ctx, 1, 20, true))
+	}
+	time.Sleep(20 * time.Millisecond)
+	assert.Len(t, events, 1)
+}
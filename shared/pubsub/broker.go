@@ -0,0 +1,53 @@
+package pubsub
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync/atomic"
+
+	redisx "stormlink/shared/redis"
+)
+
+// DefaultBuffer — сколько сообщений может накопить подписчик, прежде чем будет признан медленным
+const DefaultBuffer = 64
+
+// Broker доставляет события подписок между репликами GraphQL-сервера.
+// Сообщения — сериализованные DTO (обычно ID сущности); получатель перечитывает данные с правами зрителя.
+// Последние события каждого топика хранятся в ограниченном журнале для повторной доставки после переподключения.
+type Broker interface {
+    // Publish сохраняет событие в журнал топика и рассылает подписчикам; возвращает ID события
+    Publish(ctx context.Context, topic string, payload []byte) (string, error)
+    // Subscribe возвращает канал событий топика; канал закрывается после отмены ctx.
+    // Если since не пуст, сначала отдаются события журнала после since (ErrReplayUnavailable — они уже вытеснены или журнал истёк).
+    Subscribe(ctx context.Context, topic string, since string) (<-chan Event, error)
+    Close() error
+}
+
+var dropped atomic.Int64
+
+// Dropped — сколько сообщений потеряно из-за медленных подписчиков с момента запуска
+func Dropped() int64 { return dropped.Load() }
+
+// OnSlowSubscriber вызывается, когда буфер подписчика переполнен и сообщение отброшено;
+// n — сколько сообщений потерял этот подписчик. По умолчанию пишет в лог (первый раз и каждые 100).
+var OnSlowSubscriber = func(topic string, n int64) {
+    if n == 1 || n%100 == 0 {
+        log.Printf("⚠️ pubsub: slow subscriber on %q, dropped %d message(s)", topic, n)
+    }
+}
+
+// NewFromEnv создаёт брокер по ENV PUBSUB_BROKER: redis — общий для всех реплик, иначе in-memory
+func NewFromEnv() (Broker, error) {
+    switch os.Getenv("PUBSUB_BROKER") {
+    case "redis":
+        rdb, err := redisx.NewClient()
+        if err != nil { return nil, err }
+        return NewRedisBroker(rdb, "stormlink:pubsub:")
+    case "", "memory":
+        return NewMemoryBroker(DefaultBuffer), nil
+    default:
+        return nil, fmt.Errorf("unknown PUBSUB_BROKER %q", os.Getenv("PUBSUB_BROKER"))
+    }
+}
@@ -0,0 +1,106 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultLogSize — сколько последних событий каждого топика хранится для повторной доставки
+const DefaultLogSize = 256
+
+// DefaultRetention — сколько хранится журнал топика после последнего события
+const DefaultRetention = time.Hour
+
+// ErrReplayUnavailable — событие since уже вытеснено из журнала: клиенту нужно перезапросить данные целиком
+var ErrReplayUnavailable = errors.New("pubsub: events since the given ID are no longer available")
+
+// Event — событие топика. ID монотонно растёт в пределах топика и имеет формат Redis Streams: "<ms>-<seq>".
+type Event struct {
+    ID      string
+    Payload []byte
+}
+
+// ParseID разбирает ID события
+func ParseID(id string) (ms, seq uint64, err error) {
+    a, b, ok := strings.Cut(id, "-")
+    if ms, err = strconv.ParseUint(a, 10, 64); err != nil {
+        return 0, 0, fmt.Errorf("invalid event id %q", id)
+    }
+    if ok {
+        if seq, err = strconv.ParseUint(b, 10, 64); err != nil {
+            return 0, 0, fmt.Errorf("invalid event id %q", id)
+        }
+    }
+    return ms, seq, nil
+}
+
+// CompareIDs сравнивает ID событий (некорректные ID считаются меньше любых)
+func CompareIDs(a, b string) int {
+    ams, aseq, aerr := ParseID(a)
+    bms, bseq, berr := ParseID(b)
+    switch {
+    case aerr != nil && berr != nil:
+        return 0
+    case aerr != nil:
+        return -1
+    case berr != nil:
+        return 1
+    case ams != bms:
+        if ams < bms { return -1 }
+        return 1
+    case aseq != bseq:
+        if aseq < bseq { return -1 }
+        return 1
+    }
+    return 0
+}
+
+// idGenerator выдаёт монотонные ID по времени, как XADD в Redis
+type idGenerator struct {
+    mu     sync.Mutex
+    lastMs uint64
+    seq    uint64
+}
+
+func (g *idGenerator) next(now time.Time) string {
+    g.mu.Lock()
+    defer g.mu.Unlock()
+    ms := uint64(now.UnixMilli())
+    if ms > g.lastMs {
+        g.lastMs, g.seq = ms, 0
+    } else {
+        g.seq++
+    }
+    return fmt.Sprintf("%d-%d", g.lastMs, g.seq)
+}
+
+// replayThenLive отдаёт сначала пропущенные события, затем живые, отбрасывая дубли (ID <= последнего отданного)
+func replayThenLive(ctx context.Context, replay []Event, live <-chan Event) <-chan Event {
+    out := make(chan Event)
+    go func() {
+        defer close(out)
+        last := ""
+        send := func(ev Event) bool {
+            if last != "" && CompareIDs(ev.ID, last) <= 0 { return true }
+            select {
+            case out <- ev:
+                last = ev.ID
+                return true
+            case <-ctx.Done():
+                return false
+            }
+        }
+        for _, ev := range replay {
+            if !send(ev) { return }
+        }
+        for ev := range live {
+            if !send(ev) { return }
+        }
+    }()
+    return out
+}
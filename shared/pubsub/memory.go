@@ -0,0 +1,156 @@
+package pubsub
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type subscriber struct {
+    ch      chan Event
+    dropped int64
+}
+
+// topicLog — кольцевой журнал последних событий топика
+type topicLog struct {
+    events  []Event
+    evicted string // ID последнего вытесненного события
+    last    time.Time
+}
+
+// MemoryBroker — брокер в памяти процесса: для одной реплики и тестов
+type MemoryBroker struct {
+    mu        sync.RWMutex
+    subs      map[string]map[*subscriber]struct{}
+    logs      map[string]*topicLog
+    ids       idGenerator
+    buffer    int
+    logSize   int
+    retention time.Duration
+    publishes int
+    closed    bool
+}
+
+func NewMemoryBroker(buffer int) *MemoryBroker {
+    if buffer <= 0 { buffer = DefaultBuffer }
+    return &MemoryBroker{
+        subs:      map[string]map[*subscriber]struct{}{},
+        logs:      map[string]*topicLog{},
+        buffer:    buffer,
+        logSize:   DefaultLogSize,
+        retention: DefaultRetention,
+    }
+}
+
+func (b *MemoryBroker) Publish(_ context.Context, topic string, payload []byte) (string, error) {
+    now := time.Now()
+    ev := Event{ID: b.ids.next(now), Payload: payload}
+
+    b.mu.Lock()
+    defer b.mu.Unlock()
+    l := b.logs[topic]
+    if l == nil {
+        l = &topicLog{}
+        b.logs[topic] = l
+    }
+    l.events = append(l.events, ev)
+    if len(l.events) > b.logSize {
+        l.evicted = l.events[0].ID
+        l.events = append(l.events[:0:0], l.events[1:]...)
+    }
+    l.last = now
+
+    // Периодически удаляем журналы топиков без событий дольше retention
+    if b.publishes++; b.publishes%1000 == 0 {
+        for t, l := range b.logs {
+            if now.Sub(l.last) > b.retention { delete(b.logs, t) }
+        }
+    }
+
+    b.deliverLocked(topic, ev)
+    return ev.ID, nil
+}
+
+// deliver раздаёт событие локальным подписчикам, не блокируясь на медленных
+func (b *MemoryBroker) deliver(topic string, ev Event) {
+    b.mu.RLock()
+    defer b.mu.RUnlock()
+    b.deliverLocked(topic, ev)
+}
+
+func (b *MemoryBroker) deliverLocked(topic string, ev Event) {
+    for s := range b.subs[topic] {
+        select {
+        case s.ch <- ev:
+        default:
+            s.dropped++
+            dropped.Add(1)
+            OnSlowSubscriber(topic, s.dropped)
+        }
+    }
+}
+
+func (b *MemoryBroker) Subscribe(ctx context.Context, topic string, since string) (<-chan Event, error) {
+    s := &subscriber{ch: make(chan Event, b.buffer)}
+
+    // Регистрация подписчика и снимок журнала под одной блокировкой: без пропусков и дублей
+    b.mu.Lock()
+    if b.closed {
+        b.mu.Unlock()
+        close(s.ch)
+        return s.ch, nil
+    }
+    var replay []Event
+    if since != "" {
+        var err error
+        if replay, err = b.logs[topic].since(since); err != nil {
+            b.mu.Unlock()
+            return nil, err
+        }
+    }
+    if b.subs[topic] == nil { b.subs[topic] = map[*subscriber]struct{}{} }
+    b.subs[topic][s] = struct{}{}
+    b.mu.Unlock()
+
+    go func() {
+        <-ctx.Done()
+        b.mu.Lock()
+        defer b.mu.Unlock()
+        if _, ok := b.subs[topic][s]; !ok { return }
+        delete(b.subs[topic], s)
+        if len(b.subs[topic]) == 0 { delete(b.subs, topic) }
+        close(s.ch)
+    }()
+
+    if since == "" { return s.ch, nil }
+    return replayThenLive(ctx, replay, s.ch), nil
+}
+
+// since возвращает события после ID since
+func (l *topicLog) since(since string) ([]Event, error) {
+    if _, _, err := ParseID(since); err != nil { return nil, err }
+    // Журнала нет (истёк по retention или брокер перезапущен), а клиент уже получал события
+    // топика: что было после since, неизвестно
+    if l == nil { return nil, ErrReplayUnavailable }
+    if l.evicted != "" && CompareIDs(since, l.evicted) < 0 {
+        return nil, ErrReplayUnavailable
+    }
+    for i, ev := range l.events {
+        if CompareIDs(ev.ID, since) > 0 {
+            return append([]Event(nil), l.events[i:]...), nil
+        }
+    }
+    return nil, nil
+}
+
+// Close закрывает каналы всех подписчиков
+func (b *MemoryBroker) Close() error {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+    for topic, subs := range b.subs {
+        for s := range subs { close(s.ch) }
+        delete(b.subs, topic)
+    }
+    b.closed = true
+    return nil
+}
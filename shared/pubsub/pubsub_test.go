@@ -0,0 +1,155 @@
+package pubsub
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func receive(t *testing.T, ch <-chan Event) Event {
+	t.Helper()
+	select {
+	case ev := <-ch:
+		return ev
+	case <-time.After(time.Second):
+		t.Fatal("event not received")
+		return Event{}
+	}
+}
+
+func publish(t *testing.T, b Broker, topic, payload string) string {
+	t.Helper()
+	id, err := b.Publish(context.Background(), topic, []byte(payload))
+	require.NoError(t, err)
+	return id
+}
+
+func TestMemoryBrokerFanOut(t *testing.T) {
+	b := NewMemoryBroker(4)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	a, err := b.Subscribe(ctx, "comment.added", "")
+	require.NoError(t, err)
+	c, err := b.Subscribe(ctx, "comment.added", "")
+	require.NoError(t, err)
+	other, err := b.Subscribe(ctx, "comment.updated", "")
+	require.NoError(t, err)
+
+	id := publish(t, b, "comment.added", "1")
+	assert.Equal(t, Event{ID: id, Payload: []byte("1")}, receive(t, a))
+	assert.Equal(t, Event{ID: id, Payload: []byte("1")}, receive(t, c))
+	assert.Len(t, other, 0)
+}
+
+func TestMemoryBrokerUnsubscribeOnCancel(t *testing.T) {
+	b := NewMemoryBroker(4)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ch, err := b.Subscribe(ctx, "t", "")
+	require.NoError(t, err)
+	cancel()
+
+	select {
+	case _, ok := <-ch:
+		assert.False(t, ok, "channel must be closed")
+	case <-time.After(time.Second):
+		t.Fatal("channel not closed after cancel")
+	}
+	publish(t, b, "t", "x")
+}
+
+func TestMemoryBrokerReportsSlowSubscriber(t *testing.T) {
+	var reported []int64
+	prev := OnSlowSubscriber
+	OnSlowSubscriber = func(topic string, n int64) { reported = append(reported, n) }
+	defer func() { OnSlowSubscriber = prev }()
+
+	b := NewMemoryBroker(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, err := b.Subscribe(ctx, "t", "")
+	require.NoError(t, err)
+
+	before := Dropped()
+	for i := 0; i < 3; i++ {
+		publish(t, b, "t", "x")
+	}
+	assert.Equal(t, []int64{1, 2}, reported)
+	assert.Equal(t, before+2, Dropped())
+}
+
+func TestMemoryBrokerReplaySince(t *testing.T) {
+	b := NewMemoryBroker(8)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	first := publish(t, b, "t", "1")
+	publish(t, b, "t", "2")
+	publish(t, b, "t", "3")
+
+	ch, err := b.Subscribe(ctx, "t", first)
+	require.NoError(t, err)
+	assert.Equal(t, "2", string(receive(t, ch).Payload))
+	assert.Equal(t, "3", string(receive(t, ch).Payload))
+
+	// После повтора подписка переходит в живой режим
+	publish(t, b, "t", "4")
+	assert.Equal(t, "4", string(receive(t, ch).Payload))
+}
+
+func TestMemoryBrokerReplayUnavailable(t *testing.T) {
+	b := NewMemoryBroker(8)
+	b.logSize = 2
+
+	first := publish(t, b, "t", "1")
+	second := publish(t, b, "t", "2")
+	publish(t, b, "t", "3")
+	publish(t, b, "t", "4")
+
+	_, err := b.Subscribe(context.Background(), "t", first)
+	assert.ErrorIs(t, err, ErrReplayUnavailable)
+
+	// Последнее вытесненное событие клиент уже получил — пропусков нет
+	ch, err := b.Subscribe(context.Background(), "t", second)
+	require.NoError(t, err)
+	assert.Equal(t, "3", string(receive(t, ch).Payload))
+}
+
+func TestMemoryBrokerReplayExpiredLog(t *testing.T) {
+	b := NewMemoryBroker(8)
+	b.retention = 0
+
+	first := publish(t, b, "t", "1")
+	// Журналы без событий дольше retention удаляются раз в 1000 публикаций
+	for i := 1; i < 1000; i++ {
+		publish(t, b, "other", "x")
+	}
+
+	_, err := b.Subscribe(context.Background(), "t", first)
+	assert.ErrorIs(t, err, ErrReplayUnavailable)
+
+	// Без since подписка на топик с истекшим журналом работает как обычно
+	ch, err := b.Subscribe(context.Background(), "t", "")
+	require.NoError(t, err)
+	publish(t, b, "t", "2")
+	assert.Equal(t, "2", string(receive(t, ch).Payload))
+}
+
+func TestEventIDs(t *testing.T) {
+	var g idGenerator
+	now := time.UnixMilli(1000)
+	a, b := g.next(now), g.next(now)
+	c := g.next(now.Add(-time.Second)) // часы ушли назад — ID всё равно растут
+	assert.Equal(t, "1000-0", a)
+	assert.Equal(t, -1, CompareIDs(a, b))
+	assert.Equal(t, -1, CompareIDs(b, c))
+	assert.Equal(t, 1, CompareIDs("1001-0", "1000-5"))
+	assert.Equal(t, 0, CompareIDs("7", "7-0"))
+
+	_, _, err := ParseID("bogus")
+	assert.Error(t, err)
+}
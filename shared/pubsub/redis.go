@@ -0,0 +1,115 @@
+package pubsub
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	redis "github.com/redis/go-redis/v9"
+)
+
+// RedisBroker публикует события так, чтобы их получили подписчики на всех репликах:
+// журнал топика — Redis Stream (XADD MAXLEN ~), живая доставка — Redis Pub/Sub.
+// Каждая реплика держит одно соединение PSUBSCRIBE на prefix* и раздаёт события локальным подписчикам.
+type RedisBroker struct {
+    rdb    *redis.Client
+    prefix string
+    ps     *redis.PubSub
+    local  *MemoryBroker
+    done   chan struct{}
+}
+
+func NewRedisBroker(rdb *redis.Client, prefix string) (*RedisBroker, error) {
+    ctx := context.Background()
+    ps := rdb.PSubscribe(ctx, prefix+"*")
+    // Дожидаемся подтверждения подписки, чтобы не потерять первые события
+    if _, err := ps.Receive(ctx); err != nil {
+        _ = ps.Close()
+        return nil, fmt.Errorf("redis psubscribe: %w", err)
+    }
+    b := &RedisBroker{rdb: rdb, prefix: prefix, ps: ps, local: NewMemoryBroker(DefaultBuffer), done: make(chan struct{})}
+    go b.run()
+    log.Printf("📡 pubsub: redis broker subscribed to %s*", prefix)
+    return b, nil
+}
+
+func (b *RedisBroker) streamKey(topic string) string { return b.prefix + "log:" + topic }
+
+// run раздаёт сообщения Pub/Sub вида "<id>\n<payload>" локальным подписчикам
+func (b *RedisBroker) run() {
+    defer close(b.done)
+    // Channel() сам переподключается после разрыва соединения
+    for msg := range b.ps.Channel(redis.WithChannelSize(1000)) {
+        id, payload, ok := strings.Cut(msg.Payload, "\n")
+        if !ok { continue }
+        b.local.deliver(strings.TrimPrefix(msg.Channel, b.prefix), Event{ID: id, Payload: []byte(payload)})
+    }
+}
+
+func (b *RedisBroker) Publish(ctx context.Context, topic string, payload []byte) (string, error) {
+    key := b.streamKey(topic)
+    id, err := b.rdb.XAdd(ctx, &redis.XAddArgs{
+        Stream: key,
+        MaxLen: DefaultLogSize,
+        Approx: true,
+        Values: map[string]any{"p": payload},
+    }).Result()
+    if err != nil {
+        return "", fmt.Errorf("redis xadd %s: %w", topic, err)
+    }
+    pipe := b.rdb.Pipeline()
+    pipe.Expire(ctx, key, DefaultRetention)
+    pipe.Publish(ctx, b.prefix+topic, id+"\n"+string(payload))
+    if _, err := pipe.Exec(ctx); err != nil {
+        return id, fmt.Errorf("redis publish %s: %w", topic, err)
+    }
+    return id, nil
+}
+
+func (b *RedisBroker) Subscribe(ctx context.Context, topic string, since string) (<-chan Event, error) {
+    // Сначала подписываемся на живые события, потом читаем журнал: дубли отсеет replayThenLive
+    live, err := b.local.Subscribe(ctx, topic, "")
+    if err != nil || since == "" {
+        return live, err
+    }
+    replay, err := b.replay(ctx, topic, since)
+    if err != nil {
+        return nil, err
+    }
+    return replayThenLive(ctx, replay, live), nil
+}
+
+// replay читает из журнала события после since
+func (b *RedisBroker) replay(ctx context.Context, topic, since string) ([]Event, error) {
+    if _, _, err := ParseID(since); err != nil { return nil, err }
+    key := b.streamKey(topic)
+
+    // Если журнал уже обрезан дальше since — часть событий после него потеряна (Redis 7+)
+    info, err := b.rdb.XInfoStream(ctx, key).Result()
+    if err != nil {
+        // Журнала нет (истёк по retention), а клиент уже получал события топика:
+        // что было после since, неизвестно
+        if strings.Contains(err.Error(), "no such key") { return nil, ErrReplayUnavailable }
+        return nil, fmt.Errorf("redis xinfo %s: %w", topic, err)
+    }
+    if info.MaxDeletedEntryID != "" && info.MaxDeletedEntryID != "0-0" && CompareIDs(since, info.MaxDeletedEntryID) < 0 {
+        return nil, ErrReplayUnavailable
+    }
+
+    msgs, err := b.rdb.XRange(ctx, key, "("+since, "+").Result()
+    if err != nil { return nil, fmt.Errorf("redis xrange %s: %w", topic, err) }
+    out := make([]Event, 0, len(msgs))
+    for _, m := range msgs {
+        p, _ := m.Values["p"].(string)
+        out = append(out, Event{ID: m.ID, Payload: []byte(p)})
+    }
+    return out, nil
+}
+
+func (b *RedisBroker) Close() error {
+    err := b.ps.Close()
+    <-b.done
+    _ = b.local.Close()
+    return err
+}
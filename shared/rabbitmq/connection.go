@@ -0,0 +1,97 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Connection — долгоживущее подключение к RabbitMQ с каналом в режиме publisher confirms.
+// При разрыве соединение и канал пересоздаются при следующей публикации.
+type Connection struct {
+    url string
+
+    mu       sync.Mutex
+    conn     *amqp.Connection
+    ch       *amqp.Channel
+    declared map[string]struct{}
+}
+
+func NewConnection(url string) *Connection {
+    return &Connection{url: url}
+}
+
+// channel возвращает живой канал, при необходимости переподключаясь. Вызывать под mu.
+func (c *Connection) channel() (*amqp.Channel, error) {
+    if c.ch != nil && !c.ch.IsClosed() && c.conn != nil && !c.conn.IsClosed() {
+        return c.ch, nil
+    }
+    c.reset()
+
+    conn, err := amqp.Dial(c.url)
+    if err != nil {
+        return nil, fmt.Errorf("не удалось подключиться: %w", err)
+    }
+    ch, err := conn.Channel()
+    if err != nil {
+        _ = conn.Close()
+        return nil, fmt.Errorf("не удалось создать канал: %w", err)
+    }
+    if err := ch.Confirm(false); err != nil {
+        _ = conn.Close()
+        return nil, fmt.Errorf("не удалось включить publisher confirms: %w", err)
+    }
+    c.conn, c.ch = conn, ch
+    c.declared = make(map[string]struct{})
+    log.Println("🐇 RabbitMQ: соединение установлено")
+    return ch, nil
+}
+
+func (c *Connection) reset() {
+    if c.ch != nil { _ = c.ch.Close() }
+    if c.conn != nil { _ = c.conn.Close() }
+    c.ch, c.conn, c.declared = nil, nil, nil
+}
+
+// PublishConfirmed публикует сообщение в durable-очередь и ждёт подтверждения брокера
+func (c *Connection) PublishConfirmed(ctx context.Context, queue string, msg amqp.Publishing) error {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    ch, err := c.channel()
+    if err != nil { return err }
+
+    if _, ok := c.declared[queue]; !ok {
+        if _, err := ch.QueueDeclare(queue, true, false, false, false, nil); err != nil {
+            c.reset()
+            return fmt.Errorf("не удалось объявить очередь %s: %w", queue, err)
+        }
+        c.declared[queue] = struct{}{}
+    }
+
+    dc, err := ch.PublishWithDeferredConfirmWithContext(ctx, "", queue, false, false, msg)
+    if err != nil {
+        c.reset()
+        return fmt.Errorf("не удалось опубликовать сообщение: %w", err)
+    }
+    acked, err := dc.WaitContext(ctx)
+    if err != nil {
+        c.reset()
+        return fmt.Errorf("не дождались подтверждения: %w", err)
+    }
+    if !acked {
+        return errors.New("брокер отклонил сообщение (nack)")
+    }
+    return nil
+}
+
+// Close закрывает соединение
+func (c *Connection) Close() {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    c.reset()
+}
@@ -0,0 +1,226 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	gqlclient "github.com/99designs/gqlgen/client"
+	"github.com/stretchr/testify/suite"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"stormlink/server/ent"
+	"stormlink/server/ent/automodrule"
+	"stormlink/server/ent/moderationaction"
+	"stormlink/server/ent/report"
+	"stormlink/server/graphql"
+	automodruleuc "stormlink/server/usecase/automodrule"
+	banuc "stormlink/server/usecase/ban"
+	notificationuc "stormlink/server/usecase/notification"
+	postuc "stormlink/server/usecase/post"
+	reportuc "stormlink/server/usecase/report"
+	"stormlink/shared/pubsub"
+	"stormlink/tests/fixtures"
+	"stormlink/tests/testhelper"
+	"stormlink/tests/testhelper/gqltest"
+)
+
+const createCommentMutation = `mutation($author: ID!, $community: ID!, $post: ID!, $text: String!) {
+	createComment(input: { authorID: $author, communityID: $community, postID: $post, content: $text }) { id }
+}`
+
+type AutomodTestSuite struct {
+	suite.Suite
+	ctx    context.Context
+	helper *testhelper.PostgresTestHelper
+}
+
+func (suite *AutomodTestSuite) SetupSuite() {
+	suite.ctx = context.Background()
+	suite.helper = testhelper.NewPostgresTestHelper(suite.T())
+	suite.helper.WaitForDatabase(suite.T())
+}
+
+func (suite *AutomodTestSuite) TearDownSuite() {
+	if suite.helper != nil {
+		suite.helper.Cleanup()
+	}
+}
+
+// TestAutomodRules — правило reject отклоняет комментарий, правило hold задерживает его
+// до разбора жалобы автомодерации; отклонение жалобы выпускает комментарий
+func (suite *AutomodTestSuite) TestAutomodRules() {
+	suite.helper.CleanDatabase(suite.T())
+	client := suite.helper.GetClient()
+	now := time.Now()
+	newUser := func(name string) *ent.User {
+		u, err := fixtures.CreateTestUser(suite.ctx, client, fixtures.UserFixture{
+			Name: name, Slug: fixtures.RandomSlug(), Email: fixtures.RandomEmail(),
+			Password: "password123", Salt: "salt", IsVerified: true, CreatedAt: now,
+		})
+		suite.Require().NoError(err)
+		return u
+	}
+	owner, author := newUser("Owner"), newUser("Author")
+	community, err := fixtures.CreateTestCommunity(suite.ctx, client, fixtures.CommunityFixture{
+		Name: "Community", Slug: fixtures.RandomSlug(), OwnerID: owner.ID, CreatedAt: now,
+	})
+	suite.Require().NoError(err)
+	post, err := fixtures.CreateTestPost(suite.ctx, client, fixtures.PostFixture{
+		Title: "Post", Content: "content", CommunityID: community.ID, AuthorID: owner.ID, CreatedAt: now,
+	})
+	suite.Require().NoError(err)
+
+	c := gqltest.NewClient(&graphql.Resolver{
+		Client:         client,
+		AutomodRuleUC:  automodruleuc.NewAutomodRuleUsecase(client),
+		ReportUC:       reportuc.NewReportUsecase(client),
+		BanUC:          banuc.NewBanUsecase(client),
+		NotificationUC: notificationuc.NewNotificationUsecase(client),
+		Broker:         pubsub.NewMemoryBroker(16),
+	})
+
+	createRule := `mutation($c: ID!, $kind: AutomodRuleKind!, $action: AutomodRuleAction!, $p: [String!]) {
+		createAutomodRule(input: { communityID: $c, title: "rule", kind: $kind, action: $action, patterns: $p }) { id }
+	}`
+	var rule struct{ CreateAutomodRule struct{ ID string } }
+	suite.Require().NoError(c.Post(createRule, &rule, gqlclient.Var("c", community.ID),
+		gqlclient.Var("kind", "keywords"), gqlclient.Var("action", "reject"), gqlclient.Var("p", []string{"казино"}), gqltest.As(owner.ID)))
+	suite.Require().NoError(c.Post(createRule, &rule, gqlclient.Var("c", community.ID),
+		gqlclient.Var("kind", "link_domains"), gqlclient.Var("action", "hold"), gqlclient.Var("p", []string{"promo.example"}), gqltest.As(owner.ID)))
+
+	// Управлять правилами может только модератор сообщества
+	raw, err := c.RawPost(createRule, gqlclient.Var("c", community.ID),
+		gqlclient.Var("kind", "keywords"), gqlclient.Var("action", "reject"), gqlclient.Var("p", []string{"x"}), gqltest.As(author.ID))
+	suite.Require().NoError(err)
+	suite.NotEmpty(raw.Errors)
+
+	// Пробный прогон не записывает публикацию
+	var tested struct {
+		TestAutomod struct {
+			Action  *string
+			Matches []struct{ Reason string }
+		}
+	}
+	suite.Require().NoError(c.Post(`query($c: ID!, $a: ID) { testAutomod(communityID: $c, text: "лучшее казино", authorID: $a) { action matches { reason } } }`,
+		&tested, gqlclient.Var("c", community.ID), gqlclient.Var("a", author.ID), gqltest.As(owner.ID)))
+	suite.Require().NotNil(tested.TestAutomod.Action)
+	suite.Equal("reject", *tested.TestAutomod.Action)
+	suite.Len(tested.TestAutomod.Matches, 1)
+
+	rejected, err := c.RawPost(createCommentMutation, gqlclient.Var("author", author.ID),
+		gqlclient.Var("community", community.ID), gqlclient.Var("post", post.ID),
+		gqlclient.Var("text", "лучшее казино"), gqltest.As(author.ID))
+	suite.Require().NoError(err)
+	var errs gqlerror.List
+	suite.Require().NoError(json.Unmarshal(rejected.Errors, &errs))
+	suite.Require().Len(errs, 1)
+	suite.Equal("automod", errs[0].Extensions["reason"])
+
+	var created struct{ CreateComment struct{ ID string } }
+	suite.Require().NoError(c.Post(createCommentMutation, &created, gqlclient.Var("author", author.ID),
+		gqlclient.Var("community", community.ID), gqlclient.Var("post", post.ID),
+		gqlclient.Var("text", "заходите на https://promo.example/sale"), gqltest.As(author.ID)))
+	commentID, err := strconv.Atoi(created.CreateComment.ID)
+	suite.Require().NoError(err)
+	stored, err := client.Comment.Get(suite.ctx, commentID)
+	suite.Require().NoError(err)
+	suite.True(stored.Held)
+
+	rep, err := client.Report.Query().
+		Where(report.TargetTypeEQ(report.TargetTypeComment), report.TargetIDEQ(commentID)).
+		Only(suite.ctx)
+	suite.Require().NoError(err)
+	suite.Nil(rep.ReporterID)
+	suite.Equal(report.ReasonAutomod, rep.Reason)
+	suite.Equal(report.QueueCommunity, rep.Queue)
+
+	triggered, err := client.ModerationAction.Query().
+		Where(moderationaction.TypeEQ(moderationaction.TypeAutomodTriggered)).
+		Count(suite.ctx)
+	suite.Require().NoError(err)
+	suite.Equal(2, triggered)
+
+	suite.Require().NoError(c.Post(`mutation($id: ID!) { dismissReport(id: $id) { id } }`,
+		&struct{}{}, gqlclient.Var("id", rep.ID), gqltest.As(owner.ID)))
+	stored, err = client.Comment.Get(suite.ctx, commentID)
+	suite.Require().NoError(err)
+	suite.False(stored.Held)
+}
+
+// TestAutomodPostEdit — правка заголовка или текста поста проходит те же правила, что и
+// создание: reject отклоняет правку, hold задерживает опубликованный пост до разбора жалобы
+func (suite *AutomodTestSuite) TestAutomodPostEdit() {
+	suite.helper.CleanDatabase(suite.T())
+	client := suite.helper.GetClient()
+	now := time.Now()
+	newUser := func(name string) *ent.User {
+		u, err := fixtures.CreateTestUser(suite.ctx, client, fixtures.UserFixture{
+			Name: name, Slug: fixtures.RandomSlug(), Email: fixtures.RandomEmail(),
+			Password: "password123", Salt: "salt", IsVerified: true, CreatedAt: now,
+		})
+		suite.Require().NoError(err)
+		return u
+	}
+	owner, author := newUser("Owner"), newUser("Author")
+	community, err := fixtures.CreateTestCommunity(suite.ctx, client, fixtures.CommunityFixture{
+		Name: "Community", Slug: fixtures.RandomSlug(), OwnerID: owner.ID, CreatedAt: now,
+	})
+	suite.Require().NoError(err)
+	created, err := fixtures.CreateTestPost(suite.ctx, client, fixtures.PostFixture{
+		Title: "Post", Content: "content", CommunityID: community.ID, AuthorID: author.ID, CreatedAt: now,
+	})
+	suite.Require().NoError(err)
+	suite.Require().NoError(created.Update().SetVisibility("published").Exec(suite.ctx))
+	for _, r := range []struct {
+		kind    automodrule.Kind
+		action  automodrule.Action
+		pattern string
+	}{
+		{automodrule.KindKeywords, automodrule.ActionReject, "казино"},
+		{automodrule.KindLinkDomains, automodrule.ActionHold, "promo.example"},
+	} {
+		suite.Require().NoError(client.AutomodRule.Create().
+			SetCommunityID(community.ID).SetTitle("rule").SetKind(r.kind).SetAction(r.action).
+			SetPatterns([]string{r.pattern}).Exec(suite.ctx))
+	}
+
+	c := gqltest.NewClient(&graphql.Resolver{
+		Client:         client,
+		PostUC:         postuc.NewPostUsecase(client),
+		ReportUC:       reportuc.NewReportUsecase(client),
+		NotificationUC: notificationuc.NewNotificationUsecase(client),
+		Broker:         pubsub.NewMemoryBroker(16),
+	})
+	editPost := `mutation($id: ID!, $title: String, $content: JSON) {
+		post(input: { id: $id, title: $title, content: $content }) { id }
+	}`
+
+	rejected, err := c.RawPost(editPost, gqlclient.Var("id", created.ID),
+		gqlclient.Var("title", "лучшее казино"), gqltest.As(author.ID))
+	suite.Require().NoError(err)
+	var errs gqlerror.List
+	suite.Require().NoError(json.Unmarshal(rejected.Errors, &errs))
+	suite.Require().Len(errs, 1)
+	suite.Equal("automod", errs[0].Extensions["reason"])
+	stored, err := client.Post.Get(suite.ctx, created.ID)
+	suite.Require().NoError(err)
+	suite.Equal("Post", stored.Title)
+
+	suite.Require().NoError(c.Post(editPost, &struct{}{}, gqlclient.Var("id", created.ID),
+		gqlclient.Var("content", map[string]any{"text": "заходите на https://promo.example/sale"}), gqltest.As(author.ID)))
+	stored, err = client.Post.Get(suite.ctx, created.ID)
+	suite.Require().NoError(err)
+	suite.Equal("held", stored.Visibility.String())
+	exists, err := client.Report.Query().
+		Where(report.TargetTypeEQ(report.TargetTypePost), report.TargetIDEQ(created.ID), report.ReasonEQ(report.ReasonAutomod)).
+		Exist(suite.ctx)
+	suite.Require().NoError(err)
+	suite.True(exists)
+}
+
+func TestAutomodTestSuite(t *testing.T) {
+	suite.Run(t, new(AutomodTestSuite))
+}
@@ -0,0 +1,226 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	gqlclient "github.com/99designs/gqlgen/client"
+	"github.com/stretchr/testify/suite"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"stormlink/server/authz"
+	"stormlink/server/ent"
+	"stormlink/server/ent/banappeal"
+	"stormlink/server/ent/communityuserban"
+	"stormlink/server/ent/notification"
+	"stormlink/server/graphql"
+	"stormlink/server/model"
+	banuc "stormlink/server/usecase/ban"
+	banappealuc "stormlink/server/usecase/banappeal"
+	notificationuc "stormlink/server/usecase/notification"
+	"stormlink/shared/pubsub"
+	"stormlink/tests/fixtures"
+	"stormlink/tests/testhelper"
+	"stormlink/tests/testhelper/gqltest"
+)
+
+const appealBanMutation = `mutation($id: ID!) {
+	appealBan(input: { scope: community, banID: $id, message: "Это была ошибка" }) { id status }
+}`
+
+type BanAppealsTestSuite struct {
+	suite.Suite
+	ctx    context.Context
+	helper *testhelper.PostgresTestHelper
+}
+
+func (suite *BanAppealsTestSuite) SetupSuite() {
+	suite.ctx = context.Background()
+	suite.helper = testhelper.NewPostgresTestHelper(suite.T())
+	suite.helper.WaitForDatabase(suite.T())
+}
+
+func (suite *BanAppealsTestSuite) TearDownSuite() {
+	if suite.helper != nil {
+		suite.helper.Cleanup()
+	}
+}
+
+// TestAppealOverride — апелляция подается один раз на бан; отказ модератора сообщества
+// пересматривает персонал платформы, принятие снимает бан и уведомляет автора апелляции
+func (suite *BanAppealsTestSuite) TestAppealOverride() {
+	suite.helper.CleanDatabase(suite.T())
+	client := suite.helper.GetClient()
+	now := time.Now()
+	newUser := func(name string) *ent.User {
+		u, err := fixtures.CreateTestUser(suite.ctx, client, fixtures.UserFixture{
+			Name: name, Slug: fixtures.RandomSlug(), Email: fixtures.RandomEmail(),
+			Password: "password123", Salt: "salt", IsVerified: true, CreatedAt: now,
+		})
+		suite.Require().NoError(err)
+		return u
+	}
+	owner, banned, staff := newUser("Owner"), newUser("Banned"), newUser("Staff")
+	community, err := fixtures.CreateTestCommunity(suite.ctx, client, fixtures.CommunityFixture{
+		Name: "Community", Slug: fixtures.RandomSlug(), OwnerID: owner.ID, CreatedAt: now,
+	})
+	suite.Require().NoError(err)
+	_, err = client.HostRole.Create().
+		SetTitle("Staff").
+		SetPermissions([]string{string(authz.HostBanUser)}).
+		AddUserIDs(staff.ID).
+		Save(suite.ctx)
+	suite.Require().NoError(err)
+
+	bans := banuc.NewBanUsecase(client)
+	ban, err := bans.BanUserFromCommunity(suite.ctx, banned.ID, community.ID, model.SanctionDetails{Reason: "spam", IssuedBy: owner.ID})
+	suite.Require().NoError(err)
+
+	c := gqltest.NewClient(&graphql.Resolver{
+		Client:         client,
+		BanUC:          bans,
+		BanAppealUC:    banappealuc.NewBanAppealUsecase(client),
+		NotificationUC: notificationuc.NewNotificationUsecase(client),
+		Broker:         pubsub.NewMemoryBroker(16),
+	})
+
+	var appeal struct {
+		AppealBan struct {
+			ID     string
+			Status string
+		}
+	}
+	suite.Require().NoError(c.Post(appealBanMutation, &appeal, gqlclient.Var("id", ban.ID), gqltest.As(banned.ID)))
+	suite.Equal("pending", appeal.AppealBan.Status)
+
+	raw, err := c.RawPost(appealBanMutation, gqlclient.Var("id", ban.ID), gqltest.As(banned.ID))
+	suite.Require().NoError(err)
+	var errs gqlerror.List
+	suite.Require().NoError(json.Unmarshal(raw.Errors, &errs))
+	suite.Len(errs, 1, "one appeal per ban")
+
+	// Выдавшему бан сообщается об апелляции
+	issuerNotified, err := client.Notification.Query().
+		Where(notification.UserIDEQ(owner.ID), notification.TypeEQ(notification.TypeModeration)).
+		Exist(suite.ctx)
+	suite.Require().NoError(err)
+	suite.True(issuerNotified)
+
+	var queue struct {
+		BanAppealQueue struct {
+			Edges []struct{ Node struct{ ID string } }
+		}
+	}
+	queueQuery := `query($c: ID) { banAppealQueue(scope: community, communityID: $c) { edges { node { id } } } }`
+	suite.Require().NoError(c.Post(queueQuery, &queue, gqlclient.Var("c", community.ID), gqltest.As(owner.ID)))
+	suite.Require().Len(queue.BanAppealQueue.Edges, 1)
+	suite.Equal(appeal.AppealBan.ID, queue.BanAppealQueue.Edges[0].Node.ID)
+
+	decide := `mutation($id: ID!, $m: String) { %s(id: $id, message: $m) { status overridden } }`
+	var rejected struct{ RejectBanAppeal struct{ Status string } }
+	suite.Require().NoError(c.Post(fmt.Sprintf(decide, "rejectBanAppeal"), &rejected,
+		gqlclient.Var("id", appeal.AppealBan.ID), gqlclient.Var("m", "Нет"), gqltest.As(owner.ID)))
+	suite.Equal("rejected", rejected.RejectBanAppeal.Status)
+
+	// Модератор сообщества не пересматривает свое решение, персонал платформы — может
+	raw, err = c.RawPost(fmt.Sprintf(decide, "acceptBanAppeal"), gqlclient.Var("id", appeal.AppealBan.ID), gqltest.As(owner.ID))
+	suite.Require().NoError(err)
+	suite.NotEmpty(raw.Errors)
+
+	var accepted struct {
+		AcceptBanAppeal struct {
+			Status     string
+			Overridden bool
+		}
+	}
+	suite.Require().NoError(c.Post(fmt.Sprintf(decide, "acceptBanAppeal"), &accepted,
+		gqlclient.Var("id", appeal.AppealBan.ID), gqlclient.Var("m", "Бан снят"), gqltest.As(staff.ID)))
+	suite.Equal("accepted", accepted.AcceptBanAppeal.Status)
+	suite.True(accepted.AcceptBanAppeal.Overridden)
+
+	stillBanned, err := client.CommunityUserBan.Query().
+		Where(communityuserban.UserIDEQ(banned.ID), communityuserban.CommunityIDEQ(community.ID)).
+		Exist(suite.ctx)
+	suite.Require().NoError(err)
+	suite.False(stillBanned)
+
+	messages, err := client.Notification.Query().
+		Where(notification.UserIDEQ(banned.ID), notification.TypeEQ(notification.TypeModeration)).
+		All(suite.ctx)
+	suite.Require().NoError(err)
+	suite.Len(messages, 2, "rejection and acceptance")
+}
+
+// TestConcurrentDecide — из параллельных решений по одной апелляции проходит ровно одно,
+// остальные получают ErrDecided; бан снимается, только если прошло принятие
+func (suite *BanAppealsTestSuite) TestConcurrentDecide() {
+	suite.helper.CleanDatabase(suite.T())
+	client := suite.helper.GetClient()
+	now := time.Now()
+	newUser := func(name string) *ent.User {
+		u, err := fixtures.CreateTestUser(suite.ctx, client, fixtures.UserFixture{
+			Name: name, Slug: fixtures.RandomSlug(), Email: fixtures.RandomEmail(),
+			Password: "password123", Salt: "salt", IsVerified: true, CreatedAt: now,
+		})
+		suite.Require().NoError(err)
+		return u
+	}
+	owner, banned := newUser("Owner"), newUser("Banned")
+	community, err := fixtures.CreateTestCommunity(suite.ctx, client, fixtures.CommunityFixture{
+		Name: "Community", Slug: fixtures.RandomSlug(), OwnerID: owner.ID, CreatedAt: now,
+	})
+	suite.Require().NoError(err)
+	ban, err := banuc.NewBanUsecase(client).BanUserFromCommunity(suite.ctx, banned.ID, community.ID,
+		model.SanctionDetails{Reason: "spam", IssuedBy: owner.ID})
+	suite.Require().NoError(err)
+	appeals := banappealuc.NewBanAppealUsecase(client)
+	appeal, err := appeals.Submit(suite.ctx, banned.ID, banappeal.ScopeCommunity, ban.ID, "Это была ошибка")
+	suite.Require().NoError(err)
+
+	statuses := []banappeal.Status{
+		banappeal.StatusAccepted, banappeal.StatusRejected, banappeal.StatusAccepted, banappeal.StatusRejected,
+	}
+	type result struct {
+		status banappeal.Status
+		lifted bool
+		err    error
+	}
+	results := make(chan result, len(statuses))
+	var wg sync.WaitGroup
+	for _, status := range statuses {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, lifted, err := appeals.Decide(suite.ctx, appeal.ID, owner.ID, status, nil, false)
+			results <- result{status, lifted, err}
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	var decided []result
+	for r := range results {
+		if r.err == nil {
+			decided = append(decided, r)
+			continue
+		}
+		suite.ErrorIs(r.err, banappealuc.ErrDecided)
+	}
+	suite.Require().Len(decided, 1)
+
+	stored, err := appeals.Get(suite.ctx, appeal.ID)
+	suite.Require().NoError(err)
+	suite.Equal(decided[0].status, stored.Status)
+	stillBanned, err := client.CommunityUserBan.Query().Where(communityuserban.IDEQ(ban.ID)).Exist(suite.ctx)
+	suite.Require().NoError(err)
+	suite.Equal(decided[0].status == banappeal.StatusAccepted, decided[0].lifted)
+	suite.Equal(!decided[0].lifted, stillBanned)
+}
+
+func TestBanAppealsTestSuite(t *testing.T) {
+	suite.Run(t, new(BanAppealsTestSuite))
+}
@@ -0,0 +1,154 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"entgo.io/ent/dialect"
+	entsql "entgo.io/ent/dialect/sql"
+	gqlclient "github.com/99designs/gqlgen/client"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/stretchr/testify/suite"
+
+	"stormlink/server/ent"
+	"stormlink/server/ent/post"
+	"stormlink/server/graphql"
+	"stormlink/server/graphql/dataloader"
+	"stormlink/shared/auth"
+	"stormlink/tests/fixtures"
+	"stormlink/tests/testhelper"
+)
+
+// countingDriver считает SQL-запросы на чтение
+type countingDriver struct {
+	dialect.Driver
+	queries atomic.Int64
+}
+
+func (d *countingDriver) Query(ctx context.Context, query string, args, v any) error {
+	d.queries.Add(1)
+	return d.Driver.Query(ctx, query, args, v)
+}
+
+const postsPageQuery = `query {
+	posts(visibility: published) {
+		id
+		heroImage { id url }
+		author { id name avatar { id url } }
+		community { id title logo { id } owner { id name } }
+		postStatus { likesCount commentsCount bookmarksCount isLiked hasBookmark authorCommunityOwner authorHostOwner }
+		likes { id user { id name } }
+	}
+}`
+
+type DataloaderQueryCountTestSuite struct {
+	suite.Suite
+	ctx    context.Context
+	helper *testhelper.PostgresTestHelper
+}
+
+func (suite *DataloaderQueryCountTestSuite) SetupSuite() {
+	suite.ctx = context.Background()
+	suite.helper = testhelper.NewPostgresTestHelper(suite.T())
+	suite.helper.WaitForDatabase(suite.T())
+}
+
+func (suite *DataloaderQueryCountTestSuite) TearDownSuite() {
+	if suite.helper != nil {
+		suite.helper.Cleanup()
+	}
+}
+
+// seedPosts создает n опубликованных постов разных авторов с аватарами, лайками и закладкой зрителя
+func (suite *DataloaderQueryCountTestSuite) seedPosts(n int) (viewerID int) {
+	suite.helper.CleanDatabase(suite.T())
+	client := suite.helper.GetClient()
+	now := time.Now()
+
+	viewer, err := fixtures.CreateTestUser(suite.ctx, client, fixtures.UserFixture{
+		Name: "Viewer", Slug: fixtures.RandomSlug(), Email: fixtures.RandomEmail(),
+		Password: "password123", Salt: "salt", IsVerified: true, CreatedAt: now,
+	})
+	suite.Require().NoError(err)
+	cm, err := fixtures.CreateTestCommunity(suite.ctx, client, fixtures.CommunityFixture{
+		Name: "Community", Slug: fixtures.RandomSlug(), OwnerID: viewer.ID, CreatedAt: now,
+	})
+	suite.Require().NoError(err)
+
+	for i := 0; i < n; i++ {
+		avatar, err := fixtures.CreateTestMedia(suite.ctx, client, fixtures.MediaFixture{
+			Filename: fmt.Sprintf("avatar-%d.jpg", i), URL: fmt.Sprintf("https://example.com/avatar-%d.jpg", i), CreatedAt: now,
+		})
+		suite.Require().NoError(err)
+		author, err := fixtures.CreateTestUser(suite.ctx, client, fixtures.UserFixture{
+			Name: fmt.Sprintf("Author %d", i), Slug: fixtures.RandomSlug(), Email: fixtures.RandomEmail(),
+			Password: "password123", Salt: "salt", IsVerified: true, CreatedAt: now,
+		})
+		suite.Require().NoError(err)
+		suite.Require().NoError(client.User.UpdateOne(author).SetAvatarID(avatar.ID).Exec(suite.ctx))
+
+		p, err := fixtures.CreateTestPost(suite.ctx, client, fixtures.PostFixture{
+			Title: fmt.Sprintf("Post %d", i), Content: "content", CommunityID: cm.ID, AuthorID: author.ID, CreatedAt: now,
+		})
+		suite.Require().NoError(err)
+		suite.Require().NoError(client.Post.UpdateOne(p).SetVisibility(post.VisibilityPublished).Exec(suite.ctx))
+
+		_, err = fixtures.CreateTestPostLike(suite.ctx, client, fixtures.PostLikeFixture{PostID: p.ID, UserID: author.ID, CreatedAt: now})
+		suite.Require().NoError(err)
+		_, err = fixtures.CreateTestPostLike(suite.ctx, client, fixtures.PostLikeFixture{PostID: p.ID, UserID: viewer.ID, CreatedAt: now})
+		suite.Require().NoError(err)
+		_, err = fixtures.CreateTestBookmark(suite.ctx, client, fixtures.BookmarkFixture{PostID: p.ID, UserID: viewer.ID, CreatedAt: now})
+		suite.Require().NoError(err)
+	}
+	return viewer.ID
+}
+
+// countPostsPageQueries выполняет запрос ленты от имени зрителя и возвращает число SQL-запросов
+func (suite *DataloaderQueryCountTestSuite) countPostsPageQueries(n int) int64 {
+	viewerID := suite.seedPosts(n)
+
+	drv, err := entsql.Open(dialect.Postgres, suite.helper.GetContainers().GetPostgresDSN())
+	suite.Require().NoError(err)
+	counter := &countingDriver{Driver: drv}
+	client := ent.NewClient(ent.Driver(counter))
+	defer client.Close()
+
+	srv := handler.New(graphql.NewExecutableSchema(graphql.Config{Resolvers: &graphql.Resolver{Client: client}}))
+	srv.AddTransport(transport.POST{})
+	srv.AroundOperations(dataloader.Middleware(client))
+
+	var resp struct {
+		Posts []map[string]any
+	}
+	asViewer := func(r *gqlclient.Request) {
+		r.HTTP = r.HTTP.WithContext(auth.WithUserID(r.HTTP.Context(), viewerID))
+	}
+	gqlclient.New(srv).MustPost(postsPageQuery, &resp, asViewer)
+
+	suite.Require().Len(resp.Posts, n)
+	for _, p := range resp.Posts {
+		status := p["postStatus"].(map[string]any)
+		suite.Equal("2", status["likesCount"])
+		suite.Equal(true, status["isLiked"])
+		suite.Equal(true, status["hasBookmark"])
+		suite.Len(p["likes"], 2)
+		suite.NotNil(p["author"].(map[string]any)["avatar"])
+	}
+	return counter.queries.Load()
+}
+
+func (suite *DataloaderQueryCountTestSuite) TestPostsPageQueryCountIsFlat() {
+	small := suite.countPostsPageQueries(3)
+	large := suite.countPostsPageQueries(15)
+	suite.Equal(small, large, "number of SQL queries must not grow with page size")
+	// Посты, сообщества, авторы и владельцы, медиа, 3 счётчика, 2 флага зрителя, лайки, их пользователи, host
+	suite.LessOrEqual(large, int64(14))
+}
+
+func TestDataloaderQueryCountTestSuite(t *testing.T) {
+	suite.Run(t, new(DataloaderQueryCountTestSuite))
+}
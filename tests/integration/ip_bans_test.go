@@ -0,0 +1,226 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	gqlclient "github.com/99designs/gqlgen/client"
+	"github.com/stretchr/testify/suite"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"stormlink/server/authz"
+	"stormlink/server/ent"
+	"stormlink/server/ent/moderationaction"
+	"stormlink/server/graphql"
+	"stormlink/server/ipban"
+	"stormlink/server/middleware"
+	hostipbanuc "stormlink/server/usecase/hostipban"
+	usersessionuc "stormlink/server/usecase/usersession"
+	httpWithCookies "stormlink/shared/http"
+	"stormlink/tests/fixtures"
+	"stormlink/tests/testhelper"
+	"stormlink/tests/testhelper/gqltest"
+)
+
+const registerUserMutation = `mutation {
+	registerUser(input: { name: "Evader", email: "evader@example.com", password: "password123" }) { message }
+}`
+
+type IPBansTestSuite struct {
+	suite.Suite
+	ctx    context.Context
+	helper *testhelper.PostgresTestHelper
+}
+
+func (suite *IPBansTestSuite) SetupSuite() {
+	suite.ctx = context.Background()
+	suite.helper = testhelper.NewPostgresTestHelper(suite.T())
+	suite.helper.WaitForDatabase(suite.T())
+}
+
+func (suite *IPBansTestSuite) TearDownSuite() {
+	if suite.helper != nil {
+		suite.helper.Cleanup()
+	}
+}
+
+// TestIPBan — с адресов забаненной подсети нельзя регистрироваться и выполнять мутации, но можно
+// читать; персонал платформы снимает бан и со своего забаненного адреса
+func (suite *IPBansTestSuite) TestIPBan() {
+	suite.helper.CleanDatabase(suite.T())
+	// Запросы тестового клиента приходят с адреса httptest 192.0.2.1, за ним — внутренний прокси 10.0.0.1
+	suite.T().Setenv("TRUSTED_PROXIES", "192.0.2.1, 10.0.0.0/8")
+	client := suite.helper.GetClient()
+	now := time.Now()
+	newUser := func(name string) *ent.User {
+		u, err := fixtures.CreateTestUser(suite.ctx, client, fixtures.UserFixture{
+			Name: name, Slug: fixtures.RandomSlug(), Email: fixtures.RandomEmail(),
+			Password: "password123", Salt: "salt", IsVerified: true, CreatedAt: now,
+		})
+		suite.Require().NoError(err)
+		return u
+	}
+	staff, member := newUser("Staff"), newUser("Member")
+	_, err := client.HostRole.Create().
+		SetTitle("Staff").
+		SetPermissions([]string{string(authz.HostBanUser)}).
+		AddUserIDs(staff.ID).
+		Save(suite.ctx)
+	suite.Require().NoError(err)
+
+	checker := ipban.New(ipban.NewEntStore(client))
+	authorizer := authz.New(authz.NewEntStore(client))
+	newServer := func(guard bool) *gqlclient.Client {
+		srv := gqltest.NewServer(&graphql.Resolver{
+			Client:      client,
+			Authz:       authorizer,
+			IPBans:      checker,
+			HostIPBanUC: hostipbanuc.NewHostIPBanUsecase(client),
+		})
+		if guard {
+			srv.Use(ipban.Guard{Exempt: func(ctx context.Context, userID int) (bool, error) {
+				return authorizer.Can(ctx, userID, authz.HostBanUser, authz.Host())
+			}})
+		}
+		return gqlclient.New(middleware.IPBanMiddleware(checker)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			srv.ServeHTTP(w, r.WithContext(httpWithCookies.WithHTTPContext(r.Context(), w, r)))
+		})))
+	}
+	c := newServer(true)
+	from := func(ip string) gqlclient.Option {
+		return func(r *gqlclient.Request) {
+			r.HTTP.Header.Set("X-Forwarded-For", ip+", 10.0.0.1")
+		}
+	}
+	reason := func(raw *gqlclient.Response) string {
+		var errs gqlerror.List
+		suite.Require().NoError(json.Unmarshal(raw.Errors, &errs))
+		suite.Require().Len(errs, 1)
+		r, _ := errs[0].Extensions["reason"].(string)
+		return r
+	}
+
+	var banned struct {
+		BanIPOnHost struct {
+			ID   string
+			Cidr string
+		}
+	}
+	suite.Require().NoError(c.Post(`mutation { banIPOnHost(input: { cidr: "203.0.113.77/24", reason: "ban evasion" }) { id cidr } }`,
+		&banned, gqltest.As(staff.ID), from("192.0.2.10")))
+	suite.Equal("203.0.113.0/24", banned.BanIPOnHost.Cidr)
+	logged, err := client.ModerationAction.Query().
+		Where(moderationaction.TypeEQ(moderationaction.TypeIPBanned), moderationaction.TargetTypeEQ(moderationaction.TargetTypeIPBan)).
+		Exist(suite.ctx)
+	suite.Require().NoError(err)
+	suite.True(logged)
+
+	// Регистрацию отклоняет и Guard, и сам резолвер
+	for _, cl := range []*gqlclient.Client{c, newServer(false)} {
+		raw, err := cl.RawPost(registerUserMutation, from("203.0.113.5"))
+		suite.Require().NoError(err)
+		suite.Equal(ipban.Reason, reason(raw))
+	}
+	// Подделанный клиентом X-Forwarded-For бан не обходит: адреса левее доверенных прокси не учитываются,
+	// а от недоверенного соединения заголовок не читается вовсе
+	for name, spoof := range map[string]gqlclient.Option{
+		"prepended hop": func(r *gqlclient.Request) {
+			r.HTTP.Header.Set("X-Forwarded-For", "198.51.100.7, 203.0.113.5, 10.0.0.1")
+		},
+		"direct connection": func(r *gqlclient.Request) {
+			r.HTTP.RemoteAddr = "203.0.113.5:40000"
+			r.HTTP.Header.Set("X-Forwarded-For", "198.51.100.7")
+		},
+	} {
+		raw, err := c.RawPost(registerUserMutation, spoof)
+		suite.Require().NoError(err, name)
+		suite.Equal(ipban.Reason, reason(raw), name)
+	}
+	raw, err := c.RawPost(`mutation { unbanIPOnHost(banID: "1") }`, gqltest.As(member.ID), from("203.0.113.5"))
+	suite.Require().NoError(err)
+	suite.Equal(ipban.Reason, reason(raw))
+
+	// Чтение с забаненного адреса разрешено, персонал платформы снимает бан со своего адреса
+	var list struct{ HostIPBans []struct{ ID string } }
+	suite.Require().NoError(c.Post(`query { hostIPBans { id } }`, &list, gqltest.As(staff.ID), from("203.0.113.9")))
+	suite.Require().Len(list.HostIPBans, 1)
+	var unbanned struct{ UnbanIPOnHost bool }
+	suite.Require().NoError(c.Post(`mutation($id: ID!) { unbanIPOnHost(banID: $id) }`, &unbanned,
+		gqlclient.Var("id", banned.BanIPOnHost.ID), gqltest.As(staff.ID), from("203.0.113.9")))
+	suite.True(unbanned.UnbanIPOnHost)
+
+	// После снятия бана мутации доходят до резолверов
+	raw, err = c.RawPost(`mutation { banIPOnHost(input: { cidr: "198.51.100.0/24" }) { id } }`, gqltest.As(member.ID), from("203.0.113.5"))
+	suite.Require().NoError(err)
+	suite.Empty(reason(raw))
+}
+
+// TestAssociatedAccounts — аккаунты с общими адресами входа видит персонал платформы
+func (suite *IPBansTestSuite) TestAssociatedAccounts() {
+	suite.helper.CleanDatabase(suite.T())
+	client := suite.helper.GetClient()
+	now := time.Now()
+	newUser := func(name string) *ent.User {
+		u, err := fixtures.CreateTestUser(suite.ctx, client, fixtures.UserFixture{
+			Name: name, Slug: fixtures.RandomSlug(), Email: fixtures.RandomEmail(),
+			Password: "password123", Salt: "salt", IsVerified: true, CreatedAt: now,
+		})
+		suite.Require().NoError(err)
+		return u
+	}
+	staff, evader, second, stale, stranger := newUser("Staff"), newUser("Evader"), newUser("Second"), newUser("Stale"), newUser("Stranger")
+	_, err := client.HostRole.Create().
+		SetTitle("Staff").
+		SetPermissions([]string{string(authz.HostBanUser)}).
+		AddUserIDs(staff.ID).
+		Save(suite.ctx)
+	suite.Require().NoError(err)
+
+	sessions := usersessionuc.NewUserSessionUsecase(client)
+	for _, s := range []struct {
+		user *ent.User
+		ip   string
+	}{
+		{evader, "203.0.113.5"},
+		{evader, "198.51.100.1"},
+		{second, "198.51.100.1"},
+		{second, "203.0.113.5"},
+		{stranger, "192.0.2.1"},
+	} {
+		suite.Require().NoError(sessions.Record(suite.ctx, s.user.ID, s.ip, "test"))
+	}
+	// Вход за пределами окна не учитывается
+	_, err = client.UserSession.Create().
+		SetUserID(stale.ID).SetIP("203.0.113.5").SetCreatedAt(now.Add(-40 * 24 * time.Hour)).
+		Save(suite.ctx)
+	suite.Require().NoError(err)
+
+	c := gqltest.NewClient(&graphql.Resolver{
+		Client:        client,
+		UserSessionUC: sessions,
+	})
+
+	query := `query($id: ID!) { associatedAccounts(userID: $id, days: 30) { user { id } ips } }`
+	var out struct {
+		AssociatedAccounts []struct {
+			User struct{ ID string }
+			Ips  []string
+		}
+	}
+	suite.Require().NoError(c.Post(query, &out, gqlclient.Var("id", evader.ID), gqltest.As(staff.ID)))
+	suite.Require().Len(out.AssociatedAccounts, 1)
+	suite.Equal(strconv.Itoa(second.ID), out.AssociatedAccounts[0].User.ID)
+	suite.Equal([]string{"203.0.113.5", "198.51.100.1"}, out.AssociatedAccounts[0].Ips)
+
+	raw, err := c.RawPost(query, gqlclient.Var("id", evader.ID), gqltest.As(evader.ID))
+	suite.Require().NoError(err)
+	suite.NotEmpty(raw.Errors, "only host staff sees login addresses")
+}
+
+func TestIPBansTestSuite(t *testing.T) {
+	suite.Run(t, new(IPBansTestSuite))
+}
@@ -0,0 +1,217 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	gqlclient "github.com/99designs/gqlgen/client"
+	"github.com/stretchr/testify/suite"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"stormlink/server/ent"
+	"stormlink/server/ent/moderationaction"
+	"stormlink/server/ent/post"
+	"stormlink/server/graphql"
+	"stormlink/server/model"
+	"stormlink/server/sanctions"
+	banuc "stormlink/server/usecase/ban"
+	commentuc "stormlink/server/usecase/comment"
+	moderationloguc "stormlink/server/usecase/moderationlog"
+	notificationuc "stormlink/server/usecase/notification"
+	postuc "stormlink/server/usecase/post"
+	"stormlink/shared/auth"
+	"stormlink/shared/pubsub"
+	"stormlink/tests/fixtures"
+	"stormlink/tests/testhelper"
+	"stormlink/tests/testhelper/gqltest"
+)
+
+const moderationLogQuery = `query($c: ID) {
+	moderationLog(filter: { communityID: $c }) {
+		edges { node { type targetType targetID moderator { id } targetUser { id } community { id } } }
+	}
+}`
+
+type ModerationLogTestSuite struct {
+	suite.Suite
+	ctx    context.Context
+	helper *testhelper.PostgresTestHelper
+}
+
+func (suite *ModerationLogTestSuite) SetupSuite() {
+	suite.ctx = context.Background()
+	suite.helper = testhelper.NewPostgresTestHelper(suite.T())
+	suite.helper.WaitForDatabase(suite.T())
+}
+
+func (suite *ModerationLogTestSuite) TearDownSuite() {
+	if suite.helper != nil {
+		suite.helper.Cleanup()
+	}
+}
+
+// TestBanIsLoggedAndVisibleToModerators — бан и разбан попадают в журнал сообщества, который видит
+// владелец сообщества, но не посторонний; журнал всей платформы владельцу сообщества закрыт
+func (suite *ModerationLogTestSuite) TestBanIsLoggedAndVisibleToModerators() {
+	suite.helper.CleanDatabase(suite.T())
+	client := suite.helper.GetClient()
+	now := time.Now()
+	newUser := func(name string) *ent.User {
+		u, err := fixtures.CreateTestUser(suite.ctx, client, fixtures.UserFixture{
+			Name: name, Slug: fixtures.RandomSlug(), Email: fixtures.RandomEmail(),
+			Password: "password123", Salt: "salt", IsVerified: true, CreatedAt: now,
+		})
+		suite.Require().NoError(err)
+		return u
+	}
+	owner, target, outsider := newUser("Owner"), newUser("Target"), newUser("Outsider")
+	community, err := fixtures.CreateTestCommunity(suite.ctx, client, fixtures.CommunityFixture{
+		Name: "Community", Slug: fixtures.RandomSlug(), OwnerID: owner.ID, CreatedAt: now,
+	})
+	suite.Require().NoError(err)
+
+	bans := banuc.NewBanUsecase(client)
+	asOwner := auth.WithUserID(suite.ctx, owner.ID)
+	ban, err := bans.BanUserFromCommunity(asOwner, target.ID, community.ID, model.SanctionDetails{Reason: "spam", IssuedBy: owner.ID})
+	suite.Require().NoError(err)
+	suite.Require().NoError(bans.UnbanUserFromCommunity(asOwner, ban.ID))
+
+	c := gqltest.NewClient(&graphql.Resolver{
+		Client:          client,
+		ModerationLogUC: moderationloguc.NewModerationLogUsecase(client),
+	})
+
+	var resp struct {
+		ModerationLog struct {
+			Edges []struct {
+				Node struct {
+					Type       string
+					TargetType string
+					TargetID   string
+					Moderator  struct{ ID string }
+					TargetUser struct{ ID string }
+					Community  struct{ ID string }
+				}
+			}
+		}
+	}
+	suite.Require().NoError(c.Post(moderationLogQuery, &resp, gqlclient.Var("c", community.ID), gqltest.As(owner.ID)))
+	edges := resp.ModerationLog.Edges
+	suite.Require().Len(edges, 2)
+	// Новые записи первыми
+	suite.Equal("user_unbanned", edges[0].Node.Type)
+	suite.Equal("user_banned", edges[1].Node.Type)
+	for _, e := range edges {
+		suite.Equal("user", e.Node.TargetType)
+		suite.Equal(strconv.Itoa(target.ID), e.Node.TargetID)
+		suite.Equal(strconv.Itoa(owner.ID), e.Node.Moderator.ID)
+		suite.Equal(strconv.Itoa(target.ID), e.Node.TargetUser.ID)
+		suite.Equal(strconv.Itoa(community.ID), e.Node.Community.ID)
+	}
+
+	logged, err := client.ModerationAction.Query().
+		Where(moderationaction.TypeEQ(moderationaction.TypeUserBanned)).
+		Only(suite.ctx)
+	suite.Require().NoError(err)
+	suite.Nil(logged.Before)
+	suite.Equal("spam", logged.After["reason"])
+
+	forbidden := func(userID int, communityID any) {
+		raw, err := c.RawPost(moderationLogQuery, gqlclient.Var("c", communityID), gqltest.As(userID))
+		suite.Require().NoError(err)
+		var errs gqlerror.List
+		suite.Require().NoError(json.Unmarshal(raw.Errors, &errs))
+		if suite.Len(errs, 1) {
+			suite.Contains(errs[0].Message, "forbidden")
+		}
+	}
+	forbidden(outsider.ID, community.ID)
+	forbidden(owner.ID, nil)
+}
+
+// TestEditsAreLoggedOnlyWhenAuthorized — снятие поста с публикации и удаление комментария
+// посторонним отклоняются и не попадают в журнал; те же действия владельца сообщества
+// записываются, а правки автором своего контента — нет
+func (suite *ModerationLogTestSuite) TestEditsAreLoggedOnlyWhenAuthorized() {
+	suite.helper.CleanDatabase(suite.T())
+	client := suite.helper.GetClient()
+	now := time.Now()
+	newUser := func(name string) *ent.User {
+		u, err := fixtures.CreateTestUser(suite.ctx, client, fixtures.UserFixture{
+			Name: name, Slug: fixtures.RandomSlug(), Email: fixtures.RandomEmail(),
+			Password: "password123", Salt: "salt", IsVerified: true, CreatedAt: now,
+		})
+		suite.Require().NoError(err)
+		return u
+	}
+	owner, author, outsider := newUser("Owner"), newUser("Author"), newUser("Outsider")
+	community, err := fixtures.CreateTestCommunity(suite.ctx, client, fixtures.CommunityFixture{
+		Name: "Community", Slug: fixtures.RandomSlug(), OwnerID: owner.ID, CreatedAt: now,
+	})
+	suite.Require().NoError(err)
+	newPost := func() *ent.Post {
+		p, err := fixtures.CreateTestPost(suite.ctx, client, fixtures.PostFixture{
+			Title: "Post", Content: "content", CommunityID: community.ID, AuthorID: author.ID, CreatedAt: now,
+		})
+		suite.Require().NoError(err)
+		p, err = p.Update().SetVisibility(post.VisibilityPublished).Save(suite.ctx)
+		suite.Require().NoError(err)
+		return p
+	}
+	newComment := func(p *ent.Post) *ent.Comment {
+		cm, err := fixtures.CreateTestComment(suite.ctx, client, fixtures.CommentFixture{
+			Content: "comment", PostID: p.ID, AuthorID: author.ID, CreatedAt: now,
+		})
+		suite.Require().NoError(err)
+		return cm
+	}
+
+	c := gqltest.NewClient(&graphql.Resolver{
+		Client:          client,
+		Sanctions:       sanctions.New(sanctions.NewEntStore(client), nil),
+		PostUC:          postuc.NewPostUsecase(client),
+		CommentUC:       commentuc.NewCommentUsecase(client),
+		NotificationUC:  notificationuc.NewNotificationUsecase(client),
+		ModerationLogUC: moderationloguc.NewModerationLogUsecase(client),
+		Broker:          pubsub.NewMemoryBroker(16),
+	})
+	const (
+		unpublishPost = `mutation($id: ID!) { post(input: { id: $id, visibility: draft }) { id } }`
+		deleteComment = `mutation($id: ID!) { updateComment(input: { id: $id, hasDeleted: true }) { id } }`
+	)
+	logged := func(t moderationaction.Type) int {
+		n, err := client.ModerationAction.Query().Where(moderationaction.TypeEQ(t)).Count(suite.ctx)
+		suite.Require().NoError(err)
+		return n
+	}
+
+	p := newPost()
+	cm := newComment(p)
+	var resp struct{}
+	suite.Error(c.Post(unpublishPost, &resp, gqlclient.Var("id", p.ID), gqltest.As(outsider.ID)))
+	suite.Error(c.Post(deleteComment, &resp, gqlclient.Var("id", cm.ID), gqltest.As(outsider.ID)))
+	suite.Zero(logged(moderationaction.TypePostUnpublished), "отказ в правах не пишется в журнал")
+	suite.Zero(logged(moderationaction.TypeCommentDeleted))
+	p, err = client.Post.Get(suite.ctx, p.ID)
+	suite.Require().NoError(err)
+	suite.Equal(post.VisibilityPublished, p.Visibility)
+
+	suite.Require().NoError(c.Post(unpublishPost, &resp, gqlclient.Var("id", p.ID), gqltest.As(author.ID)))
+	suite.Require().NoError(c.Post(deleteComment, &resp, gqlclient.Var("id", cm.ID), gqltest.As(author.ID)))
+	suite.Zero(logged(moderationaction.TypePostUnpublished), "автор снимает свой пост без записи в журнал")
+	suite.Zero(logged(moderationaction.TypeCommentDeleted))
+
+	p = newPost()
+	cm = newComment(p)
+	suite.Require().NoError(c.Post(unpublishPost, &resp, gqlclient.Var("id", p.ID), gqltest.As(owner.ID)))
+	suite.Require().NoError(c.Post(deleteComment, &resp, gqlclient.Var("id", cm.ID), gqltest.As(owner.ID)))
+	suite.Equal(1, logged(moderationaction.TypePostUnpublished))
+	suite.Equal(1, logged(moderationaction.TypeCommentDeleted))
+}
+
+func TestModerationLogTestSuite(t *testing.T) {
+	suite.Run(t, new(ModerationLogTestSuite))
+}
@@ -0,0 +1,169 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	gqlclient "github.com/99designs/gqlgen/client"
+	"github.com/stretchr/testify/suite"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"stormlink/server/ent"
+	"stormlink/server/ent/communityuserban"
+	"stormlink/server/ent/notification"
+	"stormlink/server/graphql"
+	banuc "stormlink/server/usecase/ban"
+	notificationuc "stormlink/server/usecase/notification"
+	reportuc "stormlink/server/usecase/report"
+	"stormlink/shared/pubsub"
+	"stormlink/tests/fixtures"
+	"stormlink/tests/testhelper"
+	"stormlink/tests/testhelper/gqltest"
+)
+
+const createReportMutation = `mutation($type: ReportTargetType!, $id: ID!) {
+	createReport(input: { targetType: $type, targetID: $id, reason: spam }) { id queue status }
+}`
+
+type ReportsTestSuite struct {
+	suite.Suite
+	ctx    context.Context
+	helper *testhelper.PostgresTestHelper
+}
+
+func (suite *ReportsTestSuite) SetupSuite() {
+	suite.ctx = context.Background()
+	suite.helper = testhelper.NewPostgresTestHelper(suite.T())
+	suite.helper.WaitForDatabase(suite.T())
+}
+
+func (suite *ReportsTestSuite) TearDownSuite() {
+	if suite.helper != nil {
+		suite.helper.Cleanup()
+	}
+}
+
+// TestReportLifecycle — жалобы двух пользователей на пост схлопываются в одну запись очереди
+// сообщества; мера по жалобе банит автора и закрывает обе жалобы с уведомлением авторов жалоб
+func (suite *ReportsTestSuite) TestReportLifecycle() {
+	suite.helper.CleanDatabase(suite.T())
+	client := suite.helper.GetClient()
+	now := time.Now()
+	newUser := func(name string) *ent.User {
+		u, err := fixtures.CreateTestUser(suite.ctx, client, fixtures.UserFixture{
+			Name: name, Slug: fixtures.RandomSlug(), Email: fixtures.RandomEmail(),
+			Password: "password123", Salt: "salt", IsVerified: true, CreatedAt: now,
+		})
+		suite.Require().NoError(err)
+		return u
+	}
+	owner, author, first, second := newUser("Owner"), newUser("Author"), newUser("First"), newUser("Second")
+	community, err := fixtures.CreateTestCommunity(suite.ctx, client, fixtures.CommunityFixture{
+		Name: "Community", Slug: fixtures.RandomSlug(), OwnerID: owner.ID, CreatedAt: now,
+	})
+	suite.Require().NoError(err)
+	post, err := fixtures.CreateTestPost(suite.ctx, client, fixtures.PostFixture{
+		Title: "Spam", Content: "content", CommunityID: community.ID, AuthorID: author.ID, CreatedAt: now,
+	})
+	suite.Require().NoError(err)
+
+	c := gqltest.NewClient(&graphql.Resolver{
+		Client:         client,
+		ReportUC:       reportuc.NewReportUsecase(client),
+		BanUC:          banuc.NewBanUsecase(client),
+		NotificationUC: notificationuc.NewNotificationUsecase(client),
+		Broker:         pubsub.NewMemoryBroker(16),
+	})
+
+	type created struct {
+		CreateReport struct {
+			ID     string
+			Queue  string
+			Status string
+		}
+	}
+	report := func(userID int, targetType string, targetID int) created {
+		var resp created
+		suite.Require().NoError(c.Post(createReportMutation, &resp,
+			gqlclient.Var("type", targetType), gqlclient.Var("id", targetID), gqltest.As(userID)))
+		return resp
+	}
+
+	primary := report(first.ID, "post", post.ID)
+	suite.Equal("community", primary.CreateReport.Queue)
+	suite.Equal("open", primary.CreateReport.Status)
+	suite.Equal(primary.CreateReport.ID, report(first.ID, "post", post.ID).CreateReport.ID, "repeat report is deduplicated")
+	duplicate := report(second.ID, "post", post.ID)
+	suite.NotEqual(primary.CreateReport.ID, duplicate.CreateReport.ID)
+
+	// Пользователей разбирает платформа
+	suite.Equal("host", report(first.ID, "user", author.ID).CreateReport.Queue)
+
+	var queue struct {
+		ReportQueue struct {
+			Edges []struct {
+				Node struct {
+					ID         string
+					Duplicates []struct{ ID string }
+				}
+			}
+		}
+	}
+	queueQuery := `query($c: ID) { reportQueue(communityID: $c) { edges { node { id duplicates { id } } } } }`
+	suite.Require().NoError(c.Post(queueQuery, &queue, gqlclient.Var("c", community.ID), gqltest.As(owner.ID)))
+	suite.Require().Len(queue.ReportQueue.Edges, 1)
+	suite.Equal(primary.CreateReport.ID, queue.ReportQueue.Edges[0].Node.ID)
+	suite.Require().Len(queue.ReportQueue.Edges[0].Node.Duplicates, 1)
+	suite.Equal(duplicate.CreateReport.ID, queue.ReportQueue.Edges[0].Node.Duplicates[0].ID)
+
+	raw, err := c.RawPost(queueQuery, gqlclient.Var("c", community.ID), gqltest.As(first.ID))
+	suite.Require().NoError(err)
+	var errs gqlerror.List
+	suite.Require().NoError(json.Unmarshal(raw.Errors, &errs))
+	suite.Len(errs, 1, "reporters cannot see the queue")
+
+	var acted struct {
+		ActOnReport struct {
+			ID     string
+			Status string
+		}
+	}
+	suite.Require().NoError(c.Post(`mutation($id: ID!) {
+		actOnReport(input: { reportID: $id, action: BAN, reason: "spam", note: "Автор заблокирован" }) { id status }
+	}`, &acted, gqlclient.Var("id", primary.CreateReport.ID), gqltest.As(owner.ID)))
+	suite.Equal(primary.CreateReport.ID, acted.ActOnReport.ID)
+	suite.Equal("resolved", acted.ActOnReport.Status)
+
+	banned, err := client.CommunityUserBan.Query().
+		Where(communityuserban.UserIDEQ(author.ID), communityuserban.CommunityIDEQ(community.ID)).
+		Exist(suite.ctx)
+	suite.Require().NoError(err)
+	suite.True(banned)
+
+	duplicateID, err := strconv.Atoi(duplicate.CreateReport.ID)
+	suite.Require().NoError(err)
+	closed, err := client.Report.Get(suite.ctx, duplicateID)
+	suite.Require().NoError(err)
+	suite.Equal("resolved", string(closed.Status))
+
+	for _, reporter := range []*ent.User{first, second} {
+		n, err := client.Notification.Query().
+			Where(notification.UserIDEQ(reporter.ID), notification.TypeEQ(notification.TypeModeration)).
+			Only(suite.ctx)
+		suite.Require().NoError(err)
+		suite.Contains(*n.Message, "меры приняты")
+	}
+
+	// Закрытую жалобу нельзя закрыть повторно
+	raw, err = c.RawPost(`mutation($id: ID!) { dismissReport(id: $id) { id } }`,
+		gqlclient.Var("id", primary.CreateReport.ID), gqltest.As(owner.ID))
+	suite.Require().NoError(err)
+	suite.NotEmpty(raw.Errors)
+}
+
+func TestReportsTestSuite(t *testing.T) {
+	suite.Run(t, new(ReportsTestSuite))
+}
@@ -0,0 +1,189 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"entgo.io/ent/dialect"
+	entsql "entgo.io/ent/dialect/sql"
+	gqlclient "github.com/99designs/gqlgen/client"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/stretchr/testify/suite"
+
+	"stormlink/server/ent"
+	"stormlink/server/ent/post"
+	"stormlink/server/graphql"
+	"stormlink/server/graphql/respcache"
+	banuc "stormlink/server/usecase/ban"
+	notificationuc "stormlink/server/usecase/notification"
+	postuc "stormlink/server/usecase/post"
+	"stormlink/shared/auth"
+	"stormlink/shared/pubsub"
+	"stormlink/tests/fixtures"
+	"stormlink/tests/testhelper"
+)
+
+const postBySlugQuery = `query($slug: String!) {
+	postBySlug(slug: $slug) {
+		id
+		title
+		author { id name }
+		postStatus { likesCount }
+	}
+}`
+
+type ResponseCacheTestSuite struct {
+	suite.Suite
+	ctx    context.Context
+	helper *testhelper.PostgresTestHelper
+}
+
+func (suite *ResponseCacheTestSuite) SetupSuite() {
+	suite.ctx = context.Background()
+	suite.helper = testhelper.NewPostgresTestHelper(suite.T())
+	suite.helper.WaitForDatabase(suite.T())
+}
+
+func (suite *ResponseCacheTestSuite) TearDownSuite() {
+	if suite.helper != nil {
+		suite.helper.Cleanup()
+	}
+}
+
+func (suite *ResponseCacheTestSuite) TestAnonymousReadsAreCachedUntilInvalidated() {
+	suite.helper.CleanDatabase(suite.T())
+	seed := suite.helper.GetClient()
+	now := time.Now()
+
+	viewer, err := fixtures.CreateTestUser(suite.ctx, seed, fixtures.UserFixture{
+		Name: "Viewer", Slug: fixtures.RandomSlug(), Email: fixtures.RandomEmail(),
+		Password: "password123", Salt: "salt", IsVerified: true, CreatedAt: now,
+	})
+	suite.Require().NoError(err)
+	cm, err := fixtures.CreateTestCommunity(suite.ctx, seed, fixtures.CommunityFixture{
+		Name: "Community", Slug: fixtures.RandomSlug(), OwnerID: viewer.ID, CreatedAt: now,
+	})
+	suite.Require().NoError(err)
+	p, err := fixtures.CreateTestPost(suite.ctx, seed, fixtures.PostFixture{
+		Title: "Cached", Content: "content", CommunityID: cm.ID, AuthorID: viewer.ID, CreatedAt: now,
+	})
+	suite.Require().NoError(err)
+	suite.Require().NoError(seed.Post.UpdateOne(p).SetVisibility(post.VisibilityPublished).Exec(suite.ctx))
+
+	drv, err := entsql.Open(dialect.Postgres, suite.helper.GetContainers().GetPostgresDSN())
+	suite.Require().NoError(err)
+	counter := &countingDriver{Driver: drv}
+	client := ent.NewClient(ent.Driver(counter))
+	defer client.Close()
+
+	cache := &respcache.Cache{Store: respcache.NewMemoryStore(100)}
+	srv := handler.New(graphql.NewExecutableSchema(graphql.Config{Resolvers: &graphql.Resolver{
+		Client:         client,
+		PostUC:         postuc.NewPostUsecase(client),
+		NotificationUC: notificationuc.NewNotificationUsecase(client),
+		Broker:         pubsub.NewMemoryBroker(16),
+		Cache:          cache,
+	}}))
+	srv.AddTransport(transport.POST{})
+	srv.Use(cache)
+	c := gqlclient.New(srv)
+
+	anonymous := func() (map[string]any, map[string]any, int64) {
+		before := counter.queries.Load()
+		raw, err := c.RawPost(postBySlugQuery, gqlclient.Var("slug", p.Slug))
+		suite.Require().NoError(err)
+		suite.Require().Empty(raw.Errors)
+		data := raw.Data.(map[string]any)["postBySlug"].(map[string]any)
+		ext, _ := raw.Extensions["cacheControl"].(map[string]any)
+		return data, ext, counter.queries.Load() - before
+	}
+	asViewer := func(r *gqlclient.Request) {
+		r.HTTP = r.HTTP.WithContext(auth.WithUserID(r.HTTP.Context(), viewer.ID))
+	}
+
+	data, ext, queries := anonymous()
+	suite.Equal("0", data["postStatus"].(map[string]any)["likesCount"])
+	suite.Equal(false, ext["hit"])
+	suite.Greater(queries, int64(0))
+
+	_, ext, queries = anonymous()
+	suite.Equal(true, ext["hit"])
+	suite.Equal(int64(0), queries, "cache hit must not touch the database")
+
+	// Авторизованный пользователь получает ответ мимо кеша: postStatus зависит от зрителя
+	raw, err := c.RawPost(postBySlugQuery, gqlclient.Var("slug", p.Slug), asViewer)
+	suite.Require().NoError(err)
+	suite.Nil(raw.Extensions["cacheControl"])
+
+	var like struct{ LikePost map[string]any }
+	c.MustPost(`mutation($id: ID!) { likePost(input: { postID: $id }) { likesCount } }`, &like,
+		gqlclient.Var("id", p.ID), asViewer)
+	suite.Equal("1", like.LikePost["likesCount"])
+
+	data, ext, _ = anonymous()
+	suite.Equal(false, ext["hit"], "likePost must invalidate the post")
+	suite.Equal("1", data["postStatus"].(map[string]any)["likesCount"])
+}
+
+func (suite *ResponseCacheTestSuite) TestSanctionInvalidatesAuthorPosts() {
+	suite.helper.CleanDatabase(suite.T())
+	client := suite.helper.GetClient()
+	now := time.Now()
+
+	owner, err := fixtures.CreateTestUser(suite.ctx, client, fixtures.UserFixture{
+		Name: "Owner", Slug: fixtures.RandomSlug(), Email: fixtures.RandomEmail(),
+		Password: "password123", Salt: "salt", IsVerified: true, CreatedAt: now,
+	})
+	suite.Require().NoError(err)
+	author, err := fixtures.CreateTestUser(suite.ctx, client, fixtures.UserFixture{
+		Name: "Author", Slug: fixtures.RandomSlug(), Email: fixtures.RandomEmail(),
+		Password: "password123", Salt: "salt", IsVerified: true, CreatedAt: now,
+	})
+	suite.Require().NoError(err)
+	cm, err := fixtures.CreateTestCommunity(suite.ctx, client, fixtures.CommunityFixture{
+		Name: "Community", Slug: fixtures.RandomSlug(), OwnerID: owner.ID, CreatedAt: now,
+	})
+	suite.Require().NoError(err)
+	p, err := fixtures.CreateTestPost(suite.ctx, client, fixtures.PostFixture{
+		Title: "Cached", Content: "content", CommunityID: cm.ID, AuthorID: author.ID, CreatedAt: now,
+	})
+	suite.Require().NoError(err)
+	suite.Require().NoError(client.Post.UpdateOne(p).SetVisibility(post.VisibilityPublished).Exec(suite.ctx))
+
+	cache := &respcache.Cache{Store: respcache.NewMemoryStore(100)}
+	srv := handler.New(graphql.NewExecutableSchema(graphql.Config{Resolvers: &graphql.Resolver{
+		Client:         client,
+		PostUC:         postuc.NewPostUsecase(client),
+		BanUC:          banuc.NewBanUsecase(client),
+		NotificationUC: notificationuc.NewNotificationUsecase(client),
+		Broker:         pubsub.NewMemoryBroker(16),
+		Cache:          cache,
+	}}))
+	srv.AddTransport(transport.POST{})
+	srv.Use(cache)
+	c := gqlclient.New(srv)
+
+	hit := func() bool {
+		raw, err := c.RawPost(postBySlugQuery, gqlclient.Var("slug", p.Slug))
+		suite.Require().NoError(err)
+		suite.Require().Empty(raw.Errors)
+		ext, _ := raw.Extensions["cacheControl"].(map[string]any)
+		return ext["hit"] == true
+	}
+	suite.False(hit())
+	suite.True(hit())
+
+	// Теневой бан скрывает посты автора от остальных: закешированный пост должен сброситься
+	suite.Require().NoError(c.Post(`mutation($u: ID!, $c: ID!) {
+		banUserFromCommunity(input: { userID: $u, communityID: $c, reason: "spam", shadow: true }) { id }
+	}`, &struct{}{}, gqlclient.Var("u", author.ID), gqlclient.Var("c", cm.ID), func(r *gqlclient.Request) {
+		r.HTTP = r.HTTP.WithContext(auth.WithUserID(r.HTTP.Context(), owner.ID))
+	}))
+	suite.False(hit(), "a sanction must invalidate the author's posts")
+}
+
+func TestResponseCacheTestSuite(t *testing.T) {
+	suite.Run(t, new(ResponseCacheTestSuite))
+}
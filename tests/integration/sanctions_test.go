@@ -0,0 +1,311 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	gqlclient "github.com/99designs/gqlgen/client"
+	"github.com/stretchr/testify/suite"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"stormlink/server/ent"
+	"stormlink/server/graphql"
+	"stormlink/server/sanctions"
+	commentuc "stormlink/server/usecase/comment"
+	communityuc "stormlink/server/usecase/community"
+	notificationuc "stormlink/server/usecase/notification"
+	postuc "stormlink/server/usecase/post"
+	useruc "stormlink/server/usecase/user"
+	"stormlink/shared/pubsub"
+	"stormlink/tests/fixtures"
+	"stormlink/tests/testhelper"
+	"stormlink/tests/testhelper/gqltest"
+)
+
+// sanctionedWorld — пользователь, сообщество и контент, над которыми проверяются санкции
+type sanctionedWorld struct {
+	actor, other *ent.User
+	community    *ent.Community
+	post         *ent.Post
+	comment      *ent.Comment
+}
+
+// sanctionedMutation — мутация и то, какие санкции ее запрещают
+type sanctionedMutation struct {
+	name  string
+	query string
+	vars  func(w sanctionedWorld) []gqlclient.Option
+	// publishes — мутацию запрещают и муты, а не только баны
+	publishes bool
+	// scoped — мутация выполняется в сообществе и подпадает под его санкции
+	scoped bool
+}
+
+var sanctionedMutations = []sanctionedMutation{
+	{
+		name:  "createPost",
+		query: `mutation($a: ID!, $c: ID!, $content: JSON!) { createPost(input: { title: "Blocked", content: $content, authorID: $a, communityID: $c }) { id } }`,
+		vars: func(w sanctionedWorld) []gqlclient.Option {
+			return []gqlclient.Option{gqlclient.Var("a", w.actor.ID), gqlclient.Var("c", w.community.ID),
+				gqlclient.Var("content", map[string]any{"blocks": []any{}})}
+		},
+		publishes: true, scoped: true,
+	},
+	{
+		name:  "createComment",
+		query: `mutation($a: ID!, $c: ID!, $p: ID!) { createComment(input: { authorID: $a, communityID: $c, postID: $p, content: "blocked" }) { id } }`,
+		vars: func(w sanctionedWorld) []gqlclient.Option {
+			return []gqlclient.Option{gqlclient.Var("a", w.actor.ID), gqlclient.Var("c", w.community.ID), gqlclient.Var("p", w.post.ID)}
+		},
+		publishes: true, scoped: true,
+	},
+	{
+		name:  "updateComment",
+		query: `mutation($id: ID!) { updateComment(input: { id: $id, content: "edited" }) { id } }`,
+		vars: func(w sanctionedWorld) []gqlclient.Option {
+			return []gqlclient.Option{gqlclient.Var("id", w.comment.ID)}
+		},
+		publishes: true, scoped: true,
+	},
+	{
+		name:  "likePost",
+		query: `mutation($id: ID!) { likePost(input: { postID: $id }) { likesCount } }`,
+		vars: func(w sanctionedWorld) []gqlclient.Option {
+			return []gqlclient.Option{gqlclient.Var("id", w.post.ID)}
+		},
+		scoped: true,
+	},
+	{
+		name:  "likeComment",
+		query: `mutation($id: ID!) { likeComment(input: { commentID: $id }) { likesCount } }`,
+		vars: func(w sanctionedWorld) []gqlclient.Option {
+			return []gqlclient.Option{gqlclient.Var("id", w.comment.ID)}
+		},
+		scoped: true,
+	},
+	{
+		name:  "addBookmarkPost",
+		query: `mutation($id: ID!) { addBookmarkPost(input: { postID: $id }) { hasBookmark } }`,
+		vars: func(w sanctionedWorld) []gqlclient.Option {
+			return []gqlclient.Option{gqlclient.Var("id", w.post.ID)}
+		},
+		scoped: true,
+	},
+	{
+		name:  "followCommunity",
+		query: `mutation($id: ID!) { followCommunity(input: { communityID: $id }) { isFollowing } }`,
+		vars: func(w sanctionedWorld) []gqlclient.Option {
+			return []gqlclient.Option{gqlclient.Var("id", w.community.ID)}
+		},
+		scoped: true,
+	},
+	{
+		name:  "followUser",
+		query: `mutation($id: ID!) { followUser(input: { userID: $id }) { isFollowing } }`,
+		vars: func(w sanctionedWorld) []gqlclient.Option {
+			return []gqlclient.Option{gqlclient.Var("id", w.other.ID)}
+		},
+	},
+	{
+		name:  "createCommunity",
+		query: `mutation($o: ID!, $slug: String!) { createCommunity(input: { title: "Blocked", slug: $slug, ownerID: $o }) { id } }`,
+		vars: func(w sanctionedWorld) []gqlclient.Option {
+			return []gqlclient.Option{gqlclient.Var("o", w.actor.ID), gqlclient.Var("slug", fixtures.RandomSlug())}
+		},
+		publishes: true,
+	},
+	{
+		name:  "updateUser",
+		query: `mutation($id: ID!) { updateUser(input: { id: $id, description: "edited" }) { id } }`,
+		vars: func(w sanctionedWorld) []gqlclient.Option {
+			return []gqlclient.Option{gqlclient.Var("id", w.actor.ID)}
+		},
+	},
+}
+
+// sanctionCase — санкция, которую тест выдает перед прогоном всех мутаций
+type sanctionCase struct {
+	name   string
+	apply  func(ctx context.Context, client *ent.Client, w sanctionedWorld) error
+	reason sanctions.Reason
+	// mute — санкция запрещает только публикацию
+	mute bool
+	// host — санкция действует на всей платформе, а не только в сообществе
+	host bool
+	// expires — санкция выдана на срок, и клиент получает expiresAt
+	expires bool
+}
+
+func (suite *SanctionsTestSuite) cases() []sanctionCase {
+	hour := time.Now().Add(time.Hour)
+	expired := time.Now().Add(-time.Hour)
+	return []sanctionCase{
+		{name: "host ban", reason: sanctions.HostBanned, host: true,
+			apply: func(ctx context.Context, c *ent.Client, w sanctionedWorld) error {
+				return c.HostUserBan.Create().SetUserID(w.actor.ID).SetReason("spam").Exec(ctx)
+			}},
+		{name: "host mute", reason: sanctions.HostMuted, host: true, mute: true, expires: true,
+			apply: func(ctx context.Context, c *ent.Client, w sanctionedWorld) error {
+				return c.HostUserMute.Create().SetUserID(w.actor.ID).SetExpiresAt(hour).Exec(ctx)
+			}},
+		{name: "community ban", reason: sanctions.CommunityBanned,
+			apply: func(ctx context.Context, c *ent.Client, w sanctionedWorld) error {
+				return c.CommunityUserBan.Create().SetUserID(w.actor.ID).SetCommunityID(w.community.ID).Exec(ctx)
+			}},
+		{name: "community mute", reason: sanctions.CommunityMuted, mute: true, expires: true,
+			apply: func(ctx context.Context, c *ent.Client, w sanctionedWorld) error {
+				return c.CommunityUserMute.Create().SetUserID(w.actor.ID).SetCommunityID(w.community.ID).
+					SetExpiresAt(hour).Exec(ctx)
+			}},
+		{name: "community host ban", reason: sanctions.CommunityHostBanned,
+			apply: func(ctx context.Context, c *ent.Client, w sanctionedWorld) error {
+				return c.HostCommunityBan.Create().SetCommunityID(w.community.ID).Exec(ctx)
+			}},
+		{name: "community host mute", reason: sanctions.CommunityHostMuted, mute: true,
+			apply: func(ctx context.Context, c *ent.Client, w sanctionedWorld) error {
+				return c.HostCommunityMute.Create().SetCommunityID(w.community.ID).Exec(ctx)
+			}},
+		{name: "community disabled", reason: sanctions.CommunityDisabled,
+			apply: func(ctx context.Context, c *ent.Client, w sanctionedWorld) error {
+				return c.Community.UpdateOne(w.community).SetCommunityHasBanned(true).Exec(ctx)
+			}},
+		{name: "expired host ban", reason: "",
+			apply: func(ctx context.Context, c *ent.Client, w sanctionedWorld) error {
+				return c.HostUserBan.Create().SetUserID(w.actor.ID).SetExpiresAt(expired).Exec(ctx)
+			}},
+	}
+}
+
+type SanctionsTestSuite struct {
+	suite.Suite
+	ctx    context.Context
+	helper *testhelper.PostgresTestHelper
+}
+
+func (suite *SanctionsTestSuite) SetupSuite() {
+	suite.ctx = context.Background()
+	suite.helper = testhelper.NewPostgresTestHelper(suite.T())
+	suite.helper.WaitForDatabase(suite.T())
+}
+
+func (suite *SanctionsTestSuite) TearDownSuite() {
+	if suite.helper != nil {
+		suite.helper.Cleanup()
+	}
+}
+
+func (suite *SanctionsTestSuite) seed(client *ent.Client) sanctionedWorld {
+	now := time.Now()
+	newUser := func(name string) *ent.User {
+		u, err := fixtures.CreateTestUser(suite.ctx, client, fixtures.UserFixture{
+			Name: name, Slug: fixtures.RandomSlug(), Email: fixtures.RandomEmail(),
+			Password: "password123", Salt: "salt", IsVerified: true, CreatedAt: now,
+		})
+		suite.Require().NoError(err)
+		return u
+	}
+	w := sanctionedWorld{actor: newUser("Actor"), other: newUser("Other")}
+	var err error
+	w.community, err = fixtures.CreateTestCommunity(suite.ctx, client, fixtures.CommunityFixture{
+		Name: "Community", Slug: fixtures.RandomSlug(), OwnerID: w.other.ID, CreatedAt: now,
+	})
+	suite.Require().NoError(err)
+	w.post, err = fixtures.CreateTestPost(suite.ctx, client, fixtures.PostFixture{
+		Title: "Post", Content: "content", CommunityID: w.community.ID, AuthorID: w.other.ID, CreatedAt: now,
+	})
+	suite.Require().NoError(err)
+	w.comment, err = fixtures.CreateTestComment(suite.ctx, client, fixtures.CommentFixture{
+		Content: "comment", PostID: w.post.ID, AuthorID: w.actor.ID, CreatedAt: now,
+	})
+	suite.Require().NoError(err)
+	return w
+}
+
+// TestEveryMutationRespectsEverySanction прогоняет каждую мутацию под каждой санкцией
+func (suite *SanctionsTestSuite) TestEveryMutationRespectsEverySanction() {
+	for _, sc := range suite.cases() {
+		suite.Run(sc.name, func() {
+			suite.helper.CleanDatabase(suite.T())
+			client := suite.helper.GetClient()
+			w := suite.seed(client)
+			suite.Require().NoError(sc.apply(suite.ctx, client, w))
+
+			c := gqltest.NewClient(&graphql.Resolver{
+				Client:         client,
+				Sanctions:      sanctions.New(sanctions.NewEntStore(client), nil),
+				PostUC:         postuc.NewPostUsecase(client),
+				CommentUC:      commentuc.NewCommentUsecase(client),
+				UserUC:         useruc.NewUserUsecase(client),
+				CommunityUC:    communityuc.NewCommunityUsecase(client),
+				NotificationUC: notificationuc.NewNotificationUsecase(client),
+				Broker:         pubsub.NewMemoryBroker(16),
+			})
+
+			for _, m := range sanctionedMutations {
+				raw, err := c.RawPost(m.query, append(m.vars(w), gqltest.As(w.actor.ID))...)
+				suite.Require().NoError(err, m.name)
+				var errs gqlerror.List
+				if len(raw.Errors) > 0 {
+					suite.Require().NoError(json.Unmarshal(raw.Errors, &errs), m.name)
+				}
+
+				denied := sc.reason != "" && (sc.host || m.scoped) && (!sc.mute || m.publishes)
+				if !denied {
+					for _, e := range errs {
+						suite.NotEqual("PermissionDenied", e.Extensions["code"], "%s: %s", m.name, e.Message)
+					}
+					continue
+				}
+				if suite.Len(errs, 1, m.name) {
+					suite.Equal("PermissionDenied", errs[0].Extensions["code"], m.name)
+					suite.Equal(string(sc.reason), errs[0].Extensions["reason"], m.name)
+					_, hasExpiry := errs[0].Extensions["expiresAt"]
+					suite.Equal(sc.expires, hasExpiry, m.name)
+				}
+			}
+		})
+	}
+}
+
+// TestCreateActsAsViewer — санкции нельзя обойти, подставив во ввод чужого автора или сообщество
+func (suite *SanctionsTestSuite) TestCreateActsAsViewer() {
+	suite.helper.CleanDatabase(suite.T())
+	client := suite.helper.GetClient()
+	w := suite.seed(client)
+	suite.Require().NoError(client.CommunityUserBan.Create().SetUserID(w.actor.ID).SetCommunityID(w.community.ID).Exec(suite.ctx))
+	elsewhere, err := fixtures.CreateTestCommunity(suite.ctx, client, fixtures.CommunityFixture{
+		Name: "Elsewhere", Slug: fixtures.RandomSlug(), OwnerID: w.actor.ID, CreatedAt: time.Now(),
+	})
+	suite.Require().NoError(err)
+
+	c := gqltest.NewClient(&graphql.Resolver{
+		Client:         client,
+		Sanctions:      sanctions.New(sanctions.NewEntStore(client), nil),
+		CommentUC:      commentuc.NewCommentUsecase(client),
+		NotificationUC: notificationuc.NewNotificationUsecase(client),
+		Broker:         pubsub.NewMemoryBroker(16),
+	})
+	createComment := `mutation($a: ID!, $c: ID!, $p: ID!) { createComment(input: { authorID: $a, communityID: $c, postID: $p, content: "evasion" }) { id } }`
+	attempts := map[string][]gqlclient.Option{
+		"someone else's authorID": {gqlclient.Var("a", w.other.ID), gqlclient.Var("c", w.community.ID), gqlclient.Var("p", w.post.ID)},
+		"foreign communityID":     {gqlclient.Var("a", w.actor.ID), gqlclient.Var("c", elsewhere.ID), gqlclient.Var("p", w.post.ID)},
+		"anonymous":               {gqlclient.Var("a", w.actor.ID), gqlclient.Var("c", w.community.ID), gqlclient.Var("p", w.post.ID)},
+	}
+	for name, vars := range attempts {
+		if name != "anonymous" {
+			vars = append(vars, gqltest.As(w.actor.ID))
+		}
+		raw, err := c.RawPost(createComment, vars...)
+		suite.Require().NoError(err, name)
+		suite.NotEmpty(raw.Errors, name)
+	}
+	count, err := client.Comment.Query().Count(suite.ctx)
+	suite.Require().NoError(err)
+	suite.Equal(1, count, "only the seeded comment exists")
+}
+
+func TestSanctionsTestSuite(t *testing.T) {
+	suite.Run(t, new(SanctionsTestSuite))
+}
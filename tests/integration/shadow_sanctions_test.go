@@ -0,0 +1,157 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	gqlclient "github.com/99designs/gqlgen/client"
+	"github.com/stretchr/testify/suite"
+
+	"stormlink/server/authz"
+	"stormlink/server/ent"
+	"stormlink/server/ent/notification"
+	"stormlink/server/graphql"
+	"stormlink/server/shadow"
+	banuc "stormlink/server/usecase/ban"
+	commentuc "stormlink/server/usecase/comment"
+	notificationuc "stormlink/server/usecase/notification"
+	postuc "stormlink/server/usecase/post"
+	"stormlink/shared/auth"
+	"stormlink/shared/pubsub"
+	"stormlink/tests/fixtures"
+	"stormlink/tests/testhelper"
+	"stormlink/tests/testhelper/gqltest"
+)
+
+type ShadowSanctionsTestSuite struct {
+	suite.Suite
+	ctx    context.Context
+	helper *testhelper.PostgresTestHelper
+}
+
+func (suite *ShadowSanctionsTestSuite) SetupSuite() {
+	suite.ctx = context.Background()
+	suite.helper = testhelper.NewPostgresTestHelper(suite.T())
+	suite.helper.WaitForDatabase(suite.T())
+}
+
+func (suite *ShadowSanctionsTestSuite) TearDownSuite() {
+	if suite.helper != nil {
+		suite.helper.Cleanup()
+	}
+}
+
+// TestShadowBan — под теневым баном пользователь пишет как обычно и видит свой комментарий,
+// остальные его не видят ни в списках, ни в счетчиках, модератор сообщества — видит
+func (suite *ShadowSanctionsTestSuite) TestShadowBan() {
+	suite.helper.CleanDatabase(suite.T())
+	client := suite.helper.GetClient()
+	shadow.Register(client, shadow.Moderators(client, authz.New(authz.NewEntStore(client))))
+	now := time.Now()
+	newUser := func(name string) *ent.User {
+		u, err := fixtures.CreateTestUser(suite.ctx, client, fixtures.UserFixture{
+			Name: name, Slug: fixtures.RandomSlug(), Email: fixtures.RandomEmail(),
+			Password: "password123", Salt: "salt", IsVerified: true, CreatedAt: now,
+		})
+		suite.Require().NoError(err)
+		return u
+	}
+	owner, spammer, reader := newUser("Owner"), newUser("Spammer"), newUser("Reader")
+	community, err := fixtures.CreateTestCommunity(suite.ctx, client, fixtures.CommunityFixture{
+		Name: "Community", Slug: fixtures.RandomSlug(), OwnerID: owner.ID, CreatedAt: now,
+	})
+	suite.Require().NoError(err)
+	post, err := fixtures.CreateTestPost(suite.ctx, client, fixtures.PostFixture{
+		Title: "Post", Content: "content", CommunityID: community.ID, AuthorID: owner.ID, CreatedAt: now,
+	})
+	suite.Require().NoError(err)
+
+	bans := banuc.NewBanUsecase(client)
+	posts := postuc.NewPostUsecase(client)
+	broker := pubsub.NewMemoryBroker(16)
+	c := gqltest.NewClient(&graphql.Resolver{
+		Client:         client,
+		BanUC:          bans,
+		PostUC:         posts,
+		CommentUC:      commentuc.NewCommentUsecase(client),
+		NotificationUC: notificationuc.NewNotificationUsecase(client),
+		Broker:         broker,
+	})
+	subCtx, cancel := context.WithCancel(suite.ctx)
+	defer cancel()
+	statsEvents, err := broker.Subscribe(subCtx, fmt.Sprintf("post.stats.post.%d", post.ID), "")
+	suite.Require().NoError(err)
+
+	suite.Require().NoError(c.Post(`mutation($u: ID!, $c: ID) {
+		banUserFromCommunity(input: { userID: $u, communityID: $c, reason: "spam", shadow: true }) { id shadow }
+	}`, &struct{}{}, gqlclient.Var("u", spammer.ID), gqlclient.Var("c", community.ID), gqltest.As(owner.ID)))
+
+	// Бан не мешает писать, и наказанный о нем не узнает
+	suite.Require().NoError(c.Post(createCommentMutation, &struct{}{}, gqlclient.Var("author", spammer.ID),
+		gqlclient.Var("community", community.ID), gqlclient.Var("post", post.ID),
+		gqlclient.Var("text", "купите слона"), gqltest.As(spammer.ID)))
+	// Счетчики рассылаются всем подписчикам поста и не выдают теневой комментарий
+	select {
+	case ev := <-statsEvents:
+		var stats struct{ Stats struct{ CommentsCount int32 } }
+		suite.Require().NoError(json.Unmarshal(ev.Payload, &stats))
+		suite.Zero(stats.Stats.CommentsCount)
+	case <-time.After(5 * time.Second):
+		suite.Fail("no post stats event")
+	}
+	_, err = client.PostLike.Create().SetUserID(spammer.ID).SetPostID(post.ID).Save(suite.ctx)
+	suite.Require().NoError(err)
+	sanctions, err := bans.GetUserSanctions(suite.ctx, spammer.ID)
+	suite.Require().NoError(err)
+	suite.Empty(sanctions)
+	notified, err := client.Notification.Query().Where(notification.UserIDEQ(spammer.ID)).Exist(suite.ctx)
+	suite.Require().NoError(err)
+	suite.False(notified)
+	// Автор поста не получает уведомление об ответе
+	notified, err = client.Notification.Query().Where(notification.UserIDEQ(owner.ID)).Exist(suite.ctx)
+	suite.Require().NoError(err)
+	suite.False(notified)
+
+	commentsQuery := `query($id: ID!) { commentsByPostId(id: $id) { id } }`
+	bansQuery := `query($id: ID!) { community(id: $id) { bans { id shadow } } }`
+	for _, tc := range []struct {
+		name    string
+		viewer  int
+		visible int
+		// bans — сколько банов сообщества видно: теневой бан видят только модераторы
+		bans int
+	}{
+		{"reader", reader.ID, 0, 0},
+		{"anonymous", 0, 0, 0},
+		{"author", spammer.ID, 1, 0},
+		{"moderator", owner.ID, 1, 1},
+	} {
+		var out struct{ CommentsByPostID []struct{ ID string } }
+		suite.Require().NoError(c.Post(commentsQuery, &out, gqlclient.Var("id", post.ID), gqltest.As(tc.viewer)))
+		suite.Len(out.CommentsByPostID, tc.visible, tc.name)
+
+		status, err := posts.GetPostStatus(auth.WithUserID(suite.ctx, tc.viewer), tc.viewer, post.ID)
+		suite.Require().NoError(err)
+		suite.Equal(strconv.Itoa(tc.visible), status.CommentsCount, tc.name)
+		suite.Equal(strconv.Itoa(tc.visible), status.LikesCount, tc.name)
+
+		var bansOut struct {
+			Community struct {
+				Bans []struct {
+					ID     string
+					Shadow bool
+				}
+			}
+		}
+		suite.Require().NoError(c.Post(bansQuery, &bansOut, gqlclient.Var("id", community.ID), gqltest.As(tc.viewer)))
+		suite.Len(bansOut.Community.Bans, tc.bans, tc.name)
+	}
+}
+
+func TestShadowSanctionsTestSuite(t *testing.T) {
+	suite.Run(t, new(ShadowSanctionsTestSuite))
+}
@@ -0,0 +1,32 @@
+// Package gqltest поднимает GraphQL-схему сервера в процессе для интеграционных тестов.
+// Отдельный пакет, а не testhelper: testhelper импортируют тесты юзкейсов, которые
+// импортирует и server/graphql.
+package gqltest
+
+import (
+	gqlclient "github.com/99designs/gqlgen/client"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+
+	"stormlink/server/graphql"
+	"stormlink/shared/auth"
+)
+
+// NewServer — исполняемая схема с резолвером r, принимающая POST-запросы
+func NewServer(r *graphql.Resolver) *handler.Server {
+	srv := handler.New(graphql.NewExecutableSchema(graphql.Config{Resolvers: r}))
+	srv.AddTransport(transport.POST{})
+	return srv
+}
+
+// NewClient — клиент к NewServer(r)
+func NewClient(r *graphql.Resolver) *gqlclient.Client {
+	return gqlclient.New(NewServer(r))
+}
+
+// As выполняет запрос от имени пользователя userID
+func As(userID int) gqlclient.Option {
+	return func(r *gqlclient.Request) {
+		r.HTTP = r.HTTP.WithContext(auth.WithUserID(r.HTTP.Context(), userID))
+	}
+}
@@ -56,7 +56,22 @@ func (h *PostgresTestHelper) GetContainers() *testcontainers.TestContainers {
 // CleanDatabase очищает всю базу данных (для SetupTest/TearDownTest)
 func (h *PostgresTestHelper) CleanDatabase(t *testing.T) {
 	// Удаляем данные в правильном порядке (от дочерних к родительским таблицам)
-	_, err := h.client.CommentLike.Delete().Exec(h.ctx)
+	_, err := h.client.Notification.Delete().Exec(h.ctx)
+	require.NoError(t, err)
+
+	_, err = h.client.BanAppeal.Delete().Exec(h.ctx)
+	require.NoError(t, err)
+
+	_, err = h.client.Report.Delete().Exec(h.ctx)
+	require.NoError(t, err)
+
+	_, err = h.client.ModerationAction.Delete().Exec(h.ctx)
+	require.NoError(t, err)
+
+	_, err = h.client.AutomodRule.Delete().Exec(h.ctx)
+	require.NoError(t, err)
+
+	_, err = h.client.CommentLike.Delete().Exec(h.ctx)
 	require.NoError(t, err)
 
 	_, err = h.client.PostLike.Delete().Exec(h.ctx)
@@ -98,6 +113,9 @@ func (h *PostgresTestHelper) CleanDatabase(t *testing.T) {
 	_, err = h.client.HostCommunityMute.Delete().Exec(h.ctx)
 	require.NoError(t, err)
 
+	_, err = h.client.HostIPBan.Delete().Exec(h.ctx)
+	require.NoError(t, err)
+
 	_, err = h.client.Media.Delete().Exec(h.ctx)
 	require.NoError(t, err)
 
@@ -122,6 +140,15 @@ func (h *PostgresTestHelper) CleanDatabase(t *testing.T) {
 	_, err = h.client.EmailVerification.Delete().Exec(h.ctx)
 	require.NoError(t, err)
 
+	_, err = h.client.UserSession.Delete().Exec(h.ctx)
+	require.NoError(t, err)
+
+	_, err = h.client.Outbox.Delete().Exec(h.ctx)
+	require.NoError(t, err)
+
+	_, err = h.client.NotificationSettings.Delete().Exec(h.ctx)
+	require.NoError(t, err)
+
 	_, err = h.client.Community.Delete().Exec(h.ctx)
 	require.NoError(t, err)
 